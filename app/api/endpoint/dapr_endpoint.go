@@ -6,18 +6,29 @@ import (
 	"go-klikdokter/app/model/base"
 	"go-klikdokter/app/model/base/encoder"
 	request_dapr "go-klikdokter/app/model/request/dapr"
+	"go-klikdokter/app/model/response"
 	"go-klikdokter/app/service"
 )
 
 type DaprEndpoint struct {
 	Publisher              endpoint.Endpoint
 	SubscriberRatingsubcol endpoint.Endpoint
+
+	Subscribe                    endpoint.Endpoint
+	SubscriberOrderCompleted     endpoint.Endpoint
+	SubscriberOrderRefunded      endpoint.Endpoint
+	SubscriberUserProfileUpdated endpoint.Endpoint
 }
 
 func MakeDaprEndpoints(s service.DaprService) DaprEndpoint {
 	return DaprEndpoint{
 		Publisher:              makeDaprPublisher(s),
 		SubscriberRatingsubcol: makeDaprSubscriberRatingsubcol(s),
+
+		Subscribe:                    makeDaprSubscribe(s),
+		SubscriberOrderCompleted:     makeDaprSubscriber(s.SubscriberOrderCompleted),
+		SubscriberOrderRefunded:      makeDaprSubscriber(s.SubscriberOrderRefunded),
+		SubscriberUserProfileUpdated: makeDaprSubscriber(s.SubscriberUserProfileUpdated),
 	}
 }
 
@@ -56,3 +67,18 @@ func makeDaprSubscriberRatingsubcol(s service.DaprService) endpoint.Endpoint {
 		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
 	}
 }
+
+func makeDaprSubscribe(s service.DaprService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		routePrefix := rqst.(string)
+		return s.GetSubscriptions(routePrefix), nil
+	}
+}
+
+// makeDaprSubscriber answers dapr with the status of the event, dapr calls the subscribers without a token
+func makeDaprSubscriber(subscriber func(input request_dapr.CloudEventRequest) string) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request_dapr.CloudEventRequest)
+		return response.DaprSubscriberResponse{Status: subscriber(req)}, nil
+	}
+}
@@ -0,0 +1,75 @@
+package endpoint
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+type OutboxEndpoint struct {
+	GetOutboxMessages    endpoint.Endpoint
+	GetOutboxMessageById endpoint.Endpoint
+	ReplayOutboxMessage  endpoint.Endpoint
+}
+
+func MakeOutboxEndpoints(s service.OutboxService) OutboxEndpoint {
+	return OutboxEndpoint{
+		GetOutboxMessages:    makeGetOutboxMessages(s),
+		GetOutboxMessageById: makeGetOutboxMessageById(s),
+		ReplayOutboxMessage:  makeReplayOutboxMessage(s),
+	}
+}
+
+func makeGetOutboxMessages(s service.OutboxService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.GetOutboxMessagesRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		result, pagination, msg := s.GetOutboxMessages(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, pagination), nil
+	}
+}
+
+func makeGetOutboxMessageById(s service.OutboxService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.OutboxMessageRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		result, msg := s.GetOutboxMessageById(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeReplayOutboxMessage(s service.OutboxService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.OutboxMessageRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		msg := s.ReplayOutboxMessage(req)
+		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+	}
+}
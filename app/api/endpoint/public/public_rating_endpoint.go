@@ -9,6 +9,7 @@ import (
 	rp "go-klikdokter/app/repository"
 	publicrepository "go-klikdokter/app/repository/public"
 	"go-klikdokter/app/service/public"
+	"go-klikdokter/helper/global"
 	"go-klikdokter/helper/message"
 	"go-klikdokter/pkg/util"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -34,11 +35,12 @@ func MakePublicRatingEndpoints(s publicservice.PublicRatingService, logger log.L
 func makeGetListRatingSummaryBySourceType(s publicservice.PublicRatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(publicrequest.GetPublicListRatingSummaryRequest)
+		req.Lang = base.GetLanguage(ctx)
 		var result interface{}
 		var pagination *base.Pagination
 		var msg message.Message
 
-		if util.StringInSlice(strings.ToLower(req.SourceType), []string{"product", "store"}) {
+		if util.StringInSlice(strings.ToLower(req.SourceType), global.GetMarketplaceSourceTypeNames()) {
 			publicMp := publicservice.NewPublicRatingMpService(logger, rp.NewRatingMpRepository(db), publicrepository.NewPublicRatingMpRepository(db))
 			result, msg = publicMp.GetListRatingSummaryBySourceType(req)
 		} else {
@@ -54,11 +56,12 @@ func makeGetListRatingSummaryBySourceType(s publicservice.PublicRatingService, l
 func makeGetListRatingSubmissionBySourceTypeAndUID(s publicservice.PublicRatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(publicrequest.GetPublicListRatingSubmissionRequest)
+		req.UserIdLegacy = optionalUserIdLegacy(ctx)
 		var result interface{}
 		var pagination *base.Pagination
 		var msg message.Message
 
-		if util.StringInSlice(strings.ToLower(req.SourceType), []string{"product", "store"}) {
+		if util.StringInSlice(strings.ToLower(req.SourceType), global.GetMarketplaceSourceTypeNames()) {
 			publicMp := publicservice.NewPublicRatingMpService(logger, rp.NewRatingMpRepository(db), publicrepository.NewPublicRatingMpRepository(db))
 			result, pagination, msg = publicMp.GetListRatingSubmissionBySourceTypeAndUID(req)
 		} else {
@@ -75,6 +78,7 @@ func makeGetListRatingSubmissionBySourceTypeAndUID(s publicservice.PublicRatingS
 func makeGetListDetailRatingSummaryBySourceType(s publicservice.PublicRatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(publicrequest.PublicGetListDetailRatingSummaryRequest)
+		req.Lang = base.GetLanguage(ctx)
 		var result interface{}
 		var msg message.Message
 
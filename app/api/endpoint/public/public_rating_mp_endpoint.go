@@ -2,10 +2,13 @@ package publicendpoint
 
 import (
 	"context"
+	"fmt"
 	"go-klikdokter/app/model/base"
 	"go-klikdokter/app/model/base/encoder"
 	publicrequest "go-klikdokter/app/model/request/public"
 	publicservice "go-klikdokter/app/service/public"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
 
 	"github.com/go-kit/kit/endpoint"
 )
@@ -29,6 +32,7 @@ func MakePublicRatingMpEndpoints(s publicservice.PublicRatingMpService) PublicRa
 func makeGetListRatingSummaryMpBySourceType(s publicservice.PublicRatingMpService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(publicrequest.GetPublicListRatingSummaryRequest)
+		req.Lang = base.GetLanguage(ctx)
 		result, msg := s.GetListRatingSummaryBySourceType(req)
 		if msg.Code != 212000 {
 			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
@@ -40,6 +44,7 @@ func makeGetListRatingSummaryMpBySourceType(s publicservice.PublicRatingMpServic
 func makeGetListRatingSubmissionMpBySourceTypeAndUID(s publicservice.PublicRatingMpService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(publicrequest.GetPublicListRatingSubmissionRequest)
+		req.UserIdLegacy = optionalUserIdLegacy(ctx)
 		result, pagination, msg := s.GetListRatingSubmissionBySourceTypeAndUID(req)
 		if msg.Code != 212000 {
 			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, pagination), nil
@@ -51,6 +56,7 @@ func makeGetListRatingSubmissionMpBySourceTypeAndUID(s publicservice.PublicRatin
 func makeGetListRatingSubmissionByID(s publicservice.PublicRatingMpService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(publicrequest.GetPublicListRatingSubmissionByIDRequest)
+		req.UserIdLegacy = optionalUserIdLegacy(ctx)
 		result, pagination, msg, errMsg := s.GetListRatingSubmissionByID(ctx, req)
 		if msg.Code != 212000 {
 			return base.SetHttpResponseWithCorrelationID(ctx, msg.Code, msg.Message, nil, nil, errMsg), nil
@@ -62,6 +68,7 @@ func makeGetListRatingSubmissionByID(s publicservice.PublicRatingMpService) endp
 func makeGetRatingSummaryStoreProduct(s publicservice.PublicRatingMpService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(publicrequest.PublicGetRatingSummaryStoreProductRequest)
+		req.Lang = base.GetLanguage(ctx)
 		result, msg := s.GetRatingSummaryStoreProduct(ctx, req)
 		if msg.Code != 212000 {
 			return base.SetHttpResponseWithCorrelationID(ctx, msg.Code, msg.Message, nil, nil, nil), nil
@@ -69,3 +76,12 @@ func makeGetRatingSummaryStoreProduct(s publicservice.PublicRatingMpService) end
 		return base.SetHttpResponseWithCorrelationID(ctx, msg.Code, msg.Message, result, nil, nil), nil
 	}
 }
+
+// optionalUserIdLegacy returns the user of the bearer token verified by middleware.OptionalJWTAuthentication, empty for an anonymous request
+func optionalUserIdLegacy(ctx context.Context) string {
+	jwtObj, msg := global.SetJWTInfoFromContext(ctx)
+	if msg.Code != message.SuccessMsg.Code {
+		return ""
+	}
+	return fmt.Sprint(jwtObj.UserIdLegacy)
+}
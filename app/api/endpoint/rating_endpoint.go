@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go-klikdokter/app/model/base"
 	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
 	publicrequest "go-klikdokter/app/model/request/public"
 	"go-klikdokter/app/repository"
@@ -14,7 +15,6 @@ import (
 	"go-klikdokter/pkg/util"
 	"strings"
 
-	"github.com/spf13/viper"
 
 	"github.com/go-kit/log"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -23,86 +23,118 @@ import (
 )
 
 type RatingEndpoint struct {
-	CreateRatingTypeNum     endpoint.Endpoint
-	UpdateRatingById        endpoint.Endpoint
-	GetRatingTypeNumById    endpoint.Endpoint
-	DeleteRatingTypeNumById endpoint.Endpoint
-	GetRatingTypeNums       endpoint.Endpoint
+	CreateRatingTypeNum      endpoint.Endpoint
+	UpdateRatingById         endpoint.Endpoint
+	GetRatingTypeNumById     endpoint.Endpoint
+	DeleteRatingTypeNumById  endpoint.Endpoint
+	RestoreRatingTypeNumById endpoint.Endpoint
+	GetRatingTypeNums        endpoint.Endpoint
 
 	CreateRatingSubmission                  endpoint.Endpoint
 	UpdateRatingSubmission                  endpoint.Endpoint
 	GetRatingSubmission                     endpoint.Endpoint
 	GetListRatingSubmission                 endpoint.Endpoint
 	DeleteRatingSubmission                  endpoint.Endpoint
+	RestoreRatingSubmission                 endpoint.Endpoint
 	GetListRatingSubmissionWithUserIdLegacy endpoint.Endpoint
 	UpdateRatingSubDisplayNameByIdLegacy    endpoint.Endpoint
 	CancelRatingSubByIds                    endpoint.Endpoint
 	ReplyAdminRatingSubmission              endpoint.Endpoint
+	HideReplyRatingSubmission               endpoint.Endpoint
+	GetListModerationQueue                  endpoint.Endpoint
+	ModerateRatingSubmission                endpoint.Endpoint
 
-	CreateRatingTypeLikert     endpoint.Endpoint
-	GetRatingTypeLikertById    endpoint.Endpoint
-	UpdateRatingTypeLikertById endpoint.Endpoint
-	DeleteRatingTypeLikertById endpoint.Endpoint
-	GetRatingTypeLikerts       endpoint.Endpoint
+	CreateRatingTypeLikert      endpoint.Endpoint
+	GetRatingTypeLikertById     endpoint.Endpoint
+	UpdateRatingTypeLikertById  endpoint.Endpoint
+	DeleteRatingTypeLikertById  endpoint.Endpoint
+	RestoreRatingTypeLikertById endpoint.Endpoint
+	GetRatingTypeLikerts        endpoint.Endpoint
 
 	CreateRating                  endpoint.Endpoint
 	ShowRating                    endpoint.Endpoint
 	UpdateRating                  endpoint.Endpoint
 	DeleteRating                  endpoint.Endpoint
+	RestoreRating                 endpoint.Endpoint
 	GetRatings                    endpoint.Endpoint
 	GetListRatingSummary          endpoint.Endpoint
 	GetRatingBySourceTypeAndActor endpoint.Endpoint
 
-	CreateRatingFormula     endpoint.Endpoint
-	UpdateRatingFormulaById endpoint.Endpoint
-	GetRatingFormulaById    endpoint.Endpoint
-	DeleteRatingFormulaById endpoint.Endpoint
-	GetRatingFormulas       endpoint.Endpoint
+	CreateRatingFormula      endpoint.Endpoint
+	UpdateRatingFormulaById  endpoint.Endpoint
+	GetRatingFormulaById     endpoint.Endpoint
+	DeleteRatingFormulaById  endpoint.Endpoint
+	RestoreRatingFormulaById endpoint.Endpoint
+	GetRatingFormulas        endpoint.Endpoint
 
-	CreateRatingSubHelpful endpoint.Endpoint
+	PreviewRatingFormula         endpoint.Endpoint
+	GetRatingFormulaVersions     endpoint.Endpoint
+	CreateRatingFormulaVersion   endpoint.Endpoint
+	ActivateRatingFormulaVersion endpoint.Endpoint
+
+	RepublishFinalRatings endpoint.Endpoint
+
+	CreateRatingSubHelpful   endpoint.Endpoint
+	CreateRatingSubHelpfulMp endpoint.Endpoint
 
 	CreateRatingInternal endpoint.Endpoint
 }
 
 func MakeRatingEndpoints(s service.RatingService, logger log.Logger, db *mongo.Database) RatingEndpoint {
 	return RatingEndpoint{
-		CreateRatingTypeNum:     makeCreateRatingTypeNum(s),
-		UpdateRatingById:        makeUpdateRatingById(s),
-		GetRatingTypeNumById:    makeGetRatingTypeNumeById(s),
-		DeleteRatingTypeNumById: makeDeleteRatingTypeNumById(s),
-		GetRatingTypeNums:       makeGetRatingTypeNums(s),
+		CreateRatingTypeNum:      makeCreateRatingTypeNum(s),
+		UpdateRatingById:         makeUpdateRatingById(s),
+		GetRatingTypeNumById:     makeGetRatingTypeNumeById(s),
+		DeleteRatingTypeNumById:  makeDeleteRatingTypeNumById(s),
+		RestoreRatingTypeNumById: makeRestoreRatingTypeNumById(s),
+		GetRatingTypeNums:        makeGetRatingTypeNums(s),
 
 		CreateRatingSubmission:                  makeCreateRatingSubmission(s, logger, db),
 		UpdateRatingSubmission:                  makeUpdateRatingSubmission(s, logger, db),
 		GetRatingSubmission:                     makeGetRatingSubmission(s),
 		GetListRatingSubmission:                 makeGetListRatingSubmissions(s),
-		DeleteRatingSubmission:                  makeDeleteRatingSubmission(s),
+		DeleteRatingSubmission:                  makeDeleteRatingSubmission(s, logger, db),
+		RestoreRatingSubmission:                 makeRestoreRatingSubmission(s, logger, db),
 		GetListRatingSubmissionWithUserIdLegacy: makeGetListRatingSubmissionWithUserIdLegacy(s),
 		UpdateRatingSubDisplayNameByIdLegacy:    makeUpdatePublicRatingSubDisplayNameByIdLegacy(s),
 		CancelRatingSubByIds:                    makeCancelRatingSubByIds(s),
 		ReplyAdminRatingSubmission:              makeReplyAdminRatingSubmission(s, logger, db),
+		HideReplyRatingSubmission:               makeHideReplyRatingSubmission(s, logger, db),
+		GetListModerationQueue:                  makeGetListModerationQueue(s, logger, db),
+		ModerateRatingSubmission:                makeModerateRatingSubmission(s, logger, db),
 
-		CreateRatingTypeLikert:     makeCreateRatingTypeLikert(s),
-		GetRatingTypeLikertById:    makeGetRatingTypeLikertById(s),
-		UpdateRatingTypeLikertById: makeUpdateRatingTypeLikertById(s),
-		DeleteRatingTypeLikertById: makeDeleteRatingTypeLikertById(s),
-		GetRatingTypeLikerts:       makeRatingTypeLikerts(s),
+		CreateRatingTypeLikert:      makeCreateRatingTypeLikert(s),
+		GetRatingTypeLikertById:     makeGetRatingTypeLikertById(s),
+		UpdateRatingTypeLikertById:  makeUpdateRatingTypeLikertById(s),
+		DeleteRatingTypeLikertById:  makeDeleteRatingTypeLikertById(s),
+		RestoreRatingTypeLikertById: makeRestoreRatingTypeLikertById(s),
+		GetRatingTypeLikerts:        makeRatingTypeLikerts(s),
 
 		CreateRating:                  makeCreateRating(s, logger, db),
 		ShowRating:                    makeShowRating(s),
 		UpdateRating:                  makeUpdateRating(s),
 		DeleteRating:                  makeDeleteRatingById(s),
+		RestoreRating:                 makeRestoreRating(s),
 		GetRatings:                    makeGetListRatings(s),
 		GetListRatingSummary:          makGetListRatingSummary(s, logger, db),
 		GetRatingBySourceTypeAndActor: makeGetRatingBySourceTypeAndActor(s),
 
-		CreateRatingFormula:     makeCreateRatingFormula(s),
-		UpdateRatingFormulaById: makeUpdateRatingFormulaById(s),
-		GetRatingFormulaById:    makeGetRatingFormulaById(s),
-		DeleteRatingFormulaById: makeDeleteRatingFormulaById(s),
-		GetRatingFormulas:       makeRatingFormulas(s),
+		CreateRatingFormula:      makeCreateRatingFormula(s, logger, db),
+		UpdateRatingFormulaById:  makeUpdateRatingFormulaById(s, logger, db),
+		GetRatingFormulaById:     makeGetRatingFormulaById(s),
+		DeleteRatingFormulaById:  makeDeleteRatingFormulaById(s, logger, db),
+		RestoreRatingFormulaById: makeRestoreRatingFormulaById(s, logger, db),
+		GetRatingFormulas:        makeRatingFormulas(s),
+
+		PreviewRatingFormula:         makePreviewRatingFormula(s),
+		GetRatingFormulaVersions:     makeGetRatingFormulaVersions(s),
+		CreateRatingFormulaVersion:   makeCreateRatingFormulaVersion(s),
+		ActivateRatingFormulaVersion: makeActivateRatingFormulaVersion(s, logger, db),
+
+		RepublishFinalRatings: makeRepublishFinalRatings(logger, db),
 
-		CreateRatingSubHelpful: makeCreateRatingSubHelpful(s),
+		CreateRatingSubHelpful:   makeCreateRatingSubHelpful(s),
+		CreateRatingSubHelpfulMp: makeCreateRatingSubHelpfulMp(logger, db),
 
 		CreateRatingInternal: makeCreateRatingInternal(s),
 	}
@@ -166,12 +198,12 @@ func makeDeleteRatingTypeNumById(s service.RatingService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.GetRatingTypeNumRequest)
 
-		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
 		if jwtMsg.Code != message.SuccessMsg.Code {
 			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
 		}
 
-		msg := s.DeleteRatingTypeNumById(req)
+		msg := s.DeleteRatingTypeNumById(req, fmt.Sprint(jwtObj.Fullname))
 		if msg.Code != 212000 {
 			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
 		}
@@ -180,6 +212,20 @@ func makeDeleteRatingTypeNumById(s service.RatingService) endpoint.Endpoint {
 	}
 }
 
+func makeRestoreRatingTypeNumById(s service.RatingService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SoftDeleteRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		msg := s.RestoreRatingTypeNumById(req.Id)
+		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+	}
+}
+
 func makeGetRatingTypeNums(s service.RatingService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.GetRatingTypeNumsRequest)
@@ -223,11 +269,15 @@ func makeCreateRatingSubmission(s service.RatingService, logger log.Logger, db *
 		// set user_id_legacy from token jwt
 		userIdLegacy := fmt.Sprintf("%v", jwtObj.UserIdLegacy)
 		req.UserIDLegacy = &userIdLegacy
+		req.AccountCreatedAt = jwtObj.AccountCreatedAt
+		if ip, _ := ctx.Value(base.ClientIPContextKey).(string); req.IPAddress == "" {
+			req.IPAddress = ip
+		}
 
 		var result interface{}
 		var msg message.Message
 
-		if util.StringInSlice(strings.ToLower(req.RatingType), viper.GetStringSlice("rating-type-mp")) {
+		if global.IsMarketplaceRatingType(strings.ToLower(req.RatingType)) {
 			ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
 			result, msg = ratingMp.CreateRatingSubmissionMp(ctx, req)
 			
@@ -254,8 +304,9 @@ func makeUpdateRatingSubmission(s service.RatingService, logger log.Logger, db *
 		userIdLegacy := fmt.Sprintf("%v", jwtObj.UserIdLegacy)
 		req.UserIDLegacy = &userIdLegacy
 		req.UserID = &userIdLegacy
+		req.JWTObj = jwtObj
 
-		if util.StringInSlice(strings.ToLower(req.RatingType), viper.GetStringSlice("rating-type-mp")) {
+		if global.IsMarketplaceRatingType(strings.ToLower(req.RatingType)) {
 			ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
 			msg = ratingMp.UpdateRatingSubmission(ctx, req)
 		} else {
@@ -269,19 +320,42 @@ func makeUpdateRatingSubmission(s service.RatingService, logger log.Logger, db *
 	}
 }
 
-func makeDeleteRatingSubmission(s service.RatingService) endpoint.Endpoint {
+func makeDeleteRatingSubmission(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
-		msg := s.DeleteRatingSubmission(fmt.Sprint(rqst))
+		req := rqst.(request.SoftDeleteRequest)
 
-		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
 		if jwtMsg.Code != message.SuccessMsg.Code {
 			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
 		}
 
-		if msg.Code != 212000 {
-			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		var msg message.Message
+		if req.Source == "mp" {
+			ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
+			msg = ratingMp.DeleteRatingSubmission(req.Id, fmt.Sprint(jwtObj.Fullname))
+		} else {
+			msg = s.DeleteRatingSubmission(req.Id, fmt.Sprint(jwtObj.Fullname))
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+	}
+}
+
+func makeRestoreRatingSubmission(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SoftDeleteRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
 		}
 
+		var msg message.Message
+		if req.Source == "mp" {
+			ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
+			msg = ratingMp.RestoreRatingSubmission(req.Id)
+		} else {
+			msg = s.RestoreRatingSubmission(req.Id)
+		}
 		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
 	}
 }
@@ -446,12 +520,12 @@ func makeDeleteRatingTypeLikertById(s service.RatingService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.GetRatingTypeLikertRequest)
 
-		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
 		if jwtMsg.Code != message.SuccessMsg.Code {
 			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
 		}
 
-		msg := s.DeleteRatingTypeLikertById(req)
+		msg := s.DeleteRatingTypeLikertById(req, fmt.Sprint(jwtObj.Fullname))
 		if msg.Code != 212000 {
 			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
 		}
@@ -460,6 +534,20 @@ func makeDeleteRatingTypeLikertById(s service.RatingService) endpoint.Endpoint {
 	}
 }
 
+func makeRestoreRatingTypeLikertById(s service.RatingService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SoftDeleteRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		msg := s.RestoreRatingTypeLikertById(req.Id)
+		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+	}
+}
+
 func makeRatingTypeLikerts(s service.RatingService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.GetRatingTypeLikertsRequest)
@@ -531,12 +619,26 @@ func makeUpdateRating(s service.RatingService) endpoint.Endpoint {
 
 func makeDeleteRatingById(s service.RatingService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		msg := s.DeleteRating(fmt.Sprint(rqst), fmt.Sprint(jwtObj.Fullname))
+		return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
+	}
+}
+
+func makeRestoreRating(s service.RatingService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SoftDeleteRequest)
+
 		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
 		if jwtMsg.Code != message.SuccessMsg.Code {
 			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
 		}
 
-		msg := s.DeleteRating(fmt.Sprint(rqst))
+		msg := s.RestoreRating(req.Id)
 		return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
 	}
 }
@@ -591,6 +693,7 @@ func makGetListRatingSummary(s service.RatingService, logger log.Logger, db *mon
 func makeGetRatingBySourceTypeAndActor(s service.RatingService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(publicrequest.GetRatingBySourceTypeAndActorRequest)
+		req.Lang = base.GetLanguage(ctx)
 
 		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
 		if jwtMsg.Code != message.SuccessMsg.Code {
@@ -605,36 +708,41 @@ func makeGetRatingBySourceTypeAndActor(s service.RatingService) endpoint.Endpoin
 	}
 }
 
-func makeCreateRatingFormula(s service.RatingService) endpoint.Endpoint {
+func makeCreateRatingFormula(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.SaveRatingFormula)
 
-		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
 		if jwtMsg.Code != message.SuccessMsg.Code {
 			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
 		}
+		req.JWTObj = jwtObj
 
 		result, msg := s.CreateRatingFormula(req)
 		if msg.Code != 212000 {
 			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
 		}
+		refreshFinalRatings(logger, db, req.SourceType)
 		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
 	}
 }
 
-func makeUpdateRatingFormulaById(s service.RatingService) endpoint.Endpoint {
+func makeUpdateRatingFormulaById(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.SaveRatingFormula)
 
-		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
 		if jwtMsg.Code != message.SuccessMsg.Code {
 			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
 		}
+		req.JWTObj = jwtObj
 
+		before, _ := s.GetRatingFormulaById(request.GetRatingFormulaRequest{Id: req.Id})
 		msg := s.UpdateRatingFormula(req)
 		if msg.Code != 212000 {
 			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
 		}
+		refreshFinalRatingsOfFormula(s, logger, db, req.Id, before)
 		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
 	}
 }
@@ -656,19 +764,41 @@ func makeGetRatingFormulaById(s service.RatingService) endpoint.Endpoint {
 	}
 }
 
-func makeDeleteRatingFormulaById(s service.RatingService) endpoint.Endpoint {
+func makeDeleteRatingFormulaById(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.GetRatingFormulaRequest)
 
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		before, _ := s.GetRatingFormulaById(req)
+		msg := s.DeleteRatingFormulaById(req, fmt.Sprint(jwtObj.Fullname))
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		if before != nil {
+			refreshFinalRatings(logger, db, before.SourceType)
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+	}
+}
+
+func makeRestoreRatingFormulaById(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SoftDeleteRequest)
+
 		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
 		if jwtMsg.Code != message.SuccessMsg.Code {
 			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
 		}
 
-		msg := s.DeleteRatingFormulaById(req)
+		msg := s.RestoreRatingFormulaById(req.Id)
 		if msg.Code != 212000 {
 			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
 		}
+		refreshFinalRatingsOfFormula(s, logger, db, req.Id, nil)
 		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
 	}
 }
@@ -690,6 +820,119 @@ func makeRatingFormulas(s service.RatingService) endpoint.Endpoint {
 	}
 }
 
+func makePreviewRatingFormula(s service.RatingService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.PreviewRatingFormulaRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		result, msg := s.PreviewRatingFormula(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeGetRatingFormulaVersions(s service.RatingService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.GetRatingFormulaVersionsRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		result, pagination, msg := s.GetRatingFormulaVersions(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, pagination), nil
+	}
+}
+
+func makeCreateRatingFormulaVersion(s service.RatingService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.CreateRatingFormulaVersionRequest)
+
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+		req.JWTObj = jwtObj
+
+		result, msg := s.CreateRatingFormulaVersion(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeActivateRatingFormulaVersion(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.ActivateRatingFormulaVersionRequest)
+
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+		req.JWTObj = jwtObj
+
+		before, _ := s.GetRatingFormulaById(request.GetRatingFormulaRequest{Id: req.Id})
+		msg := s.ActivateRatingFormulaVersion(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		refreshFinalRatingsOfFormula(s, logger, db, req.Id, before)
+		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+	}
+}
+
+// refreshFinalRatingsOfFormula publishes the final ratings changed by the formula, before is the formula prior to the change
+func refreshFinalRatingsOfFormula(s service.RatingService, logger log.Logger, db *mongo.Database, id string, before *entity.RatingFormulaCol) {
+	sourceTypes := []string{}
+	if before != nil {
+		sourceTypes = append(sourceTypes, before.SourceType)
+	}
+	if after, msg := s.GetRatingFormulaById(request.GetRatingFormulaRequest{Id: id}); msg.Code == message.SuccessMsg.Code && (before == nil || after.SourceType != before.SourceType) {
+		sourceTypes = append(sourceTypes, after.SourceType)
+	}
+	refreshFinalRatings(logger, db, sourceTypes...)
+}
+
+// refreshFinalRatings publishes in background the final ratings of the marketplace source types which changed
+func refreshFinalRatings(logger log.Logger, db *mongo.Database, sourceTypes ...string) {
+	for _, sourceType := range sourceTypes {
+		if !util.StringInSlice(sourceType, global.GetMarketplaceSourceTypeNames()) {
+			continue
+		}
+		ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
+		ratingMp.RepublishFinalRatings(request.RepublishFinalRatingsRequest{SourceType: sourceType, OnlyChanged: true})
+	}
+}
+
+func makeRepublishFinalRatings(logger log.Logger, db *mongo.Database) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.RepublishFinalRatingsRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
+		total, msg := ratingMp.RepublishFinalRatings(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, map[string]int{"total": total}, nil), nil
+	}
+}
+
 func makeCreateRatingSubHelpful(s service.RatingService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.CreateRatingSubHelpfulRequest)
@@ -716,6 +959,33 @@ func makeCreateRatingSubHelpful(s service.RatingService) endpoint.Endpoint {
 	}
 }
 
+func makeCreateRatingSubHelpfulMp(logger log.Logger, db *mongo.Database) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.CreateRatingSubHelpfulRequest)
+
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		// Validate jwtObj User Id
+		if jwtObj.UserIdLegacy != req.UserIDLegacy {
+			msg := message.ErrUserNotFound
+			return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
+		}
+
+		// set user_id_legacy from token jwt
+		req.UserIDLegacy = fmt.Sprintf("%v", jwtObj.UserIdLegacy)
+
+		ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
+		result, msg := ratingMp.CreateRatingSubHelpfulMp(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
 func makeReplyAdminRatingSubmission(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.ReplyAdminRatingSubmissionRequest)
@@ -739,6 +1009,23 @@ func makeReplyAdminRatingSubmission(s service.RatingService, logger log.Logger,
 	}
 }
 
+func makeHideReplyRatingSubmission(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.HideReplyRatingSubmissionRequest)
+
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+		req.JWTObj = jwtObj
+
+		// currently for mp
+		ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
+		msg := ratingMp.HideReplyRatingSubmission(req)
+		return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
+	}
+}
+
 func makeCreateRatingInternal(s service.RatingService) endpoint.Endpoint {
 	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
 		req := rqst.(request.SaveRatingRequest)
@@ -746,3 +1033,56 @@ func makeCreateRatingInternal(s service.RatingService) endpoint.Endpoint {
 		return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
 	}
 }
+
+func makeGetListModerationQueue(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.ListModerationQueueRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		var result interface{}
+		var pagination *base.Pagination
+		var msg message.Message
+		if strings.ToLower(req.Source) == "mp" {
+			ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
+			result, pagination, msg = ratingMp.GetListModerationQueue(req)
+		} else {
+			result, pagination, msg = s.GetListModerationQueue(req)
+		}
+
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, pagination), nil
+	}
+}
+
+func makeModerateRatingSubmission(s service.RatingService, logger log.Logger, db *mongo.Database) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.ModerateRatingSubmissionRequest)
+
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		// set JWTObj to req
+		req.JWTObj = jwtObj
+
+		var msg message.Message
+		if strings.ToLower(req.Source) == "mp" {
+			ratingMp := service.NewRatingMpService(logger, repository.NewRatingMpRepository(db))
+			msg = ratingMp.ModerateRatingSubmission(req)
+		} else {
+			msg = s.ModerateRatingSubmission(req)
+		}
+
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
+	}
+}
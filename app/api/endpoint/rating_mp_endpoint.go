@@ -66,6 +66,10 @@ func makeCreateRatingSubmissionMp(s service.RatingMpService) endpoint.Endpoint {
 		userIdLegacy := fmt.Sprintf("%v", jwtObj.UserIdLegacy)
 		req.UserIDLegacy = &userIdLegacy
 		req.UserID = &userIdLegacy
+		req.AccountCreatedAt = jwtObj.AccountCreatedAt
+		if ip, _ := ctx.Value(base.ClientIPContextKey).(string); req.IPAddress == "" {
+			req.IPAddress = ip
+		}
 
 		result, msg := s.CreateRatingSubmissionMp(ctx, req)
 		if msg.Code != 212000 {
@@ -0,0 +1,62 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+type RatingSubReportEndpoint struct {
+	ReportRatingSubmission  endpoint.Endpoint
+	GetListRatingSubReports endpoint.Endpoint
+}
+
+func MakeRatingSubReportEndpoints(s service.RatingSubReportService) RatingSubReportEndpoint {
+	return RatingSubReportEndpoint{
+		ReportRatingSubmission:  makeReportRatingSubmission(s),
+		GetListRatingSubReports: makeGetListRatingSubReports(s),
+	}
+}
+
+func makeReportRatingSubmission(s service.RatingSubReportService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.ReportRatingSubmissionRequest)
+
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+		// the report of the user of the token
+		req.UserIDLegacy = fmt.Sprintf("%v", jwtObj.UserIdLegacy)
+
+		msg := s.ReportRatingSubmission(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, nil, nil), nil
+	}
+}
+
+func makeGetListRatingSubReports(s service.RatingSubReportService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.ListRatingSubReportsRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		result, pagination, msg := s.GetListRatingSubReports(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, pagination), nil
+	}
+}
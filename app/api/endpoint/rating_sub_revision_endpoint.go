@@ -0,0 +1,40 @@
+package endpoint
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+type RatingSubRevisionEndpoint struct {
+	GetListRatingSubRevisions endpoint.Endpoint
+}
+
+func MakeRatingSubRevisionEndpoints(s service.RatingSubRevisionService) RatingSubRevisionEndpoint {
+	return RatingSubRevisionEndpoint{
+		GetListRatingSubRevisions: makeGetListRatingSubRevisions(s),
+	}
+}
+
+func makeGetListRatingSubRevisions(s service.RatingSubRevisionService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.ListRatingSubRevisionsRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		result, msg := s.GetListRatingSubRevisions(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
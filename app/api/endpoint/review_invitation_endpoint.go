@@ -0,0 +1,62 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+type ReviewInvitationEndpoint struct {
+	CreateReviewInvitations         endpoint.Endpoint
+	GetListPendingReviewInvitations endpoint.Endpoint
+}
+
+func MakeReviewInvitationEndpoints(s service.ReviewInvitationService) ReviewInvitationEndpoint {
+	return ReviewInvitationEndpoint{
+		CreateReviewInvitations:         makeCreateReviewInvitations(s),
+		GetListPendingReviewInvitations: makeGetListPendingReviewInvitations(s),
+	}
+}
+
+func makeCreateReviewInvitations(s service.ReviewInvitationService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.CreateReviewInvitationsRequest)
+
+		_, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+
+		result, msg := s.CreateReviewInvitations(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeGetListPendingReviewInvitations(s service.ReviewInvitationService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.ListPendingReviewInvitationsRequest)
+
+		jwtObj, jwtMsg := global.SetJWTInfoFromContext(ctx)
+		if jwtMsg.Code != message.SuccessMsg.Code {
+			return base.SetHttpResponse(jwtMsg.Code, jwtMsg.Message, nil, nil), nil
+		}
+		// the invitations of the user of the token only
+		req.UserID = fmt.Sprintf("%v", jwtObj.UserIdLegacy)
+
+		result, pagination, msg := s.GetListPendingReviewInvitations(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, pagination), nil
+	}
+}
@@ -0,0 +1,80 @@
+package endpoint
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+type SourceTypeEndpoint struct {
+	GetListSourceTypes endpoint.Endpoint
+	GetSourceTypeById  endpoint.Endpoint
+	CreateSourceType   endpoint.Endpoint
+	UpdateSourceType   endpoint.Endpoint
+	DeleteSourceType   endpoint.Endpoint
+}
+
+func MakeSourceTypeEndpoints(s service.SourceTypeService) SourceTypeEndpoint {
+	return SourceTypeEndpoint{
+		GetListSourceTypes: makeGetListSourceTypes(s),
+		GetSourceTypeById:  makeGetSourceTypeById(s),
+		CreateSourceType:   makeCreateSourceType(s),
+		UpdateSourceType:   makeUpdateSourceType(s),
+		DeleteSourceType:   makeDeleteSourceType(s),
+	}
+}
+
+func makeGetListSourceTypes(s service.SourceTypeService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		result, msg := s.GetListSourceTypes()
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeGetSourceTypeById(s service.SourceTypeService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.GetSourceTypeRequest)
+		result, msg := s.GetSourceTypeById(req.Id)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeCreateSourceType(s service.SourceTypeService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SaveSourceTypeRequest)
+		result, msg := s.CreateSourceType(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeUpdateSourceType(s service.SourceTypeService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SaveSourceTypeRequest)
+		result, msg := s.UpdateSourceType(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeDeleteSourceType(s service.SourceTypeService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.GetSourceTypeRequest)
+		msg := s.DeleteSourceType(req.Id)
+		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+	}
+}
@@ -0,0 +1,82 @@
+package endpoint
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+type SubmissionTagEndpoint struct {
+	GetListSubmissionTags endpoint.Endpoint
+	GetSubmissionTagById  endpoint.Endpoint
+	CreateSubmissionTag   endpoint.Endpoint
+	UpdateSubmissionTag   endpoint.Endpoint
+	DeleteSubmissionTag   endpoint.Endpoint
+}
+
+func MakeSubmissionTagEndpoints(s service.SubmissionTagService) SubmissionTagEndpoint {
+	return SubmissionTagEndpoint{
+		GetListSubmissionTags: makeGetListSubmissionTags(s),
+		GetSubmissionTagById:  makeGetSubmissionTagById(s),
+		CreateSubmissionTag:   makeCreateSubmissionTag(s),
+		UpdateSubmissionTag:   makeUpdateSubmissionTag(s),
+		DeleteSubmissionTag:   makeDeleteSubmissionTag(s),
+	}
+}
+
+func makeGetListSubmissionTags(s service.SubmissionTagService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.ListSubmissionTagsRequest)
+		req.Lang = base.GetLanguage(ctx)
+		result, msg := s.GetListSubmissionTags(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeGetSubmissionTagById(s service.SubmissionTagService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.GetSubmissionTagRequest)
+		result, msg := s.GetSubmissionTagById(req.Id)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeCreateSubmissionTag(s service.SubmissionTagService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SaveSubmissionTagRequest)
+		result, msg := s.CreateSubmissionTag(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeUpdateSubmissionTag(s service.SubmissionTagService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.SaveSubmissionTagRequest)
+		result, msg := s.UpdateSubmissionTag(req)
+		if msg.Code != 212000 {
+			return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+		}
+		return base.SetHttpResponse(msg.Code, msg.Message, result, nil), nil
+	}
+}
+
+func makeDeleteSubmissionTag(s service.SubmissionTagService) endpoint.Endpoint {
+	return func(ctx context.Context, rqst interface{}) (resp interface{}, err error) {
+		req := rqst.(request.GetSubmissionTagRequest)
+		msg := s.DeleteSubmissionTag(req.Id)
+		return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), nil
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"go-klikdokter/helper/_struct"
 	"go-klikdokter/helper/config"
 	"go-klikdokter/helper/database"
+	util_ratelimit "go-klikdokter/pkg/util/ratelimit"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -53,6 +54,7 @@ func InitRouting(db *mongo.Database, logger log.Logger) *http.ServeMux {
 	nsm.Handle("/", swagHttp) // don't delete or change this!!
 	nsm.HandleFunc("/__kdhealth", func(writer http.ResponseWriter, request *http.Request) { writer.Write([]byte("OK")) })
 	nsm.Handle(_struct.PrefixBase+"/", globalHttp)
+	nsm.Handle("/dapr/subscribe", globalHttp)
 
 	return nsm
 }
@@ -63,9 +65,18 @@ func GlobalHttpHandler(logger log.Logger, db *mongo.Database) http.Handler {
 	publicRatingSvc := registry.RegisterPublicRatingService(db, logger)
 	publicRatingMpSvc := registry.RegisterPublicRatingMpService(db, logger)
 	daprSvc := registry.RegisterDaprService(db, logger)
+	outboxSvc := registry.RegisterOutboxService(db, logger)
+	reviewInvitationSvc := registry.RegisterReviewInvitationService(db, logger)
+	ratingSubReportSvc := registry.RegisterRatingSubReportService(db, logger)
+	ratingSubRevisionSvc := registry.RegisterRatingSubRevisionService(db, logger)
+	sourceTypeSvc := registry.RegisterSourceTypeService(db, logger)
+	submissionTagSvc := registry.RegisterSubmissionTagService(db, logger)
 	// ratingMpSvc := registry.RegisterRatingMpService(db, logger)
 	updloadImgSvc := registry.RegisterUploadService(db, logger)
 
+	// rate limited routes share their hits across the replicas when rate-limit.store is mongo
+	util_ratelimit.UseStore(util_ratelimit.NewStore(db))
+
 	pr := mux.NewRouter()
 
 	// ratingMpHttp := transport.RatingMpHttpHandler(ratingMpSvc, log.With(logger, "RatingMpTransportLayer", "HTTP"))
@@ -73,14 +84,28 @@ func GlobalHttpHandler(logger log.Logger, db *mongo.Database) http.Handler {
 	publicRatingMpHttp := publictransport.PublicRatingMpHttpHandler(publicRatingMpSvc, log.With(logger, "PublicRatingMpTransportLayer", "HTTP"))
 	publicRatingHttp := publictransport.PublicRatingHttpHandler(publicRatingSvc, log.With(logger, "PublicRatingTransportLayer", "HTTP"), db)
 	daprHttp := transport.DaprHttpHandler(daprSvc, log.With(logger, "DaprTransportLayer", "HTTP"))
+	outboxHttp := transport.OutboxHttpHandler(outboxSvc, log.With(logger, "OutboxTransportLayer", "HTTP"))
+	reviewInvitationHttp := transport.ReviewInvitationHttpHandler(reviewInvitationSvc, log.With(logger, "ReviewInvitationTransportLayer", "HTTP"))
+	ratingSubReportHttp := transport.RatingSubReportHttpHandler(ratingSubReportSvc, log.With(logger, "RatingSubReportTransportLayer", "HTTP"))
+	ratingSubRevisionHttp := transport.RatingSubRevisionHttpHandler(ratingSubRevisionSvc, log.With(logger, "RatingSubRevisionTransportLayer", "HTTP"))
+	sourceTypeHttp := transport.SourceTypeHttpHandler(sourceTypeSvc, log.With(logger, "SourceTypeTransportLayer", "HTTP"))
+	submissionTagHttp := transport.SubmissionTagHttpHandler(submissionTagSvc, log.With(logger, "SubmissionTagTransportLayer", "HTTP"))
 	uploadHttp := transport.UploadHttpHandler(updloadImgSvc, log.With(logger, "UploadTransportLayer", "HTTP"))
 
 	pr.PathPrefix(_struct.PrefixBase + "/public/rating-submissions-by-id").Handler(publicRatingMpHttp)
 	pr.PathPrefix(_struct.PrefixBase + "/public/ratings-summary/store-product").Handler(publicRatingMpHttp)
 	// pr.PathPrefix(_struct.PrefixBase + "/public/ratings-summary-mp").Handler(publicRatingMpHttp)
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/public/rating-submissions/{id}/report").Handler(ratingSubReportHttp)
 	pr.PathPrefix(_struct.PrefixBase + "/public/rating-submissions").Handler(publicRatingHttp)
 	pr.PathPrefix(_struct.PrefixBase + "/public/ratings-summary").Handler(publicRatingHttp)
 	pr.PathPrefix(_struct.PrefixBase + "/dapr").Handler(daprHttp)
+	pr.Path("/dapr/subscribe").Handler(daprHttp)
+	pr.PathPrefix(_struct.PrefixBase + "/outbox").Handler(outboxHttp)
+	pr.PathPrefix(_struct.PrefixBase + "/review-invitations").Handler(reviewInvitationHttp)
+	pr.PathPrefix(_struct.PrefixBase + "/moderation/rating-submission-reports").Handler(ratingSubReportHttp)
+	pr.PathPrefix(_struct.PrefixBase + "/moderation/rating-submission-revisions").Handler(ratingSubRevisionHttp)
+	pr.PathPrefix(_struct.PrefixBase + "/source-types").Handler(sourceTypeHttp)
+	pr.PathPrefix(_struct.PrefixBase + "/submission-tags").Handler(submissionTagHttp)
 	pr.PathPrefix(_struct.PrefixBase + "/upload/").Handler(uploadHttp) // for upload images
 	// pr.PathPrefix(_struct.PrefixBase + "/rating-submissions-mp").Handler(ratingMpHttp)
 	// pr.PathPrefix(_struct.PrefixBase + "/ratings-summary-mp").Handler(ratingMpHttp)
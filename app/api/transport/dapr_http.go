@@ -3,12 +3,16 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/schema"
 	"go-klikdokter/app/api/endpoint"
+	"go-klikdokter/app/middleware"
 	"go-klikdokter/app/model/base/encoder"
 	request_dapr "go-klikdokter/app/model/request/dapr"
+	"go-klikdokter/app/model/response"
 	"go-klikdokter/app/service"
 	"go-klikdokter/helper/_struct"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/go-kit/kit/auth/jwt"
@@ -41,6 +45,43 @@ func DaprHttpHandler(s service.DaprService, logger log.Logger) http.Handler {
 		options...,
 	))
 
+	// dapr discovers the subscriptions of the app at /dapr/subscribe, without the route prefix
+	pr.Methods(http.MethodGet).Path("/dapr/subscribe").Handler(httptransport.NewServer(
+		ep.Subscribe,
+		decodeDaprSubscribe,
+		encodeDaprResponse,
+		options...,
+	))
+
+	// a body which cannot be read is answered with DROP, dapr retries any non 200 response.
+	// The subscribers only accept the calls of the dapr sidecar, authenticated by its dapr-api-token
+	subscriberOptions := []httptransport.ServerOption{
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorEncoder(encodeDaprSubscriberError),
+		httptransport.ServerBefore(middleware.DaprAPITokenToContext()),
+	}
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/dapr/subscriber/order-completed").Handler(httptransport.NewServer(
+		middleware.DaprAPITokenAuthentication(logger)(ep.SubscriberOrderCompleted),
+		decodeDaprCloudEvent,
+		encodeDaprResponse,
+		subscriberOptions...,
+	))
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/dapr/subscriber/order-refunded").Handler(httptransport.NewServer(
+		middleware.DaprAPITokenAuthentication(logger)(ep.SubscriberOrderRefunded),
+		decodeDaprCloudEvent,
+		encodeDaprResponse,
+		subscriberOptions...,
+	))
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/dapr/subscriber/user-profile-updated").Handler(httptransport.NewServer(
+		middleware.DaprAPITokenAuthentication(logger)(ep.SubscriberUserProfileUpdated),
+		decodeDaprCloudEvent,
+		encodeDaprResponse,
+		subscriberOptions...,
+	))
+
 	return pr
 }
 
@@ -66,3 +107,32 @@ func decodeDaprSubscriberRatingsubcol(ctx context.Context, r *http.Request) (rqs
 
 	return req, nil
 }
+
+func decodeDaprSubscribe(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	return _struct.PrefixBase + "/dapr/subscriber", nil
+}
+
+func decodeDaprCloudEvent(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return request_dapr.ParseCloudEvent(body)
+}
+
+// encodeDaprResponse writes the response as is, dapr does not read the meta wrapper
+func encodeDaprResponse(ctx context.Context, w http.ResponseWriter, resp interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func encodeDaprSubscriberError(ctx context.Context, err error, w http.ResponseWriter) {
+	if errors.Is(err, middleware.ErrDaprAPIToken) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(response.DaprSubscriberResponse{Status: response.DaprStatusRetry})
+		return
+	}
+	_ = encodeDaprResponse(ctx, w, response.DaprSubscriberResponse{Status: response.DaprStatusDrop})
+}
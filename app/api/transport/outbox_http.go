@@ -0,0 +1,73 @@
+package transport
+
+import (
+	"context"
+	"go-klikdokter/app/api/endpoint"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
+	"net/http"
+
+	"github.com/go-kit/kit/auth/jwt"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/schema"
+)
+
+func OutboxHttpHandler(s service.OutboxService, logger log.Logger) http.Handler {
+	pr := mux.NewRouter()
+
+	ep := endpoint.MakeOutboxEndpoints(s)
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
+		httptransport.ServerBefore(jwt.HTTPToContext()),
+	}
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/outbox").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyOutboxRead)(ep.GetOutboxMessages),
+		decodeGetOutboxMessages,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/outbox/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyOutboxRead)(ep.GetOutboxMessageById),
+		decodeOutboxMessage,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/outbox/{id}/replay").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyOutboxReplay)(ep.ReplayOutboxMessage),
+		decodeOutboxMessage,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	return pr
+}
+
+func decodeGetOutboxMessages(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var params request.GetOutboxMessagesRequest
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if err = schema.NewDecoder().Decode(&params, r.Form); err != nil {
+		return nil, err
+	}
+	err = params.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func decodeOutboxMessage(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	return request.OutboxMessageRequest{Id: mux.Vars(r)["id"]}, nil
+}
@@ -3,6 +3,7 @@ package publictransport
 import (
 	"context"
 	publicendpoint "go-klikdokter/app/api/endpoint/public"
+	"go-klikdokter/app/middleware"
 	"go-klikdokter/app/model/base/encoder"
 	"go-klikdokter/app/model/request/public"
 	"go-klikdokter/app/service/public"
@@ -10,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
 
+	"github.com/go-kit/kit/auth/jwt"
 	httptransport "github.com/go-kit/kit/transport/http"
 	"github.com/go-kit/log"
 	"github.com/gorilla/mux"
@@ -23,6 +25,7 @@ func PublicRatingHttpHandler(s publicservice.PublicRatingService, logger log.Log
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorLogger(logger),
 		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
 	}
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/public/ratings-summary/detail/{source_type}").Handler(httptransport.NewServer(
@@ -40,10 +43,10 @@ func PublicRatingHttpHandler(s publicservice.PublicRatingService, logger log.Log
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/public/rating-submissions/{source_type}/{source_uid}").Handler(httptransport.NewServer(
-		ep.GetListRatingSubmissionBySourceTypeAndUID,
+		middleware.OptionalJWTAuthentication(logger)(ep.GetListRatingSubmissionBySourceTypeAndUID),
 		decodeGetRatingSubmissionBySourceTypeAndUID,
 		encoder.EncodeResponseHTTP,
-		options...,
+		append(options, httptransport.ServerBefore(jwt.HTTPToContext()))...,
 	))
 
 	return pr
@@ -75,6 +78,10 @@ func decodeGetRatingSubmissionBySourceTypeAndUID(ctx context.Context, r *http.Re
 	if err != nil {
 		return nil, err
 	}
+	err = params.ValidateSort()
+	if err != nil {
+		return nil, err
+	}
 	return params, nil
 }
 
@@ -13,6 +13,7 @@ import (
 
 	"go-klikdokter/app/middleware"
 
+	"github.com/go-kit/kit/auth/jwt"
 	httptransport "github.com/go-kit/kit/transport/http"
 	"github.com/go-kit/log"
 	"github.com/gorilla/mux"
@@ -25,6 +26,7 @@ func PublicRatingMpHttpHandler(s publicservice.PublicRatingMpService, logger log
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorLogger(logger),
 		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
 	}
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/public/ratings-summary-mp/{source_type}").Handler(httptransport.NewServer(
 		ep.GetListRatingSummaryBySourceType,
@@ -34,17 +36,17 @@ func PublicRatingMpHttpHandler(s publicservice.PublicRatingMpService, logger log
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/public/rating-submissions-mp/{source_type}/{source_uid}").Handler(httptransport.NewServer(
-		ep.GetListRatingSubmissionBySourceTypeAndUID,
+		middleware.OptionalJWTAuthentication(logger)(ep.GetListRatingSubmissionBySourceTypeAndUID),
 		decodeGetRatingSubmissionMpBySourceTypeAndUID,
 		encoder.EncodeResponseHTTP,
-		options...,
+		append(options, httptransport.ServerBefore(jwt.HTTPToContext()))...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/public/rating-submissions-by-id").Handler(httptransport.NewServer(
-		ep.GetListRatingSubmissionByID,
+		middleware.OptionalJWTAuthentication(logger)(ep.GetListRatingSubmissionByID),
 		decodeGetRatingSubmissionByID,
 		encoder.EncodeResponseHTTPWithCorrelationID,
-		append(options, httptransport.ServerBefore(middleware.CorrelationIdToContext()))...,
+		append(options, httptransport.ServerBefore(middleware.CorrelationIdToContext(), jwt.HTTPToContext()))...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/public/ratings-summary/store-product").Handler(httptransport.NewServer(
@@ -83,6 +85,10 @@ func decodeGetRatingSubmissionMpBySourceTypeAndUID(ctx context.Context, r *http.
 	if err != nil {
 		return nil, err
 	}
+	err = params.ValidateSort()
+	if err != nil {
+		return nil, err
+	}
 	return params, nil
 }
 
@@ -10,7 +10,9 @@ import (
 	publicrequest "go-klikdokter/app/model/request/public"
 	"go-klikdokter/app/service"
 	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
 	"net/http"
+	"strconv"
 
 	"go.mongodb.org/mongo-driver/mongo"
 
@@ -29,235 +31,334 @@ func RatingHttpHandler(s service.RatingService, logger log.Logger, db *mongo.Dat
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorLogger(logger),
 		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
 		httptransport.ServerBefore(jwt.HTTPToContext()),
 	}
 
 	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/rating-types-numeric/").Handler(httptransport.NewServer(
-		ep.CreateRatingTypeNum,
+		middleware.Secured(logger, global.PolicyRatingTypeWrite)(ep.CreateRatingTypeNum),
 		decodeCreateRatingTypeNum,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-types-numeric/{id}").Handler(httptransport.NewServer(
-		ep.GetRatingTypeNumById,
+		middleware.Secured(logger, global.PolicyRatingTypeRead)(ep.GetRatingTypeNumById),
 		decodeGetRatingById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-types-numeric/{id}").Handler(httptransport.NewServer(
-		ep.UpdateRatingById,
+		middleware.Secured(logger, global.PolicyRatingTypeWrite)(ep.UpdateRatingById),
 		decodeUpdateRatingById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodDelete).Path(_struct.PrefixBase + "/rating-types-numeric/{id}").Handler(httptransport.NewServer(
-		ep.DeleteRatingTypeNumById,
+		middleware.Secured(logger, global.PolicyRatingTypeWrite)(ep.DeleteRatingTypeNumById),
 		decodeGetRatingById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-types-numeric/{id}/restore").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyRatingTypeWrite)(ep.RestoreRatingTypeNumById),
+		decodeSoftDeleteRequest,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-types-numeric").Handler(httptransport.NewServer(
-		ep.GetRatingTypeNums,
+		middleware.Secured(logger, global.PolicyRatingTypeRead)(ep.GetRatingTypeNums),
 		decodeGetRatingTypeNums,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/rating-submissions/").Handler(httptransport.NewServer(
-		ep.CreateRatingSubmission,
+		middleware.SecuredRateLimited(logger, global.PolicySubmissionCreate, middleware.RateLimitSubmissionCreate)(ep.CreateRatingSubmission),
 		decodeCreateRatingSubmission,
 		encoder.EncodeResponseHTTPWithCorrelationID,
-		append(options, httptransport.ServerBefore(middleware.CorrelationIdToContext()))...,
+		append(options, httptransport.ServerBefore(middleware.CorrelationIdToContext(), middleware.ClientIPToContext()))...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-submissions").Handler(httptransport.NewServer(
-		ep.GetListRatingSubmission,
+		middleware.Secured(logger, global.PolicySubmissionList)(ep.GetListRatingSubmission),
 		decodeGetListRatingSubmission,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-submissions/{id}").Handler(httptransport.NewServer(
-		ep.UpdateRatingSubmission,
+		middleware.Secured(logger, global.PolicySubmissionUpdate)(ep.UpdateRatingSubmission),
 		decodeUpdateRatingSubmission,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-submissions/{id}").Handler(httptransport.NewServer(
-		ep.GetRatingSubmission,
+		middleware.Secured(logger, global.PolicySubmissionRead)(ep.GetRatingSubmission),
 		decodeGetById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodDelete).Path(_struct.PrefixBase + "/rating-submissions/{id}").Handler(httptransport.NewServer(
-		ep.DeleteRatingSubmission,
-		decodeGetById,
+		middleware.Secured(logger, global.PolicySubmissionDelete)(ep.DeleteRatingSubmission),
+		decodeSoftDeleteRequest,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-submissions/{id}/restore").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionDelete)(ep.RestoreRatingSubmission),
+		decodeSoftDeleteRequest,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/cancel/rating-submissions").Handler(httptransport.NewServer(
-		ep.CancelRatingSubByIds,
+		middleware.Secured(logger, global.PolicySubmissionCancel)(ep.CancelRatingSubByIds),
 		decodeCancelRatingSub,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/list-rating-submissions/{source_type}/{source_uid}/{user_id_legacy}").Handler(httptransport.NewServer(
-		ep.GetListRatingSubmissionWithUserIdLegacy,
+		middleware.Secured(logger, global.PolicySubmissionRead)(ep.GetListRatingSubmissionWithUserIdLegacy),
 		decodeGetRatingSubmissionWithUserIdLegacy,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-submissions/user-id-legacy/{user_id_legacy}").Handler(httptransport.NewServer(
-		ep.UpdateRatingSubDisplayNameByIdLegacy,
+		middleware.Secured(logger, global.PolicySubmissionDisplayName)(ep.UpdateRatingSubDisplayNameByIdLegacy),
 		decodeUpdatePublicRatingSubDisplayName,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-submissions/reply/{id}").Handler(httptransport.NewServer(
-		ep.ReplyAdminRatingSubmission,
+		middleware.Secured(logger, global.PolicySubmissionReply)(ep.ReplyAdminRatingSubmission),
 		decodeReplyAdminRatingSubmission,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-submissions/reply/{id}/hide").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionReplyHide)(ep.HideReplyRatingSubmission),
+		decodeHideReplyRatingSubmission,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/moderation/rating-submissions").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionModerate)(ep.GetListModerationQueue),
+		decodeGetListModerationQueue,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/moderation/rating-submissions/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionModerate)(ep.ModerateRatingSubmission),
+		decodeModerateRatingSubmission,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
 	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/rating-types-likert/").Handler(httptransport.NewServer(
-		ep.CreateRatingTypeLikert,
+		middleware.Secured(logger, global.PolicyRatingTypeWrite)(ep.CreateRatingTypeLikert),
 		decodeCreateRatingTypeLikert,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-types-likert/{id}").Handler(httptransport.NewServer(
-		ep.GetRatingTypeLikertById,
+		middleware.Secured(logger, global.PolicyRatingTypeRead)(ep.GetRatingTypeLikertById),
 		decodeGetRatingTypeLikertById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-types-likert/{id}").Handler(httptransport.NewServer(
-		ep.UpdateRatingTypeLikertById,
+		middleware.Secured(logger, global.PolicyRatingTypeWrite)(ep.UpdateRatingTypeLikertById),
 		decodeUpdateRatingTypeLikertById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodDelete).Path(_struct.PrefixBase + "/rating-types-likert/{id}").Handler(httptransport.NewServer(
-		ep.DeleteRatingTypeLikertById,
+		middleware.Secured(logger, global.PolicyRatingTypeWrite)(ep.DeleteRatingTypeLikertById),
 		decodeGetRatingTypeLikertById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-types-likert/{id}/restore").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyRatingTypeWrite)(ep.RestoreRatingTypeLikertById),
+		decodeSoftDeleteRequest,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-types-likert").Handler(httptransport.NewServer(
-		ep.GetRatingTypeLikerts,
+		middleware.Secured(logger, global.PolicyRatingTypeRead)(ep.GetRatingTypeLikerts),
 		decodeRatingTypeLikerts,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/ratings/").Handler(httptransport.NewServer(
-		ep.CreateRating,
+		middleware.Secured(logger, global.PolicyRatingWrite)(ep.CreateRating),
 		decodeCreateRating,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/ratings/summary/{source_type}").Handler(httptransport.NewServer(
-		ep.GetListRatingSummary,
+		middleware.Secured(logger, global.PolicyRatingRead)(ep.GetListRatingSummary),
 		decodeGetRatingSummary,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/ratings/{id}").Handler(httptransport.NewServer(
-		ep.ShowRating,
+		middleware.Secured(logger, global.PolicyRatingRead)(ep.ShowRating),
 		decodeGetById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/ratings/{id}").Handler(httptransport.NewServer(
-		ep.UpdateRating,
+		middleware.Secured(logger, global.PolicyRatingWrite)(ep.UpdateRating),
 		decodeEditRatingById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodDelete).Path(_struct.PrefixBase + "/ratings/{id}").Handler(httptransport.NewServer(
-		ep.DeleteRating,
+		middleware.Secured(logger, global.PolicyRatingWrite)(ep.DeleteRating),
 		decodeGetById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/ratings/{id}/restore").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyRatingWrite)(ep.RestoreRating),
+		decodeSoftDeleteRequest,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/ratings").Handler(httptransport.NewServer(
-		ep.GetRatings,
+		middleware.Secured(logger, global.PolicyRatingRead)(ep.GetRatings),
 		decodeGetRatings,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/list-ratings/{source_type}/{source_uid}").Handler(httptransport.NewServer(
-		ep.GetRatingBySourceTypeAndActor,
+		middleware.Secured(logger, global.PolicyRatingRead)(ep.GetRatingBySourceTypeAndActor),
 		decodeGetRatingBySourceTypeAndActor,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/rating-formula/").Handler(httptransport.NewServer(
-		ep.CreateRatingFormula,
+		middleware.Secured(logger, global.PolicyFormulaWrite)(ep.CreateRatingFormula),
 		decodeCreateRatingFormula,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-formula").Handler(httptransport.NewServer(
-		ep.GetRatingFormulas,
+		middleware.Secured(logger, global.PolicyFormulaRead)(ep.GetRatingFormulas),
 		decodeGetRatingFormulas,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/rating-formula/preview").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyFormulaRead)(ep.PreviewRatingFormula),
+		decodePreviewRatingFormula,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-formula/{id}/versions").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyFormulaRead)(ep.GetRatingFormulaVersions),
+		decodeGetRatingFormulaVersions,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/rating-formula/{id}/versions").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyFormulaWrite)(ep.CreateRatingFormulaVersion),
+		decodeCreateRatingFormulaVersion,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-formula/{id}/versions/{version}/activate").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyFormulaWrite)(ep.ActivateRatingFormulaVersion),
+		decodeActivateRatingFormulaVersion,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-formula/{id}").Handler(httptransport.NewServer(
-		ep.GetRatingFormulaById,
+		middleware.Secured(logger, global.PolicyFormulaRead)(ep.GetRatingFormulaById),
 		decodeGetRatingFormulaById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-formula/{id}").Handler(httptransport.NewServer(
-		ep.UpdateRatingFormulaById,
+		middleware.Secured(logger, global.PolicyFormulaWrite)(ep.UpdateRatingFormulaById),
 		decodeUpdateRatingFormulaById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodDelete).Path(_struct.PrefixBase + "/rating-formula/{id}").Handler(httptransport.NewServer(
-		ep.DeleteRatingFormulaById,
+		middleware.Secured(logger, global.PolicyFormulaWrite)(ep.DeleteRatingFormulaById),
 		decodeDeleteRatingFormulaById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/rating-formula/{id}/restore").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyFormulaWrite)(ep.RestoreRatingFormulaById),
+		decodeSoftDeleteRequest,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/final-rating/republish").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyFinalRatingRepublish)(ep.RepublishFinalRatings),
+		decodeRepublishFinalRatings,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
 	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/helpful-rating-submission/").Handler(httptransport.NewServer(
-		ep.CreateRatingSubHelpful,
+		middleware.SecuredRateLimited(logger, global.PolicyHelpful, middleware.RateLimitHelpful)(ep.CreateRatingSubHelpful),
 		decodeCreateRatingSubHelpful,
 		encoder.EncodeResponseHTTP,
-		options...,
+		append(options, httptransport.ServerBefore(middleware.ClientIPToContext()))...,
+	))
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/helpful-rating-submission-mp/").Handler(httptransport.NewServer(
+		middleware.SecuredRateLimited(logger, global.PolicyHelpful, middleware.RateLimitHelpfulMp)(ep.CreateRatingSubHelpfulMp),
+		decodeCreateRatingSubHelpful,
+		encoder.EncodeResponseHTTP,
+		append(options, httptransport.ServerBefore(middleware.ClientIPToContext()))...,
 	))
 
 	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/internal/rating").Handler(httptransport.NewServer(
-		ep.CreateRatingInternal,
+		middleware.Secured(logger, global.PolicyInternalRating)(ep.CreateRatingInternal),
 		decodeCreateRating,
 		encoder.EncodeResponseHTTP,
 		options...,
@@ -492,6 +593,18 @@ func decodeDeleteRatingFormulaById(ctx context.Context, r *http.Request) (rqst i
 	return req, nil
 }
 
+func decodeSoftDeleteRequest(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.SoftDeleteRequest
+	if err = r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if err = schema.NewDecoder().Decode(&req, r.Form); err != nil {
+		return nil, err
+	}
+	req.Id = mux.Vars(r)["id"]
+	return req, nil
+}
+
 func decodeUpdateRatingFormulaById(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
 	var req request.SaveRatingFormula
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -504,6 +617,65 @@ func decodeUpdateRatingFormulaById(ctx context.Context, r *http.Request) (rqst i
 	return req, nil
 }
 
+func decodePreviewRatingFormula(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.PreviewRatingFormulaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeGetRatingFormulaVersions(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var params request.GetRatingFormulaVersionsRequest
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if err = schema.NewDecoder().Decode(&params, r.Form); err != nil {
+		return nil, err
+	}
+	params.Id = mux.Vars(r)["id"]
+	return params, nil
+}
+
+func decodeCreateRatingFormulaVersion(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.CreateRatingFormulaVersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.Id = mux.Vars(r)["id"]
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeActivateRatingFormulaVersion(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.ActivateRatingFormulaVersionRequest
+	req.Id = mux.Vars(r)["id"]
+	req.Version, err = strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeRepublishFinalRatings(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.RepublishFinalRatingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
 func decodeGetRatingBySourceTypeAndActor(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
 	var req publicrequest.GetRatingBySourceTypeAndActorRequest
 
@@ -585,3 +757,49 @@ func decodeReplyAdminRatingSubmission(ctx context.Context, r *http.Request) (rqs
 
 	return req, nil
 }
+
+func decodeGetListModerationQueue(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var params request.ListModerationQueueRequest
+
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	if err = schema.NewDecoder().Decode(&params, r.Form); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func decodeModerateRatingSubmission(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.ModerateRatingSubmissionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ID = mux.Vars(r)["id"]
+
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func decodeHideReplyRatingSubmission(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.HideReplyRatingSubmissionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ID = mux.Vars(r)["id"]
+
+	err = req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
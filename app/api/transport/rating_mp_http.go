@@ -9,6 +9,7 @@ import (
 	"go-klikdokter/app/model/request"
 	"go-klikdokter/app/service"
 	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
 	"net/http"
 
 	"github.com/gorilla/schema"
@@ -26,32 +27,33 @@ func RatingMpHttpHandler(s service.RatingMpService, logger log.Logger) http.Hand
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorLogger(logger),
 		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
 		httptransport.ServerBefore(jwt.HTTPToContext()),
 	}
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-submissions-mp").Handler(httptransport.NewServer(
-		ep.GetListRatingSubmission,
+		middleware.Secured(logger, global.PolicySubmissionList)(ep.GetListRatingSubmission),
 		decodeGetListRatingSubmissionMp,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/rating-submissions-mp").Handler(httptransport.NewServer(
-		ep.CreateRatingSubmission,
+		middleware.SecuredRateLimited(logger, global.PolicySubmissionCreate, middleware.RateLimitSubmissionCreateMp)(ep.CreateRatingSubmission),
 		decodeCreateRatingSubmissionMp,
 		encoder.EncodeResponseHTTP,
-		append(options, httptransport.ServerBefore(middleware.CorrelationIdToContext()))...,
+		append(options, httptransport.ServerBefore(middleware.CorrelationIdToContext(), middleware.ClientIPToContext()))...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/rating-submissions-mp/{id}").Handler(httptransport.NewServer(
-		ep.GetRatingSubmission,
+		middleware.Secured(logger, global.PolicySubmissionRead)(ep.GetRatingSubmission),
 		decodeGetById,
 		encoder.EncodeResponseHTTP,
 		options...,
 	))
 
 	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/ratings-summary-mp/{source_type}").Handler(httptransport.NewServer(
-		ep.GetListRatingSummaryBySourceType,
+		middleware.Secured(logger, global.PolicyRatingRead)(ep.GetListRatingSummaryBySourceType),
 		decodeGetRatingSummaryMpBySourceType,
 		encoder.EncodeResponseHTTP,
 		options...,
@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"go-klikdokter/app/api/endpoint"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
+	"net/http"
+
+	"github.com/go-kit/kit/auth/jwt"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/schema"
+)
+
+func RatingSubReportHttpHandler(s service.RatingSubReportService, logger log.Logger) http.Handler {
+	pr := mux.NewRouter()
+
+	ep := endpoint.MakeRatingSubReportEndpoints(s)
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
+		httptransport.ServerBefore(jwt.HTTPToContext()),
+	}
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/public/rating-submissions/{id}/report").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionReport)(ep.ReportRatingSubmission),
+		decodeReportRatingSubmission,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/moderation/rating-submission-reports").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionModerate)(ep.GetListRatingSubReports),
+		decodeListRatingSubReports,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	return pr
+}
+
+func decodeReportRatingSubmission(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.ReportRatingSubmissionRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.ID = mux.Vars(r)["id"]
+	req.IPAddress = middleware.GetIP(r)
+	req.UserAgent = r.UserAgent()
+
+	if err = req.Validate(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeListRatingSubReports(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var params request.ListRatingSubReportsRequest
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if err = schema.NewDecoder().Decode(&params, r.Form); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
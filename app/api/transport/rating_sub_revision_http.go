@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"context"
+	"go-klikdokter/app/api/endpoint"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
+	"net/http"
+
+	"github.com/go-kit/kit/auth/jwt"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/schema"
+)
+
+func RatingSubRevisionHttpHandler(s service.RatingSubRevisionService, logger log.Logger) http.Handler {
+	pr := mux.NewRouter()
+
+	ep := endpoint.MakeRatingSubRevisionEndpoints(s)
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
+		httptransport.ServerBefore(jwt.HTTPToContext()),
+	}
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/moderation/rating-submission-revisions/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionModerate)(ep.GetListRatingSubRevisions),
+		decodeListRatingSubRevisions,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	return pr
+}
+
+func decodeListRatingSubRevisions(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var params request.ListRatingSubRevisionsRequest
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if err = schema.NewDecoder().Decode(&params, r.Form); err != nil {
+		return nil, err
+	}
+	params.ID = mux.Vars(r)["id"]
+	return params, nil
+}
@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"go-klikdokter/app/api/endpoint"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
+	"net/http"
+
+	"github.com/go-kit/kit/auth/jwt"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/schema"
+)
+
+func ReviewInvitationHttpHandler(s service.ReviewInvitationService, logger log.Logger) http.Handler {
+	pr := mux.NewRouter()
+
+	ep := endpoint.MakeReviewInvitationEndpoints(s)
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
+		httptransport.ServerBefore(jwt.HTTPToContext()),
+	}
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/review-invitations").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyReviewInvitationCreate)(ep.CreateReviewInvitations),
+		decodeCreateReviewInvitations,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/review-invitations/pending").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicyReviewInvitationList)(ep.GetListPendingReviewInvitations),
+		decodeListPendingReviewInvitations,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	return pr
+}
+
+func decodeCreateReviewInvitations(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.CreateReviewInvitationsRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeListPendingReviewInvitations(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var params request.ListPendingReviewInvitationsRequest
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if err = schema.NewDecoder().Decode(&params, r.Form); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
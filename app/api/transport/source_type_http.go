@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"go-klikdokter/app/api/endpoint"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
+	"net/http"
+
+	"github.com/go-kit/kit/auth/jwt"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+)
+
+func SourceTypeHttpHandler(s service.SourceTypeService, logger log.Logger) http.Handler {
+	pr := mux.NewRouter()
+
+	ep := endpoint.MakeSourceTypeEndpoints(s)
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
+		httptransport.ServerBefore(jwt.HTTPToContext()),
+	}
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/source-types").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySourceTypeRead)(ep.GetListSourceTypes),
+		decodeListSourceTypes,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/source-types/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySourceTypeRead)(ep.GetSourceTypeById),
+		decodeSourceTypeById,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/source-types").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySourceTypeWrite)(ep.CreateSourceType),
+		decodeSaveSourceType,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/source-types/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySourceTypeWrite)(ep.UpdateSourceType),
+		decodeSaveSourceType,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodDelete).Path(_struct.PrefixBase + "/source-types/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySourceTypeWrite)(ep.DeleteSourceType),
+		decodeSourceTypeById,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	return pr
+}
+
+func decodeListSourceTypes(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	return nil, nil
+}
+
+func decodeSourceTypeById(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	return request.GetSourceTypeRequest{Id: mux.Vars(r)["id"]}, nil
+}
+
+func decodeSaveSourceType(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.SaveSourceTypeRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.Id = mux.Vars(r)["id"]
+	return req, nil
+}
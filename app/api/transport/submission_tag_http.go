@@ -0,0 +1,93 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"go-klikdokter/app/api/endpoint"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
+	"net/http"
+
+	"github.com/go-kit/kit/auth/jwt"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/schema"
+)
+
+func SubmissionTagHttpHandler(s service.SubmissionTagService, logger log.Logger) http.Handler {
+	pr := mux.NewRouter()
+
+	ep := endpoint.MakeSubmissionTagEndpoints(s)
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
+		httptransport.ServerBefore(jwt.HTTPToContext()),
+	}
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/submission-tags").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionTagRead)(ep.GetListSubmissionTags),
+		decodeListSubmissionTags,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodGet).Path(_struct.PrefixBase + "/submission-tags/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionTagRead)(ep.GetSubmissionTagById),
+		decodeSubmissionTagById,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPost).Path(_struct.PrefixBase + "/submission-tags").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionTagWrite)(ep.CreateSubmissionTag),
+		decodeSaveSubmissionTag,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodPut).Path(_struct.PrefixBase + "/submission-tags/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionTagWrite)(ep.UpdateSubmissionTag),
+		decodeSaveSubmissionTag,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	pr.Methods(http.MethodDelete).Path(_struct.PrefixBase + "/submission-tags/{id}").Handler(httptransport.NewServer(
+		middleware.Secured(logger, global.PolicySubmissionTagWrite)(ep.DeleteSubmissionTag),
+		decodeSubmissionTagById,
+		encoder.EncodeResponseHTTP,
+		options...,
+	))
+
+	return pr
+}
+
+func decodeListSubmissionTags(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var params request.ListSubmissionTagsRequest
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if err = schema.NewDecoder().Decode(&params, r.Form); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func decodeSubmissionTagById(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	return request.GetSubmissionTagRequest{Id: mux.Vars(r)["id"]}, nil
+}
+
+func decodeSaveSubmissionTag(ctx context.Context, r *http.Request) (rqst interface{}, err error) {
+	var req request.SaveSubmissionTagRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.Id = mux.Vars(r)["id"]
+	return req, nil
+}
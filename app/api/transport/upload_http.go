@@ -28,6 +28,7 @@ func UploadHttpHandler(s service.UploadService, logger log.Logger) http.Handler
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorLogger(logger),
 		httptransport.ServerErrorEncoder(encoder.EncodeError),
+		httptransport.ServerBefore(middleware.LanguageToContext()),
 		httptransport.ServerBefore(jwt.HTTPToContext()),
 	}
 
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"strings"
+
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+)
+
+// Authorize allows the request only when the roles or scopes of the verified token match the policy rule.
+// It must be chained after JWTAuthentication, which puts the verified claims in context.
+func Authorize(logger log.Logger, policy string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			claims, ok := ctx.Value(kitjwt.JWTClaimsContextKey).(jwtv4.MapClaims)
+			if !ok {
+				return unauthorizedResponse(ctx, kitjwt.ErrTokenContextMissing), nil
+			}
+
+			roles := global.GetRolesFromClaims(claims)
+			if !global.IsAuthorized(policy, roles, global.GetScopesFromClaims(claims)) {
+				_ = level.Info(logger).Log("msg", "access denied", "policy", policy, "roles", strings.Join(roles, ","))
+				return forbiddenResponse(ctx), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+func forbiddenResponse(ctx context.Context) interface{} {
+	msg := message.ErrForbidden
+	if ctx.Value(CorrelationIdContextKey) != nil {
+		return base.SetHttpResponseWithCorrelationID(ctx, msg.Code, msg.Message, encoder.Empty{}, nil, nil)
+	}
+	return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil)
+}
+
+// Secured chains JWTAuthentication and Authorize for a route guarded by the policy.
+func Secured(logger log.Logger, policy string) endpoint.Middleware {
+	return endpoint.Chain(JWTAuthentication(logger), Authorize(logger, policy))
+}
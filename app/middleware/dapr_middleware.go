@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	stdHttp "net/http"
+
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/helper/config"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+)
+
+// ErrDaprAPIToken is returned for a subscriber call not sent by the dapr sidecar
+var ErrDaprAPIToken = errors.New("invalid dapr-api-token")
+
+// DaprAPITokenToContext stores the dapr-api-token header in the context for DaprAPITokenAuthentication.
+func DaprAPITokenToContext() http.RequestFunc {
+	return func(ctx context.Context, r *stdHttp.Request) context.Context {
+		return context.WithValue(ctx, base.DaprAPITokenContextKey, r.Header.Get("dapr-api-token"))
+	}
+}
+
+// DaprAPITokenAuthentication lets through the calls of the dapr sidecar only, their dapr-api-token header
+// must match dapr.app-api-token (APP_API_TOKEN of the sidecar). Every call is rejected while it is not configured.
+func DaprAPITokenAuthentication(logger log.Logger) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			expected := config.GetConfigString(viper.GetString("dapr.app-api-token"))
+			token, _ := ctx.Value(base.DaprAPITokenContextKey).(string)
+			if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+				_ = level.Info(logger).Log("msg", "dapr-api-token rejected", "configured", expected != "")
+				return nil, ErrDaprAPIToken
+			}
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+)
+
+var supportedJWTMethods = map[string]jwtv4.SigningMethod{
+	jwtv4.SigningMethodHS256.Alg(): jwtv4.SigningMethodHS256,
+	jwtv4.SigningMethodRS256.Alg(): jwtv4.SigningMethodRS256,
+}
+
+// JWTAuthentication verifies the bearer token put in context by jwt.HTTPToContext.
+// Every key configured for the token algorithm (and kid) is tried with go-kit's jwt.NewParser,
+// which enables key rotation. The verified claims are stored under jwt.JWTClaimsContextKey,
+// an invalid token is answered with 401 through encoder.EncodeResponseHTTP.
+func JWTAuthentication(logger log.Logger) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			verifiedCtx, err := verifyJWT(ctx, request)
+			if err != nil {
+				_ = level.Info(logger).Log("msg", "jwt verification failed", "err", err)
+				return unauthorizedResponse(ctx, err), nil
+			}
+			return next(verifiedCtx, request)
+		}
+	}
+}
+
+// OptionalJWTAuthentication verifies the bearer token of a public route when one is sent,
+// the request goes on anonymously without claims in context when the token is missing or invalid.
+func OptionalJWTAuthentication(logger log.Logger) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if tokenString, _ := ctx.Value(kitjwt.JWTContextKey).(string); tokenString == "" {
+				return next(ctx, request)
+			}
+			verifiedCtx, err := verifyJWT(ctx, request)
+			if err != nil {
+				_ = level.Info(logger).Log("msg", "optional jwt verification failed", "err", err)
+				return next(ctx, request)
+			}
+			return next(verifiedCtx, request)
+		}
+	}
+}
+
+func verifyJWT(ctx context.Context, request interface{}) (context.Context, error) {
+	tokenString, ok := ctx.Value(kitjwt.JWTContextKey).(string)
+	if !ok || tokenString == "" {
+		return nil, kitjwt.ErrTokenContextMissing
+	}
+
+	unverified, _, err := new(jwtv4.Parser).ParseUnverified(tokenString, jwtv4.MapClaims{})
+	if err != nil {
+		return nil, kitjwt.ErrTokenMalformed
+	}
+	method, ok := supportedJWTMethods[unverified.Method.Alg()]
+	if !ok {
+		return nil, kitjwt.ErrUnexpectedSigningMethod
+	}
+	kid, _ := unverified.Header["kid"].(string)
+
+	keys, err := global.GetJWTVerificationKeys(method, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// terminal endpoint returning the context enriched by the parser
+	passContext := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return ctx, nil
+	}
+
+	for _, k := range keys {
+		key := k.Key
+		parser := kitjwt.NewParser(func(token *jwtv4.Token) (interface{}, error) {
+			return key, nil
+		}, method, kitjwt.MapClaimsFactory)
+
+		out, errParse := parser(passContext)(ctx, request)
+		if errParse != nil {
+			err = errParse
+			// only a wrong key is worth retrying with the next one
+			if errors.Is(errParse, jwtv4.ErrSignatureInvalid) || errors.Is(errParse, rsa.ErrVerification) {
+				continue
+			}
+			return nil, errParse
+		}
+
+		verifiedCtx := out.(context.Context)
+		claims, _ := verifiedCtx.Value(kitjwt.JWTClaimsContextKey).(jwtv4.MapClaims)
+		if errClaims := global.VerifyJWTClaims(claims); errClaims != nil {
+			return nil, errClaims
+		}
+		return verifiedCtx, nil
+	}
+	return nil, err
+}
+
+func unauthorizedResponse(ctx context.Context, err error) interface{} {
+	msg := message.ErrTokenInvalid
+	if errors.Is(err, kitjwt.ErrTokenContextMissing) {
+		msg = message.ErrNoAuth
+	} else if errors.Is(err, kitjwt.ErrTokenExpired) {
+		msg = message.ErrTokenExpired
+	}
+
+	// routes encoded with EncodeResponseHTTPWithCorrelationID set the correlation id before the endpoint
+	if ctx.Value(CorrelationIdContextKey) != nil {
+		return base.SetHttpResponseWithCorrelationID(ctx, msg.Code, msg.Message, encoder.Empty{}, nil, nil)
+	}
+	return base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil)
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+	stdHttp "net/http"
+
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/helper/message"
+
+	"github.com/go-kit/kit/transport/http"
+	"github.com/spf13/viper"
+)
+
+// LanguageToContext stores the language of the request in the context, the lang parameter first then Accept-Language,
+// i18n.default-language when neither is a supported language. The texts and messages of the response are in that language.
+func LanguageToContext() http.RequestFunc {
+	return func(ctx context.Context, r *stdHttp.Request) context.Context {
+		fallback := viper.GetString("i18n.default-language")
+		if !message.IsSupportedLanguage(fallback) {
+			fallback = message.DefaultLanguage
+		}
+		lang := message.ParseLanguage(r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"), fallback)
+		return context.WithValue(ctx, base.LanguageContextKey, lang)
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	stdHttp "net/http"
+	"time"
+
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	util_ratelimit "go-klikdokter/pkg/util/ratelimit"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/transport/http"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// ClientIPToContext stores the ip of the client (GetIP) in the context for RateLimit.
+func ClientIPToContext() http.RequestFunc {
+	return func(ctx context.Context, r *stdHttp.Request) context.Context {
+		return context.WithValue(ctx, base.ClientIPContextKey, GetIP(r))
+	}
+}
+
+// RateLimit answers 429 with Retry-After once the user of the token or the ip of the client
+// is over the budget of the route (rate-limit.routes.<route>). It is chained after JWTAuthentication,
+// a failing store lets the request through.
+func RateLimit(logger log.Logger, route string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			var keys []string
+			if jwtObj, msg := global.SetJWTInfoFromContext(ctx); msg == message.SuccessMsg && fmt.Sprint(jwtObj.UserIdLegacy) != "" {
+				keys = append(keys, "user:"+fmt.Sprint(jwtObj.UserIdLegacy))
+			}
+			if ip, _ := ctx.Value(base.ClientIPContextKey).(string); ip != "" {
+				keys = append(keys, "ip:"+ip)
+			}
+
+			allowed, retryAfter, err := util_ratelimit.Allow(util_ratelimit.GetStore(), route, keys...)
+			if err != nil {
+				_ = level.Error(logger).Log("msg", "rate limit store failed", "route", route, "err", err)
+				return next(ctx, request)
+			}
+			if !allowed {
+				_ = level.Info(logger).Log("msg", "rate limit exceeded", "route", route, "keys", keys)
+				return tooManyRequestsResponse(ctx, retryAfter), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+func tooManyRequestsResponse(ctx context.Context, retryAfter time.Duration) interface{} {
+	msg := message.ErrTooManyRequests
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	if ctx.Value(CorrelationIdContextKey) != nil {
+		return base.SetRetryAfter(base.SetHttpResponseWithCorrelationID(ctx, msg.Code, msg.Message, encoder.Empty{}, nil, nil), seconds)
+	}
+	return base.SetRetryAfter(base.SetHttpResponse(msg.Code, msg.Message, encoder.Empty{}, nil), seconds)
+}
+
+// budgets of the rate limited routes, rate-limit.routes.<budget>. Each route has its own budget,
+// the routes sharing a policy are not counted together.
+const (
+	RateLimitSubmissionCreate   = "submission-create"
+	RateLimitSubmissionCreateMp = "submission-create-mp"
+	RateLimitHelpful            = "helpful"
+	RateLimitHelpfulMp          = "helpful-mp"
+)
+
+// SecuredRateLimited chains Secured and RateLimit, the budget of the route is read under route.
+func SecuredRateLimited(logger log.Logger, policy, route string) endpoint.Middleware {
+	return endpoint.Chain(Secured(logger, policy), RateLimit(logger, route))
+}
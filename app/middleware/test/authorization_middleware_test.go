@@ -0,0 +1,302 @@
+package middlewaretest
+
+import (
+	"context"
+	"go-klikdokter/app/api/transport"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/_struct"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/log"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const jwtSecret = "test-secret"
+
+var logger = log.NewNopLogger()
+
+var (
+	adminOnly     = []string{global.RoleAdmin}
+	adminInternal = []string{global.RoleAdmin, global.RoleInternalService}
+	adminMerchant = []string{global.RoleAdmin, global.RoleMerchant}
+	adminUser     = []string{global.RoleAdmin, global.RoleUser}
+	allRoles      = []string{global.RoleAdmin, global.RoleMerchant, global.RoleInternalService, global.RoleUser}
+	// roles expected on every authenticated route, keyed by "METHOD path template"
+	routeAllowedRoles = map[string][]string{
+		"POST /rating-types-numeric/":                                              adminOnly,
+		"GET /rating-types-numeric/{id}":                                           adminInternal,
+		"PUT /rating-types-numeric/{id}":                                           adminOnly,
+		"DELETE /rating-types-numeric/{id}":                                        adminOnly,
+		"PUT /rating-types-numeric/{id}/restore":                                   adminOnly,
+		"GET /rating-types-numeric":                                                adminInternal,
+		"POST /rating-submissions/":                                                allRoles,
+		"GET /rating-submissions":                                                  adminInternal,
+		"PUT /rating-submissions/{id}":                                             adminUser,
+		"GET /rating-submissions/{id}":                                             allRoles,
+		"DELETE /rating-submissions/{id}":                                          adminOnly,
+		"PUT /rating-submissions/{id}/restore":                                     adminOnly,
+		"PUT /cancel/rating-submissions":                                           adminInternal,
+		"GET /list-rating-submissions/{source_type}/{source_uid}/{user_id_legacy}": allRoles,
+		"PUT /rating-submissions/user-id-legacy/{user_id_legacy}":                  adminInternal,
+		"PUT /rating-submissions/reply/{id}":                                       adminMerchant,
+		"PUT /rating-submissions/reply/{id}/hide":                                  adminOnly,
+		"GET /moderation/rating-submissions":                                       adminOnly,
+		"PUT /moderation/rating-submissions/{id}":                                  adminOnly,
+		"GET /moderation/rating-submission-reports":                                adminOnly,
+		"GET /moderation/rating-submission-revisions/{id}":                         adminOnly,
+		"POST /public/rating-submissions/{id}/report":                              allRoles,
+		"POST /rating-types-likert/":                                               adminOnly,
+		"GET /rating-types-likert/{id}":                                            adminInternal,
+		"PUT /rating-types-likert/{id}":                                            adminOnly,
+		"DELETE /rating-types-likert/{id}":                                         adminOnly,
+		"PUT /rating-types-likert/{id}/restore":                                    adminOnly,
+		"GET /rating-types-likert":                                                 adminInternal,
+		"POST /ratings/":                                                           adminInternal,
+		"GET /ratings/summary/{source_type}":                                       allRoles,
+		"GET /ratings/{id}":                                                        allRoles,
+		"PUT /ratings/{id}":                                                        adminInternal,
+		"DELETE /ratings/{id}":                                                     adminInternal,
+		"PUT /ratings/{id}/restore":                                                adminInternal,
+		"GET /ratings":                                                             allRoles,
+		"GET /list-ratings/{source_type}/{source_uid}":                             allRoles,
+		"POST /rating-formula/":                                                    adminOnly,
+		"GET /rating-formula":                                                      adminOnly,
+		"POST /rating-formula/preview":                                             adminOnly,
+		"GET /rating-formula/{id}/versions":                                        adminOnly,
+		"POST /rating-formula/{id}/versions":                                       adminOnly,
+		"PUT /rating-formula/{id}/versions/{version}/activate":                     adminOnly,
+		"GET /rating-formula/{id}":                                                 adminOnly,
+		"PUT /rating-formula/{id}":                                                 adminOnly,
+		"DELETE /rating-formula/{id}":                                              adminOnly,
+		"PUT /rating-formula/{id}/restore":                                         adminOnly,
+		"POST /final-rating/republish":                                             adminOnly,
+		"POST /helpful-rating-submission/":                                         allRoles,
+		"POST /helpful-rating-submission-mp/":                                      allRoles,
+		"POST /internal/rating":                                                    adminInternal,
+		"GET /rating-submissions-mp":                                               adminInternal,
+		"POST /rating-submissions-mp":                                              allRoles,
+		"GET /rating-submissions-mp/{id}":                                          allRoles,
+		"GET /ratings-summary-mp/{source_type}":                                    allRoles,
+		"POST /review-invitations":                                                 adminInternal,
+		"GET /review-invitations/pending":                                          adminUser,
+		"GET /source-types":                                                        adminInternal,
+		"GET /source-types/{id}":                                                   adminInternal,
+		"POST /source-types":                                                       adminOnly,
+		"PUT /source-types/{id}":                                                   adminOnly,
+		"DELETE /source-types/{id}":                                                adminOnly,
+		"GET /submission-tags":                                                     allRoles,
+		"GET /submission-tags/{id}":                                                allRoles,
+		"POST /submission-tags":                                                    adminOnly,
+		"PUT /submission-tags/{id}":                                                adminOnly,
+		"DELETE /submission-tags/{id}":                                             adminOnly,
+		"GET /outbox":                                                              adminOnly,
+		"GET /outbox/{id}":                                                         adminOnly,
+		"PUT /outbox/{id}/replay":                                                  adminOnly,
+	}
+)
+
+// valid bodies of the routes validating their request before the authorization runs, the others get an empty json
+var routeBody = map[string]string{
+	"POST /rating-types-numeric/":             `{"type":"num","min_score":1,"max_score":5,"scale":0}`,
+	"POST /rating-types-likert/":              `{"type":"likert","num_statements":1}`,
+	"POST /ratings/":                          `{"name":"rating","source_type":"product","source_uid":"1","rating_type":"num","rating_type_id":"1"}`,
+	"PUT /ratings/{id}":                       `{"source_type":"product"}`,
+	"PUT /rating-submissions/{id}":            `{"rating_id":"1","value":"5","comment":"ok"}`,
+	"PUT /rating-submissions/reply/{id}":      `{"reply":"terima kasih"}`,
+	"PUT /moderation/rating-submissions/{id}": `{"status":"approved"}`,
+	"POST /rating-formula/preview":            `{"source_type":"product","formula":"sum / count","source_uids":["1"]}`,
+	"POST /rating-formula/{id}/versions":      `{"formula":"sum / count"}`,
+	"POST /final-rating/republish":            `{"source_type":"product"}`,
+	"POST /internal/rating":                   `{"name":"rating","source_type":"product","source_uid":"1","rating_type":"num","rating_type_id":"1"}`,
+}
+
+// The services are left nil, a request reaching them panics, which only happens once the route authorized the token
+type (
+	ratingService            struct{ service.RatingService }
+	ratingMpService          struct{ service.RatingMpService }
+	ratingSubReportService   struct{ service.RatingSubReportService }
+	ratingSubRevisionService struct {
+		service.RatingSubRevisionService
+	}
+	reviewInvitationService struct {
+		service.ReviewInvitationService
+	}
+	sourceTypeService    struct{ service.SourceTypeService }
+	submissionTagService struct{ service.SubmissionTagService }
+	outboxService        struct{ service.OutboxService }
+)
+
+func securedRouters() []*mux.Router {
+	handlers := []http.Handler{
+		transport.RatingHttpHandler(ratingService{}, logger, nil),
+		transport.RatingMpHttpHandler(ratingMpService{}, logger),
+		transport.RatingSubReportHttpHandler(ratingSubReportService{}, logger),
+		transport.RatingSubRevisionHttpHandler(ratingSubRevisionService{}, logger),
+		transport.ReviewInvitationHttpHandler(reviewInvitationService{}, logger),
+		transport.SourceTypeHttpHandler(sourceTypeService{}, logger),
+		transport.SubmissionTagHttpHandler(submissionTagService{}, logger),
+		transport.OutboxHttpHandler(outboxService{}, logger),
+	}
+	var routers []*mux.Router
+	for _, h := range handlers {
+		routers = append(routers, h.(*mux.Router))
+	}
+	return routers
+}
+
+// serveRoute sends a request with the body, passed is true when the request got through the authorization
+func serveRoute(router *mux.Router, method, path, body, token string) (code int, passed bool) {
+	defer func() {
+		if recover() != nil {
+			passed = true
+		}
+	}()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	code = rec.Code
+	passed = code != http.StatusUnauthorized && code != http.StatusForbidden &&
+		code != http.StatusNotFound && code != http.StatusMethodNotAllowed
+	return code, passed
+}
+
+func init() {
+	viper.Set("security.jwt.secret", jwtSecret)
+	global.ResetJWTVerificationKeys()
+}
+
+func signToken(claims jwtv4.MapClaims) string {
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, _ := jwtv4.NewWithClaims(jwtv4.SigningMethodHS256, claims).SignedString([]byte(jwtSecret))
+	return token
+}
+
+func callSecured(policy string, token string) (int, bool) {
+	reached := false
+	ep := middleware.Secured(logger, policy)(func(ctx context.Context, request interface{}) (interface{}, error) {
+		reached = true
+		return base.SetHttpResponse(message.SuccessCode, message.SuccessMsg.Message, nil, nil), nil
+	})
+	ctx := context.WithValue(context.Background(), kitjwt.JWTContextKey, token)
+	resp, _ := ep(ctx, nil)
+	return base.GetHttpResponse(resp).Meta.Code, reached
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAuthorizeEachRegisteredRoute(t *testing.T) {
+	pathVar := regexp.MustCompile(`{[^}]+}`)
+	walked := map[string]bool{}
+	for _, router := range securedRouters() {
+		err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			template, _ := route.GetPathTemplate()
+			methods, _ := route.GetMethods()
+			for _, method := range methods {
+				key := method + " " + strings.TrimPrefix(template, _struct.PrefixBase)
+				walked[key] = true
+				allowed, ok := routeAllowedRoles[key]
+				if !assert.True(t, ok, "%s has no expected roles", key) {
+					continue
+				}
+
+				path := pathVar.ReplaceAllString(template, "1")
+				body, ok := routeBody[key]
+				if !ok {
+					body = "{}"
+				}
+				for _, role := range allRoles {
+					code, passed := serveRoute(router, method, path, body, signToken(jwtv4.MapClaims{"id": 1, "roles": []string{role}}))
+					if contains(allowed, role) {
+						assert.True(t, passed, "%s should allow %s, got %d", key, role, code)
+					} else {
+						assert.False(t, passed, "%s should deny %s", key, role)
+						assert.Equal(t, http.StatusForbidden, code, "%s should deny %s", key, role)
+					}
+				}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+
+	for key := range routeAllowedRoles {
+		assert.True(t, walked[key], "%s is not registered", key)
+	}
+}
+
+func TestAuthorizeTokenWithoutRoleIsUser(t *testing.T) {
+	_, reached := callSecured(global.PolicySubmissionCreate, signToken(jwtv4.MapClaims{"id": 1}))
+	assert.True(t, reached)
+
+	_, reached = callSecured(global.PolicyFormulaWrite, signToken(jwtv4.MapClaims{"id": 1}))
+	assert.False(t, reached)
+}
+
+func TestAuthorizeNestedRoleClaimAndScope(t *testing.T) {
+	viper.Set("authorization.role-claims", []string{"realm_access.roles"})
+	viper.Set("authorization.rules."+global.PolicyFormulaRead, []string{"admin", "scope:formula.read"})
+	defer viper.Set("authorization.role-claims", nil)
+	defer viper.Set("authorization.rules."+global.PolicyFormulaRead, nil)
+
+	_, reached := callSecured(global.PolicyFormulaWrite, signToken(jwtv4.MapClaims{"realm_access": map[string]interface{}{"roles": []string{"admin"}}}))
+	assert.True(t, reached)
+
+	_, reached = callSecured(global.PolicyFormulaRead, signToken(jwtv4.MapClaims{"scope": "profile formula.read"}))
+	assert.True(t, reached)
+
+	_, reached = callSecured(global.PolicyFormulaWrite, signToken(jwtv4.MapClaims{"scope": "profile formula.read"}))
+	assert.False(t, reached)
+}
+
+func TestSecuredRejectsInvalidToken(t *testing.T) {
+	code, reached := callSecured(global.PolicyHelpful, "not-a-token")
+	assert.False(t, reached)
+	assert.Equal(t, message.UnauthorizedCode, code)
+
+	forged, _ := jwtv4.NewWithClaims(jwtv4.SigningMethodHS256, jwtv4.MapClaims{"roles": "admin"}).SignedString([]byte("other"))
+	code, reached = callSecured(global.PolicyFormulaWrite, forged)
+	assert.False(t, reached)
+	assert.Equal(t, message.UnauthorizedCode, code)
+}
+
+func TestOptionalJWTAuthentication(t *testing.T) {
+	callOptional := func(token string) string {
+		userIdLegacy := ""
+		ep := middleware.OptionalJWTAuthentication(logger)(func(ctx context.Context, request interface{}) (interface{}, error) {
+			if jwtObj, msg := global.SetJWTInfoFromContext(ctx); msg.Code == message.SuccessCode {
+				userIdLegacy = jwtObj.UserIdLegacy.(string)
+			}
+			return nil, nil
+		})
+		ctx := context.WithValue(context.Background(), kitjwt.JWTContextKey, token)
+		_, _ = ep(ctx, nil)
+		return userIdLegacy
+	}
+
+	assert.Equal(t, "12", callOptional(signToken(jwtv4.MapClaims{"id": 12})))
+	// the public route stays anonymous without a valid token
+	assert.Equal(t, "", callOptional(""))
+	assert.Equal(t, "", callOptional("not-a-token"))
+}
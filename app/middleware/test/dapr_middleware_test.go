@@ -0,0 +1,61 @@
+package middlewaretest
+
+import (
+	"go-klikdokter/app/api/transport"
+	"go-klikdokter/app/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// daprService is left nil, a call reaching it panics, which only happens once the dapr-api-token is accepted
+type daprService struct{ service.DaprService }
+
+const orderCompletedEvent = `{"specversion":"1.0","type":"order.completed","data":{"order_number":"888888"}}`
+
+// serveDaprSubscriber posts the event to the subscriber, passed is true when the call got through the token check
+func serveDaprSubscriber(path, token string) (code int, passed bool) {
+	defer func() {
+		if recover() != nil {
+			passed = true
+		}
+	}()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(orderCompletedEvent))
+	if token != "" {
+		req.Header.Set("dapr-api-token", token)
+	}
+	rec := httptest.NewRecorder()
+	transport.DaprHttpHandler(daprService{}, logger).ServeHTTP(rec, req)
+	return rec.Code, rec.Code != http.StatusUnauthorized
+}
+
+func TestDaprSubscribersRequireAPIToken(t *testing.T) {
+	viper.Set("dapr.app-api-token", "sidecar-token")
+	defer viper.Set("dapr.app-api-token", nil)
+
+	for _, path := range []string{"/dapr/subscriber/order-completed", "/dapr/subscriber/order-refunded", "/dapr/subscriber/user-profile-updated"} {
+		code, passed := serveDaprSubscriber(path, "")
+		assert.False(t, passed, path)
+		assert.Equal(t, http.StatusUnauthorized, code, path)
+
+		code, passed = serveDaprSubscriber(path, "forged-token")
+		assert.False(t, passed, path)
+		assert.Equal(t, http.StatusUnauthorized, code, path)
+
+		_, passed = serveDaprSubscriber(path, "sidecar-token")
+		assert.True(t, passed, path)
+	}
+}
+
+func TestDaprSubscribersRejectedWithoutConfiguredToken(t *testing.T) {
+	viper.Set("dapr.app-api-token", "")
+	defer viper.Set("dapr.app-api-token", nil)
+
+	code, passed := serveDaprSubscriber("/dapr/subscriber/order-completed", "")
+	assert.False(t, passed)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
@@ -0,0 +1,211 @@
+package middlewaretest
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeJWKSFile(t *testing.T, path, kid, secret string, modTime time.Time) {
+	jwks := fmt.Sprintf(`{"keys":[{"kid":%q,"kty":"oct","k":%q}]}`, kid, base64.RawURLEncoding.EncodeToString([]byte(secret)))
+	assert.NoError(t, ioutil.WriteFile(path, []byte(jwks), 0600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func signTokenWithKid(kid, secret string) string {
+	token := jwtv4.NewWithClaims(jwtv4.SigningMethodHS256, jwtv4.MapClaims{"id": 1, "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = kid
+	signed, _ := token.SignedString([]byte(secret))
+	return signed
+}
+
+func TestJWTKeysReloadedWhenJWKSFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeJWKSFile(t, path, "key-1", "secret-1", modTime)
+
+	viper.Set("security.jwt.jwks-file", path)
+	viper.Set("security.jwt.jwks-reload-interval-seconds", 1)
+	global.ResetJWTVerificationKeys()
+	defer func() {
+		viper.Set("security.jwt.jwks-file", "")
+		viper.Set("security.jwt.jwks-reload-interval-seconds", nil)
+		global.ResetJWTVerificationKeys()
+	}()
+
+	code, _ := callSecured(global.PolicyHelpful, signTokenWithKid("key-1", "secret-1"))
+	assert.Equal(t, message.SuccessCode, code)
+
+	// the keys are not read from disk on every request
+	writeJWKSFile(t, path, "key-2", "secret-2", modTime.Add(time.Minute))
+	code, _ = callSecured(global.PolicyHelpful, signTokenWithKid("key-2", "secret-2"))
+	assert.Equal(t, message.UnauthorizedCode, code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go global.RunJWTKeyReload(ctx, logger)
+
+	assert.Eventually(t, func() bool {
+		code, _ := callSecured(global.PolicyHelpful, signTokenWithKid("key-2", "secret-2"))
+		return code == message.SuccessCode
+	}, 5*time.Second, 100*time.Millisecond)
+
+	code, _ = callSecured(global.PolicyHelpful, signTokenWithKid("key-1", "secret-1"))
+	assert.Equal(t, message.UnauthorizedCode, code)
+}
+
+// useRSAJWKSFile configures a JWKS file holding the public key of a new RSA key under kid
+func useRSAJWKSFile(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	jwks := fmt.Sprintf(`{"keys":[{"kid":%q,"kty":"RSA","alg":"RS256","use":"sig","n":%q,"e":%q}]}`, kid,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(jwks), 0600))
+
+	viper.Set("security.jwt.jwks-file", path)
+	global.ResetJWTVerificationKeys()
+	t.Cleanup(func() {
+		viper.Set("security.jwt.jwks-file", "")
+		global.ResetJWTVerificationKeys()
+	})
+	return key
+}
+
+func signRS256(key *rsa.PrivateKey, kid string, claims jwtv4.MapClaims) string {
+	token := jwtv4.NewWithClaims(jwtv4.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, _ := token.SignedString(key)
+	return signed
+}
+
+// signHS256 signs the claims as they are, signToken adds a valid exp
+func signHS256(claims jwtv4.MapClaims) string {
+	signed, _ := jwtv4.NewWithClaims(jwtv4.SigningMethodHS256, claims).SignedString([]byte(jwtSecret))
+	return signed
+}
+
+// authenticate returns the message answered to the token and whether the endpoint was reached
+func authenticate(token string) (string, bool) {
+	reached := false
+	ep := middleware.JWTAuthentication(logger)(func(ctx context.Context, request interface{}) (interface{}, error) {
+		reached = true
+		return base.SetHttpResponse(message.SuccessCode, message.SuccessMsg.Message, nil, nil), nil
+	})
+	resp, _ := ep(context.WithValue(context.Background(), kitjwt.JWTContextKey, token), nil)
+	return base.GetHttpResponse(resp).Meta.Message, reached
+}
+
+func TestJWTRejectsTokenOutOfValidity(t *testing.T) {
+	viper.Set("security.jwt.require-exp", true)
+	defer viper.Set("security.jwt.require-exp", nil)
+	now := time.Now()
+
+	msg, reached := authenticate(signHS256(jwtv4.MapClaims{"id": 1, "exp": now.Add(-time.Minute).Unix()}))
+	assert.False(t, reached, "expired")
+	assert.Equal(t, message.ErrTokenExpired.Message, msg)
+
+	msg, reached = authenticate(signHS256(jwtv4.MapClaims{"id": 1, "exp": now.Add(time.Hour).Unix(), "nbf": now.Add(time.Hour).Unix()}))
+	assert.False(t, reached, "not yet valid")
+	assert.Equal(t, message.ErrTokenInvalid.Message, msg)
+
+	msg, reached = authenticate(signHS256(jwtv4.MapClaims{"id": 1}))
+	assert.False(t, reached, "no exp")
+	assert.Equal(t, message.ErrTokenInvalid.Message, msg)
+
+	_, reached = authenticate(signHS256(jwtv4.MapClaims{"id": 1, "exp": now.Add(time.Hour).Unix(), "nbf": now.Add(-time.Minute).Unix()}))
+	assert.True(t, reached)
+}
+
+func TestJWTRejectsWrongIssuerOrAudience(t *testing.T) {
+	viper.Set("security.jwt.issuer", []string{"https://auth.klikdokter.com"})
+	viper.Set("security.jwt.audience", []string{"rating-svc"})
+	defer func() {
+		viper.Set("security.jwt.issuer", nil)
+		viper.Set("security.jwt.audience", nil)
+	}()
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		claims  jwtv4.MapClaims
+		reached bool
+	}{
+		{name: "accepted", claims: jwtv4.MapClaims{"iss": "https://auth.klikdokter.com", "aud": "rating-svc"}, reached: true},
+		{name: "wrong issuer", claims: jwtv4.MapClaims{"iss": "https://auth.example.com", "aud": "rating-svc"}},
+		{name: "no issuer", claims: jwtv4.MapClaims{"aud": "rating-svc"}},
+		{name: "wrong audience", claims: jwtv4.MapClaims{"iss": "https://auth.klikdokter.com", "aud": "payment-svc"}},
+		{name: "no audience", claims: jwtv4.MapClaims{"iss": "https://auth.klikdokter.com"}},
+	}
+	for _, tt := range tests {
+		tt.claims["exp"] = exp
+		_, reached := authenticate(signHS256(tt.claims))
+		assert.Equal(t, tt.reached, reached, tt.name)
+	}
+}
+
+func TestJWTVerifiesRS256WithJWKSKey(t *testing.T) {
+	key := useRSAJWKSFile(t, "rsa-1")
+
+	_, reached := authenticate(signRS256(key, "rsa-1", jwtv4.MapClaims{"id": 1, "exp": time.Now().Add(time.Hour).Unix()}))
+	assert.True(t, reached)
+
+	// a key that is not in the JWKS file
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, reached = authenticate(signRS256(other, "rsa-1", jwtv4.MapClaims{"id": 1, "exp": time.Now().Add(time.Hour).Unix()}))
+	assert.False(t, reached)
+}
+
+func TestJWTRejectsUnknownKid(t *testing.T) {
+	key := useRSAJWKSFile(t, "rsa-1")
+
+	_, reached := authenticate(signRS256(key, "rsa-2", jwtv4.MapClaims{"id": 1, "exp": time.Now().Add(time.Hour).Unix()}))
+	assert.False(t, reached)
+}
+
+func TestJWTRejectsAlgNone(t *testing.T) {
+	token := jwtv4.NewWithClaims(jwtv4.SigningMethodNone, jwtv4.MapClaims{"id": 1, "roles": "admin", "exp": time.Now().Add(time.Hour).Unix()})
+	signed, err := token.SignedString(jwtv4.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	_, reached := authenticate(signed)
+	assert.False(t, reached)
+}
+
+func TestJWTRejectsAlgorithmNotMatchingKey(t *testing.T) {
+	key := useRSAJWKSFile(t, "rsa-1")
+
+	// the public RSA key is known to anyone, it must not be usable as an HS256 secret
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+	for _, secret := range [][]byte{publicPEM, key.PublicKey.N.Bytes()} {
+		token := jwtv4.NewWithClaims(jwtv4.SigningMethodHS256, jwtv4.MapClaims{"id": 1, "exp": time.Now().Add(time.Hour).Unix()})
+		token.Header["kid"] = "rsa-1"
+		signed, _ := token.SignedString(secret)
+
+		_, reached := authenticate(signed)
+		assert.False(t, reached)
+	}
+}
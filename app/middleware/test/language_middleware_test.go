@@ -0,0 +1,57 @@
+package middlewaretest
+
+import (
+	"context"
+	"encoding/json"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/base/encoder"
+	"go-klikdokter/helper/message"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func languageOf(url, acceptLanguage string) string {
+	r := httptest.NewRequest(http.MethodGet, url, nil)
+	if acceptLanguage != "" {
+		r.Header.Set("Accept-Language", acceptLanguage)
+	}
+	return base.GetLanguage(middleware.LanguageToContext()(context.Background(), r))
+}
+
+func TestLanguageToContext(t *testing.T) {
+	assert.Equal(t, message.LanguageID, languageOf("/ratings?lang=id", "en-US,en;q=0.9"))
+	assert.Equal(t, message.LanguageID, languageOf("/ratings", "id-ID,id;q=0.9,en;q=0.8"))
+	assert.Equal(t, message.LanguageEN, languageOf("/ratings", "fr-FR,en;q=0.5,id;q=0.4"))
+	assert.Equal(t, message.LanguageID, languageOf("/ratings?lang=fr", "en;q=0.2,id"))
+	assert.Equal(t, message.LanguageEN, languageOf("/ratings", "fr-FR"))
+}
+
+func TestLanguageToContextDefaultLanguage(t *testing.T) {
+	viper.Set("i18n.default-language", message.LanguageID)
+	defer viper.Set("i18n.default-language", nil)
+
+	assert.Equal(t, message.LanguageID, languageOf("/ratings", ""))
+	assert.Equal(t, message.LanguageEN, languageOf("/ratings?lang=en", ""))
+}
+
+func TestEncodeResponseHTTPLocalizesMessage(t *testing.T) {
+	ctx := context.WithValue(context.Background(), base.LanguageContextKey, message.LanguageID)
+	w := httptest.NewRecorder()
+	resp := base.SetHttpResponse(message.ErrDataNotFound.Code, message.ErrDataNotFound.Message, encoder.Empty{}, nil)
+
+	assert.NoError(t, encoder.EncodeResponseHTTP(ctx, w, resp))
+
+	var body struct {
+		Meta struct {
+			Message string `json:"message"`
+		} `json:"meta"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Data tidak ditemukan", body.Meta.Message)
+	assert.Equal(t, "interval must be 5", message.LocalizeText("interval must be 5", message.LanguageID))
+}
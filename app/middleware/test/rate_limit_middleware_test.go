@@ -0,0 +1,112 @@
+package middlewaretest
+
+import (
+	"context"
+	"errors"
+	"go-klikdokter/app/middleware"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	util_ratelimit "go-klikdokter/pkg/util/ratelimit"
+	"testing"
+	"time"
+
+	kitjwt "github.com/go-kit/kit/auth/jwt"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Hit(key string, window time.Duration) (int64, time.Time, error) {
+	return 0, time.Time{}, errors.New("store down")
+}
+
+func setRateLimit(limit int) func() {
+	viper.Set("rate-limit.enabled", true)
+	for _, route := range []string{middleware.RateLimitSubmissionCreate, middleware.RateLimitSubmissionCreateMp} {
+		viper.Set("rate-limit.routes."+route+".limit", limit)
+		viper.Set("rate-limit.routes."+route+".window-seconds", 60)
+	}
+	return func() {
+		viper.Set("rate-limit.enabled", nil)
+		for _, route := range []string{middleware.RateLimitSubmissionCreate, middleware.RateLimitSubmissionCreateMp} {
+			viper.Set("rate-limit.routes."+route+".limit", nil)
+			viper.Set("rate-limit.routes."+route+".window-seconds", nil)
+		}
+		util_ratelimit.UseStore(util_ratelimit.NewMemoryStore())
+	}
+}
+
+func callRateLimited(userId int, ip string) (int, int) {
+	return callRateLimitedRoute(middleware.RateLimitSubmissionCreateMp, userId, ip)
+}
+
+func callRateLimitedRoute(route string, userId int, ip string) (int, int) {
+	ep := middleware.SecuredRateLimited(logger, global.PolicySubmissionCreate, route)(func(ctx context.Context, request interface{}) (interface{}, error) {
+		return base.SetHttpResponse(message.SuccessCode, message.SuccessMsg.Message, nil, nil), nil
+	})
+	ctx := context.WithValue(context.Background(), kitjwt.JWTContextKey, signToken(jwtv4.MapClaims{"id": userId}))
+	ctx = context.WithValue(ctx, base.ClientIPContextKey, ip)
+	resp, _ := ep(ctx, nil)
+	result := base.GetHttpResponse(resp)
+	return result.Meta.Code, result.RetryAfter
+}
+
+func TestRateLimitPerUserAndIP(t *testing.T) {
+	defer setRateLimit(2)()
+	util_ratelimit.UseStore(util_ratelimit.NewMemoryStore())
+
+	code, _ := callRateLimited(1, "10.0.0.1")
+	assert.Equal(t, message.SuccessCode, code)
+	code, _ = callRateLimited(1, "10.0.0.2")
+	assert.Equal(t, message.SuccessCode, code)
+
+	// the user is over the budget from any ip
+	code, retryAfter := callRateLimited(1, "10.0.0.3")
+	assert.Equal(t, message.TooManyRequestsCode, code)
+	assert.True(t, retryAfter >= 1 && retryAfter <= 60)
+
+	// the ip is over the budget for any user
+	code, _ = callRateLimited(2, "10.0.0.1")
+	assert.Equal(t, message.SuccessCode, code)
+	code, _ = callRateLimited(3, "10.0.0.1")
+	assert.Equal(t, message.TooManyRequestsCode, code)
+
+	code, _ = callRateLimited(4, "10.0.0.4")
+	assert.Equal(t, message.SuccessCode, code)
+}
+
+func TestRateLimitDisabledOrStoreDown(t *testing.T) {
+	defer setRateLimit(1)()
+
+	util_ratelimit.UseStore(failingStore{})
+	code, _ := callRateLimited(1, "10.0.0.1")
+	assert.Equal(t, message.SuccessCode, code)
+	code, _ = callRateLimited(1, "10.0.0.1")
+	assert.Equal(t, message.SuccessCode, code)
+
+	util_ratelimit.UseStore(util_ratelimit.NewMemoryStore())
+	viper.Set("rate-limit.enabled", false)
+	for i := 0; i < 3; i++ {
+		code, _ = callRateLimited(1, "10.0.0.1")
+		assert.Equal(t, message.SuccessCode, code)
+	}
+}
+
+func TestRateLimitPerRoute(t *testing.T) {
+	defer setRateLimit(1)()
+	util_ratelimit.UseStore(util_ratelimit.NewMemoryStore())
+
+	code, _ := callRateLimitedRoute(middleware.RateLimitSubmissionCreateMp, 1, "10.0.0.1")
+	assert.Equal(t, message.SuccessCode, code)
+	code, _ = callRateLimitedRoute(middleware.RateLimitSubmissionCreateMp, 1, "10.0.0.1")
+	assert.Equal(t, message.TooManyRequestsCode, code)
+
+	// the legacy route of the same policy has its own budget
+	code, _ = callRateLimitedRoute(middleware.RateLimitSubmissionCreate, 1, "10.0.0.1")
+	assert.Equal(t, message.SuccessCode, code)
+	code, _ = callRateLimitedRoute(middleware.RateLimitSubmissionCreate, 1, "10.0.0.1")
+	assert.Equal(t, message.TooManyRequestsCode, code)
+}
@@ -1,5 +1,10 @@
 package base
 
+import (
+	"context"
+	"go-klikdokter/helper/message"
+)
+
 type contextKey string
 
 const (
@@ -9,4 +14,18 @@ const (
 	RequestHeaderContextKey contextKey = "RequestHeaderToken"
 	// SignedUserContextKey holds the key used to store a Signed User in the context.
 	SignedUserContextKey contextKey = "SignedUserToken"
+	// ClientIPContextKey holds the key used to store the ip of the client in the context.
+	ClientIPContextKey contextKey = "ClientIPToken"
+	// LanguageContextKey holds the key used to store the language of the request in the context.
+	LanguageContextKey contextKey = "LanguageToken"
+	// DaprAPITokenContextKey holds the key used to store the dapr-api-token header sent by the dapr sidecar in the context.
+	DaprAPITokenContextKey contextKey = "DaprAPIToken"
 )
+
+// GetLanguage returns the language of the request stored in the context, message.DefaultLanguage when there is none
+func GetLanguage(ctx context.Context) string {
+	if lang, _ := ctx.Value(LanguageContextKey).(string); lang != "" {
+		return lang
+	}
+	return message.DefaultLanguage
+}
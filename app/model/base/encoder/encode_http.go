@@ -6,6 +6,7 @@ import (
 	"go-klikdokter/app/model/base"
 	"go-klikdokter/helper/message"
 	"net/http"
+	"strconv"
 )
 
 type errorer interface {
@@ -41,10 +42,16 @@ func EncodeResponseHTTP(ctx context.Context, w http.ResponseWriter, resp interfa
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
 	result := base.GetHttpResponse(resp)
+	result.Meta.Message = message.LocalizeText(result.Meta.Message, base.GetLanguage(ctx))
 	code := result.Meta.Code
 	switch code {
 	case message.UnauthorizedCode:
 		w.WriteHeader(http.StatusUnauthorized)
+	case message.ForbiddenCode:
+		w.WriteHeader(http.StatusForbidden)
+	case message.TooManyRequestsCode:
+		w.Header().Set("Retry-After", strconv.Itoa(result.RetryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
 	case message.JSONParseFailCode, message.ErrTypeReq.Code, message.ValidationFailCode:
 		w.WriteHeader(http.StatusBadRequest)
 	case message.SuccessCode, message.DataNotFoundCode, message.ErrDataNotFoundCode:
@@ -53,7 +60,7 @@ func EncodeResponseHTTP(ctx context.Context, w http.ResponseWriter, resp interfa
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 
-	return json.NewEncoder(w).Encode(resp)
+	return json.NewEncoder(w).Encode(result)
 }
 
 func EncodeResponseHTTPWithCorrelationID(ctx context.Context, w http.ResponseWriter, resp interface{}) error {
@@ -64,10 +71,16 @@ func EncodeResponseHTTPWithCorrelationID(ctx context.Context, w http.ResponseWri
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
 	result := base.GetHttpResponseWithCorrelataionID(resp)
+	result.Meta.Message = message.LocalizeText(result.Meta.Message, base.GetLanguage(ctx))
 	code := result.Meta.Code
 	switch code {
 	case message.UnauthorizedCode:
 		w.WriteHeader(http.StatusUnauthorized)
+	case message.ForbiddenCode:
+		w.WriteHeader(http.StatusForbidden)
+	case message.TooManyRequestsCode:
+		w.Header().Set("Retry-After", strconv.Itoa(result.RetryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
 	case message.JSONParseFailCode, message.ErrTypeReq.Code, message.ValidationFailCode:
 		w.WriteHeader(http.StatusBadRequest)
 	case message.SuccessCode, message.DataNotFoundCode:
@@ -76,16 +89,16 @@ func EncodeResponseHTTPWithCorrelationID(ctx context.Context, w http.ResponseWri
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 
-	return json.NewEncoder(w).Encode(resp)
+	return json.NewEncoder(w).Encode(result)
 }
 
-// Encode error, for HTTP
-func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
+// Encode error, for HTTP, the message is in the language of the request
+func EncodeError(ctx context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusBadRequest)
 	result := &errorResponse{}
 	result.Meta.Code = message.ErrReq.Code
-	result.Meta.Message = err.Error()
+	result.Meta.Message = message.LocalizeText(err.Error(), base.GetLanguage(ctx))
 	result.Data = Empty{}
 	_ = json.NewEncoder(w).Encode(result)
 }
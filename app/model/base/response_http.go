@@ -20,6 +20,8 @@ type responseHttp struct {
 	// Errors is the response message
 	//in: string
 	Errors interface{} `json:"errors,omitempty"`
+	// RetryAfter is the Retry-After header in seconds of a rate limited response
+	RetryAfter int `json:"-"`
 }
 
 // swagger:model SuccessResponse
@@ -36,6 +38,8 @@ type responseHttpWithCorrelationID struct {
 	// Errors is the response message
 	//in: string
 	Errors interface{} `json:"errors,omitempty"`
+	// RetryAfter is the Retry-After header in seconds of a rate limited response
+	RetryAfter int `json:"-"`
 }
 
 // swagger:model MetaResponse
@@ -115,6 +119,19 @@ func SetHttpResponseWithCorrelationID(ctx context.Context,code int, message stri
 	}
 }
 
+// SetRetryAfter sets the Retry-After header in seconds of a response built by SetHttpResponse or SetHttpResponseWithCorrelationID
+func SetRetryAfter(resp interface{}, seconds int) interface{} {
+	switch result := resp.(type) {
+	case responseHttp:
+		result.RetryAfter = seconds
+		return result
+	case responseHttpWithCorrelationID:
+		result.RetryAfter = seconds
+		return result
+	}
+	return resp
+}
+
 func GetHttpResponse(resp interface{}) *responseHttp {
 	result, ok := resp.(responseHttp)
 
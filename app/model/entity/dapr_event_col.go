@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+)
+
+// DaprEventCol is an event consumed from dapr, the event is skipped when it is delivered again
+type DaprEventCol struct {
+	Key         string    `json:"key" bson:"_id"`
+	Topic       string    `json:"topic" bson:"topic"`
+	ProcessedAt time.Time `json:"processed_at" bson:"processed_at"`
+}
+
+func (DaprEventCol) CollectionName() string {
+	return "daprEventCol"
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FinalRatingCol is the last final rating published for a product or store,
+// it is the old value of the next event and lets unchanged ratings be skipped
+// swagger:model FinalRatingCol
+type FinalRatingCol struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	SourceType    string             `json:"source_type" bson:"source_type"`
+	SourceUID     string             `json:"source_uid" bson:"source_uid"`
+	Value         string             `json:"value" bson:"value"`
+	TotalReviewer int64              `json:"total_reviewer" bson:"total_reviewer"`
+	Histogram     map[string]int64   `json:"histogram" bson:"histogram"`
+	PublishedAt   time.Time          `json:"published_at" bson:"published_at"`
+}
+
+func (FinalRatingCol) CollectionName() string {
+	return "finalRatingCol"
+}
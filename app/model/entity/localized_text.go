@@ -0,0 +1,44 @@
+package entity
+
+// LocalizedText is a text by language, e.g. {"id": "Ramah", "en": "Friendly"}
+type LocalizedText map[string]string
+
+// Localize returns the text in lang, fallback when there is no text in lang
+func (t LocalizedText) Localize(lang, fallback string) string {
+	if text := t[lang]; text != "" {
+		return text
+	}
+	return fallback
+}
+
+// localizePtr returns the text in lang, text when there is no text in lang
+func (t LocalizedText) localizePtr(lang string, text *string) *string {
+	if localized := t[lang]; localized != "" {
+		return &localized
+	}
+	return text
+}
+
+// Localized returns the rating with the description in lang
+func (r RatingsCol) Localized(lang string) RatingsCol {
+	r.Description = r.DescriptionI18n.localizePtr(lang, r.Description)
+	return r
+}
+
+// Localized returns the numeric type with the description in lang
+func (n RatingTypesNumCol) Localized(lang string) RatingTypesNumCol {
+	n.Description = n.DescriptionI18n.localizePtr(lang, n.Description)
+	return n
+}
+
+// Localized returns the likert type with the description and the labels of the statements in lang
+func (l RatingTypesLikertCol) Localized(lang string) RatingTypesLikertCol {
+	l.Description = l.DescriptionI18n.localizePtr(lang, l.Description)
+	statements := l.GetStatements()
+	l.Statements = make([]LikertStatement, len(statements))
+	for i, statement := range statements {
+		statement.Label = statement.LabelI18n.Localize(lang, statement.Label)
+		l.Statements[i] = statement
+	}
+	return l
+}
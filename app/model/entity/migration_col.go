@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// MigrationCol records a one-off change of the data, e.g. a seeding, so it is not made again
+type MigrationCol struct {
+	ID        string    `json:"id" bson:"_id"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+func (MigrationCol) CollectionName() string {
+	return "migrationCol"
+}
+
+// MigrationSeedSourceTypes is the migration seeding sourceTypeCol with DefaultSourceTypes
+const MigrationSeedSourceTypes = "seed-source-types"
+
+// MigrationSeedDimensionRatingTypes is the migration seeding ratingTypesNumCol with DefaultDimensionRatingTypesNum
+const MigrationSeedDimensionRatingTypes = "seed-dimension-rating-types"
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Targets the outbox messages are delivered to
+const (
+	OutboxTargetPaymentUpdateFlag    = "payment-update-flag"
+	OutboxTargetPaymentReviewProduct = "payment-review-product-store"
+	OutboxTargetMediaHouseKeeping    = "media-house-keeping"
+	OutboxTargetDapr                 = "dapr"
+	OutboxTargetFinalRating          = "final-rating"
+)
+
+// Statuses of an outbox message, a dead message is not retried until it is replayed
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusProcessing = "processing"
+	OutboxStatusDelivered  = "delivered"
+	OutboxStatusDead       = "dead"
+)
+
+// OutboxCol is a call to another service saved with the change it belongs to,
+// the outbox dispatcher delivers it until it succeeds or runs out of attempts
+// swagger:model OutboxCol
+type OutboxCol struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Target        string             `json:"target" bson:"target"`
+	Payload       OutboxPayload      `json:"payload" bson:"payload"`
+	SubmissionID  string             `json:"submission_id,omitempty" bson:"submission_id,omitempty"`
+	Status        string             `json:"status" bson:"status"`
+	Attempts      int                `json:"attempts" bson:"attempts"`
+	LastError     string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" bson:"next_attempt_at"`
+	LockedUntil   *time.Time         `json:"-" bson:"locked_until,omitempty"`
+	DeliveredAt   *time.Time         `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// OutboxPayload holds the values of the call, only the fields of the target are set.
+// The id of the submission saved with the message is kept in OutboxCol.SubmissionID
+type OutboxPayload struct {
+	OrderNumber string `json:"order_number,omitempty" bson:"order_number,omitempty"`
+	SourceType  string `json:"source_type,omitempty" bson:"source_type,omitempty"`
+	SourceUID   string `json:"source_uid,omitempty" bson:"source_uid,omitempty"`
+	MediaUID    string `json:"media_uid,omitempty" bson:"media_uid,omitempty"`
+	Topic       string `json:"topic,omitempty" bson:"topic,omitempty"`
+	Data        string `json:"data,omitempty" bson:"data,omitempty"`
+}
+
+func (OutboxCol) CollectionName() string {
+	return "outboxCol"
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RatingAggregateCol holds the precomputed summary of the public (not cancelled, approved) submissions of a rating.
+// Histogram is keyed by submission value, a likert submission counts once for every selected statement.
+// swagger:model RatingAggregateCol
+type RatingAggregateCol struct {
+	ID           primitive.ObjectID      `json:"id" bson:"_id,omitempty"`
+	SourceType   string                  `json:"source_type" bson:"source_type"`
+	SourceUID    string                  `json:"source_uid" bson:"source_uid"`
+	RatingID     string                  `json:"rating_id" bson:"rating_id"`
+	Sum          float64                 `json:"sum" bson:"sum"`
+	Count        int64                   `json:"count" bson:"count"`
+	Histogram    map[string]int64        `json:"histogram" bson:"histogram"`
+	CommentCount int64                   `json:"comment_count" bson:"comment_count"`
+	Verified     RatingAggregateVerified `json:"verified" bson:"verified"`
+	UpdatedAt    time.Time               `json:"updated_at" bson:"updated_at"`
+}
+
+// RatingAggregateVerified holds the same summary restricted to the submissions of verified purchases
+type RatingAggregateVerified struct {
+	Sum          float64          `json:"sum" bson:"sum"`
+	Count        int64            `json:"count" bson:"count"`
+	Histogram    map[string]int64 `json:"histogram" bson:"histogram"`
+	CommentCount int64            `json:"comment_count" bson:"comment_count"`
+}
+
+func (RatingAggregateCol) CollectionName() string {
+	return "ratingAggregateCol"
+}
+
+// VerifiedOnly returns the aggregate summarizing the submissions of verified purchases only
+func (a RatingAggregateCol) VerifiedOnly() RatingAggregateCol {
+	a.Sum = a.Verified.Sum
+	a.Count = a.Verified.Count
+	a.Histogram = a.Verified.Histogram
+	a.CommentCount = a.Verified.CommentCount
+	return a
+}
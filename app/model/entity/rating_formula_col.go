@@ -6,6 +6,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// RatingFormulaCol is the formula in use, Version is its active RatingFormulaVersionCol
 // swagger:model RatingFormulaCol
 type RatingFormulaCol struct {
 	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -14,8 +15,11 @@ type RatingFormulaCol struct {
 	RatingTypeId string             `json:"rating_type_id" bson:"rating_type_id,omitempty"`
 	RatingType   string             `json:"rating_type" bson:"rating_type,omitempty"`
 	Status       *bool              `json:"status" bson:"status,omitempty"`
+	Version      int                `json:"version" bson:"version,omitempty"`
 	CreatedAt    time.Time          `json:"created_at" bson:"created_at,omitempty"`
 	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at,omitempty"`
+	DeletedAt    *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy    string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
 func (RatingFormulaCol) CollectionName() string {
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions recorded on a rating formula version
+const (
+	RatingFormulaVersionCreated   = "created"
+	RatingFormulaVersionUpdated   = "updated"
+	RatingFormulaVersionDrafted   = "drafted"
+	RatingFormulaVersionActivated = "activated"
+)
+
+// RatingFormulaVersionCol is an immutable snapshot of a rating formula, the formula points to its active version.
+// Rolling back is activating an older version.
+// swagger:model RatingFormulaVersionCol
+type RatingFormulaVersionCol struct {
+	ID           primitive.ObjectID      `json:"id" bson:"_id,omitempty"`
+	FormulaId    string                  `json:"formula_id" bson:"formula_id"`
+	Version      int                     `json:"version" bson:"version"`
+	SourceType   string                  `json:"source_type" bson:"source_type"`
+	Formula      string                  `json:"formula" bson:"formula"`
+	RatingTypeId string                  `json:"rating_type_id" bson:"rating_type_id"`
+	RatingType   string                  `json:"rating_type" bson:"rating_type"`
+	Note         string                  `json:"note" bson:"note,omitempty"`
+	Action       string                  `json:"action" bson:"action"`
+	CreatedBy    string                  `json:"created_by" bson:"created_by,omitempty"`
+	CreatedAt    time.Time               `json:"created_at" bson:"created_at"`
+	Activations  []RatingFormulaActivity `json:"activations" bson:"activations,omitempty"`
+	IsActive     bool                    `json:"is_active" bson:"-"`
+}
+
+// RatingFormulaActivity records who activated a version and when
+type RatingFormulaActivity struct {
+	ActivatedBy string    `json:"activated_by" bson:"activated_by,omitempty"`
+	ActivatedAt time.Time `json:"activated_at" bson:"activated_at"`
+}
+
+func (RatingFormulaVersionCol) CollectionName() string {
+	return "ratingFormulaVersionCol"
+}
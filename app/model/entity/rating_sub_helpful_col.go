@@ -6,6 +6,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// RatingSubHelpfulCol is the vote of a user on a submission of ratingSubCol or ratingSubMpCol,
+// status is the helpful vote counted by like_counter, not_helpful the vote counted by not_helpful_counter
 // swagger:model RatingSubHelpfulCol
 type RatingSubHelpfulCol struct {
 	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -15,6 +17,7 @@ type RatingSubHelpfulCol struct {
 	IPAddress          string             `json:"ip_address" bson:"ip_address,omitempty"`
 	UserAgent          string             `json:"user_agent" bson:"user_agent,omitempty"`
 	Status             bool               `json:"status" bson:"status,omitempty"`
+	NotHelpful         bool               `json:"not_helpful" bson:"not_helpful,omitempty"`
 	CreatedAt          time.Time          `json:"-" bson:"created_at,omitempty"`
 	UpdatedAt          time.Time          `json:"-" bson:"updated_at,omitempty"`
 }
@@ -22,3 +25,9 @@ type RatingSubHelpfulCol struct {
 func (RatingSubHelpfulCol) CollectionName() string {
 	return "ratingSubHelpfulCol"
 }
+
+// RatingSubHelpfulCounter holds the helpful counters of a submission
+type RatingSubHelpfulCounter struct {
+	LikeCounter       int `bson:"like_counter"`
+	NotHelpfulCounter int `bson:"not_helpful_counter"`
+}
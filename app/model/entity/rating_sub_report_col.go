@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reason codes of a report
+const (
+	ReportReasonSpam      = "spam"
+	ReportReasonOffensive = "offensive"
+	ReportReasonFake      = "fake"
+	ReportReasonOther     = "other"
+)
+
+// RatingSubReportSourceMp is the source of the reports on ratingSubMpCol
+const RatingSubReportSourceMp = "mp"
+
+// ModerationFlagReported flags the submissions hidden because of the reports of the users
+const ModerationFlagReported = "reported"
+
+// RatingSubReportCol is the report of a user on a submission of ratingSubCol or ratingSubMpCol, a user reports a submission once
+// swagger:model RatingSubReportCol
+type RatingSubReportCol struct {
+	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RatingSubmissionID string             `json:"rating_submission_id" bson:"rating_submission_id"`
+	// mp for the submissions of ratingSubMpCol, empty for ratingSubCol
+	Source       string    `json:"source" bson:"source"`
+	UserIDLegacy string    `json:"user_id_legacy" bson:"user_id_legacy"`
+	ReasonCode   string    `json:"reason_code" bson:"reason_code"`
+	Reason       string    `json:"reason" bson:"reason,omitempty"`
+	IPAddress    string    `json:"ip_address" bson:"ip_address,omitempty"`
+	UserAgent    string    `json:"user_agent" bson:"user_agent,omitempty"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+}
+
+func (RatingSubReportCol) CollectionName() string {
+	return "ratingSubReportCol"
+}
+
+// RatingSubReportGroup is the reports of a submission with the moderation of the submission
+type RatingSubReportGroup struct {
+	RatingSubmissionID string               `bson:"_id"`
+	TotalReport        int                  `bson:"total_report"`
+	LastReportedAt     time.Time            `bson:"last_reported_at"`
+	Reports            []RatingSubReportCol `bson:"reports"`
+	Comment            *string              `bson:"comment"`
+	ModerationStatus   string               `bson:"moderation_status"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RatingSubRevisionSourceMp is the source of the revisions of ratingSubMpCol
+const RatingSubRevisionSourceMp = "mp"
+
+// RatingSubRevisionCol is a prior version of a submission of ratingSubCol or ratingSubMpCol, saved by the edit replacing it.
+// Revision n is the version before the edit n, CreatedAt and EditedBy are the time and the actor of that edit.
+// swagger:model RatingSubRevisionCol
+type RatingSubRevisionCol struct {
+	ID                 primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RatingSubmissionID string             `json:"rating_submission_id" bson:"rating_submission_id"`
+	// mp for the submissions of ratingSubMpCol, empty for ratingSubCol
+	Source    string     `json:"source" bson:"source"`
+	Revision  int        `json:"revision" bson:"revision"`
+	RatingID  string     `json:"rating_id,omitempty" bson:"rating_id,omitempty"`
+	Value     string     `json:"value" bson:"value"`
+	Comment   *string    `json:"comment" bson:"comment,omitempty"`
+	Media     []MediaObj `json:"media,omitempty" bson:"media,omitempty"`
+	EditedBy  string     `json:"edited_by" bson:"edited_by"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+}
+
+func (RatingSubRevisionCol) CollectionName() string {
+	return "ratingSubRevisionCol"
+}
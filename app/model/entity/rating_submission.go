@@ -7,28 +7,65 @@ import (
 )
 
 type RatingSubmisson struct {
-	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	RatingID        string             `json:"rating_id" bson:"rating_id,omitempty"`
-	UserID          *string            `json:"user_id" bson:"user_id,omitempty"`
-	UserIDLegacy    *string            `json:"user_id_legacy" bson:"user_id_legacy,omitempty"`
-	DisplayName     *string            `json:"display_name" bson:"display_name,omitempty"`
-	Comment         *string            `json:"comment" bson:"comment,omitempty"`
-	Value           string             `json:"value" bson:"value,omitempty"`
-	IPAddress       string             `json:"ip_address" bson:"ip_address,omitempty"`
-	UserAgent       string             `json:"user_agent" bson:"user_agent,omitempty"`
-	Avatar          string             `json:"avatar" bson:"avatar,omitempty"`
-	SourceTransID   string             `json:"source_trans_id" bson:"source_trans_id,omitempty"`
-	LikeCounter     int                `json:"like_counter" bson:"like_counter,omitempty"`
-	UserPlatform    string             `json:"user_platform" bson:"user_platform,omitempty"`
-	Cancelled       bool               `json:"cancelled" bson:"cancelled,omitempty"`
-	CancelledReason string             `json:"cancelled_reason" bson:"cancelled_reason,omitempty"`
-	IsAnonymous     bool               `json:"is_anonymous" bson:"is_anonymous,omitempty"`
-	MediaPath       []string           `json:"media_path" bson:"media_path,omitempty"`
-	IsWithMedia     bool               `json:"is_with_media" bson:"is_with_media,omitempty"`
-	CreatedAt       time.Time          `json:"-" bson:"created_at,omitempty"`
-	UpdatedAt       time.Time          `json:"-" bson:"updated_at,omitempty"`
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RatingID         string             `json:"rating_id" bson:"rating_id,omitempty"`
+	UserID           *string            `json:"user_id" bson:"user_id,omitempty"`
+	UserIDLegacy     *string            `json:"user_id_legacy" bson:"user_id_legacy,omitempty"`
+	DisplayName      *string            `json:"display_name" bson:"display_name,omitempty"`
+	Comment          *string            `json:"comment" bson:"comment,omitempty"`
+	Value            string             `json:"value" bson:"value,omitempty"`
+	IPAddress        string             `json:"ip_address" bson:"ip_address,omitempty"`
+	UserAgent        string             `json:"user_agent" bson:"user_agent,omitempty"`
+	Avatar           string             `json:"avatar" bson:"avatar,omitempty"`
+	SourceTransID    string             `json:"source_trans_id" bson:"source_trans_id,omitempty"`
+	LikeCounter      int                `json:"like_counter" bson:"like_counter,omitempty"`
+	UserPlatform     string             `json:"user_platform" bson:"user_platform,omitempty"`
+	Cancelled        bool               `json:"cancelled" bson:"cancelled,omitempty"`
+	CancelledReason  string             `json:"cancelled_reason" bson:"cancelled_reason,omitempty"`
+	IsAnonymous      bool               `json:"is_anonymous" bson:"is_anonymous,omitempty"`
+	MediaPath        []string           `json:"media_path" bson:"media_path,omitempty"`
+	IsWithMedia      bool               `json:"is_with_media" bson:"is_with_media,omitempty"`
+	CreatedAt        time.Time          `json:"-" bson:"created_at,omitempty"`
+	UpdatedAt        time.Time          `json:"-" bson:"updated_at,omitempty"`
+	ModerationStatus string             `json:"moderation_status" bson:"moderation_status,omitempty"`
+	ModerationFlags  []string           `json:"moderation_flags" bson:"moderation_flags,omitempty"`
+	ModerationReason string             `json:"moderation_reason" bson:"moderation_reason,omitempty"`
+	ModeratedBy      string             `json:"moderated_by" bson:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time         `json:"moderated_at" bson:"moderated_at,omitempty"`
+	// invitation redeemed by the submission, a submission with an invitation is a verified purchase
+	InvitationID       *primitive.ObjectID `json:"invitation_id,omitempty" bson:"invitation_id,omitempty"`
+	IsVerifiedPurchase bool                `json:"is_verified_purchase" bson:"is_verified_purchase"`
+	// normalized comment indexed for the full-text search
+	CommentSearch string `json:"-" bson:"comment_search,omitempty"`
+	// not helpful votes, like_counter counts the helpful ones
+	NotHelpfulCounter int `json:"not_helpful_counter" bson:"not_helpful_counter,omitempty"`
+	// reports of the users, the submission is hidden for moderation when it reaches report.auto-hide-threshold
+	ReportCounter int `json:"report_counter" bson:"report_counter,omitempty"`
+	// edits of the submission, every prior version is kept in ratingSubRevisionCol
+	EditCounter int        `json:"edit_counter" bson:"edit_counter,omitempty"`
+	EditedAt    *time.Time `json:"edited_at" bson:"edited_at,omitempty"`
+	EditedBy    string     `json:"edited_by" bson:"edited_by,omitempty"`
+	// soft delete, the submission is hard deleted by the purge job after soft-delete.retention-days
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	// keys of the tags of submissionTagCol selected with the submission
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
 }
 
+// Moderation status of a submission, submissions stored before moderation have no status and count as approved
+const (
+	ModerationStatusPending  = "pending"
+	ModerationStatusApproved = "approved"
+	ModerationStatusRejected = "rejected"
+)
+
+// Moderation flags of the spam heuristics, a flagged submission waits for moderation
+const (
+	ModerationFlagSpamDuplicateComment = "spam_duplicate_comment"
+	ModerationFlagSpamIPBurst          = "spam_ip_burst"
+	ModerationFlagSpamNewAccount       = "spam_new_account"
+)
+
 func (RatingSubmisson) CollectionName() string {
 	return "ratingSubCol"
 }
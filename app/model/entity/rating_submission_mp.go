@@ -7,39 +7,81 @@ import (
 )
 
 type RatingSubmissionMp struct {
-	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	RatingID        string             `json:"rating_id" bson:"rating_id,omitempty"`
-	UserID          *string            `json:"user_id" bson:"user_id,omitempty"`
-	UserIDLegacy    *string            `json:"user_id_legacy" bson:"user_id_legacy,omitempty"`
-	DisplayName     *string            `json:"display_name" bson:"display_name,omitempty"`
-	Comment         *string            `json:"comment" bson:"comment,omitempty"`
-	Value           int                `json:"value" bson:"value,omitempty"`
-	IPAddress       string             `json:"ip_address" bson:"ip_address,omitempty"`
-	UserAgent       string             `json:"user_agent" bson:"user_agent,omitempty"`
-	Avatar          string             `json:"avatar" bson:"avatar,omitempty"`
-	SourceUID       string             `json:"source_uid" bson:"source_uid"`
-	SourceType      string             `json:"source_type" bson:"source_type"`
-	SourceTransID   string             `json:"source_trans_id" bson:"source_trans_id,omitempty"`
-	LikeCounter     int                `json:"like_counter" bson:"like_counter"`
-	UserPlatform    string             `json:"user_platform" bson:"user_platform,omitempty"`
-	Cancelled       bool               `json:"cancelled" bson:"cancelled"`
-	CancelledReason string             `json:"cancelled_reason" bson:"cancelled_reason"`
-	IsAnonymous     bool               `json:"is_anonymous" bson:"is_anonymous,omitempty"`
-	Media           []MediaObj         `json:"media" bson:"media"`
-	IsWithMedia     bool               `json:"is_with_media" bson:"is_with_media"`
-	OrderNumber     string             `json:"order_number" bson:"order_number"`
-	CreatedAt       time.Time          `json:"-" bson:"created_at,omitempty"`
-	UpdatedAt       time.Time          `json:"-" bson:"updated_at,omitempty"`
-	RatingTypeID    string             `json:"rating_type_id" bson:"rating_type_id, omitempty"`
-	Reply           string             `json:"reply" bson:"reply"`
-	ReplyBy         string             `json:"reply_by" bson:"reply_by"`
-	StoreUID        string             `json:"store_uid" bson:"store_uid"`
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	RatingID         string             `json:"rating_id" bson:"rating_id,omitempty"`
+	UserID           *string            `json:"user_id" bson:"user_id,omitempty"`
+	UserIDLegacy     *string            `json:"user_id_legacy" bson:"user_id_legacy,omitempty"`
+	DisplayName      *string            `json:"display_name" bson:"display_name,omitempty"`
+	Comment          *string            `json:"comment" bson:"comment,omitempty"`
+	Value            int                `json:"value" bson:"value,omitempty"`
+	IPAddress        string             `json:"ip_address" bson:"ip_address,omitempty"`
+	UserAgent        string             `json:"user_agent" bson:"user_agent,omitempty"`
+	Avatar           string             `json:"avatar" bson:"avatar,omitempty"`
+	SourceUID        string             `json:"source_uid" bson:"source_uid"`
+	SourceType       string             `json:"source_type" bson:"source_type"`
+	SourceTransID    string             `json:"source_trans_id" bson:"source_trans_id,omitempty"`
+	LikeCounter      int                `json:"like_counter" bson:"like_counter"`
+	UserPlatform     string             `json:"user_platform" bson:"user_platform,omitempty"`
+	Cancelled        bool               `json:"cancelled" bson:"cancelled"`
+	CancelledReason  string             `json:"cancelled_reason" bson:"cancelled_reason"`
+	IsAnonymous      bool               `json:"is_anonymous" bson:"is_anonymous,omitempty"`
+	Media            []MediaObj         `json:"media" bson:"media"`
+	IsWithMedia      bool               `json:"is_with_media" bson:"is_with_media"`
+	OrderNumber      string             `json:"order_number" bson:"order_number"`
+	CreatedAt        time.Time          `json:"-" bson:"created_at,omitempty"`
+	UpdatedAt        time.Time          `json:"-" bson:"updated_at,omitempty"`
+	RatingTypeID     string             `json:"rating_type_id" bson:"rating_type_id, omitempty"`
+	Reply            string             `json:"reply" bson:"reply"`
+	ReplyBy          string             `json:"reply_by" bson:"reply_by"`
+	RepliedAt        *time.Time         `json:"replied_at" bson:"replied_at,omitempty"`
+	ReplyEdited      bool               `json:"reply_edited" bson:"reply_edited"`
+	ReplyHidden      bool               `json:"reply_hidden" bson:"reply_hidden"`
+	ReplyHiddenBy    string             `json:"reply_hidden_by" bson:"reply_hidden_by"`
+	ReplyHistory     []ReplyHistoryObj  `json:"reply_history" bson:"reply_history,omitempty"`
+	StoreUID         string             `json:"store_uid" bson:"store_uid"`
+	ModerationStatus string             `json:"moderation_status" bson:"moderation_status,omitempty"`
+	ModerationFlags  []string           `json:"moderation_flags" bson:"moderation_flags,omitempty"`
+	ModerationReason string             `json:"moderation_reason" bson:"moderation_reason,omitempty"`
+	ModeratedBy      string             `json:"moderated_by" bson:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time         `json:"moderated_at" bson:"moderated_at,omitempty"`
+	// invitation redeemed by the submission, a submission with an invitation is a verified purchase
+	InvitationID       *primitive.ObjectID `json:"invitation_id,omitempty" bson:"invitation_id,omitempty"`
+	IsVerifiedPurchase bool                `json:"is_verified_purchase" bson:"is_verified_purchase"`
+	// normalized comment indexed for the full-text search
+	CommentSearch string `json:"-" bson:"comment_search,omitempty"`
+	// not helpful votes, like_counter counts the helpful ones
+	NotHelpfulCounter int `json:"not_helpful_counter" bson:"not_helpful_counter,omitempty"`
+	// reports of the users, the submission is hidden for moderation when it reaches report.auto-hide-threshold
+	ReportCounter int `json:"report_counter" bson:"report_counter,omitempty"`
+	// edits of the submission, every prior version is kept in ratingSubRevisionCol
+	EditCounter int        `json:"edit_counter" bson:"edit_counter,omitempty"`
+	EditedAt    *time.Time `json:"edited_at" bson:"edited_at,omitempty"`
+	EditedBy    string     `json:"edited_by" bson:"edited_by,omitempty"`
+	// soft delete, the submission is hard deleted by the purge job after soft-delete.retention-days
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	// values of the dimensions of the review, e.g. delivery speed of a store, each one is summarized apart from value
+	Dimensions []DimensionValue `json:"dimensions,omitempty" bson:"dimensions,omitempty"`
+	// keys of the tags of submissionTagCol selected with the review
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
 }
 
 func (RatingSubmissionMp) CollectionName() string {
 	return "ratingSubMpCol"
 }
 
+type ReplyHistoryObj struct {
+	Reply     string    `json:"reply" bson:"reply"`
+	ReplyBy   string    `json:"reply_by" bson:"reply_by"`
+	RepliedAt time.Time `json:"replied_at" bson:"replied_at"`
+}
+
+// DimensionValue is the value of a named dimension of the review, valid for the numeric rating type of the dimension
+type DimensionValue struct {
+	Name  string  `json:"name" bson:"name"`
+	Value float64 `json:"value" bson:"value"`
+}
+
 type MediaObj struct {
 	UID       string `json:"uid" bson:"uid"`
 	MediaPath string `json:"media_path" bson:"media_path"`
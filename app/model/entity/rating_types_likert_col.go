@@ -1,31 +1,107 @@
 package entity
 
 import (
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // swagger:model RatingTypesLikertCol
 type RatingTypesLikertCol struct {
-	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Type          string             `json:"type" bson:"type,omitempty"`
-	Description   *string            `json:"description" bson:"description,omitempty"`
-	NumStatements int                `json:"num_statements" bson:"num_statements,omitempty"`
-	Statement01   *string            `json:"statement_01" bson:"statement_01,omitempty"`
-	Statement02   *string            `json:"statement_02" bson:"statement_02,omitempty"`
-	Statement03   *string            `json:"statement_03" bson:"statement_03,omitempty"`
-	Statement04   *string            `json:"statement_04" bson:"statement_04,omitempty"`
-	Statement05   *string            `json:"statement_05" bson:"statement_05,omitempty"`
-	Statement06   *string            `json:"statement_06" bson:"statement_06,omitempty"`
-	Statement07   *string            `json:"statement_07" bson:"statement_07,omitempty"`
-	Statement08   *string            `json:"statement_08" bson:"statement_08,omitempty"`
-	Statement09   *string            `json:"statement_09" bson:"statement_09,omitempty"`
-	Statement10   *string            `json:"statement_10" bson:"statement_10,omitempty"`
-	Status        *bool              `json:"status" bson:"status,omitempty"`
-	CreatedAt     *time.Time         `json:"created_at" bson:"created_at,omitempty"`
-	UpdatedAt     *time.Time         `json:"updated_at" bson:"updated_at,omitempty"`
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Type        string             `json:"type" bson:"type,omitempty"`
+	Description *string            `json:"description" bson:"description,omitempty"`
+	// DescriptionI18n is the description by language, Description is returned for a language without text
+	DescriptionI18n LocalizedText     `json:"description_i18n,omitempty" bson:"description_i18n,omitempty"`
+	NumStatements   int               `json:"num_statements" bson:"num_statements,omitempty"`
+	Statements      []LikertStatement `json:"statements" bson:"statements,omitempty"`
+	// Deprecated: statement_01..statement_10 are read from the documents not migrated yet and returned
+	// from statements for the clients of the old shape, use Statements
+	Statement01 *string    `json:"statement_01" bson:"statement_01,omitempty"`
+	Statement02 *string    `json:"statement_02" bson:"statement_02,omitempty"`
+	Statement03 *string    `json:"statement_03" bson:"statement_03,omitempty"`
+	Statement04 *string    `json:"statement_04" bson:"statement_04,omitempty"`
+	Statement05 *string    `json:"statement_05" bson:"statement_05,omitempty"`
+	Statement06 *string    `json:"statement_06" bson:"statement_06,omitempty"`
+	Statement07 *string    `json:"statement_07" bson:"statement_07,omitempty"`
+	Statement08 *string    `json:"statement_08" bson:"statement_08,omitempty"`
+	Statement09 *string    `json:"statement_09" bson:"statement_09,omitempty"`
+	Statement10 *string    `json:"statement_10" bson:"statement_10,omitempty"`
+	Status      *bool      `json:"status" bson:"status,omitempty"`
+	CreatedAt   *time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy   string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
 func (RatingTypesLikertCol) CollectionName() string {
 	return "ratingTypesLikertCol"
 }
+
+// LikertStatement is a statement of a likert type, a submission references the statements it selects by key
+// so that the label can be renamed without changing the history
+type LikertStatement struct {
+	Key   string `json:"key" bson:"key"`
+	Label string `json:"label" bson:"label"`
+	Icon  string `json:"icon,omitempty" bson:"icon,omitempty"`
+	// LabelI18n is the label by language, Label is returned for a language without text
+	LabelI18n LocalizedText `json:"label_i18n,omitempty" bson:"label_i18n,omitempty"`
+}
+
+// GetStatements returns the ordered statements, a document not migrated yet has them in statement_01..statement_10
+func (l RatingTypesLikertCol) GetStatements() []LikertStatement {
+	if len(l.Statements) > 0 {
+		return l.Statements
+	}
+	return LikertStatementsFromLegacy(l.legacyStatements())
+}
+
+// WithLegacyStatements fills statements and statement_01..statement_10 from each other, the api returns both shapes
+func (l RatingTypesLikertCol) WithLegacyStatements() RatingTypesLikertCol {
+	l.Statements = l.GetStatements()
+	LikertStatementsToLegacy(l.Statements, l.legacyStatements())
+	return l
+}
+
+func (l *RatingTypesLikertCol) legacyStatements() []**string {
+	return []**string{&l.Statement01, &l.Statement02, &l.Statement03, &l.Statement04, &l.Statement05,
+		&l.Statement06, &l.Statement07, &l.Statement08, &l.Statement09, &l.Statement10}
+}
+
+// LikertStatementsFromLegacy converts statement_01..statement_10, each statement is keyed by its position
+// which is the value the submissions made before the migration hold
+func LikertStatementsFromLegacy(legacy []**string) []LikertStatement {
+	statements := make([]LikertStatement, 0, len(legacy))
+	for i, statement := range legacy {
+		if *statement != nil && **statement != "" {
+			statements = append(statements, LikertStatement{Key: strconv.Itoa(i + 1), Label: **statement})
+		}
+	}
+	return statements
+}
+
+// LikertStatementsToLegacy fills statement_0N with the label of the statement keyed N, a statement whose key
+// is not 1..10 takes the first field left free in order and the fields without a statement are cleared
+func LikertStatementsToLegacy(statements []LikertStatement, legacy []**string) {
+	for i := range legacy {
+		*legacy[i] = nil
+	}
+	var unkeyed []LikertStatement
+	for _, statement := range statements {
+		position, err := strconv.Atoi(statement.Key)
+		if err != nil || position < 1 || position > len(legacy) || *legacy[position-1] != nil {
+			unkeyed = append(unkeyed, statement)
+			continue
+		}
+		label := statement.Label
+		*legacy[position-1] = &label
+	}
+	for i := 0; i < len(legacy) && len(unkeyed) > 0; i++ {
+		if *legacy[i] == nil {
+			label := unkeyed[0].Label
+			*legacy[i] = &label
+			unkeyed = unkeyed[1:]
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package entity
 
 import (
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // swagger:model RatingTypesNumCol
@@ -10,15 +12,40 @@ type RatingTypesNumCol struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Type        string             `json:"type,omitempty" bson:"type,omitempty"`
 	Description *string            `json:"description,omitempty" bson:"description,omitempty"`
-	MinScore    *int               `json:"min_score,omitempty" bson:"min_score,omitempty"`
-	MaxScore    *int               `json:"max_score,omitempty" bson:"max_score,omitempty"`
-	Scale       *int               `json:"scale,omitempty" bson:"scale,omitempty"`
-	Intervals   *int               `json:"intervals,omitempty" bson:"intervals,omitempty"`
-	Status      *bool              `json:"status,omitempty" bson:"status,omitempty"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at,omitempty"`
-	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at,omitempty"`
+	// DescriptionI18n is the description by language, Description is returned for a language without text
+	DescriptionI18n LocalizedText `json:"description_i18n,omitempty" bson:"description_i18n,omitempty"`
+	MinScore        *int          `json:"min_score,omitempty" bson:"min_score,omitempty"`
+	MaxScore        *int          `json:"max_score,omitempty" bson:"max_score,omitempty"`
+	Scale           *int          `json:"scale,omitempty" bson:"scale,omitempty"`
+	Intervals       *int          `json:"intervals,omitempty" bson:"intervals,omitempty"`
+	Status          *bool         `json:"status,omitempty" bson:"status,omitempty"`
+	CreatedAt       time.Time     `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt       time.Time     `json:"updated_at" bson:"updated_at,omitempty"`
+	DeletedAt       *time.Time    `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy       string        `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
 func (RatingTypesNumCol) CollectionName() string {
 	return "ratingTypesNumCol"
 }
+
+// DefaultDimensionRatingTypesNum returns the numeric rating types of the dimensions of DefaultSourceTypes, scored from 1 to 5
+func DefaultDimensionRatingTypesNum() []RatingTypesNumCol {
+	var ratingTypes []RatingTypesNumCol
+	for _, sourceType := range DefaultSourceTypes {
+		for _, dimension := range sourceType.Dimensions {
+			description := strings.ReplaceAll(dimension.Name, "_", " ")
+			minScore, maxScore, scale, intervals, status := 1, 5, 0, 5, true
+			ratingTypes = append(ratingTypes, RatingTypesNumCol{
+				Type:        dimension.RatingType,
+				Description: &description,
+				MinScore:    &minScore,
+				MaxScore:    &maxScore,
+				Scale:       &scale,
+				Intervals:   &intervals,
+				Status:      &status,
+			})
+		}
+	}
+	return ratingTypes
+}
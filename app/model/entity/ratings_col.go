@@ -7,17 +7,21 @@ import (
 
 // swagger:model Rating
 type RatingsCol struct {
-	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name           string             `json:"name,omitempty" bson:"name,omitempty"`
-	Description    *string            `json:"description,omitempty" bson:"description,omitempty"`
-	SourceUid      string             `json:"source_uid,omitempty" bson:"source_uid,omitempty"`
-	SourceType     string             `json:"source_type,omitempty" bson:"source_type,omitempty"`
-	RatingType     string             `json:"rating_type,omitempty" bson:"rating_type,omitempty"`
-	RatingTypeId   string             `json:"rating_type_id,omitempty" bson:"rating_type_id,omitempty"`
-	CommentAllowed *bool              `json:"comment_allowed,omitempty" bson:"comment_allowed,omitempty"`
-	Status         *bool              `json:"status,omitempty" bson:"status,omitempty"`
-	CreatedAt      time.Time          `json:"created_at" bson:"created_at,omitempty"`
-	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at,omitempty"`
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name,omitempty" bson:"name,omitempty"`
+	Description *string            `json:"description,omitempty" bson:"description,omitempty"`
+	// DescriptionI18n is the description by language, Description is returned for a language without text
+	DescriptionI18n LocalizedText `json:"description_i18n,omitempty" bson:"description_i18n,omitempty"`
+	SourceUid       string        `json:"source_uid,omitempty" bson:"source_uid,omitempty"`
+	SourceType      string        `json:"source_type,omitempty" bson:"source_type,omitempty"`
+	RatingType      string        `json:"rating_type,omitempty" bson:"rating_type,omitempty"`
+	RatingTypeId    string        `json:"rating_type_id,omitempty" bson:"rating_type_id,omitempty"`
+	CommentAllowed  *bool         `json:"comment_allowed,omitempty" bson:"comment_allowed,omitempty"`
+	Status          *bool         `json:"status,omitempty" bson:"status,omitempty"`
+	CreatedAt       time.Time     `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt       time.Time     `json:"updated_at" bson:"updated_at,omitempty"`
+	DeletedAt       *time.Time    `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy       string        `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
 func (RatingsCol) CollectionName() string {
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Statuses of a review invitation
+const (
+	ReviewInvitationStatusPending   = "pending"
+	ReviewInvitationStatusSubmitted = "submitted"
+	ReviewInvitationStatusCancelled = "cancelled"
+)
+
+// ReviewInvitationCol invites the buyer of a completed order to review a product or the store of the order
+// swagger:model ReviewInvitationCol
+type ReviewInvitationCol struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OrderNumber string             `json:"order_number" bson:"order_number"`
+	UserID      string             `json:"user_id" bson:"user_id"`
+	SourceType  string             `json:"source_type" bson:"source_type"`
+	SourceUID   string             `json:"source_uid" bson:"source_uid"`
+	StoreUID    string             `json:"store_uid" bson:"store_uid"`
+	// rating types the invitation can be redeemed for, any rating type when empty
+	RatingTypes  []string   `json:"rating_types" bson:"rating_types"`
+	Status       string     `json:"status" bson:"status"`
+	ExpiredAt    time.Time  `json:"expired_at" bson:"expired_at"`
+	SubmissionID string     `json:"submission_id,omitempty" bson:"submission_id,omitempty"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty" bson:"submitted_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" bson:"updated_at"`
+}
+
+func (ReviewInvitationCol) CollectionName() string {
+	return "reviewInvitationCol"
+}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SourceTypeCol is a source type of the registry, e.g. doctor or product, with the scale of its ratings
+// swagger:model SourceTypeCol
+type SourceTypeCol struct {
+	ID   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name string             `json:"name" bson:"name"`
+	// rating types whose submissions belong to the source type, e.g. rating_for_product
+	RatingTypes []string `json:"rating_types" bson:"rating_types"`
+	// the submissions of a marketplace source type are kept in ratingSubMpCol
+	IsMarketplace bool `json:"is_marketplace" bson:"is_marketplace"`
+	// highest value of the scale, e.g. 5 for 1-5, 0 when the values come from the rating type
+	Scale int `json:"scale" bson:"scale"`
+	// values allowed for a submission, from the highest
+	AllowedValues []string `json:"allowed_values" bson:"allowed_values"`
+	// formula of the summaries while the source type has no active rating formula
+	DefaultFormula string `json:"default_formula" bson:"default_formula"`
+	// topic of the final rating events of a marketplace source type, no event is published when empty
+	FinalRatingTopic string `json:"final_rating_topic" bson:"final_rating_topic"`
+	// source type of the stores selling the sources, e.g. store for product: the submissions name the store in store_uid,
+	// a source of the store source type is identified by that store_uid
+	StoreSourceType string `json:"store_source_type" bson:"store_source_type"`
+	// dimensions scored besides the value by the marketplace submissions, e.g. delivery_speed for store
+	Dimensions   []RatingDimension `json:"dimensions" bson:"dimensions"`
+	AllowComment bool              `json:"allow_comment" bson:"allow_comment"`
+	AllowMedia   bool              `json:"allow_media" bson:"allow_media"`
+	CreatedAt    time.Time         `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt    time.Time         `json:"updated_at" bson:"updated_at,omitempty"`
+}
+
+// RatingDimension is a named dimension of the marketplace submissions, scored with the numeric rating type RatingType
+type RatingDimension struct {
+	Name       string `json:"name" bson:"name"`
+	RatingType string `json:"rating_type" bson:"rating_type"`
+}
+
+func (SourceTypeCol) CollectionName() string {
+	return "sourceTypeCol"
+}
+
+// MaximumValue returns the highest value of the scale, e.g. "5.0"
+func (s SourceTypeCol) MaximumValue() string {
+	return fmt.Sprintf("%.1f", float64(s.Scale))
+}
+
+// HasRatingType reports whether the submissions of the rating type belong to the source type
+func (s SourceTypeCol) HasRatingType(ratingType string) bool {
+	for _, rt := range s.RatingTypes {
+		if rt == ratingType {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSourceTypes seed the registry when sourceTypeCol is empty
+var DefaultSourceTypes = []SourceTypeCol{
+	{Name: "hospital", AllowComment: true, AllowMedia: true},
+	{Name: "article", AllowComment: true, AllowMedia: true},
+	{Name: "doctor", AllowComment: true, AllowMedia: true},
+	{Name: "layanan", RatingTypes: []string{"review_for_layanan"}, AllowComment: true, AllowMedia: true},
+	{
+		Name:             "product",
+		RatingTypes:      []string{"rating_for_product"},
+		IsMarketplace:    true,
+		Scale:            5,
+		AllowedValues:    []string{"5", "4", "3", "2", "1"},
+		DefaultFormula:   "sum / count",
+		FinalRatingTopic: "queuing.rnr.ts-final-rating",
+		StoreSourceType:  "store",
+		Dimensions: []RatingDimension{
+			{Name: "as_described", RatingType: "rating_for_product_as_described"},
+			{Name: "value_for_money", RatingType: "rating_for_product_value_for_money"},
+		},
+		AllowComment: true,
+		AllowMedia:   true,
+	},
+	{
+		Name:             "store",
+		RatingTypes:      []string{"rating_for_store"},
+		IsMarketplace:    true,
+		Scale:            3,
+		AllowedValues:    []string{"3", "2", "1"},
+		DefaultFormula:   "sum / count",
+		FinalRatingTopic: "queuing.rnr.ts-final-rating-store",
+		Dimensions: []RatingDimension{
+			{Name: "delivery_speed", RatingType: "rating_for_store_delivery_speed"},
+			{Name: "packaging", RatingType: "rating_for_store_packaging"},
+			{Name: "seller_response", RatingType: "rating_for_store_seller_response"},
+		},
+		AllowComment: true,
+		AllowMedia:   true,
+	},
+}
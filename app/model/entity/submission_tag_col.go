@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SubmissionTagCol is a quick-pick reason of the catalogue of a source type, e.g. "Late delivery" for 1-2 stars,
+// a submission references the tags it selects by key
+// swagger:model SubmissionTagCol
+type SubmissionTagCol struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	SourceType string             `json:"source_type" bson:"source_type"`
+	Key        string             `json:"key" bson:"key"`
+	Label      string             `json:"label" bson:"label"`
+	// LabelI18n is the label by language, Label is returned for a language without text
+	LabelI18n LocalizedText `json:"label_i18n,omitempty" bson:"label_i18n,omitempty"`
+	// range of the values of the submissions the tag is offered for, both 0 for every value
+	MinValue  float64   `json:"min_value" bson:"min_value"`
+	MaxValue  float64   `json:"max_value" bson:"max_value"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at,omitempty"`
+}
+
+func (SubmissionTagCol) CollectionName() string {
+	return "submissionTagCol"
+}
+
+// MatchesValue reports whether the tag is offered for a submission with value
+func (t SubmissionTagCol) MatchesValue(value float64) bool {
+	if t.MinValue == 0 && t.MaxValue == 0 {
+		return true
+	}
+	return value >= t.MinValue && value <= t.MaxValue
+}
+
+// Localized returns the tag with the label in lang
+func (t SubmissionTagCol) Localized(lang string) SubmissionTagCol {
+	t.Label = t.LabelI18n.Localize(lang, t.Label)
+	return t
+}
@@ -0,0 +1,114 @@
+package request_dapr
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"go-klikdokter/helper/message"
+
+	validation "github.com/itgelo/ozzo-validation/v4"
+)
+
+// CloudEventRequest is the CloudEvents envelope dapr delivers to the subscribers,
+// an event published with a raw payload has no envelope and is kept whole in Data
+type CloudEventRequest struct {
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	SpecVersion     string          `json:"specversion"`
+	DataContentType string          `json:"datacontenttype"`
+	Topic           string          `json:"topic"`
+	PubsubName      string          `json:"pubsubname"`
+	Data            json.RawMessage `json:"data"`
+	DataBase64      string          `json:"data_base64"`
+	raw             []byte
+}
+
+// ParseCloudEvent reads the body delivered by dapr
+func ParseCloudEvent(body []byte) (CloudEventRequest, error) {
+	var event CloudEventRequest
+	if err := json.Unmarshal(body, &event); err != nil {
+		return event, err
+	}
+	event.raw = body
+	if event.SpecVersion == "" {
+		// raw payload
+		event = CloudEventRequest{Data: body, raw: body}
+	}
+	return event, nil
+}
+
+// EventData returns the data of the event, data sent as a JSON string or in base64 is decoded
+func (e CloudEventRequest) EventData() ([]byte, error) {
+	if e.DataBase64 != "" {
+		return base64.StdEncoding.DecodeString(e.DataBase64)
+	}
+	if len(e.Data) == 0 {
+		return nil, errors.New("event has no data")
+	}
+	var text string
+	if err := json.Unmarshal(e.Data, &text); err == nil {
+		return []byte(text), nil
+	}
+	return e.Data, nil
+}
+
+// IdempotencyKey identifies the event across redeliveries, by the id of its envelope
+// or by the hash of the payload when it was published raw
+func (e CloudEventRequest) IdempotencyKey(topic string) string {
+	if e.ID != "" {
+		return topic + ":" + e.Source + ":" + e.ID
+	}
+	hash := sha256.Sum256(e.raw)
+	return topic + ":" + hex.EncodeToString(hash[:])
+}
+
+type OrderItemEvent struct {
+	ProductUID string `json:"product_uid"`
+	StoreUID   string `json:"store_uid"`
+}
+
+// OrderCompletedEvent is the data of the order completed event
+type OrderCompletedEvent struct {
+	OrderNumber string           `json:"order_number"`
+	UserID      string           `json:"user_id"`
+	StoreUID    string           `json:"store_uid"`
+	Items       []OrderItemEvent `json:"items"`
+}
+
+func (req OrderCompletedEvent) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.OrderNumber, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.UserID, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.Items, validation.Required.Error(message.ErrReq.Message)),
+	)
+}
+
+// OrderRefundedEvent is the data of the order refunded event, an event without items refunds the whole order
+type OrderRefundedEvent struct {
+	OrderNumber string           `json:"order_number"`
+	Reason      string           `json:"reason"`
+	Items       []OrderItemEvent `json:"items"`
+}
+
+func (req OrderRefundedEvent) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.OrderNumber, validation.Required.Error(message.ErrReq.Message)),
+	)
+}
+
+// UserProfileUpdatedEvent is the data of the user profile updated event, only the fields sent are updated
+type UserProfileUpdatedEvent struct {
+	UserID      string  `json:"user_id"`
+	DisplayName *string `json:"display_name"`
+	Avatar      *string `json:"avatar"`
+}
+
+func (req UserProfileUpdatedEvent) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.UserID, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.DisplayName, validation.When(req.DisplayName != nil, validation.Required.Error(message.ErrDisplayNameRequired.Message))),
+	)
+}
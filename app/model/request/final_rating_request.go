@@ -0,0 +1,37 @@
+package request
+
+import (
+	"fmt"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"strings"
+
+	validation "github.com/itgelo/ozzo-validation/v4"
+)
+
+// swagger:parameters republishFinalRatings
+type ReqRepublishFinalRatingsBody struct {
+	//  in: body
+	// required: true
+	Body RepublishFinalRatingsRequest `json:"body"`
+}
+
+type RepublishFinalRatingsRequest struct {
+	// product or store
+	// required: true
+	SourceType string `json:"source_type"`
+	// skip the sources whose final rating did not change since it was last published
+	OnlyChanged bool `json:"only_changed"`
+}
+
+func (req RepublishFinalRatingsRequest) Validate() error {
+	sourceTypes := global.GetMarketplaceSourceTypeNames()
+	allowed := make([]interface{}, len(sourceTypes))
+	for i, v := range sourceTypes {
+		allowed[i] = v
+	}
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.SourceType, validation.Required.Error(message.ErrReq.Message),
+			validation.In(allowed...).Error(fmt.Sprintf("source_type should be %s", strings.Join(sourceTypes, ",")))),
+	)
+}
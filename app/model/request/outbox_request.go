@@ -0,0 +1,38 @@
+package request
+
+import (
+	"go-klikdokter/app/model/entity"
+
+	validation "github.com/itgelo/ozzo-validation/v4"
+)
+
+// swagger:parameters getOutboxMessages
+type GetOutboxMessagesRequest struct {
+	// pending, processing, delivered or dead
+	// in: query
+	Status string `json:"status" schema:"status"`
+	// payment-update-flag, payment-review-product-store, media-house-keeping or dapr
+	// in: query
+	Target string `json:"target" schema:"target"`
+	// in: query
+	Page int `json:"page" schema:"page"`
+	// in: query
+	Limit int64 `json:"limit" schema:"limit"`
+}
+
+func (req GetOutboxMessagesRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Status, validation.In(entity.OutboxStatusPending, entity.OutboxStatusProcessing,
+			entity.OutboxStatusDelivered, entity.OutboxStatusDead).Error("status should be pending, processing, delivered or dead")),
+		validation.Field(&req.Target, validation.In(entity.OutboxTargetPaymentUpdateFlag, entity.OutboxTargetPaymentReviewProduct,
+			entity.OutboxTargetMediaHouseKeeping, entity.OutboxTargetDapr, entity.OutboxTargetFinalRating).
+			Error("target should be payment-update-flag, payment-review-product-store, media-house-keeping, dapr or final-rating")),
+	)
+}
+
+// swagger:parameters getOutboxMessageById replayOutboxMessage
+type OutboxMessageRequest struct {
+	// in: path
+	// required: true
+	Id string `json:"id"`
+}
@@ -2,10 +2,10 @@ package publicrequest
 
 import (
 	"fmt"
+	"go-klikdokter/helper/global"
 	"strings"
 
 	validation "github.com/itgelo/ozzo-validation/v4"
-	"github.com/spf13/viper"
 )
 
 // swagger:parameters GetPublicListRatingSummaryMpRequest
@@ -14,7 +14,7 @@ type GetPublicListRatingSummaryMpRequest struct {
 	// in: path
 	// required: true
 	SourceType string `json:"source_type"`
-	// Filter available {"source_uid": [""], "rating_type": [""]}
+	// Filter available {"source_uid": [""], "rating_type": [""], "verified_only": true}
 	Filter string `json:"filter" schema:"filter" binding:"omitempty"`
 	Limit  int    `json:"limit" schema:"limit" binding:"omitempty,numeric,min=1,max=100"`
 	Page   int    `json:"page" schema:"page" binding:"omitempty,numeric,min=1"`
@@ -32,12 +32,14 @@ type GetPublicListRatingSubmissionMpRequest struct {
 	// in: path
 	// required: true
 	SourceUID string `json:"source_uid"`
-	// Filter available {"user_id_legacy": [""], "source_trans_id": [""], "value": "", "is_with_media": true}
+	// Filter available {"user_id_legacy": [""], "source_trans_id": [""], "value": "", "is_with_media": true, "verified_only": true}
 	Filter string `json:"filter" schema:"filter" binding:"omitempty"`
 	Limit  int    `json:"limit" schema:"limit" binding:"omitempty,numeric,min=1,max=100"`
 	Page   int    `json:"page" schema:"page" binding:"omitempty,numeric,min=1"`
 	Sort   string `json:"sort" schema:"sort" binding:"omitempty"`
 	Dir    string `json:"dir" schema:"dir" binding:"omitempty"`
+	// Q searches the comments, the best matches come first when sort is empty
+	Q string `json:"q" schema:"q" binding:"omitempty"`
 }
 
 // swagger:parameters GetPublicListRatingSubmissionByIDRequest
@@ -49,6 +51,10 @@ type GetPublicListRatingSubmissionByIDRequest struct {
 	Page   int    `json:"page" schema:"page" binding:"omitempty,numeric,min=1"`
 	Sort   string `json:"sort" schema:"sort" binding:"omitempty"`
 	Dir    string `json:"dir" schema:"dir" binding:"omitempty"`
+	// Lang is the language of the texts and messages, id or en, Accept-Language when empty
+	Lang string `json:"lang" schema:"lang" binding:"omitempty"`
+	// UserIdLegacy is set from the optional bearer token to fill like_by_me
+	UserIdLegacy string `json:"-" schema:"-"`
 }
 
 func (r *GetPublicListRatingSummaryMpRequest) MakeDefaultValueIfEmpty() {
@@ -64,7 +70,7 @@ func (r *GetPublicListRatingSummaryMpRequest) MakeDefaultValueIfEmpty() {
 }
 
 func (req GetPublicListRatingSubmissionMpRequest) ValidateSourceType() error {
-	sourceType := viper.GetStringSlice("source-type")
+	sourceType := global.GetSourceTypeNames()
 	interfaceAllSource := make([]interface{}, len(sourceType))
 	for i, v := range sourceType {
 		interfaceAllSource[i] = v
@@ -93,8 +99,11 @@ type FilterRatingSubmissionMp struct {
 	RatingSubsID  []string     `json:"rating_subs_id"`
 	Value         string       `json:"value"`
 	IsWithMedia   *bool        `json:"is_with_media"`
+	VerifiedOnly  bool         `json:"verified_only"`
 	StartDate     string       `json:"start_date"`
 	EndDate       string       `json:"end_date"`
+	// Q is set from the q parameter
+	Q string `json:"-"`
 }
 
 func (req FilterRatingSubmissionMp) ValidateFormatDate() error {
@@ -117,7 +126,7 @@ func sliceStringToSliceInterface(arr []string) []interface{} {
 
 // swagger:parameters PublicGetListDetailRatingSummaryRequest
 type PublicGetListDetailRatingSummaryRequest struct {
-	// Filter available {"source_uid": [""]}
+	// Filter available {"source_uid": [""], "verified_only": true}
 	// max source_uid is 50
 	// required: true
 	Filter string `json:"filter" schema:"filter" binding:"omitempty"`
@@ -131,6 +140,8 @@ type PublicGetListDetailRatingSummaryRequest struct {
 	// in: path
 	// required: true
 	SourceType string `json:"source_type"`
+	// Lang is the language of the texts and messages, id or en, Accept-Language when empty
+	Lang string `json:"lang" schema:"lang" binding:"omitempty"`
 }
 
 func (r *PublicGetListDetailRatingSummaryRequest) MakeDefaultValueIfEmpty() {
@@ -147,10 +158,12 @@ func (r *PublicGetListDetailRatingSummaryRequest) MakeDefaultValueIfEmpty() {
 
 // swagger:parameters PublicGetRatingSummaryStoreProductRequest
 type PublicGetRatingSummaryStoreProductRequest struct {
-	// Filter available {"store_uid": [""]}
+	// Filter available {"store_uid": [""], "verified_only": true}
 	// max store_uid is 20
 	// required: true
 	Filter string `json:"filter" schema:"filter" binding:"omitempty"`
+	// Lang is the language of the texts and messages, id or en, Accept-Language when empty
+	Lang string `json:"lang" schema:"lang" binding:"omitempty"`
 
 	// Limit int    `json:"-"`
 	// Page  int    `json:"-"`
@@ -3,7 +3,7 @@ package publicrequest
 import (
 	"fmt"
 	validation "github.com/itgelo/ozzo-validation/v4"
-	"github.com/spf13/viper"
+	"go-klikdokter/helper/global"
 	"go-klikdokter/helper/message"
 	"strings"
 )
@@ -14,19 +14,22 @@ type GetPublicListRatingSummaryRequest struct {
 	// in: path
 	// required: true
 	SourceType string `json:"source_type"`
-	// Filter available {"source_uid": [""]}
+	// Filter available {"source_uid": [""], "verified_only": true}
 	Filter string `json:"filter" schema:"filter" binding:"omitempty"`
 	Limit  int    `json:"limit" schema:"limit" binding:"omitempty,numeric,min=1,max=100"`
 	Page   int    `json:"page" schema:"page" binding:"omitempty,numeric,min=1"`
 	Sort   string `json:"sort" schema:"sort" binding:"omitempty"`
 	Dir    string `json:"dir" schema:"dir" binding:"omitempty"`
+	// Lang is the language of the texts and messages, id or en, Accept-Language when empty
+	Lang string `json:"lang" schema:"lang" binding:"omitempty"`
 }
 
 type FilterRatingSummary struct {
-	SourceType string   `json:"source_type"`
-	SourceUid  []string `json:"source_uid"`
-	StoreUID   []string `json:"store_uid,omitempty"`
-	RatingType []string `json:"rating_type"`
+	SourceType   string   `json:"source_type"`
+	SourceUid    []string `json:"source_uid"`
+	StoreUID     []string `json:"store_uid,omitempty"`
+	RatingType   []string `json:"rating_type"`
+	VerifiedOnly bool     `json:"verified_only"`
 }
 
 func (f FilterRatingSummary) ValidateSourceUID() *message.Message {
@@ -63,22 +66,44 @@ type GetPublicListRatingSubmissionRequest struct {
 	// in: path
 	// required: true
 	SourceUID string `json:"source_uid"`
-	// Filter available {"user_id_legacy": [""], "source_trans_id": [""], "value": "", "is_with_media": true, "start_date": "", "end_date": "", ""}
+	// Filter available {"user_id_legacy": [""], "source_trans_id": [""], "value": "", "is_with_media": true, "verified_only": true, "start_date": "", "end_date": "", ""}
 	Filter string `json:"filter" schema:"filter" binding:"omitempty"`
 	Limit  int    `json:"limit" schema:"limit" binding:"omitempty,numeric,min=1,max=100"`
 	Page   int    `json:"page" schema:"page" binding:"omitempty,numeric,min=1"`
-	Sort   string `json:"sort" schema:"sort" binding:"omitempty"`
-	Dir    string `json:"dir" schema:"dir" binding:"omitempty"`
+	// Sort available created_at, most_helpful, highest, lowest, with_media_first, relevance, dir applies to created_at only
+	Sort string `json:"sort" schema:"sort" binding:"omitempty"`
+	Dir  string `json:"dir" schema:"dir" binding:"omitempty"`
+	// Q searches the comments, the best matches come first when sort is empty
+	Q string `json:"q" schema:"q" binding:"omitempty"`
+	// Lang is the language of the texts and messages, id or en, Accept-Language when empty
+	Lang string `json:"lang" schema:"lang" binding:"omitempty"`
+	// UserIdLegacy is set from the optional bearer token to fill like_by_me
+	UserIdLegacy string `json:"-" schema:"-"`
 }
 
+// Sort modes of the public list of rating submissions, the only sorts accepted from the request
+const (
+	SortCreatedAt      = "created_at"
+	SortMostHelpful    = "most_helpful"
+	SortHighest        = "highest"
+	SortLowest         = "lowest"
+	SortWithMediaFirst = "with_media_first"
+	SortRelevance      = "relevance"
+)
+
+var ListSortRatingSubmission = []string{SortCreatedAt, SortMostHelpful, SortHighest, SortLowest, SortWithMediaFirst, SortRelevance}
+
 type FilterRatingSubmission struct {
 	RatingID      []string     `json:"rating_id"`
 	LikertFilter  LikertFilter `json:"likert_filter"`
 	UserIdLegacy  []string     `json:"user_id_legacy"`
 	SourceTransID []string     `json:"source_trans_id"`
 	Value         string       `json:"value"`
+	VerifiedOnly  bool         `json:"verified_only"`
 	StartDate     string       `json:"start_date"`
 	EndDate       string       `json:"end_date"`
+	// Q is set from the q parameter
+	Q string `json:"-"`
 }
 
 func (req FilterRatingSubmission) ValidateFormatDate() error {
@@ -107,6 +132,8 @@ type GetRatingBySourceTypeAndActorRequest struct {
 
 	// Filter available {"rating_type": ["rating_like_dislike", "list_doctor_likert_for_positif_reviews", "list_doctor_likert_for_negative_reviews"]}
 	Filter string `json:"filter" schema:"filter" binding:"omitempty"`
+	// Lang is the language of the texts and messages, id or en, Accept-Language when empty
+	Lang string `json:"lang" schema:"lang" binding:"omitempty"`
 }
 
 type GetRatingBySourceTypeAndActorFilter struct {
@@ -125,8 +152,18 @@ func (r *GetPublicListRatingSummaryRequest) MakeDefaultValueIfEmpty() {
 	}
 }
 
+func (req GetPublicListRatingSubmissionRequest) ValidateSort() error {
+	interfaceAllSort := make([]interface{}, len(ListSortRatingSubmission))
+	for i, v := range ListSortRatingSubmission {
+		interfaceAllSort[i] = v
+	}
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Sort, validation.In(interfaceAllSort...).Error(fmt.Sprintf("sort should be %s", strings.Join(ListSortRatingSubmission, ",")))),
+	)
+}
+
 func (req GetPublicListRatingSubmissionRequest) ValidateSourceType() error {
-	sourceType := viper.GetStringSlice("source-type")
+	sourceType := global.GetSourceTypeNames()
 	interfaceAllSource := make([]interface{}, len(sourceType))
 	for i, v := range sourceType {
 		interfaceAllSource[i] = v
@@ -2,7 +2,7 @@ package request
 
 import (
 	validation "github.com/itgelo/ozzo-validation/v4"
-	"github.com/spf13/viper"
+	"go-klikdokter/helper/global"
 )
 
 // swagger:parameters GetPublicListRatingSubmissionByUserIdRequest
@@ -45,7 +45,7 @@ type UpdateRatingSubDisplayNameRequest struct {
 }
 
 func (req GetPublicListRatingSubmissionByUserIdRequest) ValidateSourceType() error {
-	sourceType := viper.GetStringSlice("source-type")
+	sourceType := global.GetSourceTypeNames()
 	return validation.ValidateStruct(&req,
 		validation.Field(&req.SourceType, validation.In(sourceType[0], sourceType[1], sourceType[2], sourceType[3])),
 	)
@@ -1,5 +1,12 @@
 package request
 
+import (
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+
+	validation "github.com/itgelo/ozzo-validation/v4"
+)
+
 // swagger:parameters getRatingFormulas
 type GetRatingFormulasRequest struct {
 	// Maximum records per page
@@ -55,7 +62,10 @@ type SaveRatingFormula struct {
 	// Source Type of rating formula
 	// in: string
 	SourceType string `json:"source_type,omitempty"`
-	// Formula of rating formula
+	// Formula of rating formula, a govaluate expression validated against sample data.
+	// Variables: sum, count, mean, total_rating_point, total_user_count, global_mean, global_count,
+	// decayed_sum, decayed_count, decayed_mean and hist_<value> (e.g. hist_5).
+	// Functions: bayes(m, C), wilson_lower_bound(positive, total[, z])
 	// in: int
 	Formula string `json:"formula,omitempty"`
 	// Rating Type Id of rating formula
@@ -68,5 +78,90 @@ type SaveRatingFormula struct {
 	// in: bool
 	Status *bool `json:"status"`
 	// For update
-	Id string `json:"-"`
+	Id     string        `json:"-"`
+	JWTObj global.JWTObj `json:"-"`
+}
+
+// swagger:parameters previewRatingFormula
+type ReqPreviewRatingFormulaBody struct {
+	//  in: body
+	// required: true
+	Body PreviewRatingFormulaRequest `json:"body"`
+}
+
+// PreviewRatingFormulaRequest evaluates a candidate formula without saving it,
+// the current formula is the one of formula_id or else the active one of source_type and rating_type_id
+type PreviewRatingFormulaRequest struct {
+	// Id of the current rating formula
+	FormulaId string `json:"formula_id,omitempty"`
+	// Source Type, required without formula_id
+	SourceType string `json:"source_type,omitempty"`
+	// Rating Type Id, required without formula_id unless the source type is a marketplace one
+	RatingTypeId string `json:"rating_type_id,omitempty"`
+	// Candidate formula
+	Formula string `json:"formula,omitempty"`
+	// Stored version of formula_id used as candidate when formula is empty
+	Version int `json:"version,omitempty"`
+	// Source uids to compare, max 100
+	// required: true
+	SourceUids []string `json:"source_uids"`
+}
+
+func (req PreviewRatingFormulaRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.SourceType, validation.When(req.FormulaId == "", validation.Required.Error(message.ErrReq.Message))),
+		validation.Field(&req.RatingTypeId, validation.When(req.FormulaId == "" && !global.IsMarketplaceSourceType(req.SourceType), validation.Required.Error(message.ErrReq.Message))),
+		validation.Field(&req.Formula, validation.When(req.Version == 0, validation.Required.Error(message.ErrReq.Message))),
+		validation.Field(&req.Version, validation.When(req.Formula == "" && req.FormulaId != "", validation.Required.Error(message.ErrReq.Message))),
+		validation.Field(&req.SourceUids, validation.Required.Error(message.ErrReq.Message), validation.Length(1, 100)),
+	)
+}
+
+// swagger:parameters getRatingFormulaVersions
+type GetRatingFormulaVersionsRequest struct {
+	// in: path
+	// required: true
+	Id string `json:"id" schema:"-"`
+	// in: query
+	Page int `json:"page" schema:"page"`
+	// in: query
+	Limit int64 `json:"limit" schema:"limit"`
+}
+
+// swagger:parameters createRatingFormulaVersion
+type ReqCreateRatingFormulaVersionBody struct {
+	// in: path
+	// required: true
+	Id string `json:"id"`
+	//  in: body
+	// required: true
+	Body CreateRatingFormulaVersionRequest `json:"body"`
+}
+
+// CreateRatingFormulaVersionRequest drafts a version of the formula, empty fields are copied from the formula in use
+type CreateRatingFormulaVersionRequest struct {
+	SourceType   string        `json:"source_type,omitempty"`
+	Formula      string        `json:"formula"`
+	RatingTypeId string        `json:"rating_type_id,omitempty"`
+	RatingType   string        `json:"rating_type,omitempty"`
+	Note         string        `json:"note,omitempty"`
+	Id           string        `json:"-"`
+	JWTObj       global.JWTObj `json:"-"`
+}
+
+func (req CreateRatingFormulaVersionRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Formula, validation.Required.Error(message.ErrReq.Message)),
+	)
+}
+
+// swagger:parameters activateRatingFormulaVersion
+type ActivateRatingFormulaVersionRequest struct {
+	// in: path
+	// required: true
+	Id string `json:"id"`
+	// in: path
+	// required: true
+	Version int           `json:"version"`
+	JWTObj  global.JWTObj `json:"-"`
 }
@@ -3,7 +3,8 @@ package request
 import (
 	"fmt"
 	validation "github.com/itgelo/ozzo-validation/v4"
-	"github.com/spf13/viper"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/helper/global"
 	"strings"
 )
 
@@ -28,6 +29,8 @@ type BodyUpdateRatingRequest struct {
 	Name string `json:"name"`
 	// example: Rating Group for Rumah Sakit RS Pondok Indah Bintaro Jaya
 	Description *string `json:"description"`
+	// DescriptionI18n is the description by language, e.g. {"id": "...", "en": "..."}, description is returned for a language without text
+	DescriptionI18n entity.LocalizedText `json:"description_i18n,omitempty"`
 	// example: 2729
 	SourceUid string `json:"source_uid"`
 	// example: hospital
@@ -51,6 +54,8 @@ type SaveRatingRequest struct {
 	Name string `json:"name"`
 	// example: Rating Group for Rumah Sakit RS Pondok Indah Bintaro Jaya
 	Description *string `json:"description"`
+	// DescriptionI18n is the description by language, e.g. {"id": "...", "en": "..."}, description is returned for a language without text
+	DescriptionI18n entity.LocalizedText `json:"description_i18n,omitempty"`
 	// example: 2729
 	SourceUid string `json:"source_uid"`
 	// example: hospital
@@ -80,7 +85,7 @@ type DeleteRatingRequest struct {
 }
 
 func (req SaveRatingRequest) Validate() error {
-	sourceType := viper.GetStringSlice("source-type")
+	sourceType := global.GetSourceTypeNames()
 	interfaceAllSource := make([]interface{}, len(sourceType))
 	for i, v := range sourceType {
 		interfaceAllSource[i] = v
@@ -95,7 +100,7 @@ func (req SaveRatingRequest) Validate() error {
 }
 
 func (req BodyUpdateRatingRequest) Validate() error {
-	sourceType := viper.GetStringSlice("source-type")
+	sourceType := global.GetSourceTypeNames()
 	interfaceAllSource := make([]interface{}, len(sourceType))
 	for i, v := range sourceType {
 		interfaceAllSource[i] = v
@@ -13,12 +13,20 @@ type ReqRatingSubHelpfulBody struct {
 	Body CreateRatingSubHelpfulRequest `json:"body"`
 }
 
+// Votes of a helpful request, voting the same again removes the vote and voting the other one switches it
+const (
+	VoteHelpful    = "helpful"
+	VoteNotHelpful = "not_helpful"
+)
+
 type CreateRatingSubHelpfulRequest struct {
 	RatingSubmissionID string `json:"rating_submission_id"`
 	UserID             string `json:"user_id"`
 	UserIDLegacy       string `json:"user_id_legacy"`
 	IPAddress          string `json:"ip_address"`
 	UserAgent          string `json:"user_agent"`
+	// Vote available helpful, not_helpful, helpful when empty
+	Vote string `json:"vote"`
 }
 
 func (req CreateRatingSubHelpfulRequest) Validate() error {
@@ -26,5 +34,6 @@ func (req CreateRatingSubHelpfulRequest) Validate() error {
 		validation.Field(&req.RatingSubmissionID, validation.Required.Error(message.ErrReq.Message)),
 		validation.Field(&req.IPAddress, validation.Match(regexp.MustCompile(regexIP)).Error(message.ErrIPFormatReq.Message)),
 		validation.Field(&req.UserIDLegacy, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.Vote, validation.In(VoteHelpful, VoteNotHelpful).Error("vote should be helpful,not_helpful")),
 	)
 }
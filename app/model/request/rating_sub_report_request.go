@@ -0,0 +1,55 @@
+package request
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/helper/message"
+
+	validation "github.com/itgelo/ozzo-validation/v4"
+)
+
+// swagger:parameters ReqReportRatingSubmissionBody
+type ReqReportRatingSubmissionBody struct {
+	// ID of Rating Submission
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	// required: true
+	Body ReportRatingSubmissionRequest `json:"body"`
+}
+
+type ReportRatingSubmissionRequest struct {
+	ID string `json:"-"`
+	// source of submission, mp for marketplace (product/store) submissions
+	Source string `json:"source"`
+	// spam, offensive, fake or other
+	ReasonCode string `json:"reason_code"`
+	// optional text of the reporter
+	Reason string `json:"reason"`
+	// UserIDLegacy, IPAddress and UserAgent are read from the token and the request
+	UserIDLegacy string `json:"-"`
+	IPAddress    string `json:"-"`
+	UserAgent    string `json:"-"`
+}
+
+func (req ReportRatingSubmissionRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.ID, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.ReasonCode, validation.Required.Error(message.ErrReq.Message),
+			validation.In(entity.ReportReasonSpam, entity.ReportReasonOffensive, entity.ReportReasonFake, entity.ReportReasonOther).
+				Error("reason_code should be spam,offensive,fake,other")),
+		validation.Field(&req.Reason, validation.Length(0, 500)),
+	)
+}
+
+// swagger:parameters ListRatingSubReportsRequest
+type ListRatingSubReportsRequest struct {
+	// source of submission, mp for marketplace (product/store) submissions
+	// in: query
+	Source string `json:"source,omitempty" schema:"source"`
+	// in: query
+	Page int `json:"page,omitempty" schema:"page"`
+	// in: query
+	Limit int64 `json:"limit,omitempty" schema:"limit"`
+}
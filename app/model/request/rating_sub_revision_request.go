@@ -0,0 +1,12 @@
+package request
+
+// swagger:parameters ListRatingSubRevisionsRequest
+type ListRatingSubRevisionsRequest struct {
+	// ID of Rating Submission
+	// in: path
+	// required: true
+	ID string `json:"id" schema:"-"`
+	// source of submission, mp for marketplace (product/store) submissions
+	// in: query
+	Source string `json:"source,omitempty" schema:"source"`
+}
@@ -3,6 +3,7 @@ package request
 import (
 	"fmt"
 	"go-klikdokter/app/model/entity"
+	"go-klikdokter/helper/global"
 	"go-klikdokter/helper/message"
 	"regexp"
 	"strings"
@@ -19,6 +20,8 @@ type ListRatingSubmissionMpRequest struct {
 	Dir   string `json:"dir,omitempty" schema:"dir" bson:"dir"`
 	// Filter available {"user_id_legacy": [""], "source_trans_id": [""], "value": "", "rating_id": [""], "is_with_media": true}
 	Filter string `json:"filter"`
+	// Q searches the comments, the best matches come first when sort is empty
+	Q string `json:"q,omitempty" schema:"q" bson:"q"`
 }
 
 type RatingSubmissionMpFilter struct {
@@ -29,6 +32,10 @@ type RatingSubmissionMpFilter struct {
 	EndDate       string    `json:"end_date"`
 	SourceTransID string    `json:"source_trans_id"`
 	IsWithMedia   *bool     `json:"is_with_media"`
+	// pending, approved or rejected
+	ModerationStatus string `json:"moderation_status"`
+	// Q is set from the q parameter
+	Q string `json:"-"`
 }
 
 // swagger:parameters ReqRatingSubmissionMpBody
@@ -126,9 +133,6 @@ type SaveRatingSubmissionMp struct {
 }
 
 func (req CreateRatingSubmissionMpRequest) Validate() error {
-	arrAllowedValueRatingProduct := []string{"1", "2", "3", "4", "5"}
-	arrAllowedValueRatingStore := []string{"1", "2", "3"}
-
 	return validation.ValidateStruct(&req,
 		validation.Field(&req.Value, validation.Required.Error(message.ErrReq.Message)),
 		validation.Field(&req.SourceUID, validation.Required.Error(message.ErrReq.Message)),
@@ -136,13 +140,18 @@ func (req CreateRatingSubmissionMpRequest) Validate() error {
 		validation.Field(&req.SourceTransID, validation.Required.Error(message.ErrReq.Message)),
 		validation.Field(&req.IPAddress, validation.Match(regexp.MustCompile(regexIP)).Error(message.ErrIPFormatReq.Message)),
 		validation.Field(&req.Comment, validation.NotNil),
-		validation.Field(&req.Value, validation.When(req.RatingType == "rating_for_product",
-			validation.In(sliceStringToSliceInterface(arrAllowedValueRatingProduct)...).Error(fmt.Sprintf("value should be %s", strings.Join(arrAllowedValueRatingProduct, ","))))),
-		validation.Field(&req.Value, validation.When(req.RatingType == "rating_for_store",
-			validation.In(sliceStringToSliceInterface(arrAllowedValueRatingStore)...).Error(fmt.Sprintf("value should be %s", strings.Join(arrAllowedValueRatingStore, ","))))),
+		validation.Field(&req.Value, allowedValueRule(req.RatingType)),
 	)
 }
 
+// allowedValueRule checks the value against the allowed values of the source type of the rating type,
+// nothing is checked when the source type has no allowed values
+func allowedValueRule(ratingType string) validation.Rule {
+	allowedValues := global.GetListRatingValueBySourceType(global.GetSourceTypeByRatingType(ratingType))
+	return validation.When(len(allowedValues) > 0,
+		validation.In(sliceStringToSliceInterface(allowedValues)...).Error(fmt.Sprintf("value should be %s", strings.Join(allowedValues, ","))))
+}
+
 func sliceStringToSliceInterface(arr []string) []interface{} {
 	arrInterface := make([]interface{}, len(arr))
 	for i, v := range arr {
@@ -1,16 +1,14 @@
 package request
 
 import (
-	"fmt"
-	"github.com/spf13/viper"
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/helper/global"
 	"go-klikdokter/helper/message"
 	"regexp"
-	"strings"
 	"time"
 
 	validation "github.com/itgelo/ozzo-validation/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
@@ -24,6 +22,8 @@ type ListRatingSubmissionRequest struct {
 	Sort   string `json:"sort,omitempty" schema:"sort" bson:"sort"`
 	Dir    string `json:"dir,omitempty" schema:"dir" bson:"dir"`
 	Filter string `json:"filter"`
+	// Q searches the comments, the best matches come first when sort is empty
+	Q string `json:"q,omitempty" schema:"q" bson:"q"`
 }
 
 type RatingSubmissionFilter struct {
@@ -33,6 +33,10 @@ type RatingSubmissionFilter struct {
 	StartDate     string    `json:"start_date"`
 	EndDate       string    `json:"end_date"`
 	SourceTransID string    `json:"source_trans_id"`
+	// pending, approved or rejected
+	ModerationStatus string `json:"moderation_status"`
+	// Q is set from the q parameter
+	Q string `json:"-"`
 }
 
 // swagger:parameters ReqRatingSubmissionBody ReqPublicRatingSubmissionBody
@@ -41,7 +45,7 @@ type ReqRatingSubmissionBody struct {
 	Body CreateRatingSubmissionRequest `json:"body"`
 }
 
-// swagger:parameters ReqRatingSubmissionById ReqDeleteRatingSubmissionById
+// swagger:parameters ReqRatingSubmissionById
 type ReqRatingSubmissionById struct {
 	// ID of Rating Submission
 	// in: path
@@ -73,6 +77,30 @@ type ReqAdminReplyRatingSubmissionBody struct {
 	Body ReplyAdminRatingSubmissionRequest `json:"body"`
 }
 
+// swagger:parameters ReqHideReplyRatingSubmissionBody
+type ReqHideReplyRatingSubmissionBody struct {
+	// ID of Rating Submission
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	// required: true
+	Body HideReplyRatingSubmissionRequest `json:"body"`
+}
+
+// swagger:parameters ReqModerateRatingSubmissionBody
+type ReqModerateRatingSubmissionBody struct {
+	// ID of Rating Submission
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// in: body
+	// required: true
+	Body ModerateRatingSubmissionRequest `json:"body"`
+}
+
 // swagger:parameters ReqCancelRatingSubmission
 type ReqCancelRatingSubmission struct {
 	// in: body
@@ -107,6 +135,14 @@ type CreateRatingSubmissionRequest struct {
 	RatingType    string            `json:"rating_type" bson:"rating_type"`
 	Value         string            `json:"value" bson:"value"`
 	Media         []entity.MediaObj `json:"media" bson:"media"`
+	// signed token of a review invitation, the order of the invitation replaces source_trans_id
+	InvitationToken string `json:"invitation_token" bson:"-"`
+	// creation date of the account from the token, a brand-new account is flagged for moderation
+	AccountCreatedAt *time.Time `json:"-" bson:"-"`
+	// values of the dimensions of the marketplace review, see the dimensions of the source type
+	Dimensions []entity.DimensionValue `json:"dimensions" bson:"dimensions"`
+	// keys of the tags of the source type selected with the review, saved with the numeric ratings
+	Tags []string `json:"tags" bson:"tags"`
 }
 
 type SaveRatingSubmission struct {
@@ -127,6 +163,13 @@ type SaveRatingSubmission struct {
 	IsAnonymous   bool              `json:"is_anonymous" bson:"is_anonymous"`
 	Media         []entity.MediaObj `json:"media" bson:"media"`
 	IsWithMedia   bool              `json:"is_with_media" bson:"is_with_media"`
+	// ModerationStatus and ModerationFlags are filled by the service, not by the client
+	ModerationStatus string   `json:"-" bson:"moderation_status"`
+	ModerationFlags  []string `json:"-" bson:"moderation_flags"`
+	// InvitationID and IsVerifiedPurchase are filled by the service, a purchase is verified by payment svc or by an invitation
+	InvitationID       *primitive.ObjectID `json:"-" bson:"invitation_id,omitempty"`
+	IsVerifiedPurchase bool                `json:"-" bson:"is_verified_purchase"`
+	Tags               []string            `json:"tags" bson:"tags,omitempty"`
 }
 
 type TaggingObj struct {
@@ -148,6 +191,55 @@ func (req ReplyAdminRatingSubmissionRequest) Validate() error {
 	)
 }
 
+type HideReplyRatingSubmissionRequest struct {
+	ID     string        `json:"-"`
+	Hidden bool          `json:"hidden"`
+	JWTObj global.JWTObj `json:"-"`
+}
+
+func (req HideReplyRatingSubmissionRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.ID, validation.Required.Error(message.ErrReq.Message)),
+	)
+}
+
+// swagger:parameters ListModerationQueueRequest
+type ListModerationQueueRequest struct {
+	// source of submission, mp for marketplace (product/store) submissions
+	// in: query
+	Source string `json:"source,omitempty" schema:"source"`
+	// moderation status, default pending
+	// in: query
+	Status string `json:"status,omitempty" schema:"status"`
+	// in: query
+	Page int `json:"page,omitempty" schema:"page"`
+	// in: query
+	Limit int64 `json:"limit,omitempty" schema:"limit"`
+	// oldest submission first by default
+	// in: query
+	Dir string `json:"dir,omitempty" schema:"dir"`
+}
+
+type ModerateRatingSubmissionRequest struct {
+	ID string `json:"-"`
+	// source of submission, mp for marketplace (product/store) submissions
+	Source string `json:"source"`
+	// approved or rejected
+	Status string `json:"status"`
+	// required when rejected
+	Reason string        `json:"reason"`
+	JWTObj global.JWTObj `json:"-"`
+}
+
+func (req ModerateRatingSubmissionRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.ID, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.Status, validation.Required.Error(message.ErrReq.Message),
+			validation.In(entity.ModerationStatusApproved, entity.ModerationStatusRejected).Error("status should be approved,rejected")),
+		validation.Field(&req.Reason, validation.When(req.Status == entity.ModerationStatusRejected, validation.Required.Error(message.ErrReq.Message))),
+	)
+}
+
 type UpdateRatingSubmissionRequest struct {
 	ID           string            `json:"-"`
 	RatingType   string            `json:"rating_type"`
@@ -155,15 +247,20 @@ type UpdateRatingSubmissionRequest struct {
 	Comment      string            `json:"comment,omitempty" bson:"comment"`
 	Value        *string           `json:"value,omitempty" bson:"value"`
 	Media        []entity.MediaObj `json:"media" bson:"media"`
+	Tags         []string          `json:"tags" bson:"tags"` // replace the tags of the submission, kept when omitted
 	UpdatedAt    time.Time         `json:"-,omitempty" bson:"updated_at"`
 	UserID       *string           `json:"-" bson:"user_id"`
 	UserIDLegacy *string           `json:"-" bson:"user_id_legacy"`
+	JWTObj       global.JWTObj     `json:"-" bson:"-"`
+	// ModerationStatus and ModerationFlags are filled by the service when the comment is edited
+	ModerationStatus string   `json:"-" bson:"moderation_status"`
+	ModerationFlags  []string `json:"-" bson:"moderation_flags"`
 }
 
 func (req CreateRatingSubmissionRequest) Validate() error {
 	return validation.ValidateStruct(&req,
 		validation.Field(&req.IPAddress, validation.Match(regexp.MustCompile(regexIP)).Error(message.ErrIPFormatReq.Message)),
-		validation.Field(&req.SourceTransID, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.SourceTransID, validation.When(req.InvitationToken == "", validation.Required.Error(message.ErrReq.Message))),
 		validation.Field(&req.Comment, validation.NotNil),
 		validation.Field(&req.UserIDLegacy, validation.Required.Error(message.ErrReq.Message)),
 		validation.Field(&req.DisplayName, validation.Required.Error(message.ErrReq.Message)),
@@ -171,21 +268,14 @@ func (req CreateRatingSubmissionRequest) Validate() error {
 }
 
 func (req CreateRatingSubmissionRequest) ValidateMp() error {
-	arrAllowedValueRatingProduct := []string{"1", "2", "3", "4", "5"}
-	arrAllowedValueRatingStore := []string{"1", "2", "3"}
-
 	return validation.ValidateStruct(&req,
 		validation.Field(&req.Value, validation.Required.Error(message.ErrReq.Message)),
 		validation.Field(&req.SourceUID, validation.Required.Error(message.ErrReq.Message)),
-		validation.Field(&req.SourceTransID, validation.Required.Error(message.ErrReq.Message)),
-		validation.Field(&req.SourceTransID, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.SourceTransID, validation.When(req.InvitationToken == "", validation.Required.Error(message.ErrReq.Message))),
 		validation.Field(&req.IPAddress, validation.Match(regexp.MustCompile(regexIP)).Error(message.ErrIPFormatReq.Message)),
 		validation.Field(&req.Comment, validation.NotNil),
-		validation.Field(&req.Value, validation.When(req.RatingType == "rating_for_product",
-			validation.In(sliceStringToSliceInterface(arrAllowedValueRatingProduct)...).Error(fmt.Sprintf("value should be %s", strings.Join(arrAllowedValueRatingProduct, ","))))),
-		validation.Field(&req.Value, validation.When(req.RatingType == "rating_for_store",
-			validation.In(sliceStringToSliceInterface(arrAllowedValueRatingStore)...).Error(fmt.Sprintf("value should be %s", strings.Join(arrAllowedValueRatingStore, ","))))),
-		validation.Field(&req.StoreUID, validation.When(req.RatingType == "rating_for_product", validation.Required)),
+		validation.Field(&req.Value, allowedValueRule(req.RatingType)),
+		validation.Field(&req.StoreUID, validation.When(req.RatingType == "rating_for_product" && req.InvitationToken == "", validation.Required)),
 	)
 }
 
@@ -197,28 +287,13 @@ func (req RatingByType) Validate() error {
 }
 
 func (req UpdateRatingSubmissionRequest) Validate() error {
-	arrRatingTypeMp := viper.GetStringSlice("rating-type-mp")
-	isRatingMp := stringInSlice(req.RatingType, arrRatingTypeMp)
-	arrAllowedValueRatingProduct := []string{"1", "2", "3", "4", "5"}
-	arrAllowedValueRatingStore := []string{"1", "2", "3"}
+	isRatingMp := global.IsMarketplaceRatingType(req.RatingType)
 
 	return validation.ValidateStruct(&req,
 		// validation.Field(&req.RatingID, validation.Required.Error(message.ErrReq.Message)),
 		validation.Field(&req.RatingID, validation.When(isRatingMp == false, validation.Required.Error(message.ErrReq.Message))),
 		validation.Field(&req.Value, validation.Required.Error(message.ErrReq.Message)),
 		validation.Field(&req.Comment, validation.NotNil),
-		validation.Field(&req.Value, validation.When(req.RatingType == "rating_for_product",
-			validation.In(sliceStringToSliceInterface(arrAllowedValueRatingProduct)...).Error(fmt.Sprintf("value should be %s", strings.Join(arrAllowedValueRatingProduct, ","))))),
-		validation.Field(&req.Value, validation.When(req.RatingType == "rating_for_store",
-			validation.In(sliceStringToSliceInterface(arrAllowedValueRatingStore)...).Error(fmt.Sprintf("value should be %s", strings.Join(arrAllowedValueRatingStore, ","))))),
+		validation.Field(&req.Value, allowedValueRule(req.RatingType)),
 	)
 }
-
-func stringInSlice(a string, list []string) bool {
-	for _, b := range list {
-		if b == a {
-			return true
-		}
-	}
-	return false
-}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"go-klikdokter/app/model/entity"
 	"go-klikdokter/helper/message"
 	"regexp"
 
@@ -31,9 +32,14 @@ type SaveRatingTypeLikertRequest struct {
 	// Description of rating type likert
 	// in: string
 	Description *string `json:"description,omitempty"`
-	// NumStatements of rating type likert
+	// DescriptionI18n is the description by language, e.g. {"id": "...", "en": "..."}, description is returned for a language without text
+	DescriptionI18n entity.LocalizedText `json:"description_i18n,omitempty"`
+	// NumStatements of rating type likert, the number of statements when empty
 	// in: integer
 	NumStatements int `json:"num_statements,omitempty"`
+	// Ordered statements of rating type likert, the submissions reference them by key
+	Statements []entity.LikertStatement `json:"statements,omitempty"`
+	// Deprecated: use statements, statement_01..statement_10 are keyed by their position
 	// Statement 1 of rating type likert
 	// in: string
 	Statement01 *string `json:"statement_01,omitempty"`
@@ -108,6 +114,15 @@ func (req SaveRatingTypeLikertRequest) Validate() error {
 	return validation.ValidateStruct(&req,
 		validation.Field(&req.Type, validation.Match(regexp.MustCompile(regexType)).Error(message.ErrTypeFormatReq.Message)),
 		validation.Field(&req.Type, validation.Required.Error(message.ErrReq.Message)),
-		validation.Field(&req.NumStatements, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.NumStatements, validation.When(len(req.Statements) == 0, validation.Required.Error(message.ErrReq.Message))),
 	)
 }
+
+// GetStatements returns the statements of either shape, statements wins over statement_01..statement_10
+func (req SaveRatingTypeLikertRequest) GetStatements() []entity.LikertStatement {
+	if len(req.Statements) > 0 {
+		return req.Statements
+	}
+	return entity.LikertStatementsFromLegacy([]**string{&req.Statement01, &req.Statement02, &req.Statement03, &req.Statement04, &req.Statement05,
+		&req.Statement06, &req.Statement07, &req.Statement08, &req.Statement09, &req.Statement10})
+}
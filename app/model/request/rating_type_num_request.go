@@ -1,6 +1,7 @@
 package request
 
 import (
+	"go-klikdokter/app/model/entity"
 	"go-klikdokter/helper/message"
 	"regexp"
 
@@ -69,6 +70,8 @@ type CreateRatingTypeNumRequest struct {
 	// Description of rating type num
 	// in: string
 	Description *string `json:"description"`
+	// DescriptionI18n is the description by language, e.g. {"id": "...", "en": "..."}, description is returned for a language without text
+	DescriptionI18n entity.LocalizedText `json:"description_i18n,omitempty"`
 	// Min Score of rating type num
 	// in: integer
 	MinScore *int `json:"min_score"`
@@ -95,6 +98,8 @@ type EditRatingTypeNumRequest struct {
 	// Description of rating type num
 	// in: string
 	Description *string `json:"description"`
+	// DescriptionI18n is the description by language, e.g. {"id": "...", "en": "..."}, description is returned for a language without text
+	DescriptionI18n entity.LocalizedText `json:"description_i18n,omitempty"`
 	// Min Score of rating type num
 	// in: integer
 	MinScore *int `json:"min_score"`
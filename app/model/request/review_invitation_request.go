@@ -0,0 +1,56 @@
+package request
+
+import (
+	"go-klikdokter/helper/message"
+
+	validation "github.com/itgelo/ozzo-validation/v4"
+)
+
+// swagger:parameters createReviewInvitations
+type ReqCreateReviewInvitationsBody struct {
+	//  in: body
+	Body CreateReviewInvitationsRequest `json:"body"`
+}
+
+type CreateReviewInvitationsRequest struct {
+	OrderNumber string                 `json:"order_number"`
+	UserID      string                 `json:"user_id"`
+	Items       []ReviewInvitationItem `json:"items"`
+	// days until the invitations expire, review-invitation.expiry-days when empty
+	ExpiryDays int `json:"expiry_days"`
+}
+
+// ReviewInvitationItem is an order line the buyer is invited to review
+type ReviewInvitationItem struct {
+	SourceType string `json:"source_type"`
+	SourceUID  string `json:"source_uid"`
+	StoreUID   string `json:"store_uid"`
+	// rating types allowed, review-invitation.rating-types of the source type when empty
+	RatingTypes []string `json:"rating_types"`
+}
+
+func (req CreateReviewInvitationsRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.OrderNumber, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.UserID, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.Items, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.ExpiryDays, validation.Min(0)),
+	)
+}
+
+func (req ReviewInvitationItem) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.SourceType, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.SourceUID, validation.Required.Error(message.ErrReq.Message)),
+	)
+}
+
+// swagger:parameters getListPendingReviewInvitations
+type ListPendingReviewInvitationsRequest struct {
+	// in: query
+	Page int `json:"page" schema:"page"`
+	// in: query
+	Limit int64 `json:"limit" schema:"limit"`
+	// UserID is read from the token
+	UserID string `json:"-" schema:"-"`
+}
@@ -0,0 +1,11 @@
+package request
+
+// swagger:parameters restoreRatingTypeNum restoreRatingTypeLikert restoreRatingFormula restoreRating restoreRatingSubmission ReqDeleteRatingSubmissionById
+type SoftDeleteRequest struct {
+	// in: path
+	// required: true
+	Id string `json:"id"`
+	// in: query
+	// mp for a marketplace submission, only read by the rating submission routes
+	Source string `json:"source" schema:"source"`
+}
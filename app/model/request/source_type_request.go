@@ -0,0 +1,93 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/helper/message"
+	"regexp"
+	"strconv"
+
+	validation "github.com/itgelo/ozzo-validation/v4"
+)
+
+var regexSourceTypeName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// swagger:parameters getSourceTypeById deleteSourceType
+type GetSourceTypeRequest struct {
+	// in: path
+	// required: true
+	Id string `json:"id"`
+}
+
+// swagger:parameters createSourceType
+type ReqCreateSourceTypeBody struct {
+	//  in: body
+	// required: true
+	Body SaveSourceTypeRequest `json:"body"`
+}
+
+// swagger:parameters updateSourceType
+type ReqUpdateSourceTypeBody struct {
+	// in: path
+	// required: true
+	Id string `json:"id"`
+	//  in: body
+	// required: true
+	Body SaveSourceTypeRequest `json:"body"`
+}
+
+type SaveSourceTypeRequest struct {
+	// Name of the source type, lower case letters, numbers, - or _
+	// required: true
+	Name string `json:"name"`
+	// Rating types whose submissions belong to the source type
+	RatingTypes []string `json:"rating_types"`
+	// Marketplace source types keep their submissions apart
+	IsMarketplace bool `json:"is_marketplace"`
+	// Highest value of the scale, 0 when the values come from the rating type
+	Scale int `json:"scale"`
+	// Values allowed for a submission, from the highest, each one between 1 and scale
+	AllowedValues []string `json:"allowed_values"`
+	// Formula of the summaries while the source type has no active rating formula
+	DefaultFormula string `json:"default_formula"`
+	// Topic of the final rating events of a marketplace source type
+	FinalRatingTopic string `json:"final_rating_topic"`
+	// Source type of the stores selling the sources, e.g. store for product
+	StoreSourceType string `json:"store_source_type"`
+	// Dimensions scored besides the value by the marketplace submissions, each one with a numeric rating type
+	Dimensions   []entity.RatingDimension `json:"dimensions"`
+	AllowComment bool                     `json:"allow_comment"`
+	AllowMedia   bool                     `json:"allow_media"`
+	// For update
+	Id string `json:"-"`
+}
+
+func (req SaveSourceTypeRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Name, validation.Required.Error(message.ErrReq.Message), validation.Match(regexSourceTypeName)),
+		validation.Field(&req.Scale, validation.Min(0)),
+		validation.Field(&req.StoreSourceType, validation.Match(regexSourceTypeName), validation.NotIn(req.Name).Error("store_source_type should not be the source type itself")),
+		validation.Field(&req.AllowedValues, validation.When(req.Scale == 0, validation.Empty.Error("allowed_values requires a scale")),
+			validation.Each(validation.By(func(value interface{}) error {
+				if v, err := strconv.Atoi(value.(string)); err != nil || v < 1 || v > req.Scale {
+					return fmt.Errorf("value should be between 1 and %d", req.Scale)
+				}
+				return nil
+			}))),
+		validation.Field(&req.Dimensions, validation.When(!req.IsMarketplace, validation.Empty.Error("dimensions requires a marketplace source type")),
+			validation.By(func(value interface{}) error {
+				names := make(map[string]bool, len(req.Dimensions))
+				for _, dimension := range req.Dimensions {
+					if !regexSourceTypeName.MatchString(dimension.Name) || dimension.RatingType == "" {
+						return errors.New("every dimension requires a name of lower case letters, numbers, - or _ and a rating_type")
+					}
+					if names[dimension.Name] {
+						return fmt.Errorf("dimension %s is given twice", dimension.Name)
+					}
+					names[dimension.Name] = true
+				}
+				return nil
+			})),
+	)
+}
@@ -0,0 +1,73 @@
+package request
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"regexp"
+
+	validation "github.com/itgelo/ozzo-validation/v4"
+)
+
+var regexSubmissionTagKey = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// swagger:parameters getSubmissionTags
+type ListSubmissionTagsRequest struct {
+	// Tags of the source type, every source type when empty
+	SourceType string `json:"source_type" schema:"source_type" binding:"omitempty"`
+	// Value of the submission, only the tags offered for the value are returned
+	Value string `json:"value" schema:"value" binding:"omitempty"`
+	// Lang is the language of the labels, id or en, Accept-Language when empty
+	Lang string `json:"lang" schema:"lang" binding:"omitempty"`
+}
+
+// swagger:parameters getSubmissionTagById deleteSubmissionTag
+type GetSubmissionTagRequest struct {
+	// in: path
+	// required: true
+	Id string `json:"id"`
+}
+
+// swagger:parameters createSubmissionTag
+type ReqCreateSubmissionTagBody struct {
+	//  in: body
+	// required: true
+	Body SaveSubmissionTagRequest `json:"body"`
+}
+
+// swagger:parameters updateSubmissionTag
+type ReqUpdateSubmissionTagBody struct {
+	// in: path
+	// required: true
+	Id string `json:"id"`
+	//  in: body
+	// required: true
+	Body SaveSubmissionTagRequest `json:"body"`
+}
+
+type SaveSubmissionTagRequest struct {
+	// Source type of the catalogue, it can not be updated
+	// required: true
+	SourceType string `json:"source_type"`
+	// Key referenced by the submissions, lower case letters, numbers, - or _, it can not be updated
+	// required: true
+	Key string `json:"key"`
+	// required: true
+	Label     string               `json:"label"`
+	LabelI18n entity.LocalizedText `json:"label_i18n,omitempty"`
+	// Range of the values the tag is offered for, both 0 for every value
+	MinValue float64 `json:"min_value"`
+	MaxValue float64 `json:"max_value"`
+	// For update
+	Id string `json:"-"`
+}
+
+func (req SaveSubmissionTagRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.SourceType, validation.Required.Error(message.ErrReq.Message), validation.In(sliceStringToSliceInterface(global.GetSourceTypeNames())...)),
+		validation.Field(&req.Key, validation.Required.Error(message.ErrReq.Message), validation.Match(regexSubmissionTagKey)),
+		validation.Field(&req.Label, validation.Required.Error(message.ErrReq.Message)),
+		validation.Field(&req.MinValue, validation.Min(float64(0))),
+		validation.Field(&req.MaxValue, validation.Min(req.MinValue).Error("max_value should not be lower than min_value")),
+	)
+}
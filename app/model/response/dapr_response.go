@@ -0,0 +1,19 @@
+package response
+
+// Statuses a dapr subscriber answers with, dapr redelivers a RETRY event and discards a DROP event
+const (
+	DaprStatusSuccess = "SUCCESS"
+	DaprStatusRetry   = "RETRY"
+	DaprStatusDrop    = "DROP"
+)
+
+// DaprSubscription is an entry of the /dapr/subscribe discovery
+type DaprSubscription struct {
+	PubsubName string `json:"pubsubname"`
+	Topic      string `json:"topic"`
+	Route      string `json:"route"`
+}
+
+type DaprSubscriberResponse struct {
+	Status string `json:"status"`
+}
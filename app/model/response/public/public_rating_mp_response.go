@@ -17,27 +17,37 @@ type PublicRatingSummaryMpResponse struct {
 	RatingType    string              `json:"rating_type,omitempty"`
 	RatingTypeId  string              `json:"rating_type_id,omitempty"`
 	RatingSummary interface{}         `json:"rating_summary,omitempty"`
+	// counts of the tags selected with the reviews, the most selected first
+	Tags []PublicRatingTagSummary `json:"tags,omitempty"`
 }
 
 type PublicRatingSubmissionMpResponse struct {
-	ID            primitive.ObjectID          `json:"id"`
-	UserID        *string                     `json:"user_id,omitempty"`
-	UserIDLegacy  *string                     `json:"user_id_legacy,omitempty"`
-	DisplayName   string                      `json:"display_name,omitempty"`
-	Avatar        string                      `json:"avatar,omitempty"`
-	Comment       *string                     `json:"comment,omitempty"`
-	SourceTransID string                      `json:"source_trans_id,omitempty"`
-	LikeCounter   int                         `json:"like_counter"`
-	SourceType    string                      `json:"source_type"`
-	SourceUID     string                      `json:"source_uid"`
-	StoreUID      string                      `json:"store_uid"`
-	Value         string                      `json:"value"`
-	LikeByMe      bool                        `json:"like_by_me"`
-	IsWithMedia   bool                        `json:"is_with_media"`
-	CreatedAt     time.Time                   `json:"created_at"`
-	Media         []response.MediaObjResponse `json:"media"`
-	Reply         string                      `json:"reply"`
-	ReplyBy       string                      `json:"reply_by"`
+	ID                 primitive.ObjectID          `json:"id"`
+	UserID             *string                     `json:"user_id,omitempty"`
+	UserIDLegacy       *string                     `json:"user_id_legacy,omitempty"`
+	DisplayName        string                      `json:"display_name,omitempty"`
+	Avatar             string                      `json:"avatar,omitempty"`
+	Comment            *string                     `json:"comment,omitempty"`
+	Highlight          string                      `json:"highlight,omitempty"`
+	SourceTransID      string                      `json:"source_trans_id,omitempty"`
+	LikeCounter        int                         `json:"like_counter"`
+	NotHelpfulCounter  int                         `json:"not_helpful_counter"`
+	HelpfulRatio       float64                     `json:"helpful_ratio"`
+	SourceType         string                      `json:"source_type"`
+	SourceUID          string                      `json:"source_uid"`
+	StoreUID           string                      `json:"store_uid"`
+	Value              string                      `json:"value"`
+	LikeByMe           bool                        `json:"like_by_me"`
+	IsWithMedia        bool                        `json:"is_with_media"`
+	IsVerifiedPurchase bool                        `json:"is_verified_purchase"`
+	CreatedAt          time.Time                   `json:"created_at"`
+	Edited             bool                        `json:"edited"`
+	EditedAt           *time.Time                  `json:"edited_at,omitempty"`
+	Media              []response.MediaObjResponse `json:"media"`
+	Reply              string                      `json:"reply"`
+	ReplyBy            string                      `json:"reply_by"`
+	RepliedAt          *time.Time                  `json:"replied_at"`
+	ReplyEdited        bool                        `json:"reply_edited"`
 }
 
 type PublicCreateRatingSubmissionMpResponse struct {
@@ -102,65 +112,10 @@ func MapRatingLikertToRatingNumericMpResp(data entity.RatingTypesLikertCol, rati
 	result.RatingType = data.Type
 	result.RatingDescription = *data.Description
 	result.RatingNumStatements = data.NumStatements
-	if data.Statement01 != nil && len(*data.Statement01) != 0 {
-		result.RatingStatement01 = data.Statement01
-	} else {
-		result.RatingStatement01 = nil
-	}
-
-	if data.Statement02 != nil && len(*data.Statement02) != 0 {
-		result.RatingStatement02 = data.Statement02
-	} else {
-		result.RatingStatement02 = nil
-	}
-
-	if data.Statement03 != nil && len(*data.Statement03) != 0 {
-		result.RatingStatement03 = data.Statement03
-	} else {
-		result.RatingStatement03 = nil
-	}
-
-	if data.Statement04 != nil && len(*data.Statement04) != 0 {
-		result.RatingStatement04 = data.Statement04
-	} else {
-		result.RatingStatement04 = nil
-	}
-
-	if data.Statement05 != nil && len(*data.Statement05) != 0 {
-		result.RatingStatement05 = data.Statement05
-	} else {
-		result.RatingStatement05 = nil
-	}
-
-	if data.Statement06 != nil && len(*data.Statement06) != 0 {
-		result.RatingStatement06 = data.Statement06
-	} else {
-		result.RatingStatement06 = nil
-	}
-
-	if data.Statement07 != nil && len(*data.Statement07) != 0 {
-		result.RatingStatement07 = data.Statement07
-	} else {
-		result.RatingStatement07 = nil
-	}
-
-	if data.Statement08 != nil && len(*data.Statement08) != 0 {
-		result.RatingStatement08 = data.Statement08
-	} else {
-		result.RatingStatement08 = nil
-	}
-
-	if data.Statement09 != nil && len(*data.Statement09) != 0 {
-		result.RatingStatement09 = data.Statement09
-	} else {
-		result.RatingStatement09 = nil
-	}
-
-	if data.Statement10 != nil && len(*data.Statement10) != 0 {
-		result.RatingStatement10 = data.Statement10
-	} else {
-		result.RatingStatement10 = nil
-	}
+	result.RatingStatements = data.GetStatements()
+	legacy := []**string{&result.RatingStatement01, &result.RatingStatement02, &result.RatingStatement03, &result.RatingStatement04, &result.RatingStatement05,
+		&result.RatingStatement06, &result.RatingStatement07, &result.RatingStatement08, &result.RatingStatement09, &result.RatingStatement10}
+	entity.LikertStatementsToLegacy(result.RatingStatements, legacy)
 	return &result
 }
 
@@ -211,6 +166,10 @@ type PublicRatingSummaryListDetailResponse struct {
 	TotalReviewer       int64                                 `json:"total_reviewer"`
 	TotalComment        int64                                 `json:"total_comment"`
 	RatingSummaryDetail []PublicRatingSummaryDetailMpResponse `json:"rating_summary_detail"`
+	// summaries of the dimensions of the reviews, see the dimensions of the source type
+	Dimensions []PublicRatingDimensionSummary `json:"dimensions,omitempty"`
+	// counts of the tags selected with the reviews, the most selected first
+	Tags []PublicRatingTagSummary `json:"tags,omitempty"`
 }
 
 type PublicRatingSummaryDetailMpResponse struct {
@@ -226,6 +185,33 @@ type RatingSummaryStoreProductNumeric struct {
 	TotalReviewer       int64                                 `json:"total_reviewer"`
 	TotalComment        int64                                 `json:"total_comment"`
 	RatingSummaryDetail []PublicRatingSummaryDetailMpResponse `json:"rating_summary_detail"`
+	// summaries of the dimensions of the reviews, see the dimensions of the source type
+	Dimensions []PublicRatingDimensionSummary `json:"dimensions,omitempty"`
+	// counts of the tags selected with the reviews of the store, the most selected first
+	Tags []PublicRatingTagSummary `json:"tags,omitempty"`
+}
+
+// PublicRatingDimensionSummary is the summary of a dimension of the reviews, total_value is the average of its values
+type PublicRatingDimensionSummary struct {
+	Name                string                                `json:"name"`
+	TotalValue          string                                `json:"total_value"`
+	MaximumValue        string                                `json:"maximum_value"`
+	TotalReviewer       int64                                 `json:"total_reviewer"`
+	RatingSummaryDetail []PublicRatingSummaryDetailMpResponse `json:"rating_summary_detail"`
+}
+
+// PublicRatingSubGroupByDimensionMp is the values of a dimension of the reviews of a source or a store, counted by value
+type PublicRatingSubGroupByDimensionMp struct {
+	ID            StructGroupDimension `json:"_id" bson:"_id"`
+	TotalValue    float64              `json:"total_value" bson:"total_value"`
+	TotalReviewer int                  `json:"total_reviewer" bson:"total_reviewer"`
+	ArrayValue    []map[string]float64 `json:"array_value" bson:"array_value"`
+}
+
+// StructGroupDimension is the dimension of the reviews of UID, a source_uid or a store_uid
+type StructGroupDimension struct {
+	UID  string `json:"uid" bson:"uid"`
+	Name string `json:"name" bson:"name"`
 }
 
 type PublicRatingSubGroupByValue struct {
@@ -16,6 +16,27 @@ type PublicRatingSummaryResponse struct {
 	RatingType    string             `json:"rating_type,omitempty"`
 	RatingTypeId  string             `json:"rating_type_id,omitempty"`
 	RatingSummary interface{}        `json:"rating_summary,omitempty"`
+	// counts of the tags selected with the submissions of the rating, the most selected first
+	Tags []PublicRatingTagSummary `json:"tags,omitempty"`
+}
+
+// PublicRatingTagSummary is the count of the submissions that selected the tag, e.g. "12 buyers said: Original product"
+type PublicRatingTagSummary struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// PublicRatingSubGroupByTag is the count of the submissions of UID that selected the tag
+type PublicRatingSubGroupByTag struct {
+	ID    StructGroupTag `json:"_id" bson:"_id"`
+	Count int            `json:"count" bson:"count"`
+}
+
+// StructGroupTag is the tag of the submissions of UID, a rating id or a source_uid
+type StructGroupTag struct {
+	UID string `json:"uid" bson:"uid"`
+	Key string `json:"key" bson:"key"`
 }
 
 type RatingSummaryNumeric struct {
@@ -30,18 +51,24 @@ type RatingSummaryLikert struct {
 }
 
 type PublicRatingSubmissionResponse struct {
-	ID            primitive.ObjectID `json:"id"`
-	UserID        *string            `json:"user_id,omitempty"`
-	UserIDLegacy  *string            `json:"user_id_legacy,omitempty"`
-	DisplayName   string             `json:"display_name,omitempty"`
-	Avatar        string             `json:"avatar,omitempty"`
-	Comment       *string            `json:"comment,omitempty"`
-	SourceTransID string             `json:"source_trans_id,omitempty"`
-	LikeCounter   int                `json:"like_counter"`
-	RatingType    string             `json:"rating_type"`
-	Value         string             `json:"value"`
-	LikeByMe      bool               `json:"like_by_me"`
-	CreatedAt     time.Time          `json:"created_at"`
+	ID                 primitive.ObjectID `json:"id"`
+	UserID             *string            `json:"user_id,omitempty"`
+	UserIDLegacy       *string            `json:"user_id_legacy,omitempty"`
+	DisplayName        string             `json:"display_name,omitempty"`
+	Avatar             string             `json:"avatar,omitempty"`
+	Comment            *string            `json:"comment,omitempty"`
+	Highlight          string             `json:"highlight,omitempty"`
+	SourceTransID      string             `json:"source_trans_id,omitempty"`
+	LikeCounter        int                `json:"like_counter"`
+	NotHelpfulCounter  int                `json:"not_helpful_counter"`
+	HelpfulRatio       float64            `json:"helpful_ratio"`
+	RatingType         string             `json:"rating_type"`
+	Value              string             `json:"value"`
+	LikeByMe           bool               `json:"like_by_me"`
+	IsVerifiedPurchase bool               `json:"is_verified_purchase"`
+	CreatedAt          time.Time          `json:"created_at"`
+	Edited             bool               `json:"edited"`
+	EditedAt           *time.Time         `json:"edited_at,omitempty"`
 }
 
 type PublicCreateRatingSubmissionResponse struct {
@@ -68,22 +95,25 @@ type PublicRatingNumericResponse struct {
 }
 
 type PublicRatingLikertResponse struct {
-	Type                string  `json:"type"`
-	RatingId            string  `json:"rating_id"`
-	RatingTypeId        string  `json:"rating_type_id"`
-	RatingType          string  `json:"rating_type"`
-	RatingDescription   string  `json:"rating_description"`
-	RatingNumStatements int     `json:"rating_num_statements"`
-	RatingStatement01   *string `json:"rating_statement_01,omitempty"`
-	RatingStatement02   *string `json:"rating_statement_02,omitempty"`
-	RatingStatement03   *string `json:"rating_statement_03,omitempty"`
-	RatingStatement04   *string `json:"rating_statement_04,omitempty"`
-	RatingStatement05   *string `json:"rating_statement_05,omitempty"`
-	RatingStatement06   *string `json:"rating_statement_06,omitempty"`
-	RatingStatement07   *string `json:"rating_statement_07,omitempty"`
-	RatingStatement08   *string `json:"rating_statement_08,omitempty"`
-	RatingStatement09   *string `json:"rating_statement_09,omitempty"`
-	RatingStatement10   *string `json:"rating_statement_10,omitempty"`
+	Type                string `json:"type"`
+	RatingId            string `json:"rating_id"`
+	RatingTypeId        string `json:"rating_type_id"`
+	RatingType          string `json:"rating_type"`
+	RatingDescription   string `json:"rating_description"`
+	RatingNumStatements int    `json:"rating_num_statements"`
+	// ordered statements, the submissions reference them by key
+	RatingStatements []entity.LikertStatement `json:"rating_statements"`
+	// Deprecated: rating_statement_01..rating_statement_10 are the labels of rating_statements for the clients of the old shape
+	RatingStatement01 *string `json:"rating_statement_01,omitempty"`
+	RatingStatement02 *string `json:"rating_statement_02,omitempty"`
+	RatingStatement03 *string `json:"rating_statement_03,omitempty"`
+	RatingStatement04 *string `json:"rating_statement_04,omitempty"`
+	RatingStatement05 *string `json:"rating_statement_05,omitempty"`
+	RatingStatement06 *string `json:"rating_statement_06,omitempty"`
+	RatingStatement07 *string `json:"rating_statement_07,omitempty"`
+	RatingStatement08 *string `json:"rating_statement_08,omitempty"`
+	RatingStatement09 *string `json:"rating_statement_09,omitempty"`
+	RatingStatement10 *string `json:"rating_statement_10,omitempty"`
 }
 
 func MapRatingNumericToRatingNumericResp(data entity.RatingTypesNumCol, ratingId string) *PublicRatingNumericResponse {
@@ -106,64 +136,9 @@ func MapRatingLikertToRatingNumericResp(data entity.RatingTypesLikertCol, rating
 	result.RatingType = data.Type
 	result.RatingDescription = *data.Description
 	result.RatingNumStatements = data.NumStatements
-	if data.Statement01 != nil && len(*data.Statement01) != 0 {
-		result.RatingStatement01 = data.Statement01
-	} else {
-		result.RatingStatement01 = nil
-	}
-
-	if data.Statement02 != nil && len(*data.Statement02) != 0 {
-		result.RatingStatement02 = data.Statement02
-	} else {
-		result.RatingStatement02 = nil
-	}
-
-	if data.Statement03 != nil && len(*data.Statement03) != 0 {
-		result.RatingStatement03 = data.Statement03
-	} else {
-		result.RatingStatement03 = nil
-	}
-
-	if data.Statement04 != nil && len(*data.Statement04) != 0 {
-		result.RatingStatement04 = data.Statement04
-	} else {
-		result.RatingStatement04 = nil
-	}
-
-	if data.Statement05 != nil && len(*data.Statement05) != 0 {
-		result.RatingStatement05 = data.Statement05
-	} else {
-		result.RatingStatement05 = nil
-	}
-
-	if data.Statement06 != nil && len(*data.Statement06) != 0 {
-		result.RatingStatement06 = data.Statement06
-	} else {
-		result.RatingStatement06 = nil
-	}
-
-	if data.Statement07 != nil && len(*data.Statement07) != 0 {
-		result.RatingStatement07 = data.Statement07
-	} else {
-		result.RatingStatement07 = nil
-	}
-
-	if data.Statement08 != nil && len(*data.Statement08) != 0 {
-		result.RatingStatement08 = data.Statement08
-	} else {
-		result.RatingStatement08 = nil
-	}
-
-	if data.Statement09 != nil && len(*data.Statement09) != 0 {
-		result.RatingStatement09 = data.Statement09
-	} else {
-		result.RatingStatement09 = nil
-	}
-
-	if data.Statement10 != nil && len(*data.Statement10) != 0 {
-		result.RatingStatement10 = data.Statement10
-	} else {
-		result.RatingStatement10 = nil
-	}
+	result.RatingStatements = data.GetStatements()
+	legacy := []**string{&result.RatingStatement01, &result.RatingStatement02, &result.RatingStatement03, &result.RatingStatement04, &result.RatingStatement05,
+		&result.RatingStatement06, &result.RatingStatement07, &result.RatingStatement08, &result.RatingStatement09, &result.RatingStatement10}
+	entity.LikertStatementsToLegacy(result.RatingStatements, legacy)
 	return &result
 }
@@ -0,0 +1,31 @@
+package response
+
+// RatingFormulaPreviewResponse compares the scores of the current and the candidate formula
+type RatingFormulaPreviewResponse struct {
+	FormulaId        string `json:"formula_id"`
+	SourceType       string `json:"source_type"`
+	RatingTypeId     string `json:"rating_type_id"`
+	CurrentFormula   string `json:"current_formula"`
+	CurrentVersion   int    `json:"current_version"`
+	CandidateFormula string `json:"candidate_formula"`
+	// ranked by candidate score
+	Items []RatingFormulaPreviewItem `json:"items"`
+	// requested source uids without a rating
+	MissingSourceUids []string `json:"missing_source_uids"`
+}
+
+// RatingFormulaPreviewItem holds the score of a source with each formula, total values are the published (rounded) scores.
+// Ranks are 1 for the highest score, sources with the same score share a rank.
+type RatingFormulaPreviewItem struct {
+	SourceUid           string  `json:"source_uid"`
+	RatingId            string  `json:"rating_id"`
+	TotalReviewer       int64   `json:"total_reviewer"`
+	CurrentScore        float64 `json:"current_score"`
+	CurrentTotalValue   int     `json:"current_total_value"`
+	CurrentRank         int     `json:"current_rank"`
+	CandidateScore      float64 `json:"candidate_score"`
+	CandidateTotalValue int     `json:"candidate_total_value"`
+	CandidateRank       int     `json:"candidate_rank"`
+	// positive when the source moves up
+	RankChange int `json:"rank_change"`
+}
@@ -1,8 +1,17 @@
 package response
 
+// Status of the helpful vote of the user after the request
+const (
+	HelpfulStatusLike       = "Like"
+	HelpfulStatusUnlike     = "Unlike"
+	HelpfulStatusNotHelpful = "NotHelpful"
+)
+
 type RatingSubHelpfulResponse struct {
-	RatingSubmissionId string `json:"rating_submission_id"`
-	UserIdLegacy       string `json:"user_id_legacy"`
-	LikeCounter        int    `json:"like_counter"`
-	Status             string `json:"status"`
+	RatingSubmissionId string  `json:"rating_submission_id"`
+	UserIdLegacy       string  `json:"user_id_legacy"`
+	LikeCounter        int     `json:"like_counter"`
+	NotHelpfulCounter  int     `json:"not_helpful_counter"`
+	HelpfulRatio       float64 `json:"helpful_ratio"`
+	Status             string  `json:"status"`
 }
@@ -0,0 +1,23 @@
+package response
+
+import "time"
+
+type RatingSubReportResponse struct {
+	ID           string    `json:"id"`
+	UserIDLegacy string    `json:"user_id_legacy"`
+	ReasonCode   string    `json:"reason_code"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RatingSubReportGroupResponse is the reports of a submission, the most reported submission first
+type RatingSubReportGroupResponse struct {
+	RatingSubmissionID string                    `json:"rating_submission_id"`
+	Source             string                    `json:"source"`
+	Comment            string                    `json:"comment"`
+	ModerationStatus   string                    `json:"moderation_status"`
+	TotalReport        int                       `json:"total_report"`
+	ReasonCodes        map[string]int            `json:"reason_codes"`
+	LastReportedAt     time.Time                 `json:"last_reported_at"`
+	Reports            []RatingSubReportResponse `json:"reports"`
+}
@@ -0,0 +1,27 @@
+package response
+
+import (
+	"go-klikdokter/app/model/entity"
+	"time"
+)
+
+// RatingSubRevisionResponse is a version of a submission, the oldest first and the current version last.
+// EditedBy, EditedAt and Changes are the edit replacing the version, empty for the current version.
+type RatingSubRevisionResponse struct {
+	Revision  int                       `json:"revision"`
+	IsCurrent bool                      `json:"is_current"`
+	RatingID  string                    `json:"rating_id,omitempty"`
+	Value     string                    `json:"value"`
+	Comment   *string                   `json:"comment"`
+	Media     []entity.MediaObj         `json:"media,omitempty"`
+	EditedBy  string                    `json:"edited_by,omitempty"`
+	EditedAt  *time.Time                `json:"edited_at,omitempty"`
+	Changes   []RatingSubRevisionChange `json:"changes,omitempty"`
+}
+
+// RatingSubRevisionChange is a field changed by an edit
+type RatingSubRevisionChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
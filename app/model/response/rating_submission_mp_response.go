@@ -11,6 +11,7 @@ type RatingSubmissionMpResponse struct {
 	SourceTransID string             `json:"source_trans_id" bson:"source_trans_id"`
 	Media         []MediaObjResponse `json:"media" bson:"media"`
 	IsWithMedia   bool               `json:"is_with_media" bson:"is_with_media"`
+	Highlight     string             `json:"highlight,omitempty" bson:"-"`
 }
 
 type MediaObjResponse struct {
@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 type RatingSubmissonResponse struct {
 	RatingID     string  `json:"rating_id" bson:"rating_id"`
 	UserID       *string `json:"user_id" bson:"user_id"`
@@ -7,4 +9,22 @@ type RatingSubmissonResponse struct {
 	Comment      string  `json:"comment" bson:"comment"`
 	Value        string  `json:"value" bson:"value"`
 	SourTransID  string  `json:"source_trans_id" bson:"source_trans_id"`
+	Highlight    string  `json:"highlight,omitempty" bson:"-"`
+}
+
+type ModerationQueueResponse struct {
+	ID               string     `json:"id"`
+	RatingID         string     `json:"rating_id,omitempty"`
+	SourceType       string     `json:"source_type,omitempty"`
+	SourceUID        string     `json:"source_uid,omitempty"`
+	UserIDLegacy     *string    `json:"user_id_legacy"`
+	DisplayName      *string    `json:"display_name"`
+	Comment          string     `json:"comment"`
+	Value            string     `json:"value"`
+	ModerationStatus string     `json:"moderation_status"`
+	ModerationFlags  []string   `json:"moderation_flags"`
+	ModerationReason string     `json:"moderation_reason"`
+	ModeratedBy      string     `json:"moderated_by"`
+	ModeratedAt      *time.Time `json:"moderated_at"`
+	CreatedAt        time.Time  `json:"created_at"`
 }
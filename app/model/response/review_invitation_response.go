@@ -0,0 +1,17 @@
+package response
+
+import "time"
+
+// swagger:model ReviewInvitationResponse
+type ReviewInvitationResponse struct {
+	ID          string    `json:"id"`
+	OrderNumber string    `json:"order_number"`
+	SourceType  string    `json:"source_type"`
+	SourceUID   string    `json:"source_uid"`
+	StoreUID    string    `json:"store_uid"`
+	RatingTypes []string  `json:"rating_types"`
+	Status      string    `json:"status"`
+	ExpiredAt   time.Time `json:"expired_at"`
+	// sent as invitation_token when the rating submission is created
+	Token string `json:"token,omitempty"`
+}
@@ -18,6 +18,7 @@ func RegisterRatingService(db *mongo.Database, logger log.Logger) service.Rating
 		rp.NewRatingRepository(db),
 		publicrepository.NewPublicRatingRepository(db),
 		util.NewMedicalFacilitySvc(util.ResponseHttp{}),
+		rp.NewRatingMpRepository(db),
 	)
 }
 
@@ -32,6 +33,9 @@ func RegisterPublicRatingService(db *mongo.Database, logger log.Logger) publicse
 func RegisterDaprService(db *mongo.Database, logger log.Logger) service.DaprService {
 	return service.NewDaprService(
 		logger,
+		rp.NewRatingRepository(db),
+		rp.NewRatingMpRepository(db),
+		rp.NewDaprRepository(db),
 	)
 }
 
@@ -55,3 +59,55 @@ func RegisterUploadService(db *mongo.Database, logger log.Logger) service.Upload
 		logger,
 	)
 }
+
+func RegisterOutboxService(db *mongo.Database, logger log.Logger) service.OutboxService {
+	return service.NewOutboxService(
+		logger,
+		rp.NewOutboxRepository(db),
+		service.NewOutboxDeliverers(logger, rp.NewRatingMpRepository(db)),
+	)
+}
+
+func RegisterPurgeService(db *mongo.Database, logger log.Logger) service.PurgeService {
+	return service.NewPurgeService(
+		logger,
+		rp.NewRatingRepository(db),
+	)
+}
+
+func RegisterReviewInvitationService(db *mongo.Database, logger log.Logger) service.ReviewInvitationService {
+	return service.NewReviewInvitationService(
+		logger,
+		rp.NewRatingMpRepository(db),
+	)
+}
+
+func RegisterSourceTypeService(db *mongo.Database, logger log.Logger) service.SourceTypeService {
+	return service.NewSourceTypeService(
+		logger,
+		rp.NewSourceTypeRepository(db),
+	)
+}
+
+func RegisterSubmissionTagService(db *mongo.Database, logger log.Logger) service.SubmissionTagService {
+	return service.NewSubmissionTagService(
+		logger,
+		rp.NewRatingRepository(db),
+	)
+}
+
+func RegisterRatingSubReportService(db *mongo.Database, logger log.Logger) service.RatingSubReportService {
+	return service.NewRatingSubReportService(
+		logger,
+		rp.NewRatingRepository(db),
+		rp.NewRatingMpRepository(db),
+	)
+}
+
+func RegisterRatingSubRevisionService(db *mongo.Database, logger log.Logger) service.RatingSubRevisionService {
+	return service.NewRatingSubRevisionService(
+		logger,
+		rp.NewRatingRepository(db),
+		rp.NewRatingMpRepository(db),
+	)
+}
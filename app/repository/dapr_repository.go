@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type daprRepo struct {
+	db *mongo.Database
+}
+
+type DaprRepository interface {
+	IsDaprEventProcessed(key string) (bool, error)
+	SaveDaprEvent(key, topic string) error
+}
+
+func NewDaprRepository(db *mongo.Database) DaprRepository {
+	return &daprRepo{db}
+}
+
+func (r *daprRepo) IsDaprEventProcessed(key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	count, err := r.db.Collection(entity.DaprEventCol{}.CollectionName()).CountDocuments(ctx, bson.D{{Key: "_id", Value: key}})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SaveDaprEvent records the event as processed, an event saved twice by concurrent deliveries is not an error
+func (r *daprRepo) SaveDaprEvent(key, topic string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	_, err := r.db.Collection(entity.DaprEventCol{}.CollectionName()).InsertOne(ctx, entity.DaprEventCol{
+		Key:         key,
+		Topic:       topic,
+		ProcessedAt: time.Now().In(util.Loc),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
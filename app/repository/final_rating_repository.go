@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetFinalRatingBySource returns the last final rating published for the source, nil when none was published yet
+func (r *ratingMpRepo) GetFinalRatingBySource(sourceType, sourceUid string) (*entity.FinalRatingCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var finalRating entity.FinalRatingCol
+	filter := bson.D{{Key: "source_type", Value: sourceType}, {Key: "source_uid", Value: sourceUid}}
+	err := r.db.Collection(entity.FinalRatingCol{}.CollectionName()).FindOne(ctx, filter).Decode(&finalRating)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &finalRating, nil
+}
+
+// SaveFinalRating saves the final rating with the outbox message of its event in one transaction
+func (r *ratingMpRepo) SaveFinalRating(finalRating entity.FinalRatingCol, event entity.OutboxCol) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	filter := bson.D{{Key: "source_type", Value: finalRating.SourceType}, {Key: "source_uid", Value: finalRating.SourceUID}}
+	data := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "value", Value: finalRating.Value},
+		{Key: "total_reviewer", Value: finalRating.TotalReviewer},
+		{Key: "histogram", Value: finalRating.Histogram},
+		{Key: "published_at", Value: finalRating.PublishedAt},
+	}}}
+	return r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
+		err := sessionContext.StartTransaction()
+		if err != nil {
+			return err
+		}
+		_, err = r.db.Collection(entity.FinalRatingCol{}.CollectionName()).UpdateOne(sessionContext, filter, data, options.Update().SetUpsert(true))
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		if err = insertOutboxMessages(sessionContext, r.db, []entity.OutboxCol{event}, ""); err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		return sessionContext.CommitTransaction(sessionContext)
+	})
+}
+
+// ScheduleFinalRating saves the outbox message recalculating the final rating of the source once due,
+// the pending message of the source is postponed to due so the changes until then are published once
+func (r *ratingMpRepo) ScheduleFinalRating(sourceType, sourceUid string, due time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	dateNow := time.Now().In(util.Loc)
+	filter := bson.D{
+		{Key: "target", Value: entity.OutboxTargetFinalRating},
+		{Key: "status", Value: entity.OutboxStatusPending},
+		{Key: "payload.source_type", Value: sourceType},
+		{Key: "payload.source_uid", Value: sourceUid},
+	}
+	data := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "next_attempt_at", Value: due},
+			{Key: "updated_at", Value: dateNow},
+		}},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "attempts", Value: 0},
+			{Key: "created_at", Value: dateNow},
+		}},
+	}
+	_, err := r.db.Collection(entity.OutboxCol{}.CollectionName()).UpdateOne(ctx, filter, data, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetFinalRatingSourceUids returns every product or store with a submission, a store is identified by the store_uid of its submissions
+func (r *ratingMpRepo) GetFinalRatingSourceUids(sourceType string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	field := "source_uid"
+	if global.IsStoreSourceType(sourceType) {
+		field = "store_uid"
+	}
+	values, err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).Distinct(ctx, field, bson.D{{Key: "source_type", Value: sourceType}})
+	if err != nil {
+		return nil, err
+	}
+	sourceUids := make([]string, 0, len(values))
+	for _, value := range values {
+		if sourceUid, ok := value.(string); ok && sourceUid != "" {
+			sourceUids = append(sourceUids, sourceUid)
+		}
+	}
+	return sourceUids, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type outboxRepo struct {
+	db *mongo.Database
+}
+
+type OutboxRepository interface {
+	ClaimOutboxMessage(lockDuration time.Duration) (*entity.OutboxCol, error)
+	MarkOutboxMessageDelivered(id primitive.ObjectID) error
+	MarkOutboxMessageFailed(id primitive.ObjectID, attempts int, lastError string, nextAttemptAt time.Time, dead bool) error
+	GetOutboxMessages(status, target string, page int, limit int64) ([]entity.OutboxCol, *base.Pagination, error)
+	GetOutboxMessageById(id primitive.ObjectID) (*entity.OutboxCol, error)
+	ReplayOutboxMessage(id primitive.ObjectID) error
+}
+
+func NewOutboxRepository(db *mongo.Database) OutboxRepository {
+	return &outboxRepo{db}
+}
+
+// insertOutboxMessages saves the messages within the transaction of the change they belong to,
+// submissionId is the submission saved in the same transaction
+func insertOutboxMessages(ctx context.Context, db *mongo.Database, messages []entity.OutboxCol, submissionId string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	dateNow := time.Now().In(util.Loc)
+	docs := make([]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		msg.Status = entity.OutboxStatusPending
+		msg.Attempts = 0
+		msg.NextAttemptAt = dateNow
+		msg.CreatedAt = dateNow
+		msg.UpdatedAt = dateNow
+		if msg.SubmissionID == "" {
+			msg.SubmissionID = submissionId
+		}
+		docs = append(docs, msg)
+	}
+	_, err := db.Collection(entity.OutboxCol{}.CollectionName()).InsertMany(ctx, docs)
+	return err
+}
+
+// ClaimOutboxMessage locks the next message due for delivery. A message still processing after its lock
+// expired crashed its dispatcher, it is claimed again first and the reclaim counts as an attempt.
+// It returns nil when nothing is due
+func (r *outboxRepo) ClaimOutboxMessage(lockDuration time.Duration) (*entity.OutboxCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	dateNow := time.Now().In(util.Loc)
+	lock := bson.E{Key: "$set", Value: bson.D{
+		{Key: "status", Value: entity.OutboxStatusProcessing},
+		{Key: "locked_until", Value: dateNow.Add(lockDuration)},
+		{Key: "updated_at", Value: dateNow},
+	}}
+
+	expired := bson.D{
+		{Key: "status", Value: entity.OutboxStatusProcessing},
+		{Key: "locked_until", Value: bson.D{{Key: "$lte", Value: dateNow}}},
+	}
+	result, err := r.claimOutboxMessage(ctx, expired, bson.D{lock, {Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}}})
+	if err != nil || result != nil {
+		return result, err
+	}
+
+	pending := bson.D{
+		{Key: "status", Value: entity.OutboxStatusPending},
+		{Key: "next_attempt_at", Value: bson.D{{Key: "$lte", Value: dateNow}}},
+	}
+	return r.claimOutboxMessage(ctx, pending, bson.D{lock})
+}
+
+func (r *outboxRepo) claimOutboxMessage(ctx context.Context, filter bson.D, update bson.D) (*entity.OutboxCol, error) {
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var result entity.OutboxCol
+	err := r.db.Collection(entity.OutboxCol{}.CollectionName()).FindOneAndUpdate(ctx, filter, update, opts).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (r *outboxRepo) MarkOutboxMessageDelivered(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	dateNow := time.Now().In(util.Loc)
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: entity.OutboxStatusDelivered},
+			{Key: "delivered_at", Value: dateNow},
+			{Key: "updated_at", Value: dateNow},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+		{Key: "$unset", Value: bson.D{{Key: "locked_until", Value: ""}, {Key: "last_error", Value: ""}}},
+	}
+	_, err := r.db.Collection(entity.OutboxCol{}.CollectionName()).UpdateByID(ctx, id, update)
+	return err
+}
+
+// MarkOutboxMessageFailed schedules the next attempt of the message, a dead message waits for a replay
+func (r *outboxRepo) MarkOutboxMessageFailed(id primitive.ObjectID, attempts int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	status := entity.OutboxStatusPending
+	if dead {
+		status = entity.OutboxStatusDead
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: status},
+			{Key: "attempts", Value: attempts},
+			{Key: "last_error", Value: lastError},
+			{Key: "next_attempt_at", Value: nextAttemptAt},
+			{Key: "updated_at", Value: time.Now().In(util.Loc)},
+		}},
+		{Key: "$unset", Value: bson.D{{Key: "locked_until", Value: ""}}},
+	}
+	_, err := r.db.Collection(entity.OutboxCol{}.CollectionName()).UpdateByID(ctx, id, update)
+	return err
+}
+
+func (r *outboxRepo) GetOutboxMessages(status, target string, page int, limit int64) ([]entity.OutboxCol, *base.Pagination, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	results := []entity.OutboxCol{}
+	collectionName := entity.OutboxCol{}.CollectionName()
+	filter := bson.D{}
+	if status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: status})
+	}
+	if target != "" {
+		filter = append(filter, bson.E{Key: "target", Value: target})
+	}
+	skip := int64(page)*limit - limit
+	cursor, err := r.db.Collection(collectionName).Find(ctx, filter, &options.FindOptions{
+		Sort:  bson.D{{Key: "created_at", Value: -1}},
+		Limit: &limit,
+		Skip:  &skip,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, nil, err
+	}
+	pagination := getPaginationMp(r.db, page, limit, results, collectionName, filter)
+	return results, pagination, nil
+}
+
+func (r *outboxRepo) GetOutboxMessageById(id primitive.ObjectID) (*entity.OutboxCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var result entity.OutboxCol
+	err := r.db.Collection(entity.OutboxCol{}.CollectionName()).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReplayOutboxMessage makes a dead or delivered message due again with a new set of attempts,
+// a message being processed is left to its dispatcher
+func (r *outboxRepo) ReplayOutboxMessage(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	dateNow := time.Now().In(util.Loc)
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "status", Value: bson.D{{Key: "$ne", Value: entity.OutboxStatusProcessing}}},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: entity.OutboxStatusPending},
+			{Key: "attempts", Value: 0},
+			{Key: "next_attempt_at", Value: dateNow},
+			{Key: "updated_at", Value: dateNow},
+		}},
+		{Key: "$unset", Value: bson.D{{Key: "delivered_at", Value: ""}}},
+	}
+	result, err := r.db.Collection(entity.OutboxCol{}.CollectionName()).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -8,12 +8,13 @@ import (
 	"go-klikdokter/app/model/entity"
 	publicrequest "go-klikdokter/app/model/request/public"
 	publicresponse "go-klikdokter/app/model/response/public"
+	"go-klikdokter/helper/global"
 	"go-klikdokter/pkg/util"
+	util_search "go-klikdokter/pkg/util/search"
 	"math"
 	"reflect"
 	"time"
 
-	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -33,19 +34,26 @@ type PublicRatingMpRepository interface {
 	GetRatingSubsByRatingId(ratingId string) ([]entity.RatingSubmissionMp, error)
 	GetSumCountRatingSubsByRatingId(ratingId string) (*publicresponse.PublicSumCountRatingSummaryMp, error)
 	GetRatingFormulaBySourceType(sourceType string) (*entity.RatingFormulaCol, error)
-	GetSumCountRatingSubsBySource(sourceUID string, sourceType string) (*publicresponse.PublicSumCountRatingSummaryMp, error)
+	GetSumCountRatingSubsBySource(sourceUID string, sourceType string, verifiedOnly bool) (*publicresponse.PublicSumCountRatingSummaryMp, error)
 	GetPublicRatingSubmissionsCustom(limit, page, dir int, sort string, filter publicrequest.FilterRatingSubmissionMp, source string) ([]entity.RatingSubmissionMp, *base.Pagination, error)
 	GetPublicRatingSubmissionsGroupByStoreSource(filter publicrequest.FilterRatingSummary) ([]publicresponse.PublicRatingSubGroupByStoreSourceMp, error)
+	GetPublicRatingSubmissionsGroupByDimension(filter publicrequest.FilterRatingSummary, uidField string) ([]publicresponse.PublicRatingSubGroupByDimensionMp, error)
+	GetPublicRatingSubmissionsGroupByTag(filter publicrequest.FilterRatingSummary, uidField string) ([]publicresponse.PublicRatingSubGroupByTag, error)
 	GetRatingSubsGroupByValue(sourceUid string, sourceType string) ([]interface{}, error)
+	GetLikedRatingSubIdsByActor(ratingSubIds []string, userIdLegacy string) (map[string]bool, error)
 }
 
 func NewPublicRatingMpRepository(db *mongo.Database) PublicRatingMpRepository {
 	return &publicRatingMpRepo{db}
 }
 
+func (r *publicRatingMpRepo) GetLikedRatingSubIdsByActor(ratingSubIds []string, userIdLegacy string) (map[string]bool, error) {
+	return getLikedRatingSubIdsByActor(r.db, ratingSubIds, userIdLegacy)
+}
+
 func (r *publicRatingMpRepo) GetListRatingBySourceTypeAndUID(sourceType, sourceUID string) ([]entity.RatingsMpCol, error) {
 	var results []entity.RatingsMpCol
-	arrRatingType := viper.GetStringSlice("rating-type-mp")
+	arrRatingType := global.GetMarketplaceRatingTypes()
 
 	bsonFilter := bson.D{{Key: "$and", Value: bson.A{
 		bson.D{{Key: "source_type", Value: sourceType}},
@@ -127,7 +135,10 @@ func (r *publicRatingMpRepo) GetPublicRatingSubmissions(limit, page, dir int, so
 		bsonSourceUID,
 		bsonSourceUIDs,
 		bsonCancelled,
+		bsonModerationApproved,
+		bsonVerifiedPurchase(filter.VerifiedOnly),
 		bsonCreatedAt,
+		util_search.Filter(filter.Q),
 		bson.D{{Key: "$or",
 			Value: bson.A{
 				bsonUserIdLegacy,
@@ -148,13 +159,8 @@ func (r *publicRatingMpRepo) GetPublicRatingSubmissions(limit, page, dir int, so
 
 	collectionName := entity.RatingSubmissionMp{}.CollectionName()
 	skip := int64(page)*limit64 - limit64
-	cursor, err := r.db.Collection(collectionName).
-		Find(context.Background(), bsonFilter,
-			&options.FindOptions{
-				Sort:  bson.D{bson.E{Key: sort, Value: dir}},
-				Limit: &limit64,
-				Skip:  &skip,
-			})
+	sortDoc, sortFields := sortRatingSubmissions(sort, dir, filter.Q, false)
+	cursor, err := findSortedRatingSubmissions(r.db.Collection(collectionName), bsonFilter, sortDoc, sortFields, limit64, skip)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -205,6 +211,7 @@ func (r *publicRatingMpRepo) GetPublicRatingSubmissionsCustom(limit, page, dir i
 	bsonFilter = bson.D{{Key: "$and", Value: bson.A{
 		bsonRatingSubsID,
 		bsonCancelled,
+		bsonModerationApproved,
 	}}}
 	collectionName := entity.RatingSubmissionMp{}.CollectionName()
 	if source == "all" {
@@ -212,13 +219,9 @@ func (r *publicRatingMpRepo) GetPublicRatingSubmissionsCustom(limit, page, dir i
 	}
 
 	skip := int64(page)*limit64 - limit64
-	cursor, err := r.db.Collection(collectionName).
-		Find(context.Background(), bsonFilter,
-			&options.FindOptions{
-				Sort:  bson.D{bson.E{Key: sort, Value: dir}},
-				Limit: &limit64,
-				Skip:  &skip,
-			})
+	// the legacy values of source all are strings
+	sortDoc, sortFields := sortRatingSubmissions(sort, dir, "", source == "all")
+	cursor, err := findSortedRatingSubmissions(r.db.Collection(collectionName), bsonFilter, sortDoc, sortFields, limit64, skip)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -264,6 +267,8 @@ func (r *publicRatingMpRepo) GetPublicRatingSubmissionsGroupBySource(filter publ
 			bsonSourceUID,
 			bsonSourceType,
 			bsonCancelled,
+			bsonModerationApproved,
+			bsonVerifiedPurchase(filter.VerifiedOnly),
 		},
 	}}
 
@@ -369,6 +374,7 @@ func (r *publicRatingMpRepo) CountRatingSubsByRatingIdAndValue(ratingId, value s
 		Value: bson.A{
 			bsonRatingId,
 			bsonValue,
+			bsonModerationApproved,
 		}},
 	}
 	counter, err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).CountDocuments(context.Background(), bsonFilter, &options.CountOptions{})
@@ -387,6 +393,7 @@ func (r *publicRatingMpRepo) GetRatingSubsByRatingId(ratingId string) ([]entity.
 		Value: bson.A{
 			bsonRatingId,
 			bsonCancelled,
+			bsonModerationApproved,
 		},
 	}}
 
@@ -410,6 +417,8 @@ func (r *publicRatingMpRepo) GetSumCountRatingSubsByRatingId(ratingId string) (*
 	bsonRatingIdAndCancelled := bson.D{
 		{Key: "rating_id", Value: ratingId},
 		{Key: "cancelled", Value: false},
+		moderationApproved,
+		notDeleted,
 	}
 
 	pipeline := bson.A{
@@ -446,12 +455,15 @@ func (r *publicRatingMpRepo) GetSumCountRatingSubsByRatingId(ratingId string) (*
 	return &results[0], nil
 }
 
-func (r *publicRatingMpRepo) GetSumCountRatingSubsBySource(sourceUID string, sourceType string) (*publicresponse.PublicSumCountRatingSummaryMp, error) {
+func (r *publicRatingMpRepo) GetSumCountRatingSubsBySource(sourceUID string, sourceType string, verifiedOnly bool) (*publicresponse.PublicSumCountRatingSummaryMp, error) {
 	var results []publicresponse.PublicSumCountRatingSummaryMp
 	bsonRatingIdAndCancelled := bson.D{
 		{Key: "source_uid", Value: sourceUID},
 		{Key: "source_type", Value: sourceType},
-		{Key: "cancelled", Value: false}}
+		{Key: "cancelled", Value: false},
+		moderationApproved,
+		notDeleted}
+	bsonRatingIdAndCancelled = append(bsonRatingIdAndCancelled, bsonVerifiedPurchase(verifiedOnly)...)
 
 	bsonGroupID := bson.D{{Key: "source_uid", Value: sourceUID},
 		{Key: "source_type", Value: sourceType}}
@@ -498,7 +510,6 @@ func (r *publicRatingMpRepo) GetRatingFormulaBySourceType(sourceType string) (*e
 	var ratingFormula entity.RatingFormulaCol
 
 	bsonSourceType := bson.D{{Key: "source_type", Value: sourceType}}
-	bsonStatus := bson.D{{Key: "status", Value: true}}
 
 	bsonFilter := bson.D{{Key: "$and",
 		Value: bson.A{
@@ -511,7 +522,7 @@ func (r *publicRatingMpRepo) GetRatingFormulaBySourceType(sourceType string) (*e
 	err := r.db.Collection(entity.RatingFormulaCol{}.CollectionName()).FindOne(ctx, bsonFilter).Decode(&ratingFormula)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil
+			return global.GetDefaultRatingFormula(sourceType), nil
 		}
 		return nil, err
 	}
@@ -609,6 +620,8 @@ func (r *publicRatingMpRepo) GetPublicRatingSubmissionsGroupByStoreSource(filter
 			bsonStoreUID,
 			bsonSourceType,
 			bsonCancelled,
+			bsonModerationApproved,
+			bsonVerifiedPurchase(filter.VerifiedOnly),
 		},
 	}}
 
@@ -658,6 +671,69 @@ func (r *publicRatingMpRepo) GetPublicRatingSubmissionsGroupByStoreSource(filter
 	return results, nil
 }
 
+// GetPublicRatingSubmissionsGroupByDimension counts the values of every dimension of the submissions by uidField,
+// source_uid with the uids of filter.SourceUid or store_uid with the uids of filter.StoreUID
+func (r *publicRatingMpRepo) GetPublicRatingSubmissionsGroupByDimension(filter publicrequest.FilterRatingSummary, uidField string) ([]publicresponse.PublicRatingSubGroupByDimensionMp, error) {
+	var results = []publicresponse.PublicRatingSubGroupByDimensionMp{}
+	uids := filter.SourceUid
+	if uidField == "store_uid" {
+		uids = filter.StoreUID
+	}
+
+	bsonSourceType := bson.D{}
+	bsonUID := bson.D{}
+	if filter.SourceType != "" {
+		bsonSourceType = bson.D{{Key: "source_type", Value: filter.SourceType}}
+	}
+	if len(uids) > 0 {
+		bsonUID = bson.D{{Key: uidField, Value: bson.D{{Key: "$in", Value: uids}}}}
+	}
+
+	filterSource := bson.D{{Key: "$and",
+		Value: bson.A{
+			bsonUID,
+			bsonSourceType,
+			bson.D{{Key: "cancelled", Value: false}},
+			bson.D{{Key: "dimensions", Value: bson.D{{Key: "$exists", Value: true}}}},
+			bsonModerationApproved,
+			bsonVerifiedPurchase(filter.VerifiedOnly),
+		},
+	}}
+
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: filterSource}},
+		bson.D{{Key: "$unwind", Value: "$dimensions"}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "uid", Value: "$" + uidField},
+				{Key: "name", Value: "$dimensions.name"},
+				{Key: "value", Value: "$dimensions.value"},
+			}},
+			{Key: "total_value", Value: bson.D{{Key: "$sum", Value: "$dimensions.value"}}},
+			{Key: "total_reviewer", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "uid", Value: "$_id.uid"},
+				{Key: "name", Value: "$_id.name"},
+			}},
+			{Key: "total_value", Value: bson.D{{Key: "$sum", Value: "$total_value"}}},
+			{Key: "total_reviewer", Value: bson.D{{Key: "$sum", Value: "$total_reviewer"}}},
+			{Key: "array_value", Value: bson.D{{Key: "$push", Value: bson.D{{Key: "key", Value: "$_id.value"}, {Key: "value", Value: "$total_reviewer"}}}}},
+		}}},
+	}
+
+	collectionName := entity.RatingSubmissionMp{}.CollectionName()
+	cursor, err := r.db.Collection(collectionName).Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func (r *publicRatingMpRepo) GetRatingSubsGroupByValue(sourceUid string, sourceType string) ([]interface{}, error) {
 	var results []interface{}
 	bsonCancelled := bson.D{{Key: "cancelled", Value: false}}
@@ -669,6 +745,7 @@ func (r *publicRatingMpRepo) GetRatingSubsGroupByValue(sourceUid string, sourceT
 			bsonSourceUID,
 			bsonSourceType,
 			bsonCancelled,
+			bsonModerationApproved,
 		},
 	}}
 
@@ -7,7 +7,10 @@ import (
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
 	"go-klikdokter/app/model/request/public"
+	publicresponse "go-klikdokter/app/model/response/public"
+	"go-klikdokter/helper/global"
 	"go-klikdokter/pkg/util"
+	util_search "go-klikdokter/pkg/util/search"
 	"math"
 	"reflect"
 	"time"
@@ -26,10 +29,8 @@ type PublicRatingRepository interface {
 	GetRatingsBySourceTypeAndActor(sourceType, sourceUID string, filter publicrequest.GetRatingBySourceTypeAndActorFilter) ([]entity.RatingsCol, error)
 	GetRatingTypeLikertById(id primitive.ObjectID) (*entity.RatingTypesLikertCol, error)
 	GetRatingTypeNumById(id primitive.ObjectID) (*entity.RatingTypesNumCol, error)
-	CreateRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, error)
-	UpdateStatusRatingSubHelpful(id primitive.ObjectID, currentStatus bool) error
 	GetRatingSubHelpfulByRatingSubAndActor(ratingSubId, userIdLegacy string) (*entity.RatingSubHelpfulCol, error)
-	UpdateCounterRatingSubmission(id primitive.ObjectID, currentCounter int64) error
+	GetLikedRatingSubIdsByActor(ratingSubIds []string, userIdLegacy string) (map[string]bool, error)
 	GetPublicRatingsByParams(limit, page, dir int, sort string, filter publicrequest.FilterRatingSummary) ([]entity.RatingsCol, *base.Pagination, error)
 	GetRatingSubsByRatingId(ratingId string) ([]entity.RatingSubmisson, error)
 	CountRatingSubsByRatingIdAndValue(ratingId, value string) (int64, error)
@@ -37,20 +38,36 @@ type PublicRatingRepository interface {
 	GetRatingFormulaByRatingTypeIdAndSourceType(ratingTypeId, sourceType string) (*entity.RatingFormulaCol, error)
 	UpdateRatingSubDisplayNameByIdLegacy(input request.UpdateRatingSubDisplayNameRequest) error
 	GetListRatingBySourceTypeAndUID(sourceType, sourceUID string) ([]entity.RatingsCol, error)
+	GetPublicRatingSubmissionsGroupByTag(ratingIds []string, verifiedOnly bool) ([]publicresponse.PublicRatingSubGroupByTag, error)
 }
 
 func NewPublicRatingRepository(db *mongo.Database) PublicRatingRepository {
 	return &publicRatingRepo{db}
 }
 
-var bsonStatus = bson.D{{"status", true}}
+// notDeleted keeps soft deleted rows out of public reads and summaries
+var notDeleted = bson.E{Key: "deleted_at", Value: nil}
+
+var bsonStatus = bson.D{{"status", true}, notDeleted}
+
+// bsonModerationApproved keeps pending and rejected submissions out of public reads and summaries,
+// submissions stored before moderation have no moderation_status and are treated as approved
+var moderationApproved = bson.E{Key: "moderation_status", Value: bson.D{{Key: "$nin", Value: bson.A{entity.ModerationStatusPending, entity.ModerationStatusRejected}}}}
+var bsonModerationApproved = bson.D{moderationApproved, notDeleted}
+
+// bsonVerifiedPurchase keeps the submissions of verified purchases only when verifiedOnly is set
+func bsonVerifiedPurchase(verifiedOnly bool) bson.D {
+	if !verifiedOnly {
+		return bson.D{}
+	}
+	return bson.D{{Key: "is_verified_purchase", Value: true}}
+}
 
 func (r *publicRatingRepo) GetRatingsBySourceTypeAndActor(sourceType, sourceUID string, filter publicrequest.GetRatingBySourceTypeAndActorFilter) ([]entity.RatingsCol, error) {
 	var results []entity.RatingsCol
 
 	bsonSourceType := bson.D{{Key: "source_type", Value: sourceType}}
 	bsonSourceUid := bson.D{{Key: "source_uid", Value: sourceUID}}
-	bsonStatus := bson.D{{Key: "status", Value: true}}
 	bsonRatingType := bson.D{}
 
 	if len(filter.RatingType) > 0 {
@@ -91,7 +108,7 @@ func (r *publicRatingRepo) GetRatingsBySourceTypeAndActor(sourceType, sourceUID
 func (r *publicRatingRepo) GetRatingTypeLikertById(id primitive.ObjectID) (*entity.RatingTypesLikertCol, error) {
 	var ratingTypeLikert entity.RatingTypesLikertCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := r.db.Collection("ratingTypesLikertCol").FindOne(ctx, bson.M{"_id": id}).Decode(&ratingTypeLikert)
+	err := r.db.Collection("ratingTypesLikertCol").FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&ratingTypeLikert)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil
@@ -104,7 +121,7 @@ func (r *publicRatingRepo) GetRatingTypeLikertById(id primitive.ObjectID) (*enti
 func (r *publicRatingRepo) GetRatingTypeNumById(id primitive.ObjectID) (*entity.RatingTypesNumCol, error) {
 	var ratingTypeNum entity.RatingTypesNumCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := r.db.Collection("ratingTypesNumCol").FindOne(ctx, bson.M{"_id": id}).Decode(&ratingTypeNum)
+	err := r.db.Collection("ratingTypesNumCol").FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&ratingTypeNum)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil
@@ -114,53 +131,6 @@ func (r *publicRatingRepo) GetRatingTypeNumById(id primitive.ObjectID) (*entity.
 	return &ratingTypeNum, nil
 }
 
-func (r *publicRatingRepo) CreateRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, error) {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
-	var ratingSubHelpful entity.RatingSubHelpfulCol
-
-	// transaction
-	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
-		err := sessionContext.StartTransaction()
-		if err != nil {
-			return err
-		}
-		result, err := r.db.Collection(entity.RatingSubHelpfulCol{}.CollectionName()).InsertOne(ctx, bson.M{
-			"rating_submission_id": input.RatingSubmissionID,
-			"user_id":              input.UserID,
-			"user_id_legacy":       input.UserIDLegacy,
-			"ip_address":           input.IPAddress,
-			"user_agent":           input.UserAgent,
-			"status":               true,
-			"created_at":           time.Now().In(util.Loc),
-			"updated_at":           time.Now().In(util.Loc),
-		})
-
-		if err != nil {
-			sessionContext.AbortTransaction(sessionContext)
-			return err
-		}
-		if err = sessionContext.CommitTransaction(sessionContext); err != nil {
-			return err
-		}
-		ratingSubHelpful.ID = result.InsertedID.(primitive.ObjectID)
-		return nil
-	})
-	if errTransaction != nil {
-		return nil, errTransaction
-	}
-	return &ratingSubHelpful, nil
-}
-
-func (r *publicRatingRepo) UpdateStatusRatingSubHelpful(id primitive.ObjectID, currentStatus bool) error {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: !currentStatus}}}}
-	_, err := r.db.Collection(entity.RatingSubHelpfulCol{}.CollectionName()).UpdateOne(ctx, bson.M{"_id": id}, update)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func (r *publicRatingRepo) GetRatingSubHelpfulByRatingSubAndActor(ratingSubId, userIdLegacy string) (*entity.RatingSubHelpfulCol, error) {
 	var ratingSubHelpful entity.RatingSubHelpfulCol
 	bsonRatingSubId := bson.D{{Key: "rating_submission_id", Value: ratingSubId}}
@@ -184,56 +154,38 @@ func (r *publicRatingRepo) GetRatingSubHelpfulByRatingSubAndActor(ratingSubId, u
 	return &ratingSubHelpful, nil
 }
 
-func (r *publicRatingRepo) UpdateCounterRatingSubmission(id primitive.ObjectID, currentCounter int64) error {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
-	// timeUpdate := time.Now().In(util.Loc)
+func (r *publicRatingRepo) GetLikedRatingSubIdsByActor(ratingSubIds []string, userIdLegacy string) (map[string]bool, error) {
+	return getLikedRatingSubIdsByActor(r.db, ratingSubIds, userIdLegacy)
+}
 
-	helpfulCounter, err := countRatingSubHelpful(r, id.Hex())
-	if err != nil {
-		return err
-	}
-	if currentCounter != helpfulCounter {
-		currentCounter = helpfulCounter
+// getLikedRatingSubIdsByActor returns the ids among ratingSubIds the user marked helpful, in one query for a page of submissions
+func getLikedRatingSubIdsByActor(db *mongo.Database, ratingSubIds []string, userIdLegacy string) (map[string]bool, error) {
+	results := map[string]bool{}
+	if userIdLegacy == "" || len(ratingSubIds) == 0 {
+		return results, nil
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
 
-	filter := bson.D{{Key: "_id", Value: id}}
-	update := bson.M{"$set": bson.M{"like_counter": int(currentCounter)}}
-
-	// transaction
-	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
-		err := sessionContext.StartTransaction()
-		if err != nil {
-			return err
-		}
-		err1 := r.db.Collection("ratingSubCol").FindOneAndUpdate(context.Background(), filter, update, &options.FindOneAndUpdateOptions{})
-		if err1 != nil {
-			sessionContext.AbortTransaction(sessionContext)
-			return err1.Err()
-		}
-		if err = sessionContext.CommitTransaction(sessionContext); err != nil {
-			return err
-		}
-		return nil
-	})
-	if errTransaction != nil {
-		return errTransaction
+	filter := bson.D{
+		{Key: "rating_submission_id", Value: bson.D{{Key: "$in", Value: ratingSubIds}}},
+		{Key: "user_id_legacy", Value: userIdLegacy},
+		{Key: "status", Value: true},
 	}
-	return nil
-}
-
-func countRatingSubHelpful(r *publicRatingRepo, ratingSubId string) (int64, error) {
-	bsonRatingSubId := bson.D{{Key: "rating_submission_id", Value: ratingSubId}}
-	filter := bson.D{{Key: "$and",
-		Value: bson.A{
-			bsonRatingSubId,
-			bsonStatus,
-		},
-	}}
-	counter, err := r.db.Collection("ratingSubHelpfulCol").CountDocuments(context.Background(), filter, &options.CountOptions{})
+	cursor, err := db.Collection(entity.RatingSubHelpfulCol{}.CollectionName()).Find(ctx, filter, &options.FindOptions{
+		Projection: bson.D{{Key: "rating_submission_id", Value: 1}},
+	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return counter, nil
+	var helpfuls []entity.RatingSubHelpfulCol
+	if err = cursor.All(ctx, &helpfuls); err != nil {
+		return nil, err
+	}
+	for _, helpful := range helpfuls {
+		results[helpful.RatingSubmissionID] = true
+	}
+	return results, nil
 }
 
 func (r *publicRatingRepo) GetPublicRatingsByParams(limit, page, dir int, sort string, filter publicrequest.FilterRatingSummary) ([]entity.RatingsCol, *base.Pagination, error) {
@@ -298,6 +250,7 @@ func (r *publicRatingRepo) GetRatingSubsByRatingId(ratingId string) ([]entity.Ra
 		Value: bson.A{
 			bsonRatingId,
 			bsonCancelled,
+			bsonModerationApproved,
 		},
 	}}
 
@@ -324,6 +277,7 @@ func (r *publicRatingRepo) CountRatingSubsByRatingIdAndValue(ratingId, value str
 		Value: bson.A{
 			bsonRatingId,
 			bsonValue,
+			bsonModerationApproved,
 		}},
 	}
 	counter, err := r.db.Collection("ratingSubCol").CountDocuments(context.Background(), bsonFilter, &options.CountOptions{})
@@ -375,6 +329,9 @@ func (r *publicRatingRepo) GetPublicRatingSubmissions(limit, page, dir int, sort
 			bsonLikertVal,
 			bsonCancelled,
 			bsonCreatedAt,
+			bsonModerationApproved,
+			bsonVerifiedPurchase(filter.VerifiedOnly),
+			util_search.Filter(filter.Q),
 		}}}
 	} else {
 		bsonRatingID := bson.D{{Key: "rating_id", Value: bson.D{{Key: "$in", Value: filter.RatingID}}}}
@@ -382,6 +339,9 @@ func (r *publicRatingRepo) GetPublicRatingSubmissions(limit, page, dir int, sort
 			bsonRatingID,
 			bsonCancelled,
 			bsonCreatedAt,
+			bsonModerationApproved,
+			bsonVerifiedPurchase(filter.VerifiedOnly),
+			util_search.Filter(filter.Q),
 			bson.D{{Key: "$or",
 				Value: bson.A{
 					bsonUserIdLegacy,
@@ -399,13 +359,8 @@ func (r *publicRatingRepo) GetPublicRatingSubmissions(limit, page, dir int, sort
 
 	collectionName := "ratingSubCol"
 	skip := int64(page)*limit64 - limit64
-	cursor, err := r.db.Collection(collectionName).
-		Find(context.Background(), bsonFilter,
-			&options.FindOptions{
-				Sort:  bson.D{bson.E{Key: sort, Value: dir}},
-				Limit: &limit64,
-				Skip:  &skip,
-			})
+	sortDoc, sortFields := sortRatingSubmissions(sort, dir, filter.Q, true)
+	cursor, err := findSortedRatingSubmissions(r.db.Collection(collectionName), bsonFilter, sortDoc, sortFields, limit64, skip)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -424,7 +379,6 @@ func (r *publicRatingRepo) GetRatingFormulaByRatingTypeIdAndSourceType(ratingTyp
 
 	bsonRatingTypeId := bson.D{{Key: "rating_type_id", Value: ratingTypeId}}
 	bsonSourceType := bson.D{{Key: "source_type", Value: sourceType}}
-	bsonStatus := bson.D{{Key: "status", Value: true}}
 
 	bsonFilter := bson.D{{Key: "$and",
 		Value: bson.A{
@@ -438,7 +392,7 @@ func (r *publicRatingRepo) GetRatingFormulaByRatingTypeIdAndSourceType(ratingTyp
 	err := r.db.Collection("ratingFormulaCol").FindOne(ctx, bsonFilter).Decode(&ratingFormula)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil
+			return global.GetDefaultRatingFormula(sourceType), nil
 		}
 		return nil, err
 	}
@@ -446,7 +400,7 @@ func (r *publicRatingRepo) GetRatingFormulaByRatingTypeIdAndSourceType(ratingTyp
 }
 
 func (r *publicRatingRepo) UpdateRatingSubDisplayNameByIdLegacy(input request.UpdateRatingSubDisplayNameRequest) error {
-	filter := bson.D{{Key: "user_id_legacy", Value: input.UserIdLegacy}}
+	filter := bson.D{{Key: "user_id_legacy", Value: input.UserIdLegacy}, notDeleted}
 	update := bson.M{"$set": bson.M{"display_name": input.DisplayName}}
 
 	_, err := r.db.Collection("ratingSubCol").UpdateMany(context.Background(), filter, update)
@@ -465,7 +419,7 @@ func (r *publicRatingRepo) GetListRatingBySourceTypeAndUID(sourceType, sourceUID
 		bson.D{{Key: "source_type", Value: sourceType}},
 		bson.D{{Key: "source_uid", Value: sourceUID}},
 		bson.D{{Key: "rating_type", Value: bson.D{{Key: "$in", Value: ratingType}}}},
-		bson.D{{Key: "status", Value: true}},
+		bsonStatus,
 	}}}
 
 	cursor, err := r.db.Collection(entity.RatingsCol{}.CollectionName()).Find(context.Background(), bsonFilter)
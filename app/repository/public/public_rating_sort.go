@@ -0,0 +1,125 @@
+package publicrepository
+
+import (
+	"context"
+	"go-klikdokter/app/model/request/public"
+	util_search "go-klikdokter/pkg/util/search"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultRelevanceCommentWeight     = 1.0
+	defaultRelevanceMediaWeight       = 1.0
+	defaultRelevanceLikeWeight        = 1.0
+	defaultRelevanceRecencyWeight     = 1.0
+	defaultRelevanceTextWeight        = 1.0
+	defaultRelevanceCommentFullLength = 200
+	defaultRelevanceHalfLifeDays      = 30
+)
+
+// relevanceWeight returns relevance-sort.<key> of the config, fallback when not set
+func relevanceWeight(key string, fallback float64) float64 {
+	if !viper.IsSet("relevance-sort." + key) {
+		return fallback
+	}
+	return viper.GetFloat64("relevance-sort." + key)
+}
+
+func positiveOrDefault(value, fallback float64) float64 {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// relevanceScore is the weighted score of the relevance sort, every part is between 0 and 1 except the likes growing on a log scale:
+// the comment length up to comment-full-length, the media, the likes, the recency halving every half-life-days
+// and the text score when the list is searched
+func relevanceScore(q string) bson.D {
+	commentFullLength := positiveOrDefault(viper.GetFloat64("relevance-sort.comment-full-length"), defaultRelevanceCommentFullLength)
+	halfLifeMs := positiveOrDefault(viper.GetFloat64("relevance-sort.half-life-days"), defaultRelevanceHalfLifeDays) * float64(24*time.Hour/time.Millisecond)
+
+	comment := bson.D{{Key: "$min", Value: bson.A{1, bson.D{{Key: "$divide", Value: bson.A{
+		bson.D{{Key: "$strLenCP", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$comment", ""}}}}},
+		commentFullLength,
+	}}}}}}
+	media := bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$is_with_media", true}}}, 1, 0}}}
+	likes := bson.D{{Key: "$ln", Value: bson.D{{Key: "$add", Value: bson.A{1, bson.D{{Key: "$max", Value: bson.A{0, bson.D{{Key: "$ifNull", Value: bson.A{"$like_counter", 0}}}}}}}}}}}
+	recency := bson.D{{Key: "$pow", Value: bson.A{0.5, bson.D{{Key: "$divide", Value: bson.A{
+		bson.D{{Key: "$max", Value: bson.A{0, bson.D{{Key: "$subtract", Value: bson.A{"$$NOW", "$created_at"}}}}}},
+		halfLifeMs,
+	}}}}}}
+
+	parts := bson.A{
+		bson.D{{Key: "$multiply", Value: bson.A{relevanceWeight("comment-weight", defaultRelevanceCommentWeight), comment}}},
+		bson.D{{Key: "$multiply", Value: bson.A{relevanceWeight("media-weight", defaultRelevanceMediaWeight), media}}},
+		bson.D{{Key: "$multiply", Value: bson.A{relevanceWeight("like-weight", defaultRelevanceLikeWeight), likes}}},
+		bson.D{{Key: "$multiply", Value: bson.A{relevanceWeight("recency-weight", defaultRelevanceRecencyWeight), recency}}},
+	}
+	if util_search.Normalize(q) != "" {
+		parts = append(parts, bson.D{{Key: "$multiply", Value: bson.A{
+			relevanceWeight("text-weight", defaultRelevanceTextWeight),
+			bson.D{{Key: "$meta", Value: "textScore"}},
+		}}})
+	}
+	return bson.D{{Key: "$add", Value: parts}}
+}
+
+// sortRatingSubmissions returns the sort of the public list of rating submissions and the fields to compute before sorting, nil when none.
+// sort is one of publicrequest.ListSortRatingSubmission, created_at is used for any other value.
+// valueIsString converts the value of the legacy submissions saved as string before sorting by value.
+func sortRatingSubmissions(sort string, dir int, q string, valueIsString bool) (bson.D, bson.D) {
+	byRecency := bson.E{Key: "created_at", Value: -1}
+	valueField := "value"
+	var fields bson.D
+	if valueIsString {
+		valueField = "value_number"
+		fields = bson.D{{Key: valueField, Value: bson.D{{Key: "$convert", Value: bson.D{
+			{Key: "input", Value: "$value"},
+			{Key: "to", Value: "double"},
+			{Key: "onError", Value: 0},
+			{Key: "onNull", Value: 0},
+		}}}}}
+	}
+
+	switch sort {
+	case publicrequest.SortMostHelpful:
+		return bson.D{{Key: "like_counter", Value: -1}, byRecency}, nil
+	case publicrequest.SortHighest:
+		return bson.D{{Key: valueField, Value: -1}, byRecency}, fields
+	case publicrequest.SortLowest:
+		return bson.D{{Key: valueField, Value: 1}, byRecency}, fields
+	case publicrequest.SortWithMediaFirst:
+		return bson.D{{Key: "is_with_media", Value: -1}, byRecency}, nil
+	case publicrequest.SortRelevance:
+		return bson.D{{Key: "relevance_score", Value: -1}, byRecency}, bson.D{{Key: "relevance_score", Value: relevanceScore(q)}}
+	case "":
+		return util_search.Sort(q, "", dir), nil
+	default:
+		return util_search.Sort(q, publicrequest.SortCreatedAt, dir), nil
+	}
+}
+
+// findSortedRatingSubmissions finds a page of the rating submissions matching the filter,
+// fields are computed by an aggregation before sorting when not empty
+func findSortedRatingSubmissions(collection *mongo.Collection, filter, sort, fields bson.D, limit, skip int64) (*mongo.Cursor, error) {
+	if len(fields) == 0 {
+		return collection.Find(context.Background(), filter, &options.FindOptions{
+			Sort:  sort,
+			Limit: &limit,
+			Skip:  &skip,
+		})
+	}
+	return collection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: fields}},
+		{{Key: "$sort", Value: sort}},
+		{{Key: "$skip", Value: skip}},
+		{{Key: "$limit", Value: limit}},
+	})
+}
@@ -0,0 +1,75 @@
+package publicrepository
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	publicrequest "go-klikdokter/app/model/request/public"
+	publicresponse "go-klikdokter/app/model/response/public"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetPublicRatingSubmissionsGroupByTag counts the tags of the submissions of the ratings by rating_id
+func (r *publicRatingRepo) GetPublicRatingSubmissionsGroupByTag(ratingIds []string, verifiedOnly bool) ([]publicresponse.PublicRatingSubGroupByTag, error) {
+	filter := bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "rating_id", Value: bson.D{{Key: "$in", Value: ratingIds}}}},
+		bson.D{{Key: "cancelled", Value: false}},
+		bsonModerationApproved,
+		bsonVerifiedPurchase(verifiedOnly),
+	}}}
+	return getPublicRatingSubmissionsGroupByTag(r.db, entity.RatingSubmisson{}.CollectionName(), "rating_id", filter)
+}
+
+// GetPublicRatingSubmissionsGroupByTag counts the tags of the reviews of filter.SourceType by uidField,
+// source_uid with the uids of filter.SourceUid or store_uid with the uids of filter.StoreUID, only when they are set
+func (r *publicRatingMpRepo) GetPublicRatingSubmissionsGroupByTag(filter publicrequest.FilterRatingSummary, uidField string) ([]publicresponse.PublicRatingSubGroupByTag, error) {
+	uids := filter.SourceUid
+	if uidField == "store_uid" {
+		uids = filter.StoreUID
+	}
+	bsonSourceType := bson.D{}
+	bsonUID := bson.D{}
+	if filter.SourceType != "" {
+		bsonSourceType = bson.D{{Key: "source_type", Value: filter.SourceType}}
+	}
+	if len(uids) > 0 {
+		bsonUID = bson.D{{Key: uidField, Value: bson.D{{Key: "$in", Value: uids}}}}
+	}
+	filterSource := bson.D{{Key: "$and", Value: bson.A{
+		bsonUID,
+		bsonSourceType,
+		bson.D{{Key: "cancelled", Value: false}},
+		bsonModerationApproved,
+		bsonVerifiedPurchase(filter.VerifiedOnly),
+	}}}
+	return getPublicRatingSubmissionsGroupByTag(r.db, entity.RatingSubmissionMp{}.CollectionName(), uidField, filterSource)
+}
+
+// getPublicRatingSubmissionsGroupByTag counts the submissions of collectionName matching filter by uidField and tag,
+// the most selected tags first
+func getPublicRatingSubmissionsGroupByTag(db *mongo.Database, collectionName, uidField string, filter bson.D) ([]publicresponse.PublicRatingSubGroupByTag, error) {
+	var results = []publicresponse.PublicRatingSubGroupByTag{}
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$match", Value: bson.D{{Key: "tags.0", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+		bson.D{{Key: "$unwind", Value: "$tags"}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "uid", Value: "$" + uidField},
+				{Key: "key", Value: "$tags"},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id.key", Value: 1}}}},
+	}
+
+	cursor, err := db.Collection(collectionName).Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -39,6 +39,29 @@ func (_m *PublicRatingMpRepository) CountRatingSubsByRatingIdAndValue(ratingId s
 	return r0, r1
 }
 
+// GetLikedRatingSubIdsByActor provides a mock function with given fields: ratingSubIds, userIdLegacy
+func (_m *PublicRatingMpRepository) GetLikedRatingSubIdsByActor(ratingSubIds []string, userIdLegacy string) (map[string]bool, error) {
+	ret := _m.Called(ratingSubIds, userIdLegacy)
+
+	var r0 map[string]bool
+	if rf, ok := ret.Get(0).(func([]string, string) map[string]bool); ok {
+		r0 = rf(ratingSubIds, userIdLegacy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]bool)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string, string) error); ok {
+		r1 = rf(ratingSubIds, userIdLegacy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetListRatingBySourceTypeAndUID provides a mock function with given fields: sourceType, sourceUID
 func (_m *PublicRatingMpRepository) GetListRatingBySourceTypeAndUID(sourceType string, sourceUID string) ([]entity.RatingsMpCol, error) {
 	ret := _m.Called(sourceType, sourceUID)
@@ -273,13 +296,13 @@ func (_m *PublicRatingMpRepository) GetSumCountRatingSubsByRatingId(ratingId str
 	return r0, r1
 }
 
-// GetSumCountRatingSubsBySource provides a mock function with given fields: sourceUID, sourceType
-func (_m *PublicRatingMpRepository) GetSumCountRatingSubsBySource(sourceUID string, sourceType string) (*publicresponse.PublicSumCountRatingSummaryMp, error) {
-	ret := _m.Called(sourceUID, sourceType)
+// GetSumCountRatingSubsBySource provides a mock function with given fields: sourceUID, sourceType, verifiedOnly
+func (_m *PublicRatingMpRepository) GetSumCountRatingSubsBySource(sourceUID string, sourceType string, verifiedOnly bool) (*publicresponse.PublicSumCountRatingSummaryMp, error) {
+	ret := _m.Called(sourceUID, sourceType, verifiedOnly)
 
 	var r0 *publicresponse.PublicSumCountRatingSummaryMp
-	if rf, ok := ret.Get(0).(func(string, string) *publicresponse.PublicSumCountRatingSummaryMp); ok {
-		r0 = rf(sourceUID, sourceType)
+	if rf, ok := ret.Get(0).(func(string, string, bool) *publicresponse.PublicSumCountRatingSummaryMp); ok {
+		r0 = rf(sourceUID, sourceType, verifiedOnly)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*publicresponse.PublicSumCountRatingSummaryMp)
@@ -287,8 +310,8 @@ func (_m *PublicRatingMpRepository) GetSumCountRatingSubsBySource(sourceUID stri
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(sourceUID, sourceType)
+	if rf, ok := ret.Get(1).(func(string, string, bool) error); ok {
+		r1 = rf(sourceUID, sourceType, verifiedOnly)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -318,6 +341,52 @@ func (_m *PublicRatingMpRepository) GetRatingSubsGroupByValue(sourceUid string,
 	return r0, r1
 }
 
+// GetPublicRatingSubmissionsGroupByDimension provides a mock function with given fields: filter, uidField
+func (_m *PublicRatingMpRepository) GetPublicRatingSubmissionsGroupByDimension(filter publicrequest.FilterRatingSummary, uidField string) ([]publicresponse.PublicRatingSubGroupByDimensionMp, error) {
+	ret := _m.Called(filter, uidField)
+
+	var r0 []publicresponse.PublicRatingSubGroupByDimensionMp
+	if rf, ok := ret.Get(0).(func(publicrequest.FilterRatingSummary, string) []publicresponse.PublicRatingSubGroupByDimensionMp); ok {
+		r0 = rf(filter, uidField)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]publicresponse.PublicRatingSubGroupByDimensionMp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(publicrequest.FilterRatingSummary, string) error); ok {
+		r1 = rf(filter, uidField)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPublicRatingSubmissionsGroupByTag provides a mock function with given fields: filter, uidField
+func (_m *PublicRatingMpRepository) GetPublicRatingSubmissionsGroupByTag(filter publicrequest.FilterRatingSummary, uidField string) ([]publicresponse.PublicRatingSubGroupByTag, error) {
+	ret := _m.Called(filter, uidField)
+
+	var r0 []publicresponse.PublicRatingSubGroupByTag
+	if rf, ok := ret.Get(0).(func(publicrequest.FilterRatingSummary, string) []publicresponse.PublicRatingSubGroupByTag); ok {
+		r0 = rf(filter, uidField)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]publicresponse.PublicRatingSubGroupByTag)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(publicrequest.FilterRatingSummary, string) error); ok {
+		r1 = rf(filter, uidField)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewPublicRatingMpRepository interface {
 	mock.TestingT
 	Cleanup(func())
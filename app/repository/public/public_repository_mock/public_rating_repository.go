@@ -12,6 +12,8 @@ import (
 
 	publicrequest "go-klikdokter/app/model/request/public"
 
+	publicresponse "go-klikdokter/app/model/response/public"
+
 	request "go-klikdokter/app/model/request"
 )
 
@@ -41,22 +43,22 @@ func (_m *PublicRatingRepository) CountRatingSubsByRatingIdAndValue(ratingId str
 	return r0, r1
 }
 
-// CreateRatingSubHelpful provides a mock function with given fields: input
-func (_m *PublicRatingRepository) CreateRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, error) {
-	ret := _m.Called(input)
+// GetLikedRatingSubIdsByActor provides a mock function with given fields: ratingSubIds, userIdLegacy
+func (_m *PublicRatingRepository) GetLikedRatingSubIdsByActor(ratingSubIds []string, userIdLegacy string) (map[string]bool, error) {
+	ret := _m.Called(ratingSubIds, userIdLegacy)
 
-	var r0 *entity.RatingSubHelpfulCol
-	if rf, ok := ret.Get(0).(func(request.CreateRatingSubHelpfulRequest) *entity.RatingSubHelpfulCol); ok {
-		r0 = rf(input)
+	var r0 map[string]bool
+	if rf, ok := ret.Get(0).(func([]string, string) map[string]bool); ok {
+		r0 = rf(ratingSubIds, userIdLegacy)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.RatingSubHelpfulCol)
+			r0 = ret.Get(0).(map[string]bool)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(request.CreateRatingSubHelpfulRequest) error); ok {
-		r1 = rf(input)
+	if rf, ok := ret.Get(1).(func([]string, string) error); ok {
+		r1 = rf(ratingSubIds, userIdLegacy)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -289,20 +291,6 @@ func (_m *PublicRatingRepository) GetRatingsBySourceTypeAndActor(sourceType stri
 	return r0, r1
 }
 
-// UpdateCounterRatingSubmission provides a mock function with given fields: id, currentCounter
-func (_m *PublicRatingRepository) UpdateCounterRatingSubmission(id primitive.ObjectID, currentCounter int64) error {
-	ret := _m.Called(id, currentCounter)
-
-	var r0 error
-	if rf, ok := ret.Get(0).(func(primitive.ObjectID, int64) error); ok {
-		r0 = rf(id, currentCounter)
-	} else {
-		r0 = ret.Error(0)
-	}
-
-	return r0
-}
-
 // UpdateRatingSubDisplayNameByIdLegacy provides a mock function with given fields: input
 func (_m *PublicRatingRepository) UpdateRatingSubDisplayNameByIdLegacy(input request.UpdateRatingSubDisplayNameRequest) error {
 	ret := _m.Called(input)
@@ -317,18 +305,27 @@ func (_m *PublicRatingRepository) UpdateRatingSubDisplayNameByIdLegacy(input req
 	return r0
 }
 
-// UpdateStatusRatingSubHelpful provides a mock function with given fields: id, currentStatus
-func (_m *PublicRatingRepository) UpdateStatusRatingSubHelpful(id primitive.ObjectID, currentStatus bool) error {
-	ret := _m.Called(id, currentStatus)
+// GetPublicRatingSubmissionsGroupByTag provides a mock function with given fields: ratingIds, verifiedOnly
+func (_m *PublicRatingRepository) GetPublicRatingSubmissionsGroupByTag(ratingIds []string, verifiedOnly bool) ([]publicresponse.PublicRatingSubGroupByTag, error) {
+	ret := _m.Called(ratingIds, verifiedOnly)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(primitive.ObjectID, bool) error); ok {
-		r0 = rf(id, currentStatus)
+	var r0 []publicresponse.PublicRatingSubGroupByTag
+	if rf, ok := ret.Get(0).(func([]string, bool) []publicresponse.PublicRatingSubGroupByTag); ok {
+		r0 = rf(ratingIds, verifiedOnly)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]publicresponse.PublicRatingSubGroupByTag)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string, bool) error); ok {
+		r1 = rf(ratingIds, verifiedOnly)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 type mockConstructorTestingTNewPublicRatingRepository interface {
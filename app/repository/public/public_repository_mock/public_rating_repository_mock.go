@@ -7,6 +7,7 @@ import (
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
 	"go-klikdokter/app/model/request/public"
+	publicresponse "go-klikdokter/app/model/response/public"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -49,17 +50,6 @@ func (repository *PublicRatingRepositoryMock) GetRatingTypeNumById(id primitive.
 	}
 }
 
-func (repository *PublicRatingRepositoryMock) CreateRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, error) {
-	ratingSubHelpfulCol := entity.RatingSubHelpfulCol{}
-	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
-	ratingSubHelpfulCol.ID = objectId
-	return &ratingSubHelpfulCol, nil
-}
-
-func (repository *PublicRatingRepositoryMock) UpdateStatusRatingSubHelpful(id primitive.ObjectID, currentStatus bool) error {
-	return nil
-}
-
 func (repository *PublicRatingRepositoryMock) GetRatingSubHelpfulByRatingSubAndActor(ratingSubId, userIdLegacy string) (*entity.RatingSubHelpfulCol, error) {
 	arguments := repository.Mock.Called(ratingSubId, userIdLegacy)
 	if arguments.Get(0) == nil {
@@ -70,8 +60,9 @@ func (repository *PublicRatingRepositoryMock) GetRatingSubHelpfulByRatingSubAndA
 	}
 }
 
-func (repository *PublicRatingRepositoryMock) UpdateCounterRatingSubmission(id primitive.ObjectID, currentCounter int64) error {
-	return nil
+func (repository *PublicRatingRepositoryMock) GetLikedRatingSubIdsByActor(ratingSubIds []string, userIdLegacy string) (map[string]bool, error) {
+	arguments := repository.Mock.Called(ratingSubIds, userIdLegacy)
+	return arguments.Get(0).(map[string]bool), nil
 }
 
 func (repository *PublicRatingRepositoryMock) GetPublicRatingsByParams(limit, page, dir int, sort string, filter publicrequest.FilterRatingSummary) ([]entity.RatingsCol, *base.Pagination, error) {
@@ -138,3 +129,11 @@ func (repository *PublicRatingRepositoryMock) GetListRatingBySourceTypeAndUID(so
 
 	return arguments.Get(0).([]entity.RatingsCol), nil
 }
+
+func (repository *PublicRatingRepositoryMock) GetPublicRatingSubmissionsGroupByTag(ratingIds []string, verifiedOnly bool) ([]publicresponse.PublicRatingSubGroupByTag, error) {
+	arguments := repository.Mock.Called(ratingIds, verifiedOnly)
+	if arguments.Get(0) == nil {
+		return nil, arguments.Error(1)
+	}
+	return arguments.Get(0).([]publicresponse.PublicRatingSubGroupByTag), arguments.Error(1)
+}
@@ -0,0 +1,32 @@
+package publicrepositorytest
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// runMockMongo runs callback against a mocked deployment, the replies are queued with mt.AddMockResponses
+// and the commands sent by the repository are read back with startedCommands
+func runMockMongo(t *testing.T, name string, callback func(mt *mtest.T)) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	mt.Run(name, callback)
+}
+
+// startedCommands returns the commands named name sent to the mocked deployment, the oldest first
+func startedCommands(mt *mtest.T, name string) []bson.Raw {
+	var commands []bson.Raw
+	for _, evt := range mt.GetAllStartedEvents() {
+		if evt.CommandName == name {
+			commands = append(commands, evt.Command)
+		}
+	}
+	return commands
+}
+
+// cursorResponse is the reply of a find or an aggregate returning docs in a single batch
+func cursorResponse(collectionName string, docs ...bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, "test."+collectionName, mtest.FirstBatch, docs...)
+}
@@ -0,0 +1,230 @@
+package publicrepositorytest
+
+import (
+	"go-klikdokter/app/model/entity"
+	publicrequest "go-klikdokter/app/model/request/public"
+	publicrepository "go-klikdokter/app/repository/public"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type sortField struct {
+	key string
+	dir int64
+}
+
+// sentSort returns the sort of the list of submissions sent to the mocked deployment and the fields computed
+// before sorting, nil when the list was a find
+func sentSort(mt *mtest.T) (bson.Raw, bson.Raw) {
+	if finds := startedCommands(mt, "find"); len(finds) > 0 {
+		return finds[0].Lookup("sort").Document(), nil
+	}
+	var sort, fields bson.Raw
+	stages, _ := startedCommands(mt, "aggregate")[0].Lookup("pipeline").Array().Values()
+	for _, stage := range stages {
+		if value, err := stage.Document().LookupErr("$sort"); err == nil {
+			sort = value.Document()
+		}
+		if value, err := stage.Document().LookupErr("$addFields"); err == nil {
+			fields = value.Document()
+		}
+	}
+	return sort, fields
+}
+
+func sortFields(sort bson.Raw) []sortField {
+	elements, _ := sort.Elements()
+	fields := make([]sortField, 0, len(elements))
+	for _, element := range elements {
+		dir, _ := element.Value().AsInt64OK()
+		fields = append(fields, sortField{key: element.Key(), dir: dir})
+	}
+	return fields
+}
+
+// number returns the numeric value as a float64
+func number(value bson.RawValue) float64 {
+	var f float64
+	_ = value.Unmarshal(&f)
+	return f
+}
+
+// listRatingSubmissionsMp lists the marketplace submissions sorted by sort, searched by q
+func listRatingSubmissionsMp(mt *mtest.T, sort, q string) {
+	collectionName := entity.RatingSubmissionMp{}.CollectionName()
+	mt.AddMockResponses(cursorResponse(collectionName), cursorResponse(collectionName))
+	_, _, err := publicrepository.NewPublicRatingMpRepository(mt.DB).GetPublicRatingSubmissions(10, 1, -1, sort,
+		publicrequest.FilterRatingSubmissionMp{SourceType: "product", SourceUID: "1234", Q: q})
+	assert.Nil(mt, err)
+}
+
+func TestValidateSortRatingSubmission(t *testing.T) {
+	tests := []struct {
+		sort  string
+		valid bool
+	}{
+		{sort: "", valid: true},
+		{sort: publicrequest.SortCreatedAt, valid: true},
+		{sort: publicrequest.SortMostHelpful, valid: true},
+		{sort: publicrequest.SortHighest, valid: true},
+		{sort: publicrequest.SortLowest, valid: true},
+		{sort: publicrequest.SortWithMediaFirst, valid: true},
+		{sort: publicrequest.SortRelevance, valid: true},
+		{sort: "updated_at", valid: false},
+		{sort: "like_counter", valid: false},
+		{sort: "$natural", valid: false},
+		{sort: "HIGHEST", valid: false},
+	}
+	for _, tt := range tests {
+		err := publicrequest.GetPublicListRatingSubmissionRequest{Sort: tt.sort}.ValidateSort()
+		assert.Equal(t, tt.valid, err == nil, "sort %q", tt.sort)
+	}
+}
+
+func TestSortRatingSubmissions(t *testing.T) {
+	byRecency := sortField{key: "created_at", dir: -1}
+	tests := []struct {
+		name       string
+		sort       string
+		q          string
+		want       []sortField
+		wantFields bool
+	}{
+		{name: "created at", sort: publicrequest.SortCreatedAt, want: []sortField{{key: "created_at", dir: -1}}},
+		{name: "most helpful", sort: publicrequest.SortMostHelpful, want: []sortField{{key: "like_counter", dir: -1}, byRecency}},
+		{name: "highest", sort: publicrequest.SortHighest, want: []sortField{{key: "value", dir: -1}, byRecency}},
+		{name: "lowest", sort: publicrequest.SortLowest, want: []sortField{{key: "value", dir: 1}, byRecency}},
+		{name: "with media first", sort: publicrequest.SortWithMediaFirst, want: []sortField{{key: "is_with_media", dir: -1}, byRecency}},
+		{name: "relevance", sort: publicrequest.SortRelevance, want: []sortField{{key: "relevance_score", dir: -1}, byRecency}, wantFields: true},
+		{name: "no sort", want: []sortField{{key: "created_at", dir: -1}}},
+		{name: "not whitelisted", sort: "updated_at", want: []sortField{{key: "created_at", dir: -1}}},
+		{name: "not whitelisted searched", sort: "updated_at", q: "bagus", want: []sortField{{key: "created_at", dir: -1}}},
+	}
+	for _, tt := range tests {
+		runMockMongo(t, tt.name, func(mt *mtest.T) {
+			listRatingSubmissionsMp(mt, tt.sort, tt.q)
+
+			sort, fields := sentSort(mt)
+			assert.Equal(t, tt.want, sortFields(sort))
+			assert.Equal(t, tt.wantFields, fields != nil)
+		})
+	}
+}
+
+func TestSortRatingSubmissionsByID(t *testing.T) {
+	tests := []struct {
+		name   string
+		sort   string
+		source string
+		want   []sortField
+	}{
+		{name: "not whitelisted", sort: "email", want: []sortField{{key: "created_at", dir: -1}}},
+		{name: "operator", sort: "$natural", want: []sortField{{key: "created_at", dir: -1}}},
+		{name: "most helpful", sort: publicrequest.SortMostHelpful, want: []sortField{{key: "like_counter", dir: -1}, {key: "created_at", dir: -1}}},
+		{name: "legacy highest", sort: publicrequest.SortHighest, source: "all", want: []sortField{{key: "value_number", dir: -1}, {key: "created_at", dir: -1}}},
+	}
+	for _, tt := range tests {
+		runMockMongo(t, tt.name, func(mt *mtest.T) {
+			collectionName := entity.RatingSubmissionMp{}.CollectionName()
+			mt.AddMockResponses(cursorResponse(collectionName), cursorResponse(collectionName))
+			_, _, err := publicrepository.NewPublicRatingMpRepository(mt.DB).GetPublicRatingSubmissionsCustom(10, 1, -1, tt.sort,
+				publicrequest.FilterRatingSubmissionMp{RatingSubsID: []string{"629dce7bf1f26275e0d84826"}}, tt.source)
+			assert.Nil(t, err)
+
+			sort, _ := sentSort(mt)
+			assert.Equal(t, tt.want, sortFields(sort))
+		})
+	}
+}
+
+func TestSortRatingSubmissionsSearchedByScore(t *testing.T) {
+	runMockMongo(t, "no sort", func(mt *mtest.T) {
+		listRatingSubmissionsMp(mt, "", "bagus")
+
+		sort, fields := sentSort(mt)
+		assert.Nil(t, fields)
+		assert.Equal(t, "textScore", sort.Lookup("score", "$meta").StringValue())
+	})
+}
+
+func TestSortRatingSubmissionsLegacyValue(t *testing.T) {
+	tests := []struct {
+		sort string
+		dir  int64
+	}{
+		{sort: publicrequest.SortHighest, dir: -1},
+		{sort: publicrequest.SortLowest, dir: 1},
+	}
+	for _, tt := range tests {
+		runMockMongo(t, tt.sort, func(mt *mtest.T) {
+			mt.AddMockResponses(cursorResponse("ratingSubCol"), cursorResponse("ratingSubCol"))
+			_, _, err := publicrepository.NewPublicRatingRepository(mt.DB).GetPublicRatingSubmissions(10, 1, -1, tt.sort,
+				publicrequest.FilterRatingSubmission{RatingID: []string{"1"}})
+			assert.Nil(t, err)
+
+			// the legacy values are strings, they are converted before sorting
+			sort, fields := sentSort(mt)
+			assert.Equal(t, []sortField{{key: "value_number", dir: tt.dir}, {key: "created_at", dir: -1}}, sortFields(sort))
+			assert.Equal(t, "$value", fields.Lookup("value_number", "$convert", "input").StringValue())
+		})
+	}
+}
+
+func TestRelevanceScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		q         string
+		config    map[string]interface{}
+		wantParts int
+		// weight of the likes and comment length counted as full
+		wantLikeWeight    float64
+		wantCommentLength float64
+	}{
+		{name: "defaults", wantParts: 4, wantLikeWeight: 1, wantCommentLength: 200},
+		{name: "searched adds the text score", q: "bagus", wantParts: 5, wantLikeWeight: 1, wantCommentLength: 200},
+		{name: "punctuation only is not searched", q: "?!", wantParts: 4, wantLikeWeight: 1, wantCommentLength: 200},
+		{
+			name:              "configured",
+			config:            map[string]interface{}{"relevance-sort.like-weight": 3, "relevance-sort.comment-full-length": 50},
+			wantParts:         4,
+			wantLikeWeight:    3,
+			wantCommentLength: 50,
+		},
+		{
+			name:              "like weight of 0 is kept, a comment length of 0 is the default",
+			config:            map[string]interface{}{"relevance-sort.like-weight": 0, "relevance-sort.comment-full-length": 0},
+			wantParts:         4,
+			wantLikeWeight:    0,
+			wantCommentLength: 200,
+		},
+	}
+	for _, tt := range tests {
+		for key, value := range tt.config {
+			viper.Set(key, value)
+		}
+		runMockMongo(t, tt.name, func(mt *mtest.T) {
+			listRatingSubmissionsMp(mt, publicrequest.SortRelevance, tt.q)
+
+			_, fields := sentSort(mt)
+			parts, _ := fields.Lookup("relevance_score", "$add").Array().Values()
+			assert.Len(t, parts, tt.wantParts)
+			comment, _ := parts[0].Document().Lookup("$multiply").Array().Values()
+			commentLength := comment[1].Document().Lookup("$min").Array().Index(1).Value().Document().
+				Lookup("$divide").Array().Index(1).Value()
+			assert.Equal(t, tt.wantCommentLength, number(commentLength))
+			likes, _ := parts[2].Document().Lookup("$multiply").Array().Values()
+			assert.Equal(t, tt.wantLikeWeight, number(likes[0]))
+			if tt.wantParts == 5 {
+				text, _ := parts[4].Document().Lookup("$multiply").Array().Values()
+				assert.Equal(t, "textScore", text[1].Document().Lookup("$meta").StringValue())
+			}
+		})
+		for key := range tt.config {
+			viper.Set(key, nil)
+		}
+	}
+}
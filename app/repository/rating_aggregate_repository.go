@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ratingAggregateDelta is the change applied to the aggregate of a rating when its submissions change
+type ratingAggregateDelta struct {
+	sum          float64
+	count        int64
+	commentCount int64
+	histogram    map[string]int64
+	// verified is the part of the change made by the submissions of verified purchases
+	verified *ratingAggregateDelta
+}
+
+// newRatingAggregateDelta returns what a submission adds to (sign 1) or removes from (sign -1) the aggregate of its rating.
+// Cancelled, deleted, pending and rejected submissions are not public and count for nothing.
+func newRatingAggregateDelta(sub entity.RatingSubmisson, sign int64) ratingAggregateDelta {
+	delta := ratingAggregateDelta{histogram: map[string]int64{}}
+	if sub.Cancelled || sub.DeletedAt != nil || (sub.ModerationStatus != "" && sub.ModerationStatus != entity.ModerationStatusApproved) {
+		return delta
+	}
+
+	// a likert submission holds every selected statement, its value is the average of the statements
+	var total float64
+	values := strings.Split(sub.Value, ",")
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		floatValue, _ := strconv.ParseFloat(value, 64)
+		total += floatValue
+		delta.histogram[histogramKey(value)] += sign
+	}
+	delta.sum = float64(sign) * total / float64(len(values))
+	delta.count = sign
+	if sub.Comment != nil && strings.TrimSpace(*sub.Comment) != "" {
+		delta.commentCount = sign
+	}
+	if sub.IsVerifiedPurchase {
+		verified := ratingAggregateDelta{sum: delta.sum, count: delta.count, commentCount: delta.commentCount, histogram: map[string]int64{}}
+		for key, total := range delta.histogram {
+			verified.histogram[key] = total
+		}
+		delta.verified = &verified
+	}
+	return delta
+}
+
+func (d *ratingAggregateDelta) add(other ratingAggregateDelta) {
+	d.sum += other.sum
+	d.count += other.count
+	d.commentCount += other.commentCount
+	for key, total := range other.histogram {
+		d.histogram[key] += total
+	}
+	if other.verified != nil {
+		if d.verified == nil {
+			d.verified = &ratingAggregateDelta{histogram: map[string]int64{}}
+		}
+		d.verified.add(*other.verified)
+	}
+}
+
+func (d ratingAggregateDelta) isEmpty() bool {
+	if d.sum != 0 || d.count != 0 || d.commentCount != 0 {
+		return false
+	}
+	for _, total := range d.histogram {
+		if total != 0 {
+			return false
+		}
+	}
+	return d.verified == nil || d.verified.isEmpty()
+}
+
+// incFields returns the $inc of the delta, prefix is the path of the summary in the aggregate
+func (d ratingAggregateDelta) incFields(prefix string) bson.D {
+	inc := bson.D{
+		{Key: prefix + "sum", Value: d.sum},
+		{Key: prefix + "count", Value: d.count},
+		{Key: prefix + "comment_count", Value: d.commentCount},
+	}
+	for key, total := range d.histogram {
+		if total != 0 {
+			inc = append(inc, bson.E{Key: prefix + "histogram." + key, Value: total})
+		}
+	}
+	return inc
+}
+
+// histogramKey keeps decimal values usable as a field name
+func histogramKey(value string) string {
+	return strings.ReplaceAll(value, ".", "_")
+}
+
+// ratingAggregateDeltas are the changes of the aggregates keyed by rating id
+type ratingAggregateDeltas map[string]ratingAggregateDelta
+
+// add adds the difference between the previous and the current state of a submission.
+// A nil previous means the submission is new, a nil current means it is removed.
+func (d ratingAggregateDeltas) add(previous, current *entity.RatingSubmisson) {
+	if previous != nil && previous.RatingID != "" {
+		d.addDelta(previous.RatingID, newRatingAggregateDelta(*previous, -1))
+	}
+	if current != nil && current.RatingID != "" {
+		d.addDelta(current.RatingID, newRatingAggregateDelta(*current, 1))
+	}
+}
+
+func (d ratingAggregateDeltas) addDelta(ratingId string, delta ratingAggregateDelta) {
+	total, ok := d[ratingId]
+	if !ok {
+		total = ratingAggregateDelta{histogram: map[string]int64{}}
+	}
+	total.add(delta)
+	d[ratingId] = total
+}
+
+// applyRatingAggregateDelta applies the difference between the previous and the current state of a submission.
+// A nil previous means the submission is new, a nil current means it is removed.
+func (r *ratingRepo) applyRatingAggregateDelta(ctx context.Context, previous, current *entity.RatingSubmisson) error {
+	deltas := ratingAggregateDeltas{}
+	deltas.add(previous, current)
+	return r.applyRatingAggregateDeltas(ctx, deltas)
+}
+
+// applyRatingAggregateDeltas applies the changes once per rating. ctx is the session of the transaction
+// saving the submissions, the aggregates are rebuilt from the submissions changed in it.
+func (r *ratingRepo) applyRatingAggregateDeltas(ctx context.Context, deltas ratingAggregateDeltas) error {
+	for ratingId, delta := range deltas {
+		if err := r.incRatingAggregate(ctx, ratingId, delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// incRatingAggregate applies the delta to the aggregate of the rating. A missing aggregate (rating stored before
+// aggregates existed) is rebuilt from the submissions instead, the delta alone would be served as the whole aggregate.
+func (r *ratingRepo) incRatingAggregate(ctx context.Context, ratingId string, delta ratingAggregateDelta) error {
+	if delta.isEmpty() {
+		return nil
+	}
+
+	aggregateColl := r.db.Collection(entity.RatingAggregateCol{}.CollectionName())
+	filter := bson.D{{Key: "rating_id", Value: ratingId}}
+	err := aggregateColl.FindOne(ctx, filter, options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}})).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		objectRatingId, err := primitive.ObjectIDFromHex(ratingId)
+		if err != nil {
+			return err
+		}
+		rating := entity.RatingsCol{ID: objectRatingId}
+		err = r.db.Collection(entity.RatingsCol{}.CollectionName()).FindOne(ctx, bson.M{"_id": objectRatingId}).Decode(&rating)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		_, err = rebuildRatingAggregate(ctx, r.db, rating)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	inc := delta.incFields("")
+	if delta.verified != nil {
+		inc = append(inc, delta.verified.incFields("verified.")...)
+	}
+	data := bson.D{
+		{Key: "$inc", Value: inc},
+		{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now().In(util.Loc)}}},
+	}
+	_, err = aggregateColl.UpdateOne(ctx, filter, data)
+	return err
+}
+
+// GetRatingAggregates returns the aggregates of the ratings keyed by rating id.
+// Ratings without an aggregate yet (stored before aggregates existed) are rebuilt from their submissions.
+func (r *ratingRepo) GetRatingAggregates(ratings []entity.RatingsCol) (map[string]entity.RatingAggregateCol, error) {
+	results := map[string]entity.RatingAggregateCol{}
+	if len(ratings) == 0 {
+		return results, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	ratingIds := make([]string, 0, len(ratings))
+	for _, rating := range ratings {
+		ratingIds = append(ratingIds, rating.ID.Hex())
+	}
+	filter := bson.D{{Key: "rating_id", Value: bson.D{{Key: "$in", Value: ratingIds}}}}
+	cursor, err := r.db.Collection(entity.RatingAggregateCol{}.CollectionName()).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var aggregates []entity.RatingAggregateCol
+	if err = cursor.All(ctx, &aggregates); err != nil {
+		return nil, err
+	}
+	for _, aggregate := range aggregates {
+		results[aggregate.RatingID] = aggregate
+	}
+
+	for _, rating := range ratings {
+		if _, ok := results[rating.ID.Hex()]; ok {
+			continue
+		}
+		aggregate, err := r.RebuildRatingAggregate(rating)
+		if err != nil {
+			return nil, err
+		}
+		results[aggregate.RatingID] = *aggregate
+	}
+	return results, nil
+}
+
+// RebuildRatingAggregate recomputes the aggregate of a rating from its submissions, used to backfill and to repair drift
+func (r *ratingRepo) RebuildRatingAggregate(rating entity.RatingsCol) (*entity.RatingAggregateCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	return rebuildRatingAggregate(ctx, r.db, rating)
+}
+
+// rebuildRatingAggregate replaces the aggregate of the rating by the one computed from its submissions,
+// in a transaction when ctx is a session
+func rebuildRatingAggregate(ctx context.Context, db *mongo.Database, rating entity.RatingsCol) (*entity.RatingAggregateCol, error) {
+	ratingId := rating.ID.Hex()
+	filter := bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "rating_id", Value: ratingId}},
+		bson.D{{Key: "cancelled", Value: false}},
+		bsonModerationApproved,
+	}}}
+	cursor, err := db.Collection("ratingSubCol").Find(ctx, filter, options.Find().SetProjection(bson.D{
+		{Key: "value", Value: 1},
+		{Key: "comment", Value: 1},
+		{Key: "rating_id", Value: 1},
+		{Key: "is_verified_purchase", Value: 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	total := ratingAggregateDelta{histogram: map[string]int64{}}
+	for cursor.Next(ctx) {
+		var sub entity.RatingSubmisson
+		if err = cursor.Decode(&sub); err != nil {
+			return nil, err
+		}
+		total.add(newRatingAggregateDelta(sub, 1))
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	for key, count := range total.histogram {
+		if count == 0 {
+			delete(total.histogram, key)
+		}
+	}
+	verified := entity.RatingAggregateVerified{Histogram: map[string]int64{}}
+	if total.verified != nil {
+		verified.Sum = total.verified.sum
+		verified.Count = total.verified.count
+		verified.CommentCount = total.verified.commentCount
+		for key, count := range total.verified.histogram {
+			if count != 0 {
+				verified.Histogram[key] = count
+			}
+		}
+	}
+	aggregate := entity.RatingAggregateCol{
+		SourceType:   rating.SourceType,
+		SourceUID:    rating.SourceUid,
+		RatingID:     ratingId,
+		Sum:          total.sum,
+		Count:        total.count,
+		Histogram:    total.histogram,
+		CommentCount: total.commentCount,
+		Verified:     verified,
+		UpdatedAt:    time.Now().In(util.Loc),
+	}
+	_, err = db.Collection(entity.RatingAggregateCol{}.CollectionName()).
+		ReplaceOne(ctx, bson.D{{Key: "rating_id", Value: ratingId}}, aggregate, options.Replace().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	return &aggregate, nil
+}
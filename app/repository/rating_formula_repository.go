@@ -0,0 +1,377 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/pkg/util"
+	util_formula "go-klikdokter/pkg/util/formula"
+	"math"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// formulaSumCount is the result of the sum and count queries used by the rating formula variables
+type formulaSumCount struct {
+	Sum   float64 `bson:"sum"`
+	Count float64 `bson:"count"`
+}
+
+// aggregateSumCount runs the pipeline which groups the matched documents into a single sum and count
+func aggregateSumCount(ctx context.Context, collection *mongo.Collection, pipeline bson.A) (float64, float64, error) {
+	var results []formulaSumCount
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return 0, 0, err
+	}
+	if len(results) == 0 {
+		return 0, 0, nil
+	}
+	return results[0].Sum, results[0].Count, nil
+}
+
+// decayedSumCountPipeline weighs every matched submission by 0.5^(age / halfLife), a submission as old as halfLife counts for half
+func decayedSumCountPipeline(match bson.D, halfLife time.Duration) bson.A {
+	decayRate := -math.Ln2 / float64(halfLife.Milliseconds())
+	return bson.A{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "weight", Value: bson.D{{Key: "$exp", Value: bson.D{{Key: "$multiply", Value: bson.A{
+				decayRate,
+				bson.D{{Key: "$subtract", Value: bson.A{"$$NOW", "$created_at"}}},
+			}}}}}},
+			{Key: "numericValue", Value: bson.D{{Key: "$convert", Value: bson.D{
+				{Key: "input", Value: "$value"},
+				{Key: "to", Value: "double"},
+				{Key: "onError", Value: 0},
+				{Key: "onNull", Value: 0},
+			}}}},
+		}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: primitive.Null{}},
+			{Key: "sum", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$multiply", Value: bson.A{"$numericValue", "$weight"}}}}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: "$weight"}}},
+		}}},
+	}
+}
+
+// GetGlobalSumCountBySourceType returns the sum and count of every public submission of the ratings of the source type
+// and of the rating type of the formula, used by global_mean. Every rating type is summed when ratingTypeId is empty
+func (r *ratingRepo) GetGlobalSumCountBySourceType(sourceType, ratingTypeId string) (float64, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	match := bson.D{{Key: "source_type", Value: sourceType}}
+	if ratingTypeId != "" {
+		ids, err := r.db.Collection(entity.RatingsCol{}.CollectionName()).Distinct(ctx, "_id",
+			bson.D{{Key: "source_type", Value: sourceType}, {Key: "rating_type_id", Value: ratingTypeId}})
+		if err != nil {
+			return 0, 0, err
+		}
+		ratingIds := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if objectId, ok := id.(primitive.ObjectID); ok {
+				ratingIds = append(ratingIds, objectId.Hex())
+			}
+		}
+		match = append(match, bson.E{Key: "rating_id", Value: bson.D{{Key: "$in", Value: ratingIds}}})
+	}
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: primitive.Null{}},
+			{Key: "sum", Value: bson.D{{Key: "$sum", Value: "$sum"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: "$count"}}},
+		}}},
+	}
+	sum, count, err := aggregateSumCount(ctx, r.db.Collection(entity.RatingAggregateCol{}.CollectionName()), pipeline)
+	return sum, int64(count), err
+}
+
+// GetDecayedSumCountByRatingId returns the time-decayed sum and count of the public submissions of a rating
+func (r *ratingRepo) GetDecayedSumCountByRatingId(ratingId string, halfLife time.Duration) (float64, float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	match := bson.D{{Key: "$and", Value: bson.A{
+		bson.D{{Key: "rating_id", Value: ratingId}},
+		bson.D{{Key: "cancelled", Value: false}},
+		bsonModerationApproved,
+	}}}
+	return aggregateSumCount(ctx, r.db.Collection(entity.RatingSubmisson{}.CollectionName()), decayedSumCountPipeline(match, halfLife))
+}
+
+// GetGlobalSumCountBySourceType returns the sum and count of every public submission of the source type and of the rating type
+// of the formula, used by global_mean. Every rating type is summed when ratingTypeId is empty
+func (r *ratingMpRepo) GetGlobalSumCountBySourceType(sourceType, ratingTypeId string) (float64, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	match := bson.A{
+		bson.D{{Key: "source_type", Value: sourceType}},
+		bson.D{{Key: "cancelled", Value: false}},
+		bsonModerationApproved,
+	}
+	if ratingTypeId != "" {
+		match = append(match, bson.D{{Key: "rating_type_id", Value: ratingTypeId}})
+	}
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "$and", Value: match}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: primitive.Null{}},
+			{Key: "sum", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$toDouble", Value: "$value"}}}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+	sum, count, err := aggregateSumCount(ctx, r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()), pipeline)
+	return sum, int64(count), err
+}
+
+// GetDecayedSumCountBySource returns the time-decayed sum and count of the public submissions of a source,
+// a store source type matches every submission of the store
+func (r *ratingMpRepo) GetDecayedSumCountBySource(sourceUid, sourceType string, halfLife time.Duration) (float64, float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	bsonSource := bson.D{{Key: "source_uid", Value: sourceUid}, {Key: "source_type", Value: sourceType}}
+	if global.IsStoreSourceType(sourceType) {
+		bsonSource = bson.D{{Key: "store_uid", Value: sourceUid}}
+	}
+	match := bson.D{{Key: "$and", Value: bson.A{
+		bsonSource,
+		bson.D{{Key: "cancelled", Value: false}},
+		bsonModerationApproved,
+	}}}
+	return aggregateSumCount(ctx, r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()), decayedSumCountPipeline(match, halfLife))
+}
+
+// NewRatingMpFormulaLoader loads the rating formula variables of a marketplace source with the formula of the rating type,
+// the global mean of a store is the one of every source type sold by the stores, whatever their rating type
+func NewRatingMpFormulaLoader(r RatingMpRepository, sourceUid, sourceType, ratingTypeId string) util_formula.Loader {
+	globalSourceTypes := global.GetSourceTypesOfStore(sourceType)
+	if len(globalSourceTypes) > 0 {
+		ratingTypeId = ""
+	} else {
+		globalSourceTypes = []string{sourceType}
+	}
+	return util_formula.Loader{
+		Histogram: func() (map[string]int64, error) {
+			groups, err := r.GetRatingSubsGroupByValue(sourceUid, sourceType)
+			if err != nil {
+				return nil, err
+			}
+			histogram := make(map[string]int64, len(groups))
+			for _, group := range groups {
+				histogram[strconv.Itoa(group.ConvertedValue)] = int64(group.Total)
+			}
+			return histogram, nil
+		},
+		Global: func() (float64, int64, error) {
+			var sum float64
+			var count int64
+			for _, globalSourceType := range globalSourceTypes {
+				sourceSum, sourceCount, err := r.GetGlobalSumCountBySourceType(globalSourceType, ratingTypeId)
+				if err != nil {
+					return 0, 0, err
+				}
+				sum += sourceSum
+				count += sourceCount
+			}
+			return sum, count, nil
+		},
+		Decayed: func(halfLife time.Duration) (float64, float64, error) {
+			return r.GetDecayedSumCountBySource(sourceUid, sourceType, halfLife)
+		},
+	}
+}
+
+// insertRatingFormulaVersion snapshots the formula as its next version
+func (r *ratingRepo) insertRatingFormulaVersion(ctx context.Context, formula entity.RatingFormulaCol, action, note, actor string, activate bool) (*entity.RatingFormulaVersionCol, error) {
+	formulaId := formula.ID.Hex()
+	var last entity.RatingFormulaVersionCol
+	err := r.db.Collection(entity.RatingFormulaVersionCol{}.CollectionName()).
+		FindOne(ctx, bson.D{{Key: "formula_id", Value: formulaId}}, options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})).
+		Decode(&last)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	timeNow := time.Now().In(util.Loc)
+	version := entity.RatingFormulaVersionCol{
+		FormulaId:    formulaId,
+		Version:      last.Version + 1,
+		SourceType:   formula.SourceType,
+		Formula:      formula.Formula,
+		RatingTypeId: formula.RatingTypeId,
+		RatingType:   formula.RatingType,
+		Note:         note,
+		Action:       action,
+		CreatedBy:    actor,
+		CreatedAt:    timeNow,
+	}
+	if activate {
+		version.Activations = []entity.RatingFormulaActivity{{ActivatedBy: actor, ActivatedAt: timeNow}}
+	}
+	result, err := r.db.Collection(entity.RatingFormulaVersionCol{}.CollectionName()).InsertOne(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	version.ID = result.InsertedID.(primitive.ObjectID)
+	return &version, nil
+}
+
+// ensureRatingFormulaBaseline keeps a formula stored before versioning as its version 1
+func (r *ratingRepo) ensureRatingFormulaBaseline(ctx context.Context, formula entity.RatingFormulaCol) error {
+	if formula.Version != 0 {
+		return nil
+	}
+	baseline, err := r.insertRatingFormulaVersion(ctx, formula, entity.RatingFormulaVersionCreated, "", "", false)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Collection(entity.RatingFormulaCol{}.CollectionName()).UpdateOne(ctx, bson.D{{Key: "_id", Value: formula.ID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "version", Value: baseline.Version}}}})
+	return err
+}
+
+// versionUpdatedRatingFormula records the updated formula as its active version
+func (r *ratingRepo) versionUpdatedRatingFormula(ctx context.Context, previous entity.RatingFormulaCol, actor string) error {
+	if err := r.ensureRatingFormulaBaseline(ctx, previous); err != nil {
+		return err
+	}
+
+	collection := r.db.Collection(entity.RatingFormulaCol{}.CollectionName())
+	var current entity.RatingFormulaCol
+	if err := collection.FindOne(ctx, bson.D{{Key: "_id", Value: previous.ID}}).Decode(&current); err != nil {
+		return err
+	}
+	version, err := r.insertRatingFormulaVersion(ctx, current, entity.RatingFormulaVersionUpdated, "", actor, true)
+	if err != nil {
+		return err
+	}
+	_, err = collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: previous.ID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "version", Value: version.Version}}}})
+	return err
+}
+
+// CreateRatingFormulaVersion stores a draft version of the formula, it is only used once activated
+func (r *ratingRepo) CreateRatingFormulaVersion(formula entity.RatingFormulaCol, note, actor string) (*entity.RatingFormulaVersionCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var version *entity.RatingFormulaVersionCol
+	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
+		err := sessionContext.StartTransaction()
+		if err != nil {
+			return err
+		}
+		var current entity.RatingFormulaCol
+		err = r.db.Collection(entity.RatingFormulaCol{}.CollectionName()).FindOne(sessionContext, bson.D{{Key: "_id", Value: formula.ID}, notDeleted}).Decode(&current)
+		if err == nil {
+			err = r.ensureRatingFormulaBaseline(sessionContext, current)
+		}
+		if err == nil {
+			version, err = r.insertRatingFormulaVersion(sessionContext, formula, entity.RatingFormulaVersionDrafted, note, actor, false)
+		}
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		return sessionContext.CommitTransaction(sessionContext)
+	})
+	if errTransaction != nil {
+		return nil, errTransaction
+	}
+	return version, nil
+}
+
+// GetRatingFormulaVersions returns the versions of a formula, latest first
+func (r *ratingRepo) GetRatingFormulaVersions(formulaId string, page int, limit int64) ([]entity.RatingFormulaVersionCol, *base.Pagination, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	results := []entity.RatingFormulaVersionCol{}
+	collectionName := entity.RatingFormulaVersionCol{}.CollectionName()
+	filter := bson.D{{Key: "formula_id", Value: formulaId}}
+	skip := int64(page)*limit - limit
+	cursor, err := r.db.Collection(collectionName).Find(ctx, filter, &options.FindOptions{
+		Sort:  bson.D{{Key: "version", Value: -1}},
+		Limit: &limit,
+		Skip:  &skip,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, nil, err
+	}
+	pagination := getPagination(r, page, limit, results, collectionName, filter)
+	return results, pagination, nil
+}
+
+func (r *ratingRepo) GetRatingFormulaVersion(formulaId string, version int) (*entity.RatingFormulaVersionCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var result entity.RatingFormulaVersionCol
+	filter := bson.D{{Key: "formula_id", Value: formulaId}, {Key: "version", Value: version}}
+	err := r.db.Collection(entity.RatingFormulaVersionCol{}.CollectionName()).FindOne(ctx, filter).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ActivateRatingFormulaVersion makes the version the formula in use, activating an older version rolls the formula back
+func (r *ratingRepo) ActivateRatingFormulaVersion(id primitive.ObjectID, version int, actor string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	return r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
+		err := sessionContext.StartTransaction()
+		if err != nil {
+			return err
+		}
+		versionCollection := r.db.Collection(entity.RatingFormulaVersionCol{}.CollectionName())
+		versionFilter := bson.D{{Key: "formula_id", Value: id.Hex()}, {Key: "version", Value: version}}
+		var formulaVersion entity.RatingFormulaVersionCol
+		if err = versionCollection.FindOne(sessionContext, versionFilter).Decode(&formulaVersion); err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+
+		timeNow := time.Now().In(util.Loc)
+		result, err := r.db.Collection(entity.RatingFormulaCol{}.CollectionName()).UpdateOne(sessionContext, bson.D{{Key: "_id", Value: id}, notDeleted},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "source_type", Value: formulaVersion.SourceType},
+				{Key: "formula", Value: formulaVersion.Formula},
+				{Key: "rating_type_id", Value: formulaVersion.RatingTypeId},
+				{Key: "rating_type", Value: formulaVersion.RatingType},
+				{Key: "version", Value: formulaVersion.Version},
+				{Key: "updated_at", Value: timeNow},
+			}}})
+		if err == nil && result.MatchedCount == 0 {
+			err = mongo.ErrNoDocuments
+		}
+		if err == nil {
+			_, err = versionCollection.UpdateOne(sessionContext, versionFilter, bson.D{{Key: "$push", Value: bson.D{
+				{Key: "activations", Value: entity.RatingFormulaActivity{ActivatedBy: actor, ActivatedAt: timeNow}},
+			}}})
+		}
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		return sessionContext.CommitTransaction(sessionContext)
+	})
+}
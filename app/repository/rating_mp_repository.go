@@ -8,12 +8,13 @@ import (
 	"go-klikdokter/app/model/request"
 	publicrequest "go-klikdokter/app/model/request/public"
 	publicresponse "go-klikdokter/app/model/response/public"
+	"go-klikdokter/helper/global"
 	"go-klikdokter/pkg/util"
+	util_search "go-klikdokter/pkg/util/search"
 	"math"
 	"reflect"
 	"time"
 
-	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -26,13 +27,13 @@ type ratingMpRepo struct {
 
 type RatingMpRepository interface {
 	// Rating submission
-	CreateRatingSubmission(input []entity.RatingSubmissionMp) (*[]entity.RatingSubmissionMp, error)
+	CreateRatingSubmission(input []entity.RatingSubmissionMp, outbox []entity.OutboxCol) (*[]entity.RatingSubmissionMp, error)
 	UpdateRatingSubmission(input entity.RatingSubmissionMp, id primitive.ObjectID) error
 	GetRatingSubmissionById(id primitive.ObjectID) (*entity.RatingSubmissionMp, error)
 	GetRatingSubmissionByIdAndUser(id primitive.ObjectID, userIDLegacy string) (*entity.RatingSubmissionMp, error)
 	GetListRatingSubmissions(filter request.RatingSubmissionMpFilter, page int, limit int64, sort string, dir interface{}) ([]entity.RatingSubmissionMp, *base.Pagination, error)
 	FindRatingSubmissionByUserIDAndRatingID(userId *string, ratingId string, sourceTransId string) (*entity.RatingSubmissionMp, error)
-	FindRatingSubmissionBySourceTransID(sourceTransId string) (*entity.RatingSubmissionMp, error)
+	FindRatingSubmissionByOrderLine(orderNumber, sourceType, sourceUid, userId string) (*entity.RatingSubmissionMp, error)
 	GetPublicRatingsByParams(limit, page, dir int, sort string, filter publicrequest.FilterRatingSummary) ([]entity.RatingsMpCol, *base.Pagination, error)
 	GetSumCountRatingSubsByRatingId(ratingId string) (*publicresponse.PublicSumCountRatingSummaryMp, error)
 	CountRatingSubsByRatingIdAndValue(ratingId, value string) (int64, error)
@@ -55,6 +56,37 @@ type RatingMpRepository interface {
 	FindRatingTypeNumByRatingTypeID(ratingTypeID primitive.ObjectID) (*entity.RatingTypesNumCol, error)
 	GetRatingSubsGroupByValue(sourceUid string, sourceType string) ([]publicresponse.PublicRatingSubGroupByValue, error)
 	GetRatingFormulaBySourceType(sourceType string) (*entity.RatingFormulaCol, error)
+
+	// rating formula variables
+	GetGlobalSumCountBySourceType(sourceType, ratingTypeId string) (float64, int64, error)
+	GetDecayedSumCountBySource(sourceUid, sourceType string, halfLife time.Duration) (float64, float64, error)
+
+	// final rating
+	GetFinalRatingBySource(sourceType, sourceUid string) (*entity.FinalRatingCol, error)
+	SaveFinalRating(finalRating entity.FinalRatingCol, event entity.OutboxCol) error
+	GetFinalRatingSourceUids(sourceType string) ([]string, error)
+	ScheduleFinalRating(sourceType, sourceUid string, due time.Time) error
+
+	// order and user events
+	GetRatingSubmissionsByOrderNumber(orderNumber string, sourceUids []string) ([]entity.RatingSubmissionMp, error)
+	GetRatingSubmissionsByIds(ids []primitive.ObjectID) ([]entity.RatingSubmissionMp, error)
+	CancelRatingSubmissionByIds(ids []primitive.ObjectID, reason string) error
+	UpdateRatingSubmissionUserProfile(userId string, displayName, avatar *string) error
+	CreateReviewInvitations(invitations []entity.ReviewInvitationCol) (int, error)
+	CancelReviewInvitationsByOrderNumber(orderNumber string, sourceUids []string) error
+	GetReviewInvitationsByOrderNumber(orderNumber, userId string) ([]entity.ReviewInvitationCol, error)
+	GetPendingReviewInvitations(userId string, page int, limit int64) ([]entity.ReviewInvitationCol, *base.Pagination, error)
+	GetReviewInvitationById(id primitive.ObjectID) (*entity.ReviewInvitationCol, error)
+	GetReviewInvitationByOrderLine(orderNumber, userId, sourceType, sourceUid string) (*entity.ReviewInvitationCol, error)
+	GetSubmissionTags(sourceType string) ([]entity.SubmissionTagCol, error)
+	ToggleRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, *entity.RatingSubHelpfulCounter, error)
+	ReportRatingSubmission(report entity.RatingSubReportCol, threshold int) (bool, error)
+	GetRatingSubReportGroups(page int, limit int64) ([]entity.RatingSubReportGroup, *base.Pagination, error)
+	CountRatingSubmissionsSince(field, value string, since time.Time) (int64, error)
+	EditRatingSubmission(input entity.RatingSubmissionMp, id primitive.ObjectID, outbox []entity.OutboxCol) error
+	GetRatingSubRevisions(ratingSubmissionId string) ([]entity.RatingSubRevisionCol, error)
+	SoftDeleteRatingSubmission(id primitive.ObjectID, deletedBy string) (*entity.RatingSubmissionMp, error)
+	RestoreRatingSubmission(id primitive.ObjectID) (*entity.RatingSubmissionMp, error)
 }
 
 func NewRatingMpRepository(db *mongo.Database) RatingMpRepository {
@@ -89,7 +121,9 @@ func (r *ratingMpRepo) FindRatingBySourceUIDAndRatingType(sourceUID, ratingType
 	return &rating, nil
 }
 
-func (r *ratingMpRepo) CreateRatingSubmission(input []entity.RatingSubmissionMp) (*[]entity.RatingSubmissionMp, error) {
+// CreateRatingSubmission saves the submissions and their outbox messages in one transaction,
+// the outbox messages refer to the first submission
+func (r *ratingMpRepo) CreateRatingSubmission(input []entity.RatingSubmissionMp, outbox []entity.OutboxCol) (*[]entity.RatingSubmissionMp, error) {
 	ratingSubmissionColl := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName())
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
 	var ratingSubmission []entity.RatingSubmissionMp
@@ -98,6 +132,9 @@ func (r *ratingMpRepo) CreateRatingSubmission(input []entity.RatingSubmissionMp)
 		dateNow := time.Now().In(util.Loc)
 		args.CreatedAt = dateNow
 		args.UpdatedAt = dateNow
+		if args.Comment != nil {
+			args.CommentSearch = util_search.Normalize(*args.Comment)
+		}
 		docs = append(docs, args)
 	}
 	if len(docs) < 1 {
@@ -110,12 +147,24 @@ func (r *ratingMpRepo) CreateRatingSubmission(input []entity.RatingSubmissionMp)
 		if err != nil {
 			return err
 		}
-		result, err := ratingSubmissionColl.InsertMany(ctx, docs)
+		result, err := ratingSubmissionColl.InsertMany(sessionContext, docs)
 
 		if err != nil {
 			sessionContext.AbortTransaction(sessionContext)
 			return err
 		}
+		err = insertOutboxMessages(sessionContext, r.db, outbox, result.InsertedIDs[0].(primitive.ObjectID).Hex())
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		if invitationId := input[0].InvitationID; invitationId != nil {
+			err = redeemReviewInvitation(sessionContext, r.db, *invitationId, result.InsertedIDs[0].(primitive.ObjectID).Hex())
+			if err != nil {
+				sessionContext.AbortTransaction(sessionContext)
+				return err
+			}
+		}
 		if err = sessionContext.CommitTransaction(sessionContext); err != nil {
 			return err
 		}
@@ -136,7 +185,10 @@ func (r *ratingMpRepo) CreateRatingSubmission(input []entity.RatingSubmissionMp)
 func (r *ratingMpRepo) UpdateRatingSubmission(input entity.RatingSubmissionMp, id primitive.ObjectID) error {
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
 
-	filter := bson.D{{"_id", id}}
+	if input.Comment != nil {
+		input.CommentSearch = util_search.Normalize(*input.Comment)
+	}
+	filter := bson.D{{"_id", id}, notDeleted}
 	data := bson.D{{"$set", input}}
 
 	// transaction
@@ -165,7 +217,7 @@ func (r *ratingMpRepo) GetRatingSubmissionById(id primitive.ObjectID) (*entity.R
 	var ratingSubmission entity.RatingSubmissionMp
 	ratingSubmissionColl := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName())
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"_id": id}).Decode(&ratingSubmission)
+	err := ratingSubmissionColl.FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&ratingSubmission)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +228,7 @@ func (r *ratingMpRepo) GetRatingSubmissionByIdAndUser(id primitive.ObjectID, use
 	var ratingSubmission entity.RatingSubmissionMp
 	ratingSubmissionColl := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName())
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"_id": id, "user_id_legacy": &userIDLegacy}).Decode(&ratingSubmission)
+	err := ratingSubmissionColl.FindOne(ctx, bson.M{"_id": id, "user_id_legacy": &userIDLegacy, "deleted_at": nil}).Decode(&ratingSubmission)
 	if err != nil {
 		return nil, err
 	}
@@ -195,6 +247,7 @@ func (r *ratingMpRepo) GetListRatingSubmissions(filter request.RatingSubmissionM
 	bsonDate := bson.D{}
 	bsonTransId := bson.D{}
 	bsonIsWithMedia := bson.D{}
+	bsonModeration := bson.D{}
 	if len(filter.UserIDLegacy) > 0 {
 		bsonUserUid = bson.D{{Key: "user_id_legacy", Value: bson.D{{Key: "$in", Value: filter.UserIDLegacy}}}}
 	}
@@ -211,7 +264,13 @@ func (r *ratingMpRepo) GetListRatingSubmissions(filter request.RatingSubmissionM
 		bsonIsWithMedia = bson.D{{Key: "is_with_media", Value: filter.IsWithMedia}}
 	}
 
-	arrSourceType := viper.GetStringSlice("source-type-mp")
+	if filter.ModerationStatus == entity.ModerationStatusApproved {
+		bsonModeration = bsonModerationApproved
+	} else if filter.ModerationStatus != "" {
+		bsonModeration = bson.D{{Key: "moderation_status", Value: filter.ModerationStatus}}
+	}
+
+	arrSourceType := global.GetMarketplaceSourceTypeNames()
 	bsonSourceType := bson.D{{Key: "source_type", Value: bson.D{{Key: "$in", Value: arrSourceType}}}}
 
 	filter1 := bson.D{{Key: "$and",
@@ -238,7 +297,10 @@ func (r *ratingMpRepo) GetListRatingSubmissions(filter request.RatingSubmissionM
 					bsonIsWithMedia,
 				},
 			}},
+			bsonModeration,
+			bsonNotDeleted,
 			bsonSourceType,
+			util_search.Filter(filter.Q),
 		},
 	},
 	}
@@ -247,7 +309,7 @@ func (r *ratingMpRepo) GetListRatingSubmissions(filter request.RatingSubmissionM
 	cursor, err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).
 		Find(context.Background(), filter1,
 			newMongoPaginate(limitPage, page).getPaginatedOpts().
-				SetSort(bson.D{{Key: sort, Value: dir}}))
+				SetSort(util_search.Sort(filter.Q, sort, dir)))
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return results, nil, nil
@@ -267,7 +329,7 @@ func (r *ratingMpRepo) FindRatingSubmissionByUserIDAndRatingID(userId *string, r
 	var ratingSubmission entity.RatingSubmissionMp
 	ratingSubmissionColl := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName())
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"user_id": &userId, "rating_id": ratingId, "source_trans_id": sourceTransId}).Decode(&ratingSubmission)
+	err := ratingSubmissionColl.FindOne(ctx, bson.M{"user_id": &userId, "rating_id": ratingId, "source_trans_id": sourceTransId, "deleted_at": nil}).Decode(&ratingSubmission)
 	if err != nil {
 
 		return nil, err
@@ -275,13 +337,21 @@ func (r *ratingMpRepo) FindRatingSubmissionByUserIDAndRatingID(userId *string, r
 	return &ratingSubmission, nil
 }
 
-func (r *ratingMpRepo) FindRatingSubmissionBySourceTransID(sourceTransId string) (*entity.RatingSubmissionMp, error) {
+// FindRatingSubmissionByOrderLine returns the submission of the user on a line (source type and uid) of the order
+func (r *ratingMpRepo) FindRatingSubmissionByOrderLine(orderNumber, sourceType, sourceUid, userId string) (*entity.RatingSubmissionMp, error) {
 	var ratingSubmission entity.RatingSubmissionMp
 	ratingSubmissionColl := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName())
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"source_trans_id": sourceTransId}).Decode(&ratingSubmission)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	filter := bson.D{
+		{Key: "order_number", Value: orderNumber},
+		{Key: "source_type", Value: sourceType},
+		{Key: "source_uid", Value: sourceUid},
+		{Key: "user_id", Value: userId},
+		{Key: "deleted_at", Value: nil},
+	}
+	err := ratingSubmissionColl.FindOne(ctx, filter).Decode(&ratingSubmission)
 	if err != nil {
-
 		return nil, err
 	}
 	return &ratingSubmission, nil
@@ -345,6 +415,8 @@ func (r *ratingMpRepo) GetSumCountRatingSubsByRatingId(ratingId string) (*public
 	bsonRatingIdAndCancelled := bson.D{
 		{Key: "rating_id", Value: ratingId},
 		{Key: "cancelled", Value: false},
+		moderationApproved,
+		notDeleted,
 	}
 
 	pipeline := bson.A{
@@ -393,6 +465,7 @@ func (r *ratingMpRepo) CountRatingSubsByRatingIdAndValue(ratingId, value string)
 		Value: bson.A{
 			bsonRatingId,
 			bsonValue,
+			bsonModerationApproved,
 		}},
 	}
 	counter, err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).CountDocuments(context.Background(), bsonFilter, &options.CountOptions{})
@@ -456,7 +529,7 @@ func (r *ratingMpRepo) GetRatingSubmissionByRatingId(id string) (*entity.RatingS
 	var ratingSubmission entity.RatingSubmissionMp
 	ratingSubmissionColl := r.db.Collection(ratingSubmission.CollectionName())
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"rating_id": id}).Decode(&ratingSubmission)
+	err := ratingSubmissionColl.FindOne(ctx, bson.M{"rating_id": id, "deleted_at": nil}).Decode(&ratingSubmission)
 	if err != nil {
 		return nil, err
 	}
@@ -585,7 +658,6 @@ func (r *ratingMpRepo) GetRatingFormulaByRatingTypeIdAndSourceType(ratingTypeId,
 
 	bsonRatingTypeId := bson.D{{Key: "rating_type_id", Value: ratingTypeId}}
 	bsonSourceType := bson.D{{Key: "source_type", Value: sourceType}}
-	bsonStatus := bson.D{{Key: "status", Value: true}}
 
 	bsonFilter := bson.D{{Key: "$and",
 		Value: bson.A{
@@ -599,7 +671,7 @@ func (r *ratingMpRepo) GetRatingFormulaByRatingTypeIdAndSourceType(ratingTypeId,
 	err := r.db.Collection(entity.RatingFormulaCol{}.CollectionName()).FindOne(ctx, bsonFilter).Decode(&ratingFormula)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil
+			return global.GetDefaultRatingFormula(sourceType), nil
 		}
 		return nil, err
 	}
@@ -643,7 +715,7 @@ func (r *ratingMpRepo) GetRatingTypeNumByIdAndStatus(id primitive.ObjectID) (*en
 func (r *ratingMpRepo) FindRatingTypeNumByRatingType(ratingType string) (*entity.RatingTypesNumCol, error) {
 	var ratingTypeNum entity.RatingTypesNumCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	bsonFilter := bson.D{{"type", ratingType}}
+	bsonFilter := bson.D{{"type", ratingType}, notDeleted}
 	err := r.db.Collection(ratingTypeNum.CollectionName()).FindOne(ctx, bsonFilter).Decode(&ratingTypeNum)
 	if err != nil {
 
@@ -656,7 +728,7 @@ func (r *ratingMpRepo) FindRatingTypeNumByRatingTypeID(ratingTypeId primitive.Ob
 	var ratingTypeNum entity.RatingTypesNumCol
 	ratingTypeNumColl := r.db.Collection(ratingTypeNum.CollectionName())
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingTypeNumColl.FindOne(ctx, bson.M{"_id": ratingTypeId}).Decode(&ratingTypeNum)
+	err := ratingTypeNumColl.FindOne(ctx, bson.M{"_id": ratingTypeId, "deleted_at": nil}).Decode(&ratingTypeNum)
 	if err != nil {
 		return nil, err
 	}
@@ -686,7 +758,7 @@ func (r *ratingMpRepo) GetRatingSubsGroupByValue(sourceUid string, sourceType st
 
 	bsonSourceUID = bson.D{{Key: "source_uid", Value: sourceUid}}
 	bsonSourceType = bson.D{{Key: "source_type", Value: sourceType}}
-	if sourceType == "store" {
+	if global.IsStoreSourceType(sourceType) {
 		bsonSourceUID = bson.D{{Key: "store_uid", Value: sourceUid}}
 	}
 	
@@ -695,6 +767,7 @@ func (r *ratingMpRepo) GetRatingSubsGroupByValue(sourceUid string, sourceType st
 			bsonSourceUID,
 			bsonSourceType,
 			bsonCancelled,
+			bsonModerationApproved,
 		},
 	}}
 
@@ -742,7 +815,6 @@ func (r *ratingMpRepo) GetRatingFormulaBySourceType(sourceType string) (*entity.
 	var ratingFormula entity.RatingFormulaCol
 
 	bsonSourceType := bson.D{{Key: "source_type", Value: sourceType}}
-	bsonStatus := bson.D{{Key: "status", Value: true}}
 
 	bsonFilter := bson.D{{Key: "$and",
 		Value: bson.A{
@@ -755,9 +827,94 @@ func (r *ratingMpRepo) GetRatingFormulaBySourceType(sourceType string) (*entity.
 	err := r.db.Collection(entity.RatingFormulaCol{}.CollectionName()).FindOne(ctx, bsonFilter).Decode(&ratingFormula)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil
+			return global.GetDefaultRatingFormula(sourceType), nil
 		}
 		return nil, err
 	}
 	return &ratingFormula, nil
-}
\ No newline at end of file
+}
+// GetRatingSubmissionsByOrderNumber returns the submissions of the order not cancelled yet, of every source when sourceUids is empty
+func (r *ratingMpRepo) GetRatingSubmissionsByOrderNumber(orderNumber string, sourceUids []string) ([]entity.RatingSubmissionMp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	results := []entity.RatingSubmissionMp{}
+	filter := bson.D{
+		{Key: "order_number", Value: orderNumber},
+		{Key: "cancelled", Value: false},
+		notDeleted,
+	}
+	if len(sourceUids) > 0 {
+		filter = append(filter, bson.E{Key: "source_uid", Value: bson.D{{Key: "$in", Value: sourceUids}}})
+	}
+	cursor, err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetRatingSubmissionsByIds returns the submissions of the ids not cancelled yet
+func (r *ratingMpRepo) GetRatingSubmissionsByIds(ids []primitive.ObjectID) ([]entity.RatingSubmissionMp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	results := []entity.RatingSubmissionMp{}
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		{Key: "cancelled", Value: false},
+		notDeleted,
+	}
+	cursor, err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *ratingMpRepo) CancelRatingSubmissionByIds(ids []primitive.ObjectID, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}, notDeleted}
+	data := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "cancelled", Value: true},
+		{Key: "cancelled_reason", Value: reason},
+		{Key: "updated_at", Value: time.Now().In(util.Loc)},
+	}}}
+	_, err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).UpdateMany(ctx, filter, data)
+	return err
+}
+
+// UpdateRatingSubmissionUserProfile sets the display name and/or avatar of every submission of the user
+func (r *ratingMpRepo) UpdateRatingSubmissionUserProfile(userId string, displayName, avatar *string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	return updateRatingSubmissionUserProfile(ctx, r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()), userId, displayName, avatar)
+}
+
+func updateRatingSubmissionUserProfile(ctx context.Context, collection *mongo.Collection, userId string, displayName, avatar *string) error {
+	set := bson.D{}
+	if displayName != nil {
+		set = append(set, bson.E{Key: "display_name", Value: *displayName})
+	}
+	if avatar != nil {
+		set = append(set, bson.E{Key: "avatar", Value: *avatar})
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "user_id_legacy", Value: userId}},
+		bson.D{{Key: "user_id", Value: userId}},
+	}}, notDeleted}
+	_, err := collection.UpdateMany(ctx, filter, bson.D{{Key: "$set", Value: set}})
+	return err
+}
@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"go-klikdokter/app/model/base"
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
 	"go-klikdokter/pkg/util"
+	util_search "go-klikdokter/pkg/util/search"
 	"math"
 	"reflect"
 	"time"
@@ -23,22 +25,47 @@ type ratingRepo struct {
 	db *mongo.Database
 }
 
-var bsonStatus = bson.D{{"status", true}}
+// notDeleted keeps soft deleted rows out of every query, they stay until the purge job hard deletes them
+var notDeleted = bson.E{Key: "deleted_at", Value: nil}
+var bsonNotDeleted = bson.D{notDeleted}
+
+var bsonStatus = bson.D{{"status", true}, notDeleted}
+
+// moderationApproved keeps pending and rejected submissions out of the final rating,
+// submissions stored before moderation have no moderation_status and are treated as approved
+var moderationApproved = bson.E{Key: "moderation_status", Value: bson.D{{Key: "$nin", Value: bson.A{entity.ModerationStatusPending, entity.ModerationStatusRejected}}}}
+var bsonModerationApproved = bson.D{moderationApproved, notDeleted}
 
 type RatingRepository interface {
 	// Rating type num
 	CreateRatingTypeNum(input request.CreateRatingTypeNumRequest) (*entity.RatingTypesNumCol, error)
 	UpdateRatingTypeNum(id primitive.ObjectID, input request.EditRatingTypeNumRequest) error
 	GetRatingTypeNumById(id primitive.ObjectID) (*entity.RatingTypesNumCol, error)
-	DeleteRatingTypeNum(id primitive.ObjectID) error
+	DeleteRatingTypeNum(id primitive.ObjectID, deletedBy string) error
+	RestoreRatingTypeNum(id primitive.ObjectID) error
 	GetRatingTypeNums(filter request.Filter, page int, limit int64, sort string, dir interface{}) ([]entity.RatingTypesNumCol, *base.Pagination, error)
 
 	// Rating submission
-	CreateRatingSubmission(input []request.SaveRatingSubmission) (*[]entity.RatingSubmisson, error)
+	CreateRatingSubmission(input []request.SaveRatingSubmission, outbox []entity.OutboxCol) (*[]entity.RatingSubmisson, error)
 	UpdateRatingSubmission(input request.UpdateRatingSubmissionRequest, id primitive.ObjectID) error
-	DeleteSubmission(id primitive.ObjectID) error
+	DeleteSubmission(id primitive.ObjectID, deletedBy string) error
+	RestoreSubmission(id primitive.ObjectID) error
 	GetRatingSubmissionById(id primitive.ObjectID) (*entity.RatingSubmisson, error)
 	CancelRatingSubmissionByIds(ids []primitive.ObjectID, reason string) error
+	GetRatingSubmissionIdsByOrderNumber(orderNumber string) ([]primitive.ObjectID, error)
+	UpdateRatingSubmissionUserProfile(userId string, displayName, avatar *string) error
+	GetReviewInvitationById(id primitive.ObjectID) (*entity.ReviewInvitationCol, error)
+	GetSubmissionTags(sourceType string) ([]entity.SubmissionTagCol, error)
+	GetSubmissionTagById(id primitive.ObjectID) (*entity.SubmissionTagCol, error)
+	CreateSubmissionTag(tag entity.SubmissionTagCol) (*entity.SubmissionTagCol, error)
+	UpdateSubmissionTag(id primitive.ObjectID, tag entity.SubmissionTagCol) (*entity.SubmissionTagCol, error)
+	DeleteSubmissionTag(id primitive.ObjectID) error
+	ToggleRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, *entity.RatingSubHelpfulCounter, error)
+	ReportRatingSubmission(report entity.RatingSubReportCol, threshold int) (bool, error)
+	GetRatingSubReportGroups(page int, limit int64) ([]entity.RatingSubReportGroup, *base.Pagination, error)
+	CountRatingSubmissionsSince(field, value string, since time.Time) (int64, error)
+	GetRatingSubRevisions(ratingSubmissionId string) ([]entity.RatingSubRevisionCol, error)
+	UpdateModerationRatingSubmission(id primitive.ObjectID, status, reason, moderatedBy string) error
 
 	GetListRatingSubmissions(filter request.RatingSubmissionFilter, page int, limit int64, sort string, dir interface{}) ([]entity.RatingSubmisson, *base.Pagination, error)
 	GetRatingSubmissionByRatingId(id string) (*entity.RatingSubmisson, error)
@@ -51,7 +78,9 @@ type RatingRepository interface {
 	CreateRating(input request.SaveRatingRequest) (*entity.RatingsCol, error)
 	GetRatingById(id primitive.ObjectID) (*entity.RatingsCol, error)
 	UpdateRating(id primitive.ObjectID, input request.BodyUpdateRatingRequest) (*entity.RatingsCol, error)
-	DeleteRating(id primitive.ObjectID) error
+	DeleteRating(id primitive.ObjectID, deletedBy string) error
+	RestoreRating(id primitive.ObjectID) error
+	GetDeletedRatingById(id primitive.ObjectID) (*entity.RatingsCol, error)
 	GetRatingsByParams(limit, page, dir int, sort string, filter request.RatingFilter) ([]entity.RatingsCol, *base.Pagination, error)
 	GetRatingByName(name string) (*entity.RatingsCol, error)
 	GetRatingTypeNumByIdAndStatus(id primitive.ObjectID) (*entity.RatingTypesNumCol, error)
@@ -62,15 +91,33 @@ type RatingRepository interface {
 	CreateRatingTypeLikert(input request.SaveRatingTypeLikertRequest) error
 	GetRatingTypeLikertById(id primitive.ObjectID) (*entity.RatingTypesLikertCol, error)
 	UpdateRatingTypeLikert(id primitive.ObjectID, input request.SaveRatingTypeLikertRequest) error
-	DeleteRatingTypeLikert(id primitive.ObjectID) error
+	DeleteRatingTypeLikert(id primitive.ObjectID, deletedBy string) error
+	RestoreRatingTypeLikert(id primitive.ObjectID) error
 	GetRatingTypeLikerts(filter request.FilterRatingTypeLikert, page int, limit int64, sort string, dir interface{}) ([]entity.RatingTypesLikertCol, *base.Pagination, error)
+	MigrateRatingTypeLikertStatements() (int64, error)
 	Paginate(value interface{}, pagination *base.Pagination, db *gorm.DB, currRecord int64) func(db *gorm.DB) *gorm.DB
 
 	CreateRatingFormula(input request.SaveRatingFormula) (*entity.RatingFormulaCol, error)
 	UpdateRatingFormula(id primitive.ObjectID, input request.SaveRatingFormula) error
 	GetRatingFormulaById(id primitive.ObjectID) (*entity.RatingFormulaCol, error)
-	DeleteRatingFormula(id primitive.ObjectID) error
+	DeleteRatingFormula(id primitive.ObjectID, deletedBy string) error
+	RestoreRatingFormula(id primitive.ObjectID) error
 	GetRatingFormulas(filter request.RatingFormulaFilter, page int, limit int64, sort string, dir interface{}) ([]entity.RatingFormulaCol, *base.Pagination, error)
+	CreateRatingFormulaVersion(formula entity.RatingFormulaCol, note, actor string) (*entity.RatingFormulaVersionCol, error)
+	GetRatingFormulaVersions(formulaId string, page int, limit int64) ([]entity.RatingFormulaVersionCol, *base.Pagination, error)
+	GetRatingFormulaVersion(formulaId string, version int) (*entity.RatingFormulaVersionCol, error)
+	ActivateRatingFormulaVersion(id primitive.ObjectID, version int, actor string) error
+
+	// Rating aggregate
+	GetRatingAggregates(ratings []entity.RatingsCol) (map[string]entity.RatingAggregateCol, error)
+	RebuildRatingAggregate(rating entity.RatingsCol) (*entity.RatingAggregateCol, error)
+
+	// Rating formula variables
+	GetGlobalSumCountBySourceType(sourceType, ratingTypeId string) (float64, int64, error)
+	GetDecayedSumCountByRatingId(ratingId string, halfLife time.Duration) (float64, float64, error)
+
+	// Soft delete
+	PurgeSoftDeleted(collectionName string, deletedBefore time.Time) (int64, error)
 }
 
 func NewRatingRepository(db *mongo.Database) RatingRepository {
@@ -103,15 +150,16 @@ func (r *ratingRepo) CreateRatingTypeNum(input request.CreateRatingTypeNumReques
 			return err
 		}
 		result, err := r.db.Collection("ratingTypesNumCol").InsertOne(ctx, bson.M{
-			"type":        input.Type,
-			"description": input.Description,
-			"min_score":   input.MinScore,
-			"max_score":   input.MaxScore,
-			"scale":       input.Scale,
-			"intervals":   input.Intervals,
-			"status":      input.Status,
-			"created_at":  time.Now().In(util.Loc),
-			"updated_at":  time.Now().In(util.Loc),
+			"type":             input.Type,
+			"description":      input.Description,
+			"description_i18n": input.DescriptionI18n,
+			"min_score":        input.MinScore,
+			"max_score":        input.MaxScore,
+			"scale":            input.Scale,
+			"intervals":        input.Intervals,
+			"status":           input.Status,
+			"created_at":       time.Now().In(util.Loc),
+			"updated_at":       time.Now().In(util.Loc),
 		})
 
 		if err != nil {
@@ -135,16 +183,17 @@ func (r *ratingRepo) UpdateRatingTypeNum(id primitive.ObjectID, input request.Ed
 	var timeUpdate time.Time
 	timeUpdate = time.Now().In(util.Loc)
 	ratingTypeLikert := entity.RatingTypesNumCol{
-		Type:        input.Type,
-		Description: input.Description,
-		MinScore:    input.MinScore,
-		MaxScore:    input.MaxScore,
-		Scale:       input.Scale,
-		Intervals:   input.Intervals,
-		Status:      input.Status,
-		UpdatedAt:   timeUpdate,
-	}
-	filter := bson.D{{"_id", id}}
+		Type:            input.Type,
+		Description:     input.Description,
+		DescriptionI18n: input.DescriptionI18n,
+		MinScore:        input.MinScore,
+		MaxScore:        input.MaxScore,
+		Scale:           input.Scale,
+		Intervals:       input.Intervals,
+		Status:          input.Status,
+		UpdatedAt:       timeUpdate,
+	}
+	filter := bson.D{{"_id", id}, notDeleted}
 	data := bson.D{{"$set", ratingTypeLikert}}
 	// transaction
 	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
@@ -171,7 +220,7 @@ func (r *ratingRepo) UpdateRatingTypeNum(id primitive.ObjectID, input request.Ed
 func (r *ratingRepo) GetRatingTypeNumById(id primitive.ObjectID) (*entity.RatingTypesNumCol, error) {
 	var ratingTypeNum entity.RatingTypesNumCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := r.db.Collection("ratingTypesNumCol").FindOne(ctx, bson.M{"_id": id}).Decode(&ratingTypeNum)
+	err := r.db.Collection("ratingTypesNumCol").FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&ratingTypeNum)
 	if err != nil {
 
 		return nil, err
@@ -179,14 +228,9 @@ func (r *ratingRepo) GetRatingTypeNumById(id primitive.ObjectID) (*entity.Rating
 	return &ratingTypeNum, nil
 }
 
-func (r *ratingRepo) DeleteRatingTypeNum(id primitive.ObjectID) error {
+func (r *ratingRepo) DeleteRatingTypeNum(id primitive.ObjectID, deletedBy string) error {
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	update := bson.D{{"$set", bson.D{{"status", false}}}}
-	_, err := r.db.Collection("ratingTypesNumCol").UpdateOne(ctx, bson.M{"_id": id}, update)
-	if err != nil {
-		return err
-	}
-	return nil
+	return softDeleteById(ctx, r.db.Collection("ratingTypesNumCol"), id, deletedBy)
 }
 
 func (r *ratingRepo) GetRatingTypeNums(filter request.Filter, page int, limit int64, sort string, dir interface{}) ([]entity.RatingTypesNumCol, *base.Pagination, error) {
@@ -199,8 +243,6 @@ func (r *ratingRepo) GetRatingTypeNums(filter request.Filter, page int, limit in
 	bsonMinScore := bson.D{}
 	bsonMaxScore := bson.D{}
 	bsonTypeIdsScore := bson.D{}
-	bsonStatus := bson.D{{"status", true}}
-
 	if len(typeIds) > 0 {
 		bsonTypeIdsScore = bson.D{{"_id", bson.D{{"$in", typeIds}}}}
 	}
@@ -252,7 +294,8 @@ func (r *ratingRepo) GetRatingTypeNums(filter request.Filter, page int, limit in
 	return results, pagination, nil
 }
 
-func (r *ratingRepo) CreateRatingSubmission(input []request.SaveRatingSubmission) (*[]entity.RatingSubmisson, error) {
+// CreateRatingSubmission saves the submissions and their outbox messages in one transaction
+func (r *ratingRepo) CreateRatingSubmission(input []request.SaveRatingSubmission, outbox []entity.OutboxCol) (*[]entity.RatingSubmisson, error) {
 	ratingSubmissionColl := r.db.Collection("ratingSubCol")
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
 	var ratingSubmission []entity.RatingSubmisson
@@ -266,6 +309,7 @@ func (r *ratingRepo) CreateRatingSubmission(input []request.SaveRatingSubmission
 				{Key: "user_id_legacy", Value: args.UserIDLegacy},
 				{Key: "display_name", Value: args.DisplayName},
 				{Key: "comment", Value: args.Comment},
+				{Key: "comment_search", Value: util_search.Normalize(args.Comment)},
 				{Key: "value", Value: args.Value},
 				{Key: "avatar", Value: args.Avatar},
 				{Key: "ip_address", Value: args.IPAddress},
@@ -281,6 +325,9 @@ func (r *ratingRepo) CreateRatingSubmission(input []request.SaveRatingSubmission
 				{Key: "is_anonymous", Value: args.IsAnonymous},
 				{Key: "tagging", Value: args.Tagging},
 				{Key: "source_type", Value: args.SourceType},
+				{Key: "moderation_status", Value: args.ModerationStatus},
+				{Key: "moderation_flags", Value: args.ModerationFlags},
+				{Key: "is_verified_purchase", Value: args.IsVerifiedPurchase},
 			})
 		} else {
 			docs = append(docs, bson.D{
@@ -289,6 +336,7 @@ func (r *ratingRepo) CreateRatingSubmission(input []request.SaveRatingSubmission
 				{Key: "user_id_legacy", Value: args.UserIDLegacy},
 				{Key: "display_name", Value: args.DisplayName},
 				{Key: "comment", Value: args.Comment},
+				{Key: "comment_search", Value: util_search.Normalize(args.Comment)},
 				{Key: "value", Value: args.Value},
 				{Key: "avatar", Value: args.Avatar},
 				{Key: "ip_address", Value: args.IPAddress},
@@ -303,8 +351,17 @@ func (r *ratingRepo) CreateRatingSubmission(input []request.SaveRatingSubmission
 				{Key: "cancelled_reason", Value: ""},
 				{Key: "is_anonymous", Value: args.IsAnonymous},
 				{Key: "source_type", Value: args.SourceType},
+				{Key: "moderation_status", Value: args.ModerationStatus},
+				{Key: "moderation_flags", Value: args.ModerationFlags},
+				{Key: "is_verified_purchase", Value: args.IsVerifiedPurchase},
 			})
 		}
+		if args.InvitationID != nil {
+			docs[len(docs)-1] = append(docs[len(docs)-1].(bson.D), bson.E{Key: "invitation_id", Value: args.InvitationID})
+		}
+		if len(args.Tags) > 0 {
+			docs[len(docs)-1] = append(docs[len(docs)-1].(bson.D), bson.E{Key: "tags", Value: args.Tags})
+		}
 	}
 	if len(docs) < 1 {
 		return nil, mongo.ErrNilValue
@@ -316,12 +373,39 @@ func (r *ratingRepo) CreateRatingSubmission(input []request.SaveRatingSubmission
 		if err != nil {
 			return err
 		}
-		result, err := ratingSubmissionColl.InsertMany(ctx, docs)
+		result, err := ratingSubmissionColl.InsertMany(sessionContext, docs)
 
 		if err != nil {
 			sessionContext.AbortTransaction(sessionContext)
 			return err
 		}
+		deltas := ratingAggregateDeltas{}
+		for _, args := range input {
+			inserted := entity.RatingSubmisson{
+				RatingID:         args.RatingID,
+				Comment:          &args.Comment,
+				ModerationStatus: args.ModerationStatus,
+			}
+			if args.Value != nil {
+				inserted.Value = *args.Value
+			}
+			deltas.add(nil, &inserted)
+		}
+		if err = r.applyRatingAggregateDeltas(sessionContext, deltas); err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		if err = insertOutboxMessages(sessionContext, r.db, outbox, ""); err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		if invitationId := input[0].InvitationID; invitationId != nil {
+			err = redeemReviewInvitation(sessionContext, r.db, *invitationId, result.InsertedIDs[0].(primitive.ObjectID).Hex())
+			if err != nil {
+				sessionContext.AbortTransaction(sessionContext)
+				return err
+			}
+		}
 		if err = sessionContext.CommitTransaction(sessionContext); err != nil {
 			return err
 		}
@@ -343,14 +427,28 @@ func (r *ratingRepo) UpdateRatingSubmission(input request.UpdateRatingSubmission
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
 	var timeUpdate time.Time
 	timeUpdate = time.Now().In(util.Loc)
+	var editedBy string
+	if input.UserIDLegacy != nil {
+		editedBy = *input.UserIDLegacy
+	}
 	ratingSubmiss := entity.RatingSubmisson{
-		RatingID:  input.RatingID,
-		Comment:   &input.Comment,
-		Value:     *input.Value,
-		UpdatedAt: timeUpdate,
+		RatingID:         input.RatingID,
+		Comment:          &input.Comment,
+		CommentSearch:    util_search.Normalize(input.Comment),
+		Value:            *input.Value,
+		UpdatedAt:        timeUpdate,
+		ModerationStatus: input.ModerationStatus,
+		ModerationFlags:  input.ModerationFlags,
+		EditedAt:         &timeUpdate,
+		EditedBy:         editedBy,
+		Tags:             input.Tags,
+	}
+	filter := bson.D{{"_id", id}, notDeleted}
+	data := bson.D{{"$set", ratingSubmiss}, {Key: "$inc", Value: bson.D{{Key: "edit_counter", Value: 1}}}}
+	if input.Tags != nil && len(input.Tags) == 0 {
+		// the omitted empty tags are removed
+		data = append(data, bson.E{Key: "$unset", Value: bson.D{{Key: "tags", Value: ""}}})
 	}
-	filter := bson.D{{"_id", id}}
-	data := bson.D{{"$set", ratingSubmiss}}
 
 	// transaction
 	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
@@ -358,10 +456,38 @@ func (r *ratingRepo) UpdateRatingSubmission(input request.UpdateRatingSubmission
 		if err != nil {
 			return err
 		}
-		err1 := r.db.Collection("ratingSubCol").FindOneAndUpdate(context.Background(), filter, data, &options.FindOneAndUpdateOptions{})
-		if err1 != nil {
+		var previous entity.RatingSubmisson
+		err = r.db.Collection("ratingSubCol").FindOneAndUpdate(sessionContext, filter, data, &options.FindOneAndUpdateOptions{}).Decode(&previous)
+		if err != nil {
 			sessionContext.AbortTransaction(sessionContext)
-			return err1.Err()
+			return err
+		}
+		// the version replaced by the edit is kept in ratingSubRevisionCol
+		err = insertRatingSubRevision(sessionContext, r.db, entity.RatingSubRevisionCol{
+			RatingSubmissionID: id.Hex(),
+			Revision:           previous.EditCounter + 1,
+			RatingID:           previous.RatingID,
+			Value:              previous.Value,
+			Comment:            previous.Comment,
+			EditedBy:           editedBy,
+			CreatedAt:          timeUpdate,
+		})
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		current := previous
+		if input.RatingID != "" {
+			current.RatingID = input.RatingID
+		}
+		current.Comment = &input.Comment
+		current.Value = *input.Value
+		if input.ModerationStatus != "" {
+			current.ModerationStatus = input.ModerationStatus
+		}
+		if err = r.applyRatingAggregateDelta(sessionContext, &previous, &current); err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
 		}
 		if err = sessionContext.CommitTransaction(sessionContext); err != nil {
 			return err
@@ -374,11 +500,45 @@ func (r *ratingRepo) UpdateRatingSubmission(input request.UpdateRatingSubmission
 	return nil
 }
 
+func (r *ratingRepo) UpdateModerationRatingSubmission(id primitive.ObjectID, status, reason, moderatedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+	timeUpdate := time.Now().In(util.Loc)
+	filter := bson.D{{Key: "_id", Value: id}, notDeleted}
+	data := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "moderation_status", Value: status},
+		{Key: "moderation_reason", Value: reason},
+		{Key: "moderated_by", Value: moderatedBy},
+		{Key: "moderated_at", Value: timeUpdate},
+		{Key: "updated_at", Value: timeUpdate},
+	}}}
+
+	// the aggregate of the rating changes in the transaction of the moderation
+	return r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
+		err := sessionContext.StartTransaction()
+		if err != nil {
+			return err
+		}
+		var previous entity.RatingSubmisson
+		err = r.db.Collection("ratingSubCol").FindOneAndUpdate(sessionContext, filter, data).Decode(&previous)
+		if err == nil {
+			current := previous
+			current.ModerationStatus = status
+			err = r.applyRatingAggregateDelta(sessionContext, &previous, &current)
+		}
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		return sessionContext.CommitTransaction(sessionContext)
+	})
+}
+
 func (r *ratingRepo) GetRatingSubmissionById(id primitive.ObjectID) (*entity.RatingSubmisson, error) {
 	var ratingSubmission entity.RatingSubmisson
 	ratingSubmissionColl := r.db.Collection("ratingSubCol")
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"_id": id}).Decode(&ratingSubmission)
+	err := ratingSubmissionColl.FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&ratingSubmission)
 	if err != nil {
 
 		return nil, err
@@ -396,7 +556,7 @@ func (r *ratingRepo) CancelRatingSubmissionByIds(ids []primitive.ObjectID, reaso
 		UpdatedAt:       timeUpdate,
 	}
 
-	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}, notDeleted}
 	data := bson.D{{Key: "$set", Value: ratingSub}}
 
 	// transaction
@@ -405,11 +565,32 @@ func (r *ratingRepo) CancelRatingSubmissionByIds(ids []primitive.ObjectID, reaso
 		if err != nil {
 			return err
 		}
-		_, errUpd := r.db.Collection("ratingSubCol").UpdateMany(context.Background(), filter, data)
+		// submissions already cancelled are not part of the aggregate anymore
+		var previous []entity.RatingSubmisson
+		cursor, err := r.db.Collection("ratingSubCol").Find(sessionContext, bson.D{{Key: "$and", Value: bson.A{
+			filter,
+			bson.D{{Key: "cancelled", Value: false}},
+		}}})
+		if err == nil {
+			err = cursor.All(sessionContext, &previous)
+		}
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		_, errUpd := r.db.Collection("ratingSubCol").UpdateMany(sessionContext, filter, data)
 		if errUpd != nil {
 			sessionContext.AbortTransaction(sessionContext)
 			return errUpd
 		}
+		deltas := ratingAggregateDeltas{}
+		for i := range previous {
+			deltas.add(&previous[i], nil)
+		}
+		if err = r.applyRatingAggregateDeltas(sessionContext, deltas); err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
 		if err = sessionContext.CommitTransaction(sessionContext); err != nil {
 			return err
 		}
@@ -426,7 +607,7 @@ func (r *ratingRepo) GetRatingSubmissionByRatingId(id string) (*entity.RatingSub
 	var ratingSubmission entity.RatingSubmisson
 	ratingSubmissionColl := r.db.Collection("ratingSubCol")
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"rating_id": id}).Decode(&ratingSubmission)
+	err := ratingSubmissionColl.FindOne(ctx, bson.M{"rating_id": id, "deleted_at": nil}).Decode(&ratingSubmission)
 	if err != nil {
 		return nil, err
 	}
@@ -437,7 +618,7 @@ func (r *ratingRepo) FindRatingSubmissionByUserIDAndRatingID(userId *string, rat
 	var ratingSubmission entity.RatingSubmisson
 	ratingSubmissionColl := r.db.Collection("ratingSubCol")
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"user_id": &userId, "rating_id": ratingId, "source_trans_id": sourceTransId}).Decode(&ratingSubmission)
+	err := ratingSubmissionColl.FindOne(ctx, bson.M{"user_id": &userId, "rating_id": ratingId, "source_trans_id": sourceTransId, "deleted_at": nil}).Decode(&ratingSubmission)
 	if err != nil {
 
 		return nil, err
@@ -449,7 +630,7 @@ func (r *ratingRepo) FindRatingSubmissionByUserIDLegacyAndRatingID(userIdLegacy
 	var ratingSubmission entity.RatingSubmisson
 	ratingSubmissionColl := r.db.Collection("ratingSubCol")
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingSubmissionColl.FindOne(ctx, bson.M{"user_id_legacy": &userIdLegacy, "rating_id": ratingId, "source_trans_id": sourceTransId}).Decode(&ratingSubmission)
+	err := ratingSubmissionColl.FindOne(ctx, bson.M{"user_id_legacy": &userIdLegacy, "rating_id": ratingId, "source_trans_id": sourceTransId, "deleted_at": nil}).Decode(&ratingSubmission)
 	if err != nil {
 
 		return nil, err
@@ -461,7 +642,7 @@ func (r *ratingRepo) FindRatingByRatingID(ratingId primitive.ObjectID) (*entity.
 	var rating entity.RatingsCol
 	ratingColl := r.db.Collection("ratingsCol")
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingColl.FindOne(ctx, bson.M{"_id": ratingId}).Decode(&rating)
+	err := ratingColl.FindOne(ctx, bson.M{"_id": ratingId, "deleted_at": nil}).Decode(&rating)
 	if err != nil {
 		return nil, err
 	}
@@ -473,7 +654,7 @@ func (r *ratingRepo) FindRatingBySourceUIDAndRatingType(sourceUID, ratingType st
 
 	ratingColl := r.db.Collection("ratingsCol")
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingColl.FindOne(ctx, bson.M{"source_uid": sourceUID, "rating_type": ratingType}).Decode(&rating)
+	err := ratingColl.FindOne(ctx, bson.M{"source_uid": sourceUID, "rating_type": ratingType, "deleted_at": nil}).Decode(&rating)
 	if err != nil {
 		return nil, err
 	}
@@ -484,23 +665,34 @@ func (r *ratingRepo) FindRatingNumericTypeByRatingTypeID(ratingTypeId primitive.
 	var ratingTypeNum entity.RatingTypesNumCol
 	ratingTypeNumColl := r.db.Collection("ratingTypesNumCol")
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := ratingTypeNumColl.FindOne(ctx, bson.M{"_id": ratingTypeId}).Decode(&ratingTypeNum)
+	err := ratingTypeNumColl.FindOne(ctx, bson.M{"_id": ratingTypeId, "deleted_at": nil}).Decode(&ratingTypeNum)
 	if err != nil {
 		return nil, err
 	}
 	return &ratingTypeNum, nil
 }
 
-func (r *ratingRepo) DeleteSubmission(id primitive.ObjectID) error {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	result, err := r.db.Collection("ratingSubCol").DeleteOne(ctx, bson.M{"_id": id})
-	if err != nil {
-		return err
-	}
-	if result.DeletedCount == 0 {
-		return errors.New("user not found")
-	}
-	return nil
+// DeleteSubmission soft deletes the submission, it leaves the aggregate of its rating until restored
+func (r *ratingRepo) DeleteSubmission(id primitive.ObjectID, deletedBy string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	return r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
+		err := sessionContext.StartTransaction()
+		if err != nil {
+			return err
+		}
+		var previous entity.RatingSubmisson
+		err = r.db.Collection("ratingSubCol").FindOneAndUpdate(sessionContext, bson.D{{Key: "_id", Value: id}, notDeleted}, softDeleteUpdate(deletedBy)).Decode(&previous)
+		if err == nil {
+			err = r.applyRatingAggregateDelta(sessionContext, &previous, nil)
+		}
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		return sessionContext.CommitTransaction(sessionContext)
+	})
 }
 
 func (r *ratingRepo) CreateRating(input request.SaveRatingRequest) (*entity.RatingsCol, error) {
@@ -514,16 +706,17 @@ func (r *ratingRepo) CreateRating(input request.SaveRatingRequest) (*entity.Rati
 			return err
 		}
 		result, err := r.db.Collection(entity.RatingsCol{}.CollectionName()).InsertOne(ctx, bson.M{
-			"name":            input.Name,
-			"description":     input.Description,
-			"source_uid":      input.SourceUid,
-			"source_type":     input.SourceType,
-			"rating_type":     input.RatingType,
-			"rating_type_id":  input.RatingTypeId,
-			"comment_allowed": input.CommentAllowed,
-			"status":          input.Status,
-			"created_at":      time.Now().In(util.Loc),
-			"updated_at":      time.Now().In(util.Loc),
+			"name":             input.Name,
+			"description":      input.Description,
+			"description_i18n": input.DescriptionI18n,
+			"source_uid":       input.SourceUid,
+			"source_type":      input.SourceType,
+			"rating_type":      input.RatingType,
+			"rating_type_id":   input.RatingTypeId,
+			"comment_allowed":  input.CommentAllowed,
+			"status":           input.Status,
+			"created_at":       time.Now().In(util.Loc),
+			"updated_at":       time.Now().In(util.Loc),
 		})
 
 		if err != nil {
@@ -545,7 +738,7 @@ func (r *ratingRepo) CreateRating(input request.SaveRatingRequest) (*entity.Rati
 func (r *ratingRepo) GetRatingById(id primitive.ObjectID) (*entity.RatingsCol, error) {
 	var rating entity.RatingsCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := r.db.Collection(entity.RatingsCol{}.CollectionName()).FindOne(ctx, bson.M{"_id": id}).Decode(&rating)
+	err := r.db.Collection(entity.RatingsCol{}.CollectionName()).FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&rating)
 	if err != nil {
 		return nil, err
 	}
@@ -555,14 +748,15 @@ func (r *ratingRepo) GetRatingById(id primitive.ObjectID) (*entity.RatingsCol, e
 func (r *ratingRepo) UpdateRating(id primitive.ObjectID, input request.BodyUpdateRatingRequest) (*entity.RatingsCol, error) {
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
 	rating := entity.RatingsCol{
-		Name:           input.Name,
-		Description:    input.Description,
-		SourceUid:      input.SourceUid,
-		SourceType:     input.SourceType,
-		CommentAllowed: input.CommentAllowed,
-		UpdatedAt:      time.Now().In(util.Loc),
-	}
-	filter := bson.D{{"_id", id}}
+		Name:            input.Name,
+		Description:     input.Description,
+		DescriptionI18n: input.DescriptionI18n,
+		SourceUid:       input.SourceUid,
+		SourceType:      input.SourceType,
+		CommentAllowed:  input.CommentAllowed,
+		UpdatedAt:       time.Now().In(util.Loc),
+	}
+	filter := bson.D{{"_id", id}, notDeleted}
 	data := bson.D{{"$set", rating}}
 	// transaction
 	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
@@ -586,14 +780,9 @@ func (r *ratingRepo) UpdateRating(id primitive.ObjectID, input request.BodyUpdat
 	return &rating, nil
 }
 
-func (r *ratingRepo) DeleteRating(id primitive.ObjectID) error {
+func (r *ratingRepo) DeleteRating(id primitive.ObjectID, deletedBy string) error {
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	update := bson.D{{"$set", bson.D{{"status", false}}}}
-	_, err := r.db.Collection(entity.RatingsCol{}.CollectionName()).UpdateOne(ctx, bson.M{"_id": id}, update)
-	if err != nil {
-		return err
-	}
-	return nil
+	return softDeleteById(ctx, r.db.Collection(entity.RatingsCol{}.CollectionName()), id, deletedBy)
 }
 
 func (r *ratingRepo) GetRatingsByParams(limit, page, dir int, sort string, filter request.RatingFilter) ([]entity.RatingsCol, *base.Pagination, error) {
@@ -672,7 +861,7 @@ func (r *ratingRepo) GetRatingsByParams(limit, page, dir int, sort string, filte
 func (r *ratingRepo) GetRatingByName(name string) (*entity.RatingsCol, error) {
 	var rating entity.RatingsCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := r.db.Collection(entity.RatingsCol{}.CollectionName()).FindOne(ctx, bson.M{"name": name}).Decode(&rating)
+	err := r.db.Collection(entity.RatingsCol{}.CollectionName()).FindOne(ctx, bson.M{"name": name, "deleted_at": nil}).Decode(&rating)
 	if err != nil {
 
 		return nil, err
@@ -683,7 +872,7 @@ func (r *ratingRepo) GetRatingByName(name string) (*entity.RatingsCol, error) {
 func (r *ratingRepo) GetRatingByType(id string) (*entity.RatingsCol, error) {
 	var rating entity.RatingsCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := r.db.Collection(entity.RatingsCol{}.CollectionName()).FindOne(ctx, bson.M{"rating_type_id": id}).Decode(&rating)
+	err := r.db.Collection(entity.RatingsCol{}.CollectionName()).FindOne(ctx, bson.M{"rating_type_id": id, "deleted_at": nil}).Decode(&rating)
 	if err != nil {
 
 		return nil, err
@@ -730,7 +919,7 @@ func (r *ratingRepo) GetRatingByRatingTypeSourceUidAndSourceType(ratingTypeId, s
 	var rating entity.RatingsCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
 	bsonFilter := bson.D{{"$and", bson.A{
-		bson.D{{"status", true}},
+		bsonStatus,
 		bson.D{{"rating_type_id", ratingTypeId}},
 		bson.D{{"source_uid", sourceUid}},
 		bson.D{{"source_type", sourceType}},
@@ -766,6 +955,7 @@ func (r *ratingRepo) GetListRatingSubmissions(filter request.RatingSubmissionFil
 	bsonRating := bson.D{}
 	bsonDate := bson.D{}
 	bsonTransId := bson.D{}
+	bsonModeration := bson.D{}
 	if len(filter.UserIDLegacy) > 0 {
 		bsonUserUid = bson.D{{Key: "user_id_legacy", Value: bson.D{{Key: "$in", Value: filter.UserIDLegacy}}}}
 	}
@@ -779,6 +969,12 @@ func (r *ratingRepo) GetListRatingSubmissions(filter request.RatingSubmissionFil
 		bsonTransId = bson.D{{Key: "source_trans_id", Value: filter.SourceTransID}}
 	}
 
+	if filter.ModerationStatus == entity.ModerationStatusApproved {
+		bsonModeration = bsonModerationApproved
+	} else if filter.ModerationStatus != "" {
+		bsonModeration = bson.D{{Key: "moderation_status", Value: filter.ModerationStatus}}
+	}
+
 	filter1 := bson.D{{Key: "$and",
 		Value: bson.A{
 			bson.D{{Key: "$or",
@@ -798,6 +994,9 @@ func (r *ratingRepo) GetListRatingSubmissions(filter request.RatingSubmissionFil
 					bsonDate,
 				},
 			}},
+			bsonModeration,
+			bsonNotDeleted,
+			util_search.Filter(filter.Q),
 		},
 	},
 	}
@@ -806,7 +1005,7 @@ func (r *ratingRepo) GetListRatingSubmissions(filter request.RatingSubmissionFil
 	cursor, err := r.db.Collection("ratingSubCol").
 		Find(context.Background(), filter1,
 			newMongoPaginate(limitPage, page).getPaginatedOpts().
-				SetSort(bson.D{{Key: sort, Value: dir}}))
+				SetSort(util_search.Sort(filter.Q, sort, dir)))
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return results, nil, nil
@@ -833,22 +1032,14 @@ func (r *ratingRepo) CreateRatingTypeLikert(input request.SaveRatingTypeLikertRe
 			return err
 		}
 		result, err := r.db.Collection("ratingTypesLikertCol").InsertOne(ctx, bson.M{
-			"type":           input.Type,
-			"description":    input.Description,
-			"num_statements": input.NumStatements,
-			"statement_01":   input.Statement01,
-			"statement_02":   input.Statement02,
-			"statement_03":   input.Statement03,
-			"statement_04":   input.Statement04,
-			"statement_05":   input.Statement05,
-			"statement_06":   input.Statement06,
-			"statement_07":   input.Statement07,
-			"statement_08":   input.Statement08,
-			"statement_09":   input.Statement09,
-			"statement_10":   input.Statement10,
-			"status":         input.Status,
-			"created_at":     time.Now().In(util.Loc),
-			"updated_at":     time.Now().In(util.Loc),
+			"type":             input.Type,
+			"description":      input.Description,
+			"description_i18n": input.DescriptionI18n,
+			"num_statements":   input.NumStatements,
+			"statements":       input.GetStatements(),
+			"status":           input.Status,
+			"created_at":       time.Now().In(util.Loc),
+			"updated_at":       time.Now().In(util.Loc),
 		})
 
 		if err != nil {
@@ -870,7 +1061,7 @@ func (r *ratingRepo) CreateRatingTypeLikert(input request.SaveRatingTypeLikertRe
 func (r *ratingRepo) GetRatingTypeLikertById(id primitive.ObjectID) (*entity.RatingTypesLikertCol, error) {
 	var ratingTypeLikert entity.RatingTypesLikertCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := r.db.Collection("ratingTypesLikertCol").FindOne(ctx, bson.M{"_id": id}).Decode(&ratingTypeLikert)
+	err := r.db.Collection("ratingTypesLikertCol").FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&ratingTypeLikert)
 	if err != nil {
 
 		return nil, err
@@ -883,36 +1074,29 @@ func (r *ratingRepo) UpdateRatingTypeLikert(id primitive.ObjectID, input request
 	var timeUpdate time.Time
 	timeUpdate = time.Now().In(util.Loc)
 	ratingTypeLikert := entity.RatingTypesLikertCol{
-		Type:          input.Type,
-		Description:   input.Description,
-		NumStatements: input.NumStatements,
-		Status:        input.Status,
-		UpdatedAt:     &timeUpdate,
-	}
-	filter := bson.D{{"_id", id}}
+		Type:            input.Type,
+		Description:     input.Description,
+		DescriptionI18n: input.DescriptionI18n,
+		NumStatements:   input.NumStatements,
+		Statements:      input.GetStatements(),
+		Status:          input.Status,
+		UpdatedAt:       &timeUpdate,
+	}
+	filter := bson.D{{"_id", id}, notDeleted}
 	data := bson.D{{"$set", ratingTypeLikert}}
-	dataStatement := bson.D{{"$set", bson.D{
-		{"statement_01", input.Statement01},
-		{"statement_02", input.Statement02},
-		{"statement_03", input.Statement03},
-		{"statement_04", input.Statement04},
-		{"statement_05", input.Statement05},
-		{"statement_06", input.Statement06},
-		{"statement_07", input.Statement07},
-		{"statement_08", input.Statement08},
-		{"statement_09", input.Statement09},
-		{"statement_10", input.Statement10},
-	}}}
 	// transaction
 	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
 		err := sessionContext.StartTransaction()
 		if err != nil {
 			return err
 		}
-		_, err1 := r.db.Collection("ratingTypesLikertCol").UpdateOne(context.Background(), filter, dataStatement, &options.UpdateOptions{})
-		if err1 != nil {
-			sessionContext.AbortTransaction(sessionContext)
-			return err1
+		// the statements replace statement_01..statement_10 of a document not migrated yet
+		if len(ratingTypeLikert.Statements) > 0 {
+			_, err1 := r.db.Collection("ratingTypesLikertCol").UpdateOne(context.Background(), filter, bson.D{{Key: "$unset", Value: legacyLikertStatementFields()}}, &options.UpdateOptions{})
+			if err1 != nil {
+				sessionContext.AbortTransaction(sessionContext)
+				return err1
+			}
 		}
 
 		err2 := r.db.Collection("ratingTypesLikertCol").FindOneAndUpdate(context.Background(), filter, data, &options.FindOneAndUpdateOptions{})
@@ -931,14 +1115,9 @@ func (r *ratingRepo) UpdateRatingTypeLikert(id primitive.ObjectID, input request
 	return nil
 }
 
-func (r *ratingRepo) DeleteRatingTypeLikert(id primitive.ObjectID) error {
+func (r *ratingRepo) DeleteRatingTypeLikert(id primitive.ObjectID, deletedBy string) error {
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	update := bson.D{{"$set", bson.D{{"status", false}}}}
-	_, err := r.db.Collection("ratingTypesLikertCol").UpdateOne(ctx, bson.M{"_id": id}, update)
-	if err != nil {
-		return err
-	}
-	return nil
+	return softDeleteById(ctx, r.db.Collection("ratingTypesLikertCol"), id, deletedBy)
 }
 
 func (r *ratingRepo) GetRatingTypeLikerts(filter request.FilterRatingTypeLikert, page int, limit int64, sort string, dir interface{}) ([]entity.RatingTypesLikertCol, *base.Pagination, error) {
@@ -986,7 +1165,8 @@ func (r *ratingRepo) GetRatingTypeLikerts(filter request.FilterRatingTypeLikert,
 }
 
 func (r *ratingRepo) CreateRatingFormula(input request.SaveRatingFormula) (*entity.RatingFormulaCol, error) {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
 	var ratingFormula entity.RatingFormulaCol
 
 	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
@@ -994,16 +1174,34 @@ func (r *ratingRepo) CreateRatingFormula(input request.SaveRatingFormula) (*enti
 		if err != nil {
 			return err
 		}
-		result, err := r.db.Collection("ratingFormulaCol").InsertOne(ctx, bson.M{
+		timeNow := time.Now().In(util.Loc)
+		ratingFormula = entity.RatingFormulaCol{
+			SourceType:   input.SourceType,
+			Formula:      input.Formula,
+			RatingTypeId: input.RatingTypeId,
+			RatingType:   input.RatingType,
+			Status:       input.Status,
+			Version:      1,
+			CreatedAt:    timeNow,
+			UpdatedAt:    timeNow,
+		}
+		result, err := r.db.Collection("ratingFormulaCol").InsertOne(sessionContext, bson.M{
 			"source_type":    input.SourceType,
 			"formula":        input.Formula,
 			"rating_type_id": input.RatingTypeId,
 			"rating_type":    input.RatingType,
 			"status":         input.Status,
-			"created_at":     time.Now().In(util.Loc),
-			"updated_at":     time.Now().In(util.Loc),
+			"version":        ratingFormula.Version,
+			"created_at":     timeNow,
+			"updated_at":     timeNow,
 		})
 
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		ratingFormula.ID = result.InsertedID.(primitive.ObjectID)
+		_, err = r.insertRatingFormulaVersion(sessionContext, ratingFormula, entity.RatingFormulaVersionCreated, "", fmt.Sprint(input.JWTObj.Fullname), true)
 		if err != nil {
 			sessionContext.AbortTransaction(sessionContext)
 			return err
@@ -1011,17 +1209,18 @@ func (r *ratingRepo) CreateRatingFormula(input request.SaveRatingFormula) (*enti
 		if err = sessionContext.CommitTransaction(sessionContext); err != nil {
 			return err
 		}
-		ratingFormula.ID = result.InsertedID.(primitive.ObjectID)
 		return nil
 	})
 	if errTransaction != nil {
 		return nil, errTransaction
 	}
-	return &ratingFormula, nil
+	return &entity.RatingFormulaCol{ID: ratingFormula.ID}, nil
 }
 
+// UpdateRatingFormula records a new active version when the source type, formula or rating type changes
 func (r *ratingRepo) UpdateRatingFormula(id primitive.ObjectID, input request.SaveRatingFormula) error {
-	ctx, _ := context.WithTimeout(context.Background(), time.Second*20)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
 	var timeUpdate time.Time
 	timeUpdate = time.Now().In(util.Loc)
 	ratingFormula := entity.RatingFormulaCol{
@@ -1032,7 +1231,8 @@ func (r *ratingRepo) UpdateRatingFormula(id primitive.ObjectID, input request.Sa
 		Status:       input.Status,
 		UpdatedAt:    timeUpdate,
 	}
-	filter := bson.D{{Key: "_id", Value: id}}
+	isVersioned := input.SourceType != "" || input.Formula != "" || input.RatingTypeId != "" || input.RatingType != ""
+	filter := bson.D{{Key: "_id", Value: id}, notDeleted}
 	data := bson.D{{Key: "$set", Value: ratingFormula}}
 	// transaction
 	errTransaction := r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
@@ -1040,10 +1240,18 @@ func (r *ratingRepo) UpdateRatingFormula(id primitive.ObjectID, input request.Sa
 		if err != nil {
 			return err
 		}
-		err1 := r.db.Collection("ratingFormulaCol").FindOneAndUpdate(context.Background(), filter, data, &options.FindOneAndUpdateOptions{})
-		if err1 != nil {
+		var previous entity.RatingFormulaCol
+		err = r.db.Collection("ratingFormulaCol").FindOneAndUpdate(sessionContext, filter, data, &options.FindOneAndUpdateOptions{}).Decode(&previous)
+		if err != nil {
 			sessionContext.AbortTransaction(sessionContext)
-			return err1.Err()
+			return err
+		}
+		if isVersioned {
+			err = r.versionUpdatedRatingFormula(sessionContext, previous, fmt.Sprint(input.JWTObj.Fullname))
+			if err != nil {
+				sessionContext.AbortTransaction(sessionContext)
+				return err
+			}
 		}
 		if err = sessionContext.CommitTransaction(sessionContext); err != nil {
 			return err
@@ -1059,7 +1267,7 @@ func (r *ratingRepo) UpdateRatingFormula(id primitive.ObjectID, input request.Sa
 func (r *ratingRepo) GetRatingFormulaById(id primitive.ObjectID) (*entity.RatingFormulaCol, error) {
 	var ratingFormula entity.RatingFormulaCol
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	err := r.db.Collection("ratingFormulaCol").FindOne(ctx, bson.M{"_id": id}).Decode(&ratingFormula)
+	err := r.db.Collection("ratingFormulaCol").FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&ratingFormula)
 	if err != nil {
 
 		return nil, err
@@ -1067,14 +1275,9 @@ func (r *ratingRepo) GetRatingFormulaById(id primitive.ObjectID) (*entity.Rating
 	return &ratingFormula, nil
 }
 
-func (r *ratingRepo) DeleteRatingFormula(id primitive.ObjectID) error {
+func (r *ratingRepo) DeleteRatingFormula(id primitive.ObjectID, deletedBy string) error {
 	ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: false}}}}
-	_, err := r.db.Collection("ratingFormulaCol").UpdateOne(ctx, bson.M{"_id": id}, update)
-	if err != nil {
-		return err
-	}
-	return nil
+	return softDeleteById(ctx, r.db.Collection("ratingFormulaCol"), id, deletedBy)
 }
 
 func (r *ratingRepo) GetRatingFormulas(filter request.RatingFormulaFilter, page int, limit int64, sort string, dir interface{}) ([]entity.RatingFormulaCol, *base.Pagination, error) {
@@ -1086,7 +1289,6 @@ func (r *ratingRepo) GetRatingFormulas(filter request.RatingFormulaFilter, page
 	}
 	bsonSourceType := bson.D{}
 	bsonRatingTypeIds := bson.D{}
-	bsonStatus := bson.D{{Key: "status", Value: true}}
 
 	if len(typeIds) > 0 {
 		bsonRatingTypeIds = bson.D{{Key: "_id", Value: bson.D{{"$in", typeIds}}}}
@@ -1130,3 +1332,37 @@ func (r *ratingRepo) GetRatingFormulas(filter request.RatingFormulaFilter, page
 	pagination := getPagination(r, page, limit, results, collectionName, filter1)
 	return results, pagination, nil
 }
+
+// GetRatingSubmissionIdsByOrderNumber returns the submissions of the order not cancelled yet,
+// the source_trans_id of a submission is its order id
+func (r *ratingRepo) GetRatingSubmissionIdsByOrderNumber(orderNumber string) ([]primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "source_trans_id", Value: orderNumber},
+		{Key: "cancelled", Value: false},
+		notDeleted,
+	}
+	var submissions []entity.RatingSubmisson
+	cursor, err := r.db.Collection("ratingSubCol").Find(ctx, filter, options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &submissions); err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(submissions))
+	for _, submission := range submissions {
+		ids = append(ids, submission.ID)
+	}
+	return ids, nil
+}
+
+// UpdateRatingSubmissionUserProfile sets the display name and/or avatar of every submission of the user
+func (r *ratingRepo) UpdateRatingSubmissionUserProfile(userId string, displayName, avatar *string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	return updateRatingSubmissionUserProfile(ctx, r.db.Collection("ratingSubCol"), userId, displayName, avatar)
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ToggleRatingSubHelpful saves the helpful vote of the user on a submission of ratingSubCol
+func (r *ratingRepo) ToggleRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, *entity.RatingSubHelpfulCounter, error) {
+	return toggleRatingSubHelpful(r.db, entity.RatingSubmisson{}.CollectionName(), input)
+}
+
+// ToggleRatingSubHelpful saves the helpful vote of the user on a submission of ratingSubMpCol
+func (r *ratingMpRepo) ToggleRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, *entity.RatingSubHelpfulCounter, error) {
+	return toggleRatingSubHelpful(r.db, entity.RatingSubmissionMp{}.CollectionName(), input)
+}
+
+// nextRatingSubHelpful returns the vote after the user voted, voting the same again removes the vote and voting the other one switches it
+func nextRatingSubHelpful(previous entity.RatingSubHelpfulCol, vote string) entity.RatingSubHelpfulCol {
+	next := previous
+	if vote == request.VoteNotHelpful {
+		next.NotHelpful = !previous.NotHelpful
+		next.Status = false
+	} else {
+		next.Status = !previous.Status
+		next.NotHelpful = false
+	}
+	return next
+}
+
+func counterDelta(previous, next bool) int {
+	switch {
+	case next && !previous:
+		return 1
+	case previous && !next:
+		return -1
+	}
+	return 0
+}
+
+// toggleRatingSubHelpful saves the vote and $inc the counters of the submission in one transaction, it returns the vote saved
+// and the counters of the submission after the vote. The unique index of the vote makes concurrent votes of a user conflict,
+// WithTransaction retries the conflicting one so no vote is lost. mongo.ErrNoDocuments is returned when the submission does not exist.
+func toggleRatingSubHelpful(db *mongo.Database, collectionName string, input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, *entity.RatingSubHelpfulCounter, error) {
+	ratingSubmissionId, err := primitive.ObjectIDFromHex(input.RatingSubmissionID)
+	if err != nil {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer session.EndSession(ctx)
+
+	var vote entity.RatingSubHelpfulCol
+	var counter entity.RatingSubHelpfulCounter
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		helpfulCol := db.Collection(entity.RatingSubHelpfulCol{}.CollectionName())
+		filter := bson.D{
+			{Key: "rating_submission_id", Value: input.RatingSubmissionID},
+			{Key: "user_id_legacy", Value: input.UserIDLegacy},
+		}
+		var previous entity.RatingSubHelpfulCol
+		if err := helpfulCol.FindOne(sessionContext, filter).Decode(&previous); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		vote = nextRatingSubHelpful(previous, input.Vote)
+
+		dateNow := time.Now().In(util.Loc)
+		data := bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "user_id", Value: input.UserID},
+				{Key: "ip_address", Value: input.IPAddress},
+				{Key: "user_agent", Value: input.UserAgent},
+				{Key: "status", Value: vote.Status},
+				{Key: "not_helpful", Value: vote.NotHelpful},
+				{Key: "updated_at", Value: dateNow},
+			}},
+			{Key: "$setOnInsert", Value: bson.D{{Key: "created_at", Value: dateNow}}},
+		}
+		err := helpfulCol.FindOneAndUpdate(sessionContext, filter, data,
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&vote)
+		if err != nil {
+			return nil, err
+		}
+
+		inc := bson.D{
+			{Key: "like_counter", Value: counterDelta(previous.Status, vote.Status)},
+			{Key: "not_helpful_counter", Value: counterDelta(previous.NotHelpful, vote.NotHelpful)},
+		}
+		return nil, db.Collection(collectionName).FindOneAndUpdate(sessionContext,
+			bson.D{{Key: "_id", Value: ratingSubmissionId}, notDeleted},
+			bson.D{{Key: "$inc", Value: inc}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After).
+				SetProjection(bson.D{{Key: "like_counter", Value: 1}, {Key: "not_helpful_counter", Value: 1}}),
+		).Decode(&counter)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &vote, &counter, nil
+}
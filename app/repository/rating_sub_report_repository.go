@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReportRatingSubmission saves the report of the user on a submission of ratingSubCol,
+// a submission hidden by the report leaves the rating aggregates in the transaction of the report
+func (r *ratingRepo) ReportRatingSubmission(report entity.RatingSubReportCol, threshold int) (bool, error) {
+	var previous entity.RatingSubmisson
+	return reportRatingSubmission(r.db, entity.RatingSubmisson{}.CollectionName(), report, threshold, &previous, func(sessionContext mongo.SessionContext) error {
+		current := previous
+		current.ModerationStatus = entity.ModerationStatusPending
+		return r.applyRatingAggregateDelta(sessionContext, &previous, &current)
+	})
+}
+
+// ReportRatingSubmission saves the report of the user on a submission of ratingSubMpCol
+func (r *ratingMpRepo) ReportRatingSubmission(report entity.RatingSubReportCol, threshold int) (bool, error) {
+	var previous entity.RatingSubmissionMp
+	return reportRatingSubmission(r.db, entity.RatingSubmissionMp{}.CollectionName(), report, threshold, &previous, nil)
+}
+
+func (r *ratingRepo) GetRatingSubReportGroups(page int, limit int64) ([]entity.RatingSubReportGroup, *base.Pagination, error) {
+	return getRatingSubReportGroups(r.db, "", entity.RatingSubmisson{}.CollectionName(), page, limit)
+}
+
+func (r *ratingMpRepo) GetRatingSubReportGroups(page int, limit int64) ([]entity.RatingSubReportGroup, *base.Pagination, error) {
+	return getRatingSubReportGroups(r.db, entity.RatingSubReportSourceMp, entity.RatingSubmissionMp{}.CollectionName(), page, limit)
+}
+
+// reportRatingSubmission saves the report and $inc report_counter of the submission in one transaction.
+// The submission reaching the threshold is moved to pending moderation and true is returned, previous is the submission before the report.
+// onHidden, when set, runs in the transaction once the submission is hidden.
+// Reaching the threshold hides a submission once, a submission approved by an admin afterwards stays published.
+// The unique index of the reports returns a duplicate key error when the user already reported the submission,
+// mongo.ErrNoDocuments is returned when the submission does not exist.
+func reportRatingSubmission(db *mongo.Database, collectionName string, report entity.RatingSubReportCol, threshold int, previous interface{},
+	onHidden func(sessionContext mongo.SessionContext) error) (bool, error) {
+	ratingSubmissionId, err := primitive.ObjectIDFromHex(report.RatingSubmissionID)
+	if err != nil {
+		return false, mongo.ErrNoDocuments
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return false, err
+	}
+	defer session.EndSession(ctx)
+
+	hidden := false
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		hidden = false
+		collection := db.Collection(collectionName)
+		filter := bson.D{{Key: "_id", Value: ratingSubmissionId}, notDeleted}
+
+		var raw bson.Raw
+		err := collection.FindOneAndUpdate(sessionContext, filter, bson.D{{Key: "$inc", Value: bson.D{{Key: "report_counter", Value: 1}}}}).Decode(&raw)
+		if err != nil {
+			return nil, err
+		}
+		if err = bson.Unmarshal(raw, previous); err != nil {
+			return nil, err
+		}
+
+		report.CreatedAt = time.Now().In(util.Loc)
+		if _, err = db.Collection(entity.RatingSubReportCol{}.CollectionName()).InsertOne(sessionContext, report); err != nil {
+			return nil, err
+		}
+
+		reportCounter, _ := raw.Lookup("report_counter").AsInt64OK()
+		status, _ := raw.Lookup("moderation_status").StringValueOK()
+		if threshold <= 0 || reportCounter+1 != int64(threshold) ||
+			status == entity.ModerationStatusPending || status == entity.ModerationStatusRejected {
+			return nil, nil
+		}
+		_, err = collection.UpdateOne(sessionContext, filter, bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "moderation_status", Value: entity.ModerationStatusPending},
+				{Key: "moderation_reason", Value: "reported by users"},
+				{Key: "updated_at", Value: report.CreatedAt},
+			}},
+			{Key: "$addToSet", Value: bson.D{{Key: "moderation_flags", Value: entity.ModerationFlagReported}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if onHidden != nil {
+			if err = onHidden(sessionContext); err != nil {
+				return nil, err
+			}
+		}
+		hidden = true
+		return nil, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return hidden, nil
+}
+
+// getRatingSubReportGroups groups the reports of the source by submission, the most reported submission first
+func getRatingSubReportGroups(db *mongo.Database, source, collectionName string, page int, limit int64) ([]entity.RatingSubReportGroup, *base.Pagination, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	results := []entity.RatingSubReportGroup{}
+	reportCol := db.Collection(entity.RatingSubReportCol{}.CollectionName())
+	filter := bson.D{{Key: "source", Value: source}}
+	skip := int64(page)*limit - limit
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$rating_submission_id"},
+			{Key: "total_report", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "last_reported_at", Value: bson.D{{Key: "$max", Value: "$created_at"}}},
+			{Key: "reports", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total_report", Value: -1}, {Key: "last_reported_at", Value: -1}}}},
+		{{Key: "$skip", Value: skip}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: collectionName},
+			{Key: "let", Value: bson.D{{Key: "id", Value: bson.D{{Key: "$toObjectId", Value: "$_id"}}}}},
+			{Key: "pipeline", Value: bson.A{
+				bson.D{{Key: "$match", Value: bson.D{{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$_id", "$$id"}}}}}}},
+				bson.D{{Key: "$project", Value: bson.D{{Key: "comment", Value: 1}, {Key: "moderation_status", Value: 1}}}},
+			}},
+			{Key: "as", Value: "submission"},
+		}}},
+		{{Key: "$unwind", Value: bson.D{{Key: "path", Value: "$submission"}, {Key: "preserveNullAndEmptyArrays", Value: true}}}},
+		{{Key: "$addFields", Value: bson.D{
+			{Key: "comment", Value: "$submission.comment"},
+			{Key: "moderation_status", Value: "$submission.moderation_status"},
+		}}},
+	}
+	cursor, err := reportCol.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, nil, err
+	}
+
+	ids, err := reportCol.Distinct(ctx, "rating_submission_id", filter, &options.DistinctOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	pagination := base.Pagination{
+		Page:         page,
+		Limit:        int(limit),
+		TotalRecords: int64(len(ids)),
+		Records:      int64(len(results)),
+	}
+	pagination.TotalPage = int(math.Ceil(float64(pagination.TotalRecords) / float64(pagination.GetLimit())))
+	return results, &pagination, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	util_search "go-klikdokter/pkg/util/search"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EditRatingSubmission saves the submission edited by the user and keeps the version it replaces in ratingSubRevisionCol,
+// in one transaction. Only the fields of the edit are set, the counters and the moderation updated concurrently
+// by the votes and the reports are kept. The moderation of input replaces the current one when the comment changed.
+// The outbox messages of the edit are queued in the same transaction.
+func (r *ratingMpRepo) EditRatingSubmission(input entity.RatingSubmissionMp, id primitive.ObjectID, outbox []entity.OutboxCol) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	var commentSearch string
+	if input.Comment != nil {
+		commentSearch = util_search.Normalize(*input.Comment)
+	}
+	filter := bson.D{{Key: "_id", Value: id}, notDeleted}
+	data := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "value", Value: input.Value},
+			{Key: "comment", Value: input.Comment},
+			{Key: "comment_search", Value: commentSearch},
+			{Key: "media", Value: input.Media},
+			{Key: "is_with_media", Value: input.IsWithMedia},
+			{Key: "tags", Value: input.Tags},
+			{Key: "updated_at", Value: input.UpdatedAt},
+			{Key: "edited_at", Value: input.EditedAt},
+			{Key: "edited_by", Value: input.EditedBy},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "edit_counter", Value: 1}}},
+	}
+
+	return r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
+		err := sessionContext.StartTransaction()
+		if err != nil {
+			return err
+		}
+		var previous entity.RatingSubmissionMp
+		err = r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).FindOneAndUpdate(sessionContext, filter, data).Decode(&previous)
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		if commentOf(previous.Comment) != commentOf(input.Comment) {
+			_, err = r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).UpdateOne(sessionContext, filter, bson.D{{Key: "$set", Value: bson.D{
+				{Key: "moderation_status", Value: input.ModerationStatus},
+				{Key: "moderation_flags", Value: input.ModerationFlags},
+			}}})
+			if err != nil {
+				sessionContext.AbortTransaction(sessionContext)
+				return err
+			}
+		}
+		revision := entity.RatingSubRevisionCol{
+			RatingSubmissionID: id.Hex(),
+			Source:             entity.RatingSubRevisionSourceMp,
+			Revision:           previous.EditCounter + 1,
+			Value:              strconv.Itoa(previous.Value),
+			Comment:            previous.Comment,
+			Media:              previous.Media,
+			EditedBy:           input.EditedBy,
+			CreatedAt:          time.Now().In(util.Loc),
+		}
+		if err = insertRatingSubRevision(sessionContext, r.db, revision); err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		if err = insertOutboxMessages(sessionContext, r.db, outbox, id.Hex()); err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		return sessionContext.CommitTransaction(sessionContext)
+	})
+}
+
+func (r *ratingRepo) GetRatingSubRevisions(ratingSubmissionId string) ([]entity.RatingSubRevisionCol, error) {
+	return getRatingSubRevisions(r.db, "", ratingSubmissionId)
+}
+
+func (r *ratingMpRepo) GetRatingSubRevisions(ratingSubmissionId string) ([]entity.RatingSubRevisionCol, error) {
+	return getRatingSubRevisions(r.db, entity.RatingSubRevisionSourceMp, ratingSubmissionId)
+}
+
+func commentOf(comment *string) string {
+	if comment == nil {
+		return ""
+	}
+	return *comment
+}
+
+// insertRatingSubRevision saves a prior version of a submission, the unique index on the revision
+// fails the transaction of an edit racing another edit of the same submission
+func insertRatingSubRevision(ctx context.Context, db *mongo.Database, revision entity.RatingSubRevisionCol) error {
+	_, err := db.Collection(entity.RatingSubRevisionCol{}.CollectionName()).InsertOne(ctx, revision)
+	return err
+}
+
+// getRatingSubRevisions returns the revisions of a submission, the oldest first
+func getRatingSubRevisions(db *mongo.Database, source, ratingSubmissionId string) ([]entity.RatingSubRevisionCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	filter := bson.D{{Key: "rating_submission_id", Value: ratingSubmissionId}, {Key: "source", Value: source}}
+	cursor, err := db.Collection(entity.RatingSubRevisionCol{}.CollectionName()).Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "revision", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var revisions []entity.RatingSubRevisionCol
+	if err = cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
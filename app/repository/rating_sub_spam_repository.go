@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CountRatingSubmissionsSince counts the submissions of ratingSubCol created since the date whose field equals value
+func (r *ratingRepo) CountRatingSubmissionsSince(field, value string, since time.Time) (int64, error) {
+	return countRatingSubmissionsSince(r.db, entity.RatingSubmisson{}.CollectionName(), field, value, since)
+}
+
+// CountRatingSubmissionsSince counts the submissions of ratingSubMpCol created since the date whose field equals value
+func (r *ratingMpRepo) CountRatingSubmissionsSince(field, value string, since time.Time) (int64, error) {
+	return countRatingSubmissionsSince(r.db, entity.RatingSubmissionMp{}.CollectionName(), field, value, since)
+}
+
+func countRatingSubmissionsSince(db *mongo.Database, collectionName, field, value string, since time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	return db.Collection(collectionName).CountDocuments(ctx, bson.D{
+		{Key: field, Value: value},
+		{Key: "created_at", Value: bson.D{{Key: "$gte", Value: since}}},
+		notDeleted,
+	})
+}
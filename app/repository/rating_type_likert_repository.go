@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-klikdokter/app/model/entity"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// legacyLikertStatementFields are statement_01..statement_10, the likert statements before they were stored as an array
+func legacyLikertStatementFields() bson.D {
+	fields := make(bson.D, 0, 10)
+	for i := 1; i <= 10; i++ {
+		fields = append(fields, bson.E{Key: fmt.Sprintf("statement_%02d", i), Value: ""})
+	}
+	return fields
+}
+
+// MigrateRatingTypeLikertStatements moves statement_01..statement_10 of the likert types to statements keyed by position,
+// the value of the submissions made before stays a valid key. Returns the number of likert types migrated.
+func (r *ratingRepo) MigrateRatingTypeLikertStatements() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	collection := r.db.Collection(entity.RatingTypesLikertCol{}.CollectionName())
+	cursor, err := collection.Find(ctx, bson.D{{Key: "statements", Value: bson.D{{Key: "$exists", Value: false}}}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var total int64
+	for cursor.Next(ctx) {
+		var likert entity.RatingTypesLikertCol
+		if err = cursor.Decode(&likert); err != nil {
+			return total, err
+		}
+		update := bson.D{
+			{Key: "$set", Value: bson.D{{Key: "statements", Value: likert.GetStatements()}}},
+			{Key: "$unset", Value: legacyLikertStatementFields()},
+		}
+		if _, err = collection.UpdateByID(ctx, likert.ID, update); err != nil {
+			return total, err
+		}
+		total++
+	}
+	return total, cursor.Err()
+}
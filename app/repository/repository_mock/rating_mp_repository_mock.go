@@ -15,6 +15,8 @@ import (
 	publicresponse "go-klikdokter/app/model/response/public"
 
 	request "go-klikdokter/app/model/request"
+
+	time "time"
 )
 
 // RatingMpRepository is an autogenerated mock type for the RatingMpRepository type
@@ -66,13 +68,13 @@ func (_m *RatingMpRepository) CreateRating(input request.SaveRatingRequest) (*en
 	return r0, r1
 }
 
-// CreateRatingSubmission provides a mock function with given fields: input
-func (_m *RatingMpRepository) CreateRatingSubmission(input []entity.RatingSubmissionMp) (*[]entity.RatingSubmissionMp, error) {
-	ret := _m.Called(input)
+// CreateRatingSubmission provides a mock function with given fields: input, outbox
+func (_m *RatingMpRepository) CreateRatingSubmission(input []entity.RatingSubmissionMp, outbox []entity.OutboxCol) (*[]entity.RatingSubmissionMp, error) {
+	ret := _m.Called(input, outbox)
 
 	var r0 *[]entity.RatingSubmissionMp
-	if rf, ok := ret.Get(0).(func([]entity.RatingSubmissionMp) *[]entity.RatingSubmissionMp); ok {
-		r0 = rf(input)
+	if rf, ok := ret.Get(0).(func([]entity.RatingSubmissionMp, []entity.OutboxCol) *[]entity.RatingSubmissionMp); ok {
+		r0 = rf(input, outbox)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]entity.RatingSubmissionMp)
@@ -80,8 +82,8 @@ func (_m *RatingMpRepository) CreateRatingSubmission(input []entity.RatingSubmis
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func([]entity.RatingSubmissionMp) error); ok {
-		r1 = rf(input)
+	if rf, ok := ret.Get(1).(func([]entity.RatingSubmissionMp, []entity.OutboxCol) error); ok {
+		r1 = rf(input, outbox)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -126,13 +128,13 @@ func (_m *RatingMpRepository) FindRatingBySourceUIDAndRatingType(sourceUID strin
 	return r0, r1
 }
 
-// FindRatingSubmissionBySourceTransID provides a mock function with given fields: sourceTransId
-func (_m *RatingMpRepository) FindRatingSubmissionBySourceTransID(sourceTransId string) (*entity.RatingSubmissionMp, error) {
-	ret := _m.Called(sourceTransId)
+// FindRatingSubmissionByOrderLine provides a mock function with given fields: orderNumber, sourceType, sourceUid, userId
+func (_m *RatingMpRepository) FindRatingSubmissionByOrderLine(orderNumber string, sourceType string, sourceUid string, userId string) (*entity.RatingSubmissionMp, error) {
+	ret := _m.Called(orderNumber, sourceType, sourceUid, userId)
 
 	var r0 *entity.RatingSubmissionMp
-	if rf, ok := ret.Get(0).(func(string) *entity.RatingSubmissionMp); ok {
-		r0 = rf(sourceTransId)
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *entity.RatingSubmissionMp); ok {
+		r0 = rf(orderNumber, sourceType, sourceUid, userId)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.RatingSubmissionMp)
@@ -140,8 +142,8 @@ func (_m *RatingMpRepository) FindRatingSubmissionBySourceTransID(sourceTransId
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(sourceTransId)
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(orderNumber, sourceType, sourceUid, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -521,6 +523,38 @@ func (_m *RatingMpRepository) GetSumCountRatingSubsByRatingId(ratingId string) (
 	return r0, r1
 }
 
+// ToggleRatingSubHelpful provides a mock function with given fields: input
+func (_m *RatingMpRepository) ToggleRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, *entity.RatingSubHelpfulCounter, error) {
+	ret := _m.Called(input)
+
+	var r0 *entity.RatingSubHelpfulCol
+	if rf, ok := ret.Get(0).(func(request.CreateRatingSubHelpfulRequest) *entity.RatingSubHelpfulCol); ok {
+		r0 = rf(input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RatingSubHelpfulCol)
+		}
+	}
+
+	var r1 *entity.RatingSubHelpfulCounter
+	if rf, ok := ret.Get(1).(func(request.CreateRatingSubHelpfulRequest) *entity.RatingSubHelpfulCounter); ok {
+		r1 = rf(input)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*entity.RatingSubHelpfulCounter)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(request.CreateRatingSubHelpfulRequest) error); ok {
+		r2 = rf(input)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // UpdateRating provides a mock function with given fields: id, input
 func (_m *RatingMpRepository) UpdateRating(id primitive.ObjectID, input request.BodyUpdateRatingRequest) (*entity.RatingsMpCol, error) {
 	ret := _m.Called(id, input)
@@ -601,6 +635,370 @@ func (_m *RatingMpRepository) GetRatingFormulaBySourceType(sourceType string) (*
 
 	return r0, r1
 }
+
+// GetGlobalSumCountBySourceType provides a mock function with given fields: sourceType, ratingTypeId
+func (_m *RatingMpRepository) GetGlobalSumCountBySourceType(sourceType string, ratingTypeId string) (float64, int64, error) {
+	ret := _m.Called(sourceType, ratingTypeId)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(string, string) float64); ok {
+		r0 = rf(sourceType, ratingTypeId)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(string, string) int64); ok {
+		r1 = rf(sourceType, ratingTypeId)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, string) error); ok {
+		r2 = rf(sourceType, ratingTypeId)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetDecayedSumCountBySource provides a mock function with given fields: sourceUid, sourceType, halfLife
+func (_m *RatingMpRepository) GetDecayedSumCountBySource(sourceUid string, sourceType string, halfLife time.Duration) (float64, float64, error) {
+	ret := _m.Called(sourceUid, sourceType, halfLife)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) float64); ok {
+		r0 = rf(sourceUid, sourceType, halfLife)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 float64
+	if rf, ok := ret.Get(1).(func(string, string, time.Duration) float64); ok {
+		r1 = rf(sourceUid, sourceType, halfLife)
+	} else {
+		r1 = ret.Get(1).(float64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, string, time.Duration) error); ok {
+		r2 = rf(sourceUid, sourceType, halfLife)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetFinalRatingBySource provides a mock function with given fields: sourceType, sourceUid
+func (_m *RatingMpRepository) GetFinalRatingBySource(sourceType string, sourceUid string) (*entity.FinalRatingCol, error) {
+	ret := _m.Called(sourceType, sourceUid)
+
+	var r0 *entity.FinalRatingCol
+	if rf, ok := ret.Get(0).(func(string, string) *entity.FinalRatingCol); ok {
+		r0 = rf(sourceType, sourceUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.FinalRatingCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(sourceType, sourceUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ScheduleFinalRating provides a mock function with given fields: sourceType, sourceUid, due
+func (_m *RatingMpRepository) ScheduleFinalRating(sourceType string, sourceUid string, due time.Time) error {
+	ret := _m.Called(sourceType, sourceUid, due)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) error); ok {
+		r0 = rf(sourceType, sourceUid, due)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveFinalRating provides a mock function with given fields: finalRating, event
+func (_m *RatingMpRepository) SaveFinalRating(finalRating entity.FinalRatingCol, event entity.OutboxCol) error {
+	ret := _m.Called(finalRating, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.FinalRatingCol, entity.OutboxCol) error); ok {
+		r0 = rf(finalRating, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetFinalRatingSourceUids provides a mock function with given fields: sourceType
+func (_m *RatingMpRepository) GetFinalRatingSourceUids(sourceType string) ([]string, error) {
+	ret := _m.Called(sourceType)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(sourceType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(sourceType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRatingSubmissionsByOrderNumber provides a mock function with given fields: orderNumber, sourceUids
+func (_m *RatingMpRepository) GetRatingSubmissionsByOrderNumber(orderNumber string, sourceUids []string) ([]entity.RatingSubmissionMp, error) {
+	ret := _m.Called(orderNumber, sourceUids)
+
+	var r0 []entity.RatingSubmissionMp
+	if rf, ok := ret.Get(0).(func(string, []string) []entity.RatingSubmissionMp); ok {
+		r0 = rf(orderNumber, sourceUids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RatingSubmissionMp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(orderNumber, sourceUids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRatingSubmissionsByIds provides a mock function with given fields: ids
+func (_m *RatingMpRepository) GetRatingSubmissionsByIds(ids []primitive.ObjectID) ([]entity.RatingSubmissionMp, error) {
+	ret := _m.Called(ids)
+
+	var r0 []entity.RatingSubmissionMp
+	if rf, ok := ret.Get(0).(func([]primitive.ObjectID) []entity.RatingSubmissionMp); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RatingSubmissionMp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]primitive.ObjectID) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CancelRatingSubmissionByIds provides a mock function with given fields: ids, reason
+func (_m *RatingMpRepository) CancelRatingSubmissionByIds(ids []primitive.ObjectID, reason string) error {
+	ret := _m.Called(ids, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]primitive.ObjectID, string) error); ok {
+		r0 = rf(ids, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateRatingSubmissionUserProfile provides a mock function with given fields: userId, displayName, avatar
+func (_m *RatingMpRepository) UpdateRatingSubmissionUserProfile(userId string, displayName *string, avatar *string) error {
+	ret := _m.Called(userId, displayName, avatar)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *string, *string) error); ok {
+		r0 = rf(userId, displayName, avatar)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateReviewInvitations provides a mock function with given fields: invitations
+func (_m *RatingMpRepository) CreateReviewInvitations(invitations []entity.ReviewInvitationCol) (int, error) {
+	ret := _m.Called(invitations)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func([]entity.ReviewInvitationCol) int); ok {
+		r0 = rf(invitations)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]entity.ReviewInvitationCol) error); ok {
+		r1 = rf(invitations)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CancelReviewInvitationsByOrderNumber provides a mock function with given fields: orderNumber, sourceUids
+func (_m *RatingMpRepository) CancelReviewInvitationsByOrderNumber(orderNumber string, sourceUids []string) error {
+	ret := _m.Called(orderNumber, sourceUids)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(orderNumber, sourceUids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetReviewInvitationsByOrderNumber provides a mock function with given fields: orderNumber, userId
+func (_m *RatingMpRepository) GetReviewInvitationsByOrderNumber(orderNumber string, userId string) ([]entity.ReviewInvitationCol, error) {
+	ret := _m.Called(orderNumber, userId)
+
+	var r0 []entity.ReviewInvitationCol
+	if rf, ok := ret.Get(0).(func(string, string) []entity.ReviewInvitationCol); ok {
+		r0 = rf(orderNumber, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.ReviewInvitationCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(orderNumber, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingReviewInvitations provides a mock function with given fields: userId, page, limit
+func (_m *RatingMpRepository) GetPendingReviewInvitations(userId string, page int, limit int64) ([]entity.ReviewInvitationCol, *base.Pagination, error) {
+	ret := _m.Called(userId, page, limit)
+
+	var r0 []entity.ReviewInvitationCol
+	if rf, ok := ret.Get(0).(func(string, int, int64) []entity.ReviewInvitationCol); ok {
+		r0 = rf(userId, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.ReviewInvitationCol)
+		}
+	}
+
+	var r1 *base.Pagination
+	if rf, ok := ret.Get(1).(func(string, int, int64) *base.Pagination); ok {
+		r1 = rf(userId, page, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*base.Pagination)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, int, int64) error); ok {
+		r2 = rf(userId, page, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetReviewInvitationById provides a mock function with given fields: id
+func (_m *RatingMpRepository) GetReviewInvitationById(id primitive.ObjectID) (*entity.ReviewInvitationCol, error) {
+	ret := _m.Called(id)
+
+	var r0 *entity.ReviewInvitationCol
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) *entity.ReviewInvitationCol); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ReviewInvitationCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReviewInvitationByOrderLine provides a mock function with given fields: orderNumber, userId, sourceType, sourceUid
+func (_m *RatingMpRepository) GetReviewInvitationByOrderLine(orderNumber string, userId string, sourceType string, sourceUid string) (*entity.ReviewInvitationCol, error) {
+	ret := _m.Called(orderNumber, userId, sourceType, sourceUid)
+
+	var r0 *entity.ReviewInvitationCol
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *entity.ReviewInvitationCol); ok {
+		r0 = rf(orderNumber, userId, sourceType, sourceUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ReviewInvitationCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(orderNumber, userId, sourceType, sourceUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubmissionTags provides a mock function with given fields: sourceType
+func (_m *RatingMpRepository) GetSubmissionTags(sourceType string) ([]entity.SubmissionTagCol, error) {
+	ret := _m.Called(sourceType)
+
+	var r0 []entity.SubmissionTagCol
+	if rf, ok := ret.Get(0).(func(string) []entity.SubmissionTagCol); ok {
+		r0 = rf(sourceType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.SubmissionTagCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(sourceType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewRatingMpRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -615,3 +1013,160 @@ func NewRatingMpRepository(t mockConstructorTestingTNewRatingMpRepository) *Rati
 
 	return mock
 }
+
+// ReportRatingSubmission provides a mock function with given fields: report, threshold
+func (_m *RatingMpRepository) ReportRatingSubmission(report entity.RatingSubReportCol, threshold int) (bool, error) {
+	ret := _m.Called(report, threshold)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(entity.RatingSubReportCol, int) bool); ok {
+		r0 = rf(report, threshold)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(entity.RatingSubReportCol, int) error); ok {
+		r1 = rf(report, threshold)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRatingSubReportGroups provides a mock function with given fields: page, limit
+func (_m *RatingMpRepository) GetRatingSubReportGroups(page int, limit int64) ([]entity.RatingSubReportGroup, *base.Pagination, error) {
+	ret := _m.Called(page, limit)
+
+	var r0 []entity.RatingSubReportGroup
+	if rf, ok := ret.Get(0).(func(int, int64) []entity.RatingSubReportGroup); ok {
+		r0 = rf(page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RatingSubReportGroup)
+		}
+	}
+
+	var r1 *base.Pagination
+	if rf, ok := ret.Get(1).(func(int, int64) *base.Pagination); ok {
+		r1 = rf(page, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*base.Pagination)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int, int64) error); ok {
+		r2 = rf(page, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// CountRatingSubmissionsSince provides a mock function with given fields: field, value, since
+func (_m *RatingMpRepository) CountRatingSubmissionsSince(field string, value string, since time.Time) (int64, error) {
+	ret := _m.Called(field, value, since)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) int64); ok {
+		r0 = rf(field, value, since)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(field, value, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EditRatingSubmission provides a mock function with given fields: input, id, outbox
+func (_m *RatingMpRepository) EditRatingSubmission(input entity.RatingSubmissionMp, id primitive.ObjectID, outbox []entity.OutboxCol) error {
+	ret := _m.Called(input, id, outbox)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.RatingSubmissionMp, primitive.ObjectID, []entity.OutboxCol) error); ok {
+		r0 = rf(input, id, outbox)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetRatingSubRevisions provides a mock function with given fields: ratingSubmissionId
+func (_m *RatingMpRepository) GetRatingSubRevisions(ratingSubmissionId string) ([]entity.RatingSubRevisionCol, error) {
+	ret := _m.Called(ratingSubmissionId)
+
+	var r0 []entity.RatingSubRevisionCol
+	if rf, ok := ret.Get(0).(func(string) []entity.RatingSubRevisionCol); ok {
+		r0 = rf(ratingSubmissionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RatingSubRevisionCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ratingSubmissionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SoftDeleteRatingSubmission provides a mock function with given fields: id, deletedBy
+func (_m *RatingMpRepository) SoftDeleteRatingSubmission(id primitive.ObjectID, deletedBy string) (*entity.RatingSubmissionMp, error) {
+	ret := _m.Called(id, deletedBy)
+
+	var r0 *entity.RatingSubmissionMp
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, string) *entity.RatingSubmissionMp); ok {
+		r0 = rf(id, deletedBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RatingSubmissionMp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID, string) error); ok {
+		r1 = rf(id, deletedBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreRatingSubmission provides a mock function with given fields: id
+func (_m *RatingMpRepository) RestoreRatingSubmission(id primitive.ObjectID) (*entity.RatingSubmissionMp, error) {
+	ret := _m.Called(id)
+
+	var r0 *entity.RatingSubmissionMp
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) *entity.RatingSubmissionMp); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RatingSubmissionMp)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
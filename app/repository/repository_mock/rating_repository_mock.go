@@ -5,6 +5,8 @@ import (
 	"go-klikdokter/app/model/base"
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
+	"reflect"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -125,10 +127,10 @@ func (repository *RatingRepositoryMock) UpdateRatingTypeLikert(id primitive.Obje
 	return nil
 }
 
-func (repository *RatingRepositoryMock) DeleteRatingTypeLikert(id primitive.ObjectID) error {
+func (repository *RatingRepositoryMock) DeleteRatingTypeLikert(id primitive.ObjectID, deletedBy string) error {
 	objectId, _ := primitive.ObjectIDFromHex("629ec0836f3c2761ba2dc869")
 	if id == objectId {
-		return errors.New("user not found")
+		return mongo.ErrNoDocuments
 	}
 	objectId2, _ := primitive.ObjectIDFromHex("629ec0836f3c2761ba2dc899")
 	if id == objectId2 {
@@ -140,7 +142,7 @@ func (repository *RatingRepositoryMock) DeleteRatingTypeLikert(id primitive.Obje
 func (repository *RatingRepositoryMock) GetRatingTypeLikerts(filter request.FilterRatingTypeLikert, page int, limit int64, sort string, dir interface{}) ([]entity.RatingTypesLikertCol, *base.Pagination, error) {
 	arguments := repository.Mock.Called(filter, page, limit, sort, dir)
 	rating := entity.RatingTypesLikertCol{}
-	if arguments.Get(0) == rating {
+	if reflect.DeepEqual(arguments.Get(0), rating) {
 		return nil, nil, gorm.ErrRecordNotFound
 	}
 	if sort == "failed" {
@@ -168,10 +170,10 @@ func (repository *RatingRepositoryMock) GetRatingTypeNumById(id primitive.Object
 	}
 }
 
-func (repository *RatingRepositoryMock) DeleteRatingTypeNum(id primitive.ObjectID) error {
+func (repository *RatingRepositoryMock) DeleteRatingTypeNum(id primitive.ObjectID, deletedBy string) error {
 	objectId, _ := primitive.ObjectIDFromHex("629ec0836f3c2761ba2dc869")
 	if id == objectId {
-		return errors.New("user not found")
+		return mongo.ErrNoDocuments
 	}
 	objectId2, _ := primitive.ObjectIDFromHex("629ec0836f3c2761ba2dc899")
 	if id == objectId2 {
@@ -183,7 +185,7 @@ func (repository *RatingRepositoryMock) DeleteRatingTypeNum(id primitive.ObjectI
 func (repository *RatingRepositoryMock) GetRatingTypeNums(filter request.Filter, page int, limit int64, sort string, dir interface{}) ([]entity.RatingTypesNumCol, *base.Pagination, error) {
 	arguments := repository.Mock.Called(filter, page, limit, sort, dir)
 	rating := entity.RatingTypesNumCol{}
-	if arguments.Get(0) == rating {
+	if reflect.DeepEqual(arguments.Get(0), rating) {
 		return nil, nil, gorm.ErrRecordNotFound
 	}
 	if sort == "failed" {
@@ -214,7 +216,7 @@ func (repository *RatingRepositoryMock) Paginate(value interface{}, pagination *
 	return nil
 }
 
-func (repository *RatingRepositoryMock) CreateRatingSubmission(input []request.SaveRatingSubmission) (*[]entity.RatingSubmisson, error) {
+func (repository *RatingRepositoryMock) CreateRatingSubmission(input []request.SaveRatingSubmission, outbox []entity.OutboxCol) (*[]entity.RatingSubmisson, error) {
 	for _, arg := range input {
 		if *arg.UserID != "629dce7bf1f26275e0d84826" {
 			return nil, errors.New("can not be created")
@@ -228,10 +230,10 @@ func (repository *RatingRepositoryMock) UpdateRatingSubmission(input request.Upd
 	}
 	return nil
 }
-func (repository *RatingRepositoryMock) DeleteSubmission(id primitive.ObjectID) error {
+func (repository *RatingRepositoryMock) DeleteSubmission(id primitive.ObjectID, deletedBy string) error {
 	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
 	if id != objectId {
-		return errors.New("user not found")
+		return mongo.ErrNoDocuments
 	}
 	return nil
 }
@@ -254,6 +256,52 @@ func (repository *RatingRepositoryMock) CancelRatingSubmissionByIds(ids []primit
 	return nil
 }
 
+// ToggleRatingSubHelpful provides a mock function with given fields: input
+func (_m *RatingRepositoryMock) ToggleRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (*entity.RatingSubHelpfulCol, *entity.RatingSubHelpfulCounter, error) {
+	ret := _m.Mock.Called(input)
+
+	var r0 *entity.RatingSubHelpfulCol
+	if rf, ok := ret.Get(0).(func(request.CreateRatingSubHelpfulRequest) *entity.RatingSubHelpfulCol); ok {
+		r0 = rf(input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RatingSubHelpfulCol)
+		}
+	}
+
+	var r1 *entity.RatingSubHelpfulCounter
+	if rf, ok := ret.Get(1).(func(request.CreateRatingSubHelpfulRequest) *entity.RatingSubHelpfulCounter); ok {
+		r1 = rf(input)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*entity.RatingSubHelpfulCounter)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(request.CreateRatingSubHelpfulRequest) error); ok {
+		r2 = rf(input)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateModerationRatingSubmission provides a mock function with given fields: id, status, reason, moderatedBy
+func (_m *RatingRepositoryMock) UpdateModerationRatingSubmission(id primitive.ObjectID, status string, reason string, moderatedBy string) error {
+	ret := _m.Mock.Called(id, status, reason, moderatedBy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, string, string, string) error); ok {
+		r0 = rf(id, status, reason, moderatedBy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 func (repository *RatingRepositoryMock) FindRatingSubmissionByUserIDAndRatingID(userId *string, ratingId string, sourceTransId string) (*entity.RatingSubmisson, error) {
 	arguments := repository.Mock.Called(userId, ratingId, sourceTransId)
 	if *userId == "629dce7bf1f26275e0d84826" && ratingId == "629dce7bf1f26275e0d84826" && sourceTransId == "629dce7bf1f26275e0d84826" {
@@ -357,12 +405,12 @@ func (_m *RatingRepositoryMock) CreateRating(input request.SaveRatingRequest) (*
 }
 
 // DeleteRating provides a mock function with given fields: id
-func (_m *RatingRepositoryMock) DeleteRating(id primitive.ObjectID) error {
-	ret := _m.Mock.Called(id)
+func (_m *RatingRepositoryMock) DeleteRating(id primitive.ObjectID, deletedBy string) error {
+	ret := _m.Mock.Called(id, deletedBy)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(primitive.ObjectID) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, string) error); ok {
+		r0 = rf(id, deletedBy)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -551,7 +599,7 @@ func (repository *RatingRepositoryMock) GetRatingFormulaById(id primitive.Object
 	}
 }
 
-func (repository *RatingRepositoryMock) DeleteRatingFormula(id primitive.ObjectID) error {
+func (repository *RatingRepositoryMock) DeleteRatingFormula(id primitive.ObjectID, deletedBy string) error {
 	objectId, _ := primitive.ObjectIDFromHex("629ec0836f3c2761ba2dc899")
 	if id == objectId {
 		return errors.New("error")
@@ -570,3 +618,595 @@ func (repository *RatingRepositoryMock) GetRatingFormulas(filter request.RatingF
 	}
 	return arguments.Get(0).([]entity.RatingFormulaCol), arguments.Get(1).(*base.Pagination), nil
 }
+
+// GetRatingAggregates provides a mock function with given fields: ratings
+func (_m *RatingRepositoryMock) GetRatingAggregates(ratings []entity.RatingsCol) (map[string]entity.RatingAggregateCol, error) {
+	ret := _m.Mock.Called(ratings)
+
+	var r0 map[string]entity.RatingAggregateCol
+	if rf, ok := ret.Get(0).(func([]entity.RatingsCol) map[string]entity.RatingAggregateCol); ok {
+		r0 = rf(ratings)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]entity.RatingAggregateCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]entity.RatingsCol) error); ok {
+		r1 = rf(ratings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RebuildRatingAggregate provides a mock function with given fields: rating
+func (_m *RatingRepositoryMock) RebuildRatingAggregate(rating entity.RatingsCol) (*entity.RatingAggregateCol, error) {
+	ret := _m.Mock.Called(rating)
+
+	var r0 *entity.RatingAggregateCol
+	if rf, ok := ret.Get(0).(func(entity.RatingsCol) *entity.RatingAggregateCol); ok {
+		r0 = rf(rating)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RatingAggregateCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(entity.RatingsCol) error); ok {
+		r1 = rf(rating)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGlobalSumCountBySourceType provides a mock function with given fields: sourceType, ratingTypeId
+func (_m *RatingRepositoryMock) GetGlobalSumCountBySourceType(sourceType string, ratingTypeId string) (float64, int64, error) {
+	ret := _m.Mock.Called(sourceType, ratingTypeId)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(string, string) float64); ok {
+		r0 = rf(sourceType, ratingTypeId)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(string, string) int64); ok {
+		r1 = rf(sourceType, ratingTypeId)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, string) error); ok {
+		r2 = rf(sourceType, ratingTypeId)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetDecayedSumCountByRatingId provides a mock function with given fields: ratingId, halfLife
+func (_m *RatingRepositoryMock) GetDecayedSumCountByRatingId(ratingId string, halfLife time.Duration) (float64, float64, error) {
+	ret := _m.Mock.Called(ratingId, halfLife)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(string, time.Duration) float64); ok {
+		r0 = rf(ratingId, halfLife)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 float64
+	if rf, ok := ret.Get(1).(func(string, time.Duration) float64); ok {
+		r1 = rf(ratingId, halfLife)
+	} else {
+		r1 = ret.Get(1).(float64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, time.Duration) error); ok {
+		r2 = rf(ratingId, halfLife)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// CreateRatingFormulaVersion provides a mock function with given fields: formula, note, actor
+func (_m *RatingRepositoryMock) CreateRatingFormulaVersion(formula entity.RatingFormulaCol, note string, actor string) (*entity.RatingFormulaVersionCol, error) {
+	ret := _m.Mock.Called(formula, note, actor)
+
+	var r0 *entity.RatingFormulaVersionCol
+	if rf, ok := ret.Get(0).(func(entity.RatingFormulaCol, string, string) *entity.RatingFormulaVersionCol); ok {
+		r0 = rf(formula, note, actor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RatingFormulaVersionCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(entity.RatingFormulaCol, string, string) error); ok {
+		r1 = rf(formula, note, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRatingFormulaVersions provides a mock function with given fields: formulaId, page, limit
+func (_m *RatingRepositoryMock) GetRatingFormulaVersions(formulaId string, page int, limit int64) ([]entity.RatingFormulaVersionCol, *base.Pagination, error) {
+	ret := _m.Mock.Called(formulaId, page, limit)
+
+	var r0 []entity.RatingFormulaVersionCol
+	if rf, ok := ret.Get(0).(func(string, int, int64) []entity.RatingFormulaVersionCol); ok {
+		r0 = rf(formulaId, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RatingFormulaVersionCol)
+		}
+	}
+
+	var r1 *base.Pagination
+	if rf, ok := ret.Get(1).(func(string, int, int64) *base.Pagination); ok {
+		r1 = rf(formulaId, page, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*base.Pagination)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, int, int64) error); ok {
+		r2 = rf(formulaId, page, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetRatingFormulaVersion provides a mock function with given fields: formulaId, version
+func (_m *RatingRepositoryMock) GetRatingFormulaVersion(formulaId string, version int) (*entity.RatingFormulaVersionCol, error) {
+	ret := _m.Mock.Called(formulaId, version)
+
+	var r0 *entity.RatingFormulaVersionCol
+	if rf, ok := ret.Get(0).(func(string, int) *entity.RatingFormulaVersionCol); ok {
+		r0 = rf(formulaId, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RatingFormulaVersionCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(formulaId, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ActivateRatingFormulaVersion provides a mock function with given fields: id, version, actor
+func (_m *RatingRepositoryMock) ActivateRatingFormulaVersion(id primitive.ObjectID, version int, actor string) error {
+	ret := _m.Mock.Called(id, version, actor)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, int, string) error); ok {
+		r0 = rf(id, version, actor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetRatingSubmissionIdsByOrderNumber provides a mock function with given fields: orderNumber
+func (_m *RatingRepositoryMock) GetRatingSubmissionIdsByOrderNumber(orderNumber string) ([]primitive.ObjectID, error) {
+	ret := _m.Mock.Called(orderNumber)
+
+	var r0 []primitive.ObjectID
+	if rf, ok := ret.Get(0).(func(string) []primitive.ObjectID); ok {
+		r0 = rf(orderNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]primitive.ObjectID)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(orderNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateRatingSubmissionUserProfile provides a mock function with given fields: userId, displayName, avatar
+func (_m *RatingRepositoryMock) UpdateRatingSubmissionUserProfile(userId string, displayName *string, avatar *string) error {
+	ret := _m.Mock.Called(userId, displayName, avatar)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *string, *string) error); ok {
+		r0 = rf(userId, displayName, avatar)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetReviewInvitationById provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) GetReviewInvitationById(id primitive.ObjectID) (*entity.ReviewInvitationCol, error) {
+	ret := _m.Mock.Called(id)
+
+	var r0 *entity.ReviewInvitationCol
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) *entity.ReviewInvitationCol); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ReviewInvitationCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubmissionTags provides a mock function with given fields: sourceType
+func (_m *RatingRepositoryMock) GetSubmissionTags(sourceType string) ([]entity.SubmissionTagCol, error) {
+	ret := _m.Mock.Called(sourceType)
+
+	var r0 []entity.SubmissionTagCol
+	if rf, ok := ret.Get(0).(func(string) []entity.SubmissionTagCol); ok {
+		r0 = rf(sourceType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.SubmissionTagCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(sourceType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubmissionTagById provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) GetSubmissionTagById(id primitive.ObjectID) (*entity.SubmissionTagCol, error) {
+	ret := _m.Mock.Called(id)
+
+	var r0 *entity.SubmissionTagCol
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) *entity.SubmissionTagCol); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.SubmissionTagCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSubmissionTag provides a mock function with given fields: tag
+func (_m *RatingRepositoryMock) CreateSubmissionTag(tag entity.SubmissionTagCol) (*entity.SubmissionTagCol, error) {
+	ret := _m.Mock.Called(tag)
+
+	var r0 *entity.SubmissionTagCol
+	if rf, ok := ret.Get(0).(func(entity.SubmissionTagCol) *entity.SubmissionTagCol); ok {
+		r0 = rf(tag)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.SubmissionTagCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(entity.SubmissionTagCol) error); ok {
+		r1 = rf(tag)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSubmissionTag provides a mock function with given fields: id, tag
+func (_m *RatingRepositoryMock) UpdateSubmissionTag(id primitive.ObjectID, tag entity.SubmissionTagCol) (*entity.SubmissionTagCol, error) {
+	ret := _m.Mock.Called(id, tag)
+
+	var r0 *entity.SubmissionTagCol
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID, entity.SubmissionTagCol) *entity.SubmissionTagCol); ok {
+		r0 = rf(id, tag)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.SubmissionTagCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID, entity.SubmissionTagCol) error); ok {
+		r1 = rf(id, tag)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteSubmissionTag provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) DeleteSubmissionTag(id primitive.ObjectID) error {
+	ret := _m.Mock.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReportRatingSubmission provides a mock function with given fields: report, threshold
+func (_m *RatingRepositoryMock) ReportRatingSubmission(report entity.RatingSubReportCol, threshold int) (bool, error) {
+	ret := _m.Mock.Called(report, threshold)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(entity.RatingSubReportCol, int) bool); ok {
+		r0 = rf(report, threshold)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(entity.RatingSubReportCol, int) error); ok {
+		r1 = rf(report, threshold)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRatingSubReportGroups provides a mock function with given fields: page, limit
+func (_m *RatingRepositoryMock) GetRatingSubReportGroups(page int, limit int64) ([]entity.RatingSubReportGroup, *base.Pagination, error) {
+	ret := _m.Mock.Called(page, limit)
+
+	var r0 []entity.RatingSubReportGroup
+	if rf, ok := ret.Get(0).(func(int, int64) []entity.RatingSubReportGroup); ok {
+		r0 = rf(page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RatingSubReportGroup)
+		}
+	}
+
+	var r1 *base.Pagination
+	if rf, ok := ret.Get(1).(func(int, int64) *base.Pagination); ok {
+		r1 = rf(page, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*base.Pagination)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int, int64) error); ok {
+		r2 = rf(page, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// CountRatingSubmissionsSince provides a mock function with given fields: field, value, since
+func (_m *RatingRepositoryMock) CountRatingSubmissionsSince(field string, value string, since time.Time) (int64, error) {
+	ret := _m.Mock.Called(field, value, since)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) int64); ok {
+		r0 = rf(field, value, since)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(field, value, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRatingSubRevisions provides a mock function with given fields: ratingSubmissionId
+func (_m *RatingRepositoryMock) GetRatingSubRevisions(ratingSubmissionId string) ([]entity.RatingSubRevisionCol, error) {
+	ret := _m.Mock.Called(ratingSubmissionId)
+
+	var r0 []entity.RatingSubRevisionCol
+	if rf, ok := ret.Get(0).(func(string) []entity.RatingSubRevisionCol); ok {
+		r0 = rf(ratingSubmissionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RatingSubRevisionCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ratingSubmissionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreRatingTypeNum provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) RestoreRatingTypeNum(id primitive.ObjectID) error {
+	ret := _m.Mock.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreSubmission provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) RestoreSubmission(id primitive.ObjectID) error {
+	ret := _m.Mock.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreRatingTypeLikert provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) RestoreRatingTypeLikert(id primitive.ObjectID) error {
+	ret := _m.Mock.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreRating provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) RestoreRating(id primitive.ObjectID) error {
+	ret := _m.Mock.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreRatingFormula provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) RestoreRatingFormula(id primitive.ObjectID) error {
+	ret := _m.Mock.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDeletedRatingById provides a mock function with given fields: id
+func (_m *RatingRepositoryMock) GetDeletedRatingById(id primitive.ObjectID) (*entity.RatingsCol, error) {
+	ret := _m.Mock.Called(id)
+
+	var r0 *entity.RatingsCol
+	if rf, ok := ret.Get(0).(func(primitive.ObjectID) *entity.RatingsCol); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RatingsCol)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(primitive.ObjectID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeSoftDeleted provides a mock function with given fields: collectionName, deletedBefore
+func (_m *RatingRepositoryMock) PurgeSoftDeleted(collectionName string, deletedBefore time.Time) (int64, error) {
+	ret := _m.Mock.Called(collectionName, deletedBefore)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, time.Time) int64); ok {
+		r0 = rf(collectionName, deletedBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(collectionName, deletedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MigrateRatingTypeLikertStatements provides a mock function with given fields:
+func (_m *RatingRepositoryMock) MigrateRatingTypeLikertStatements() (int64, error) {
+	ret := _m.Mock.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
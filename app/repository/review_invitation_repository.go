@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateReviewInvitations saves the invitations not created yet, returns the number of invitations created
+func (r *ratingMpRepo) CreateReviewInvitations(invitations []entity.ReviewInvitationCol) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	if len(invitations) == 0 {
+		return 0, nil
+	}
+	dateNow := time.Now().In(util.Loc)
+	models := make([]mongo.WriteModel, 0, len(invitations))
+	for _, invitation := range invitations {
+		filter := bson.D{
+			{Key: "order_number", Value: invitation.OrderNumber},
+			{Key: "user_id", Value: invitation.UserID},
+			{Key: "source_type", Value: invitation.SourceType},
+			{Key: "source_uid", Value: invitation.SourceUID},
+		}
+		data := bson.D{{Key: "$setOnInsert", Value: bson.D{
+			{Key: "store_uid", Value: invitation.StoreUID},
+			{Key: "rating_types", Value: invitation.RatingTypes},
+			{Key: "status", Value: entity.ReviewInvitationStatusPending},
+			{Key: "expired_at", Value: invitation.ExpiredAt},
+			{Key: "created_at", Value: dateNow},
+			{Key: "updated_at", Value: dateNow},
+		}}}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(data).SetUpsert(true))
+	}
+	result, err := r.db.Collection(entity.ReviewInvitationCol{}.CollectionName()).BulkWrite(ctx, models)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.UpsertedCount), nil
+}
+
+// CancelReviewInvitationsByOrderNumber cancels the pending invitations of the order, of every source when sourceUids is empty
+func (r *ratingMpRepo) CancelReviewInvitationsByOrderNumber(orderNumber string, sourceUids []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "order_number", Value: orderNumber},
+		{Key: "status", Value: entity.ReviewInvitationStatusPending},
+	}
+	if len(sourceUids) > 0 {
+		filter = append(filter, bson.E{Key: "source_uid", Value: bson.D{{Key: "$in", Value: sourceUids}}})
+	}
+	data := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: entity.ReviewInvitationStatusCancelled},
+		{Key: "updated_at", Value: time.Now().In(util.Loc)},
+	}}}
+	_, err := r.db.Collection(entity.ReviewInvitationCol{}.CollectionName()).UpdateMany(ctx, filter, data)
+	return err
+}
+
+// GetReviewInvitationsByOrderNumber returns every invitation of the user for the order
+func (r *ratingMpRepo) GetReviewInvitationsByOrderNumber(orderNumber, userId string) ([]entity.ReviewInvitationCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	results := []entity.ReviewInvitationCol{}
+	filter := bson.D{
+		{Key: "order_number", Value: orderNumber},
+		{Key: "user_id", Value: userId},
+	}
+	cursor, err := r.db.Collection(entity.ReviewInvitationCol{}.CollectionName()).Find(ctx, filter, &options.FindOptions{
+		Sort: bson.D{{Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetPendingReviewInvitations returns the invitations the user can still redeem, the ones expiring first on top
+func (r *ratingMpRepo) GetPendingReviewInvitations(userId string, page int, limit int64) ([]entity.ReviewInvitationCol, *base.Pagination, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	results := []entity.ReviewInvitationCol{}
+	collectionName := entity.ReviewInvitationCol{}.CollectionName()
+	filter := bson.D{
+		{Key: "user_id", Value: userId},
+		{Key: "status", Value: entity.ReviewInvitationStatusPending},
+		{Key: "expired_at", Value: bson.D{{Key: "$gt", Value: time.Now().In(util.Loc)}}},
+	}
+	skip := int64(page)*limit - limit
+	cursor, err := r.db.Collection(collectionName).Find(ctx, filter, &options.FindOptions{
+		Sort:  bson.D{{Key: "expired_at", Value: 1}},
+		Limit: &limit,
+		Skip:  &skip,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, nil, err
+	}
+	pagination := getPaginationMp(r.db, page, limit, results, collectionName, filter)
+	return results, pagination, nil
+}
+
+func (r *ratingMpRepo) GetReviewInvitationById(id primitive.ObjectID) (*entity.ReviewInvitationCol, error) {
+	return getReviewInvitationById(r.db, id)
+}
+
+func (r *ratingRepo) GetReviewInvitationById(id primitive.ObjectID) (*entity.ReviewInvitationCol, error) {
+	return getReviewInvitationById(r.db, id)
+}
+
+func getReviewInvitationById(db *mongo.Database, id primitive.ObjectID) (*entity.ReviewInvitationCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var result entity.ReviewInvitationCol
+	err := db.Collection(entity.ReviewInvitationCol{}.CollectionName()).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// redeemReviewInvitation marks the invitation submitted within the transaction saving the submission,
+// it returns mongo.ErrNoDocuments when the invitation is not pending anymore or expired
+func redeemReviewInvitation(ctx context.Context, db *mongo.Database, id primitive.ObjectID, submissionId string) error {
+	dateNow := time.Now().In(util.Loc)
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "status", Value: entity.ReviewInvitationStatusPending},
+		{Key: "expired_at", Value: bson.D{{Key: "$gt", Value: dateNow}}},
+	}
+	data := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: entity.ReviewInvitationStatusSubmitted},
+		{Key: "submission_id", Value: submissionId},
+		{Key: "submitted_at", Value: dateNow},
+		{Key: "updated_at", Value: dateNow},
+	}}}
+	result, err := db.Collection(entity.ReviewInvitationCol{}.CollectionName()).UpdateOne(ctx, filter, data)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// GetReviewInvitationByOrderLine returns the invitation of the user to review the source of the order
+func (r *ratingMpRepo) GetReviewInvitationByOrderLine(orderNumber, userId, sourceType, sourceUid string) (*entity.ReviewInvitationCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var result entity.ReviewInvitationCol
+	filter := bson.D{
+		{Key: "order_number", Value: orderNumber},
+		{Key: "user_id", Value: userId},
+		{Key: "source_type", Value: sourceType},
+		{Key: "source_uid", Value: sourceUid},
+	}
+	err := r.db.Collection(entity.ReviewInvitationCol{}.CollectionName()).FindOne(ctx, filter).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bsonDeleted matches the soft deleted rows, only restore and the purge job read them
+var bsonDeleted = bson.D{{Key: "deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}}}
+
+func softDeleteUpdate(deletedBy string) bson.D {
+	return bson.D{{Key: "$set", Value: bson.D{
+		{Key: "deleted_at", Value: time.Now().In(util.Loc)},
+		{Key: "deleted_by", Value: deletedBy},
+	}}}
+}
+
+var restoreUpdate = bson.D{{Key: "$unset", Value: bson.D{
+	{Key: "deleted_at", Value: ""},
+	{Key: "deleted_by", Value: ""},
+}}}
+
+// softDeleteById sets deleted_at and deleted_by of the row, mongo.ErrNoDocuments when it does not exist or is already deleted
+func softDeleteById(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, deletedBy string) error {
+	result, err := collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}, notDeleted}, softDeleteUpdate(deletedBy))
+	if err == nil && result.MatchedCount == 0 {
+		err = mongo.ErrNoDocuments
+	}
+	return err
+}
+
+// restoreById unsets deleted_at and deleted_by of the row, mongo.ErrNoDocuments when it is not soft deleted
+func restoreById(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID) error {
+	filter := append(bson.D{{Key: "_id", Value: id}}, bsonDeleted...)
+	result, err := collection.UpdateOne(ctx, filter, restoreUpdate)
+	if err == nil && result.MatchedCount == 0 {
+		err = mongo.ErrNoDocuments
+	}
+	return err
+}
+
+func (r *ratingRepo) RestoreRatingTypeNum(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return restoreById(ctx, r.db.Collection(entity.RatingTypesNumCol{}.CollectionName()), id)
+}
+
+func (r *ratingRepo) RestoreRatingTypeLikert(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return restoreById(ctx, r.db.Collection(entity.RatingTypesLikertCol{}.CollectionName()), id)
+}
+
+func (r *ratingRepo) RestoreRatingFormula(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return restoreById(ctx, r.db.Collection(entity.RatingFormulaCol{}.CollectionName()), id)
+}
+
+func (r *ratingRepo) RestoreRating(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return restoreById(ctx, r.db.Collection(entity.RatingsCol{}.CollectionName()), id)
+}
+
+// GetDeletedRatingById returns the rating when it is soft deleted
+func (r *ratingRepo) GetDeletedRatingById(id primitive.ObjectID) (*entity.RatingsCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var rating entity.RatingsCol
+	filter := append(bson.D{{Key: "_id", Value: id}}, bsonDeleted...)
+	err := r.db.Collection(entity.RatingsCol{}.CollectionName()).FindOne(ctx, filter).Decode(&rating)
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// RestoreSubmission restores the soft deleted submission, it is part of the aggregate of its rating again
+func (r *ratingRepo) RestoreSubmission(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	filter := append(bson.D{{Key: "_id", Value: id}}, bsonDeleted...)
+	return r.db.Client().UseSession(ctx, func(sessionContext mongo.SessionContext) error {
+		err := sessionContext.StartTransaction()
+		if err != nil {
+			return err
+		}
+		var current entity.RatingSubmisson
+		err = r.db.Collection(entity.RatingSubmisson{}.CollectionName()).FindOneAndUpdate(sessionContext, filter, restoreUpdate,
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&current)
+		if err == nil {
+			err = r.applyRatingAggregateDelta(sessionContext, nil, &current)
+		}
+		if err != nil {
+			sessionContext.AbortTransaction(sessionContext)
+			return err
+		}
+		return sessionContext.CommitTransaction(sessionContext)
+	})
+}
+
+// SoftDeleteRatingSubmission soft deletes the submission and returns it as it was before the delete
+func (r *ratingMpRepo) SoftDeleteRatingSubmission(id primitive.ObjectID, deletedBy string) (*entity.RatingSubmissionMp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var previous entity.RatingSubmissionMp
+	err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).
+		FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: id}, notDeleted}, softDeleteUpdate(deletedBy)).Decode(&previous)
+	if err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
+// RestoreRatingSubmission restores the soft deleted submission and returns it restored
+func (r *ratingMpRepo) RestoreRatingSubmission(id primitive.ObjectID) (*entity.RatingSubmissionMp, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var current entity.RatingSubmissionMp
+	filter := append(bson.D{{Key: "_id", Value: id}}, bsonDeleted...)
+	err := r.db.Collection(entity.RatingSubmissionMp{}.CollectionName()).
+		FindOneAndUpdate(ctx, filter, restoreUpdate, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&current)
+	if err != nil {
+		return nil, err
+	}
+	return &current, nil
+}
+
+// PurgeSoftDeleted hard deletes the rows of the collection soft deleted before the date, returns the number of rows deleted.
+// The votes, reports and revisions of purged submissions are deleted with them.
+func (r *ratingRepo) PurgeSoftDeleted(collectionName string, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	collection := r.db.Collection(collectionName)
+	filter := bson.D{{Key: "deleted_at", Value: bson.D{{Key: "$lt", Value: deletedBefore}}}}
+	isSubmission := collectionName == entity.RatingSubmisson{}.CollectionName() || collectionName == entity.RatingSubmissionMp{}.CollectionName()
+	if !isSubmission {
+		result, err := collection.DeleteMany(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		return result.DeletedCount, nil
+	}
+
+	ids, err := collection.Distinct(ctx, "_id", filter)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	submissionIds := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if objectId, ok := id.(primitive.ObjectID); ok {
+			submissionIds = append(submissionIds, objectId.Hex())
+		}
+	}
+	relatedFilter := bson.D{{Key: "rating_submission_id", Value: bson.D{{Key: "$in", Value: submissionIds}}}}
+	for _, related := range []string{
+		entity.RatingSubHelpfulCol{}.CollectionName(),
+		entity.RatingSubReportCol{}.CollectionName(),
+		entity.RatingSubRevisionCol{}.CollectionName(),
+	} {
+		if _, err = r.db.Collection(related).DeleteMany(ctx, relatedFilter); err != nil {
+			return 0, err
+		}
+	}
+	result, err := collection.DeleteMany(ctx, append(bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}, filter...))
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type sourceTypeRepo struct {
+	db *mongo.Database
+}
+
+type SourceTypeRepository interface {
+	GetSourceTypes() ([]entity.SourceTypeCol, error)
+	GetSourceTypeById(id primitive.ObjectID) (*entity.SourceTypeCol, error)
+	GetSourceTypeByName(name string) (*entity.SourceTypeCol, error)
+	CreateSourceType(sourceType entity.SourceTypeCol) (*entity.SourceTypeCol, error)
+	SeedSourceTypes(sourceTypes []entity.SourceTypeCol) error
+	SeedDimensionRatingTypes(ratingTypes []entity.RatingTypesNumCol) error
+	UpdateSourceType(id primitive.ObjectID, sourceType entity.SourceTypeCol) (*entity.SourceTypeCol, error)
+	DeleteSourceType(id primitive.ObjectID) error
+	CountRatingsBySourceType(name string) (int64, error)
+}
+
+func NewSourceTypeRepository(db *mongo.Database) SourceTypeRepository {
+	return &sourceTypeRepo{db}
+}
+
+func (r *sourceTypeRepo) GetSourceTypes() ([]entity.SourceTypeCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	results := []entity.SourceTypeCol{}
+	cursor, err := r.db.Collection(entity.SourceTypeCol{}.CollectionName()).
+		Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *sourceTypeRepo) GetSourceTypeById(id primitive.ObjectID) (*entity.SourceTypeCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var sourceType entity.SourceTypeCol
+	err := r.db.Collection(sourceType.CollectionName()).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&sourceType)
+	if err != nil {
+		return nil, err
+	}
+	return &sourceType, nil
+}
+
+func (r *sourceTypeRepo) GetSourceTypeByName(name string) (*entity.SourceTypeCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var sourceType entity.SourceTypeCol
+	err := r.db.Collection(sourceType.CollectionName()).FindOne(ctx, bson.D{{Key: "name", Value: name}}).Decode(&sourceType)
+	if err != nil {
+		return nil, err
+	}
+	return &sourceType, nil
+}
+
+func (r *sourceTypeRepo) CreateSourceType(sourceType entity.SourceTypeCol) (*entity.SourceTypeCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	sourceType.CreatedAt = time.Now().In(util.Loc)
+	sourceType.UpdatedAt = sourceType.CreatedAt
+	result, err := r.db.Collection(sourceType.CollectionName()).InsertOne(ctx, sourceType)
+	if err != nil {
+		return nil, err
+	}
+	sourceType.ID = result.InsertedID.(primitive.ObjectID)
+	return &sourceType, nil
+}
+
+// SeedSourceTypes saves the source types the registry is seeded with, once: the seeding is recorded in migrationCol
+// in the same transaction and the source types deleted afterwards are not seeded again. A registry stored before
+// the record existed is not seeded either. The duplicate key of a replica seeding concurrently means it is seeded.
+func (r *sourceTypeRepo) SeedSourceTypes(sourceTypes []entity.SourceTypeCol) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	session, err := r.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	dateNow := time.Now().In(util.Loc)
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		migration := entity.MigrationCol{ID: entity.MigrationSeedSourceTypes, CreatedAt: dateNow}
+		if _, err := r.db.Collection(migration.CollectionName()).InsertOne(sessionContext, migration); err != nil {
+			return nil, err
+		}
+		sourceTypeColl := r.db.Collection(entity.SourceTypeCol{}.CollectionName())
+		count, err := sourceTypeColl.CountDocuments(sessionContext, bson.D{})
+		if err != nil || count > 0 {
+			return nil, err
+		}
+		docs := make([]interface{}, 0, len(sourceTypes))
+		for _, sourceType := range sourceTypes {
+			sourceType.CreatedAt = dateNow
+			sourceType.UpdatedAt = dateNow
+			docs = append(docs, sourceType)
+		}
+		_, err = sourceTypeColl.InsertMany(sessionContext, docs)
+		return nil, err
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// UpdateSourceType replaces the fields of the source type and returns it updated
+func (r *sourceTypeRepo) UpdateSourceType(id primitive.ObjectID, sourceType entity.SourceTypeCol) (*entity.SourceTypeCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	data := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "name", Value: sourceType.Name},
+		{Key: "rating_types", Value: sourceType.RatingTypes},
+		{Key: "is_marketplace", Value: sourceType.IsMarketplace},
+		{Key: "scale", Value: sourceType.Scale},
+		{Key: "allowed_values", Value: sourceType.AllowedValues},
+		{Key: "default_formula", Value: sourceType.DefaultFormula},
+		{Key: "final_rating_topic", Value: sourceType.FinalRatingTopic},
+		{Key: "store_source_type", Value: sourceType.StoreSourceType},
+		{Key: "dimensions", Value: sourceType.Dimensions},
+		{Key: "allow_comment", Value: sourceType.AllowComment},
+		{Key: "allow_media", Value: sourceType.AllowMedia},
+		{Key: "updated_at", Value: time.Now().In(util.Loc)},
+	}}}
+	var updated entity.SourceTypeCol
+	err := r.db.Collection(sourceType.CollectionName()).FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: id}}, data,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// SeedDimensionRatingTypes saves the numeric rating types of the dimensions, once like SeedSourceTypes:
+// a rating type whose type is already saved is kept as it is
+func (r *sourceTypeRepo) SeedDimensionRatingTypes(ratingTypes []entity.RatingTypesNumCol) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	session, err := r.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	dateNow := time.Now().In(util.Loc)
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		migration := entity.MigrationCol{ID: entity.MigrationSeedDimensionRatingTypes, CreatedAt: dateNow}
+		if _, err := r.db.Collection(migration.CollectionName()).InsertOne(sessionContext, migration); err != nil {
+			return nil, err
+		}
+		ratingTypeColl := r.db.Collection(entity.RatingTypesNumCol{}.CollectionName())
+		for _, ratingType := range ratingTypes {
+			ratingType.CreatedAt = dateNow
+			ratingType.UpdatedAt = dateNow
+			_, err := ratingTypeColl.UpdateOne(sessionContext, bson.D{{Key: "type", Value: ratingType.Type}},
+				bson.D{{Key: "$setOnInsert", Value: ratingType}}, options.Update().SetUpsert(true))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *sourceTypeRepo) DeleteSourceType(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	result, err := r.db.Collection(entity.SourceTypeCol{}.CollectionName()).DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err == nil && result.DeletedCount == 0 {
+		err = mongo.ErrNoDocuments
+	}
+	return err
+}
+
+// CountRatingsBySourceType counts the ratings and the marketplace submissions of the source type, the deleted ones too
+func (r *sourceTypeRepo) CountRatingsBySourceType(name string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	filter := bson.D{{Key: "source_type", Value: name}}
+	var total int64
+	for _, collectionName := range []string{entity.RatingsCol{}.CollectionName(), entity.RatingSubmissionMp{}.CollectionName()} {
+		count, err := r.db.Collection(collectionName).CountDocuments(ctx, filter, options.Count().SetLimit(1))
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *ratingRepo) GetSubmissionTags(sourceType string) ([]entity.SubmissionTagCol, error) {
+	return getSubmissionTags(r.db, sourceType)
+}
+
+func (r *ratingMpRepo) GetSubmissionTags(sourceType string) ([]entity.SubmissionTagCol, error) {
+	return getSubmissionTags(r.db, sourceType)
+}
+
+// getSubmissionTags returns the tags of the catalogue of sourceType, of every source type when it is empty
+func getSubmissionTags(db *mongo.Database, sourceType string) ([]entity.SubmissionTagCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	filter := bson.D{}
+	if sourceType != "" {
+		filter = bson.D{{Key: "source_type", Value: sourceType}}
+	}
+	results := []entity.SubmissionTagCol{}
+	cursor, err := db.Collection(entity.SubmissionTagCol{}.CollectionName()).
+		Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "source_type", Value: 1}, {Key: "min_value", Value: 1}, {Key: "key", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *ratingRepo) GetSubmissionTagById(id primitive.ObjectID) (*entity.SubmissionTagCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var tag entity.SubmissionTagCol
+	err := r.db.Collection(tag.CollectionName()).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&tag)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *ratingRepo) CreateSubmissionTag(tag entity.SubmissionTagCol) (*entity.SubmissionTagCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	tag.CreatedAt = time.Now().In(util.Loc)
+	tag.UpdatedAt = tag.CreatedAt
+	result, err := r.db.Collection(tag.CollectionName()).InsertOne(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	tag.ID = result.InsertedID.(primitive.ObjectID)
+	return &tag, nil
+}
+
+// UpdateSubmissionTag replaces the label and the value range of the tag, the source type and the key referenced
+// by the submissions are kept
+func (r *ratingRepo) UpdateSubmissionTag(id primitive.ObjectID, tag entity.SubmissionTagCol) (*entity.SubmissionTagCol, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	data := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "label", Value: tag.Label},
+		{Key: "label_i18n", Value: tag.LabelI18n},
+		{Key: "min_value", Value: tag.MinValue},
+		{Key: "max_value", Value: tag.MaxValue},
+		{Key: "updated_at", Value: time.Now().In(util.Loc)},
+	}}}
+	var updated entity.SubmissionTagCol
+	err := r.db.Collection(tag.CollectionName()).FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: id}}, data,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteSubmissionTag removes the tag from the catalogue, the submissions keep its key
+func (r *ratingRepo) DeleteSubmissionTag(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	result, err := r.db.Collection(entity.SubmissionTagCol{}.CollectionName()).DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err == nil && result.DeletedCount == 0 {
+		err = mongo.ErrNoDocuments
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package repositorytest
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestScheduleFinalRating(t *testing.T) {
+	runMockMongo(t, "postpones the pending message of the source", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		due := time.Now().Add(5 * time.Second)
+
+		err := repository.NewRatingMpRepository(mt.DB).ScheduleFinalRating("store", "store-1", due)
+
+		assert.Nil(t, err)
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 1)
+		assert.Equal(t, entity.OutboxCol{}.CollectionName(), updates[0].Lookup("update").StringValue())
+		statements, _ := updates[0].Lookup("updates").Array().Values()
+		statement := statements[0].Document()
+		assert.True(t, statement.Lookup("upsert").Boolean())
+
+		filter := statement.Lookup("q").Document()
+		assert.Equal(t, entity.OutboxTargetFinalRating, filter.Lookup("target").StringValue())
+		assert.Equal(t, entity.OutboxStatusPending, filter.Lookup("status").StringValue())
+		assert.Equal(t, "store", filter.Lookup("payload.source_type").StringValue())
+		assert.Equal(t, "store-1", filter.Lookup("payload.source_uid").StringValue())
+		nextAttemptAt := statement.Lookup("u", "$set", "next_attempt_at").Time()
+		assert.WithinDuration(t, due, nextAttemptAt, time.Millisecond)
+	})
+}
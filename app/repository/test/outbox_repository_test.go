@@ -0,0 +1,67 @@
+package repositorytest
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClaimOutboxMessageReclaimCountsAttempt(t *testing.T) {
+	runMockMongo(t, "reclaim", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(findAndModifyResponse(bson.D{
+			{Key: "_id", Value: id}, {Key: "status", Value: entity.OutboxStatusProcessing}, {Key: "attempts", Value: int32(3)},
+		}))
+
+		msg, err := repository.NewOutboxRepository(mt.DB).ClaimOutboxMessage(time.Minute)
+
+		assert.Nil(t, err)
+		assert.Equal(t, id, msg.ID)
+		assert.Equal(t, 3, msg.Attempts)
+		commands := startedCommands(mt, "findAndModify")
+		assert.Len(t, commands, 1)
+		assert.Equal(t, entity.OutboxStatusProcessing, commands[0].Lookup("query", "status").StringValue())
+		assert.Equal(t, int32(1), commands[0].Lookup("update", "$inc", "attempts").Int32())
+	})
+}
+
+func TestClaimOutboxMessagePending(t *testing.T) {
+	runMockMongo(t, "pending", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}},
+			findAndModifyResponse(bson.D{{Key: "_id", Value: id}, {Key: "status", Value: entity.OutboxStatusProcessing}}),
+		)
+
+		msg, err := repository.NewOutboxRepository(mt.DB).ClaimOutboxMessage(time.Minute)
+
+		assert.Nil(t, err)
+		assert.Equal(t, id, msg.ID)
+		commands := startedCommands(mt, "findAndModify")
+		assert.Len(t, commands, 2)
+		assert.Equal(t, entity.OutboxStatusPending, commands[1].Lookup("query", "status").StringValue())
+		// claiming a pending message is not an attempt yet, the delivery counts it
+		_, err = commands[1].LookupErr("update", "$inc")
+		assert.Error(t, err)
+	})
+}
+
+func TestClaimOutboxMessageNothingDue(t *testing.T) {
+	runMockMongo(t, "nothing due", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}},
+		)
+
+		msg, err := repository.NewOutboxRepository(mt.DB).ClaimOutboxMessage(time.Minute)
+
+		assert.Nil(t, err)
+		assert.Nil(t, msg)
+	})
+}
@@ -0,0 +1,72 @@
+package repositorytest
+
+import (
+	"go-klikdokter/app/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestDeleteSubmissionRebuildsMissingAggregate(t *testing.T) {
+	runMockMongo(t, "delete", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		ratingId := primitive.NewObjectID()
+		mt.AddMockResponses(
+			findAndModifyResponse(bson.D{{Key: "_id", Value: id}, {Key: "rating_id", Value: ratingId.Hex()}, {Key: "value", Value: "4"}}),
+			// the rating was stored before the aggregates existed
+			cursorResponse("ratingAggregateCol"),
+			cursorResponse("ratingsCol", bson.D{{Key: "_id", Value: ratingId}, {Key: "source_type", Value: "doctor"}, {Key: "source_uid", Value: "1"}}),
+			// the submissions left once the deleted one is out, read in the transaction
+			cursorResponse("ratingSubCol",
+				bson.D{{Key: "rating_id", Value: ratingId.Hex()}, {Key: "value", Value: "5"}},
+				bson.D{{Key: "rating_id", Value: ratingId.Hex()}, {Key: "value", Value: "3"}},
+			),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := repository.NewRatingRepository(mt.DB).DeleteSubmission(id, "admin")
+
+		assert.Nil(t, err)
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 1)
+		update := updates[0].Lookup("updates").Array().Index(0).Value().Document()
+		assert.True(t, update.Lookup("upsert").Boolean())
+		_, err = update.LookupErr("u", "$inc")
+		assert.Error(t, err, "a delta must not be upserted as the aggregate")
+		assert.Equal(t, float64(8), update.Lookup("u", "sum").Double())
+		assert.Equal(t, int64(2), update.Lookup("u", "count").Int64())
+		assert.Equal(t, "doctor", update.Lookup("u", "source_type").StringValue())
+		for _, find := range startedCommands(mt, "find") {
+			_, err = find.LookupErr("lsid")
+			assert.Nil(t, err, "the rebuild must read in the transaction of the delete")
+		}
+	})
+}
+
+func TestDeleteSubmissionIncrementsExistingAggregate(t *testing.T) {
+	runMockMongo(t, "delete", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		ratingId := primitive.NewObjectID()
+		mt.AddMockResponses(
+			findAndModifyResponse(bson.D{{Key: "_id", Value: id}, {Key: "rating_id", Value: ratingId.Hex()}, {Key: "value", Value: "4"}}),
+			cursorResponse("ratingAggregateCol", bson.D{{Key: "_id", Value: primitive.NewObjectID()}}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := repository.NewRatingRepository(mt.DB).DeleteSubmission(id, "admin")
+
+		assert.Nil(t, err)
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 1)
+		update := updates[0].Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, float64(-4), update.Lookup("u", "$inc", "sum").Double())
+		assert.Equal(t, int64(-1), update.Lookup("u", "$inc", "count").Int64())
+		_, err = update.LookupErr("upsert")
+		assert.Error(t, err)
+	})
+}
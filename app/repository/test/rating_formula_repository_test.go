@@ -0,0 +1,56 @@
+package repositorytest
+
+import (
+	"go-klikdokter/app/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestGetGlobalSumCountBySourceTypeOfRatingType(t *testing.T) {
+	runMockMongo(t, "legacy ratings of the rating type", func(mt *mtest.T) {
+		ratingId := primitive.NewObjectID()
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "values", Value: bson.A{ratingId}}},
+			cursorResponse("ratingAggregateCol", bson.D{{Key: "sum", Value: 80.0}, {Key: "count", Value: int64(20)}}),
+		)
+
+		sum, count, err := repository.NewRatingRepository(mt.DB).GetGlobalSumCountBySourceType("doctor", "62c3e57b457ed515928c3712")
+
+		assert.Nil(t, err)
+		assert.Equal(t, float64(80), sum)
+		assert.Equal(t, int64(20), count)
+		distinct := startedCommands(mt, "distinct")
+		assert.Len(t, distinct, 1)
+		assert.Equal(t, "62c3e57b457ed515928c3712", distinct[0].Lookup("query", "rating_type_id").StringValue())
+		aggregates := startedCommands(mt, "aggregate")
+		assert.Len(t, aggregates, 1)
+		match := aggregates[0].Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+		ratingIds, _ := match.Lookup("rating_id", "$in").Array().Values()
+		assert.Len(t, ratingIds, 1)
+		assert.Equal(t, ratingId.Hex(), ratingIds[0].StringValue())
+	})
+
+	runMockMongo(t, "marketplace submissions of the rating type", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse("ratingSubMpCol", bson.D{{Key: "sum", Value: 45.0}, {Key: "count", Value: 10.0}}))
+
+		sum, count, err := repository.NewRatingMpRepository(mt.DB).GetGlobalSumCountBySourceType("product", "62c3e57b457ed515928c3713")
+
+		assert.Nil(t, err)
+		assert.Equal(t, float64(45), sum)
+		assert.Equal(t, int64(10), count)
+		aggregates := startedCommands(mt, "aggregate")
+		assert.Len(t, aggregates, 1)
+		conditions, _ := aggregates[0].Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match", "$and").Array().Values()
+		found := false
+		for _, condition := range conditions {
+			if value, err := condition.Document().LookupErr("rating_type_id"); err == nil {
+				found = value.StringValue() == "62c3e57b457ed515928c3713"
+			}
+		}
+		assert.True(t, found, "the submissions must be filtered by the rating type of the formula")
+	})
+}
@@ -0,0 +1,80 @@
+package repositorytest
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// assertInTransaction checks every write was sent in the transaction committed last
+func assertInTransaction(t *testing.T, mt *mtest.T, names ...string) {
+	commits := startedCommands(mt, "commitTransaction")
+	assert.Len(t, commits, 1)
+	txnNumber := commits[0].Lookup("txnNumber").Int64()
+	for _, name := range names {
+		commands := startedCommands(mt, name)
+		assert.NotEmpty(t, commands, name)
+		for _, command := range commands {
+			number, ok := command.Lookup("txnNumber").Int64OK()
+			assert.True(t, ok && number == txnNumber, name+" must be sent in the transaction")
+		}
+	}
+}
+
+func TestReportRatingSubmissionHidesInTransaction(t *testing.T) {
+	runMockMongo(t, "report", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		ratingId := primitive.NewObjectID()
+		mt.AddMockResponses(
+			findAndModifyResponse(bson.D{
+				{Key: "_id", Value: id}, {Key: "rating_id", Value: ratingId.Hex()}, {Key: "value", Value: "4"},
+				{Key: "report_counter", Value: int64(2)}, {Key: "moderation_status", Value: entity.ModerationStatusApproved},
+			}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			cursorResponse("ratingAggregateCol", bson.D{{Key: "_id", Value: primitive.NewObjectID()}}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		hidden, err := repository.NewRatingRepository(mt.DB).ReportRatingSubmission(entity.RatingSubReportCol{
+			RatingSubmissionID: id.Hex(), UserIDLegacy: "1",
+		}, 3)
+
+		assert.Nil(t, err)
+		assert.True(t, hidden)
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 2)
+		inc := updates[1].Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$inc").Document()
+		assert.Equal(t, int64(-1), inc.Lookup("count").Int64())
+		assertInTransaction(t, mt, "findAndModify", "insert", "update")
+	})
+}
+
+func TestUpdateModerationRatingSubmissionInTransaction(t *testing.T) {
+	runMockMongo(t, "moderation", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		ratingId := primitive.NewObjectID()
+		mt.AddMockResponses(
+			findAndModifyResponse(bson.D{
+				{Key: "_id", Value: id}, {Key: "rating_id", Value: ratingId.Hex()}, {Key: "value", Value: "4"},
+				{Key: "moderation_status", Value: entity.ModerationStatusPending},
+			}),
+			cursorResponse("ratingAggregateCol", bson.D{{Key: "_id", Value: primitive.NewObjectID()}}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := repository.NewRatingRepository(mt.DB).UpdateModerationRatingSubmission(id, entity.ModerationStatusApproved, "", "admin")
+
+		assert.Nil(t, err)
+		inc := startedCommands(mt, "update")[0].Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$inc").Document()
+		assert.Equal(t, int64(1), inc.Lookup("count").Int64())
+		assertInTransaction(t, mt, "findAndModify", "update")
+	})
+}
@@ -0,0 +1,100 @@
+package repositorytest
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestEditRatingSubmissionMpKeepsConcurrentCounters(t *testing.T) {
+	runMockMongo(t, "edit", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		comment := "bagus"
+		// votes and a report landed after the service read the submission with its counters at 0
+		mt.AddMockResponses(
+			findAndModifyResponse(bson.D{
+				{Key: "_id", Value: id}, {Key: "value", Value: 1}, {Key: "comment", Value: comment},
+				{Key: "like_counter", Value: 7}, {Key: "not_helpful_counter", Value: 2}, {Key: "report_counter", Value: 3},
+				{Key: "moderation_status", Value: entity.ModerationStatusPending},
+			}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+		editedAt := time.Now()
+		err := repository.NewRatingMpRepository(mt.DB).EditRatingSubmission(entity.RatingSubmissionMp{
+			ID: id, Value: 5, Comment: &comment, EditCounter: 1, EditedAt: &editedAt, EditedBy: "34343432",
+			ModerationStatus: entity.ModerationStatusApproved,
+		}, id, nil)
+
+		assert.Nil(t, err)
+		commands := startedCommands(mt, "findAndModify")
+		assert.Len(t, commands, 1)
+		set := commands[0].Lookup("update", "$set").Document()
+		for _, field := range []string{"like_counter", "not_helpful_counter", "report_counter", "moderation_status", "edit_counter"} {
+			_, err := set.LookupErr(field)
+			assert.Error(t, err, field+" must not be set by an edit")
+		}
+		assert.Equal(t, int32(5), set.Lookup("value").Int32())
+		assert.Equal(t, "bagus", set.Lookup("comment_search").StringValue())
+		assert.Equal(t, int32(1), commands[0].Lookup("update", "$inc", "edit_counter").Int32())
+		// the comment did not change, the submission hidden by the reports stays hidden
+		assert.Empty(t, startedCommands(mt, "update"))
+		assert.Len(t, startedCommands(mt, "insert"), 1)
+	})
+}
+
+func TestEditRatingSubmissionMpModeratesChangedComment(t *testing.T) {
+	runMockMongo(t, "edit", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(
+			findAndModifyResponse(bson.D{{Key: "_id", Value: id}, {Key: "value", Value: 1}, {Key: "comment", Value: "jelek"}}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+		comment := "hubungi 081234567890"
+		err := repository.NewRatingMpRepository(mt.DB).EditRatingSubmission(entity.RatingSubmissionMp{
+			ID: id, Value: 5, Comment: &comment, ModerationStatus: entity.ModerationStatusPending, ModerationFlags: []string{"pii_phone"},
+		}, id, nil)
+
+		assert.Nil(t, err)
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 1)
+		set := updates[0].Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$set").Document()
+		assert.Equal(t, entity.ModerationStatusPending, set.Lookup("moderation_status").StringValue())
+	})
+}
+
+func TestEditRatingSubmissionMpQueuesOutbox(t *testing.T) {
+	runMockMongo(t, "edit", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		comment := "bagus"
+		mt.AddMockResponses(
+			findAndModifyResponse(bson.D{{Key: "_id", Value: id}, {Key: "value", Value: 1}, {Key: "comment", Value: comment}}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+		err := repository.NewRatingMpRepository(mt.DB).EditRatingSubmission(entity.RatingSubmissionMp{
+			ID: id, Value: 5, Comment: &comment,
+		}, id, []entity.OutboxCol{{Target: entity.OutboxTargetMediaHouseKeeping, Payload: entity.OutboxPayload{MediaUID: "media-1"}}})
+
+		assert.Nil(t, err)
+		inserts := startedCommands(mt, "insert")
+		assert.Len(t, inserts, 2)
+		assert.Equal(t, entity.OutboxCol{}.CollectionName(), inserts[1].Lookup("insert").StringValue())
+		doc := inserts[1].Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, entity.OutboxTargetMediaHouseKeeping, doc.Lookup("target").StringValue())
+		assert.Equal(t, id.Hex(), doc.Lookup("submission_id").StringValue())
+		assert.Equal(t, entity.OutboxStatusPending, doc.Lookup("status").StringValue())
+		// the outbox is written in the transaction of the edit
+		_, err = inserts[1].LookupErr("txnNumber")
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,40 @@
+package repositorytest
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMigrateRatingTypeLikertStatements(t *testing.T) {
+	runMockMongo(t, "keys the legacy statements by their field", func(mt *mtest.T) {
+		collectionName := entity.RatingTypesLikertCol{}.CollectionName()
+		mt.AddMockResponses(
+			cursorResponse(collectionName, bson.D{
+				{Key: "_id", Value: primitive.NewObjectID()},
+				{Key: "statement_01", Value: "Friendly"},
+				{Key: "statement_03", Value: "On time"},
+			}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		total, err := repository.NewRatingRepository(mt.DB).MigrateRatingTypeLikertStatements()
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), total)
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 1)
+		statements, _ := updates[0].Lookup("updates").Array().Values()
+		migrated, _ := statements[0].Document().Lookup("u", "$set", "statements").Array().Values()
+		assert.Len(t, migrated, 2)
+		assert.Equal(t, "1", migrated[0].Document().Lookup("key").StringValue())
+		assert.Equal(t, "Friendly", migrated[0].Document().Lookup("label").StringValue())
+		assert.Equal(t, "3", migrated[1].Document().Lookup("key").StringValue())
+		assert.Equal(t, "On time", migrated[1].Document().Lookup("label").StringValue())
+	})
+}
@@ -0,0 +1,104 @@
+package repositorytest
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// insertedInto returns the inserts sent to the collection
+func insertedInto(mt *mtest.T, collectionName string) []bson.Raw {
+	var inserts []bson.Raw
+	for _, insert := range startedCommands(mt, "insert") {
+		if insert.Lookup("insert").StringValue() == collectionName {
+			inserts = append(inserts, insert)
+		}
+	}
+	return inserts
+}
+
+func TestSeedSourceTypes(t *testing.T) {
+	runMockMongo(t, "fresh database", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(),
+			cursorResponse("sourceTypeCol"),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := repository.NewSourceTypeRepository(mt.DB).SeedSourceTypes(entity.DefaultSourceTypes)
+
+		assert.Nil(t, err)
+		assert.Len(t, insertedInto(mt, "migrationCol"), 1)
+		inserts := insertedInto(mt, "sourceTypeCol")
+		assert.Len(t, inserts, 1)
+		docs, _ := inserts[0].Lookup("documents").Array().Values()
+		assert.Len(t, docs, len(entity.DefaultSourceTypes))
+	})
+
+	runMockMongo(t, "seeded by another replica", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "duplicate key"}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := repository.NewSourceTypeRepository(mt.DB).SeedSourceTypes(entity.DefaultSourceTypes)
+
+		assert.Nil(t, err)
+		assert.Empty(t, insertedInto(mt, "sourceTypeCol"))
+	})
+
+	runMockMongo(t, "registry stored before the seeding was recorded", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(),
+			cursorResponse("sourceTypeCol", bson.D{{Key: "_id", Value: 1}, {Key: "n", Value: 2}}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := repository.NewSourceTypeRepository(mt.DB).SeedSourceTypes(entity.DefaultSourceTypes)
+
+		assert.Nil(t, err)
+		assert.Len(t, insertedInto(mt, "migrationCol"), 1)
+		assert.Empty(t, insertedInto(mt, "sourceTypeCol"))
+		assert.Len(t, startedCommands(mt, "commitTransaction"), 1)
+	})
+}
+
+func TestSeedDimensionRatingTypes(t *testing.T) {
+	runMockMongo(t, "fresh database", func(mt *mtest.T) {
+		ratingTypes := entity.DefaultDimensionRatingTypesNum()
+		responses := []bson.D{mtest.CreateSuccessResponse()}
+		for range ratingTypes {
+			responses = append(responses, mtest.CreateSuccessResponse())
+		}
+		mt.AddMockResponses(append(responses, mtest.CreateSuccessResponse())...)
+
+		err := repository.NewSourceTypeRepository(mt.DB).SeedDimensionRatingTypes(ratingTypes)
+
+		assert.Nil(t, err)
+		assert.Len(t, insertedInto(mt, "migrationCol"), 1)
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 5)
+		statements, _ := updates[0].Lookup("updates").Array().Values()
+		statement := statements[0].Document()
+		assert.True(t, statement.Lookup("upsert").Boolean())
+		assert.Equal(t, "rating_for_product_as_described", statement.Lookup("q", "type").StringValue())
+		assert.Equal(t, int32(5), statement.Lookup("u", "$setOnInsert", "max_score").Int32())
+	})
+
+	runMockMongo(t, "seeded by another replica", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "duplicate key"}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := repository.NewSourceTypeRepository(mt.DB).SeedDimensionRatingTypes(entity.DefaultDimensionRatingTypesNum())
+
+		assert.Nil(t, err)
+		assert.Empty(t, startedCommands(mt, "update"))
+	})
+}
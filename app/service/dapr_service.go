@@ -3,6 +3,8 @@ package service
 import (
 	"fmt"
 	request_dapr "go-klikdokter/app/model/request/dapr"
+	"go-klikdokter/app/model/response"
+	"go-klikdokter/app/repository"
 	helper_dapr "go-klikdokter/helper/dapr"
 	"go-klikdokter/helper/message"
 
@@ -14,16 +16,29 @@ type DaprService interface {
 	// PUB/SUB
 	Publisher(input request_dapr.PublisherRequest) (map[string]interface{}, message.Message)
 	SubscriberRatingsubcol(input request_dapr.BodySubscriberRatingsubcolRequest) (string, message.Message)
+
+	// Subscribers of the topics listed in dapr.subscriptions
+	GetSubscriptions(routePrefix string) []response.DaprSubscription
+	SubscriberOrderCompleted(input request_dapr.CloudEventRequest) string
+	SubscriberOrderRefunded(input request_dapr.CloudEventRequest) string
+	SubscriberUserProfileUpdated(input request_dapr.CloudEventRequest) string
 }
 
 type daprServiceImpl struct {
-	logger log.Logger
+	logger       log.Logger
+	ratingRepo   repository.RatingRepository
+	ratingMpRepo repository.RatingMpRepository
+	daprRepo     repository.DaprRepository
+	ratingMp     *ratingMpServiceImpl
 }
 
 func NewDaprService(
 	lg log.Logger,
+	rr repository.RatingRepository,
+	rmr repository.RatingMpRepository,
+	dr repository.DaprRepository,
 ) DaprService {
-	return &daprServiceImpl{lg}
+	return &daprServiceImpl{lg, rr, rmr, dr, &ratingMpServiceImpl{lg, rmr}}
 }
 
 // swagger:route POST /dapr/publisher DAPR PublisherRequest
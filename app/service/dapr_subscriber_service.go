@@ -0,0 +1,191 @@
+package service
+
+import (
+	"encoding/json"
+	"go-klikdokter/app/model/entity"
+	request_dapr "go-klikdokter/app/model/request/dapr"
+	"go-klikdokter/app/model/response"
+	"go-klikdokter/helper/config"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Names of the subscriptions, the topic of each is read from dapr.subscriptions.<name>
+const (
+	DaprSubscriptionOrderCompleted     = "order-completed"
+	DaprSubscriptionOrderRefunded      = "order-refunded"
+	DaprSubscriptionUserProfileUpdated = "user-profile-updated"
+)
+
+var daprSubscriptions = []string{DaprSubscriptionOrderCompleted, DaprSubscriptionOrderRefunded, DaprSubscriptionUserProfileUpdated}
+
+const defaultRefundCancelledReason = "order refunded"
+
+type daprEventData interface {
+	Validate() error
+}
+
+// GetSubscriptions lists the subscriptions with a configured topic, each is routed to routePrefix/<name>
+func (s *daprServiceImpl) GetSubscriptions(routePrefix string) []response.DaprSubscription {
+	pubsubName := config.GetConfigString(viper.GetString("dapr.pubsub-name"))
+	subscriptions := []response.DaprSubscription{}
+	for _, name := range daprSubscriptions {
+		topic := config.GetConfigString(viper.GetString("dapr.subscriptions." + name))
+		if topic == "" {
+			continue
+		}
+		subscriptions = append(subscriptions, response.DaprSubscription{
+			PubsubName: pubsubName,
+			Topic:      topic,
+			Route:      routePrefix + "/" + name,
+		})
+	}
+	return subscriptions
+}
+
+// consume runs handle once per event. An event which cannot be read is dropped,
+// a failure of handle is retried by dapr
+func (s *daprServiceImpl) consume(name string, event request_dapr.CloudEventRequest, data daprEventData, handle func() error) string {
+	logger := log.With(s.logger, "DaprService", "Subscriber", "subscription", name, "event_id", event.ID)
+	key := event.IdempotencyKey(name)
+
+	processed, err := s.daprRepo.IsDaprEventProcessed(key)
+	if err != nil {
+		_ = level.Error(logger).Log("err", err)
+		return response.DaprStatusRetry
+	}
+	if processed {
+		_ = level.Info(logger).Log("message", "event already processed", "key", key)
+		return response.DaprStatusSuccess
+	}
+
+	raw, err := event.EventData()
+	if err == nil {
+		err = json.Unmarshal(raw, data)
+	}
+	if err == nil {
+		err = data.Validate()
+	}
+	if err != nil {
+		_ = level.Error(logger).Log("message", "event dropped", "err", err)
+		return response.DaprStatusDrop
+	}
+
+	if err = handle(); err != nil {
+		_ = level.Error(logger).Log("err", err)
+		return response.DaprStatusRetry
+	}
+	// the handlers are idempotent, an event which could not be saved is only processed again
+	if err = s.daprRepo.SaveDaprEvent(key, event.Topic); err != nil {
+		_ = level.Error(logger).Log("key", key, "err", err)
+	}
+	return response.DaprStatusSuccess
+}
+
+// swagger:route POST /dapr/subscriber/order-completed DAPR SubscriberOrderCompleted
+// Subscriber of the order completed topic, invites the buyer to review the products and the store of the order
+//
+// responses:
+//
+//	200: SuccessResponse
+func (s *daprServiceImpl) SubscriberOrderCompleted(input request_dapr.CloudEventRequest) string {
+	var event request_dapr.OrderCompletedEvent
+	return s.consume(DaprSubscriptionOrderCompleted, input, &event, func() error {
+		var invitations []entity.ReviewInvitationCol
+		stores := map[string]bool{}
+		for _, item := range event.Items {
+			storeUid := item.StoreUID
+			if storeUid == "" {
+				storeUid = event.StoreUID
+			}
+			if storeUid != "" && !stores[storeUid] {
+				stores[storeUid] = true
+				invitations = append(invitations, newReviewInvitation(event.OrderNumber, event.UserID, "store", storeUid, storeUid, nil, 0))
+			}
+			if item.ProductUID == "" {
+				continue
+			}
+			invitations = append(invitations, newReviewInvitation(event.OrderNumber, event.UserID, "product", item.ProductUID, storeUid, nil, 0))
+		}
+
+		created, err := s.ratingMpRepo.CreateReviewInvitations(invitations)
+		if err != nil {
+			return err
+		}
+		_ = level.Info(s.logger).Log("Type", "Review Invitation", "order_number", event.OrderNumber, "created", created)
+		return nil
+	})
+}
+
+// swagger:route POST /dapr/subscriber/order-refunded DAPR SubscriberOrderRefunded
+// Subscriber of the order refunded topic, cancels the submissions and the invitations of the refunded items
+//
+// responses:
+//
+//	200: SuccessResponse
+func (s *daprServiceImpl) SubscriberOrderRefunded(input request_dapr.CloudEventRequest) string {
+	var event request_dapr.OrderRefundedEvent
+	return s.consume(DaprSubscriptionOrderRefunded, input, &event, func() error {
+		reason := event.Reason
+		if reason == "" {
+			reason = defaultRefundCancelledReason
+		}
+		var sourceUids []string
+		for _, item := range event.Items {
+			if item.ProductUID != "" {
+				sourceUids = append(sourceUids, item.ProductUID)
+			}
+		}
+
+		submissions, err := s.ratingMpRepo.GetRatingSubmissionsByOrderNumber(event.OrderNumber, sourceUids)
+		if err != nil {
+			return err
+		}
+		if len(submissions) > 0 {
+			ids := make([]primitive.ObjectID, 0, len(submissions))
+			for _, submission := range submissions {
+				ids = append(ids, submission.ID)
+			}
+			if err = s.ratingMpRepo.CancelRatingSubmissionByIds(ids, reason); err != nil {
+				return err
+			}
+			for _, submission := range submissions {
+				s.ratingMp.emitFinalRatingOfSubmission(input.ID, submission)
+			}
+		}
+
+		// doctor and layanan orders have no items
+		if len(sourceUids) == 0 {
+			ids, err := s.ratingRepo.GetRatingSubmissionIdsByOrderNumber(event.OrderNumber)
+			if err != nil {
+				return err
+			}
+			if len(ids) > 0 {
+				if err = s.ratingRepo.CancelRatingSubmissionByIds(ids, reason); err != nil {
+					return err
+				}
+			}
+		}
+
+		return s.ratingMpRepo.CancelReviewInvitationsByOrderNumber(event.OrderNumber, sourceUids)
+	})
+}
+
+// swagger:route POST /dapr/subscriber/user-profile-updated DAPR SubscriberUserProfileUpdated
+// Subscriber of the user profile updated topic, refreshes the display name and avatar of the submissions of the user
+//
+// responses:
+//
+//	200: SuccessResponse
+func (s *daprServiceImpl) SubscriberUserProfileUpdated(input request_dapr.CloudEventRequest) string {
+	var event request_dapr.UserProfileUpdatedEvent
+	return s.consume(DaprSubscriptionUserProfileUpdated, input, &event, func() error {
+		if err := s.ratingRepo.UpdateRatingSubmissionUserProfile(event.UserID, event.DisplayName, event.Avatar); err != nil {
+			return err
+		}
+		return s.ratingMpRepo.UpdateRatingSubmissionUserProfile(event.UserID, event.DisplayName, event.Avatar)
+	})
+}
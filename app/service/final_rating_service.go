@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"go-klikdokter/pkg/util"
+	util_formula "go-klikdokter/pkg/util/formula"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+)
+
+const defaultFinalRatingDebounceSeconds = 5
+
+func getFinalRatingDebounce() time.Duration {
+	seconds := viper.GetFloat64("final-rating.debounce-seconds")
+	if seconds <= 0 {
+		seconds = defaultFinalRatingDebounceSeconds
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func getFinalRatingTopic(sourceType string) string {
+	if topic := viper.GetString("final-rating.topics." + sourceType); topic != "" {
+		return topic
+	}
+	return global.GetFinalRatingTopicBySourceType(sourceType)
+}
+
+// emitFinalRatingOfSubmission schedules the final rating event of the product or store the submission belongs to
+func (s *ratingMpServiceImpl) emitFinalRatingOfSubmission(correlationId string, sub entity.RatingSubmissionMp) {
+	// the rating of a store is made of the submissions with its store_uid
+	sourceUid := sub.SourceUID
+	if global.IsStoreSourceType(sub.SourceType) && sub.StoreUID != "" {
+		sourceUid = sub.StoreUID
+	}
+	s.emitFinalRating(correlationId, sub.SourceType, sourceUid)
+}
+
+// emitFinalRating schedules the final rating event of a product or store in the outbox, changes within the debounce delay
+// are published once by the outbox dispatcher
+func (s *ratingMpServiceImpl) emitFinalRating(correlationId, sourceType, sourceUid string) {
+	if sourceUid == "" || getFinalRatingTopic(sourceType) == "" {
+		return
+	}
+	due := time.Now().In(util.Loc).Add(getFinalRatingDebounce())
+	if err := s.ratingMpRepo.ScheduleFinalRating(sourceType, sourceUid, due); err != nil && s.logger != nil {
+		_ = level.Error(s.logger).Log("correlationID", correlationId, "Type", "Final Rating", "source_type", sourceType, "source_uid", sourceUid, "err", err)
+	}
+}
+
+// NewFinalRatingDeliverer returns the deliverer of the final rating outbox messages, it publishes the final rating of the source
+func NewFinalRatingDeliverer(logger log.Logger, ratingMpRepo repository.RatingMpRepository) OutboxDeliverer {
+	s := &ratingMpServiceImpl{logger, ratingMpRepo}
+	return func(msg entity.OutboxCol) error {
+		return s.publishFinalRating(msg.Payload.SourceType, msg.Payload.SourceUID, false)
+	}
+}
+
+// calculateFinalRating evaluates the formula of the source type against the public submissions of the source
+func (s *ratingMpServiceImpl) calculateFinalRating(sourceType, sourceUid string) (*entity.FinalRatingCol, error) {
+	groups, err := s.ratingMpRepo.GetRatingSubsGroupByValue(sourceUid, sourceType)
+	if err != nil {
+		return nil, err
+	}
+	totalValue := 0
+	totalReviewer := 0
+	histogram := make(map[string]int64, len(groups))
+	for _, group := range groups {
+		totalValue += group.ConvertedValue * group.Total
+		totalReviewer += group.Total
+		histogram[strconv.Itoa(group.ConvertedValue)] = int64(group.Total)
+	}
+
+	formulaRating, err := s.ratingMpRepo.GetRatingFormulaBySourceType(sourceType)
+	if err != nil {
+		return nil, err
+	}
+	if formulaRating == nil {
+		return nil, errors.New("formula rating not found, for source_type: " + sourceType)
+	}
+
+	variables := util_formula.Variables{Sum: float64(totalValue), Count: int64(totalReviewer), Histogram: histogram}
+	err = variables.Load(formulaRating.Formula, repository.NewRatingMpFormulaLoader(s.ratingMpRepo, sourceUid, sourceType, formulaRating.RatingTypeId))
+	if err != nil {
+		return nil, err
+	}
+	finalCalc, err := util_formula.Evaluate(formulaRating.Formula, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.FinalRatingCol{
+		SourceType:    sourceType,
+		SourceUID:     sourceUid,
+		Value:         fmt.Sprintf("%.1f", finalCalc),
+		TotalReviewer: int64(totalReviewer),
+		Histogram:     histogram,
+	}, nil
+}
+
+// publishFinalRating queues the event of the final rating of the source with its previous value,
+// a final rating equal to the last published one is skipped unless force
+func (s *ratingMpServiceImpl) publishFinalRating(sourceType, sourceUid string, force bool) error {
+	finalRating, err := s.calculateFinalRating(sourceType, sourceUid)
+	if err != nil {
+		return err
+	}
+	previous, err := s.ratingMpRepo.GetFinalRatingBySource(sourceType, sourceUid)
+	if err != nil {
+		return err
+	}
+	if !force && previous != nil && previous.Value == finalRating.Value &&
+		previous.TotalReviewer == finalRating.TotalReviewer && reflect.DeepEqual(previous.Histogram, finalRating.Histogram) {
+		return nil
+	}
+
+	finalRating.PublishedAt = time.Now().In(util.Loc)
+	data := map[string]interface{}{
+		"source_type":      sourceType,
+		"source_uid":       sourceUid,
+		"final_rating":     finalRating.Value,
+		"old_final_rating": nil,
+		"total_reviewer":   finalRating.TotalReviewer,
+		"histogram":        finalRating.Histogram,
+		"published_at":     finalRating.PublishedAt,
+	}
+	// product_uid is kept for the consumers of the product event
+	if global.IsStoreSourceType(sourceType) {
+		data["store_uid"] = sourceUid
+	} else {
+		data["product_uid"] = sourceUid
+	}
+	if previous != nil {
+		data["old_final_rating"] = previous.Value
+		data["old_total_reviewer"] = previous.TotalReviewer
+	}
+	jsonPayload, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+
+	event := entity.OutboxCol{
+		Target:  entity.OutboxTargetDapr,
+		Payload: entity.OutboxPayload{Topic: getFinalRatingTopic(sourceType), Data: string(jsonPayload)},
+	}
+	_ = level.Info(s.logger).Log("Type", "Final Rating", "source_type", sourceType, "source_uid", sourceUid, "result", finalRating.Value)
+
+	// the event is saved with the final rating and published by the outbox dispatcher
+	return s.ratingMpRepo.SaveFinalRating(*finalRating, event)
+}
+
+// swagger:route POST /final-rating/republish FinalRating republishFinalRatings
+// Republish the final rating of every product or store in background, returns the number of sources to publish
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingMpServiceImpl) RepublishFinalRatings(input request.RepublishFinalRatingsRequest) (int, message.Message) {
+	if err := input.Validate(); err != nil {
+		return 0, message.Message{
+			Code:    message.ValidationFailCode,
+			Message: err.Error(),
+		}
+	}
+	sourceUids, err := s.ratingMpRepo.GetFinalRatingSourceUids(input.SourceType)
+	if err != nil {
+		return 0, message.FailedMsg
+	}
+	if s.logger == nil {
+		return len(sourceUids), message.SuccessMsg
+	}
+
+	go func() {
+		succeeded := 0
+		for _, sourceUid := range sourceUids {
+			if err := s.publishFinalRating(input.SourceType, sourceUid, !input.OnlyChanged); err != nil {
+				_ = level.Error(s.logger).Log("Type", "Republish Final Rating", "source_type", input.SourceType, "source_uid", sourceUid, "err", err)
+				continue
+			}
+			succeeded++
+		}
+		_ = level.Info(s.logger).Log("Type", "Republish Final Rating", "source_type", input.SourceType, "total", len(sourceUids), "succeeded", succeeded)
+	}()
+	return len(sourceUids), message.SuccessMsg
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository"
+	helper_dapr "go-klikdokter/helper/dapr"
+	"go-klikdokter/helper/httputil"
+	"go-klikdokter/helper/message"
+	"go-klikdokter/pkg/util"
+	util_media "go-klikdokter/pkg/util/media"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaults of the outbox config
+const (
+	defaultOutboxPollIntervalSeconds = 5
+	defaultOutboxBatchSize           = 100
+	defaultOutboxMaxAttempts         = 10
+	defaultOutboxBackoffMinSeconds   = 5
+	defaultOutboxBackoffMaxSeconds   = 3600
+	defaultOutboxLockSeconds         = 60
+)
+
+var errOutboxLockExpired = errors.New("outbox lock expired before the message was delivered")
+
+// OutboxDeliverer delivers an outbox message to its target, an error schedules another attempt
+type OutboxDeliverer func(msg entity.OutboxCol) error
+
+// OutboxDeliverers are the deliverers by outbox target
+type OutboxDeliverers map[string]OutboxDeliverer
+
+type OutboxService interface {
+	RunOutboxDispatcher(ctx context.Context)
+	DispatchOutboxMessages() int
+	GetOutboxMessages(input request.GetOutboxMessagesRequest) ([]entity.OutboxCol, *base.Pagination, message.Message)
+	GetOutboxMessageById(input request.OutboxMessageRequest) (*entity.OutboxCol, message.Message)
+	ReplayOutboxMessage(input request.OutboxMessageRequest) message.Message
+}
+
+type outboxServiceImpl struct {
+	logger     log.Logger
+	outboxRepo repository.OutboxRepository
+	deliverers OutboxDeliverers
+}
+
+func NewOutboxService(
+	lg log.Logger,
+	or repository.OutboxRepository,
+	od OutboxDeliverers,
+) OutboxService {
+	return &outboxServiceImpl{lg, or, od}
+}
+
+// NewOutboxDeliverers returns the deliverers calling payment-svc, media-svc and dapr, and publishing the final ratings
+func NewOutboxDeliverers(logger log.Logger, ratingMpRepo repository.RatingMpRepository) OutboxDeliverers {
+	return OutboxDeliverers{
+		entity.OutboxTargetPaymentUpdateFlag: func(msg entity.OutboxCol) error {
+			return paymentResult(util.UpdateFlagPayment(msg.Payload.OrderNumber, logger))
+		},
+		entity.OutboxTargetPaymentReviewProduct: func(msg entity.OutboxCol) error {
+			return paymentResult(util.UpdateReviewProductStore(msg.Payload.OrderNumber, msg.Payload.SourceType, msg.Payload.SourceUID, msg.SubmissionID, logger))
+		},
+		entity.OutboxTargetMediaHouseKeeping: func(msg entity.OutboxCol) error {
+			media := []entity.MediaObj{{UID: msg.Payload.MediaUID}}
+			_, err := util_media.ImageHouseKeeping(context.Background(), logger, media, msg.SubmissionID)
+			return err
+		},
+		entity.OutboxTargetDapr: func(msg entity.OutboxCol) error {
+			response, err := helper_dapr.NewDaprHttpClient().PublishEvent(msg.Payload.Topic, msg.Payload.Data)
+			if err != nil {
+				return err
+			}
+			if statusCode, _ := response["StatusCode"].(int); statusCode >= 300 {
+				return fmt.Errorf("dapr response status code is %d: %v", statusCode, response["Response"])
+			}
+			return nil
+		},
+		entity.OutboxTargetFinalRating: NewFinalRatingDeliverer(logger, ratingMpRepo),
+	}
+}
+
+func paymentResult(msg message.Message, err error) error {
+	if err != nil {
+		return err
+	}
+	if msg != message.SuccessMsg {
+		return errors.New(msg.Message)
+	}
+	return nil
+}
+
+func getOutboxConfigInt(key string, defaultValue int) int {
+	if value := viper.GetInt("outbox." + key); value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// RunOutboxDispatcher delivers the due outbox messages every poll interval until ctx is done
+func (s *outboxServiceImpl) RunOutboxDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(getOutboxConfigInt("poll-interval-seconds", defaultOutboxPollIntervalSeconds)) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.DispatchOutboxMessages()
+		}
+	}
+}
+
+// DispatchOutboxMessages delivers up to outbox.batch-size due messages, returns the number of messages processed
+func (s *outboxServiceImpl) DispatchOutboxMessages() int {
+	lock := time.Duration(getOutboxConfigInt("lock-seconds", defaultOutboxLockSeconds)) * time.Second
+	batchSize := getOutboxConfigInt("batch-size", defaultOutboxBatchSize)
+
+	processed := 0
+	for processed < batchSize {
+		msg, err := s.outboxRepo.ClaimOutboxMessage(lock)
+		if err != nil {
+			_ = level.Error(s.logger).Log("Type", "Outbox", "err", err)
+			return processed
+		}
+		if msg == nil {
+			return processed
+		}
+		s.deliverOutboxMessage(*msg)
+		processed++
+	}
+	return processed
+}
+
+// deliverOutboxMessage delivers the message, a failed message is retried with an exponential backoff
+// and becomes dead once outbox.max-attempts is reached
+func (s *outboxServiceImpl) deliverOutboxMessage(msg entity.OutboxCol) {
+	// the reclaims of a message crashing its dispatcher used up the attempts, it is not delivered again
+	if msg.Attempts >= getOutboxConfigInt("max-attempts", defaultOutboxMaxAttempts) {
+		_ = level.Error(s.logger).Log("Type", "Outbox", "id", msg.ID.Hex(), "target", msg.Target, "attempts", msg.Attempts, "dead", true, "err", errOutboxLockExpired)
+		err := s.outboxRepo.MarkOutboxMessageFailed(msg.ID, msg.Attempts, errOutboxLockExpired.Error(), time.Now().In(util.Loc), true)
+		if err != nil {
+			_ = level.Error(s.logger).Log("Type", "Outbox", "id", msg.ID.Hex(), "err", err)
+		}
+		return
+	}
+
+	var err error
+	deliver, ok := s.deliverers[msg.Target]
+	if ok {
+		err = deliver(msg)
+	} else {
+		err = errors.New("unknown outbox target " + msg.Target)
+	}
+
+	if err == nil {
+		if err = s.outboxRepo.MarkOutboxMessageDelivered(msg.ID); err != nil {
+			_ = level.Error(s.logger).Log("Type", "Outbox", "id", msg.ID.Hex(), "err", err)
+		}
+		return
+	}
+
+	attempts := msg.Attempts + 1
+	dead := !ok || attempts >= getOutboxConfigInt("max-attempts", defaultOutboxMaxAttempts)
+	backoff := httputil.BackOffPolicy(
+		time.Duration(getOutboxConfigInt("backoff-min-seconds", defaultOutboxBackoffMinSeconds))*time.Second,
+		time.Duration(getOutboxConfigInt("backoff-max-seconds", defaultOutboxBackoffMaxSeconds))*time.Second,
+		attempts-1, nil)
+	_ = level.Error(s.logger).Log("Type", "Outbox", "id", msg.ID.Hex(), "target", msg.Target, "attempts", attempts, "dead", dead, "err", err)
+
+	err = s.outboxRepo.MarkOutboxMessageFailed(msg.ID, attempts, err.Error(), time.Now().In(util.Loc).Add(backoff), dead)
+	if err != nil {
+		_ = level.Error(s.logger).Log("Type", "Outbox", "id", msg.ID.Hex(), "err", err)
+	}
+}
+
+// swagger:route GET /outbox Outbox getOutboxMessages
+// Get Outbox Messages, latest first
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *outboxServiceImpl) GetOutboxMessages(input request.GetOutboxMessagesRequest) ([]entity.OutboxCol, *base.Pagination, message.Message) {
+	if err := input.Validate(); err != nil {
+		return nil, nil, message.Message{
+			Code:    message.ValidationFailCode,
+			Message: err.Error(),
+		}
+	}
+	if input.Page <= 0 {
+		input.Page = 1
+	}
+	if input.Limit <= 0 {
+		input.Limit = 50
+	}
+
+	results, pagination, err := s.outboxRepo.GetOutboxMessages(input.Status, input.Target, input.Page, input.Limit)
+	if err != nil {
+		return nil, nil, message.FailedMsg
+	}
+	return results, pagination, message.SuccessMsg
+}
+
+// swagger:route GET /outbox/{id} Outbox getOutboxMessageById
+// Get Outbox Message by ID
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *outboxServiceImpl) GetOutboxMessageById(input request.OutboxMessageRequest) (*entity.OutboxCol, message.Message) {
+	objectId, err := primitive.ObjectIDFromHex(input.Id)
+	if err != nil {
+		return nil, message.ErrNoData
+	}
+	result, err := s.outboxRepo.GetOutboxMessageById(objectId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, message.ErrNoData
+		}
+		return nil, message.FailedMsg
+	}
+	return result, message.SuccessMsg
+}
+
+// swagger:route PUT /outbox/{id}/replay Outbox replayOutboxMessage
+// Replay Outbox Message, the message is delivered again with a new set of attempts
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *outboxServiceImpl) ReplayOutboxMessage(input request.OutboxMessageRequest) message.Message {
+	outboxMessage, msg := s.GetOutboxMessageById(input)
+	if msg.Code != message.SuccessMsg.Code {
+		return msg
+	}
+	if outboxMessage.Status == entity.OutboxStatusProcessing {
+		return message.ErrOutboxMessageProcessing
+	}
+
+	err := s.outboxRepo.ReplayOutboxMessage(outboxMessage.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.ErrOutboxMessageProcessing
+		}
+		return message.FailedMsg
+	}
+	return message.SuccessMsg
+}
@@ -0,0 +1,89 @@
+package publicservice
+
+import (
+	"errors"
+	"fmt"
+	"go-klikdokter/app/model/entity"
+	publicrequest "go-klikdokter/app/model/request/public"
+	publicresponse "go-klikdokter/app/model/response/public"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/pkg/util"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// summarizeRatingDimensions returns the summaries of the dimensions of filter.SourceType by uid, the submissions are
+// grouped by uidField, source_uid or store_uid. A uid without dimension values has no summary.
+func (s *publicRatingMpServiceImpl) summarizeRatingDimensions(filter publicrequest.FilterRatingSummary, uidField string) (map[string][]publicresponse.PublicRatingDimensionSummary, error) {
+	dimensions := global.GetRatingDimensionsBySourceType(filter.SourceType)
+	if len(dimensions) == 0 {
+		return nil, nil
+	}
+
+	groups, err := s.publicRatingMpRepo.GetPublicRatingSubmissionsGroupByDimension(filter, uidField)
+	if err != nil {
+		return nil, err
+	}
+	groupsByUID := make(map[string]map[string]publicresponse.PublicRatingSubGroupByDimensionMp)
+	for _, group := range groups {
+		if groupsByUID[group.ID.UID] == nil {
+			groupsByUID[group.ID.UID] = make(map[string]publicresponse.PublicRatingSubGroupByDimensionMp)
+		}
+		groupsByUID[group.ID.UID][group.ID.Name] = group
+	}
+	if len(groupsByUID) == 0 {
+		return nil, nil
+	}
+
+	// a dimension whose rating type no longer exists is not summarized
+	ratingTypes := make(map[string]*entity.RatingTypesNumCol, len(dimensions))
+	for _, dimension := range dimensions {
+		ratingTypeNum, err := s.ratingMpRepo.FindRatingTypeNumByRatingType(dimension.RatingType)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+		ratingTypes[dimension.Name] = ratingTypeNum
+	}
+
+	summaries := make(map[string][]publicresponse.PublicRatingDimensionSummary, len(groupsByUID))
+	for uid, groupsByName := range groupsByUID {
+		for _, dimension := range dimensions {
+			if ratingTypeNum := ratingTypes[dimension.Name]; ratingTypeNum != nil {
+				summaries[uid] = append(summaries[uid], summarizeRatingDimension(dimension.Name, ratingTypeNum, groupsByName[dimension.Name]))
+			}
+		}
+	}
+	return summaries, nil
+}
+
+// summarizeRatingDimension returns the average of the values of the dimension and the count of every valid value of its
+// rating type, from the highest value
+func summarizeRatingDimension(name string, ratingTypeNum *entity.RatingTypesNumCol, group publicresponse.PublicRatingSubGroupByDimensionMp) publicresponse.PublicRatingDimensionSummary {
+	summary := publicresponse.PublicRatingDimensionSummary{
+		Name:          name,
+		TotalValue:    "0",
+		MaximumValue:  fmt.Sprintf("%.1f", float64(*ratingTypeNum.MaxScore)),
+		TotalReviewer: int64(group.TotalReviewer),
+	}
+	if summary.TotalReviewer > 0 {
+		summary.TotalValue = fmt.Sprintf("%.1f", group.TotalValue/float64(summary.TotalReviewer))
+	}
+
+	values := util.ValidValue(*ratingTypeNum.MinScore, *ratingTypeNum.MaxScore, *ratingTypeNum.Intervals, *ratingTypeNum.Scale)
+	summary.RatingSummaryDetail = make([]publicresponse.PublicRatingSummaryDetailMpResponse, 0, len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		detail := publicresponse.PublicRatingSummaryDetailMpResponse{Value: strconv.FormatFloat(values[i], 'f', -1, 64)}
+		for _, av := range group.ArrayValue {
+			if key, isKey := av["key"]; isKey && key == values[i] {
+				detail.Count = int64(av["value"])
+			}
+		}
+		if detail.Count > 0 {
+			percent, _ := strconv.ParseFloat(fmt.Sprintf("%.1f", (float32(detail.Count)/float32(summary.TotalReviewer))*100), 32)
+			detail.Percent = float32(percent)
+		}
+		summary.RatingSummaryDetail = append(summary.RatingSummaryDetail, detail)
+	}
+	return summary
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
 	publicrequest "go-klikdokter/app/model/request/public"
 	"go-klikdokter/app/model/response"
 	publicresponse "go-klikdokter/app/model/response/public"
@@ -16,13 +17,14 @@ import (
 	"go-klikdokter/helper/message"
 	"go-klikdokter/helper/thumbor"
 	"go-klikdokter/pkg/util"
+	util_formula "go-klikdokter/pkg/util/formula"
+	util_search "go-klikdokter/pkg/util/search"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/spf13/viper"
-	"github.com/vjeantet/govaluate"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -65,7 +67,8 @@ func (s *publicRatingMpServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(in
 	if input.Limit <= 0 {
 		input.Limit = 50
 	}
-	if input.Sort == "" {
+	// the matches of q come by relevance unless a sort is asked
+	if input.Sort == "" && input.Q == "" {
 		input.Sort = "created_at"
 	}
 
@@ -80,6 +83,7 @@ func (s *publicRatingMpServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(in
 	// new filter by source_uid and source_type
 	filterRatingSubs.SourceUID = input.SourceUID
 	filterRatingSubs.SourceType = input.SourceType
+	filterRatingSubs.Q = input.Q
 
 	// validation filter date
 	if err := filterRatingSubs.ValidateFormatDate(); err != nil {
@@ -105,6 +109,19 @@ func (s *publicRatingMpServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(in
 		return results, pagination, message.ErrNoData
 	}
 
+	// like_by_me of the whole page in one query, for the user of the optional bearer token
+	likedByMe := map[string]bool{}
+	if input.UserIdLegacy != "" {
+		ratingSubIds := make([]string, 0, len(ratingSubs))
+		for _, v := range ratingSubs {
+			ratingSubIds = append(ratingSubIds, v.ID.Hex())
+		}
+		likedByMe, err = s.publicRatingMpRepo.GetLikedRatingSubIdsByActor(ratingSubIds, input.UserIdLegacy)
+		if err != nil {
+			return nil, nil, message.FailedMsg
+		}
+	}
+
 	for _, v := range ratingSubs {
 		if v.Avatar == "" {
 			v.Avatar = avatarDefault
@@ -118,6 +135,10 @@ func (s *publicRatingMpServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(in
 		} else {
 			displayName = *v.DisplayName
 		}
+		highlight := ""
+		if v.Comment != nil {
+			highlight = util_search.Highlight(*v.Comment, input.Q)
+		}
 		// update media_path from null to empty array
 		// create thumbor response
 		var mediaResponse = []response.MediaObjResponse{}
@@ -131,25 +152,30 @@ func (s *publicRatingMpServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(in
 		}
 
 		results = append(results, publicresponse.PublicRatingSubmissionMpResponse{
-			ID:            v.ID,
-			SourceType:    v.SourceType,
-			SourceUID:     v.SourceUID,
-			StoreUID:      v.StoreUID,
-			UserID:        v.UserID,
-			UserIDLegacy:  v.UserIDLegacy,
-			DisplayName:   displayName,
-			Avatar:        v.Avatar,
-			Comment:       v.Comment,
-			SourceTransID: v.SourceTransID,
-			LikeCounter:   v.LikeCounter,
-			Value:         strconv.Itoa(v.Value),
-			LikeByMe:      false,
-			Media:         mediaResponse,
-			IsWithMedia:   v.IsWithMedia,
-			CreatedAt:     v.CreatedAt.In(Loc),
-			Reply:         v.Reply,
-			ReplyBy:       v.ReplyBy,
+			ID:                 v.ID,
+			SourceType:         v.SourceType,
+			SourceUID:          v.SourceUID,
+			StoreUID:           v.StoreUID,
+			UserID:             v.UserID,
+			UserIDLegacy:       v.UserIDLegacy,
+			DisplayName:        displayName,
+			Avatar:             v.Avatar,
+			Comment:            v.Comment,
+			Highlight:          highlight,
+			SourceTransID:      v.SourceTransID,
+			LikeCounter:        v.LikeCounter,
+			NotHelpfulCounter:  v.NotHelpfulCounter,
+			HelpfulRatio:       util.HelpfulRatio(v.LikeCounter, v.NotHelpfulCounter),
+			Value:              strconv.Itoa(v.Value),
+			LikeByMe:           likedByMe[v.ID.Hex()],
+			Media:              mediaResponse,
+			IsWithMedia:        v.IsWithMedia,
+			IsVerifiedPurchase: v.IsVerifiedPurchase,
+			CreatedAt:          v.CreatedAt.In(Loc),
+			Edited:             v.EditedAt != nil,
+			EditedAt:           util.TimeIn(v.EditedAt, Loc),
 		})
+		setReplyResponse(&results[len(results)-1], v, Loc)
 
 	}
 	return results, pagination, message.SuccessMsg
@@ -178,18 +204,25 @@ func (s *publicRatingMpServiceImpl) GetListRatingSummaryBySourceType(input publi
 		return results, message.SuccessMsg
 	}
 
+	tags, err := s.summarizeRatingTagsMp(filter, "source_uid", input.Lang)
+	if err != nil {
+		return nil, message.RecordNotFound
+	}
 	for _, args := range ratingSub {
-		data, err := s.summaryRatingNumeric(args.ID.SourceUID, input.SourceType)
+		data, err := s.summaryRatingNumeric(args, input.SourceType, filter.VerifiedOnly)
 		if err != nil {
 			return nil, message.ErrFailedSummaryRatingNumeric
 		}
+		data.Tags = tags[data.SourceUid]
 		results = append(results, *data)
 	}
 	return results, message.SuccessMsg
 }
 
-func (s *publicRatingMpServiceImpl) summaryRatingNumeric(sourceUID string, sourceType string) (*publicresponse.PublicRatingSummaryMpResponse, error) {
-	sumCountRatingSubs, err := s.publicRatingMpRepo.GetSumCountRatingSubsBySource(sourceUID, sourceType)
+// summaryRatingNumeric summarizes the submissions of the source, of verified purchases only when verifiedOnly is set
+func (s *publicRatingMpServiceImpl) summaryRatingNumeric(ratingSub publicresponse.PublicRatingSubGroupBySourceMp, sourceType string, verifiedOnly bool) (*publicresponse.PublicRatingSummaryMpResponse, error) {
+	sourceUID := ratingSub.ID.SourceUID
+	sumCountRatingSubs, err := s.publicRatingMpRepo.GetSumCountRatingSubsBySource(sourceUID, sourceType, verifiedOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +240,11 @@ func (s *publicRatingMpServiceImpl) summaryRatingNumeric(sourceUID string, sourc
 	}
 
 	if formulaRating.Formula != "" {
-		ratingSummary, err := calculateRatingMpValue(sourceUID, formulaRating.Formula, sumCountRatingSubs)
+		loader := repository.NewRatingMpFormulaLoader(s.ratingMpRepo, sourceUID, sourceType, formulaRating.RatingTypeId)
+		if verifiedOnly {
+			loader.Histogram = histogramFromArrayValue(ratingSub.ArrayValue)
+		}
+		ratingSummary, err := calculateRatingMpValue(sourceUID, formulaRating.Formula, sumCountRatingSubs, loader)
 		if err != nil {
 			return nil, err
 		}
@@ -277,6 +314,20 @@ func (s *publicRatingMpServiceImpl) GetListRatingSubmissionByID(ctx context.Cont
 		return result, pagination, message.SuccessMsg, nil
 	}
 
+	// like_by_me of the whole page in one query, for the user of the optional bearer token
+	likedByMe := map[string]bool{}
+	if input.UserIdLegacy != "" {
+		ratingSubIds := make([]string, 0, len(ratingSubs))
+		for _, v := range ratingSubs {
+			ratingSubIds = append(ratingSubIds, v.ID.Hex())
+		}
+		likedByMe, err = s.publicRatingMpRepo.GetLikedRatingSubIdsByActor(ratingSubIds, input.UserIdLegacy)
+		if err != nil {
+			errMsg["like_by_me"] = "Error Get Helpful"
+			return result, pagination, message.FailedMsg, errMsg
+		}
+	}
+
 	for _, v := range ratingSubs {
 		if v.Avatar == "" {
 			v.Avatar = avatarDefault
@@ -304,31 +355,49 @@ func (s *publicRatingMpServiceImpl) GetListRatingSubmissionByID(ctx context.Cont
 			mediaResponse = append(mediaResponse, mediaObjResponse)
 		}
 		result = append(result, publicresponse.PublicRatingSubmissionMpResponse{
-			ID:            v.ID,
-			SourceType:    v.SourceType,
-			SourceUID:     v.SourceUID,
-			StoreUID:      v.StoreUID,
-			UserID:        v.UserID,
-			UserIDLegacy:  v.UserIDLegacy,
-			DisplayName:   displayName,
-			Avatar:        v.Avatar,
-			Comment:       v.Comment,
-			SourceTransID: v.SourceTransID,
-			LikeCounter:   v.LikeCounter,
-			Value:         strconv.Itoa(v.Value),
-			LikeByMe:      false,
-			IsWithMedia:   v.IsWithMedia,
-			Media:         mediaResponse,
-			CreatedAt:     v.CreatedAt.In(Loc),
-			Reply:         v.Reply,
-			ReplyBy:       v.ReplyBy,
+			ID:                 v.ID,
+			SourceType:         v.SourceType,
+			SourceUID:          v.SourceUID,
+			StoreUID:           v.StoreUID,
+			UserID:             v.UserID,
+			UserIDLegacy:       v.UserIDLegacy,
+			DisplayName:        displayName,
+			Avatar:             v.Avatar,
+			Comment:            v.Comment,
+			SourceTransID:      v.SourceTransID,
+			LikeCounter:        v.LikeCounter,
+			NotHelpfulCounter:  v.NotHelpfulCounter,
+			HelpfulRatio:       util.HelpfulRatio(v.LikeCounter, v.NotHelpfulCounter),
+			Value:              strconv.Itoa(v.Value),
+			LikeByMe:           likedByMe[v.ID.Hex()],
+			IsWithMedia:        v.IsWithMedia,
+			IsVerifiedPurchase: v.IsVerifiedPurchase,
+			Media:              mediaResponse,
+			CreatedAt:          v.CreatedAt.In(Loc),
+			Edited:             v.EditedAt != nil,
+			EditedAt:           util.TimeIn(v.EditedAt, Loc),
 		})
+		setReplyResponse(&result[len(result)-1], v, Loc)
 
 	}
 	return result, pagination, message.SuccessMsg, nil
 }
 
-func calculateRatingMpValue(sourceUID, formula string, sumCountRatingSubs *publicresponse.PublicSumCountRatingSummaryMp) (publicresponse.RatingSummaryMpNumeric, error) {
+// setReplyResponse fill reply metadata, a reply hidden by admin is not published
+func setReplyResponse(res *publicresponse.PublicRatingSubmissionMpResponse, ratingSub entity.RatingSubmissionMp, loc *time.Location) {
+	if ratingSub.ReplyHidden || ratingSub.Reply == "" {
+		return
+	}
+	res.Reply = ratingSub.Reply
+	res.ReplyBy = ratingSub.ReplyBy
+	res.ReplyEdited = ratingSub.ReplyEdited
+	if ratingSub.RepliedAt != nil {
+		repliedAt := ratingSub.RepliedAt.In(loc)
+		res.RepliedAt = &repliedAt
+	}
+}
+
+func calculateRatingMpValue(sourceUID, formula string, sumCountRatingSubs *publicresponse.PublicSumCountRatingSummaryMp, loader util_formula.Loader) (publicresponse.RatingSummaryMpNumeric, error) {
 	result := publicresponse.RatingSummaryMpNumeric{}
 	result.SourceUID = sourceUID
 	result.TotalReviewer = sumCountRatingSubs.Count
@@ -345,15 +414,11 @@ func calculateRatingMpValue(sourceUID, formula string, sumCountRatingSubs *publi
 	}
 
 	if formula != "" {
-		expression, err := govaluate.NewEvaluableExpression(formula)
-		if err != nil {
+		variables := util_formula.Variables{Sum: float64(sumCountRatingSubs.Sum), Count: sumCountRatingSubs.Count}
+		if err := variables.Load(formula, loader); err != nil {
 			return result, err
 		}
-		parameters := make(map[string]interface{}, 8)
-		parameters["sum"] = sumCountRatingSubs.Sum
-		parameters["count"] = sumCountRatingSubs.Count
-
-		finalCalc, err := expression.Evaluate(parameters)
+		finalCalc, err := util_formula.Evaluate(formula, variables)
 		if err != nil {
 			return result, err
 		}
@@ -402,6 +467,15 @@ func (s *publicRatingMpServiceImpl) GetListDetailRatingSummaryBySourceType(input
 		return nil, message.RecordNotFound
 	}
 
+	dimensions, err := s.summarizeRatingDimensions(filter, "source_uid")
+	if err != nil {
+		return nil, message.RecordNotFound
+	}
+	tags, err := s.summarizeRatingTagsMp(filter, "source_uid", input.Lang)
+	if err != nil {
+		return nil, message.RecordNotFound
+	}
+
 	// https://it-mkt.atlassian.net/browse/MP-675
 	// case product 1: total 10 review, bintang 5 ada 9, bar hijau hampir penuh (9/10 = 90%).
 	// case product 2: total 155 review, bintang 4 ada 11, bar hijau nya sedikit (11/155 = 7%)
@@ -428,11 +502,15 @@ func (s *publicRatingMpServiceImpl) GetListDetailRatingSummaryBySourceType(input
 		}
 		pRsldr.MaximumValue = global.GetMaximumValueBySourceType(input.SourceType)
 
-		ratingSummary, err := calculateRatingMpValue(ratingSub.ID.SourceUID, formulaRating.Formula, sumCountRatingSub)
+		loader := repository.NewRatingMpFormulaLoader(s.ratingMpRepo, ratingSub.ID.SourceUID, ratingSub.ID.SourceType, formulaRating.RatingTypeId)
+		loader.Histogram = histogramFromArrayValue(ratingSub.ArrayValue)
+		ratingSummary, err := calculateRatingMpValue(ratingSub.ID.SourceUID, formulaRating.Formula, sumCountRatingSub, loader)
 		if err == nil {
 			pRsldr.TotalValue = ratingSummary.TotalValue
 			pRsldr.TotalComment = ratingSummary.TotalComment
 		}
+		pRsldr.Dimensions = dimensions[ratingSub.ID.SourceUID]
+		pRsldr.Tags = tags[ratingSub.ID.SourceUID]
 
 		results = append(results, pRsldr)
 	}
@@ -451,9 +529,8 @@ func (s *publicRatingMpServiceImpl) GetRatingSummaryStoreProduct(ctx context.Con
 	// https://it-mkt.atlassian.net/browse/MP-694
 	results := []publicresponse.RatingSummaryStoreProductNumeric{}
 	input.MakeDefaultValueIfEmpty()
-	var sourceType string = "product"
 	filter := publicrequest.FilterRatingSummary{}
-	filter.SourceType = sourceType
+	filter.SourceType = global.GetStoreProductSourceType()
 	if input.Filter != "" {
 		errMarshal := json.Unmarshal([]byte(input.Filter), &filter)
 		if errMarshal != nil {
@@ -461,6 +538,12 @@ func (s *publicRatingMpServiceImpl) GetRatingSummaryStoreProduct(ctx context.Con
 			return nil, message.ErrUnmarshalFilterListRatingRequest
 		}
 	}
+	// the summary of a store is the one of the store source type of the source type sold
+	var sourceType string = filter.SourceType
+	registered, _ := global.GetSourceType(sourceType)
+	if registered.StoreSourceType == "" {
+		return nil, message.ErrSourceTypeNotSoldByStore
+	}
 	if errValidate := filter.ValidateStoreUID(); errValidate != nil {
 		return nil, *errValidate
 	}
@@ -481,6 +564,15 @@ func (s *publicRatingMpServiceImpl) GetRatingSummaryStoreProduct(ctx context.Con
 		return nil, message.RecordNotFound
 	}
 
+	dimensions, err := s.summarizeRatingDimensions(filter, "store_uid")
+	if err != nil {
+		return nil, message.RecordNotFound
+	}
+	tags, err := s.summarizeRatingTagsMp(filter, "store_uid", input.Lang)
+	if err != nil {
+		return nil, message.RecordNotFound
+	}
+
 	// processing calculate  summary
 	for _, ratingSub := range ratingSubs {
 		result := publicresponse.RatingSummaryStoreProductNumeric{}
@@ -494,7 +586,9 @@ func (s *publicRatingMpServiceImpl) GetRatingSummaryStoreProduct(ctx context.Con
 			Sum:   totalValue,
 			Count: result.TotalReviewer,
 		}
-		ratingSummary, err := calculateRatingMpValue(ratingSub.ID.StoreUID, formulaRating.Formula, sumCountRatingSub)
+		loader := repository.NewRatingMpFormulaLoader(s.ratingMpRepo, ratingSub.ID.StoreUID, registered.StoreSourceType, formulaRating.RatingTypeId)
+		loader.Histogram = histogramFromArrayValue(ratingSub.ArrayValue)
+		ratingSummary, err := calculateRatingMpValue(ratingSub.ID.StoreUID, formulaRating.Formula, sumCountRatingSub, loader)
 		if err == nil {
 			result.TotalValue = ratingSummary.TotalValue
 			result.TotalComment = result.TotalReviewer
@@ -503,6 +597,8 @@ func (s *publicRatingMpServiceImpl) GetRatingSummaryStoreProduct(ctx context.Con
 		// calculate star
 		var arrRatingDetailSummary = populateStarRatingSummary(arrRatingValue, ratingSub.ArrayValue, result.TotalReviewer)
 		result.RatingSummaryDetail = arrRatingDetailSummary
+		result.Dimensions = dimensions[ratingSub.ID.StoreUID]
+		result.Tags = tags[ratingSub.ID.StoreUID]
 
 		results = append(results, result)
 	}
@@ -510,6 +606,21 @@ func (s *publicRatingMpServiceImpl) GetRatingSummaryStoreProduct(ctx context.Con
 	return results, message.SuccessMsg
 }
 
+// histogramFromArrayValue returns the loader of the formula histogram from the submissions grouped by value
+func histogramFromArrayValue(arrayValue []map[string]int) func() (map[string]int64, error) {
+	return func() (map[string]int64, error) {
+		histogram := make(map[string]int64, len(arrayValue))
+		for _, av := range arrayValue {
+			key, isKey := av["key"]
+			countValue, isCountValue := av["value"]
+			if isKey && isCountValue {
+				histogram[fmt.Sprint(key)] = int64(countValue)
+			}
+		}
+		return histogram, nil
+	}
+}
+
 func populateStarRatingSummary(arrRatingValue []string, arrayValue []map[string]int, totalReviewer int64) []publicresponse.PublicRatingSummaryDetailMpResponse {
 	var arrRatingDetailSummary []publicresponse.PublicRatingSummaryDetailMpResponse
 	for _, arv := range arrRatingValue {
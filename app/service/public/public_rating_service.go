@@ -3,7 +3,6 @@ package publicservice
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"go-klikdokter/app/model/base"
 	"go-klikdokter/app/model/entity"
 	publicrequest "go-klikdokter/app/model/request/public"
@@ -13,13 +12,13 @@ import (
 	"go-klikdokter/helper/config"
 	"go-klikdokter/helper/message"
 	"go-klikdokter/pkg/util"
+	util_formula "go-klikdokter/pkg/util/formula"
+	util_search "go-klikdokter/pkg/util/search"
 	"math"
-	"strconv"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/spf13/viper"
-	"github.com/vjeantet/govaluate"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -76,7 +75,17 @@ func (s *publicRatingServiceImpl) GetListRatingSummaryBySourceType(input publicr
 		return results, pagination, message.SuccessMsg
 	}
 
+	aggregates, err := s.ratingRepo.GetRatingAggregates(ratings)
+	if err != nil {
+		return nil, nil, message.ErrFailedSummaryRatingNumeric
+	}
+
 	for _, args := range ratings {
+		args = args.Localized(input.Lang)
+		aggregate := aggregates[args.ID.Hex()]
+		if filter.VerifiedOnly {
+			aggregate = aggregate.VerifiedOnly()
+		}
 		ratingTypeId, err := primitive.ObjectIDFromHex(args.RatingTypeId)
 		if err != nil {
 			return nil, nil, message.FailedMsg
@@ -89,50 +98,58 @@ func (s *publicRatingServiceImpl) GetListRatingSummaryBySourceType(input publicr
 		}
 
 		if ratingTypeLikert == nil {
-			data, err := s.summaryRatingNumeric(args, input.SourceType)
+			data, err := s.summaryRatingNumeric(args, aggregate, input.SourceType)
 			if err != nil {
 				return nil, nil, message.ErrFailedSummaryRatingNumeric
 			}
 			results = append(results, *data)
 		} else {
-			data, err := s.summaryRatingLikert(args, *ratingTypeLikert)
+			data, err := s.summaryRatingLikert(args, aggregate, ratingTypeLikert.Localized(input.Lang))
 			if err != nil {
 				return nil, nil, message.ErrFailedSummaryRatingNumeric
 			}
 			results = append(results, *data)
 		}
 	}
+
+	// the counts of the tags are by rating, a rating is a source_uid and a rating type
+	ratingIds := make([]string, 0, len(results))
+	for _, result := range results {
+		ratingIds = append(ratingIds, result.ID.Hex())
+	}
+	groups, err := s.publicRatingRepo.GetPublicRatingSubmissionsGroupByTag(ratingIds, filter.VerifiedOnly)
+	if err != nil {
+		return nil, nil, message.FailedMsg
+	}
+	if len(groups) > 0 {
+		tags, err := s.ratingRepo.GetSubmissionTags(input.SourceType)
+		if err != nil {
+			return nil, nil, message.FailedMsg
+		}
+		summaries := summarizeRatingTags(groups, tags, input.Lang)
+		for i := range results {
+			results[i].Tags = summaries[results[i].ID.Hex()]
+		}
+	}
 	return results, pagination, message.SuccessMsg
 }
 
-func (s *publicRatingServiceImpl) summaryRatingLikert(rating entity.RatingsCol, ratingLikert entity.RatingTypesLikertCol) (*publicresponse.PublicRatingSummaryResponse, error) {
+func (s *publicRatingServiceImpl) summaryRatingLikert(rating entity.RatingsCol, aggregate entity.RatingAggregateCol, ratingLikert entity.RatingTypesLikertCol) (*publicresponse.PublicRatingSummaryResponse, error) {
 	likertSummary := publicresponse.RatingSummaryLikert{}
-	var myMap map[string]string
-	datas, _ := json.Marshal(ratingLikert)
-	json.Unmarshal(datas, &myMap)
-
 	likertSummary.SourceUID = rating.SourceUid
-	for i := 1; i <= ratingLikert.NumStatements; i++ {
-		var statement string
-		if i < 10 {
-			statement = fmt.Sprint("statement_0", i)
-		} else {
-			statement = fmt.Sprint("statement_", i)
-		}
-		data := myMap[statement]
-		if data != "" {
-			totalCount, err := s.publicRatingRepo.CountRatingSubsByRatingIdAndValue(rating.ID.Hex(), strconv.Itoa(i))
-			if err != nil {
-				return nil, err
-			}
-			likertObjCount := make(map[string]interface{})
-			likertObjCount["seq_id"] = i
-			likertObjCount["value"] = data
-			likertObjCount["total_reviewer"] = totalCount
-			likertSummary.ValueList = append(likertSummary.ValueList, likertObjCount)
-		} else {
-			return nil, errors.New("invalid statement value")
-		}
+	statements := ratingLikert.GetStatements()
+	if len(statements) < ratingLikert.NumStatements {
+		return nil, errors.New("invalid statement value")
+	}
+	for i, statement := range statements {
+		totalCount := aggregate.Histogram[statement.Key]
+		likertObjCount := make(map[string]interface{})
+		likertObjCount["seq_id"] = i + 1
+		likertObjCount["key"] = statement.Key
+		likertObjCount["value"] = statement.Label
+		likertObjCount["icon"] = statement.Icon
+		likertObjCount["total_reviewer"] = totalCount
+		likertSummary.ValueList = append(likertSummary.ValueList, likertObjCount)
 	}
 
 	result := publicresponse.PublicRatingSummaryResponse{
@@ -148,12 +165,7 @@ func (s *publicRatingServiceImpl) summaryRatingLikert(rating entity.RatingsCol,
 	return &result, nil
 }
 
-func (s *publicRatingServiceImpl) summaryRatingNumeric(rating entity.RatingsCol, sourceType string) (*publicresponse.PublicRatingSummaryResponse, error) {
-	ratingSubs, err := s.publicRatingRepo.GetRatingSubsByRatingId(rating.ID.Hex())
-	if err != nil {
-		return nil, err
-	}
-
+func (s *publicRatingServiceImpl) summaryRatingNumeric(rating entity.RatingsCol, aggregate entity.RatingAggregateCol, sourceType string) (*publicresponse.PublicRatingSummaryResponse, error) {
 	formulaRating, err := s.publicRatingRepo.GetRatingFormulaByRatingTypeIdAndSourceType(rating.RatingTypeId, sourceType)
 	if err != nil {
 		return nil, err
@@ -163,7 +175,19 @@ func (s *publicRatingServiceImpl) summaryRatingNumeric(rating entity.RatingsCol,
 	}
 
 	if formulaRating.Formula != "" {
-		ratingSummary, err := calculateRatingValue(rating.SourceUid, formulaRating.Formula, ratingSubs)
+		variables := util_formula.Variables{Sum: aggregate.Sum, Count: aggregate.Count, Histogram: aggregate.Histogram}
+		err = variables.Load(formulaRating.Formula, util_formula.Loader{
+			Global: func() (float64, int64, error) {
+				return s.ratingRepo.GetGlobalSumCountBySourceType(sourceType, formulaRating.RatingTypeId)
+			},
+			Decayed: func(halfLife time.Duration) (float64, float64, error) {
+				return s.ratingRepo.GetDecayedSumCountByRatingId(rating.ID.Hex(), halfLife)
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		ratingSummary, err := calculateRatingValue(rating.SourceUid, formulaRating.Formula, variables)
 		if err != nil {
 			return nil, err
 		}
@@ -184,37 +208,18 @@ func (s *publicRatingServiceImpl) summaryRatingNumeric(rating entity.RatingsCol,
 	}
 }
 
-func calculateRatingValue(sourceUID, formula string, ratingSubs []entity.RatingSubmisson) (publicresponse.RatingSummaryNumeric, error) {
+func calculateRatingValue(sourceUID, formula string, variables util_formula.Variables) (publicresponse.RatingSummaryNumeric, error) {
 	result := publicresponse.RatingSummaryNumeric{}
-	totalRatingPoint := 0
-	totalUserCount := len(ratingSubs)
-
-	if totalUserCount > 0 {
-		// Get total rating point
-		for _, args := range ratingSubs {
-			intVal, err := strconv.Atoi(args.Value)
-			if err != nil {
-				return result, err
-			}
-			totalRatingPoint = totalRatingPoint + intVal
-		}
-	}
+	totalRatingPoint := int(math.Round(variables.Sum))
+	totalUserCount := int(variables.Count)
 
 	if formula != "" {
-		expression, err := govaluate.NewEvaluableExpression(formula)
-		if err != nil {
-			return result, err
-		}
-		parameters := make(map[string]interface{}, 8)
-		parameters["total_rating_point"] = totalRatingPoint
-		parameters["total_user_count"] = totalUserCount
-
-		finalCalc, err := expression.Evaluate(parameters)
+		finalCalc, err := util_formula.Evaluate(formula, variables)
 		if err != nil {
 			return result, err
 		}
 
-		result.TotalValue = int(math.Floor(finalCalc.(float64) + 0.5))
+		result.TotalValue = int(math.Floor(finalCalc + 0.5))
 	} else {
 		result.TotalValue = totalRatingPoint
 	}
@@ -247,7 +252,8 @@ func (s *publicRatingServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(inpu
 	if input.Limit <= 0 {
 		input.Limit = 50
 	}
-	if input.Sort == "" {
+	// the matches of q come by relevance unless a sort is asked
+	if input.Sort == "" && input.Q == "" {
 		input.Sort = "created_at"
 	}
 
@@ -276,6 +282,7 @@ func (s *publicRatingServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(inpu
 			return nil, nil, message.ErrUnmarshalFilterListRatingRequest
 		}
 	}
+	filterRatingSubs.Q = input.Q
 	// Set rating id to filter
 	for _, v := range ratings {
 		filterRatingSubs.RatingID = append(filterRatingSubs.RatingID, v.ID.Hex())
@@ -304,6 +311,19 @@ func (s *publicRatingServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(inpu
 		return results, pagination, message.ErrNoData
 	}
 
+	// like_by_me of the whole page in one query, for the user of the optional bearer token
+	likedByMe := map[string]bool{}
+	if input.UserIdLegacy != "" {
+		ratingSubIds := make([]string, 0, len(ratingSubs))
+		for _, v := range ratingSubs {
+			ratingSubIds = append(ratingSubIds, v.ID.Hex())
+		}
+		likedByMe, err = s.publicRatingRepo.GetLikedRatingSubIdsByActor(ratingSubIds, input.UserIdLegacy)
+		if err != nil {
+			return nil, nil, message.FailedMsg
+		}
+	}
+
 	for _, v := range ratingSubs {
 		// Get Rating value
 		ratingId, err := primitive.ObjectIDFromHex(v.RatingID)
@@ -320,6 +340,11 @@ func (s *publicRatingServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(inpu
 		}
 		Loc, _ := time.LoadLocation(timezone)
 
+		highlight := ""
+		if v.Comment != nil {
+			highlight = util_search.Highlight(*v.Comment, input.Q)
+		}
+
 		// Masking Anonym Display Name
 		displayName := ""
 		if v.IsAnonymous {
@@ -328,18 +353,24 @@ func (s *publicRatingServiceImpl) GetListRatingSubmissionBySourceTypeAndUID(inpu
 			displayName = *v.DisplayName
 		}
 		results = append(results, publicresponse.PublicRatingSubmissionResponse{
-			ID:            v.ID,
-			UserID:        v.UserID,
-			UserIDLegacy:  v.UserIDLegacy,
-			DisplayName:   displayName,
-			Avatar:        v.Avatar,
-			Comment:       v.Comment,
-			SourceTransID: v.SourceTransID,
-			LikeCounter:   v.LikeCounter,
-			RatingType:    rating.RatingType,
-			Value:         v.Value,
-			LikeByMe:      false,
-			CreatedAt:     v.CreatedAt.In(Loc),
+			ID:                 v.ID,
+			UserID:             v.UserID,
+			UserIDLegacy:       v.UserIDLegacy,
+			DisplayName:        displayName,
+			Avatar:             v.Avatar,
+			Comment:            v.Comment,
+			Highlight:          highlight,
+			SourceTransID:      v.SourceTransID,
+			LikeCounter:        v.LikeCounter,
+			NotHelpfulCounter:  v.NotHelpfulCounter,
+			HelpfulRatio:       util.HelpfulRatio(v.LikeCounter, v.NotHelpfulCounter),
+			RatingType:         rating.RatingType,
+			Value:              v.Value,
+			LikeByMe:           likedByMe[v.ID.Hex()],
+			IsVerifiedPurchase: v.IsVerifiedPurchase,
+			CreatedAt:          v.CreatedAt.In(Loc),
+			Edited:             v.EditedAt != nil,
+			EditedAt:           util.TimeIn(v.EditedAt, Loc),
 		})
 	}
 	return results, pagination, message.SuccessMsg
@@ -0,0 +1,43 @@
+package publicservice
+
+import (
+	"go-klikdokter/app/model/entity"
+	publicrequest "go-klikdokter/app/model/request/public"
+	publicresponse "go-klikdokter/app/model/response/public"
+)
+
+// summarizeRatingTagsMp returns the counts of the tags of the reviews of filter.SourceType by uidField, source_uid or store_uid
+func (s *publicRatingMpServiceImpl) summarizeRatingTagsMp(filter publicrequest.FilterRatingSummary, uidField, lang string) (map[string][]publicresponse.PublicRatingTagSummary, error) {
+	groups, err := s.publicRatingMpRepo.GetPublicRatingSubmissionsGroupByTag(filter, uidField)
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+	tags, err := s.ratingMpRepo.GetSubmissionTags(filter.SourceType)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeRatingTags(groups, tags, lang), nil
+}
+
+// summarizeRatingTags returns the counts of the tags by uid in the order of groups, the label of a tag removed from
+// the catalogue is its key
+func summarizeRatingTags(groups []publicresponse.PublicRatingSubGroupByTag, tags []entity.SubmissionTagCol, lang string) map[string][]publicresponse.PublicRatingTagSummary {
+	labels := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		labels[tag.Key] = tag.Localized(lang).Label
+	}
+
+	summaries := make(map[string][]publicresponse.PublicRatingTagSummary)
+	for _, group := range groups {
+		label, ok := labels[group.ID.Key]
+		if !ok {
+			label = group.ID.Key
+		}
+		summaries[group.ID.UID] = append(summaries[group.ID.UID], publicresponse.PublicRatingTagSummary{
+			Key:   group.ID.Key,
+			Label: label,
+			Count: int64(group.Count),
+		})
+	}
+	return summaries
+}
@@ -0,0 +1,93 @@
+package publictest
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	publicrequest "go-klikdokter/app/model/request/public"
+	publicresponse "go-klikdokter/app/model/response/public"
+	"go-klikdokter/app/repository/public/public_repository_mock"
+	"go-klikdokter/app/repository/repository_mock"
+	publicservice "go-klikdokter/app/service/public"
+	"go-klikdokter/helper/message"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newDimensionRatingTypeNum() *entity.RatingTypesNumCol {
+	minScore, maxScore, intervals, scale := 1, 5, 5, 0
+	return &entity.RatingTypesNumCol{MinScore: &minScore, MaxScore: &maxScore, Intervals: &intervals, Scale: &scale}
+}
+
+func TestGetListDetailRatingSummaryWithDimensions(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	filter := publicrequest.FilterRatingSummary{SourceType: "product", SourceUid: []string{"1234"}}
+
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupBySource", filter).Return([]publicresponse.PublicRatingSubGroupBySourceMp{{
+		ID:            publicresponse.StructGroupSource{SourceUID: "1234", SourceType: "product"},
+		TotalValue:    9,
+		TotalReviewer: 2,
+		ArrayValue:    []map[string]int{{"key": 5, "value": 1}, {"key": 4, "value": 1}},
+	}}, nil).Once()
+	publicRepo.Mock.On("GetRatingFormulaBySourceType", "product").Return(&entity.RatingFormulaCol{Formula: "sum / count"}, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByDimension", filter, "source_uid").Return([]publicresponse.PublicRatingSubGroupByDimensionMp{{
+		ID:            publicresponse.StructGroupDimension{UID: "1234", Name: "as_described"},
+		TotalValue:    8,
+		TotalReviewer: 2,
+		ArrayValue:    []map[string]float64{{"key": 5, "value": 1}, {"key": 3, "value": 1}},
+	}}, nil).Once()
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_product_as_described").Return(newDimensionRatingTypeNum(), nil).Once()
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_product_value_for_money").Return(newDimensionRatingTypeNum(), nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByTag", filter, "source_uid").Return(nil, nil).Once()
+
+	result, msg := publicservice.NewPublicRatingMpService(logger, repo, publicRepo).GetListDetailRatingSummaryBySourceType(requestSummaryMpDetail)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, len(result))
+	dimensions := result[0].Dimensions
+	assert.Equal(t, 2, len(dimensions))
+	assert.Equal(t, "as_described", dimensions[0].Name)
+	assert.Equal(t, "4.0", dimensions[0].TotalValue)
+	assert.Equal(t, "5.0", dimensions[0].MaximumValue)
+	assert.Equal(t, int64(2), dimensions[0].TotalReviewer)
+	assert.Equal(t, publicresponse.PublicRatingSummaryDetailMpResponse{Value: "5", Count: 1, Percent: 50}, dimensions[0].RatingSummaryDetail[0])
+	assert.Equal(t, publicresponse.PublicRatingSummaryDetailMpResponse{Value: "4"}, dimensions[0].RatingSummaryDetail[1])
+	assert.Equal(t, "value_for_money", dimensions[1].Name)
+	assert.Equal(t, "0", dimensions[1].TotalValue)
+	assert.Equal(t, int64(0), dimensions[1].TotalReviewer)
+	assert.Equal(t, 5, len(dimensions[1].RatingSummaryDetail))
+}
+
+func TestGetRatingSummaryStoreProductWithDimensions(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	filter := publicrequest.FilterRatingSummary{SourceType: "product", StoreUID: []string{"1"}}
+
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByStoreSource", filter).Return([]publicresponse.PublicRatingSubGroupByStoreSourceMp{{
+		ID:            publicresponse.StructGroupStoreSource{StoreUID: "1", SourceType: "product"},
+		TotalValue:    5,
+		TotalReviewer: 1,
+		ArrayValue:    []map[string]int{{"key": 5, "value": 1}},
+	}}, nil, nil).Once()
+	publicRepo.Mock.On("GetRatingFormulaBySourceType", "product").Return(&entity.RatingFormulaCol{Formula: "sum / count"}, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByDimension", filter, "store_uid").Return([]publicresponse.PublicRatingSubGroupByDimensionMp{{
+		ID:            publicresponse.StructGroupDimension{UID: "1", Name: "value_for_money"},
+		TotalValue:    4,
+		TotalReviewer: 1,
+		ArrayValue:    []map[string]float64{{"key": 4, "value": 1}},
+	}}, nil).Once()
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_product_as_described").Return(newDimensionRatingTypeNum(), nil).Once()
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_product_value_for_money").Return(newDimensionRatingTypeNum(), nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByTag", filter, "store_uid").Return(nil, nil).Once()
+
+	result, msg := publicservice.NewPublicRatingMpService(logger, repo, publicRepo).GetRatingSummaryStoreProduct(context.TODO(), requestSummaryStoreProduct)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, 2, len(result[0].Dimensions))
+	assert.Equal(t, "value_for_money", result[0].Dimensions[1].Name)
+	assert.Equal(t, "4.0", result[0].Dimensions[1].TotalValue)
+	assert.Equal(t, int64(1), result[0].Dimensions[1].RatingSummaryDetail[1].Count)
+}
@@ -148,8 +148,9 @@ func TestGetRatingSummaryMpBySourceType(t *testing.T) {
 
 	publicRatingMpRepository.Mock.On("GetPublicRatingSubmissionsGroupBySource", filterSummaryMp).
 		Return([]publicresponse.PublicRatingSubGroupBySourceMp{ratingSubmissionGroupBySource}, nil).Once()
-	publicRatingMpRepository.Mock.On("GetSumCountRatingSubsBySource", ratingSubmissionGroupBySource.ID.SourceUID, ratingSubmissionGroupBySource.ID.SourceType).Return(&sumCountRatingSummary, nil).Once()
+	publicRatingMpRepository.Mock.On("GetSumCountRatingSubsBySource", ratingSubmissionGroupBySource.ID.SourceUID, ratingSubmissionGroupBySource.ID.SourceType, false).Return(&sumCountRatingSummary, nil).Once()
 	publicRatingMpRepository.Mock.On("GetRatingFormulaBySourceType", requestSummaryMp.SourceType).Return(&ratingFormulaMp, nil).Once()
+	publicRatingMpRepository.Mock.On("GetPublicRatingSubmissionsGroupByTag", filterSummaryMp, "source_uid").Return(nil, nil).Once()
 
 	result, msg := publicRatingMpService.GetListRatingSummaryBySourceType(requestSummaryMp)
 	assert.Equal(t, message.SuccessMsg.Code, msg.Code, "Code must be 1000")
@@ -266,6 +267,8 @@ func TestGetListDetailRatingSummaryMpBySourceType(t *testing.T) {
 	publicRatingMpRepository.Mock.On("GetPublicRatingSubmissionsGroupBySource", filterListDetailRatingSummaryMp).
 		Return([]publicresponse.PublicRatingSubGroupBySourceMp{ratingSubmissionGroupBySource}, nil).Once()
 	publicRatingMpRepository.Mock.On("GetRatingFormulaBySourceType", requestSummaryMp.SourceType).Return(&ratingFormulaMp, nil).Once()
+	publicRatingMpRepository.Mock.On("GetPublicRatingSubmissionsGroupByTag", filterListDetailRatingSummaryMp, "source_uid").Return(nil, nil).Once()
+	publicRatingMpRepository.Mock.On("GetPublicRatingSubmissionsGroupByDimension", filterListDetailRatingSummaryMp, "source_uid").Return(nil, nil).Once()
 
 	result, msg := publicRatingMpService.GetListDetailRatingSummaryBySourceType(requestSummaryMpDetail)
 
@@ -312,6 +315,8 @@ func TestGetRatingSummaryStoreProduct(t *testing.T) {
 	publicRatingMpRepository.Mock.On("GetPublicRatingSubmissionsGroupByStoreSource", filter).
 		Return([]publicresponse.PublicRatingSubGroupByStoreSourceMp{ratingSubmissionGroupByStoreSource}, &paginationResult, nil).Once()
 	publicRatingMpRepository.Mock.On("GetRatingFormulaBySourceType", "product").Return(&ratingFormulaMp, nil).Once()
+	publicRatingMpRepository.Mock.On("GetPublicRatingSubmissionsGroupByDimension", filter, "store_uid").Return(nil, nil).Once()
+	publicRatingMpRepository.Mock.On("GetPublicRatingSubmissionsGroupByTag", filter, "store_uid").Return(nil, nil).Once()
 
 	result, msg := publicRatingMpService.GetRatingSummaryStoreProduct(context.TODO(), requestSummaryStoreProduct)
 
@@ -320,3 +325,135 @@ func TestGetRatingSummaryStoreProduct(t *testing.T) {
 	assert.Equal(t, 1, len(result), "Count of list kd must be 1")
 	// assert.Equal(t, int64(1), pagination.Records, "Total record must be 1")
 }
+
+func TestGetRatingSubmissionMpVerifiedOnly(t *testing.T) {
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	request := requestSubmissionMp
+	request.Filter = `{"verified_only": true}`
+	filterSubmission := publicrequest.FilterRatingSubmissionMp{
+		SourceUID:    "1234",
+		SourceType:   "product",
+		VerifiedOnly: true,
+	}
+	ratingSubDatas := []entity.RatingSubmissionMp{
+		{
+			ID:                 idDummy1Obj,
+			DisplayName:        &displayName,
+			Comment:            &comment,
+			Value:              5,
+			IsVerifiedPurchase: true,
+		},
+	}
+
+	publicRepo.Mock.On("GetPublicRatingSubmissions", request.Limit, request.Page, -1, "created_at", filterSubmission).
+		Return(ratingSubDatas, &base.Pagination{Records: 1}, nil).Once()
+
+	result, _, msg := publicservice.NewPublicRatingMpService(logger, ratingMpRepository, publicRepo).GetListRatingSubmissionBySourceTypeAndUID(request)
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.True(t, result[0].IsVerifiedPurchase, "Submission must be flagged as verified purchase")
+	publicRepo.AssertExpectations(t)
+}
+
+func TestGetRatingSubmissionMpSearch(t *testing.T) {
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	request := requestSubmissionMp
+	request.Sort = ""
+	request.Q = "BAGUSSS"
+	filterSubmission := publicrequest.FilterRatingSubmissionMp{
+		SourceUID:  "1234",
+		SourceType: "product",
+		Q:          "BAGUSSS",
+	}
+	searchComment := "Barangnya bagus & cepat sampai"
+	ratingSubDatas := []entity.RatingSubmissionMp{
+		{
+			ID:          idDummy1Obj,
+			DisplayName: &displayName,
+			Comment:     &searchComment,
+			Value:       5,
+		},
+	}
+
+	// the matches come by relevance, no sort field is given to the repository
+	publicRepo.Mock.On("GetPublicRatingSubmissions", request.Limit, request.Page, -1, "", filterSubmission).
+		Return(ratingSubDatas, &base.Pagination{Records: 1}, nil).Once()
+
+	result, _, msg := publicservice.NewPublicRatingMpService(logger, ratingMpRepository, publicRepo).GetListRatingSubmissionBySourceTypeAndUID(request)
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, "Barangnya <em>bagus</em> &amp; cepat sampai", result[0].Highlight)
+	publicRepo.AssertExpectations(t)
+}
+
+func TestGetRatingSubmissionMpLikeByMe(t *testing.T) {
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	request := requestSubmissionMp
+	request.UserIdLegacy = "34343432"
+	filterSubmission := publicrequest.FilterRatingSubmissionMp{
+		SourceUID:  "1234",
+		SourceType: "product",
+	}
+	idDummy2Obj := primitive.NewObjectID()
+	ratingSubDatas := []entity.RatingSubmissionMp{
+		{ID: idDummy1Obj, DisplayName: &displayName, Comment: &comment, Value: 5},
+		{ID: idDummy2Obj, DisplayName: &displayName, Comment: &comment, Value: 4},
+	}
+
+	publicRepo.Mock.On("GetPublicRatingSubmissions", request.Limit, request.Page, -1, "created_at", filterSubmission).
+		Return(ratingSubDatas, &base.Pagination{Records: 2}, nil).Once()
+	// one query for the whole page
+	publicRepo.Mock.On("GetLikedRatingSubIdsByActor", []string{idDummy1Obj.Hex(), idDummy2Obj.Hex()}, "34343432").
+		Return(map[string]bool{idDummy2Obj.Hex(): true}, nil).Once()
+
+	result, _, msg := publicservice.NewPublicRatingMpService(logger, ratingMpRepository, publicRepo).GetListRatingSubmissionBySourceTypeAndUID(request)
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.False(t, result[0].LikeByMe)
+	assert.True(t, result[1].LikeByMe)
+	publicRepo.AssertExpectations(t)
+}
+
+func TestGetRatingSubmissionMpLikeByMeAnonymous(t *testing.T) {
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	filterSubmission := publicrequest.FilterRatingSubmissionMp{
+		SourceUID:  "1234",
+		SourceType: "product",
+	}
+	ratingSubDatas := []entity.RatingSubmissionMp{{ID: idDummy1Obj, DisplayName: &displayName, Comment: &comment, Value: 5}}
+
+	publicRepo.Mock.On("GetPublicRatingSubmissions", requestSubmissionMp.Limit, requestSubmissionMp.Page, -1, "created_at", filterSubmission).
+		Return(ratingSubDatas, &base.Pagination{Records: 1}, nil).Once()
+
+	result, _, msg := publicservice.NewPublicRatingMpService(logger, ratingMpRepository, publicRepo).GetListRatingSubmissionBySourceTypeAndUID(requestSubmissionMp)
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.False(t, result[0].LikeByMe)
+	publicRepo.AssertNotCalled(t, "GetLikedRatingSubIdsByActor", mock.Anything, mock.Anything)
+}
+
+func TestGetRatingSummaryMpBySourceTypeVerifiedOnly(t *testing.T) {
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	request := requestSummaryMp
+	request.Filter = `{"source_uid": ["1234"], "rating_type": ["rating_for_product"], "verified_only": true}`
+	filter := filterSummaryMp
+	filter.VerifiedOnly = true
+	ratingSubmissionGroupBySource := publicresponse.PublicRatingSubGroupBySourceMp{
+		ID:            publicresponse.StructGroupSource{SourceUID: "1234", SourceType: "product"},
+		TotalValue:    5,
+		TotalReviewer: 1,
+		ArrayValue:    []map[string]int{{"key": 5, "value": 1}},
+	}
+	ratingFormulaMp := entity.RatingFormulaCol{
+		SourceType: "product",
+		Formula:    "(sum / count) / 1",
+	}
+
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupBySource", filter).
+		Return([]publicresponse.PublicRatingSubGroupBySourceMp{ratingSubmissionGroupBySource}, nil).Once()
+	publicRepo.Mock.On("GetSumCountRatingSubsBySource", "1234", "product", true).
+		Return(&publicresponse.PublicSumCountRatingSummaryMp{Sum: 5, Count: 1}, nil).Once()
+	publicRepo.Mock.On("GetRatingFormulaBySourceType", "product").Return(&ratingFormulaMp, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByTag", filter, "source_uid").Return(nil, nil).Once()
+
+	result, msg := publicservice.NewPublicRatingMpService(logger, ratingMpRepository, publicRepo).GetListRatingSummaryBySourceType(request)
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, len(result), "Count of list kd must be 1")
+	publicRepo.AssertExpectations(t)
+}
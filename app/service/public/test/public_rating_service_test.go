@@ -6,6 +6,7 @@ import (
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
 	publicrequest "go-klikdokter/app/model/request/public"
+	publicresponse "go-klikdokter/app/model/response/public"
 	"go-klikdokter/app/repository/public/public_repository_mock"
 	"go-klikdokter/app/repository/repository_mock"
 	"go-klikdokter/app/service"
@@ -28,7 +29,7 @@ var ratingRepository = &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
 var publicRatingRepository = &public_repository_mock.PublicRatingRepositoryMock{Mock: mock.Mock{}}
 var publicRatingService = publicservice.NewPublicRatingService(logger, ratingRepository, publicRatingRepository)
 var medicalFacility = &mocks.MedicalFacilitySvc{Mock: mock.Mock{}}
-var svc = service.NewRatingService(logger, ratingRepository, publicRatingRepository, medicalFacility)
+var svc = service.NewRatingService(logger, ratingRepository, publicRatingRepository, medicalFacility, ratingMpRepository)
 
 func init() {
 	{
@@ -134,10 +135,6 @@ func TestGetRatingBySourceTypeAndSourceUIDErrNoDataRating(t *testing.T) {
 }
 
 func TestCreateRatingSubHelpfulSuccess(t *testing.T) {
-	objectId, _ := primitive.ObjectIDFromHex(ratingSubId)
-	objectRatingSubHelpfulId, _ := primitive.ObjectIDFromHex(ratingSubHelpId)
-	counter := int64(4)
-
 	input := request.CreateRatingSubHelpfulRequest{
 		RatingSubmissionID: ratingSubId,
 		UserID:             userId,
@@ -146,40 +143,18 @@ func TestCreateRatingSubHelpfulSuccess(t *testing.T) {
 		UserAgent:          useragent,
 	}
 
-	ratingSubmission := entity.RatingSubmisson{
-		ID:            objectId,
-		RatingID:      ratingId,
-		UserID:        &userId,
-		UserIDLegacy:  &userId,
-		Comment:       &comment,
-		Value:         "85",
-		IPAddress:     ipaddress,
-		UserAgent:     useragent,
-		SourceTransID: "",
-		LikeCounter:   3,
-	}
-
-	ratingSubHelpful := entity.RatingSubHelpfulCol{
-		ID:                 objectRatingSubHelpfulId,
-		RatingSubmissionID: ratingSubId,
-		UserID:             userId,
-		UserIDLegacy:       userId,
-		IPAddress:          ipaddress,
-		UserAgent:          useragent,
-	}
-
-	ratingRepository.Mock.On("GetRatingSubmissionById", objectId).Return(ratingSubmission, nil).Once()
-	publicRatingRepository.Mock.On("GetRatingSubHelpfulByRatingSubAndActor", input.RatingSubmissionID, input.UserIDLegacy).Return(nil, nil).Once()
-	publicRatingRepository.Mock.On("CreateRatingSubHelpful", input).Return(ratingSubHelpful, nil).Once()
-	publicRatingRepository.Mock.On("UpdateCounterRatingSubmission", objectId, counter).Return(nil).Once()
+	ratingRepository.Mock.On("ToggleRatingSubHelpful", input).
+		Return(&entity.RatingSubHelpfulCol{Status: true}, &entity.RatingSubHelpfulCounter{LikeCounter: 4, NotHelpfulCounter: 1}, nil).Once()
 
-	_, msg := svc.CreateRatingSubHelpful(input)
+	result, msg := svc.CreateRatingSubHelpful(input)
 	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, "Like", result.Status)
+	assert.Equal(t, 4, result.LikeCounter)
+	assert.Equal(t, 1, result.NotHelpfulCounter)
+	assert.Equal(t, 0.8, result.HelpfulRatio)
 }
 
 func TestCreateRatingSubHelpfulRatingSubmissionNil(t *testing.T) {
-	objectRatingSubmissionId, _ := primitive.ObjectIDFromHex(ratingSubIdFailed)
-
 	input := request.CreateRatingSubHelpfulRequest{
 		RatingSubmissionID: ratingSubIdFailed,
 		UserID:             userId,
@@ -188,66 +163,26 @@ func TestCreateRatingSubHelpfulRatingSubmissionNil(t *testing.T) {
 		UserAgent:          useragent,
 	}
 
-	ratingSubmission := entity.RatingSubmisson{
-		ID:            objectRatingSubmissionId,
-		RatingID:      ratingId,
-		UserID:        &userId,
-		UserIDLegacy:  &userId,
-		Comment:       &comment,
-		Value:         "85",
-		IPAddress:     ipaddress,
-		UserAgent:     useragent,
-		SourceTransID: "",
-		LikeCounter:   3,
-	}
-
-	ratingRepository.Mock.On("GetRatingSubmissionById", objectRatingSubmissionId).Return(ratingSubmission, nil).Once()
+	ratingRepository.Mock.On("ToggleRatingSubHelpful", input).Return(nil, nil, mongo.ErrNoDocuments).Once()
 
 	_, msg := svc.CreateRatingSubHelpful(input)
-	assert.Equal(t, message.FailedMsg, msg)
+	assert.Equal(t, message.ErrRatingSubmissionNotFound, msg)
 }
 
 func TestCreateRatingSubHelpfulUpdateCounterFailed(t *testing.T) {
-	objectId, _ := primitive.ObjectIDFromHex(ratingSubId)
-	objectRatingSubHelpfulId, _ := primitive.ObjectIDFromHex(ratingSubHelpId)
-
 	input := request.CreateRatingSubHelpfulRequest{
 		RatingSubmissionID: ratingSubId,
 		UserID:             userId,
 		UserIDLegacy:       userId,
 		IPAddress:          ipaddress,
 		UserAgent:          useragent,
+		Vote:               request.VoteNotHelpful,
 	}
 
-	ratingSubmission := entity.RatingSubmisson{
-		ID:            objectId,
-		RatingID:      ratingid,
-		UserID:        &userId,
-		UserIDLegacy:  &userId,
-		Comment:       &comment,
-		Value:         "85",
-		IPAddress:     ipaddress,
-		UserAgent:     useragent,
-		SourceTransID: "",
-		LikeCounter:   3,
-	}
-
-	ratingSubHelpful := entity.RatingSubHelpfulCol{
-		ID:                 objectRatingSubHelpfulId,
-		RatingSubmissionID: ratingSubId,
-		UserID:             userId,
-		UserIDLegacy:       userId,
-		IPAddress:          ipaddress,
-		UserAgent:          useragent,
-	}
-
-	ratingRepository.Mock.On("GetRatingSubmissionById", objectId).Return(ratingSubmission, nil).Once()
-	publicRatingRepository.Mock.On("GetRatingSubHelpfulByRatingSubAndActor", input.RatingSubmissionID, input.UserIDLegacy).Return(nil, nil).Once()
-	publicRatingRepository.Mock.On("CreateRatingSubHelpful", input).Return(ratingSubHelpful, nil).Once()
-	publicRatingRepository.Mock.On("UpdateCounterRatingSubmission", objectId, ratingSubmission.LikeCounter).Return(errors.New("error")).Once()
+	ratingRepository.Mock.On("ToggleRatingSubHelpful", input).Return(nil, nil, errors.New("error")).Once()
 
 	_, msg := svc.CreateRatingSubHelpful(input)
-	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, message.FailedMsg, msg)
 }
 
 func TestGetRatingSummaryBySourceType(t *testing.T) {
@@ -265,18 +200,15 @@ func TestGetRatingSummaryBySourceType(t *testing.T) {
 			RatingTypeId: ratingid,
 		},
 	}
-	ratingSubDatas := []entity.RatingSubmisson{
-		{
-			ID:            idObj,
-			RatingID:      idDummy1,
-			UserID:        &userId,
-			UserIDLegacy:  &userId,
-			Comment:       &comment,
-			Value:         "90",
-			IPAddress:     ipaddress,
-			UserAgent:     useragent,
-			SourceTransID: "",
-			LikeCounter:   5,
+	aggregates := map[string]entity.RatingAggregateCol{
+		idDummy1: {
+			SourceType:   requestSummary.SourceType,
+			SourceUID:    "3310",
+			RatingID:     idDummy1,
+			Sum:          90,
+			Count:        1,
+			Histogram:    map[string]int64{"90": 1},
+			CommentCount: 1,
 		},
 	}
 	ratingFormula := entity.RatingFormulaCol{
@@ -294,14 +226,18 @@ func TestGetRatingSummaryBySourceType(t *testing.T) {
 	}
 	publicRatingRepository.Mock.On("GetPublicRatingsByParams", requestSummary.Limit, requestSummary.Page, "updated_at", filterSummary).Return(ratingDatas, &paginationResult, nil).Once()
 	ratingRepository.Mock.On("GetRatingTypeLikertByIdAndStatus", ratingTypeObj).Return(nil, mongo.ErrNoDocuments).Once()
-	publicRatingRepository.Mock.On("GetRatingSubsByRatingId", idObj.Hex()).Return(ratingSubDatas, nil).Once()
+	ratingRepository.Mock.On("GetRatingAggregates", ratingDatas).Return(aggregates, nil).Once()
 	publicRatingRepository.Mock.On("GetRatingFormulaByRatingTypeIdAndSourceType", ratingid, requestSummary.SourceType).Return(&ratingFormula, nil).Once()
+	publicRatingRepository.Mock.On("GetPublicRatingSubmissionsGroupByTag", mock.Anything, false).Return(nil, nil).Once()
 
 	result, pagination, msg := publicRatingService.GetListRatingSummaryBySourceType(requestSummary)
 	assert.Equal(t, message.SuccessMsg.Code, msg.Code, "Code must be 1000")
 	assert.Equal(t, message.SuccessMsg.Message, msg.Message, "Message must be success")
 	assert.Equal(t, 1, len(result), "Count of list kd must be 1")
 	assert.Equal(t, int64(1), pagination.Records, "Total record must be 1")
+	summary := result[0].RatingSummary.(publicresponse.RatingSummaryNumeric)
+	assert.Equal(t, 90, summary.TotalValue, "Total value must be calculated from the aggregate")
+	assert.Equal(t, 1, summary.TotalReviewer, "Total reviewer must be the aggregate count")
 }
 
 func TestGetRatingSummaryBySourceTypeErrEmptyRating(t *testing.T) {
@@ -365,7 +301,7 @@ func TestGetRatingSummaryBySourceTypeErrGetRatingSubmission(t *testing.T) {
 	}
 	publicRatingRepository.Mock.On("GetPublicRatingsByParams", requestSummary.Limit, requestSummary.Page, "updated_at", filterSummary).Return(ratingDatas, &paginationResult, nil).Once()
 	ratingRepository.Mock.On("GetRatingTypeLikertByIdAndStatus", ratingTypeObj).Return(nil, mongo.ErrNoDocuments).Once()
-	publicRatingRepository.Mock.On("GetRatingSubsByRatingId", failedId).Return(nil, errors.New("error")).Once()
+	ratingRepository.Mock.On("GetRatingAggregates", ratingDatas).Return(nil, errors.New("error")).Once()
 
 	_, _, msg := publicRatingService.GetListRatingSummaryBySourceType(requestSummary)
 	assert.Equal(t, message.ErrFailedSummaryRatingNumeric.Code, msg.Code, "Code must be 412002")
@@ -388,13 +324,7 @@ func TestGetRatingSummaryBySourceTypeErrFailedCalculate(t *testing.T) {
 			RatingTypeId: failID,
 		},
 	}
-	ratingSubDatas := []entity.RatingSubmisson{
-		{
-			ID:       idObj,
-			RatingID: idDummy1,
-			Value:    "k",
-		},
-	}
+	aggregates := map[string]entity.RatingAggregateCol{}
 	paginationResult := base.Pagination{
 		Records:      1,
 		Limit:        10,
@@ -403,7 +333,7 @@ func TestGetRatingSummaryBySourceTypeErrFailedCalculate(t *testing.T) {
 	}
 	publicRatingRepository.Mock.On("GetPublicRatingsByParams", requestSummary.Limit, requestSummary.Page, "updated_at", filterSummary).Return(ratingDatas, &paginationResult, errors.New("error")).Once()
 	ratingRepository.Mock.On("GetRatingTypeLikertByIdAndStatus", ratingTypeObj).Return(nil, mongo.ErrNoDocuments).Once()
-	publicRatingRepository.Mock.On("GetRatingSubsByRatingId", idObj.Hex()).Return(ratingSubDatas, nil).Once()
+	ratingRepository.Mock.On("GetRatingAggregates", ratingDatas).Return(aggregates, nil).Once()
 	publicRatingRepository.Mock.On("GetRatingFormulaByRatingTypeIdAndSourceType", failID, requestSummary.SourceType).Return(nil, nil).Once()
 
 	_, _, msg := publicRatingService.GetListRatingSummaryBySourceType(requestSummary)
@@ -522,3 +452,178 @@ func TestGetRatingSubmissionBySourceTypeAndUIDErrGetRating(t *testing.T) {
 	assert.Equal(t, 412002, msg.Code, "Code must be 412002")
 	assert.Equal(t, message, msg.Message, "Message must be "+message)
 }
+
+func TestGetRatingSummaryBySourceTypeBayesFormula(t *testing.T) {
+	request := publicrequest.GetPublicListRatingSummaryRequest{
+		SourceType: "hospital",
+		Dir:        "desc",
+		Page:       1,
+		Limit:      50,
+	}
+	filter := publicrequest.FilterRatingSummary{SourceType: request.SourceType}
+	idObj, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3711")
+	ratingTypeObj, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3712")
+
+	ratingDatas := []entity.RatingsCol{
+		{
+			ID:           idObj,
+			Name:         "Rating Hospital A",
+			SourceUid:    "4410",
+			SourceType:   request.SourceType,
+			RatingTypeId: ratingTypeObj.Hex(),
+		},
+	}
+	aggregates := map[string]entity.RatingAggregateCol{
+		idObj.Hex(): {
+			SourceType: request.SourceType,
+			SourceUID:  "4410",
+			RatingID:   idObj.Hex(),
+			Sum:        100,
+			Count:      1,
+			Histogram:  map[string]int64{"100": 1},
+		},
+	}
+	ratingFormula := entity.RatingFormulaCol{
+		SourceType:   request.SourceType,
+		Formula:      "bayes(global_mean, 9)",
+		RatingTypeId: ratingTypeObj.Hex(),
+	}
+	paginationResult := base.Pagination{
+		Records:      1,
+		Limit:        50,
+		Page:         1,
+		TotalRecords: 1,
+	}
+	publicRatingRepository.Mock.On("GetPublicRatingsByParams", request.Limit, request.Page, "updated_at", filter).Return(ratingDatas, &paginationResult, nil).Once()
+	ratingRepository.Mock.On("GetRatingTypeLikertByIdAndStatus", ratingTypeObj).Return(nil, mongo.ErrNoDocuments).Once()
+	ratingRepository.Mock.On("GetRatingAggregates", ratingDatas).Return(aggregates, nil).Once()
+	publicRatingRepository.Mock.On("GetRatingFormulaByRatingTypeIdAndSourceType", ratingTypeObj.Hex(), request.SourceType).Return(&ratingFormula, nil).Once()
+	ratingRepository.Mock.On("GetGlobalSumCountBySourceType", request.SourceType, ratingTypeObj.Hex()).Return(float64(8000), int64(100), nil).Once()
+	publicRatingRepository.Mock.On("GetPublicRatingSubmissionsGroupByTag", mock.Anything, false).Return(nil, nil).Once()
+
+	result, _, msg := publicRatingService.GetListRatingSummaryBySourceType(request)
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, len(result))
+	// (9 * 80 + 100) / (9 + 1)
+	assert.Equal(t, 82, result[0].RatingSummary.(publicresponse.RatingSummaryNumeric).TotalValue, "A single review must be pulled towards the global mean")
+}
+
+func TestGetRatingSummaryBySourceTypeVerifiedOnly(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	publicRepo := &public_repository_mock.PublicRatingRepositoryMock{Mock: mock.Mock{}}
+	idObj, _ := primitive.ObjectIDFromHex(idDummy1)
+	ratingTypeObj, _ := primitive.ObjectIDFromHex(ratingid)
+	request := requestSummary
+	request.Filter = `{"verified_only": true}`
+	filter := filterSummary
+	filter.VerifiedOnly = true
+
+	ratingDatas := []entity.RatingsCol{
+		{
+			ID:           idObj,
+			SourceUid:    "3310",
+			SourceType:   requestSummary.SourceType,
+			RatingType:   ratingType,
+			RatingTypeId: ratingid,
+		},
+	}
+	aggregates := map[string]entity.RatingAggregateCol{
+		idDummy1: {
+			RatingID:  idDummy1,
+			Sum:       150,
+			Count:     3,
+			Histogram: map[string]int64{"30": 1, "40": 1, "80": 1},
+			Verified:  entity.RatingAggregateVerified{Sum: 80, Count: 1, Histogram: map[string]int64{"80": 1}},
+		},
+	}
+	ratingFormula := entity.RatingFormulaCol{
+		SourceType: "doctor",
+		Formula:    "total_rating_point / total_user_count",
+	}
+	publicRepo.Mock.On("GetPublicRatingsByParams", request.Limit, request.Page, "updated_at", filter).Return(ratingDatas, &base.Pagination{Records: 1}, nil).Once()
+	repo.Mock.On("GetRatingAggregates", ratingDatas).Return(aggregates, nil).Once()
+	repo.Mock.On("GetRatingTypeLikertByIdAndStatus", ratingTypeObj).Return(nil, mongo.ErrNoDocuments).Once()
+	publicRepo.Mock.On("GetRatingFormulaByRatingTypeIdAndSourceType", ratingid, requestSummary.SourceType).Return(&ratingFormula, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByTag", mock.Anything, true).Return(nil, nil).Once()
+
+	result, _, msg := publicservice.NewPublicRatingService(logger, repo, publicRepo).GetListRatingSummaryBySourceType(request)
+	assert.Equal(t, message.SuccessMsg, msg)
+	summary := result[0].RatingSummary.(publicresponse.RatingSummaryNumeric)
+	assert.Equal(t, 80, summary.TotalValue, "Total value must be calculated from the verified purchases")
+	assert.Equal(t, 1, summary.TotalReviewer, "Total reviewer must be the count of the verified purchases")
+}
+
+func TestGetRatingBySourceTypeAndSourceUIDLocalized(t *testing.T) {
+	publicRepo := &public_repository_mock.PublicRatingRepositoryMock{Mock: mock.Mock{}}
+	req := publicrequest.GetRatingBySourceTypeAndActorRequest{
+		SourceType: "doctor",
+		SourceUID:  "894",
+		Lang:       message.LanguageID,
+	}
+	ratingId, _ := primitive.ObjectIDFromHex("629ec0736f3c2761ba2dc867")
+	ratingTypeId, _ := primitive.ObjectIDFromHex("62c4f03b6d90d90d6594fab5")
+	ratings := []entity.RatingsCol{{ID: ratingId, SourceUid: "894", SourceType: "doctor", RatingTypeId: ratingTypeId.Hex()}}
+	likert := entity.RatingTypesLikertCol{
+		ID:              ratingTypeId,
+		Description:     &description,
+		DescriptionI18n: entity.LocalizedText{message.LanguageID: "Kepuasan layanan"},
+		NumStatements:   2,
+		Statements: []entity.LikertStatement{
+			{Key: "satisfied", Label: "Satisfied", LabelI18n: entity.LocalizedText{message.LanguageID: "Puas", message.LanguageEN: "Satisfied"}},
+			{Key: "unsatisfied", Label: "Unsatisfied"},
+		},
+	}
+	publicRepo.Mock.On("GetRatingsBySourceTypeAndActor", req.SourceType, req.SourceUID, publicrequest.GetRatingBySourceTypeAndActorFilter{}).Return(ratings, nil).Once()
+	publicRepo.Mock.On("GetRatingTypeLikertById", ratingTypeId).Return(likert, nil).Once()
+
+	result, msg := service.NewRatingService(logger, ratingRepository, publicRepo, medicalFacility, ratingMpRepository).GetRatingBySourceTypeAndActor(req)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	likertResp := result.Ratings[0].(*publicresponse.PublicRatingLikertResponse)
+	assert.Equal(t, "Kepuasan layanan", likertResp.RatingDescription)
+	assert.Equal(t, "Puas", likertResp.RatingStatements[0].Label)
+	assert.Equal(t, "Puas", *likertResp.RatingStatement01)
+	assert.Equal(t, "Unsatisfied", *likertResp.RatingStatement02, "Label must fall back to the statement label")
+}
+
+func TestGetRatingSummaryBySourceTypeLikertLocalized(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	publicRepo := &public_repository_mock.PublicRatingRepositoryMock{Mock: mock.Mock{}}
+	idObj, _ := primitive.ObjectIDFromHex(idDummy1)
+	likertId := "629dce7bf1f26275e0d84820"
+	likertObj, _ := primitive.ObjectIDFromHex(likertId)
+	request := requestSummary
+	request.Lang = message.LanguageID
+
+	ratingDatas := []entity.RatingsCol{
+		{
+			ID:              idObj,
+			Description:     &description,
+			DescriptionI18n: entity.LocalizedText{message.LanguageEN: "Service"},
+			SourceUid:       "3310",
+			SourceType:      requestSummary.SourceType,
+			RatingTypeId:    likertId,
+		},
+	}
+	aggregates := map[string]entity.RatingAggregateCol{
+		idDummy1: {RatingID: idDummy1, Count: 2, Histogram: map[string]int64{"friendly": 2}},
+	}
+	likert := entity.RatingTypesLikertCol{
+		ID:            likertObj,
+		NumStatements: 1,
+		Statements:    []entity.LikertStatement{{Key: "friendly", Label: "Friendly", LabelI18n: entity.LocalizedText{message.LanguageID: "Ramah"}}},
+	}
+	publicRepo.Mock.On("GetPublicRatingsByParams", request.Limit, request.Page, "updated_at", filterSummary).Return(ratingDatas, &base.Pagination{Records: 1}, nil).Once()
+	repo.Mock.On("GetRatingAggregates", ratingDatas).Return(aggregates, nil).Once()
+	repo.Mock.On("GetRatingTypeLikertByIdAndStatus", likertObj).Return(likert, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByTag", mock.Anything, false).Return(nil, nil).Once()
+
+	result, _, msg := publicservice.NewPublicRatingService(logger, repo, publicRepo).GetListRatingSummaryBySourceType(request)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, description, *result[0].Description, "Description must fall back without a text in the language")
+	summary := result[0].RatingSummary.(publicresponse.RatingSummaryLikert)
+	statement := summary.ValueList[0].(map[string]interface{})
+	assert.Equal(t, "Ramah", statement["value"])
+	assert.Equal(t, int64(2), statement["total_reviewer"])
+}
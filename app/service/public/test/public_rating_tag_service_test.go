@@ -0,0 +1,79 @@
+package publictest
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	publicrequest "go-klikdokter/app/model/request/public"
+	publicresponse "go-klikdokter/app/model/response/public"
+	"go-klikdokter/app/repository/public/public_repository_mock"
+	"go-klikdokter/app/repository/repository_mock"
+	publicservice "go-klikdokter/app/service/public"
+	"go-klikdokter/helper/message"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetListDetailRatingSummaryWithTags(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	filter := publicrequest.FilterRatingSummary{SourceType: "product", SourceUid: []string{"1234"}}
+	input := requestSummaryMpDetail
+	input.Lang = "en"
+
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupBySource", filter).Return([]publicresponse.PublicRatingSubGroupBySourceMp{{
+		ID:            publicresponse.StructGroupSource{SourceUID: "1234", SourceType: "product"},
+		TotalValue:    9,
+		TotalReviewer: 2,
+		ArrayValue:    []map[string]int{{"key": 5, "value": 1}, {"key": 4, "value": 1}},
+	}}, nil).Once()
+	publicRepo.Mock.On("GetRatingFormulaBySourceType", "product").Return(&entity.RatingFormulaCol{Formula: "sum / count"}, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByTag", filter, "source_uid").Return([]publicresponse.PublicRatingSubGroupByTag{
+		{ID: publicresponse.StructGroupTag{UID: "1234", Key: "original_product"}, Count: 2},
+		{ID: publicresponse.StructGroupTag{UID: "1234", Key: "removed_tag"}, Count: 1},
+	}, nil).Once()
+	repo.Mock.On("GetSubmissionTags", "product").Return([]entity.SubmissionTagCol{
+		{SourceType: "product", Key: "original_product", Label: "Produk original", LabelI18n: entity.LocalizedText{"en": "Original product"}},
+	}, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByDimension", filter, "source_uid").Return(nil, nil).Once()
+
+	result, msg := publicservice.NewPublicRatingMpService(logger, repo, publicRepo).GetListDetailRatingSummaryBySourceType(input)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, []publicresponse.PublicRatingTagSummary{
+		{Key: "original_product", Label: "Original product", Count: 2},
+		{Key: "removed_tag", Label: "removed_tag", Count: 1},
+	}, result[0].Tags)
+}
+
+func TestGetRatingSummaryStoreProductWithTags(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	publicRepo := &public_repository_mock.PublicRatingMpRepository{Mock: mock.Mock{}}
+	filter := publicrequest.FilterRatingSummary{SourceType: "product", StoreUID: []string{"1"}}
+	input := requestSummaryStoreProduct
+	input.Lang = "en"
+
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByStoreSource", filter).Return([]publicresponse.PublicRatingSubGroupByStoreSourceMp{{
+		ID:            publicresponse.StructGroupStoreSource{StoreUID: "1", SourceType: "product"},
+		TotalValue:    5,
+		TotalReviewer: 1,
+		ArrayValue:    []map[string]int{{"key": 5, "value": 1}},
+	}}, nil, nil).Once()
+	publicRepo.Mock.On("GetRatingFormulaBySourceType", "product").Return(&entity.RatingFormulaCol{Formula: "sum / count"}, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByDimension", filter, "store_uid").Return(nil, nil).Once()
+	publicRepo.Mock.On("GetPublicRatingSubmissionsGroupByTag", filter, "store_uid").Return([]publicresponse.PublicRatingSubGroupByTag{
+		{ID: publicresponse.StructGroupTag{UID: "1", Key: "original_product"}, Count: 3},
+	}, nil).Once()
+	repo.Mock.On("GetSubmissionTags", "product").Return([]entity.SubmissionTagCol{
+		{SourceType: "product", Key: "original_product", Label: "Produk original", LabelI18n: entity.LocalizedText{"en": "Original product"}},
+	}, nil).Once()
+
+	result, msg := publicservice.NewPublicRatingMpService(logger, repo, publicRepo).GetRatingSummaryStoreProduct(context.TODO(), input)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, []publicresponse.PublicRatingTagSummary{{Key: "original_product", Label: "Original product", Count: 3}}, result[0].Tags)
+	publicRepo.AssertExpectations(t)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"go-klikdokter/pkg/util"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// validateRatingDimensions checks the dimensions of the review against the registry of the source types,
+// a dimension is given once and its value must be valid for the numeric rating type of the dimension
+func validateRatingDimensions(sourceType string, values []entity.DimensionValue, findRatingTypeNum func(ratingType string) (*entity.RatingTypesNumCol, error)) message.Message {
+	if len(values) == 0 {
+		return message.SuccessMsg
+	}
+	dimensions := global.GetRatingDimensionsBySourceType(sourceType)
+	given := make(map[string]bool, len(values))
+	for _, value := range values {
+		dimension, ok := findRatingDimension(dimensions, value.Name)
+		if !ok {
+			return message.ErrDimensionNotExist
+		}
+		if given[value.Name] {
+			return message.ErrDuplicateDimension
+		}
+		given[value.Name] = true
+
+		ratingTypeNum, err := findRatingTypeNum(dimension.RatingType)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return message.ErrRatingTypeNotExist
+			}
+			return message.ErrDB
+		}
+		if ratingTypeNum == nil {
+			return message.ErrRatingTypeNotExist
+		}
+		if msg := util.ValidateTypeNumeric(ratingTypeNum, value.Value); msg.Code != message.SuccessCode {
+			return msg
+		}
+	}
+	return message.SuccessMsg
+}
+
+func findRatingDimension(dimensions []entity.RatingDimension, name string) (entity.RatingDimension, bool) {
+	for _, dimension := range dimensions {
+		if dimension.Name == name {
+			return dimension, true
+		}
+	}
+	return entity.RatingDimension{}, false
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/model/response"
+	"go-klikdokter/app/repository"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	util_formula "go-klikdokter/pkg/util/formula"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// swagger:route POST /rating-formula/preview RatingFormula previewRatingFormula
+// Preview Rating Formula, compares the scores and ranks of the sources with the current and the candidate formula without saving anything
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) PreviewRatingFormula(input request.PreviewRatingFormulaRequest) (*response.RatingFormulaPreviewResponse, message.Message) {
+	result := response.RatingFormulaPreviewResponse{
+		SourceType:        input.SourceType,
+		RatingTypeId:      input.RatingTypeId,
+		Items:             []response.RatingFormulaPreviewItem{},
+		MissingSourceUids: []string{},
+	}
+
+	var current *entity.RatingFormulaCol
+	if input.FormulaId != "" {
+		objectId, err := primitive.ObjectIDFromHex(input.FormulaId)
+		if err != nil {
+			return nil, message.ErrNoData
+		}
+		current, err = s.ratingRepo.GetRatingFormulaById(objectId)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, message.ErrNoData
+			}
+			return nil, message.FailedMsg
+		}
+	} else if global.IsMarketplaceSourceType(input.SourceType) {
+		// the formula of a marketplace source type is the one of the source type
+		var err error
+		current, err = s.ratingMpRepo.GetRatingFormulaBySourceType(input.SourceType)
+		if err != nil {
+			return nil, message.FailedMsg
+		}
+	} else {
+		var err error
+		current, err = s.publicRatingRepo.GetRatingFormulaByRatingTypeIdAndSourceType(input.RatingTypeId, input.SourceType)
+		if err != nil {
+			return nil, message.FailedMsg
+		}
+	}
+	if current != nil {
+		if !current.ID.IsZero() {
+			result.FormulaId = current.ID.Hex()
+		}
+		result.SourceType = current.SourceType
+		result.RatingTypeId = current.RatingTypeId
+		result.CurrentFormula = current.Formula
+		result.CurrentVersion = current.Version
+	}
+
+	result.CandidateFormula = input.Formula
+	if result.CandidateFormula == "" {
+		version, err := s.ratingRepo.GetRatingFormulaVersion(input.FormulaId, input.Version)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, message.ErrNoData
+			}
+			return nil, message.FailedMsg
+		}
+		result.CandidateFormula = version.Formula
+	}
+	if msg := validateFormula(result.CandidateFormula); msg != nil {
+		return nil, *msg
+	}
+
+	sources, err := s.getRatingFormulaPreviewSources(result.SourceType, result.RatingTypeId, input.SourceUids)
+	if err != nil {
+		return nil, message.FailedMsg
+	}
+
+	found := map[string]bool{}
+	var currentScores, candidateScores []float64
+	for _, source := range sources {
+		variables := source.variables
+		// one load for the variables of both formulas
+		err = variables.Load(result.CurrentFormula+" "+result.CandidateFormula, source.loader)
+		if err != nil {
+			return nil, message.FailedMsg
+		}
+
+		item := response.RatingFormulaPreviewItem{
+			SourceUid:     source.sourceUid,
+			RatingId:      source.ratingId,
+			TotalReviewer: variables.Count,
+		}
+		if result.CurrentFormula != "" {
+			item.CurrentScore, err = evaluatePreviewScore(result.CurrentFormula, variables)
+			if err != nil {
+				return nil, message.FailedMsg
+			}
+			item.CurrentTotalValue = int(math.Floor(item.CurrentScore + 0.5))
+		}
+		item.CandidateScore, err = evaluatePreviewScore(result.CandidateFormula, variables)
+		if err != nil {
+			return nil, message.Message{
+				Code:    message.ErrInvalidFormula.Code,
+				Message: message.ErrInvalidFormula.Message + ": " + err.Error(),
+			}
+		}
+		item.CandidateTotalValue = int(math.Floor(item.CandidateScore + 0.5))
+
+		found[source.sourceUid] = true
+		currentScores = append(currentScores, item.CurrentScore)
+		candidateScores = append(candidateScores, item.CandidateScore)
+		result.Items = append(result.Items, item)
+	}
+
+	for i := range result.Items {
+		if result.CurrentFormula != "" {
+			result.Items[i].CurrentRank = rankOf(result.Items[i].CurrentScore, currentScores)
+		}
+		result.Items[i].CandidateRank = rankOf(result.Items[i].CandidateScore, candidateScores)
+		if result.Items[i].CurrentRank > 0 {
+			result.Items[i].RankChange = result.Items[i].CurrentRank - result.Items[i].CandidateRank
+		}
+	}
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		if result.Items[i].CandidateRank != result.Items[j].CandidateRank {
+			return result.Items[i].CandidateRank < result.Items[j].CandidateRank
+		}
+		return result.Items[i].SourceUid < result.Items[j].SourceUid
+	})
+
+	for _, sourceUid := range input.SourceUids {
+		if !found[sourceUid] {
+			result.MissingSourceUids = append(result.MissingSourceUids, sourceUid)
+		}
+	}
+	return &result, message.SuccessMsg
+}
+
+// ratingFormulaPreviewSource is a source compared by the preview with the variables of its public submissions
+type ratingFormulaPreviewSource struct {
+	sourceUid string
+	// empty for a marketplace source
+	ratingId  string
+	variables util_formula.Variables
+	loader    util_formula.Loader
+}
+
+// getRatingFormulaPreviewSources returns the sources of the preview having public submissions, the submissions of
+// a marketplace source type are read from ratingSubMpCol the same way its final rating is calculated
+func (s *ratingServiceImpl) getRatingFormulaPreviewSources(sourceType, ratingTypeId string, sourceUids []string) ([]ratingFormulaPreviewSource, error) {
+	var sources []ratingFormulaPreviewSource
+	if global.IsMarketplaceSourceType(sourceType) {
+		for _, sourceUid := range sourceUids {
+			groups, err := s.ratingMpRepo.GetRatingSubsGroupByValue(sourceUid, sourceType)
+			if err != nil {
+				return nil, err
+			}
+			if len(groups) == 0 {
+				continue
+			}
+			variables := util_formula.Variables{Histogram: make(map[string]int64, len(groups))}
+			for _, group := range groups {
+				variables.Sum += float64(group.ConvertedValue * group.Total)
+				variables.Count += int64(group.Total)
+				variables.Histogram[strconv.Itoa(group.ConvertedValue)] = int64(group.Total)
+			}
+			sources = append(sources, ratingFormulaPreviewSource{
+				sourceUid: sourceUid,
+				variables: variables,
+				loader:    repository.NewRatingMpFormulaLoader(s.ratingMpRepo, sourceUid, sourceType, ratingTypeId),
+			})
+		}
+		return sources, nil
+	}
+
+	filter := request.RatingFilter{
+		SourceUid:    sourceUids,
+		RatingTypeId: []string{ratingTypeId},
+		SourceType:   sourceType,
+	}
+	ratings, _, err := s.ratingRepo.GetRatingsByParams(len(sourceUids), 1, 1, "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+	aggregates, err := s.ratingRepo.GetRatingAggregates(ratings)
+	if err != nil {
+		return nil, err
+	}
+	for _, rating := range ratings {
+		aggregate := aggregates[rating.ID.Hex()]
+		sources = append(sources, ratingFormulaPreviewSource{
+			sourceUid: rating.SourceUid,
+			ratingId:  rating.ID.Hex(),
+			variables: util_formula.Variables{Sum: aggregate.Sum, Count: aggregate.Count, Histogram: aggregate.Histogram},
+			loader:    s.newRatingFormulaLoader(rating),
+		})
+	}
+	return sources, nil
+}
+
+// newRatingFormulaLoader loads the formula variables of a rating the same way the public summary does
+func (s *ratingServiceImpl) newRatingFormulaLoader(rating entity.RatingsCol) util_formula.Loader {
+	return util_formula.Loader{
+		Global: func() (float64, int64, error) {
+			return s.ratingRepo.GetGlobalSumCountBySourceType(rating.SourceType, rating.RatingTypeId)
+		},
+		Decayed: func(halfLife time.Duration) (float64, float64, error) {
+			return s.ratingRepo.GetDecayedSumCountByRatingId(rating.ID.Hex(), halfLife)
+		},
+	}
+}
+
+// evaluatePreviewScore evaluates the formula, a score which is not a finite number is 0 so the preview stays encodable
+func evaluatePreviewScore(formula string, variables util_formula.Variables) (float64, error) {
+	score, err := util_formula.Evaluate(formula, variables)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		return 0, nil
+	}
+	return score, nil
+}
+
+// rankOf returns 1 + the number of higher scores, equal scores share a rank
+func rankOf(score float64, scores []float64) int {
+	rank := 1
+	for _, other := range scores {
+		if other > score {
+			rank++
+		}
+	}
+	return rank
+}
+
+// swagger:route GET /rating-formula/{id}/versions RatingFormula getRatingFormulaVersions
+// Get Rating Formula Versions, latest first
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) GetRatingFormulaVersions(input request.GetRatingFormulaVersionsRequest) ([]entity.RatingFormulaVersionCol, *base.Pagination, message.Message) {
+	if input.Page <= 0 {
+		input.Page = 1
+	}
+	if input.Limit <= 0 {
+		input.Limit = 50
+	}
+	formula, msg := s.GetRatingFormulaById(request.GetRatingFormulaRequest{Id: input.Id})
+	if msg.Code != message.SuccessMsg.Code {
+		return nil, nil, msg
+	}
+
+	versions, pagination, err := s.ratingRepo.GetRatingFormulaVersions(input.Id, input.Page, input.Limit)
+	if err != nil {
+		return nil, nil, message.FailedMsg
+	}
+	results := make([]entity.RatingFormulaVersionCol, 0, len(versions))
+	for _, version := range versions {
+		version.IsActive = version.Version == formula.Version
+		results = append(results, version)
+	}
+	return results, pagination, message.SuccessMsg
+}
+
+// swagger:route POST /rating-formula/{id}/versions RatingFormula createRatingFormulaVersion
+// Create a draft Rating Formula Version, it is used once activated
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) CreateRatingFormulaVersion(input request.CreateRatingFormulaVersionRequest) (*entity.RatingFormulaVersionCol, message.Message) {
+	if msg := validateFormula(input.Formula); msg != nil {
+		return nil, *msg
+	}
+	formula, msg := s.GetRatingFormulaById(request.GetRatingFormulaRequest{Id: input.Id})
+	if msg.Code != message.SuccessMsg.Code {
+		return nil, msg
+	}
+
+	draft := *formula
+	draft.Formula = input.Formula
+	if input.SourceType != "" {
+		draft.SourceType = input.SourceType
+	}
+	if input.RatingTypeId != "" {
+		draft.RatingTypeId = input.RatingTypeId
+	}
+	if input.RatingType != "" {
+		draft.RatingType = input.RatingType
+	}
+	version, err := s.ratingRepo.CreateRatingFormulaVersion(draft, strings.TrimSpace(input.Note), fmt.Sprint(input.JWTObj.Fullname))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, message.ErrDuplicateType
+		}
+		return nil, message.FailedMsg
+	}
+	return version, message.SuccessMsg
+}
+
+// swagger:route PUT /rating-formula/{id}/versions/{version}/activate RatingFormula activateRatingFormulaVersion
+// Activate Rating Formula Version, activating an older version rolls the formula back
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) ActivateRatingFormulaVersion(input request.ActivateRatingFormulaVersionRequest) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(input.Id)
+	if err != nil {
+		return message.ErrNoData
+	}
+	version, err := s.ratingRepo.GetRatingFormulaVersion(input.Id, input.Version)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.ErrNoData
+		}
+		return message.FailedMsg
+	}
+	// the variables and functions may have changed since the version was saved
+	if msg := validateFormula(version.Formula); msg != nil {
+		return *msg
+	}
+
+	err = s.ratingRepo.ActivateRatingFormulaVersion(objectId, input.Version, fmt.Sprint(input.JWTObj.Fullname))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.ErrNoData
+		}
+		return message.FailedMsg
+	}
+	return message.SuccessMsg
+}
@@ -13,19 +13,18 @@ import (
 	"go-klikdokter/app/model/response"
 	publicresponse "go-klikdokter/app/model/response/public"
 	"go-klikdokter/app/repository"
-	helper_dapr "go-klikdokter/helper/dapr"
 	global "go-klikdokter/helper/global"
 	"go-klikdokter/helper/message"
 	"go-klikdokter/helper/thumbor"
 	"go-klikdokter/pkg/util"
-	util_media "go-klikdokter/pkg/util/media"
+	util_formula "go-klikdokter/pkg/util/formula"
+	util_moderation "go-klikdokter/pkg/util/moderation"
+	util_search "go-klikdokter/pkg/util/search"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-kit/log"
-	"github.com/go-kit/log/level"
-	"github.com/vjeantet/govaluate"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -35,6 +34,15 @@ type RatingMpService interface {
 	CreateRatingSubmissionMp(ctx context.Context, input request.CreateRatingSubmissionRequest) ([]response.CreateRatingSubmissionMpResponse, message.Message)
 	UpdateRatingSubmission(ctx context.Context, input request.UpdateRatingSubmissionRequest) message.Message
 	ReplyAdminRatingSubmission(input request.ReplyAdminRatingSubmissionRequest) message.Message
+	HideReplyRatingSubmission(input request.HideReplyRatingSubmissionRequest) message.Message
+	GetListModerationQueue(input request.ListModerationQueueRequest) ([]response.ModerationQueueResponse, *base.Pagination, message.Message)
+	ModerateRatingSubmission(input request.ModerateRatingSubmissionRequest) message.Message
+	CreateRatingSubHelpfulMp(input request.CreateRatingSubHelpfulRequest) (response.RatingSubHelpfulResponse, message.Message)
+	DeleteRatingSubmission(id, deletedBy string) message.Message
+	RestoreRatingSubmission(id string) message.Message
+
+	// Final rating
+	RepublishFinalRatings(input request.RepublishFinalRatingsRequest) (int, message.Message)
 
 	// unused
 	GetRatingSubmissionMp(id string) (*response.RatingSubmissionMpResponse, message.Message)
@@ -276,6 +284,13 @@ func (s *ratingMpServiceImpl) CreateRatingSubmissionMp(ctx context.Context, inpu
 		}
 	}
 	
+	if sourceType == "" {
+		return result, message.ErrRatingTypeNotExist
+	}
+	if msg := validateSourceTypeContent(sourceType, input.Comment, input.Media); msg != message.SuccessMsg {
+		return result, msg
+	}
+
 	// set user_id as user_id_legacy must be filled
 	input.UserID = input.UserIDLegacy
 	// Validate displayname
@@ -290,6 +305,20 @@ func (s *ratingMpServiceImpl) CreateRatingSubmissionMp(ctx context.Context, inpu
 
 	originalSourceTransID := input.SourceTransID
 
+	// the order of a redeemed invitation is confirmed, the submission is a verified purchase
+	var invitation *entity.ReviewInvitationCol
+	if input.InvitationToken != "" {
+		var msg message.Message
+		invitation, msg = getRedeemableReviewInvitation(input.InvitationToken, *input.UserID, input.SourceUID, input.RatingType, s.ratingMpRepo.GetReviewInvitationById)
+		if msg != message.SuccessMsg {
+			return result, msg
+		}
+		originalSourceTransID = invitation.OrderNumber
+		if input.StoreUID == "" {
+			input.StoreUID = invitation.StoreUID
+		}
+	}
+
 	ratingTypeNum, err := s.ratingMpRepo.FindRatingTypeNumByRatingType(input.RatingType)
 
 	if err != nil {
@@ -300,14 +329,38 @@ func (s *ratingMpServiceImpl) CreateRatingSubmissionMp(ctx context.Context, inpu
 		return result, message.ErrRatingTypeNotExist
 	}
 
-	// Concate source_trans_id, source_type, source_uid, user_id
-	input.SourceTransID = originalSourceTransID + "||" + sourceType + "||" + input.SourceUID + "||" + *input.UserID
+	if msg := validateRatingDimensions(sourceType, input.Dimensions, s.ratingMpRepo.FindRatingTypeNumByRatingType); msg != message.SuccessMsg {
+		return result, msg
+	}
+	if msg := validateSubmissionTags(sourceType, input.Value, input.Tags, s.ratingMpRepo.GetSubmissionTags); msg != message.SuccessMsg {
+		return result, msg
+	}
+
+	input.SourceTransID = originalSourceTransID
 
-	// A submission with a combination of either (rating_id and user_id) OR (rating_id and user_id_legacy) is allowed once
-	userHasSubmitRating, _ := checkUserHaveSubmitRatingMpBySourceTransID(input.SourceTransID, s)
+	// A user submits once per order line, the source type and uid of the order
+	userHasSubmitRating, _ := s.ratingMpRepo.FindRatingSubmissionByOrderLine(originalSourceTransID, sourceType, input.SourceUID, *input.UserID)
 	if userHasSubmitRating != nil {
 		return result, message.UserRated
 	}
+
+	if invitation == nil {
+		// a submission without token still redeems the invitation of its order line
+		invitation, err = s.ratingMpRepo.GetReviewInvitationByOrderLine(originalSourceTransID, *input.UserID, sourceType, input.SourceUID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return result, message.ErrDB
+		}
+		if invitation != nil && invitation.Status == entity.ReviewInvitationStatusSubmitted {
+			return result, message.UserRated
+		}
+		if invitation != nil && (invitation.Status != entity.ReviewInvitationStatusPending || !invitation.ExpiredAt.After(time.Now())) {
+			invitation = nil
+		}
+	}
+	var invitationId *primitive.ObjectID
+	if invitation != nil {
+		invitationId = &invitation.ID
+	}
 	// process media_path
 	var media []entity.MediaObj
 	var isWithMedia bool
@@ -320,57 +373,73 @@ func (s *ratingMpServiceImpl) CreateRatingSubmissionMp(ctx context.Context, inpu
 		}
 	}
 	// end process media_path
+	// Comment is moderated before it goes public, a submission caught by the spam heuristics waits for moderation too
+	moderationStatus, moderationFlags := util_moderation.GetModerationStatus(input.Comment)
+	moderationStatus, moderationFlags = applySpamFlags(moderationStatus, moderationFlags,
+		getSpamFlags(s.logger, s.ratingMpRepo, input.Comment, input.IPAddress, input.AccountCreatedAt))
 	value, _ := strconv.Atoi(input.Value)
 	saveReq = append(saveReq, entity.RatingSubmissionMp{
 		// RatingID:      rating.ID.Hex(),
-		Value:         value,
-		UserID:        input.UserID,
-		UserIDLegacy:  input.UserIDLegacy,
-		DisplayName:   input.DisplayName,
-		Comment:       &input.Comment,
-		Avatar:        input.Avatar,
-		IPAddress:     input.IPAddress,
-		UserAgent:     input.UserAgent,
-		SourceTransID: input.SourceTransID,
-		UserPlatform:  input.UserPlatform,
-		IsAnonymous:   input.IsAnonymous,
-		SourceUID:     input.SourceUID,
-		SourceType:    sourceType,
-		Media:         media,
-		IsWithMedia:   isWithMedia,
-		OrderNumber:   originalSourceTransID,
-		RatingTypeID:  ratingTypeNum.ID.Hex(),
-		Cancelled:     false,
-		StoreUID:      input.StoreUID,
+		Value:              value,
+		UserID:             input.UserID,
+		UserIDLegacy:       input.UserIDLegacy,
+		DisplayName:        input.DisplayName,
+		Comment:            &input.Comment,
+		Avatar:             input.Avatar,
+		IPAddress:          input.IPAddress,
+		UserAgent:          input.UserAgent,
+		SourceTransID:      input.SourceTransID,
+		UserPlatform:       input.UserPlatform,
+		IsAnonymous:        input.IsAnonymous,
+		SourceUID:          input.SourceUID,
+		SourceType:         sourceType,
+		Media:              media,
+		IsWithMedia:        isWithMedia,
+		OrderNumber:        originalSourceTransID,
+		RatingTypeID:       ratingTypeNum.ID.Hex(),
+		Cancelled:          false,
+		StoreUID:           input.StoreUID,
+		ModerationStatus:   moderationStatus,
+		ModerationFlags:    moderationFlags,
+		InvitationID:       invitationId,
+		IsVerifiedPurchase: invitationId != nil,
+		Dimensions:         input.Dimensions,
+		Tags:               input.Tags,
 	})
 
 	if len(saveReq) == 0 {
 		return result, message.ErrTypeNotFound
 	}
 
-	ratingSubs, err := s.ratingMpRepo.CreateRatingSubmission(saveReq)
-	if err != nil {
-		return result, message.ErrSaveData
+	// image house keeping and the review of product & store sent to payment svc are delivered by the outbox dispatcher
+	var outbox []entity.OutboxCol
+	for _, mp := range media {
+		outbox = append(outbox, entity.OutboxCol{
+			Target:  entity.OutboxTargetMediaHouseKeeping,
+			Payload: entity.OutboxPayload{MediaUID: mp.UID},
+		})
 	}
+	outbox = append(outbox, entity.OutboxCol{
+		Target:  entity.OutboxTargetPaymentReviewProduct,
+		Payload: entity.OutboxPayload{OrderNumber: originalSourceTransID, SourceType: sourceType, SourceUID: input.SourceUID},
+	})
 
-	ratingSubsID := ""
-
-	for _, val := range *ratingSubs {
-		ratingSubsID = val.ID.Hex()
+	ratingSubs, err := s.ratingMpRepo.CreateRatingSubmission(saveReq, outbox)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return result, message.ErrInvitationNotPending
+		}
+		// a concurrent submission of the same order line
+		if mongo.IsDuplicateKeyError(err) {
+			return result, message.UserRated
+		}
+		return result, message.ErrSaveData
 	}
 
-	go func() {
-		// trigger image house keeping
-		util_media.ImageHouseKeeping(ctx, s.logger, media, ratingSubsID)
-		// send review for product & store to payment svc
-		if ratingSubs != nil && len(*ratingSubs) > 0 {
-			ratingSub := *ratingSubs
-			util.UpdateReviewProductStore(originalSourceTransID, sourceType, input.SourceUID, ratingSub[0].ID.Hex(), s.logger)
+	if ratingSubs != nil && moderationStatus == entity.ModerationStatusApproved {
+		for _, ratingSub := range *ratingSubs {
+			s.emitFinalRatingOfSubmission(correlationId, ratingSub)
 		}
-	}()
-
-	if input.RatingType == "rating_for_product" {
-		go GetFinalRating(s, correlationId, sourceType, input.SourceUID)
 	}
 	
 	return result, message.SuccessMsg
@@ -401,6 +470,11 @@ func (s *ratingMpServiceImpl) UpdateRatingSubmission(ctx context.Context ,input
 		return message.ErrUserPermissionUpdate
 	}
 
+	// edit window of the source type and max edits
+	if msg := validateRatingSubEdit(ratingSubmission.SourceType, ratingSubmission.CreatedAt, ratingSubmission.EditCounter); msg.Code != message.SuccessCode {
+		return msg
+	}
+
 	// set update data ratingSub
 	var timeUpdate time.Time
 	timeUpdate = time.Now().In(util.Loc)
@@ -417,22 +491,46 @@ func (s *ratingMpServiceImpl) UpdateRatingSubmission(ctx context.Context ,input
 	}
 	// end process media_path
 	value, _ := strconv.Atoi(*input.Value)
+	// the tags must still match the value, the tags of the submission are kept when none are given
+	tags := ratingSubmission.Tags
+	if input.Tags != nil {
+		tags = input.Tags
+	}
+	if input.Tags != nil || value != ratingSubmission.Value {
+		if msg := validateSubmissionTags(ratingSubmission.SourceType, *input.Value, tags, s.ratingMpRepo.GetSubmissionTags); msg != message.SuccessMsg {
+			return msg
+		}
+	}
+	// edited comment goes through moderation again
+	if ratingSubmission.Comment == nil || *ratingSubmission.Comment != input.Comment {
+		ratingSubmission.ModerationStatus, ratingSubmission.ModerationFlags = util_moderation.GetModerationStatus(input.Comment)
+	}
 	ratingSubmission.Comment = &input.Comment
 	ratingSubmission.Value = value
 	ratingSubmission.Media = media
 	ratingSubmission.IsWithMedia = isWithMedia
+	ratingSubmission.Tags = tags
 	ratingSubmission.UpdatedAt = timeUpdate
+	ratingSubmission.EditCounter++
+	ratingSubmission.EditedAt = &timeUpdate
+	ratingSubmission.EditedBy = *input.UserIDLegacy
 
-	// Update
-	errC := s.ratingMpRepo.UpdateRatingSubmission(*ratingSubmission, objectRatingSubmissionId)
+	// image house keeping is delivered by the outbox dispatcher
+	var outbox []entity.OutboxCol
+	for _, mp := range media {
+		outbox = append(outbox, entity.OutboxCol{
+			Target:  entity.OutboxTargetMediaHouseKeeping,
+			Payload: entity.OutboxPayload{MediaUID: mp.UID},
+		})
+	}
+
+	// Update, the replaced version is kept in the edit history
+	errC := s.ratingMpRepo.EditRatingSubmission(*ratingSubmission, objectRatingSubmissionId, outbox)
 	if errC != nil {
 		return message.ErrSaveData
 	}
-
-	go func() {
-		// trigger image house keeping
-		util_media.ImageHouseKeeping(ctx, s.logger, media, ratingSubmission.ID.Hex())
-	}()
+	// value or moderation status may have changed, unchanged final ratings are not published again
+	s.emitFinalRatingOfSubmission(fmt.Sprint(ctx.Value(middleware.CorrelationIdContextKey)), *ratingSubmission)
 
 	return message.SuccessMsg
 
@@ -458,10 +556,32 @@ func (s *ratingMpServiceImpl) ReplyAdminRatingSubmission(input request.ReplyAdmi
 		return message.RatingSubmissionNotFound
 	}
 
+	// merchant can only reply rating submission of their own store
+	if !input.JWTObj.IsAdmin() {
+		if input.JWTObj.StoreUID == "" || input.JWTObj.StoreUID != ratingSubmission.StoreUID {
+			return message.ErrReplyStoreNotAllowed
+		}
+	}
+
+	// keep previous reply as history
+	timeNow := time.Now().In(util.Loc)
+	if ratingSubmission.Reply != "" {
+		history := entity.ReplyHistoryObj{
+			Reply:   ratingSubmission.Reply,
+			ReplyBy: ratingSubmission.ReplyBy,
+		}
+		if ratingSubmission.RepliedAt != nil {
+			history.RepliedAt = *ratingSubmission.RepliedAt
+		}
+		ratingSubmission.ReplyHistory = append(ratingSubmission.ReplyHistory, history)
+		ratingSubmission.ReplyEdited = true
+	}
+
 	// set update data ratingSub
-	ratingSubmission.UpdatedAt = time.Now().In(util.Loc)
+	ratingSubmission.UpdatedAt = timeNow
 	ratingSubmission.Reply = input.Reply
 	ratingSubmission.ReplyBy = fmt.Sprint(input.JWTObj.Fullname)
+	ratingSubmission.RepliedAt = &timeNow
 
 	// Update
 	errC := s.ratingMpRepo.UpdateRatingSubmission(*ratingSubmission, objectRatingSubmissionId)
@@ -472,6 +592,108 @@ func (s *ratingMpServiceImpl) ReplyAdminRatingSubmission(input request.ReplyAdmi
 	return message.SuccessMsg
 }
 
+// swagger:route PUT /rating-submissions/reply/{id}/hide RatingSubmission ReqHideReplyRatingSubmissionBody
+// Hide or Show Reply of Rating Submission By ID
+//
+// security:
+// - Bearer: []
+// responses:
+//  401: SuccessResponse
+//  200: SuccessResponse
+func (s *ratingMpServiceImpl) HideReplyRatingSubmission(input request.HideReplyRatingSubmissionRequest) message.Message {
+	objectRatingSubmissionId, err := primitive.ObjectIDFromHex(input.ID)
+	if err != nil {
+		return message.RatingSubmissionNotFound
+	}
+	ratingSubmission, err := s.ratingMpRepo.GetRatingSubmissionById(objectRatingSubmissionId)
+	if err != nil || ratingSubmission == nil {
+		return message.RatingSubmissionNotFound
+	}
+
+	ratingSubmission.UpdatedAt = time.Now().In(util.Loc)
+	ratingSubmission.ReplyHidden = input.Hidden
+	ratingSubmission.ReplyHiddenBy = ""
+	if input.Hidden {
+		ratingSubmission.ReplyHiddenBy = fmt.Sprint(input.JWTObj.Fullname)
+	}
+
+	errC := s.ratingMpRepo.UpdateRatingSubmission(*ratingSubmission, objectRatingSubmissionId)
+	if errC != nil {
+		return message.ErrSaveData
+	}
+
+	return message.SuccessMsg
+}
+
+func (s *ratingMpServiceImpl) GetListModerationQueue(input request.ListModerationQueueRequest) ([]response.ModerationQueueResponse, *base.Pagination, message.Message) {
+	results := make([]response.ModerationQueueResponse, 0)
+	dir := setModerationQueueDefault(&input)
+
+	filter := request.RatingSubmissionMpFilter{ModerationStatus: input.Status}
+	ratingSubmissions, pagination, err := s.ratingMpRepo.GetListRatingSubmissions(filter, input.Page, input.Limit, "created_at", dir)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return results, pagination, message.ErrDataNotFound
+		}
+		return nil, nil, message.FailedMsg
+	}
+
+	for _, args := range ratingSubmissions {
+		data := response.ModerationQueueResponse{
+			ID:               args.ID.Hex(),
+			RatingID:         args.RatingID,
+			SourceType:       args.SourceType,
+			SourceUID:        args.SourceUID,
+			UserIDLegacy:     args.UserIDLegacy,
+			DisplayName:      args.DisplayName,
+			Value:            strconv.Itoa(args.Value),
+			ModerationStatus: args.ModerationStatus,
+			ModerationFlags:  args.ModerationFlags,
+			ModerationReason: args.ModerationReason,
+			ModeratedBy:      args.ModeratedBy,
+			ModeratedAt:      args.ModeratedAt,
+			CreatedAt:        args.CreatedAt,
+		}
+		if args.Comment != nil {
+			data.Comment = *args.Comment
+		}
+		results = append(results, data)
+	}
+
+	return results, pagination, message.SuccessMsg
+}
+
+func (s *ratingMpServiceImpl) ModerateRatingSubmission(input request.ModerateRatingSubmissionRequest) message.Message {
+	objectRatingSubmissionId, err := primitive.ObjectIDFromHex(input.ID)
+	if err != nil {
+		return message.RatingSubmissionNotFound
+	}
+	ratingSubmission, err := s.ratingMpRepo.GetRatingSubmissionById(objectRatingSubmissionId)
+	if err != nil || ratingSubmission == nil {
+		return message.RatingSubmissionNotFound
+	}
+
+	timeNow := time.Now().In(util.Loc)
+	previousStatus := ratingSubmission.ModerationStatus
+	ratingSubmission.ModerationStatus = input.Status
+	ratingSubmission.ModerationReason = input.Reason
+	ratingSubmission.ModeratedBy = fmt.Sprint(input.JWTObj.Fullname)
+	ratingSubmission.ModeratedAt = &timeNow
+	ratingSubmission.UpdatedAt = timeNow
+
+	errC := s.ratingMpRepo.UpdateRatingSubmission(*ratingSubmission, objectRatingSubmissionId)
+	if errC != nil {
+		return message.ErrSaveData
+	}
+
+	// final rating only counts approved submissions
+	if previousStatus != input.Status {
+		s.emitFinalRatingOfSubmission("", *ratingSubmission)
+	}
+
+	return message.SuccessMsg
+}
+
 func (s *ratingMpServiceImpl) GetRatingSubmissionMp(id string) (*response.RatingSubmissionMpResponse, message.Message) {
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -527,7 +749,8 @@ func (s *ratingMpServiceImpl) GetListRatingSubmissionsMp(input request.ListRatin
 	if input.Limit <= 0 {
 		input.Limit = 50
 	}
-	if input.Sort == "" {
+	// the matches of q come by relevance unless a sort is asked
+	if input.Sort == "" && input.Q == "" {
 		input.Sort = "created_at"
 	}
 
@@ -538,6 +761,7 @@ func (s *ratingMpServiceImpl) GetListRatingSubmissionsMp(input request.ListRatin
 			return nil, nil, message.WrongFilter
 		}
 	}
+	filter.Q = input.Q
 	ratingSubmissions, pagination, err := s.ratingMpRepo.GetListRatingSubmissions(filter, input.Page, input.Limit, input.Sort, dir)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -574,6 +798,7 @@ func (s *ratingMpServiceImpl) GetListRatingSubmissionsMp(input request.ListRatin
 				SourceTransID: args.SourceTransID,
 				Media:         mediaResponse,
 				IsWithMedia:   args.IsWithMedia,
+				Highlight:     util_search.Highlight(*args.Comment, input.Q),
 			})
 		}
 	}
@@ -592,12 +817,6 @@ func checkUserHaveSubmitRatingMp(userId, ratingId, sourceTransId string, s *rati
 	return ratingSubmissionMp, err
 }
 
-func checkUserHaveSubmitRatingMpBySourceTransID(sourceTransId string, s *ratingMpServiceImpl) (*entity.RatingSubmissionMp, error) {
-
-	ratingSubmissionMp, err := s.ratingMpRepo.FindRatingSubmissionBySourceTransID(sourceTransId)
-	return ratingSubmissionMp, err
-}
-
 func filterScoreSubmissionMp(ratingSubmissionsMp entity.RatingSubmissionMp, score []float64) bool {
 	if len(score) == 0 {
 		return true
@@ -679,32 +898,23 @@ func (s *ratingMpServiceImpl) GetListRatingSummaryBySourceType(input request.Get
 
 func (s *ratingMpServiceImpl) summaryRatingLikert(rating entity.RatingsMpCol, ratingLikert entity.RatingTypesLikertCol) (*response.RatingSummaryMpResponse, error) {
 	likertSummary := publicresponse.RatingSummaryLikert{}
-	var myMap map[string]string
-	datas, _ := json.Marshal(ratingLikert)
-	json.Unmarshal(datas, &myMap)
-
 	likertSummary.SourceUID = rating.SourceUid
-	for i := 1; i <= ratingLikert.NumStatements; i++ {
-		var statement string
-		if i < 10 {
-			statement = fmt.Sprint("statement_0", i)
-		} else {
-			statement = fmt.Sprint("statement_", i)
-		}
-		data := myMap[statement]
-		if data != "" {
-			totalCount, err := s.ratingMpRepo.CountRatingSubsByRatingIdAndValue(rating.ID.Hex(), strconv.Itoa(i))
-			if err != nil {
-				return nil, err
-			}
-			likertObjCount := make(map[string]interface{})
-			likertObjCount["seq_id"] = i
-			likertObjCount["value"] = data
-			likertObjCount["total_reviewer"] = totalCount
-			likertSummary.ValueList = append(likertSummary.ValueList, likertObjCount)
-		} else {
-			return nil, errors.New("invalid statement value")
+	statements := ratingLikert.GetStatements()
+	if len(statements) < ratingLikert.NumStatements {
+		return nil, errors.New("invalid statement value")
+	}
+	for i, statement := range statements {
+		totalCount, err := s.ratingMpRepo.CountRatingSubsByRatingIdAndValue(rating.ID.Hex(), statement.Key)
+		if err != nil {
+			return nil, err
 		}
+		likertObjCount := make(map[string]interface{})
+		likertObjCount["seq_id"] = i + 1
+		likertObjCount["key"] = statement.Key
+		likertObjCount["value"] = statement.Label
+		likertObjCount["icon"] = statement.Icon
+		likertObjCount["total_reviewer"] = totalCount
+		likertSummary.ValueList = append(likertSummary.ValueList, likertObjCount)
 	}
 
 	result := response.RatingSummaryMpResponse{
@@ -737,7 +947,7 @@ func (s *ratingMpServiceImpl) summaryRatingNumeric(rating entity.RatingsMpCol, s
 	}
 
 	if formulaRating.Formula != "" {
-		ratingSummary, err := calculateRatingMpValue(rating.SourceUid, formulaRating.Formula, sumCountRatingSubs)
+		ratingSummary, err := calculateRatingMpValue(rating.SourceUid, formulaRating.Formula, sumCountRatingSubs, repository.NewRatingMpFormulaLoader(s.ratingMpRepo, rating.SourceUid, sourceType, formulaRating.RatingTypeId))
 		if err != nil {
 			return nil, err
 		}
@@ -758,7 +968,7 @@ func (s *ratingMpServiceImpl) summaryRatingNumeric(rating entity.RatingsMpCol, s
 	}
 }
 
-func calculateRatingMpValue(sourceUID, formula string, sumCountRatingSubs *publicresponse.PublicSumCountRatingSummaryMp) (publicresponse.RatingSummaryMpNumeric, error) {
+func calculateRatingMpValue(sourceUID, formula string, sumCountRatingSubs *publicresponse.PublicSumCountRatingSummaryMp, loader util_formula.Loader) (publicresponse.RatingSummaryMpNumeric, error) {
 	result := publicresponse.RatingSummaryMpNumeric{}
 	result.SourceUID = sourceUID
 	result.TotalReviewer = sumCountRatingSubs.Count
@@ -771,78 +981,30 @@ func calculateRatingMpValue(sourceUID, formula string, sumCountRatingSubs *publi
 	}
 
 	if formula != "" {
-		expression, err := govaluate.NewEvaluableExpression(formula)
-		if err != nil {
+		variables := util_formula.Variables{Sum: float64(sumCountRatingSubs.Sum), Count: sumCountRatingSubs.Count}
+		if err := variables.Load(formula, loader); err != nil {
 			return result, err
 		}
-		parameters := make(map[string]interface{}, 8)
-		parameters["sum"] = sumCountRatingSubs.Sum
-		parameters["count"] = sumCountRatingSubs.Count
-
-		finalCalc, err := expression.Evaluate(parameters)
+		finalCalc, err := util_formula.Evaluate(formula, variables)
 		if err != nil {
 			return result, err
 		}
-		// totalValue, err := strconv.ParseFloat(fmt.Sprintf("%.1f", finalCalc.(float64)), 64)
-		// if err != nil {
-		//	return result, err
-		// }
 		result.TotalValue = fmt.Sprintf("%.1f", finalCalc)
 	}
 
 	return result, nil
 }
 
-func GetFinalRating(s *ratingMpServiceImpl, correlationId string ,sourceType string, sourceUid string) {
-	if s.logger == nil {
-		return
-	}
-	summarySubsGroupByValue, _ := s.ratingMpRepo.GetRatingSubsGroupByValue(sourceUid, sourceType)
-	totalValue := 0
-	TotalReviewer := 0
-
-	for _, val := range summarySubsGroupByValue {
-		totalValue += val.ConvertedValue * val.Total
-		TotalReviewer += val.Total
-	}
-
-	formulaRating, _  := s.ratingMpRepo.GetRatingFormulaBySourceType(sourceType)
-	
-	if formulaRating == nil {
-		level.Info(s.logger).Log("correlationID", correlationId, "Type", "Create Rating Submission", "err", "Failed Get Formula Rating")	
-		return
-	}
-
-	formula := formulaRating.Formula
-	expression, err := govaluate.NewEvaluableExpression(formula)
-
-	if err != nil {
-		level.Info(s.logger).Log("correlationID", correlationId, "Type", "Create Rating Submission", "err", "Failed Get Expression Formula Rating")
-		return
-	}
-
-	parameters := make(map[string]interface{}, 8)
-	parameters["sum"] = totalValue
-	parameters["count"] = TotalReviewer
-
-	finalCalc, err := expression.Evaluate(parameters)
-	if err != nil {
-		level.Info(s.logger).Log("correlationID", correlationId, "Type", "Create Rating Submission", "err", "Failed Calculate Formula Rating")
-		return
-	}
-	result := fmt.Sprintf("%.1f", finalCalc)
-	level.Info(s.logger).Log("correlationID", correlationId, "Type", "Create Rating Submission","result", result)
-
-	jsonPayload, _ := json.Marshal(map[string]interface{}{
-		"data": map[string]string{
-			"product_uid": sourceUid,
-			"final_rating": result,
-		},
-	})
-		
-	client := helper_dapr.NewDaprHttpClient()
-	response, err := client.PublishEvent("queuing.rnr.ts-final-rating", string(jsonPayload))
-
-	_ = level.Info(s.logger).Log(fmt.Sprintf("Publisher response: %v", response))
-	_ = level.Info(s.logger).Log(fmt.Sprintf("Publisher error: %v", err))
-}
\ No newline at end of file
+// swagger:route POST /helpful-rating-submission-mp/ RatingSubHelpful ReqRatingSubHelpfulBody
+// Create Helpful Rating Submission of a product or store, voting the same again removes the vote and voting the other one switches it
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingMpServiceImpl) CreateRatingSubHelpfulMp(input request.CreateRatingSubHelpfulRequest) (response.RatingSubHelpfulResponse, message.Message) {
+	vote, counter, err := s.ratingMpRepo.ToggleRatingSubHelpful(input)
+	return toRatingSubHelpfulResponse(input, vote, counter, err)
+}
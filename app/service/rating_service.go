@@ -15,13 +15,15 @@ import (
 	"go-klikdokter/helper/config"
 	"go-klikdokter/helper/message"
 	"go-klikdokter/pkg/util"
+	util_formula "go-klikdokter/pkg/util/formula"
+	util_moderation "go-klikdokter/pkg/util/moderation"
+	util_search "go-klikdokter/pkg/util/search"
 	"math"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-kit/log"
-	"github.com/go-kit/log/level"
 	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -32,7 +34,8 @@ type RatingService interface {
 	CreateRatingTypeNum(input request.CreateRatingTypeNumRequest) (*entity.RatingTypesNumCol, message.Message)
 	UpdateRatingTypeNum(input request.EditRatingTypeNumRequest) message.Message
 	GetRatingTypeNumById(input request.GetRatingTypeNumRequest) (*entity.RatingTypesNumCol, message.Message)
-	DeleteRatingTypeNumById(input request.GetRatingTypeNumRequest) message.Message
+	DeleteRatingTypeNumById(input request.GetRatingTypeNumRequest, deletedBy string) message.Message
+	RestoreRatingTypeNumById(id string) message.Message
 	GetRatingTypeNums(input request.GetRatingTypeNumsRequest) ([]entity.RatingTypesNumCol, *base.Pagination, message.Message)
 
 	// Rating submission
@@ -40,7 +43,10 @@ type RatingService interface {
 	UpdateRatingSubmission(input request.UpdateRatingSubmissionRequest) message.Message
 	GetRatingSubmission(id string) (*response.RatingSubmissonResponse, message.Message)
 	GetListRatingSubmissions(input request.ListRatingSubmissionRequest) ([]response.RatingSubmissonResponse, *base.Pagination, message.Message)
-	DeleteRatingSubmission(id string) message.Message
+	GetListModerationQueue(input request.ListModerationQueueRequest) ([]response.ModerationQueueResponse, *base.Pagination, message.Message)
+	ModerateRatingSubmission(input request.ModerateRatingSubmissionRequest) message.Message
+	DeleteRatingSubmission(id, deletedBy string) message.Message
+	RestoreRatingSubmission(id string) message.Message
 	GetListRatingSubmissionWithUserIdLegacy(input request.GetPublicListRatingSubmissionByUserIdRequest) ([]publicresponse.PublicRatingSubmissionResponse, *base.Pagination, message.Message)
 	UpdateRatingSubDisplayNameByIdLegacy(input request.UpdateRatingSubDisplayNameRequest) message.Message
 	CancelRatingSubmission(input request.CancelRatingById) message.Message
@@ -49,24 +55,33 @@ type RatingService interface {
 	CreateRatingTypeLikert(input request.SaveRatingTypeLikertRequest) message.Message
 	GetRatingTypeLikertById(input request.GetRatingTypeLikertRequest) (*entity.RatingTypesLikertCol, message.Message)
 	UpdateRatingTypeLikert(input request.SaveRatingTypeLikertRequest) message.Message
-	DeleteRatingTypeLikertById(input request.GetRatingTypeLikertRequest) message.Message
+	DeleteRatingTypeLikertById(input request.GetRatingTypeLikertRequest, deletedBy string) message.Message
+	RestoreRatingTypeLikertById(id string) message.Message
 	GetRatingTypeLikerts(input request.GetRatingTypeLikertsRequest) ([]entity.RatingTypesLikertCol, *base.Pagination, message.Message)
+	MigrateLikertStatements() (int, message.Message)
 
 	// Rating
 	CreateRating(input request.SaveRatingRequest) (*entity.RatingsCol, message.Message)
 	GetRatingById(id string) (*entity.RatingsCol, message.Message)
 	UpdateRating(input request.UpdateRatingRequest) message.Message
-	DeleteRating(id string) message.Message
+	DeleteRating(id, deletedBy string) message.Message
+	RestoreRating(id string) message.Message
 	GetListRatings(input request.GetListRatingsRequest) ([]entity.RatingsCol, *base.Pagination, message.Message)
 	GetListRatingSummary(input request.GetListRatingSummaryRequest) ([]response.RatingSummaryResponse, message.Message)
+	RebuildRatingAggregates(sourceType string) (int, message.Message)
 	GetRatingBySourceTypeAndActor(input publicrequest.GetRatingBySourceTypeAndActorRequest) (*publicresponse.RatingBySourceTypeAndActorResponse, message.Message)
 
 	// Rating Formula
 	CreateRatingFormula(input request.SaveRatingFormula) (*entity.RatingFormulaCol, message.Message)
 	GetRatingFormulaById(input request.GetRatingFormulaRequest) (*entity.RatingFormulaCol, message.Message)
 	UpdateRatingFormula(input request.SaveRatingFormula) message.Message
-	DeleteRatingFormulaById(input request.GetRatingFormulaRequest) message.Message
+	DeleteRatingFormulaById(input request.GetRatingFormulaRequest, deletedBy string) message.Message
+	RestoreRatingFormulaById(id string) message.Message
 	GetRatingFormulas(input request.GetRatingFormulasRequest) ([]entity.RatingFormulaCol, *base.Pagination, message.Message)
+	PreviewRatingFormula(input request.PreviewRatingFormulaRequest) (*response.RatingFormulaPreviewResponse, message.Message)
+	GetRatingFormulaVersions(input request.GetRatingFormulaVersionsRequest) ([]entity.RatingFormulaVersionCol, *base.Pagination, message.Message)
+	CreateRatingFormulaVersion(input request.CreateRatingFormulaVersionRequest) (*entity.RatingFormulaVersionCol, message.Message)
+	ActivateRatingFormulaVersion(input request.ActivateRatingFormulaVersionRequest) message.Message
 
 	// Rating Sub Helpful
 	CreateRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (response.RatingSubHelpfulResponse, message.Message)
@@ -77,6 +92,8 @@ type ratingServiceImpl struct {
 	ratingRepo       repository.RatingRepository
 	publicRatingRepo repository2.PublicRatingRepository
 	medicalFacility  util.MedicalFacilitySvc
+	ratingMpRepo     repository.RatingMpRepository
+	ratingMp         *ratingMpServiceImpl
 }
 
 func NewRatingService(
@@ -84,8 +101,9 @@ func NewRatingService(
 	rr repository.RatingRepository,
 	prr repository2.PublicRatingRepository,
 	mf util.MedicalFacilitySvc,
+	rmr repository.RatingMpRepository,
 ) RatingService {
-	return &ratingServiceImpl{lg, rr, prr, mf}
+	return &ratingServiceImpl{lg, rr, prr, mf, rmr, &ratingMpServiceImpl{lg, rmr}}
 }
 
 // swagger:route POST /rating-types-numeric/ RatingTypeNum createRatingTypeNum
@@ -104,6 +122,9 @@ func (s *ratingServiceImpl) CreateRatingTypeNum(input request.CreateRatingTypeNu
 	if *input.MaxScore <= *input.MinScore {
 		return nil, message.ErrMaxMin
 	}
+	if errMsg := util.ValidateLocalizedText(input.DescriptionI18n); errMsg.Message != "" {
+		return nil, errMsg
+	}
 	check := true
 	if input.Status == nil {
 		input.Status = &check
@@ -202,6 +223,9 @@ func (s *ratingServiceImpl) UpdateRatingTypeNum(input request.EditRatingTypeNumR
 	if msg != message.SuccessMsg {
 		return msg
 	}
+	if msg = util.ValidateLocalizedText(input.DescriptionI18n); msg.Message != "" {
+		return msg
+	}
 
 	err = s.ratingRepo.UpdateRatingTypeNum(objectId, input)
 	if err != nil {
@@ -225,7 +249,7 @@ func (s *ratingServiceImpl) UpdateRatingTypeNum(input request.EditRatingTypeNumR
 //
 //	401: SuccessResponse
 //	200: SuccessResponse
-func (s *ratingServiceImpl) DeleteRatingTypeNumById(input request.GetRatingTypeNumRequest) message.Message {
+func (s *ratingServiceImpl) DeleteRatingTypeNumById(input request.GetRatingTypeNumRequest, deletedBy string) message.Message {
 	objectId, err := primitive.ObjectIDFromHex(input.Id)
 	if err != nil {
 		return message.ErrNoData
@@ -243,7 +267,7 @@ func (s *ratingServiceImpl) DeleteRatingTypeNumById(input request.GetRatingTypeN
 			return message.ErrThisRatingTypeIsInUse
 		}
 	}
-	err = s.ratingRepo.DeleteRatingTypeNum(objectId)
+	err = s.ratingRepo.DeleteRatingTypeNum(objectId, deletedBy)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return message.ErrNoData
@@ -349,6 +373,23 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 	}
 
 	originalSourceTransID := input.SourceTransID
+
+	// the order of a redeemed invitation is confirmed, payment svc is not asked again
+	var invitationId *primitive.ObjectID
+	if input.InvitationToken != "" {
+		userId := *input.UserIDLegacy
+		if userId == "" {
+			userId = *input.UserID
+		}
+		invitation, msg := getRedeemableReviewInvitation(input.InvitationToken, userId, input.SourceUID, input.RatingType, s.ratingRepo.GetReviewInvitationById)
+		if msg != message.SuccessMsg {
+			return result, msg
+		}
+		originalSourceTransID = invitation.OrderNumber
+		invitationId = &invitation.ID
+		isOrderIdExist = true
+	}
+
 	if len(input.Ratings) == 0 && input.RatingType != "" {
 		// Condition for All Rating (not doctor)
 		isAllRating = true
@@ -360,9 +401,14 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 		if rating == nil || !*rating.Status {
 			return result, message.ErrRatingNotFound
 		}
+		if msg := validateSourceTypeContent(rating.SourceType, input.Comment, input.Media); msg != message.SuccessMsg {
+			return result, msg
+		}
+		if msg := validateSubmissionTags(rating.SourceType, valueLayanan, input.Tags, s.ratingRepo.GetSubmissionTags); msg != message.SuccessMsg {
+			return result, msg
+		}
 
-		// Concate source_trans_id
-		input.SourceTransID = originalSourceTransID + "||" + rating.ID.Hex()
+		input.SourceTransID = originalSourceTransID
 
 		// A submission with a combination of either (rating_id and user_id) OR (rating_id and user_id_legacy) is allowed once
 		userHasSubmitRating := checkUserHaveSubmitRating(*input.UserID, *input.UserIDLegacy, rating.ID.Hex(), input.SourceTransID, s)
@@ -385,12 +431,12 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 			SourceUID:     input.SourceUID,
 			IsAnonymous:   input.IsAnonymous,
 			SourceType:    rating.SourceType,
+			Tags:          input.Tags,
 		})
 	} else {
 		// Condition for Doctor Rating
 		for _, argRatings := range input.Ratings {
-			// Concate source_trans_id
-			input.SourceTransID = originalSourceTransID + "||" + argRatings.ID
+			input.SourceTransID = originalSourceTransID
 
 			// Find rating_type_id by rating_id
 			objectRatingId, err := primitive.ObjectIDFromHex(argRatings.ID)
@@ -404,6 +450,9 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 			if rating == nil || !*rating.Status {
 				return result, message.ErrRatingNotFound
 			}
+			if msg := validateSourceTypeContent(rating.SourceType, input.Comment, input.Media); msg != message.SuccessMsg {
+				return result, msg
+			}
 
 			// Validate Numeric Type Value
 			objectRatingTypeId, err := primitive.ObjectIDFromHex(rating.RatingTypeId)
@@ -440,9 +489,12 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 				if validateErr, validList := util.ValidateLikertType(ratingTypeLikert, strValue); validateErr != nil {
 					return result, message.Message{
 						Code:    message.ValidationFailCode,
-						Message: "value must be integer and include in " + fmt.Sprintf("%v", validList),
+						Message: "value must be a statement key or position and include in " + fmt.Sprintf("%v", validList),
 					}
 				}
+				// the submission references the statements by key
+				keys := strings.Join(util.LikertStatementKeys(ratingTypeLikert, strValue), ",")
+				argRatings.Value = &keys
 			}
 
 			// A submission with a combination of either (rating_id and user_id) OR (rating_id and user_id_legacy) is allowed once
@@ -475,6 +527,10 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 					IsAnonymous:   input.IsAnonymous,
 				})
 			} else {
+				// the tags are saved with the numeric ratings, like the comment
+				if msg := validateSubmissionTags(rating.SourceType, *argRatings.Value, input.Tags, s.ratingRepo.GetSubmissionTags); msg != message.SuccessMsg {
+					return result, msg
+				}
 				haveRatNum = true
 				numId = argRatings.ID
 				saveReq = append(saveReq, request.SaveRatingSubmission{
@@ -491,6 +547,7 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 					UserPlatform:  input.UserPlatform,
 					SourceUID:     input.SourceUID,
 					IsAnonymous:   input.IsAnonymous,
+					Tags:          input.Tags,
 				})
 			}
 		}
@@ -502,7 +559,7 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 	}
 
 	// Check order_id exist for layanan
-	if isAllRating {
+	if isAllRating && invitationId == nil {
 		msg, err := util.CheckOrderIdExist(originalSourceTransID, logger)
 		if err != nil {
 			return result, message.ErrFailedRequestToPayment
@@ -515,15 +572,36 @@ func (s *ratingServiceImpl) CreateRatingSubmission(input request.CreateRatingSub
 		}
 	}
 
-	if isOrderIdExist {
-		go UpdateFlagPayment(originalSourceTransID, logger)
-	}
-
 	if len(saveReq) == 0 {
 		return result, message.ErrTypeNotFound
 	}
-	ratingSubs, err := s.ratingRepo.CreateRatingSubmission(saveReq)
+
+	// Comment is moderated before it goes public, a submission caught by the spam heuristics waits for moderation too.
+	// The order confirmed by payment svc or by an invitation makes a verified purchase
+	for i := range saveReq {
+		saveReq[i].ModerationStatus, saveReq[i].ModerationFlags = util_moderation.GetModerationStatus(saveReq[i].Comment)
+		saveReq[i].ModerationStatus, saveReq[i].ModerationFlags = applySpamFlags(saveReq[i].ModerationStatus, saveReq[i].ModerationFlags,
+			getSpamFlags(s.logger, s.ratingRepo, saveReq[i].Comment, saveReq[i].IPAddress, input.AccountCreatedAt))
+		saveReq[i].InvitationID = invitationId
+		saveReq[i].IsVerifiedPurchase = isOrderIdExist
+	}
+	// the review flag of the order is sent to payment svc by the outbox dispatcher
+	var outbox []entity.OutboxCol
+	if isOrderIdExist {
+		outbox = append(outbox, entity.OutboxCol{
+			Target:  entity.OutboxTargetPaymentUpdateFlag,
+			Payload: entity.OutboxPayload{OrderNumber: originalSourceTransID},
+		})
+	}
+	ratingSubs, err := s.ratingRepo.CreateRatingSubmission(saveReq, outbox)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return result, message.ErrInvitationNotPending
+		}
+		// a concurrent submission of the same order line
+		if mongo.IsDuplicateKeyError(err) {
+			return result, message.UserRated
+		}
 		return result, message.ErrSaveData
 	}
 
@@ -560,21 +638,6 @@ func getSourceTypeByRatingType(ratingType string) string {
 	return sourceType
 }
 
-func UpdateFlagPayment(orderId string, logger log.Logger) error {
-	msg, err := util.UpdateFlagPayment(orderId, logger)
-	if err != nil {
-		_ = level.Error(logger).Log("error", err)
-		return err
-	}
-
-	if msg == message.SuccessMsg {
-		return nil
-	} else {
-		_ = level.Error(logger).Log("error", "response code is not 200")
-		return errors.New("response code request is not 200")
-	}
-}
-
 func checkUserHaveSubmitRating(userId, userIdLegacy, ratingId, sourceTransId string, s *ratingServiceImpl) message.Message {
 	if userId != "" && userIdLegacy != "" {
 		ratingSubmission, er := s.ratingRepo.FindRatingSubmissionByUserIDLegacyAndRatingID(&userIdLegacy, ratingId, sourceTransId)
@@ -660,6 +723,11 @@ func (s *ratingServiceImpl) UpdateRatingSubmission(input request.UpdateRatingSub
 		return message.RatingSubmissionNotFound
 	}
 
+	// validate cannot update rating submission of another user, unless the caller is admin
+	if !input.JWTObj.IsAdmin() && (input.UserIDLegacy == nil || ratingSubmission.UserIDLegacy == nil || *ratingSubmission.UserIDLegacy != *input.UserIDLegacy) {
+		return message.ErrUserPermissionUpdate
+	}
+
 	// Find rating_type_id by rating
 	rating, err := s.ratingRepo.FindRatingByRatingID(objectRatingId)
 	if err != nil {
@@ -669,6 +737,11 @@ func (s *ratingServiceImpl) UpdateRatingSubmission(input request.UpdateRatingSub
 		return message.ErrRatingNotFound
 	}
 
+	// edit window of the source type and max edits
+	if msg := validateRatingSubEdit(rating.SourceType, ratingSubmission.CreatedAt, ratingSubmission.EditCounter); msg.Code != message.SuccessCode {
+		return msg
+	}
+
 	// Validate value of numeric type
 	objectRatingTypeId, err := primitive.ObjectIDFromHex(rating.RatingTypeId)
 	if err != nil {
@@ -707,9 +780,12 @@ func (s *ratingServiceImpl) UpdateRatingSubmission(input request.UpdateRatingSub
 		if validateErr, validList := util.ValidateLikertType(ratingTypeLikert, strValue); validateErr != nil {
 			return message.Message{
 				Code:    message.ValidationFailCode,
-				Message: "value must be integer and include in " + fmt.Sprintf("%v", validList),
+				Message: "value must be a statement key or position and include in " + fmt.Sprintf("%v", validList),
 			}
 		}
+		// the submission references the statements by key
+		keys := strings.Join(util.LikertStatementKeys(ratingTypeLikert, strValue), ",")
+		input.Value = &keys
 		input.Comment = empty
 	}
 
@@ -753,7 +829,23 @@ func (s *ratingServiceImpl) UpdateRatingSubmission(input request.UpdateRatingSub
 		}
 	}
 
-	// Update
+	// the tags must still match the value, the tags of the submission are kept when none are given
+	tagsChanged := input.Tags != nil
+	if !tagsChanged {
+		input.Tags = ratingSubmission.Tags
+	}
+	if tagsChanged || ratingSubmission.Value != *input.Value {
+		if msg := validateSubmissionTags(rating.SourceType, *input.Value, input.Tags, s.ratingRepo.GetSubmissionTags); msg != message.SuccessMsg {
+			return msg
+		}
+	}
+
+	// edited comment goes through moderation again
+	if ratingSubmission.Comment == nil || *ratingSubmission.Comment != input.Comment {
+		input.ModerationStatus, input.ModerationFlags = util_moderation.GetModerationStatus(input.Comment)
+	}
+
+	// Update, the replaced version is kept in the edit history
 	errC := s.ratingRepo.UpdateRatingSubmission(input, objectRatingSubmissionId)
 	if errC != nil {
 		return message.ErrSaveData
@@ -763,7 +855,7 @@ func (s *ratingServiceImpl) UpdateRatingSubmission(input request.UpdateRatingSub
 }
 
 // swagger:route DELETE /rating-submissions/{id} RatingSubmission ReqDeleteRatingSubmissionById
-// Delete Rating Submission
+// Delete Rating Submission, source mp deletes a marketplace submission. It can be restored until the purge job deletes it
 //
 // security:
 // - Bearer: []
@@ -771,14 +863,14 @@ func (s *ratingServiceImpl) UpdateRatingSubmission(input request.UpdateRatingSub
 //
 //	401: SuccessResponse
 //	200: SuccessResponse
-func (s *ratingServiceImpl) DeleteRatingSubmission(id string) message.Message {
+func (s *ratingServiceImpl) DeleteRatingSubmission(id, deletedBy string) message.Message {
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return message.ErrDataNotFound
 	}
-	err = s.ratingRepo.DeleteSubmission(objectId)
+	err = s.ratingRepo.DeleteSubmission(objectId, deletedBy)
 	if err != nil {
-		if err.Error() == "user not found" {
+		if errors.Is(err, mongo.ErrNoDocuments) {
 			return message.ErrNoData
 		}
 		return message.FailedMsg
@@ -894,18 +986,23 @@ func (s *ratingServiceImpl) GetListRatingSubmissionWithUserIdLegacy(input reques
 			displayName = *v.DisplayName
 		}
 		results = append(results, publicresponse.PublicRatingSubmissionResponse{
-			ID:            v.ID,
-			UserID:        v.UserID,
-			UserIDLegacy:  v.UserIDLegacy,
-			DisplayName:   displayName,
-			Avatar:        v.Avatar,
-			Comment:       v.Comment,
-			SourceTransID: v.SourceTransID,
-			LikeCounter:   v.LikeCounter,
-			LikeByMe:      likeByme,
-			RatingType:    rating.RatingType,
-			Value:         v.Value,
-			CreatedAt:     v.CreatedAt.In(Loc),
+			ID:                 v.ID,
+			UserID:             v.UserID,
+			UserIDLegacy:       v.UserIDLegacy,
+			DisplayName:        displayName,
+			Avatar:             v.Avatar,
+			Comment:            v.Comment,
+			SourceTransID:      v.SourceTransID,
+			LikeCounter:        v.LikeCounter,
+			NotHelpfulCounter:  v.NotHelpfulCounter,
+			HelpfulRatio:       util.HelpfulRatio(v.LikeCounter, v.NotHelpfulCounter),
+			LikeByMe:           likeByme,
+			IsVerifiedPurchase: v.IsVerifiedPurchase,
+			RatingType:         rating.RatingType,
+			Value:              v.Value,
+			CreatedAt:          v.CreatedAt.In(Loc),
+			Edited:             v.EditedAt != nil,
+			EditedAt:           util.TimeIn(v.EditedAt, Loc),
 		})
 	}
 	return results, pagination, message.SuccessMsg
@@ -958,6 +1055,20 @@ func (s *ratingServiceImpl) CancelRatingSubmission(input request.CancelRatingByI
 	if err != nil {
 		return message.ErrSaveData
 	}
+
+	// the ids may be marketplace submissions, their final ratings are recalculated
+	submissionsMp, err := s.ratingMpRepo.GetRatingSubmissionsByIds(ids)
+	if err != nil {
+		return message.ErrSaveData
+	}
+	if len(submissionsMp) > 0 {
+		if err = s.ratingMpRepo.CancelRatingSubmissionByIds(ids, reason); err != nil {
+			return message.ErrSaveData
+		}
+		for _, submission := range submissionsMp {
+			s.ratingMp.emitFinalRatingOfSubmission("", submission)
+		}
+	}
 	return message.SuccessMsg
 }
 
@@ -1019,7 +1130,8 @@ func (s *ratingServiceImpl) GetListRatingSubmissions(input request.ListRatingSub
 	if input.Limit <= 0 {
 		input.Limit = 50
 	}
-	if input.Sort == "" {
+	// the matches of q come by relevance unless a sort is asked
+	if input.Sort == "" && input.Q == "" {
 		input.Sort = "created_at"
 	}
 
@@ -1030,6 +1142,7 @@ func (s *ratingServiceImpl) GetListRatingSubmissions(input request.ListRatingSub
 			return nil, nil, message.WrongFilter
 		}
 	}
+	filter.Q = input.Q
 	ratingSubmissions, pagination, err := s.ratingRepo.GetListRatingSubmissions(filter, input.Page, input.Limit, input.Sort, dir)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -1053,6 +1166,7 @@ func (s *ratingServiceImpl) GetListRatingSubmissions(input request.ListRatingSub
 				Comment:      *args.Comment,
 				Value:        args.Value,
 				SourTransID:  args.SourceTransID,
+				Highlight:    util_search.Highlight(*args.Comment, input.Q),
 			})
 		}
 	}
@@ -1064,6 +1178,93 @@ func (s *ratingServiceImpl) GetListRatingSubmissions(input request.ListRatingSub
 	return results, pagination, message.SuccessMsg
 }
 
+// swagger:route GET /moderation/rating-submissions RatingSubmission ListModerationQueueRequest
+// Get List Rating Submissions by Moderation Status, oldest first
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) GetListModerationQueue(input request.ListModerationQueueRequest) ([]response.ModerationQueueResponse, *base.Pagination, message.Message) {
+	results := make([]response.ModerationQueueResponse, 0)
+	dir := setModerationQueueDefault(&input)
+
+	filter := request.RatingSubmissionFilter{ModerationStatus: input.Status}
+	ratingSubmissions, pagination, err := s.ratingRepo.GetListRatingSubmissions(filter, input.Page, input.Limit, "created_at", dir)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return results, pagination, message.ErrDataNotFound
+		}
+		return nil, nil, message.FailedMsg
+	}
+
+	for _, args := range ratingSubmissions {
+		data := response.ModerationQueueResponse{
+			ID:               args.ID.Hex(),
+			RatingID:         args.RatingID,
+			UserIDLegacy:     args.UserIDLegacy,
+			DisplayName:      args.DisplayName,
+			Value:            args.Value,
+			ModerationStatus: args.ModerationStatus,
+			ModerationFlags:  args.ModerationFlags,
+			ModerationReason: args.ModerationReason,
+			ModeratedBy:      args.ModeratedBy,
+			ModeratedAt:      args.ModeratedAt,
+			CreatedAt:        args.CreatedAt,
+		}
+		if args.Comment != nil {
+			data.Comment = *args.Comment
+		}
+		results = append(results, data)
+	}
+
+	return results, pagination, message.SuccessMsg
+}
+
+// swagger:route PUT /moderation/rating-submissions/{id} RatingSubmission ReqModerateRatingSubmissionBody
+// Approve or Reject Rating Submission By ID
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) ModerateRatingSubmission(input request.ModerateRatingSubmissionRequest) message.Message {
+	objectRatingSubmissionId, err := primitive.ObjectIDFromHex(input.ID)
+	if err != nil {
+		return message.RatingSubmissionNotFound
+	}
+
+	err = s.ratingRepo.UpdateModerationRatingSubmission(objectRatingSubmissionId, input.Status, input.Reason, fmt.Sprint(input.JWTObj.Fullname))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.RatingSubmissionNotFound
+		}
+		return message.ErrSaveData
+	}
+	return message.SuccessMsg
+}
+
+// setModerationQueueDefault fills the default status, paging and direction of the moderation queue
+func setModerationQueueDefault(input *request.ListModerationQueueRequest) interface{} {
+	if input.Status == "" {
+		input.Status = entity.ModerationStatusPending
+	}
+	if input.Page <= 0 {
+		input.Page = 1
+	}
+	if input.Limit <= 0 {
+		input.Limit = 50
+	}
+	if input.Dir == "desc" {
+		return -1
+	}
+	return 1
+}
+
 func filterScoreSubmission(ratingSubmissions entity.RatingSubmisson, score []float64) bool {
 	if len(score) == 0 {
 		return true
@@ -1094,11 +1295,14 @@ func filterScoreSubmission(ratingSubmissions entity.RatingSubmisson, score []flo
 //	401: SuccessResponse
 //	200: SuccessResponse
 func (s *ratingServiceImpl) CreateRatingTypeLikert(input request.SaveRatingTypeLikertRequest) message.Message {
-
+	input = normalizeLikertStatements(input)
 	errMsg := validateNumStatement(input)
 	if errMsg.Message != "" {
 		return errMsg
 	}
+	if errMsg = util.ValidateLocalizedText(input.DescriptionI18n); errMsg.Message != "" {
+		return errMsg
+	}
 	check := true
 	if input.Status == nil {
 		input.Status = &check
@@ -1135,7 +1339,8 @@ func (s *ratingServiceImpl) GetRatingTypeLikertById(input request.GetRatingTypeL
 		}
 		return nil, message.FailedMsg
 	}
-	return result, message.SuccessMsg
+	likert := result.WithLegacyStatements()
+	return &likert, message.SuccessMsg
 }
 
 // swagger:route PUT /rating-types-likert/{id} RatingTypesLikert updateRatingTypeLikert
@@ -1152,6 +1357,10 @@ func (s *ratingServiceImpl) UpdateRatingTypeLikert(input request.SaveRatingTypeL
 	if err != nil {
 		return message.ErrNoData
 	}
+	input = normalizeLikertStatements(input)
+	if errMsg := util.ValidateLocalizedText(input.DescriptionI18n); errMsg.Message != "" {
+		return errMsg
+	}
 
 	ratingTypeLikert, err := s.ratingRepo.GetRatingTypeLikertById(objectId)
 	if err != nil {
@@ -1210,7 +1419,7 @@ func (s *ratingServiceImpl) UpdateRatingTypeLikert(input request.SaveRatingTypeL
 //
 //	401: SuccessResponse
 //	200: SuccessResponse
-func (s *ratingServiceImpl) DeleteRatingTypeLikertById(input request.GetRatingTypeLikertRequest) message.Message {
+func (s *ratingServiceImpl) DeleteRatingTypeLikertById(input request.GetRatingTypeLikertRequest, deletedBy string) message.Message {
 	objectId, err := primitive.ObjectIDFromHex(input.Id)
 	if err != nil {
 		return message.ErrNoData
@@ -1228,9 +1437,9 @@ func (s *ratingServiceImpl) DeleteRatingTypeLikertById(input request.GetRatingTy
 			return message.ErrThisRatingTypeIsInUse
 		}
 	}
-	err = s.ratingRepo.DeleteRatingTypeLikert(objectId)
+	err = s.ratingRepo.DeleteRatingTypeLikert(objectId, deletedBy)
 	if err != nil {
-		if err.Error() == "user not found" {
+		if errors.Is(err, mongo.ErrNoDocuments) {
 			return message.ErrNoData
 		}
 		return message.FailedMsg
@@ -1281,11 +1490,10 @@ func (s *ratingServiceImpl) GetRatingTypeLikerts(input request.GetRatingTypeLike
 		return nil, nil, message.FailedMsg
 	}
 
-	results := make([]entity.RatingTypesLikertCol, 0)
-	if len(ratingTypeLikerts) == 0 {
-		return results, pagination, message.SuccessMsg
+	results := make([]entity.RatingTypesLikertCol, 0, len(ratingTypeLikerts))
+	for _, likert := range ratingTypeLikerts {
+		results = append(results, likert.WithLegacyStatements())
 	}
-	results = ratingTypeLikerts
 
 	return results, pagination, message.SuccessMsg
 }
@@ -1299,6 +1507,9 @@ func (s *ratingServiceImpl) GetRatingTypeLikerts(input request.GetRatingTypeLike
 //
 //	200: SuccessResponse
 func (s *ratingServiceImpl) CreateRating(input request.SaveRatingRequest) (*entity.RatingsCol, message.Message) {
+	if errMsg := util.ValidateLocalizedText(input.DescriptionI18n); errMsg.Message != "" {
+		return nil, errMsg
+	}
 
 	if input.Status == nil {
 		status := true
@@ -1409,6 +1620,9 @@ func (s *ratingServiceImpl) UpdateRating(input request.UpdateRatingRequest) mess
 	if err != nil {
 		return message.ErrDataNotFound
 	}
+	if errMsg := util.ValidateLocalizedText(input.Body.DescriptionI18n); errMsg.Message != "" {
+		return errMsg
+	}
 
 	currentRating, err := s.ratingRepo.GetRatingById(objectId)
 	if err != nil {
@@ -1482,7 +1696,7 @@ func (s *ratingServiceImpl) UpdateRating(input request.UpdateRatingRequest) mess
 // responses:
 //
 //	200: SuccessResponse
-func (s *ratingServiceImpl) DeleteRating(id string) message.Message {
+func (s *ratingServiceImpl) DeleteRating(id, deletedBy string) message.Message {
 	objectId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return message.ErrDataNotFound
@@ -1508,7 +1722,7 @@ func (s *ratingServiceImpl) DeleteRating(id string) message.Message {
 		return message.ErrRatingHasRatingSubmission
 	}
 
-	err = s.ratingRepo.DeleteRating(objectId)
+	err = s.ratingRepo.DeleteRating(objectId, deletedBy)
 	if err != nil {
 		return message.FailedMsg
 	}
@@ -1553,34 +1767,36 @@ func (s *ratingServiceImpl) GetListRatings(input request.GetListRatingsRequest)
 	return results, pagination, message.SuccessMsg
 }
 
-func validateNumStatement(input request.SaveRatingTypeLikertRequest) message.Message {
-	var errMsg message.Message
-	var myMap map[string]string
-	count := 1
-	datas, _ := json.Marshal(input)
-	json.Unmarshal(datas, &myMap)
-
-	for i := 1; i <= 10; i++ {
-		var statement string
-		if i < 10 {
-			statement = fmt.Sprint("statement_0", i)
-		} else {
-			statement = fmt.Sprint("statement_", i)
-		}
-		data := myMap[statement]
-		if data != "" {
-			if count == input.NumStatements+1 {
-				errMsg = message.ErrMatchNumState
-				return errMsg
-			}
-			count++
-		}
+// defaultLikertMaxStatements is the number of statements of a likert type when likert.max-statements is not set
+const defaultLikertMaxStatements = 10
+
+func getLikertMaxStatements() int {
+	if value := viper.GetInt("likert.max-statements"); value > 0 {
+		return value
 	}
-	if count <= input.NumStatements {
-		errMsg = message.ErrMatchNumState
-		return errMsg
+	return defaultLikertMaxStatements
+}
+
+// normalizeLikertStatements moves the statements of either shape to statements, num_statements defaults to their number
+func normalizeLikertStatements(input request.SaveRatingTypeLikertRequest) request.SaveRatingTypeLikertRequest {
+	input.Statements = input.GetStatements()
+	if input.NumStatements == 0 && len(input.Statements) > 0 {
+		input.NumStatements = len(input.Statements)
 	}
-	return errMsg
+	return input
+}
+
+// MigrateLikertStatements moves the statement_01..statement_10 of the likert types not migrated yet to statements
+func (s *ratingServiceImpl) MigrateLikertStatements() (int, message.Message) {
+	total, err := s.ratingRepo.MigrateRatingTypeLikertStatements()
+	if err != nil {
+		return int(total), message.FailedMsg
+	}
+	return int(total), message.SuccessMsg
+}
+
+func validateNumStatement(input request.SaveRatingTypeLikertRequest) message.Message {
+	return util.ValidateLikertStatements(input.GetStatements(), input.NumStatements, getLikertMaxStatements())
 }
 
 func updateRatingTypeLikertHaveRating(s *ratingServiceImpl, input request.SaveRatingTypeLikertRequest, ratingTypeLikert *entity.RatingTypesLikertCol, objectId primitive.ObjectID) message.Message {
@@ -1605,23 +1821,17 @@ func updateRatingTypeLikertHaveRating(s *ratingServiceImpl, input request.SaveRa
 }
 
 func updateRatingTypeLikertHaveSubmission(s *ratingServiceImpl, input request.SaveRatingTypeLikertRequest, ratingTypeLikert *entity.RatingTypesLikertCol, objectId primitive.ObjectID) message.Message {
-	errMsg := util.ValidInputUpdateRatingTypeLikertInSubmission(input)
+	errMsg := util.ValidInputUpdateRatingTypeLikertInSubmission(input, ratingTypeLikert.GetStatements())
 	if errMsg.Message != "" {
 		return errMsg
 	}
 
 	input.Type = ratingTypeLikert.Type
-	input.NumStatements = ratingTypeLikert.NumStatements
-	input.Statement01 = ratingTypeLikert.Statement01
-	input.Statement02 = ratingTypeLikert.Statement02
-	input.Statement03 = ratingTypeLikert.Statement03
-	input.Statement04 = ratingTypeLikert.Statement04
-	input.Statement05 = ratingTypeLikert.Statement05
-	input.Statement06 = ratingTypeLikert.Statement06
-	input.Statement07 = ratingTypeLikert.Statement07
-	input.Statement08 = ratingTypeLikert.Statement08
-	input.Statement09 = ratingTypeLikert.Statement09
-	input.Statement10 = ratingTypeLikert.Statement10
+	statements := ratingTypeLikert.GetStatements()
+	if len(input.GetStatements()) == 0 {
+		input.Statements = statements
+	}
+	input.NumStatements = len(statements)
 	input.Status = ratingTypeLikert.Status
 	errMsg = validateNumStatement(input)
 	if errMsg.Message != "" {
@@ -1690,14 +1900,6 @@ func (s *ratingServiceImpl) GetListRatingSummary(input request.GetListRatingSumm
 		}
 	}
 
-	findS, _, err := s.ratingRepo.GetListRatingSubmissions(filterForRatingSub, input.Page, int64(input.Limit), input.Sort, dir)
-	if err != nil {
-		return nil, message.Message{
-			Code:    message.ValidationFailCode,
-			Message: "Wrong filter",
-		}
-	}
-
 	var min, max float64
 	if filterForRatingSub.Score == nil {
 		min, max = 0, 10
@@ -1710,6 +1912,28 @@ func (s *ratingServiceImpl) GetListRatingSummary(input request.GetListRatingSumm
 
 	results := make([]response.RatingSummaryResponse, 0)
 
+	// aggregates hold every public submission, filters on the submissions themselves still need the scan
+	if len(filterForRatingSub.UserIDLegacy) == 0 && filterForRatingSub.StartDate == "" && filterForRatingSub.EndDate == "" &&
+		filterForRatingSub.SourceTransID == "" && filterForRatingSub.ModerationStatus == "" {
+		if len(findR) == 0 {
+			return results, message.SuccessMsg
+		}
+		aggregates, err := s.ratingRepo.GetRatingAggregates(findR)
+		if err != nil {
+			return nil, message.FailedMsg
+		}
+		results = CalculateAggregateValue(filterForRating, findR, aggregates, min, max)
+		return results, message.SuccessMsg
+	}
+
+	findS, _, err := s.ratingRepo.GetListRatingSubmissions(filterForRatingSub, input.Page, int64(input.Limit), input.Sort, dir)
+	if err != nil {
+		return nil, message.Message{
+			Code:    message.ValidationFailCode,
+			Message: "Wrong filter",
+		}
+	}
+
 	if len(filterForRating.SourceUid) == 0 || len(findR) == 0 || len(findS) == 0 {
 		return results, message.SuccessMsg
 	}
@@ -1719,6 +1943,58 @@ func (s *ratingServiceImpl) GetListRatingSummary(input request.GetListRatingSumm
 	return results, message.SuccessMsg
 }
 
+// CalculateAggregateValue returns the average value and total review per source_uid from the rating aggregates
+func CalculateAggregateValue(filterForRating request.RatingFilter, rating []entity.RatingsCol, aggregates map[string]entity.RatingAggregateCol, min float64, max float64) []response.RatingSummaryResponse {
+	results := make([]response.RatingSummaryResponse, 0)
+	for _, args := range filterForRating.SourceUid {
+		var sum float64
+		var count int64
+		for _, argRs := range rating {
+			if argRs.SourceUid != args {
+				continue
+			}
+			aggregate := aggregates[argRs.ID.Hex()]
+			sum += aggregate.Sum
+			count += aggregate.Count
+		}
+
+		var avgValue float64
+		if count > 0 {
+			avgValue = util.RoundFloatWithPrecision(sum/float64(count), 1)
+		}
+		if filterScore(min, max, avgValue) {
+			results = append(results, response.RatingSummaryResponse{
+				SourceUID:   args,
+				TotalReview: int(count),
+				Value:       avgValue,
+			})
+		}
+	}
+	return results
+}
+
+// RebuildRatingAggregates recomputes the aggregate of every active rating of the source type (all when empty) from its submissions
+func (s *ratingServiceImpl) RebuildRatingAggregates(sourceType string) (int, message.Message) {
+	filter := request.RatingFilter{SourceType: sourceType}
+	total := 0
+	for page := 1; ; page++ {
+		ratings, pagination, err := s.ratingRepo.GetRatingsByParams(100, page, 1, "_id", filter)
+		if err != nil {
+			return total, message.FailedMsg
+		}
+		for _, rating := range ratings {
+			if _, err = s.ratingRepo.RebuildRatingAggregate(rating); err != nil {
+				return total, message.FailedMsg
+			}
+			total++
+		}
+		if pagination == nil || len(ratings) == 0 || page >= pagination.TotalPage {
+			break
+		}
+	}
+	return total, message.SuccessMsg
+}
+
 func CalculateValue(filterForRating request.RatingFilter, rating []entity.RatingsCol, ratingSubmission []entity.RatingSubmisson, min float64, max float64) []response.RatingSummaryResponse {
 	results := make([]response.RatingSummaryResponse, 0)
 	for _, args := range filterForRating.SourceUid {
@@ -1819,7 +2095,7 @@ func (s *ratingServiceImpl) GetRatingBySourceTypeAndActor(input publicrequest.Ge
 			return nil, message.FailedMsg
 		}
 		if likert != nil {
-			likertResp := publicresponse.MapRatingLikertToRatingNumericResp(*likert, v.ID.Hex())
+			likertResp := publicresponse.MapRatingLikertToRatingNumericResp(likert.Localized(input.Lang), v.ID.Hex())
 			result.Ratings = append(result.Ratings, likertResp)
 		} else {
 			numeric, err := s.publicRatingRepo.GetRatingTypeNumById(ratingTypeId)
@@ -1829,7 +2105,7 @@ func (s *ratingServiceImpl) GetRatingBySourceTypeAndActor(input publicrequest.Ge
 			if numeric == nil {
 				return nil, message.ErrRatingTypeNotExist
 			}
-			numericResp := publicresponse.MapRatingNumericToRatingNumericResp(*numeric, v.ID.Hex())
+			numericResp := publicresponse.MapRatingNumericToRatingNumericResp(numeric.Localized(input.Lang), v.ID.Hex())
 			result.Ratings = append(result.Ratings, numericResp)
 		}
 	}
@@ -1846,6 +2122,9 @@ func (s *ratingServiceImpl) GetRatingBySourceTypeAndActor(input publicrequest.Ge
 //	401: SuccessResponse
 //	200: SuccessResponse
 func (s *ratingServiceImpl) CreateRatingFormula(input request.SaveRatingFormula) (*entity.RatingFormulaCol, message.Message) {
+	if msg := validateFormula(input.Formula); msg != nil {
+		return nil, *msg
+	}
 	check := true
 	if input.Status == nil {
 		input.Status = &check
@@ -1860,6 +2139,17 @@ func (s *ratingServiceImpl) CreateRatingFormula(input request.SaveRatingFormula)
 	return result, message.SuccessMsg
 }
 
+// validateFormula evaluates the formula against sample data so a broken formula never reaches the summaries
+func validateFormula(formula string) *message.Message {
+	if err := util_formula.Validate(formula); err != nil {
+		return &message.Message{
+			Code:    message.ErrInvalidFormula.Code,
+			Message: message.ErrInvalidFormula.Message + ": " + err.Error(),
+		}
+	}
+	return nil
+}
+
 // swagger:route GET /rating-formula/{id} RatingFormula getRatingFormulaById
 // Get Rating Formula by ID
 //
@@ -1898,6 +2188,12 @@ func (s *ratingServiceImpl) UpdateRatingFormula(input request.SaveRatingFormula)
 	if err != nil {
 		return message.ErrNoData
 	}
+	// formula is only updated when it is sent
+	if input.Formula != "" {
+		if msg := validateFormula(input.Formula); msg != nil {
+			return *msg
+		}
+	}
 	err = s.ratingRepo.UpdateRatingFormula(objectId, input)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -1920,12 +2216,12 @@ func (s *ratingServiceImpl) UpdateRatingFormula(input request.SaveRatingFormula)
 //
 //	401: SuccessResponse
 //	200: SuccessResponse
-func (s *ratingServiceImpl) DeleteRatingFormulaById(input request.GetRatingFormulaRequest) message.Message {
+func (s *ratingServiceImpl) DeleteRatingFormulaById(input request.GetRatingFormulaRequest, deletedBy string) message.Message {
 	objectId, err := primitive.ObjectIDFromHex(input.Id)
 	if err != nil {
 		return message.ErrNoData
 	}
-	err = s.ratingRepo.DeleteRatingFormula(objectId)
+	err = s.ratingRepo.DeleteRatingFormula(objectId, deletedBy)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return message.ErrNoData
@@ -1996,68 +2292,31 @@ func (s *ratingServiceImpl) GetRatingFormulas(input request.GetRatingFormulasReq
 //	401: SuccessResponse
 //	200: SuccessResponse
 func (s *ratingServiceImpl) CreateRatingSubHelpful(input request.CreateRatingSubHelpfulRequest) (response.RatingSubHelpfulResponse, message.Message) {
-	result := response.RatingSubHelpfulResponse{}
-	result.RatingSubmissionId = input.RatingSubmissionID
-	result.UserIdLegacy = input.UserIDLegacy
-	counter := 0
-	// check rating submission is exist
-	ratingSubmissionId, err := primitive.ObjectIDFromHex(input.RatingSubmissionID)
-	if err != nil {
-		return result, message.RatingSubmissionNotFound
-	}
+	vote, counter, err := s.ratingRepo.ToggleRatingSubHelpful(input)
+	return toRatingSubHelpfulResponse(input, vote, counter, err)
+}
 
-	ratingSubmission, err := s.ratingRepo.GetRatingSubmissionById(ratingSubmissionId)
-	if err != nil {
-		if !errors.Is(err, mongo.ErrNoDocuments) {
-			return result, message.FailedMsg
-		}
-		return result, message.FailedMsg
-	}
-	if ratingSubmission == nil {
-		return result, message.ErrRatingSubmissionNotFound
+// toRatingSubHelpfulResponse answers the helpful vote saved by ToggleRatingSubHelpful with the counters of the submission
+func toRatingSubHelpfulResponse(input request.CreateRatingSubHelpfulRequest, vote *entity.RatingSubHelpfulCol, counter *entity.RatingSubHelpfulCounter, err error) (response.RatingSubHelpfulResponse, message.Message) {
+	result := response.RatingSubHelpfulResponse{
+		RatingSubmissionId: input.RatingSubmissionID,
+		UserIdLegacy:       input.UserIDLegacy,
 	}
-	counter = ratingSubmission.LikeCounter
-
-	// Check rating sub helpful exist or not
-	ratingSubHelpful, err := s.publicRatingRepo.GetRatingSubHelpfulByRatingSubAndActor(input.RatingSubmissionID, input.UserIDLegacy)
 	if err != nil {
-		return result, message.FailedMsg
-	}
-	if ratingSubHelpful == nil {
-		counter = counter + 1
-		// Create new one of rating sub helpful
-		_, errCr := s.publicRatingRepo.CreateRatingSubHelpful(input)
-		if errCr != nil {
-			if mongo.IsDuplicateKeyError(err) {
-				return result, message.ErrDuplicateRatingName
-			}
-			return result, message.FailedMsg
-		}
-		// Set result counter & status
-		result.Status = "Like"
-		result.LikeCounter = counter
-	} else {
-		if !ratingSubHelpful.Status {
-			counter = counter + 1
-			result.Status = "Like"
-		} else {
-			counter = counter - 1
-			result.Status = "Unlike"
-		}
-		// Update status of rating sub helpful
-		errUpd := s.publicRatingRepo.UpdateStatusRatingSubHelpful(ratingSubHelpful.ID, ratingSubHelpful.Status)
-		if errUpd != nil {
-			return result, message.FailedMsg
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return result, message.ErrRatingSubmissionNotFound
 		}
-		// Set result counter & status
-		result.LikeCounter = counter
+		return result, message.FailedMsg
 	}
 
-	// Update like_counter rating submission
-	err3 := s.publicRatingRepo.UpdateCounterRatingSubmission(ratingSubmissionId, int64(counter))
-	if err3 != nil {
-		return result, message.ErrSaveData
+	result.Status = response.HelpfulStatusUnlike
+	if vote.Status {
+		result.Status = response.HelpfulStatusLike
+	} else if vote.NotHelpful {
+		result.Status = response.HelpfulStatusNotHelpful
 	}
-
+	result.LikeCounter = counter.LikeCounter
+	result.NotHelpfulCounter = counter.NotHelpfulCounter
+	result.HelpfulRatio = util.HelpfulRatio(counter.LikeCounter, counter.NotHelpfulCounter)
 	return result, message.SuccessMsg
 }
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/model/response"
+	"go-klikdokter/app/repository"
+	"go-klikdokter/helper/message"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultReportAutoHideThreshold = 5
+
+type RatingSubReportService interface {
+	ReportRatingSubmission(input request.ReportRatingSubmissionRequest) message.Message
+	GetListRatingSubReports(input request.ListRatingSubReportsRequest) ([]response.RatingSubReportGroupResponse, *base.Pagination, message.Message)
+}
+
+type ratingSubReportServiceImpl struct {
+	logger       log.Logger
+	ratingRepo   repository.RatingRepository
+	ratingMpRepo repository.RatingMpRepository
+	ratingMp     *ratingMpServiceImpl
+}
+
+func NewRatingSubReportService(
+	lg log.Logger,
+	rr repository.RatingRepository,
+	rmr repository.RatingMpRepository,
+) RatingSubReportService {
+	return &ratingSubReportServiceImpl{lg, rr, rmr, &ratingMpServiceImpl{lg, rmr}}
+}
+
+// getReportAutoHideThreshold is the number of reports hiding a submission until moderated, 0 never hides
+func getReportAutoHideThreshold() int {
+	if !viper.IsSet("report.auto-hide-threshold") {
+		return defaultReportAutoHideThreshold
+	}
+	return viper.GetInt("report.auto-hide-threshold")
+}
+
+// swagger:route POST /public/rating-submissions/{id}/report RatingSubReport ReqReportRatingSubmissionBody
+// Report Rating Submission as spam, offensive or fake, a submission reaching report.auto-hide-threshold is hidden until moderated
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingSubReportServiceImpl) ReportRatingSubmission(input request.ReportRatingSubmissionRequest) message.Message {
+	if input.UserIDLegacy == "" {
+		return message.ErrUserNotFound
+	}
+	isMp := strings.ToLower(input.Source) == entity.RatingSubReportSourceMp
+	report := entity.RatingSubReportCol{
+		RatingSubmissionID: input.ID,
+		UserIDLegacy:       input.UserIDLegacy,
+		ReasonCode:         input.ReasonCode,
+		Reason:             input.Reason,
+		IPAddress:          input.IPAddress,
+		UserAgent:          input.UserAgent,
+	}
+	threshold := getReportAutoHideThreshold()
+
+	var hidden bool
+	var err error
+	if isMp {
+		report.Source = entity.RatingSubReportSourceMp
+		hidden, err = s.ratingMpRepo.ReportRatingSubmission(report, threshold)
+	} else {
+		hidden, err = s.ratingRepo.ReportRatingSubmission(report, threshold)
+	}
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.ErrRatingSubmissionNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return message.ErrRatingSubReportExists
+		}
+		return message.ErrSaveData
+	}
+
+	if hidden {
+		if s.logger != nil {
+			_ = level.Info(s.logger).Log("Type", "Report", "rating_submission_id", input.ID, "source", report.Source, "hidden", hidden)
+		}
+		// final rating only counts approved submissions
+		if isMp {
+			objectId, _ := primitive.ObjectIDFromHex(input.ID)
+			if ratingSubmission, err := s.ratingMpRepo.GetRatingSubmissionById(objectId); err == nil && ratingSubmission != nil {
+				s.ratingMp.emitFinalRatingOfSubmission("", *ratingSubmission)
+			}
+		}
+	}
+	return message.SuccessMsg
+}
+
+// swagger:route GET /moderation/rating-submission-reports RatingSubReport ListRatingSubReportsRequest
+// Get List Reports grouped by Rating Submission, the most reported submission first
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingSubReportServiceImpl) GetListRatingSubReports(input request.ListRatingSubReportsRequest) ([]response.RatingSubReportGroupResponse, *base.Pagination, message.Message) {
+	if input.Page <= 0 {
+		input.Page = 1
+	}
+	if input.Limit <= 0 {
+		input.Limit = 50
+	}
+
+	source := ""
+	var groups []entity.RatingSubReportGroup
+	var pagination *base.Pagination
+	var err error
+	if strings.ToLower(input.Source) == entity.RatingSubReportSourceMp {
+		source = entity.RatingSubReportSourceMp
+		groups, pagination, err = s.ratingMpRepo.GetRatingSubReportGroups(input.Page, input.Limit)
+	} else {
+		groups, pagination, err = s.ratingRepo.GetRatingSubReportGroups(input.Page, input.Limit)
+	}
+	if err != nil {
+		return nil, nil, message.FailedMsg
+	}
+
+	results := make([]response.RatingSubReportGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		data := response.RatingSubReportGroupResponse{
+			RatingSubmissionID: group.RatingSubmissionID,
+			Source:             source,
+			ModerationStatus:   group.ModerationStatus,
+			TotalReport:        group.TotalReport,
+			ReasonCodes:        map[string]int{},
+			LastReportedAt:     group.LastReportedAt,
+			Reports:            make([]response.RatingSubReportResponse, 0, len(group.Reports)),
+		}
+		if group.Comment != nil {
+			data.Comment = *group.Comment
+		}
+		for _, report := range group.Reports {
+			data.ReasonCodes[report.ReasonCode]++
+			data.Reports = append(data.Reports, response.RatingSubReportResponse{
+				ID:           report.ID.Hex(),
+				UserIDLegacy: report.UserIDLegacy,
+				ReasonCode:   report.ReasonCode,
+				Reason:       report.Reason,
+				CreatedAt:    report.CreatedAt,
+			})
+		}
+		results = append(results, data)
+	}
+	return results, pagination, message.SuccessMsg
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/model/response"
+	"go-klikdokter/app/repository"
+	"go-klikdokter/helper/message"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RatingSubRevisionService interface {
+	GetListRatingSubRevisions(input request.ListRatingSubRevisionsRequest) ([]response.RatingSubRevisionResponse, message.Message)
+}
+
+type ratingSubRevisionServiceImpl struct {
+	logger       log.Logger
+	ratingRepo   repository.RatingRepository
+	ratingMpRepo repository.RatingMpRepository
+}
+
+func NewRatingSubRevisionService(
+	lg log.Logger,
+	rr repository.RatingRepository,
+	rmr repository.RatingMpRepository,
+) RatingSubRevisionService {
+	return &ratingSubRevisionServiceImpl{lg, rr, rmr}
+}
+
+// validateRatingSubEdit refuses an edit once edit.max-count edits were made, or after edit.window-days
+// of the source type (edit.window-days.default for the others) since the creation. 0 or unset disables a limit.
+func validateRatingSubEdit(sourceType string, createdAt time.Time, editCounter int) message.Message {
+	if maxCount := viper.GetInt("edit.max-count"); maxCount > 0 && editCounter >= maxCount {
+		return message.ErrRatingSubEditLimitReached
+	}
+	if days := getEditWindowDays(sourceType); days > 0 && !createdAt.IsZero() && time.Since(createdAt) > time.Duration(days)*24*time.Hour {
+		return message.ErrRatingSubEditWindowExpired
+	}
+	return message.SuccessMsg
+}
+
+func getEditWindowDays(sourceType string) int {
+	key := "edit.window-days." + strings.ToLower(sourceType)
+	if sourceType != "" && viper.IsSet(key) {
+		return viper.GetInt(key)
+	}
+	return viper.GetInt("edit.window-days.default")
+}
+
+// swagger:route GET /moderation/rating-submission-revisions/{id} RatingSubRevision ListRatingSubRevisionsRequest
+// Get the edit history of a Rating Submission, every version with the changes of the edit replacing it
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingSubRevisionServiceImpl) GetListRatingSubRevisions(input request.ListRatingSubRevisionsRequest) ([]response.RatingSubRevisionResponse, message.Message) {
+	objectId, err := primitive.ObjectIDFromHex(input.ID)
+	if err != nil {
+		return nil, message.ErrRatingSubmissionNotFound
+	}
+
+	var current response.RatingSubRevisionResponse
+	var revisions []entity.RatingSubRevisionCol
+	if strings.ToLower(input.Source) == entity.RatingSubRevisionSourceMp {
+		ratingSubmission, err := s.ratingMpRepo.GetRatingSubmissionById(objectId)
+		if err != nil || ratingSubmission == nil {
+			return nil, message.ErrRatingSubmissionNotFound
+		}
+		current = response.RatingSubRevisionResponse{
+			Value:   strconv.Itoa(ratingSubmission.Value),
+			Comment: ratingSubmission.Comment,
+			Media:   ratingSubmission.Media,
+		}
+		revisions, err = s.ratingMpRepo.GetRatingSubRevisions(input.ID)
+		if err != nil {
+			return nil, message.FailedMsg
+		}
+	} else {
+		ratingSubmission, err := s.ratingRepo.GetRatingSubmissionById(objectId)
+		if err != nil || ratingSubmission == nil {
+			return nil, message.ErrRatingSubmissionNotFound
+		}
+		current = response.RatingSubRevisionResponse{
+			RatingID: ratingSubmission.RatingID,
+			Value:    ratingSubmission.Value,
+			Comment:  ratingSubmission.Comment,
+		}
+		revisions, err = s.ratingRepo.GetRatingSubRevisions(input.ID)
+		if err != nil {
+			return nil, message.FailedMsg
+		}
+	}
+
+	results := make([]response.RatingSubRevisionResponse, 0, len(revisions)+1)
+	for _, revision := range revisions {
+		editedAt := revision.CreatedAt
+		results = append(results, response.RatingSubRevisionResponse{
+			Revision: revision.Revision,
+			RatingID: revision.RatingID,
+			Value:    revision.Value,
+			Comment:  revision.Comment,
+			Media:    revision.Media,
+			EditedBy: revision.EditedBy,
+			EditedAt: &editedAt,
+		})
+	}
+	current.Revision = len(revisions) + 1
+	current.IsCurrent = true
+	results = append(results, current)
+
+	for i := 0; i < len(results)-1; i++ {
+		results[i].Changes = diffRatingSubVersions(results[i], results[i+1])
+	}
+	return results, message.SuccessMsg
+}
+
+// diffRatingSubVersions lists the fields changed from a version to the next one
+func diffRatingSubVersions(before, after response.RatingSubRevisionResponse) []response.RatingSubRevisionChange {
+	var changes []response.RatingSubRevisionChange
+	if before.RatingID != after.RatingID {
+		changes = append(changes, response.RatingSubRevisionChange{Field: "rating_id", Before: before.RatingID, After: after.RatingID})
+	}
+	if before.Value != after.Value {
+		changes = append(changes, response.RatingSubRevisionChange{Field: "value", Before: before.Value, After: after.Value})
+	}
+	beforeComment, afterComment := "", ""
+	if before.Comment != nil {
+		beforeComment = *before.Comment
+	}
+	if after.Comment != nil {
+		afterComment = *after.Comment
+	}
+	if beforeComment != afterComment {
+		changes = append(changes, response.RatingSubRevisionChange{Field: "comment", Before: beforeComment, After: afterComment})
+	}
+	if len(before.Media) != len(after.Media) || (len(before.Media) > 0 && !reflect.DeepEqual(before.Media, after.Media)) {
+		changes = append(changes, response.RatingSubRevisionChange{Field: "media", Before: before.Media, After: after.Media})
+	}
+	return changes
+}
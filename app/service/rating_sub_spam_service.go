@@ -0,0 +1,61 @@
+package service
+
+import (
+	"go-klikdokter/app/model/entity"
+	util_search "go-klikdokter/pkg/util/search"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+)
+
+// spamCounter is implemented by RatingRepository and RatingMpRepository
+type spamCounter interface {
+	CountRatingSubmissionsSince(field, value string, since time.Time) (int64, error)
+}
+
+// getSpamFlags runs the spam heuristics of the spam config on a new submission:
+// the same comment posted spam.duplicate-comments times, spam.reviews-per-ip reviews from one ip
+// within spam.window-minutes, or an account younger than spam.new-account-hours.
+// A failing count is logged and skipped, the submission is not held back by it.
+func getSpamFlags(logger log.Logger, counter spamCounter, comment, ipAddress string, accountCreatedAt *time.Time) []string {
+	if !viper.GetBool("spam.enabled") {
+		return nil
+	}
+
+	var flags []string
+	since := time.Now().Add(-time.Duration(viper.GetInt("spam.window-minutes")) * time.Minute)
+	if limit := viper.GetInt64("spam.duplicate-comments"); limit > 0 {
+		if normalized := util_search.Normalize(comment); normalized != "" {
+			count, err := counter.CountRatingSubmissionsSince("comment_search", normalized, since)
+			if err != nil {
+				_ = level.Error(logger).Log("msg", "spam duplicate comment count failed", "err", err)
+			} else if count+1 >= limit {
+				flags = append(flags, entity.ModerationFlagSpamDuplicateComment)
+			}
+		}
+	}
+	if limit := viper.GetInt64("spam.reviews-per-ip"); limit > 0 && ipAddress != "" {
+		count, err := counter.CountRatingSubmissionsSince("ip_address", ipAddress, since)
+		if err != nil {
+			_ = level.Error(logger).Log("msg", "spam ip count failed", "err", err)
+		} else if count+1 >= limit {
+			flags = append(flags, entity.ModerationFlagSpamIPBurst)
+		}
+	}
+	if hours := viper.GetInt("spam.new-account-hours"); hours > 0 && accountCreatedAt != nil {
+		if time.Since(*accountCreatedAt) < time.Duration(hours)*time.Hour {
+			flags = append(flags, entity.ModerationFlagSpamNewAccount)
+		}
+	}
+	return flags
+}
+
+// applySpamFlags holds a flagged submission for moderation
+func applySpamFlags(status string, flags, spamFlags []string) (string, []string) {
+	if len(spamFlags) == 0 {
+		return status, flags
+	}
+	return entity.ModerationStatusPending, append(flags, spamFlags...)
+}
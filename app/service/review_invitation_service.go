@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/model/response"
+	"go-klikdokter/app/repository"
+	"go-klikdokter/helper/message"
+	util_invitation "go-klikdokter/pkg/util/invitation"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultReviewInvitationExpiryDays = 30
+
+type ReviewInvitationService interface {
+	CreateReviewInvitations(input request.CreateReviewInvitationsRequest) ([]response.ReviewInvitationResponse, message.Message)
+	GetListPendingReviewInvitations(input request.ListPendingReviewInvitationsRequest) ([]response.ReviewInvitationResponse, *base.Pagination, message.Message)
+}
+
+type reviewInvitationServiceImpl struct {
+	logger       log.Logger
+	ratingMpRepo repository.RatingMpRepository
+}
+
+func NewReviewInvitationService(
+	lg log.Logger,
+	rmr repository.RatingMpRepository,
+) ReviewInvitationService {
+	return &reviewInvitationServiceImpl{lg, rmr}
+}
+
+// newReviewInvitation fills the rating types and the expiry of the invitation from the review-invitation config when empty
+func newReviewInvitation(orderNumber, userId, sourceType, sourceUid, storeUid string, ratingTypes []string, expiryDays int) entity.ReviewInvitationCol {
+	if len(ratingTypes) == 0 {
+		ratingTypes = viper.GetStringSlice("review-invitation.rating-types." + sourceType)
+	}
+	if expiryDays <= 0 {
+		expiryDays = viper.GetInt("review-invitation.expiry-days")
+	}
+	if expiryDays <= 0 {
+		expiryDays = defaultReviewInvitationExpiryDays
+	}
+	return entity.ReviewInvitationCol{
+		OrderNumber: orderNumber,
+		UserID:      userId,
+		SourceType:  sourceType,
+		SourceUID:   sourceUid,
+		StoreUID:    storeUid,
+		RatingTypes: ratingTypes,
+		ExpiredAt:   time.Now().AddDate(0, 0, expiryDays),
+	}
+}
+
+func toReviewInvitationResponse(invitation entity.ReviewInvitationCol) response.ReviewInvitationResponse {
+	result := response.ReviewInvitationResponse{
+		ID:          invitation.ID.Hex(),
+		OrderNumber: invitation.OrderNumber,
+		SourceType:  invitation.SourceType,
+		SourceUID:   invitation.SourceUID,
+		StoreUID:    invitation.StoreUID,
+		RatingTypes: invitation.RatingTypes,
+		Status:      invitation.Status,
+		ExpiredAt:   invitation.ExpiredAt,
+	}
+	if invitation.Status == entity.ReviewInvitationStatusPending && invitation.ExpiredAt.After(time.Now()) {
+		result.Token = util_invitation.SignToken(invitation.ID.Hex(), invitation.ExpiredAt)
+	}
+	return result
+}
+
+// getRedeemableReviewInvitation returns the invitation of the token when userId can redeem it for the source and the rating type
+func getRedeemableReviewInvitation(token, userId, sourceUid, ratingType string, find func(id primitive.ObjectID) (*entity.ReviewInvitationCol, error)) (*entity.ReviewInvitationCol, message.Message) {
+	id, err := util_invitation.ParseToken(token)
+	if err != nil {
+		if errors.Is(err, util_invitation.ErrTokenExpired) {
+			return nil, message.ErrInvitationExpired
+		}
+		return nil, message.ErrInvitationInvalid
+	}
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, message.ErrInvitationInvalid
+	}
+	invitation, err := find(objectId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, message.ErrInvitationInvalid
+		}
+		return nil, message.ErrDB
+	}
+
+	if invitation.UserID != userId || (sourceUid != "" && invitation.SourceUID != sourceUid) {
+		return nil, message.ErrInvitationInvalid
+	}
+	if invitation.Status != entity.ReviewInvitationStatusPending {
+		return nil, message.ErrInvitationNotPending
+	}
+	if !invitation.ExpiredAt.After(time.Now()) {
+		return nil, message.ErrInvitationExpired
+	}
+	if len(invitation.RatingTypes) > 0 && ratingType != "" && !isStringInSlice(ratingType, invitation.RatingTypes) {
+		return nil, message.ErrInvitationRatingType
+	}
+	return invitation, message.SuccessMsg
+}
+
+func isStringInSlice(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// swagger:route POST /review-invitations ReviewInvitation createReviewInvitations
+// Create Review Invitations of the lines of a completed order, the invitations already created are returned as is
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *reviewInvitationServiceImpl) CreateReviewInvitations(input request.CreateReviewInvitationsRequest) ([]response.ReviewInvitationResponse, message.Message) {
+	if err := input.Validate(); err != nil {
+		return nil, message.Message{
+			Code:    message.ValidationFailCode,
+			Message: err.Error(),
+		}
+	}
+
+	invitations := make([]entity.ReviewInvitationCol, 0, len(input.Items))
+	for _, item := range input.Items {
+		invitations = append(invitations, newReviewInvitation(input.OrderNumber, input.UserID, item.SourceType, item.SourceUID, item.StoreUID, item.RatingTypes, input.ExpiryDays))
+	}
+	created, err := s.ratingMpRepo.CreateReviewInvitations(invitations)
+	if err != nil {
+		return nil, message.ErrSaveData
+	}
+	if s.logger != nil {
+		_ = level.Info(s.logger).Log("Type", "Review Invitation", "order_number", input.OrderNumber, "created", created)
+	}
+
+	saved, err := s.ratingMpRepo.GetReviewInvitationsByOrderNumber(input.OrderNumber, input.UserID)
+	if err != nil {
+		return nil, message.FailedMsg
+	}
+	results := make([]response.ReviewInvitationResponse, 0, len(saved))
+	for _, invitation := range saved {
+		results = append(results, toReviewInvitationResponse(invitation))
+	}
+	return results, message.SuccessMsg
+}
+
+// swagger:route GET /review-invitations/pending ReviewInvitation getListPendingReviewInvitations
+// Get the pending Review Invitations of the user of the token, the ones expiring first on top
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *reviewInvitationServiceImpl) GetListPendingReviewInvitations(input request.ListPendingReviewInvitationsRequest) ([]response.ReviewInvitationResponse, *base.Pagination, message.Message) {
+	if input.UserID == "" {
+		return nil, nil, message.ErrUserNotFound
+	}
+	if input.Page <= 0 {
+		input.Page = 1
+	}
+	if input.Limit <= 0 {
+		input.Limit = 50
+	}
+
+	invitations, pagination, err := s.ratingMpRepo.GetPendingReviewInvitations(input.UserID, input.Page, input.Limit)
+	if err != nil {
+		return nil, nil, message.FailedMsg
+	}
+	results := make([]response.ReviewInvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		results = append(results, toReviewInvitationResponse(invitation))
+	}
+	return results, pagination, message.SuccessMsg
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/repository"
+	"go-klikdokter/helper/message"
+	"go-klikdokter/pkg/util"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaults of the soft-delete config
+const (
+	defaultSoftDeleteRetentionDays        = 30
+	defaultSoftDeletePurgeIntervalMinutes = 60
+)
+
+// softDeletedCollections are the collections whose deletes are soft, the purge job hard deletes their rows after the retention
+var softDeletedCollections = []string{
+	entity.RatingsCol{}.CollectionName(),
+	entity.RatingSubmisson{}.CollectionName(),
+	entity.RatingSubmissionMp{}.CollectionName(),
+	entity.RatingTypesNumCol{}.CollectionName(),
+	entity.RatingTypesLikertCol{}.CollectionName(),
+	entity.RatingFormulaCol{}.CollectionName(),
+}
+
+func getSoftDeleteConfigInt(key string, defaultValue int) int {
+	if value := viper.GetInt("soft-delete." + key); value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// swagger:route PUT /rating-types-numeric/{id}/restore RatingTypeNum restoreRatingTypeNum
+// Restore a deleted Numeric Rating Type
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) RestoreRatingTypeNumById(id string) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message.ErrNoData
+	}
+	return restoreMessage(s.ratingRepo.RestoreRatingTypeNum(objectId), message.ErrNoData)
+}
+
+// swagger:route PUT /rating-types-likert/{id}/restore RatingTypesLikert restoreRatingTypeLikert
+// Restore a deleted Likert Rating Type
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) RestoreRatingTypeLikertById(id string) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message.ErrNoData
+	}
+	return restoreMessage(s.ratingRepo.RestoreRatingTypeLikert(objectId), message.ErrNoData)
+}
+
+// swagger:route PUT /rating-formula/{id}/restore RatingFormula restoreRatingFormula
+// Restore a deleted Rating Formula
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) RestoreRatingFormulaById(id string) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message.ErrNoData
+	}
+	return restoreMessage(s.ratingRepo.RestoreRatingFormula(objectId), message.ErrNoData)
+}
+
+// swagger:route PUT /ratings/{id}/restore Ratings restoreRating
+// Restore a deleted Rating, unless a rating of the same type and source was created since
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	200: SuccessResponse
+func (s *ratingServiceImpl) RestoreRating(id string) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message.ErrDataNotFound
+	}
+	rating, err := s.ratingRepo.GetDeletedRatingById(objectId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.ErrDataNotFound
+		}
+		return message.FailedMsg
+	}
+
+	existing, err := s.ratingRepo.GetRatingByRatingTypeSourceUidAndSourceType(rating.RatingTypeId, rating.SourceUid, rating.SourceType)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return message.FailedMsg
+	}
+	if existing != nil {
+		return message.ErrExistingRatingTypeIdSourceUidAndSourceType
+	}
+	return restoreMessage(s.ratingRepo.RestoreRating(objectId), message.ErrDataNotFound)
+}
+
+// swagger:route PUT /rating-submissions/{id}/restore RatingSubmission restoreRatingSubmission
+// Restore a deleted Rating Submission, source mp restores a marketplace submission
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *ratingServiceImpl) RestoreRatingSubmission(id string) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message.ErrDataNotFound
+	}
+	return restoreMessage(s.ratingRepo.RestoreSubmission(objectId), message.ErrNoData)
+}
+
+// DeleteRatingSubmission soft deletes the marketplace submission, the final rating of its source no longer counts it
+func (s *ratingMpServiceImpl) DeleteRatingSubmission(id, deletedBy string) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message.ErrDataNotFound
+	}
+	previous, err := s.ratingMpRepo.SoftDeleteRatingSubmission(objectId, deletedBy)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.ErrNoData
+		}
+		return message.FailedMsg
+	}
+	s.emitFinalRatingOfSubmission("", *previous)
+	return message.SuccessMsg
+}
+
+// RestoreRatingSubmission restores the soft deleted marketplace submission, the final rating of its source counts it again
+func (s *ratingMpServiceImpl) RestoreRatingSubmission(id string) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message.ErrDataNotFound
+	}
+	current, err := s.ratingMpRepo.RestoreRatingSubmission(objectId)
+	if err != nil {
+		return restoreMessage(err, message.ErrNoData)
+	}
+	s.emitFinalRatingOfSubmission("", *current)
+	return message.SuccessMsg
+}
+
+// restoreMessage returns notFound when the row does not exist or is not deleted, ErrDataExists when a live row holds its unique key
+func restoreMessage(err error, notFound message.Message) message.Message {
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return notFound
+		}
+		// a row created since the delete holds the unique key of the restored one
+		if mongo.IsDuplicateKeyError(err) {
+			return message.ErrDataExists
+		}
+		return message.FailedMsg
+	}
+	return message.SuccessMsg
+}
+
+type PurgeService interface {
+	RunPurgeJob(ctx context.Context)
+	PurgeSoftDeleted() int64
+}
+
+type purgeServiceImpl struct {
+	logger     log.Logger
+	ratingRepo repository.RatingRepository
+}
+
+func NewPurgeService(
+	lg log.Logger,
+	rr repository.RatingRepository,
+) PurgeService {
+	return &purgeServiceImpl{lg, rr}
+}
+
+// RunPurgeJob hard deletes the expired soft deleted rows every purge interval until ctx is done
+func (s *purgeServiceImpl) RunPurgeJob(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(getSoftDeleteConfigInt("purge-interval-minutes", defaultSoftDeletePurgeIntervalMinutes)) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.PurgeSoftDeleted()
+		}
+	}
+}
+
+// PurgeSoftDeleted hard deletes the rows soft deleted more than soft-delete.retention-days ago, returns the number of rows deleted
+func (s *purgeServiceImpl) PurgeSoftDeleted() int64 {
+	retention := time.Duration(getSoftDeleteConfigInt("retention-days", defaultSoftDeleteRetentionDays)) * 24 * time.Hour
+	deletedBefore := time.Now().In(util.Loc).Add(-retention)
+
+	var total int64
+	for _, collectionName := range softDeletedCollections {
+		purged, err := s.ratingRepo.PurgeSoftDeleted(collectionName, deletedBefore)
+		if err != nil {
+			_ = level.Error(s.logger).Log("Type", "Purge", "collection", collectionName, "err", err)
+			continue
+		}
+		total += purged
+	}
+	return total
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultSourceTypeRefreshIntervalSeconds = 60
+
+type SourceTypeService interface {
+	GetListSourceTypes() ([]entity.SourceTypeCol, message.Message)
+	GetSourceTypeById(id string) (*entity.SourceTypeCol, message.Message)
+	CreateSourceType(input request.SaveSourceTypeRequest) (*entity.SourceTypeCol, message.Message)
+	UpdateSourceType(input request.SaveSourceTypeRequest) (*entity.SourceTypeCol, message.Message)
+	DeleteSourceType(id string) message.Message
+	SeedSourceTypes() error
+	LoadSourceTypes() error
+	RunSourceTypeRefresh(ctx context.Context)
+}
+
+type sourceTypeServiceImpl struct {
+	logger         log.Logger
+	sourceTypeRepo repository.SourceTypeRepository
+}
+
+func NewSourceTypeService(
+	lg log.Logger,
+	str repository.SourceTypeRepository,
+) SourceTypeService {
+	return &sourceTypeServiceImpl{lg, str}
+}
+
+// swagger:route GET /source-types SourceType getSourceTypes
+// Get List Source Types
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *sourceTypeServiceImpl) GetListSourceTypes() ([]entity.SourceTypeCol, message.Message) {
+	sourceTypes, err := s.sourceTypeRepo.GetSourceTypes()
+	if err != nil {
+		return nil, message.FailedMsg
+	}
+	return sourceTypes, message.SuccessMsg
+}
+
+// swagger:route GET /source-types/{id} SourceType getSourceTypeById
+// Get Source Type by Id
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *sourceTypeServiceImpl) GetSourceTypeById(id string) (*entity.SourceTypeCol, message.Message) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, message.ErrNoData
+	}
+	sourceType, err := s.sourceTypeRepo.GetSourceTypeById(objectId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, message.ErrNoData
+		}
+		return nil, message.FailedMsg
+	}
+	return sourceType, message.SuccessMsg
+}
+
+// swagger:route POST /source-types SourceType createSourceType
+// Create Source Type
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *sourceTypeServiceImpl) CreateSourceType(input request.SaveSourceTypeRequest) (*entity.SourceTypeCol, message.Message) {
+	if msg := s.validateSourceType(input, primitive.NilObjectID); msg != message.SuccessMsg {
+		return nil, msg
+	}
+	sourceType, err := s.sourceTypeRepo.CreateSourceType(toSourceTypeCol(input))
+	if err != nil {
+		// another admin saved the name since it was checked
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, message.ErrExistingSourceType
+		}
+		return nil, message.FailedMsg
+	}
+	s.reloadSourceTypes()
+	return sourceType, message.SuccessMsg
+}
+
+// swagger:route PUT /source-types/{id} SourceType updateSourceType
+// Update Source Type, a source type in use can not be renamed
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *sourceTypeServiceImpl) UpdateSourceType(input request.SaveSourceTypeRequest) (*entity.SourceTypeCol, message.Message) {
+	current, msg := s.GetSourceTypeById(input.Id)
+	if msg != message.SuccessMsg {
+		return nil, msg
+	}
+	if msg = s.validateSourceType(input, current.ID); msg != message.SuccessMsg {
+		return nil, msg
+	}
+	if input.Name != current.Name {
+		if msg = s.checkSourceTypeNotInUse(current.Name); msg != message.SuccessMsg {
+			return nil, msg
+		}
+	}
+
+	sourceType, err := s.sourceTypeRepo.UpdateSourceType(current.ID, toSourceTypeCol(input))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, message.ErrExistingSourceType
+		}
+		return nil, message.FailedMsg
+	}
+	s.reloadSourceTypes()
+	return sourceType, message.SuccessMsg
+}
+
+// swagger:route DELETE /source-types/{id} SourceType deleteSourceType
+// Delete Source Type, a source type in use can not be deleted
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *sourceTypeServiceImpl) DeleteSourceType(id string) message.Message {
+	current, msg := s.GetSourceTypeById(id)
+	if msg != message.SuccessMsg {
+		return msg
+	}
+	if msg = s.checkSourceTypeNotInUse(current.Name); msg != message.SuccessMsg {
+		return msg
+	}
+	if err := s.sourceTypeRepo.DeleteSourceType(current.ID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.ErrNoData
+		}
+		return message.FailedMsg
+	}
+	s.reloadSourceTypes()
+	return message.SuccessMsg
+}
+
+// SeedSourceTypes seeds sourceTypeCol with the defaults and ratingTypesNumCol with the rating types of their dimensions,
+// the first time the service starts on the database
+func (s *sourceTypeServiceImpl) SeedSourceTypes() error {
+	if err := s.sourceTypeRepo.SeedSourceTypes(entity.DefaultSourceTypes); err != nil {
+		return err
+	}
+	return s.sourceTypeRepo.SeedDimensionRatingTypes(entity.DefaultDimensionRatingTypesNum())
+}
+
+// LoadSourceTypes loads sourceTypeCol into the registry read by the services
+func (s *sourceTypeServiceImpl) LoadSourceTypes() error {
+	sourceTypes, err := s.sourceTypeRepo.GetSourceTypes()
+	if err != nil {
+		return err
+	}
+	global.SetSourceTypes(sourceTypes)
+	return nil
+}
+
+// RunSourceTypeRefresh reloads the registry every source-type.refresh-interval-seconds until ctx is done,
+// the changes made on another replica are read within the interval
+func (s *sourceTypeServiceImpl) RunSourceTypeRefresh(ctx context.Context) {
+	interval := viper.GetInt("source-type.refresh-interval-seconds")
+	if interval <= 0 {
+		interval = defaultSourceTypeRefreshIntervalSeconds
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reloadSourceTypes()
+		}
+	}
+}
+
+func (s *sourceTypeServiceImpl) reloadSourceTypes() {
+	if err := s.LoadSourceTypes(); err != nil {
+		_ = level.Error(s.logger).Log("Type", "LoadSourceTypes", "err", err)
+	}
+}
+
+// validateSourceType checks the request, the name and the rating types must not belong to another source type than id
+func (s *sourceTypeServiceImpl) validateSourceType(input request.SaveSourceTypeRequest, id primitive.ObjectID) message.Message {
+	if err := input.Validate(); err != nil {
+		return message.Message{
+			Code:    message.ValidationFailCode,
+			Message: err.Error(),
+		}
+	}
+	sourceTypes, err := s.sourceTypeRepo.GetSourceTypes()
+	if err != nil {
+		return message.FailedMsg
+	}
+	for _, sourceType := range sourceTypes {
+		if !id.IsZero() && sourceType.ID == id {
+			continue
+		}
+		if sourceType.Name == input.Name {
+			return message.ErrExistingSourceType
+		}
+		for _, ratingType := range input.RatingTypes {
+			if sourceType.HasRatingType(ratingType) {
+				return message.ErrSourceTypeRatingTypeInUse
+			}
+		}
+	}
+	return message.SuccessMsg
+}
+
+func (s *sourceTypeServiceImpl) checkSourceTypeNotInUse(name string) message.Message {
+	count, err := s.sourceTypeRepo.CountRatingsBySourceType(name)
+	if err != nil {
+		return message.FailedMsg
+	}
+	if count > 0 {
+		return message.ErrSourceTypeInUse
+	}
+	return message.SuccessMsg
+}
+
+func toSourceTypeCol(input request.SaveSourceTypeRequest) entity.SourceTypeCol {
+	return entity.SourceTypeCol{
+		Name:             input.Name,
+		RatingTypes:      input.RatingTypes,
+		IsMarketplace:    input.IsMarketplace,
+		Scale:            input.Scale,
+		AllowedValues:    input.AllowedValues,
+		DefaultFormula:   input.DefaultFormula,
+		FinalRatingTopic: input.FinalRatingTopic,
+		StoreSourceType:  input.StoreSourceType,
+		Dimensions:       input.Dimensions,
+		AllowComment:     input.AllowComment,
+		AllowMedia:       input.AllowMedia,
+	}
+}
+
+// validateSourceTypeContent checks the comment and the media of a submission against the registry,
+// nothing is checked for a source type missing from the registry
+func validateSourceTypeContent(sourceType, comment string, media []entity.MediaObj) message.Message {
+	registered, ok := global.GetSourceType(sourceType)
+	if !ok {
+		return message.SuccessMsg
+	}
+	if !registered.AllowComment && strings.TrimSpace(comment) != "" {
+		return message.ErrCommentNotAllowed
+	}
+	if !registered.AllowMedia && len(media) > 0 {
+		return message.ErrMediaNotAllowed
+	}
+	return message.SuccessMsg
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository"
+	"go-klikdokter/helper/message"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SubmissionTagService interface {
+	GetListSubmissionTags(input request.ListSubmissionTagsRequest) ([]entity.SubmissionTagCol, message.Message)
+	GetSubmissionTagById(id string) (*entity.SubmissionTagCol, message.Message)
+	CreateSubmissionTag(input request.SaveSubmissionTagRequest) (*entity.SubmissionTagCol, message.Message)
+	UpdateSubmissionTag(input request.SaveSubmissionTagRequest) (*entity.SubmissionTagCol, message.Message)
+	DeleteSubmissionTag(id string) message.Message
+}
+
+type submissionTagServiceImpl struct {
+	logger     log.Logger
+	ratingRepo repository.RatingRepository
+}
+
+func NewSubmissionTagService(
+	lg log.Logger,
+	rr repository.RatingRepository,
+) SubmissionTagService {
+	return &submissionTagServiceImpl{lg, rr}
+}
+
+// swagger:route GET /submission-tags SubmissionTag getSubmissionTags
+// Get List Submission Tags, the quick-pick reasons of a source type offered for a value
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *submissionTagServiceImpl) GetListSubmissionTags(input request.ListSubmissionTagsRequest) ([]entity.SubmissionTagCol, message.Message) {
+	var value float64
+	if input.Value != "" {
+		var err error
+		if value, err = strconv.ParseFloat(input.Value, 64); err != nil {
+			return nil, message.ErrValueFormatForNumericType
+		}
+	}
+
+	tags, err := s.ratingRepo.GetSubmissionTags(input.SourceType)
+	if err != nil {
+		return nil, message.FailedMsg
+	}
+	results := make([]entity.SubmissionTagCol, 0, len(tags))
+	for _, tag := range tags {
+		if input.Value != "" && !tag.MatchesValue(value) {
+			continue
+		}
+		results = append(results, tag.Localized(input.Lang))
+	}
+	return results, message.SuccessMsg
+}
+
+// swagger:route GET /submission-tags/{id} SubmissionTag getSubmissionTagById
+// Get Submission Tag by Id
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *submissionTagServiceImpl) GetSubmissionTagById(id string) (*entity.SubmissionTagCol, message.Message) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, message.ErrNoData
+	}
+	tag, err := s.ratingRepo.GetSubmissionTagById(objectId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, message.ErrNoData
+		}
+		return nil, message.FailedMsg
+	}
+	return tag, message.SuccessMsg
+}
+
+// swagger:route POST /submission-tags SubmissionTag createSubmissionTag
+// Create Submission Tag
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *submissionTagServiceImpl) CreateSubmissionTag(input request.SaveSubmissionTagRequest) (*entity.SubmissionTagCol, message.Message) {
+	if err := input.Validate(); err != nil {
+		return nil, message.Message{
+			Code:    message.ValidationFailCode,
+			Message: err.Error(),
+		}
+	}
+	tags, err := s.ratingRepo.GetSubmissionTags(input.SourceType)
+	if err != nil {
+		return nil, message.FailedMsg
+	}
+	if _, ok := findSubmissionTag(tags, input.Key); ok {
+		return nil, message.ErrExistingTag
+	}
+
+	tag, err := s.ratingRepo.CreateSubmissionTag(entity.SubmissionTagCol{
+		SourceType: input.SourceType,
+		Key:        input.Key,
+		Label:      input.Label,
+		LabelI18n:  input.LabelI18n,
+		MinValue:   input.MinValue,
+		MaxValue:   input.MaxValue,
+	})
+	if err != nil {
+		// another admin created the key since it was checked
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, message.ErrExistingTag
+		}
+		return nil, message.FailedMsg
+	}
+	return tag, message.SuccessMsg
+}
+
+// swagger:route PUT /submission-tags/{id} SubmissionTag updateSubmissionTag
+// Update Submission Tag, the source type and the key referenced by the submissions can not be updated
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *submissionTagServiceImpl) UpdateSubmissionTag(input request.SaveSubmissionTagRequest) (*entity.SubmissionTagCol, message.Message) {
+	current, msg := s.GetSubmissionTagById(input.Id)
+	if msg != message.SuccessMsg {
+		return nil, msg
+	}
+	input.SourceType = current.SourceType
+	input.Key = current.Key
+	if err := input.Validate(); err != nil {
+		return nil, message.Message{
+			Code:    message.ValidationFailCode,
+			Message: err.Error(),
+		}
+	}
+
+	tag, err := s.ratingRepo.UpdateSubmissionTag(current.ID, entity.SubmissionTagCol{
+		Label:     input.Label,
+		LabelI18n: input.LabelI18n,
+		MinValue:  input.MinValue,
+		MaxValue:  input.MaxValue,
+	})
+	if err != nil {
+		return nil, message.FailedMsg
+	}
+	return tag, message.SuccessMsg
+}
+
+// swagger:route DELETE /submission-tags/{id} SubmissionTag deleteSubmissionTag
+// Delete Submission Tag, the submissions keep its key and it is counted by key in the summaries
+//
+// security:
+// - Bearer: []
+// responses:
+//
+//	401: SuccessResponse
+//	200: SuccessResponse
+func (s *submissionTagServiceImpl) DeleteSubmissionTag(id string) message.Message {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message.ErrNoData
+	}
+	if err = s.ratingRepo.DeleteSubmissionTag(objectId); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return message.ErrNoData
+		}
+		return message.FailedMsg
+	}
+	return message.SuccessMsg
+}
+
+// validateSubmissionTags checks the tags of the submission against the catalogue of the source type,
+// a tag is given once and it must be offered for the value of the submission
+func validateSubmissionTags(sourceType, value string, keys []string, findTags func(sourceType string) ([]entity.SubmissionTagCol, error)) message.Message {
+	if len(keys) == 0 {
+		return message.SuccessMsg
+	}
+	tags, err := findTags(sourceType)
+	if err != nil {
+		return message.ErrDB
+	}
+	numValue, errValue := strconv.ParseFloat(value, 64)
+	given := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		tag, ok := findSubmissionTag(tags, key)
+		if !ok {
+			return message.ErrTagNotExist
+		}
+		if given[key] {
+			return message.ErrDuplicateTag
+		}
+		given[key] = true
+
+		if errValue != nil || !tag.MatchesValue(numValue) {
+			return message.ErrTagNotAllowedForValue
+		}
+	}
+	return message.SuccessMsg
+}
+
+func findSubmissionTag(tags []entity.SubmissionTagCol, key string) (entity.SubmissionTagCol, bool) {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return tag, true
+		}
+	}
+	return entity.SubmissionTagCol{}, false
+}
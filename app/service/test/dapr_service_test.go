@@ -0,0 +1,175 @@
+package test
+
+import (
+	"errors"
+	"go-klikdokter/app/model/entity"
+	request_dapr "go-klikdokter/app/model/request/dapr"
+	"go-klikdokter/app/model/response"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type daprMocks struct {
+	rating   *repository_mock.RatingRepositoryMock
+	ratingMp *repository_mock.RatingMpRepository
+	dapr     *repository_mock.DaprRepository
+}
+
+func newDaprSvc() (service.DaprService, daprMocks) {
+	mocks := daprMocks{
+		rating:   &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}},
+		ratingMp: &repository_mock.RatingMpRepository{Mock: mock.Mock{}},
+		dapr:     &repository_mock.DaprRepository{Mock: mock.Mock{}},
+	}
+	return service.NewDaprService(logger, mocks.rating, mocks.ratingMp, mocks.dapr), mocks
+}
+
+func parseCloudEvent(t *testing.T, body string) request_dapr.CloudEventRequest {
+	event, err := request_dapr.ParseCloudEvent([]byte(body))
+	assert.Nil(t, err)
+	return event
+}
+
+func TestGetDaprSubscriptions(t *testing.T) {
+	viper.Set("dapr.pubsub-name", "kafka-pubsub")
+	viper.Set("dapr.subscriptions.order-completed", "queuing.order.completed")
+	defer viper.Set("dapr.pubsub-name", nil)
+	defer viper.Set("dapr.subscriptions.order-completed", nil)
+	daprSvc, _ := newDaprSvc()
+
+	subscriptions := daprSvc.GetSubscriptions("/rating-svc/api/v1/dapr/subscriber")
+
+	assert.Equal(t, []response.DaprSubscription{{
+		PubsubName: "kafka-pubsub",
+		Topic:      "queuing.order.completed",
+		Route:      "/rating-svc/api/v1/dapr/subscriber/order-completed",
+	}}, subscriptions)
+}
+
+func TestSubscriberOrderCompleted(t *testing.T) {
+	viper.Set("review-invitation.rating-types.product", []string{"rating_for_product"})
+	viper.Set("review-invitation.rating-types.store", []string{"rating_for_store"})
+	defer viper.Set("review-invitation.rating-types.product", nil)
+	defer viper.Set("review-invitation.rating-types.store", nil)
+	daprSvc, mocks := newDaprSvc()
+	event := parseCloudEvent(t, `{"specversion":"1.0","id":"evt-1","source":"order-svc","topic":"queuing.order.completed",
+		"data":{"order_number":"ORD-1","user_id":"user-1","store_uid":"store-1","items":[{"product_uid":"product-1"},{"product_uid":"product-2"}]}}`)
+	invitations := []entity.ReviewInvitationCol{
+		{OrderNumber: "ORD-1", UserID: "user-1", SourceType: "store", SourceUID: "store-1", StoreUID: "store-1", RatingTypes: []string{"rating_for_store"}},
+		{OrderNumber: "ORD-1", UserID: "user-1", SourceType: "product", SourceUID: "product-1", StoreUID: "store-1", RatingTypes: []string{"rating_for_product"}},
+		{OrderNumber: "ORD-1", UserID: "user-1", SourceType: "product", SourceUID: "product-2", StoreUID: "store-1", RatingTypes: []string{"rating_for_product"}},
+	}
+
+	mocks.dapr.Mock.On("IsDaprEventProcessed", "order-completed:order-svc:evt-1").Return(false, nil).Once()
+	mocks.ratingMp.Mock.On("CreateReviewInvitations", mock.MatchedBy(func(created []entity.ReviewInvitationCol) bool {
+		if len(created) != len(invitations) {
+			return false
+		}
+		for i, invitation := range created {
+			// invitations expire after review-invitation.expiry-days, 30 days by default
+			expiry := time.Until(invitation.ExpiredAt)
+			if expiry < 29*24*time.Hour || expiry > 30*24*time.Hour {
+				return false
+			}
+			invitation.ExpiredAt = time.Time{}
+			if !assert.ObjectsAreEqual(invitations[i], invitation) {
+				return false
+			}
+		}
+		return true
+	})).Return(3, nil).Once()
+	mocks.dapr.Mock.On("SaveDaprEvent", "order-completed:order-svc:evt-1", "queuing.order.completed").Return(nil).Once()
+
+	assert.Equal(t, response.DaprStatusSuccess, daprSvc.SubscriberOrderCompleted(event))
+	mocks.ratingMp.AssertExpectations(t)
+	mocks.dapr.AssertExpectations(t)
+}
+
+func TestSubscriberOrderCompletedAlreadyProcessed(t *testing.T) {
+	daprSvc, mocks := newDaprSvc()
+	event := parseCloudEvent(t, `{"specversion":"1.0","id":"evt-1","source":"order-svc","data":{"order_number":"ORD-1"}}`)
+
+	mocks.dapr.Mock.On("IsDaprEventProcessed", "order-completed:order-svc:evt-1").Return(true, nil).Once()
+
+	assert.Equal(t, response.DaprStatusSuccess, daprSvc.SubscriberOrderCompleted(event))
+	mocks.ratingMp.AssertNotCalled(t, "CreateReviewInvitations", mock.Anything)
+}
+
+func TestSubscriberOrderCompletedInvalidData(t *testing.T) {
+	daprSvc, mocks := newDaprSvc()
+	event := parseCloudEvent(t, `{"specversion":"1.0","id":"evt-2","source":"order-svc","data":{"user_id":"user-1","items":[{"product_uid":"product-1"}]}}`)
+
+	mocks.dapr.Mock.On("IsDaprEventProcessed", mock.Anything).Return(false, nil).Once()
+
+	assert.Equal(t, response.DaprStatusDrop, daprSvc.SubscriberOrderCompleted(event))
+	mocks.ratingMp.AssertNotCalled(t, "CreateReviewInvitations", mock.Anything)
+	mocks.dapr.AssertNotCalled(t, "SaveDaprEvent", mock.Anything, mock.Anything)
+}
+
+func TestSubscriberOrderRefundedItems(t *testing.T) {
+	daprSvc, mocks := newDaprSvc()
+	event := parseCloudEvent(t, `{"specversion":"1.0","id":"evt-3","source":"order-svc","topic":"queuing.order.refunded",
+		"data_base64":"eyJvcmRlcl9udW1iZXIiOiJPUkQtMSIsIml0ZW1zIjpbeyJwcm9kdWN0X3VpZCI6InByb2R1Y3QtMSJ9XX0="}`)
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	submissions := []entity.RatingSubmissionMp{{ID: objectId, SourceType: "product", SourceUID: "product-1", OrderNumber: "ORD-1"}}
+
+	mocks.dapr.Mock.On("IsDaprEventProcessed", mock.Anything).Return(false, nil).Once()
+	mocks.ratingMp.Mock.On("GetRatingSubmissionsByOrderNumber", "ORD-1", []string{"product-1"}).Return(submissions, nil).Once()
+	mocks.ratingMp.Mock.On("CancelRatingSubmissionByIds", []primitive.ObjectID{objectId}, "order refunded").Return(nil).Once()
+	mocks.ratingMp.Mock.On("ScheduleFinalRating", "product", "product-1", mock.Anything).Return(nil).Once()
+	mocks.ratingMp.Mock.On("CancelReviewInvitationsByOrderNumber", "ORD-1", []string{"product-1"}).Return(nil).Once()
+	mocks.dapr.Mock.On("SaveDaprEvent", mock.Anything, "queuing.order.refunded").Return(nil).Once()
+
+	assert.Equal(t, response.DaprStatusSuccess, daprSvc.SubscriberOrderRefunded(event))
+	mocks.ratingMp.AssertExpectations(t)
+	mocks.rating.Mock.AssertNotCalled(t, "GetRatingSubmissionIdsByOrderNumber", mock.Anything)
+}
+
+func TestSubscriberOrderRefundedWholeOrder(t *testing.T) {
+	daprSvc, mocks := newDaprSvc()
+	event := parseCloudEvent(t, `{"specversion":"1.0","id":"evt-4","source":"order-svc","data":{"order_number":"ORD-2","reason":"cancelled by buyer"}}`)
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+
+	mocks.dapr.Mock.On("IsDaprEventProcessed", mock.Anything).Return(false, nil).Once()
+	mocks.ratingMp.Mock.On("GetRatingSubmissionsByOrderNumber", "ORD-2", []string(nil)).Return([]entity.RatingSubmissionMp{}, nil).Once()
+	mocks.rating.Mock.On("GetRatingSubmissionIdsByOrderNumber", "ORD-2").Return([]primitive.ObjectID{objectId}, nil).Once()
+	mocks.ratingMp.Mock.On("CancelReviewInvitationsByOrderNumber", "ORD-2", []string(nil)).Return(nil).Once()
+	mocks.dapr.Mock.On("SaveDaprEvent", mock.Anything, mock.Anything).Return(nil).Once()
+
+	assert.Equal(t, response.DaprStatusSuccess, daprSvc.SubscriberOrderRefunded(event))
+	mocks.rating.Mock.AssertExpectations(t)
+	mocks.ratingMp.AssertNotCalled(t, "CancelRatingSubmissionByIds", mock.Anything, mock.Anything)
+}
+
+func TestSubscriberOrderRefundedRetry(t *testing.T) {
+	daprSvc, mocks := newDaprSvc()
+	event := parseCloudEvent(t, `{"specversion":"1.0","id":"evt-5","source":"order-svc","data":{"order_number":"ORD-3"}}`)
+
+	mocks.dapr.Mock.On("IsDaprEventProcessed", mock.Anything).Return(false, nil).Once()
+	mocks.ratingMp.Mock.On("GetRatingSubmissionsByOrderNumber", "ORD-3", []string(nil)).Return(nil, errors.New("connection refused")).Once()
+
+	assert.Equal(t, response.DaprStatusRetry, daprSvc.SubscriberOrderRefunded(event))
+	mocks.dapr.AssertNotCalled(t, "SaveDaprEvent", mock.Anything, mock.Anything)
+}
+
+func TestSubscriberUserProfileUpdatedRawPayload(t *testing.T) {
+	daprSvc, mocks := newDaprSvc()
+	event := parseCloudEvent(t, `{"user_id":"user-1","display_name":"Budi"}`)
+	displayName := "Budi"
+
+	mocks.dapr.Mock.On("IsDaprEventProcessed", mock.Anything).Return(false, nil).Once()
+	mocks.rating.Mock.On("UpdateRatingSubmissionUserProfile", "user-1", &displayName, (*string)(nil)).Return(nil).Once()
+	mocks.ratingMp.Mock.On("UpdateRatingSubmissionUserProfile", "user-1", &displayName, (*string)(nil)).Return(nil).Once()
+	mocks.dapr.Mock.On("SaveDaprEvent", mock.Anything, "").Return(nil).Once()
+
+	assert.Equal(t, response.DaprStatusSuccess, daprSvc.SubscriberUserProfileUpdated(event))
+	mocks.rating.Mock.AssertExpectations(t)
+	mocks.ratingMp.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"errors"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/message"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newOutboxSvc(repo *repository_mock.OutboxRepository, deliver service.OutboxDeliverer) service.OutboxService {
+	return service.NewOutboxService(logger, repo, service.OutboxDeliverers{entity.OutboxTargetPaymentUpdateFlag: deliver})
+}
+
+func TestDispatchOutboxMessagesDelivered(t *testing.T) {
+	repo := &repository_mock.OutboxRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	msg := entity.OutboxCol{ID: objectId, Target: entity.OutboxTargetPaymentUpdateFlag, Payload: entity.OutboxPayload{OrderNumber: "888888"}}
+	var delivered []string
+
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(&msg, nil).Once()
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(nil, nil).Once()
+	repo.Mock.On("MarkOutboxMessageDelivered", objectId).Return(nil).Once()
+
+	processed := newOutboxSvc(repo, func(msg entity.OutboxCol) error {
+		delivered = append(delivered, msg.Payload.OrderNumber)
+		return nil
+	}).DispatchOutboxMessages()
+
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, []string{"888888"}, delivered)
+	repo.AssertExpectations(t)
+}
+
+func TestDispatchOutboxMessagesRetryWithBackoff(t *testing.T) {
+	viper.Set("outbox.backoff-min-seconds", 10)
+	defer viper.Set("outbox.backoff-min-seconds", nil)
+	repo := &repository_mock.OutboxRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	msg := entity.OutboxCol{ID: objectId, Target: entity.OutboxTargetPaymentUpdateFlag, Attempts: 2}
+
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(&msg, nil).Once()
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(nil, nil).Once()
+	repo.Mock.On("MarkOutboxMessageFailed", objectId, 3, "payment service is down", mock.MatchedBy(func(next time.Time) bool {
+		// third attempt waits 2^2 * backoff-min-seconds
+		wait := time.Until(next)
+		return wait > 35*time.Second && wait <= 40*time.Second
+	}), false).Return(nil).Once()
+
+	newOutboxSvc(repo, func(msg entity.OutboxCol) error {
+		return errors.New("payment service is down")
+	}).DispatchOutboxMessages()
+
+	repo.AssertExpectations(t)
+}
+
+func TestDispatchOutboxMessagesDeadLetter(t *testing.T) {
+	viper.Set("outbox.max-attempts", 3)
+	defer viper.Set("outbox.max-attempts", nil)
+	repo := &repository_mock.OutboxRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	unknownId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84827")
+
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(&entity.OutboxCol{ID: objectId, Target: entity.OutboxTargetPaymentUpdateFlag, Attempts: 2}, nil).Once()
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(&entity.OutboxCol{ID: unknownId, Target: "unknown"}, nil).Once()
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(nil, nil).Once()
+	repo.Mock.On("MarkOutboxMessageFailed", objectId, 3, "payment service is down", mock.Anything, true).Return(nil).Once()
+	repo.Mock.On("MarkOutboxMessageFailed", unknownId, 1, "unknown outbox target unknown", mock.Anything, true).Return(nil).Once()
+
+	processed := newOutboxSvc(repo, func(msg entity.OutboxCol) error {
+		return errors.New("payment service is down")
+	}).DispatchOutboxMessages()
+
+	assert.Equal(t, 2, processed)
+	repo.AssertExpectations(t)
+}
+
+func TestDispatchOutboxMessagesDeadAfterReclaims(t *testing.T) {
+	viper.Set("outbox.max-attempts", 3)
+	defer viper.Set("outbox.max-attempts", nil)
+	repo := &repository_mock.OutboxRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	delivered := false
+
+	// the message crashed its dispatcher three times, every reclaim counted an attempt
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(&entity.OutboxCol{ID: objectId, Target: entity.OutboxTargetPaymentUpdateFlag, Attempts: 3}, nil).Once()
+	repo.Mock.On("ClaimOutboxMessage", mock.Anything).Return(nil, nil).Once()
+	repo.Mock.On("MarkOutboxMessageFailed", objectId, 3, "outbox lock expired before the message was delivered", mock.Anything, true).Return(nil).Once()
+
+	processed := newOutboxSvc(repo, func(msg entity.OutboxCol) error {
+		delivered = true
+		return nil
+	}).DispatchOutboxMessages()
+
+	assert.Equal(t, 1, processed)
+	assert.False(t, delivered)
+	repo.AssertExpectations(t)
+}
+
+func TestGetOutboxMessagesInvalidStatus(t *testing.T) {
+	repo := &repository_mock.OutboxRepository{Mock: mock.Mock{}}
+
+	_, _, msg := newOutboxSvc(repo, nil).GetOutboxMessages(request.GetOutboxMessagesRequest{Status: "failed"})
+
+	assert.Equal(t, message.ValidationFailCode, msg.Code)
+	repo.AssertNotCalled(t, "GetOutboxMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReplayOutboxMessage(t *testing.T) {
+	repo := &repository_mock.OutboxRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+
+	repo.Mock.On("GetOutboxMessageById", objectId).Return(&entity.OutboxCol{ID: objectId, Status: entity.OutboxStatusDead}, nil).Once()
+	repo.Mock.On("ReplayOutboxMessage", objectId).Return(nil).Once()
+
+	msg := newOutboxSvc(repo, nil).ReplayOutboxMessage(request.OutboxMessageRequest{Id: objectId.Hex()})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	repo.AssertExpectations(t)
+}
+
+func TestReplayOutboxMessageProcessing(t *testing.T) {
+	repo := &repository_mock.OutboxRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+
+	repo.Mock.On("GetOutboxMessageById", objectId).Return(&entity.OutboxCol{ID: objectId, Status: entity.OutboxStatusProcessing}, nil).Once()
+
+	msg := newOutboxSvc(repo, nil).ReplayOutboxMessage(request.OutboxMessageRequest{Id: objectId.Hex()})
+
+	assert.Equal(t, message.ErrOutboxMessageProcessing, msg)
+	repo.AssertNotCalled(t, "ReplayOutboxMessage", objectId)
+}
+
+func TestReplayOutboxMessageNotFound(t *testing.T) {
+	repo := &repository_mock.OutboxRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+
+	repo.Mock.On("GetOutboxMessageById", objectId).Return(nil, mongo.ErrNoDocuments).Once()
+
+	msg := newOutboxSvc(repo, nil).ReplayOutboxMessage(request.OutboxMessageRequest{Id: objectId.Hex()})
+
+	assert.Equal(t, message.ErrNoData, msg)
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/message"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newDimensionSubmission(dimensions ...entity.DimensionValue) request.CreateRatingSubmissionRequest {
+	userId := "34343433"
+	return request.CreateRatingSubmissionRequest{
+		UserIDLegacy:  &userId,
+		DisplayName:   &name,
+		SourceTransID: "888889",
+		SourceUID:     "Frtgffggffgft123",
+		RatingType:    "rating_for_store",
+		Value:         "3",
+		Comment:       "Pengiriman cepat",
+		Dimensions:    dimensions,
+	}
+}
+
+func newDimensionRatingMpRepo(t *testing.T) *repository_mock.RatingMpRepository {
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	minScore, maxScore, intervals, scale := 1, 5, 5, 0
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_store").Return(&entity.RatingTypesNumCol{ID: objectID}, nil)
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_store_delivery_speed").
+		Return(&entity.RatingTypesNumCol{MinScore: &minScore, MaxScore: &maxScore, Intervals: &intervals, Scale: &scale}, nil)
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_store_seller_response").Return(nil, mongo.ErrNoDocuments)
+	return repo
+}
+
+func TestCreateRatingSubmissionMpDimensionNotExist(t *testing.T) {
+	repo := newDimensionRatingMpRepo(t)
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(),
+		newDimensionSubmission(entity.DimensionValue{Name: "as_described", Value: 5}))
+
+	assert.Equal(t, message.ErrDimensionNotExist, msg)
+	repo.Mock.AssertNotCalled(t, "CreateRatingSubmission", mock.Anything, mock.Anything)
+}
+
+func TestCreateRatingSubmissionMpDuplicateDimension(t *testing.T) {
+	repo := newDimensionRatingMpRepo(t)
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(),
+		newDimensionSubmission(entity.DimensionValue{Name: "delivery_speed", Value: 5}, entity.DimensionValue{Name: "delivery_speed", Value: 4}))
+
+	assert.Equal(t, message.ErrDuplicateDimension, msg)
+}
+
+func TestCreateRatingSubmissionMpDimensionWrongValue(t *testing.T) {
+	repo := newDimensionRatingMpRepo(t)
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(),
+		newDimensionSubmission(entity.DimensionValue{Name: "delivery_speed", Value: 6}))
+
+	assert.Equal(t, message.ValidationFailCode, msg.Code)
+	assert.Contains(t, msg.Message, "[1 2 3 4 5]")
+}
+
+func TestCreateRatingSubmissionMpDimensionRatingTypeNotExist(t *testing.T) {
+	repo := newDimensionRatingMpRepo(t)
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(),
+		newDimensionSubmission(entity.DimensionValue{Name: "seller_response", Value: 3}))
+
+	assert.Equal(t, message.ErrRatingTypeNotExist, msg)
+}
@@ -2,16 +2,21 @@ package test
 
 import (
 	"context"
+	"go-klikdokter/app/model/base"
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
 	"go-klikdokter/app/repository/repository_mock"
 	"go-klikdokter/app/service"
+	"go-klikdokter/helper/global"
 	"go-klikdokter/helper/message"
+	util_moderation "go-klikdokter/pkg/util/moderation"
 	"strconv"
 	"testing"
+	"time"
 
 	publicresponse "go-klikdokter/app/model/response/public"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -26,7 +31,6 @@ func TestCreateRatingSubmissionMpSuccess(t *testing.T) {
 	userId := "34343432"
 	orderNumber := "888888"
 	value := "4"
-	SourceTransID := orderNumber + "||product||Frtgffggffgft123||34343432"
 	input := request.CreateRatingSubmissionRequest{
 		UserID:        &userId,
 		UserIDLegacy:  &userId,
@@ -50,7 +54,7 @@ func TestCreateRatingSubmissionMpSuccess(t *testing.T) {
 		UserID:        &userId,
 		UserIDLegacy:  &userId,
 		RatingID:      id,
-		SourceTransID: orderNumber + "||product||Frtgffggffgft123||34343432",
+		SourceTransID: orderNumber,
 		Value:         valueInt,
 		OrderNumber:   orderNumber,
 		Media:         nil,
@@ -60,18 +64,19 @@ func TestCreateRatingSubmissionMpSuccess(t *testing.T) {
 
 	saveReq := []entity.RatingSubmissionMp{
 		{
-			UserID:        &userId,
-			UserIDLegacy:  &userId,
-			DisplayName:   &name,
-			SourceTransID: orderNumber + "||product||Frtgffggffgft123||34343432",
-			SourceUID:     "Frtgffggffgft123",
-			SourceType:    "product",
-			Value:         valueInt,
-			Media:         nil,
-			OrderNumber:   orderNumber,
-			RatingTypeID:  ratingTypeID.ID.Hex(),
-			Comment:       &userId,
-			StoreUID:      input.StoreUID,
+			UserID:           &userId,
+			UserIDLegacy:     &userId,
+			DisplayName:      &name,
+			SourceTransID:    orderNumber,
+			SourceUID:        "Frtgffggffgft123",
+			SourceType:       "product",
+			Value:            valueInt,
+			Media:            nil,
+			OrderNumber:      orderNumber,
+			RatingTypeID:     ratingTypeID.ID.Hex(),
+			Comment:          &userId,
+			StoreUID:         input.StoreUID,
+			ModerationStatus: entity.ModerationStatusApproved,
 		},
 	}
 	arrSub := []entity.RatingSubmissionMp{sub}
@@ -93,9 +98,16 @@ func TestCreateRatingSubmissionMpSuccess(t *testing.T) {
 		Status: &status, 
 	}
 
-	ratingMpRepository.Mock.On("FindRatingSubmissionBySourceTransID", SourceTransID).Return(nil, gorm.ErrRecordNotFound)
+	ratingMpRepository.Mock.On("FindRatingSubmissionByOrderLine", orderNumber, "product", input.SourceUID, userId).Return(nil, gorm.ErrRecordNotFound)
+	ratingMpRepository.Mock.On("GetReviewInvitationByOrderLine", orderNumber, userId, "product", input.SourceUID).Return(nil, mongo.ErrNoDocuments)
 	ratingMpRepository.Mock.On("FindRatingTypeNumByRatingType", input.RatingType).Return(&ratingTypeID, nil)
-	ratingMpRepository.Mock.On("CreateRatingSubmission", saveReq).Return(&arrSub, nil)
+	outbox := []entity.OutboxCol{
+		{
+			Target:  entity.OutboxTargetPaymentReviewProduct,
+			Payload: entity.OutboxPayload{OrderNumber: orderNumber, SourceType: "product", SourceUID: input.SourceUID},
+		},
+	}
+	ratingMpRepository.Mock.On("CreateRatingSubmission", saveReq, outbox).Return(&arrSub, nil)
 	ratingMpRepository.Mock.On("GetRatingSubsGroupByValue", input.SourceUID, "product").Return(valueGroupBy, nil)
 	ratingMpRepository.Mock.On("GetRatingFormulaBySourceType", "product").Return(&formula, nil)
 
@@ -128,3 +140,298 @@ func TestGetRatingSubmissionMpFail(t *testing.T) {
 
 	assert.Equal(t, message.ErrRatingSubmissionNotFound, msg)
 }
+
+func TestReplyRatingSubmissionMpOtherStore(t *testing.T) {
+	subId := "629dce7bf1f26275e0d84830"
+	objectId, _ := primitive.ObjectIDFromHex(subId)
+	getSub := entity.RatingSubmissionMp{
+		ID:       objectId,
+		StoreUID: "store-a",
+	}
+	ratingMpRepository.Mock.On("GetRatingSubmissionById", objectId).Return(&getSub, nil)
+
+	msg := ratingMpSvc.ReplyAdminRatingSubmission(request.ReplyAdminRatingSubmissionRequest{
+		ID:     subId,
+		Reply:  "terima kasih",
+		JWTObj: global.JWTObj{Fullname: "Store B", StoreUID: "store-b", Roles: []string{global.RoleMerchant}},
+	})
+
+	assert.Equal(t, message.ErrReplyStoreNotAllowed, msg)
+}
+
+func TestReplyRatingSubmissionMpEditKeepHistory(t *testing.T) {
+	subId := "629dce7bf1f26275e0d84831"
+	objectId, _ := primitive.ObjectIDFromHex(subId)
+	repliedAt := time.Now().Add(-time.Hour)
+	getSub := entity.RatingSubmissionMp{
+		ID:        objectId,
+		StoreUID:  "store-a",
+		Reply:     "first reply",
+		ReplyBy:   "Store A",
+		RepliedAt: &repliedAt,
+	}
+	ratingMpRepository.Mock.On("GetRatingSubmissionById", objectId).Return(&getSub, nil)
+	ratingMpRepository.Mock.On("UpdateRatingSubmission", mock.MatchedBy(func(sub entity.RatingSubmissionMp) bool {
+		return sub.ID == objectId && sub.Reply == "second reply" && sub.ReplyEdited &&
+			len(sub.ReplyHistory) == 1 && sub.ReplyHistory[0].Reply == "first reply" && sub.RepliedAt.After(repliedAt)
+	}), objectId).Return(nil)
+
+	msg := ratingMpSvc.ReplyAdminRatingSubmission(request.ReplyAdminRatingSubmissionRequest{
+		ID:     subId,
+		Reply:  "second reply",
+		JWTObj: global.JWTObj{Fullname: "Store A", StoreUID: "store-a", Roles: []string{global.RoleMerchant}},
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestHideReplyRatingSubmissionMp(t *testing.T) {
+	subId := "629dce7bf1f26275e0d84832"
+	objectId, _ := primitive.ObjectIDFromHex(subId)
+	getSub := entity.RatingSubmissionMp{
+		ID:    objectId,
+		Reply: "spam reply",
+	}
+	ratingMpRepository.Mock.On("GetRatingSubmissionById", objectId).Return(&getSub, nil)
+	ratingMpRepository.Mock.On("UpdateRatingSubmission", mock.MatchedBy(func(sub entity.RatingSubmissionMp) bool {
+		return sub.ID == objectId && sub.ReplyHidden && sub.ReplyHiddenBy == "Admin"
+	}), objectId).Return(nil)
+
+	msg := ratingMpSvc.HideReplyRatingSubmission(request.HideReplyRatingSubmissionRequest{
+		ID:     subId,
+		Hidden: true,
+		JWTObj: global.JWTObj{Fullname: "Admin", Roles: []string{global.RoleAdmin}},
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestModerationStatusFlaggedComment(t *testing.T) {
+	viper.Set("moderation.enabled", true)
+	viper.Set("moderation.pii-detection", true)
+	viper.Set("moderation.block-links", true)
+	viper.Set("moderation.banned-words", []string{"bodoh"})
+	defer viper.Set("moderation.enabled", false)
+
+	status, flags := util_moderation.GetModerationStatus("hubungi saya di 0812-3456-7890 atau cek www.tokosebelah.com")
+	assert.Equal(t, entity.ModerationStatusPending, status)
+	assert.Equal(t, []string{"pii_phone", "link"}, flags)
+
+	status, flags = util_moderation.GetModerationStatus("Penjual BODOH")
+	assert.Equal(t, entity.ModerationStatusPending, status)
+	assert.Equal(t, []string{"banned_word"}, flags)
+
+	status, flags = util_moderation.GetModerationStatus("barang bagus, pengiriman cepat")
+	assert.Equal(t, entity.ModerationStatusApproved, status)
+	assert.Empty(t, flags)
+}
+
+func TestModerateRatingSubmissionMpReject(t *testing.T) {
+	subId := "629dce7bf1f26275e0d84833"
+	objectId, _ := primitive.ObjectIDFromHex(subId)
+	getSub := entity.RatingSubmissionMp{
+		ID:               objectId,
+		SourceType:       "store",
+		ModerationStatus: entity.ModerationStatusPending,
+	}
+	ratingMpRepository.Mock.On("GetRatingSubmissionById", objectId).Return(&getSub, nil)
+	ratingMpRepository.Mock.On("UpdateRatingSubmission", mock.MatchedBy(func(sub entity.RatingSubmissionMp) bool {
+		return sub.ID == objectId && sub.ModerationStatus == entity.ModerationStatusRejected &&
+			sub.ModerationReason == "contains phone number" && sub.ModeratedBy == "Admin" && sub.ModeratedAt != nil
+	}), objectId).Return(nil)
+
+	msg := ratingMpSvc.ModerateRatingSubmission(request.ModerateRatingSubmissionRequest{
+		ID:     subId,
+		Source: "mp",
+		Status: entity.ModerationStatusRejected,
+		Reason: "contains phone number",
+		JWTObj: global.JWTObj{Fullname: "Admin", Roles: []string{global.RoleAdmin}},
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestModerateRatingSubmissionRequestReasonRequired(t *testing.T) {
+	err := request.ModerateRatingSubmissionRequest{ID: id, Status: entity.ModerationStatusRejected}.Validate()
+	assert.NotNil(t, err)
+
+	err = request.ModerateRatingSubmissionRequest{ID: id, Status: entity.ModerationStatusApproved}.Validate()
+	assert.Nil(t, err)
+
+	err = request.ModerateRatingSubmissionRequest{ID: id, Status: entity.ModerationStatusPending}.Validate()
+	assert.NotNil(t, err)
+}
+
+func TestGetListModerationQueueMp(t *testing.T) {
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84834")
+	comment := "hubungi 081234567890"
+	pending := []entity.RatingSubmissionMp{
+		{
+			ID:               objectId,
+			Comment:          &comment,
+			Value:            5,
+			ModerationStatus: entity.ModerationStatusPending,
+			ModerationFlags:  []string{"pii_phone"},
+		},
+	}
+	filter := request.RatingSubmissionMpFilter{ModerationStatus: entity.ModerationStatusPending}
+	ratingMpRepository.Mock.On("GetListRatingSubmissions", filter, 1, int64(50), "created_at", 1).Return(pending, &base.Pagination{}, nil)
+
+	result, _, msg := ratingMpSvc.GetListModerationQueue(request.ListModerationQueueRequest{Source: "mp"})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, objectId.Hex(), result[0].ID)
+	assert.Equal(t, comment, result[0].Comment)
+	assert.Equal(t, []string{"pii_phone"}, result[0].ModerationFlags)
+}
+
+func TestRepublishFinalRatings(t *testing.T) {
+	ratingMpRepository.Mock.On("GetFinalRatingSourceUids", "store").Return([]string{"store-1", "store-2"}, nil).Once()
+
+	total, msg := ratingMpSvc.RepublishFinalRatings(request.RepublishFinalRatingsRequest{SourceType: "store"})
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 2, total)
+}
+
+func TestRepublishFinalRatingsInvalidSourceType(t *testing.T) {
+	total, msg := ratingMpSvc.RepublishFinalRatings(request.RepublishFinalRatingsRequest{SourceType: "doctor"})
+	assert.Equal(t, message.ValidationFailCode, msg.Code)
+	assert.Equal(t, 0, total)
+}
+
+func TestRepublishFinalRatingsSkipUnchanged(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	svcWithLogger := service.NewRatingMpService(logger, repo)
+	checked := make(chan bool)
+
+	repo.Mock.On("GetFinalRatingSourceUids", "product").Return([]string{"product-1"}, nil).Once()
+	repo.Mock.On("GetRatingSubsGroupByValue", "product-1", "product").
+		Return([]publicresponse.PublicRatingSubGroupByValue{{ConvertedValue: 5, Total: 1}, {ConvertedValue: 4, Total: 1}}, nil).Once()
+	repo.Mock.On("GetRatingFormulaBySourceType", "product").Return(&entity.RatingFormulaCol{Formula: "sum / count"}, nil).Once()
+	repo.Mock.On("GetFinalRatingBySource", "product", "product-1").
+		Return(&entity.FinalRatingCol{Value: "4.5", TotalReviewer: 2, Histogram: map[string]int64{"5": 1, "4": 1}}, nil).
+		Run(func(args mock.Arguments) { checked <- true }).Once()
+
+	total, msg := svcWithLogger.RepublishFinalRatings(request.RepublishFinalRatingsRequest{SourceType: "product", OnlyChanged: true})
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, total)
+
+	select {
+	case <-checked:
+	case <-time.After(time.Second):
+		t.Fatal("final rating was not compared with the published one")
+	}
+	time.Sleep(50 * time.Millisecond)
+	repo.AssertNotCalled(t, "SaveFinalRating", mock.Anything, mock.Anything)
+}
+
+func TestRepublishFinalRatingsStoreSourceTypeFromRegistry(t *testing.T) {
+	t.Cleanup(func() { global.SetSourceTypes(entity.DefaultSourceTypes) })
+	global.SetSourceTypes([]entity.SourceTypeCol{
+		{Name: "pharmacy", IsMarketplace: true, Scale: 5, StoreSourceType: "pharmacy-store"},
+		{Name: "pharmacy-store", IsMarketplace: true, Scale: 3, FinalRatingTopic: "queuing.rnr.ts-final-rating-pharmacy-store"},
+	})
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	svcWithLogger := service.NewRatingMpService(logger, repo)
+	saved := make(chan entity.OutboxCol, 1)
+
+	repo.Mock.On("GetFinalRatingSourceUids", "pharmacy-store").Return([]string{"store-1"}, nil).Once()
+	repo.Mock.On("GetRatingSubsGroupByValue", "store-1", "pharmacy-store").
+		Return([]publicresponse.PublicRatingSubGroupByValue{{ConvertedValue: 3, Total: 1}}, nil).Once()
+	repo.Mock.On("GetRatingFormulaBySourceType", "pharmacy-store").Return(&entity.RatingFormulaCol{Formula: "sum / count"}, nil).Once()
+	repo.Mock.On("GetFinalRatingBySource", "pharmacy-store", "store-1").Return(nil, nil).Once()
+	repo.Mock.On("SaveFinalRating", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { saved <- args.Get(1).(entity.OutboxCol) }).Return(nil).Once()
+
+	total, msg := svcWithLogger.RepublishFinalRatings(request.RepublishFinalRatingsRequest{SourceType: "pharmacy-store"})
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 1, total)
+
+	select {
+	case event := <-saved:
+		assert.Equal(t, "queuing.rnr.ts-final-rating-pharmacy-store", event.Payload.Topic)
+		assert.Contains(t, event.Payload.Data, `"store_uid":"store-1"`)
+	case <-time.After(time.Second):
+		t.Fatal("final rating was not saved")
+	}
+}
+
+func TestCreateRatingSubHelpfulMpNotHelpful(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	input := request.CreateRatingSubHelpfulRequest{
+		RatingSubmissionID: "629dce7bf1f26275e0d84826",
+		UserIDLegacy:       "34343432",
+		Vote:               request.VoteNotHelpful,
+	}
+
+	repo.Mock.On("ToggleRatingSubHelpful", input).
+		Return(&entity.RatingSubHelpfulCol{NotHelpful: true}, &entity.RatingSubHelpfulCounter{LikeCounter: 1, NotHelpfulCounter: 2}, nil).Once()
+
+	result, msg := service.NewRatingMpService(logger, repo).CreateRatingSubHelpfulMp(input)
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, "NotHelpful", result.Status)
+	assert.Equal(t, 2, result.NotHelpfulCounter)
+	assert.Equal(t, 0.33, result.HelpfulRatio)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateRatingSubHelpfulMpNotFound(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	input := request.CreateRatingSubHelpfulRequest{RatingSubmissionID: "xxx", UserIDLegacy: "34343432"}
+
+	repo.Mock.On("ToggleRatingSubHelpful", input).Return(nil, nil, mongo.ErrNoDocuments).Once()
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubHelpfulMp(input)
+	assert.Equal(t, message.ErrRatingSubmissionNotFound, msg)
+}
+
+func TestCreateRatingSubmissionMpSpamFlagged(t *testing.T) {
+	viper.Set("spam.enabled", true)
+	viper.Set("spam.window-minutes", 60)
+	viper.Set("spam.duplicate-comments", 2)
+	viper.Set("spam.reviews-per-ip", 5)
+	viper.Set("spam.new-account-hours", 24)
+	defer func() {
+		for _, key := range []string{"spam.enabled", "spam.window-minutes", "spam.duplicate-comments", "spam.reviews-per-ip", "spam.new-account-hours"} {
+			viper.Set(key, nil)
+		}
+	}()
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	svc := service.NewRatingMpService(logger, repo)
+
+	userId := "34343433"
+	orderNumber := "888889"
+	createdAt := time.Now().Add(-time.Hour)
+	input := request.CreateRatingSubmissionRequest{
+		UserID:           &userId,
+		UserIDLegacy:     &userId,
+		DisplayName:      &name,
+		SourceTransID:    orderNumber,
+		SourceUID:        "Frtgffggffgft123",
+		RatingType:       "rating_for_product",
+		Value:            "5",
+		Comment:          "Mantap bagusss!!",
+		IPAddress:        "10.0.0.1",
+		StoreUID:         "1",
+		AccountCreatedAt: &createdAt,
+	}
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	ratingTypeID := entity.RatingTypesNumCol{ID: objectID}
+
+	repo.Mock.On("FindRatingSubmissionByOrderLine", orderNumber, "product", input.SourceUID, userId).Return(nil, gorm.ErrRecordNotFound)
+	repo.Mock.On("GetReviewInvitationByOrderLine", orderNumber, userId, "product", input.SourceUID).Return(nil, mongo.ErrNoDocuments)
+	repo.Mock.On("FindRatingTypeNumByRatingType", input.RatingType).Return(&ratingTypeID, nil)
+	repo.Mock.On("CountRatingSubmissionsSince", "comment_search", "mantap bagus", mock.Anything).Return(int64(1), nil).Once()
+	repo.Mock.On("CountRatingSubmissionsSince", "ip_address", "10.0.0.1", mock.Anything).Return(int64(1), nil).Once()
+	repo.Mock.On("CreateRatingSubmission", mock.MatchedBy(func(subs []entity.RatingSubmissionMp) bool {
+		return len(subs) == 1 && subs[0].ModerationStatus == entity.ModerationStatusPending &&
+			assert.ObjectsAreEqual([]string{entity.ModerationFlagSpamDuplicateComment, entity.ModerationFlagSpamNewAccount}, subs[0].ModerationFlags)
+	}), mock.Anything).Return(&[]entity.RatingSubmissionMp{{ID: objectID}}, nil).Once()
+	repo.Mock.On("ScheduleFinalRating", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	_, msg := svc.CreateRatingSubmissionMp(context.Background(), input)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	repo.AssertExpectations(t)
+}
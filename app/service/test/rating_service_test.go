@@ -6,6 +6,8 @@ import (
 	"go-klikdokter/app/model/base"
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/model/response"
+	publicresponse "go-klikdokter/app/model/response/public"
 	"go-klikdokter/app/repository/public/public_repository_mock"
 	"go-klikdokter/app/repository/repository_mock"
 	"go-klikdokter/app/service"
@@ -32,7 +34,7 @@ var ratingRepository = &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
 var medicalFacility = &mocks.MedicalFacilitySvc{Mock: mock.Mock{}}
 var publicRatingRepository = &public_repository_mock.PublicRatingRepositoryMock{Mock: mock.Mock{}}
 var publicRatingService = publicservice.NewPublicRatingService(logger, ratingRepository, publicRatingRepository)
-var svc = service.NewRatingService(logger, ratingRepository, publicRatingRepository, medicalFacility)
+var svc = service.NewRatingService(logger, ratingRepository, publicRatingRepository, medicalFacility, ratingMpRepository)
 
 func init() {
 	{
@@ -61,6 +63,7 @@ var (
 	callMFSuccess = "CallGetDetailMedicalFacilitySuccess"
 	callMFFailed  = "CallGetDetailMedicalFacilityFailed"
 	e             = errors.New("error")
+	deletedBy     = "Test"
 )
 
 var jwtObj = global.JWTObj{
@@ -436,7 +439,7 @@ func TestDeleteRatingTypeNumById(t *testing.T) {
 	req := request.GetRatingTypeNumRequest{Id: "629ec07e6f3c2761ba2dc867"}
 
 	ratingRepository.Mock.On("GetRatingByType", req.Id).Return(nil)
-	msg := svc.DeleteRatingTypeNumById(req)
+	msg := svc.DeleteRatingTypeNumById(req, deletedBy)
 	assert.Equal(t, message.SuccessMsg, msg)
 }
 
@@ -444,7 +447,7 @@ func TestDeleteRatingTypeNumByIdErrNoData(t *testing.T) {
 	req := request.GetRatingTypeNumRequest{Id: "q324"}
 
 	ratingRepository.Mock.On("GetRatingByType", req.Id).Return(nil)
-	msg := svc.DeleteRatingTypeNumById(req)
+	msg := svc.DeleteRatingTypeNumById(req, deletedBy)
 	assert.Equal(t, message.ErrNoData, msg)
 }
 
@@ -457,7 +460,7 @@ func TestDeleteRatingTypeNumByIdFailed(t *testing.T) {
 		Name: "abc",
 	}
 	ratingRepository.Mock.On("GetRatingByType", req.Id).Return(rating)
-	msg := svc.DeleteRatingTypeNumById(req)
+	msg := svc.DeleteRatingTypeNumById(req, deletedBy)
 	assert.Equal(t, message.FailedMsg, msg)
 }
 
@@ -476,7 +479,7 @@ func TestDeleteRatingTypeNumByIdErrThisRatingTypeIsInUse(t *testing.T) {
 	}
 	ratingRepository.Mock.On("GetRatingByType", req.Id).Return(rating)
 	ratingRepository.Mock.On("GetRatingSubmissionByRatingId", rating.ID).Return(submissison)
-	msg := svc.DeleteRatingTypeNumById(req)
+	msg := svc.DeleteRatingTypeNumById(req, deletedBy)
 	assert.Equal(t, message.ErrThisRatingTypeIsInUse, msg)
 }
 
@@ -550,9 +553,9 @@ func TestGetRatingSubmissionWrongID(t *testing.T) {
 func TestDeleteRatingSubmissionSuccess(t *testing.T) {
 	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
 
-	ratingRepository.Mock.On("DeleteSubmission", objectId).Return(nil)
+	ratingRepository.Mock.On("DeleteSubmission", objectId, deletedBy).Return(nil)
 
-	msg := svc.DeleteRatingSubmission(id)
+	msg := svc.DeleteRatingSubmission(id, deletedBy)
 
 	assert.Equal(t, message.SuccessMsg, msg)
 }
@@ -561,9 +564,9 @@ func TestDeleteRatingSubmissionFail(t *testing.T) {
 	failId := "629dce7bf1f26275e0d84827"
 	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84827")
 
-	ratingRepository.Mock.On("DeleteSubmission", objectId).Return(errors.New("user not found"))
+	ratingRepository.Mock.On("DeleteSubmission", objectId, deletedBy).Return(mongo.ErrNoDocuments)
 
-	msg := svc.DeleteRatingSubmission(failId)
+	msg := svc.DeleteRatingSubmission(failId, deletedBy)
 
 	assert.Equal(t, message.ErrNoData, msg)
 }
@@ -572,9 +575,9 @@ func TestDeleteRatingSubmissionWrongId(t *testing.T) {
 	failId := "123456"
 	objectId, _ := primitive.ObjectIDFromHex("123456")
 
-	ratingRepository.Mock.On("DeleteSubmission", objectId).Return(nil)
+	ratingRepository.Mock.On("DeleteSubmission", objectId, deletedBy).Return(nil)
 
-	msg := svc.DeleteRatingSubmission(failId)
+	msg := svc.DeleteRatingSubmission(failId, deletedBy)
 
 	assert.Equal(t, message.ErrDataNotFound, msg)
 }
@@ -703,7 +706,7 @@ func TestCreateRatingSubmissionFailLikertType(t *testing.T) {
 
 	assert.Equal(t, message.Message{
 		Code:    message.ValidationFailCode,
-		Message: "value must be integer and include in [1]",
+		Message: "value must be a statement key or position and include in [1]",
 	}, msg)
 }
 
@@ -772,10 +775,12 @@ func TestUpdateRatingSubmissionSuccess(t *testing.T) {
 	likertID := "629dce7bf1f26275e0d84826"
 	objectId, _ := primitive.ObjectIDFromHex(id)
 	objectIdT, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84827")
+	// the admin edits any submission
 	input := request.UpdateRatingSubmissionRequest{
 		ID:       id,
 		Value:    &valueRate,
 		RatingID: id,
+		JWTObj:   global.JWTObj{Roles: []string{global.RoleAdmin}},
 	}
 
 	sub := entity.RatingSubmisson{
@@ -834,10 +839,12 @@ func TestUpdateRatingSubmissionSuccessUserID(t *testing.T) {
 	numericID := "629dce7bf1f26275e0d84826"
 	likertID := "629dce7bf1f26275e0d84826"
 	objectId, _ := primitive.ObjectIDFromHex(id)
+	// the admin edits any submission
 	input := request.UpdateRatingSubmissionRequest{
 		ID:       id,
 		Value:    &valueRate,
 		RatingID: id,
+		JWTObj:   global.JWTObj{Roles: []string{global.RoleAdmin}},
 	}
 
 	sub := entity.RatingSubmisson{
@@ -886,10 +893,12 @@ func TestUpdateRatingSubmissionSuccessUserIDLegacy(t *testing.T) {
 	numericID := "629dce7bf1f26275e0d84826"
 	likertID := "629dce7bf1f26275e0d84826"
 	objectId, _ := primitive.ObjectIDFromHex(id)
+	// the admin edits any submission
 	input := request.UpdateRatingSubmissionRequest{
 		ID:       id,
 		Value:    &valueRate,
 		RatingID: id,
+		JWTObj:   global.JWTObj{Roles: []string{global.RoleAdmin}},
 	}
 
 	sub := entity.RatingSubmisson{
@@ -1279,7 +1288,7 @@ func TestDeleteRatingTypeLikertById(t *testing.T) {
 	req := request.GetRatingTypeLikertRequest{
 		Id: "629ec07e6f3c2761ba2dc868",
 	}
-	msg := svc.DeleteRatingTypeLikertById(req)
+	msg := svc.DeleteRatingTypeLikertById(req, deletedBy)
 	assert.Equal(t, message.SuccessMsg, msg)
 }
 
@@ -1287,7 +1296,7 @@ func TestDeleteRatingTypeLikertByIdErrIdFormatReq(t *testing.T) {
 	req := request.GetRatingTypeLikertRequest{
 		Id: "21323",
 	}
-	msg := svc.DeleteRatingTypeLikertById(req)
+	msg := svc.DeleteRatingTypeLikertById(req, deletedBy)
 	assert.Equal(t, message.ErrNoData, msg)
 }
 
@@ -1300,7 +1309,7 @@ func TestDeleteRatingTypeLikertByIdFailedMsg(t *testing.T) {
 		Name: "abc",
 	}
 	ratingRepository.Mock.On("GetRatingByType", req.Id).Return(rating)
-	msg := svc.DeleteRatingTypeLikertById(req)
+	msg := svc.DeleteRatingTypeLikertById(req, deletedBy)
 	assert.Equal(t, message.FailedMsg, msg)
 }
 
@@ -1308,7 +1317,7 @@ func TestDeleteRatingTypeLikertByIdErrNoData(t *testing.T) {
 	req := request.GetRatingTypeLikertRequest{Id: "q324"}
 
 	ratingRepository.Mock.On("GetRatingByType", req.Id).Return(nil)
-	msg := svc.DeleteRatingTypeLikertById(req)
+	msg := svc.DeleteRatingTypeLikertById(req, deletedBy)
 	assert.Equal(t, message.ErrNoData, msg)
 }
 
@@ -1327,7 +1336,7 @@ func TestDeleteRatingTypeLikertByIdErrThisRatingTypeIsInUse(t *testing.T) {
 	}
 	ratingRepository.Mock.On("GetRatingByType", req.Id).Return(rating)
 	ratingRepository.Mock.On("GetRatingSubmissionByRatingId", rating.ID).Return(submissison)
-	msg := svc.DeleteRatingTypeLikertById(req)
+	msg := svc.DeleteRatingTypeLikertById(req, deletedBy)
 	assert.Equal(t, message.ErrThisRatingTypeIsInUse, msg)
 }
 
@@ -1948,9 +1957,9 @@ func TestDeleteRating(t *testing.T) {
 
 	ratingRepository.Mock.On("GetRatingById", ObjRatingId).Return(rating, nil)
 	ratingRepository.Mock.On("GetRatingSubmissionByRatingId", rId).Return(nil)
-	ratingRepository.Mock.On("DeleteRating", ObjRatingId).Return(nil)
+	ratingRepository.Mock.On("DeleteRating", ObjRatingId, deletedBy).Return(nil)
 
-	mgs := svc.DeleteRating(rId)
+	mgs := svc.DeleteRating(rId, deletedBy)
 	assert.Equal(t, message.SuccessMsg, mgs)
 }
 
@@ -1965,9 +1974,9 @@ func TestDeleteRatingFailed8(t *testing.T) {
 
 	ratingRepository.Mock.On("GetRatingById", ObjRatingId).Return(rating, nil)
 	ratingRepository.Mock.On("GetRatingSubmissionByRatingId", rId).Return(nil)
-	ratingRepository.Mock.On("DeleteRating", ObjRatingId).Return(e)
+	ratingRepository.Mock.On("DeleteRating", ObjRatingId, deletedBy).Return(e)
 
-	mgs := svc.DeleteRating(rId)
+	mgs := svc.DeleteRating(rId, deletedBy)
 	assert.Equal(t, message.FailedMsg, mgs)
 }
 
@@ -1977,14 +1986,14 @@ func TestDeleteRatingFailed1(t *testing.T) {
 	ObjRatingId, _ := primitive.ObjectIDFromHex(ratingIdFailed)
 
 	ratingRepository.Mock.On("GetRatingById", ObjRatingId).Return(nil, e)
-	mgs := svc.DeleteRating(ratingIdFailed)
+	mgs := svc.DeleteRating(ratingIdFailed, deletedBy)
 	assert.Equal(t, message.FailedMsg, mgs)
 }
 
 func TestDeleteRatingFailed2(t *testing.T) {
 	ratingIdFailed := "getRatingFailed"
 
-	mgs := svc.DeleteRating(ratingIdFailed)
+	mgs := svc.DeleteRating(ratingIdFailed, deletedBy)
 	assert.Equal(t, message.ErrDataNotFound, mgs)
 }
 
@@ -1994,7 +2003,7 @@ func TestDeleteRatingFailed3(t *testing.T) {
 	ObjRatingId, _ := primitive.ObjectIDFromHex(ratingIdFailed)
 
 	ratingRepository.Mock.On("GetRatingById", ObjRatingId).Return(nil, mongo.ErrNoDocuments)
-	mgs := svc.DeleteRating(ratingIdFailed)
+	mgs := svc.DeleteRating(ratingIdFailed, deletedBy)
 	assert.Equal(t, message.ErrDataNotFound, mgs)
 }
 
@@ -2013,7 +2022,7 @@ func TestDeleteRatingFailed4(t *testing.T) {
 
 	ratingRepository.Mock.On("GetRatingById", ObjRatingId).Return(rating, nil)
 	ratingRepository.Mock.On("GetRatingSubmissionByRatingId", rId).Return(ratingSubmission)
-	mgs := svc.DeleteRating(rId)
+	mgs := svc.DeleteRating(rId, deletedBy)
 	assert.Equal(t, message.ErrRatingHasRatingSubmission, mgs)
 }
 
@@ -2031,7 +2040,7 @@ func TestDeleteRatingFailed5(t *testing.T) {
 
 	ratingRepository.Mock.On("GetRatingById", ObjRatingId).Return(rating, nil)
 	ratingRepository.Mock.On("GetRatingSubmissionByRatingId", "629dce7bf1f26275e0d84826").Return(ratingSubmission)
-	mgs := svc.DeleteRating("629dce7bf1f26275e0d84826")
+	mgs := svc.DeleteRating("629dce7bf1f26275e0d84826", deletedBy)
 	assert.Equal(t, message.FailedMsg, mgs)
 }
 
@@ -2074,16 +2083,20 @@ func TestGetListRatingSummary(t *testing.T) {
 		Limit:  0,
 		Filter: "{\"source_uid\": [\"2729\", \"2951\"],\"score\":[0,5]}",
 	}
-	result := []entity.RatingSubmisson{
-		{
-			RatingID: "629ec07e6f3c2761ba2dc468",
-			Comment:  &Desc,
-			Value:    value,
+	aggregates := map[string]entity.RatingAggregateCol{
+		"629ec07e6f3c2761ba2dc468": {
+			SourceUID: "2951",
+			RatingID:  "629ec07e6f3c2761ba2dc468",
+			Sum:       9,
+			Count:     2,
+			Histogram: map[string]int64{"4": 1, "5": 1},
 		},
-		{
-			RatingID: "629ec07e6f3c2761ba2dc848",
-			Comment:  &Desc,
-			Value:    value,
+		"629ec07e6f3c2761ba2dc848": {
+			SourceUID: "2729",
+			RatingID:  "629ec07e6f3c2761ba2dc848",
+			Sum:       4,
+			Count:     1,
+			Histogram: map[string]int64{"4": 1},
 		},
 	}
 	objectId1, _ := primitive.ObjectIDFromHex("629ec07e6f3c2761ba2dc468")
@@ -2107,9 +2120,13 @@ func TestGetListRatingSummary(t *testing.T) {
 		TotalPage: 1,
 	}
 	ratingRepository.Mock.On("GetRatingsByParams", request.RatingFilter{SourceUid: []string{"2729", "2951"}, RatingTypeId: []string(nil)}, 1, 50, "updated_at", -1).Return(result2, &paginationResult, nil)
-	ratingRepository.Mock.On("GetListRatingSubmissions", request.RatingSubmissionFilter{UserIDLegacy: []string(nil), Score: []float64{0, 5}, RatingID: []string{"629ec07e6f3c2761ba2dc468", "629ec07e6f3c2761ba2dc848"}, StartDate: "", EndDate: ""}, 1, int64(50), "updated_at", -1).Return(result, &paginationResult, nil)
-	_, msg := svc.GetListRatingSummary(req)
+	ratingRepository.Mock.On("GetRatingAggregates", result2).Return(aggregates, nil)
+	result, msg := svc.GetListRatingSummary(req)
 	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, []response.RatingSummaryResponse{
+		{SourceUID: "2729", TotalReview: 1, Value: 4},
+		{SourceUID: "2951", TotalReview: 2, Value: 4.5},
+	}, result)
 }
 
 func TestGetListRatingSubmissionErrWrongFilter(t *testing.T) {
@@ -2246,7 +2263,7 @@ func TestGetListRatingSummaryWrongFilter(t *testing.T) {
 		Dir:    "desc",
 		Page:   0,
 		Limit:  0,
-		Filter: "{\"source_uid\": [\"2729\", \"2951\"]}",
+		Filter: "{\"source_uid\": [\"2729\", \"2951\"], \"start_date\": \"2022-06-01\"}",
 	}
 
 	objectId1, _ := primitive.ObjectIDFromHex("629ec07e6f3c2761ba2dc468")
@@ -2270,7 +2287,7 @@ func TestGetListRatingSummaryWrongFilter(t *testing.T) {
 		TotalPage: 1,
 	}
 	ratingRepository.Mock.On("GetRatingsByParams", request.RatingFilter{SourceUid: []string{"2729", "2951"}, RatingTypeId: []string(nil)}, 1, 50, "wrong filter", -1).Return(result2, &paginationResult, nil)
-	ratingRepository.Mock.On("GetListRatingSubmissions", request.RatingSubmissionFilter{UserIDLegacy: []string(nil), Score: []float64(nil), RatingID: []string{"629ec07e6f3c2761ba2dc468", "629ec07e6f3c2761ba2dc848"}, StartDate: "", EndDate: ""}, 1, int64(50), "wrong filter", -1).Return(nil, &paginationResult, gorm.ErrInvalidDB)
+	ratingRepository.Mock.On("GetListRatingSubmissions", request.RatingSubmissionFilter{UserIDLegacy: []string(nil), Score: []float64(nil), RatingID: []string{"629ec07e6f3c2761ba2dc468", "629ec07e6f3c2761ba2dc848"}, StartDate: "2022-06-01", EndDate: ""}, 1, int64(50), "wrong filter", -1).Return(nil, &paginationResult, gorm.ErrInvalidDB)
 	_, msg := svc.GetListRatingSummary(req)
 	assert.Equal(t, message.WrongFilter, msg)
 }
@@ -2329,16 +2346,20 @@ func TestGetListRatingSummaryNoScoreFilter(t *testing.T) {
 		Limit:  0,
 		Filter: "{\"source_uid\": [\"2729\", \"2951\"]}",
 	}
-	result := []entity.RatingSubmisson{
-		{
-			RatingID: "629ec07e6f3c2761ba2dc468",
-			Comment:  &Desc,
-			Value:    value,
+	aggregates := map[string]entity.RatingAggregateCol{
+		"629ec07e6f3c2761ba2dc468": {
+			SourceUID: "2951",
+			RatingID:  "629ec07e6f3c2761ba2dc468",
+			Sum:       9,
+			Count:     2,
+			Histogram: map[string]int64{"4": 1, "5": 1},
 		},
-		{
-			RatingID: "629ec07e6f3c2761ba2dc848",
-			Comment:  &Desc,
-			Value:    value,
+		"629ec07e6f3c2761ba2dc848": {
+			SourceUID: "2729",
+			RatingID:  "629ec07e6f3c2761ba2dc848",
+			Sum:       4,
+			Count:     1,
+			Histogram: map[string]int64{"4": 1},
 		},
 	}
 	objectId1, _ := primitive.ObjectIDFromHex("629ec07e6f3c2761ba2dc468")
@@ -2362,7 +2383,7 @@ func TestGetListRatingSummaryNoScoreFilter(t *testing.T) {
 		TotalPage: 1,
 	}
 	ratingRepository.Mock.On("GetRatingsByParams", request.RatingFilter{SourceUid: []string{"2729", "2951"}, RatingTypeId: []string(nil)}, 1, 50, "updated_at", 1).Return(result2, &paginationResult, nil)
-	ratingRepository.Mock.On("GetListRatingSubmissions", request.RatingSubmissionFilter{UserIDLegacy: []string(nil), Score: []float64(nil), RatingID: []string{"629ec07e6f3c2761ba2dc468", "629ec07e6f3c2761ba2dc848"}, StartDate: "", EndDate: ""}, 1, int64(50), "updated_at", 1).Return(result, &paginationResult, nil)
+	ratingRepository.Mock.On("GetRatingAggregates", result2).Return(aggregates, nil)
 	_, msg := svc.GetListRatingSummary(req)
 	assert.Equal(t, message.SuccessMsg, msg)
 }
@@ -2426,11 +2447,32 @@ func TestCancelRatingSubmissionSuccess(t *testing.T) {
 	}
 
 	ratingRepository.Mock.On("CancelRatingSubmissionByIds", ids, input.CancelledReason).Return(nil)
+	ratingMpRepository.Mock.On("GetRatingSubmissionsByIds", ids).Return([]entity.RatingSubmissionMp{}, nil).Once()
 	msg := svc.CancelRatingSubmission(input)
 
 	assert.Equal(t, message.SuccessMsg, msg)
 }
 
+func TestCancelRatingSubmissionMpSchedulesFinalRating(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	mpRepo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("630dca3fc27e5483bdc006ee")
+	ids := []primitive.ObjectID{objectId}
+	submission := entity.RatingSubmissionMp{ID: objectId, SourceType: "product", SourceUID: "product-1", StoreUID: "store-1"}
+
+	mpRepo.Mock.On("GetRatingSubmissionsByIds", ids).Return([]entity.RatingSubmissionMp{submission}, nil).Once()
+	mpRepo.Mock.On("CancelRatingSubmissionByIds", ids, "fraud").Return(nil).Once()
+	mpRepo.Mock.On("ScheduleFinalRating", "product", "product-1", mock.Anything).Return(nil).Once()
+
+	msg := service.NewRatingService(logger, repo, publicRatingRepository, medicalFacility, mpRepo).CancelRatingSubmission(request.CancelRatingById{
+		RatingSubmissionId: []string{objectId.Hex()},
+		CancelledReason:    "fraud",
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	mpRepo.AssertExpectations(t)
+}
+
 func TestCancelRatingSubmissionFailed(t *testing.T) {
 	ids := []primitive.ObjectID{}
 	input := request.CancelRatingById{
@@ -2448,3 +2490,254 @@ func TestCancelRatingSubmissionFailed(t *testing.T) {
 
 	assert.Equal(t, message.ErrSaveData, msg)
 }
+
+func TestModerateRatingSubmissionApprove(t *testing.T) {
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84840")
+	ratingRepository.Mock.On("UpdateModerationRatingSubmission", objectId, entity.ModerationStatusApproved, "", "Admin").Return(nil)
+
+	msg := svc.ModerateRatingSubmission(request.ModerateRatingSubmissionRequest{
+		ID:     objectId.Hex(),
+		Status: entity.ModerationStatusApproved,
+		JWTObj: global.JWTObj{Fullname: "Admin"},
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestModerateRatingSubmissionNotFound(t *testing.T) {
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84841")
+	ratingRepository.Mock.On("UpdateModerationRatingSubmission", objectId, entity.ModerationStatusRejected, "spam", "Admin").Return(mongo.ErrNoDocuments)
+
+	msg := svc.ModerateRatingSubmission(request.ModerateRatingSubmissionRequest{
+		ID:     objectId.Hex(),
+		Status: entity.ModerationStatusRejected,
+		Reason: "spam",
+		JWTObj: global.JWTObj{Fullname: "Admin"},
+	})
+
+	assert.Equal(t, message.RatingSubmissionNotFound, msg)
+}
+
+func TestRebuildRatingAggregates(t *testing.T) {
+	objectId1, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3701")
+	objectId2, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3702")
+	page1 := []entity.RatingsCol{{ID: objectId1, SourceType: "rebuild", SourceUid: "1001"}}
+	page2 := []entity.RatingsCol{{ID: objectId2, SourceType: "rebuild", SourceUid: "1002"}}
+	paginationResult := base.Pagination{
+		Records:      1,
+		TotalRecords: 2,
+		Limit:        100,
+		TotalPage:    2,
+	}
+	filter := request.RatingFilter{SourceType: "rebuild"}
+	ratingRepository.Mock.On("GetRatingsByParams", filter, 1, 100, "_id", 1).Return(page1, &paginationResult, nil).Once()
+	ratingRepository.Mock.On("GetRatingsByParams", filter, 2, 100, "_id", 1).Return(page2, &paginationResult, nil).Once()
+	ratingRepository.Mock.On("RebuildRatingAggregate", page1[0]).Return(&entity.RatingAggregateCol{RatingID: objectId1.Hex()}, nil).Once()
+	ratingRepository.Mock.On("RebuildRatingAggregate", page2[0]).Return(&entity.RatingAggregateCol{RatingID: objectId2.Hex()}, nil).Once()
+
+	total, msg := svc.RebuildRatingAggregates("rebuild")
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 2, total, "Every rating must be rebuilt")
+}
+
+func TestRebuildRatingAggregatesFailed(t *testing.T) {
+	objectId, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3703")
+	ratings := []entity.RatingsCol{{ID: objectId, SourceType: "rebuild-failed", SourceUid: "1003"}}
+	paginationResult := base.Pagination{
+		Records:   1,
+		Limit:     100,
+		TotalPage: 1,
+	}
+	ratingRepository.Mock.On("GetRatingsByParams", request.RatingFilter{SourceType: "rebuild-failed"}, 1, 100, "_id", 1).Return(ratings, &paginationResult, nil).Once()
+	ratingRepository.Mock.On("RebuildRatingAggregate", ratings[0]).Return(nil, errors.New("error")).Once()
+
+	total, msg := svc.RebuildRatingAggregates("rebuild-failed")
+	assert.Equal(t, message.FailedMsg, msg)
+	assert.Equal(t, 0, total)
+}
+
+func TestCreateRatingFormulaInvalidFormula(t *testing.T) {
+	input := request.SaveRatingFormula{
+		SourceType:   "doctor",
+		Formula:      "(sum + unknown_point) / count",
+		RatingTypeId: id,
+	}
+
+	result, msg := svc.CreateRatingFormula(input)
+	assert.Nil(t, result)
+	assert.Equal(t, message.ErrInvalidFormula.Code, msg.Code)
+	assert.Equal(t, message.ErrInvalidFormula.Message+": unknown variable unknown_point", msg.Message)
+}
+
+func TestUpdateRatingFormulaInvalidFormula(t *testing.T) {
+	input := request.SaveRatingFormula{
+		Id:      "629dce7bf1f26275e0d84826",
+		Formula: "sum / (count - 1)",
+	}
+
+	msg := svc.UpdateRatingFormula(input)
+	assert.Equal(t, message.ErrInvalidFormula.Code, msg.Code, "Formula dividing by zero for a single review must be rejected")
+}
+
+func TestCreateRatingFormulaBayes(t *testing.T) {
+	input := request.SaveRatingFormula{
+		SourceType:   "doctor-bayes",
+		Formula:      "bayes(global_mean, 10) * 0.8 + wilson_lower_bound(hist_4 + hist_5, count) * 5 * 0.2",
+		RatingTypeId: id,
+	}
+	result, msg := svc.CreateRatingFormula(input)
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, "629dce7bf1f26275e0d84826", result.ID.Hex())
+}
+
+func TestPreviewRatingFormula(t *testing.T) {
+	formulaId, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3801")
+	ratingId1, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3802")
+	ratingId2, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3803")
+	formula := entity.RatingFormulaCol{
+		ID:           formulaId,
+		SourceType:   "doctor-preview",
+		Formula:      "sum / count",
+		RatingTypeId: ratingTypeId,
+		Version:      2,
+	}
+	ratings := []entity.RatingsCol{
+		{ID: ratingId1, SourceType: "doctor-preview", SourceUid: "2001", RatingTypeId: ratingTypeId},
+		{ID: ratingId2, SourceType: "doctor-preview", SourceUid: "2002", RatingTypeId: ratingTypeId},
+	}
+	aggregates := map[string]entity.RatingAggregateCol{
+		ratingId1.Hex(): {RatingID: ratingId1.Hex(), Sum: 5, Count: 1},
+		ratingId2.Hex(): {RatingID: ratingId2.Hex(), Sum: 45, Count: 10},
+	}
+	filter := request.RatingFilter{
+		SourceUid:    []string{"2001", "2002", "2003"},
+		RatingTypeId: []string{ratingTypeId},
+		SourceType:   "doctor-preview",
+	}
+	ratingRepository.Mock.On("GetRatingFormulaById", formulaId).Return(formula).Once()
+	ratingRepository.Mock.On("GetRatingsByParams", filter, 1, 3, "_id", 1).Return(ratings, &base.Pagination{}, nil).Once()
+	ratingRepository.Mock.On("GetRatingAggregates", ratings).Return(aggregates, nil).Once()
+
+	result, msg := svc.PreviewRatingFormula(request.PreviewRatingFormulaRequest{
+		FormulaId:  formulaId.Hex(),
+		Formula:    "bayes(4, 10)",
+		SourceUids: []string{"2001", "2002", "2003"},
+	})
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 2, result.CurrentVersion)
+	assert.Equal(t, []string{"2003"}, result.MissingSourceUids, "Source without a rating must be reported")
+	assert.Equal(t, 2, len(result.Items))
+	// a single 5 star review no longer outranks ten reviews averaging 4.5
+	assert.Equal(t, "2002", result.Items[0].SourceUid)
+	assert.Equal(t, 2, result.Items[0].CurrentRank)
+	assert.Equal(t, 1, result.Items[0].CandidateRank)
+	assert.Equal(t, 1, result.Items[0].RankChange)
+	assert.Equal(t, 4.25, result.Items[0].CandidateScore)
+	assert.Equal(t, "2001", result.Items[1].SourceUid)
+	assert.Equal(t, float64(5), result.Items[1].CurrentScore)
+	assert.Equal(t, 5, result.Items[1].CurrentTotalValue)
+	assert.Equal(t, -1, result.Items[1].RankChange)
+}
+
+func TestPreviewRatingFormulaMarketplace(t *testing.T) {
+	mpRepo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	formulaId, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3807")
+	mpRepo.Mock.On("GetRatingFormulaBySourceType", "product").
+		Return(&entity.RatingFormulaCol{ID: formulaId, SourceType: "product", Formula: "sum / count", Version: 1}, nil).Once()
+	mpRepo.Mock.On("GetRatingSubsGroupByValue", "product-1", "product").
+		Return([]publicresponse.PublicRatingSubGroupByValue{{ConvertedValue: 5, Total: 1}}, nil).Once()
+	mpRepo.Mock.On("GetRatingSubsGroupByValue", "product-2", "product").
+		Return([]publicresponse.PublicRatingSubGroupByValue{{ConvertedValue: 5, Total: 6}, {ConvertedValue: 4, Total: 4}}, nil).Once()
+	mpRepo.Mock.On("GetRatingSubsGroupByValue", "product-3", "product").Return([]publicresponse.PublicRatingSubGroupByValue{}, nil).Once()
+	mpRepo.Mock.On("GetGlobalSumCountBySourceType", "product", "").Return(float64(400), int64(100), nil).Twice()
+
+	// the legacy repository has no expectation, reading the legacy ratings fails the test
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	result, msg := service.NewRatingService(logger, repo, publicRatingRepository, medicalFacility, mpRepo).
+		PreviewRatingFormula(request.PreviewRatingFormulaRequest{
+			SourceType: "product",
+			Formula:    "bayes(global_mean, 10)",
+			SourceUids: []string{"product-1", "product-2", "product-3"},
+		})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, formulaId.Hex(), result.FormulaId)
+	assert.Equal(t, []string{"product-3"}, result.MissingSourceUids)
+	assert.Equal(t, 2, len(result.Items))
+	assert.Equal(t, "product-2", result.Items[0].SourceUid)
+	assert.Equal(t, int64(10), result.Items[0].TotalReviewer)
+	assert.Equal(t, 4.6, result.Items[0].CurrentScore)
+	assert.Equal(t, 4.3, result.Items[0].CandidateScore)
+	assert.Equal(t, 1, result.Items[0].RankChange)
+	assert.Equal(t, "", result.Items[0].RatingId)
+	mpRepo.AssertExpectations(t)
+}
+
+func TestPreviewRatingFormulaInvalidFormula(t *testing.T) {
+	formulaId, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3804")
+	ratingRepository.Mock.On("GetRatingFormulaById", formulaId).Return(entity.RatingFormulaCol{ID: formulaId, Formula: "sum / count"}).Once()
+
+	result, msg := svc.PreviewRatingFormula(request.PreviewRatingFormulaRequest{
+		FormulaId:  formulaId.Hex(),
+		Formula:    "sum / unknown_variable",
+		SourceUids: []string{"2001"},
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, message.ErrInvalidFormula.Code, msg.Code)
+}
+
+func TestGetRatingFormulaVersions(t *testing.T) {
+	formulaId, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3805")
+	versions := []entity.RatingFormulaVersionCol{
+		{FormulaId: formulaId.Hex(), Version: 2, Formula: "bayes(4, 10)"},
+		{FormulaId: formulaId.Hex(), Version: 1, Formula: "sum / count"},
+	}
+	ratingRepository.Mock.On("GetRatingFormulaById", formulaId).Return(entity.RatingFormulaCol{ID: formulaId, Version: 1}).Once()
+	ratingRepository.Mock.On("GetRatingFormulaVersions", formulaId.Hex(), 1, int64(50)).Return(versions, &base.Pagination{}, nil).Once()
+
+	result, _, msg := svc.GetRatingFormulaVersions(request.GetRatingFormulaVersionsRequest{Id: formulaId.Hex()})
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.False(t, result[0].IsActive)
+	assert.True(t, result[1].IsActive, "Rolled back version must be the active one")
+}
+
+func TestCreateRatingFormulaVersion(t *testing.T) {
+	formulaId, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3806")
+	formula := entity.RatingFormulaCol{ID: formulaId, SourceType: "doctor", Formula: "sum / count", RatingTypeId: ratingTypeId, Version: 1}
+	draft := formula
+	draft.Formula = "bayes(global_mean, 10)"
+	ratingRepository.Mock.On("GetRatingFormulaById", formulaId).Return(formula).Once()
+	ratingRepository.Mock.On("CreateRatingFormulaVersion", draft, "bayesian average", "Admin").
+		Return(&entity.RatingFormulaVersionCol{FormulaId: formulaId.Hex(), Version: 2, Formula: draft.Formula}, nil).Once()
+
+	result, msg := svc.CreateRatingFormulaVersion(request.CreateRatingFormulaVersionRequest{
+		Id:      formulaId.Hex(),
+		Formula: "bayes(global_mean, 10)",
+		Note:    " bayesian average ",
+		JWTObj:  global.JWTObj{Fullname: "Admin"},
+	})
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 2, result.Version)
+}
+
+func TestActivateRatingFormulaVersion(t *testing.T) {
+	formulaId, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3807")
+	ratingRepository.Mock.On("GetRatingFormulaVersion", formulaId.Hex(), 1).
+		Return(&entity.RatingFormulaVersionCol{FormulaId: formulaId.Hex(), Version: 1, Formula: "sum / count"}, nil).Once()
+	ratingRepository.Mock.On("ActivateRatingFormulaVersion", formulaId, 1, "Admin").Return(nil).Once()
+
+	msg := svc.ActivateRatingFormulaVersion(request.ActivateRatingFormulaVersionRequest{
+		Id:      formulaId.Hex(),
+		Version: 1,
+		JWTObj:  global.JWTObj{Fullname: "Admin"},
+	})
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestActivateRatingFormulaVersionNotFound(t *testing.T) {
+	formulaId, _ := primitive.ObjectIDFromHex("62c3e57b457ed515928c3808")
+	ratingRepository.Mock.On("GetRatingFormulaVersion", formulaId.Hex(), 9).Return(nil, mongo.ErrNoDocuments).Once()
+
+	msg := svc.ActivateRatingFormulaVersion(request.ActivateRatingFormulaVersionRequest{Id: formulaId.Hex(), Version: 9})
+	assert.Equal(t, message.ErrNoData, msg)
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/message"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newReportSvc() (service.RatingSubReportService, *repository_mock.RatingRepositoryMock, *repository_mock.RatingMpRepository) {
+	rating := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	ratingMp := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	return service.NewRatingSubReportService(logger, rating, ratingMp), rating, ratingMp
+}
+
+func TestReportRatingSubmissionMpHidden(t *testing.T) {
+	viper.Set("report.auto-hide-threshold", 3)
+	defer viper.Set("report.auto-hide-threshold", nil)
+	reportSvc, _, ratingMp := newReportSvc()
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	report := entity.RatingSubReportCol{
+		RatingSubmissionID: objectId.Hex(),
+		Source:             entity.RatingSubReportSourceMp,
+		UserIDLegacy:       "34343432",
+		ReasonCode:         entity.ReportReasonSpam,
+		Reason:             "jualan",
+	}
+
+	ratingMp.Mock.On("ReportRatingSubmission", report, 3).Return(true, nil).Once()
+	ratingMp.Mock.On("GetRatingSubmissionById", objectId).Return(&entity.RatingSubmissionMp{ID: objectId, SourceType: "product", SourceUID: "product-1"}, nil).Once()
+	ratingMp.Mock.On("ScheduleFinalRating", "product", "product-1", mock.Anything).Return(nil).Once()
+
+	msg := reportSvc.ReportRatingSubmission(request.ReportRatingSubmissionRequest{
+		ID:           objectId.Hex(),
+		Source:       "mp",
+		ReasonCode:   entity.ReportReasonSpam,
+		Reason:       "jualan",
+		UserIDLegacy: "34343432",
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	ratingMp.AssertExpectations(t)
+}
+
+func TestReportRatingSubmissionAlreadyReported(t *testing.T) {
+	reportSvc, rating, _ := newReportSvc()
+	duplicate := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}
+
+	// the default threshold without config
+	rating.Mock.On("ReportRatingSubmission", mock.Anything, 5).Return(false, duplicate).Once()
+
+	msg := reportSvc.ReportRatingSubmission(request.ReportRatingSubmissionRequest{
+		ID:           "629dce7bf1f26275e0d84826",
+		ReasonCode:   entity.ReportReasonFake,
+		UserIDLegacy: "34343432",
+	})
+
+	assert.Equal(t, message.ErrRatingSubReportExists, msg)
+}
+
+func TestReportRatingSubmissionNotFound(t *testing.T) {
+	reportSvc, rating, ratingMp := newReportSvc()
+
+	rating.Mock.On("ReportRatingSubmission", mock.Anything, mock.Anything).Return(false, mongo.ErrNoDocuments).Once()
+
+	msg := reportSvc.ReportRatingSubmission(request.ReportRatingSubmissionRequest{
+		ID:           "xxx",
+		ReasonCode:   entity.ReportReasonOffensive,
+		UserIDLegacy: "34343432",
+	})
+
+	assert.Equal(t, message.ErrRatingSubmissionNotFound, msg)
+	ratingMp.AssertNotCalled(t, "ReportRatingSubmission", mock.Anything, mock.Anything)
+}
+
+func TestReportRatingSubmissionRequestValidate(t *testing.T) {
+	assert.Nil(t, request.ReportRatingSubmissionRequest{ID: "1", ReasonCode: entity.ReportReasonOther}.Validate())
+	assert.NotNil(t, request.ReportRatingSubmissionRequest{ID: "1", ReasonCode: "boring"}.Validate())
+	assert.NotNil(t, request.ReportRatingSubmissionRequest{ID: "1"}.Validate())
+}
+
+func TestGetListRatingSubReports(t *testing.T) {
+	reportSvc, _, ratingMp := newReportSvc()
+	comment := "Barang palsu"
+	reportedAt := time.Now()
+	groups := []entity.RatingSubReportGroup{{
+		RatingSubmissionID: "629dce7bf1f26275e0d84826",
+		TotalReport:        3,
+		LastReportedAt:     reportedAt,
+		Comment:            &comment,
+		ModerationStatus:   entity.ModerationStatusPending,
+		Reports: []entity.RatingSubReportCol{
+			{UserIDLegacy: "1", ReasonCode: entity.ReportReasonSpam, CreatedAt: reportedAt},
+			{UserIDLegacy: "2", ReasonCode: entity.ReportReasonFake},
+			{UserIDLegacy: "3", ReasonCode: entity.ReportReasonSpam},
+		},
+	}}
+
+	ratingMp.Mock.On("GetRatingSubReportGroups", 1, int64(50)).Return(groups, &base.Pagination{Page: 1}, nil).Once()
+
+	results, _, msg := reportSvc.GetListRatingSubReports(request.ListRatingSubReportsRequest{Source: "mp"})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "mp", results[0].Source)
+	assert.Equal(t, comment, results[0].Comment)
+	assert.Equal(t, map[string]int{entity.ReportReasonSpam: 2, entity.ReportReasonFake: 1}, results[0].ReasonCodes)
+	assert.Len(t, results[0].Reports, 3)
+}
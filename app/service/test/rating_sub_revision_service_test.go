@@ -0,0 +1,174 @@
+package test
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func setEditLimits(maxCount, windowDays int) func() {
+	viper.Set("edit.max-count", maxCount)
+	viper.Set("edit.window-days.default", windowDays)
+	return func() {
+		viper.Set("edit.max-count", nil)
+		viper.Set("edit.window-days.default", nil)
+	}
+}
+
+func TestUpdateRatingSubmissionMpKeepsRevision(t *testing.T) {
+	defer setEditLimits(3, 30)()
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	svc := service.NewRatingMpService(logger, repo)
+
+	userId := "34343432"
+	value := "5"
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84841")
+	oldComment := "jelek"
+	repo.Mock.On("GetRatingSubmissionById", objectId).Return(&entity.RatingSubmissionMp{
+		ID: objectId, UserID: &userId, UserIDLegacy: &userId, SourceType: "product", SourceUID: "product-1",
+		Value: 1, Comment: &oldComment, EditCounter: 1, CreatedAt: time.Now().Add(-24 * time.Hour),
+	}, nil)
+	repo.Mock.On("EditRatingSubmission", mock.MatchedBy(func(sub entity.RatingSubmissionMp) bool {
+		return sub.Value == 5 && *sub.Comment == "bagus" && sub.EditCounter == 2 && sub.EditedAt != nil && sub.EditedBy == userId
+	}), objectId, []entity.OutboxCol(nil)).Return(nil).Once()
+	repo.Mock.On("ScheduleFinalRating", "product", "product-1", mock.Anything).Return(nil).Once()
+
+	msg := svc.UpdateRatingSubmission(context.Background(), request.UpdateRatingSubmissionRequest{
+		ID: objectId.Hex(), UserID: &userId, UserIDLegacy: &userId, Value: &value, Comment: "bagus",
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateRatingSubmissionMpQueuesMediaHouseKeeping(t *testing.T) {
+	defer setEditLimits(3, 30)()
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	svc := service.NewRatingMpService(logger, repo)
+
+	userId := "34343432"
+	value := "5"
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84848")
+	repo.Mock.On("GetRatingSubmissionById", objectId).Return(&entity.RatingSubmissionMp{
+		ID: objectId, UserID: &userId, UserIDLegacy: &userId, SourceType: "product", SourceUID: "product-1", Value: 5, CreatedAt: time.Now(),
+	}, nil)
+	// the media are kept by media-svc through the outbox, in the transaction of the edit
+	repo.Mock.On("EditRatingSubmission", mock.Anything, objectId, []entity.OutboxCol{
+		{Target: entity.OutboxTargetMediaHouseKeeping, Payload: entity.OutboxPayload{MediaUID: "media-1"}},
+		{Target: entity.OutboxTargetMediaHouseKeeping, Payload: entity.OutboxPayload{MediaUID: "media-2"}},
+	}).Return(nil).Once()
+	repo.Mock.On("ScheduleFinalRating", "product", "product-1", mock.Anything).Return(nil)
+
+	msg := svc.UpdateRatingSubmission(context.Background(), request.UpdateRatingSubmissionRequest{
+		ID: objectId.Hex(), UserID: &userId, UserIDLegacy: &userId, Value: &value, Comment: "bagus",
+		Media: []entity.MediaObj{{UID: "media-1", MediaPath: "path/1.jpg"}, {UID: "media-2", MediaPath: "path/2.jpg"}, {UID: "no-path"}},
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateRatingSubmissionMpEditLimits(t *testing.T) {
+	defer setEditLimits(2, 30)()
+	viper.Set("edit.window-days.store", 7)
+	defer viper.Set("edit.window-days.store", nil)
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	svc := service.NewRatingMpService(logger, repo)
+
+	userId := "34343432"
+	value := "5"
+	limitId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84842")
+	repo.Mock.On("GetRatingSubmissionById", limitId).Return(&entity.RatingSubmissionMp{
+		ID: limitId, UserID: &userId, UserIDLegacy: &userId, SourceType: "product", EditCounter: 2, CreatedAt: time.Now(),
+	}, nil)
+	windowId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84843")
+	repo.Mock.On("GetRatingSubmissionById", windowId).Return(&entity.RatingSubmissionMp{
+		ID: windowId, UserID: &userId, UserIDLegacy: &userId, SourceType: "store", CreatedAt: time.Now().Add(-8 * 24 * time.Hour),
+	}, nil)
+
+	msg := svc.UpdateRatingSubmission(context.Background(), request.UpdateRatingSubmissionRequest{
+		ID: limitId.Hex(), UserID: &userId, UserIDLegacy: &userId, Value: &value,
+	})
+	assert.Equal(t, message.ErrRatingSubEditLimitReached, msg)
+
+	// store submissions have their own window
+	msg = svc.UpdateRatingSubmission(context.Background(), request.UpdateRatingSubmissionRequest{
+		ID: windowId.Hex(), UserID: &userId, UserIDLegacy: &userId, Value: &value,
+	})
+	assert.Equal(t, message.ErrRatingSubEditWindowExpired, msg)
+	repo.AssertNotCalled(t, "EditRatingSubmission", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRatingSubmissionOfAnotherUser(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	svc := service.NewRatingService(logger, repo, publicRatingRepository, medicalFacility, ratingMpRepository)
+
+	// the mocked submission belongs to user_id_legacy "success"
+	owner, other := "success", "11111111"
+	value := "5"
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	ratingObjectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84846")
+	repo.Mock.On("GetRatingSubmissionById", objectId).Return(entity.RatingSubmisson{
+		ID: objectId, UserID: &owner, RatingID: ratingObjectId.Hex(),
+	}, nil)
+	repo.Mock.On("FindRatingByRatingID", ratingObjectId).Return(nil, mongo.ErrNoDocuments)
+
+	msg := svc.UpdateRatingSubmission(request.UpdateRatingSubmissionRequest{
+		ID: objectId.Hex(), RatingID: ratingObjectId.Hex(), UserID: &other, UserIDLegacy: &other, Value: &value,
+	})
+	assert.Equal(t, message.ErrUserPermissionUpdate, msg)
+	repo.Mock.AssertNotCalled(t, "FindRatingByRatingID", mock.Anything)
+
+	// the owner and the admin get past the check
+	msg = svc.UpdateRatingSubmission(request.UpdateRatingSubmissionRequest{
+		ID: objectId.Hex(), RatingID: ratingObjectId.Hex(), UserID: &owner, UserIDLegacy: &owner, Value: &value,
+	})
+	assert.Equal(t, message.ErrRatingNotFound, msg)
+	msg = svc.UpdateRatingSubmission(request.UpdateRatingSubmissionRequest{
+		ID: objectId.Hex(), RatingID: ratingObjectId.Hex(), UserID: &other, UserIDLegacy: &other, Value: &value,
+		JWTObj: global.JWTObj{Roles: []string{global.RoleAdmin}},
+	})
+	assert.Equal(t, message.ErrRatingNotFound, msg)
+	repo.Mock.AssertNotCalled(t, "UpdateRatingSubmission", mock.Anything)
+}
+
+func TestGetListRatingSubRevisionsMp(t *testing.T) {
+	rating := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	ratingMp := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	svc := service.NewRatingSubRevisionService(logger, rating, ratingMp)
+
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84844")
+	first, second, current := "jelek", "lumayan", "bagus"
+	editedAt := time.Now()
+	ratingMp.Mock.On("GetRatingSubmissionById", objectId).Return(&entity.RatingSubmissionMp{ID: objectId, Value: 5, Comment: &current, EditCounter: 2}, nil)
+	ratingMp.Mock.On("GetRatingSubRevisions", objectId.Hex()).Return([]entity.RatingSubRevisionCol{
+		{RatingSubmissionID: objectId.Hex(), Source: entity.RatingSubRevisionSourceMp, Revision: 1, Value: "1", Comment: &first, EditedBy: "34343432", CreatedAt: editedAt},
+		{RatingSubmissionID: objectId.Hex(), Source: entity.RatingSubRevisionSourceMp, Revision: 2, Value: "1", Comment: &second, EditedBy: "34343432", CreatedAt: editedAt},
+	}, nil)
+
+	result, msg := svc.GetListRatingSubRevisions(request.ListRatingSubRevisionsRequest{ID: objectId.Hex(), Source: "mp"})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Len(t, result, 3)
+	assert.Equal(t, 1, len(result[0].Changes))
+	assert.Equal(t, "comment", result[0].Changes[0].Field)
+	assert.Equal(t, 2, len(result[1].Changes))
+	assert.Equal(t, "value", result[1].Changes[0].Field)
+	assert.Equal(t, "5", result[1].Changes[0].After)
+	assert.True(t, result[2].IsCurrent)
+	assert.Equal(t, 3, result[2].Revision)
+	assert.Empty(t, result[2].Changes)
+	rating.Mock.AssertNotCalled(t, "GetRatingSubRevisions", mock.Anything)
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/helper/message"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateRatingTypeLikertStatements(t *testing.T) {
+	req := request.SaveRatingTypeLikertRequest{
+		Type:        "likert_statements",
+		Description: &description,
+		Statements: []entity.LikertStatement{
+			{Key: "friendly", Label: "Friendly", Icon: "smile"},
+			{Key: "on_time", Label: "On time"},
+		},
+	}
+
+	msg := svc.CreateRatingTypeLikert(req)
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestCreateRatingTypeLikertStatementsDuplicateKey(t *testing.T) {
+	req := request.SaveRatingTypeLikertRequest{
+		Type: "likert_statements",
+		Statements: []entity.LikertStatement{
+			{Key: "friendly", Label: "Friendly"},
+			{Key: "friendly", Label: "Very friendly"},
+		},
+	}
+
+	msg := svc.CreateRatingTypeLikert(req)
+	assert.Equal(t, message.ErrStatementKey, msg)
+}
+
+func TestCreateRatingTypeLikertStatementsOverLimit(t *testing.T) {
+	viper.Set("likert.max-statements", 1)
+	defer viper.Set("likert.max-statements", nil)
+	req := request.SaveRatingTypeLikertRequest{
+		Type: "likert_statements",
+		Statements: []entity.LikertStatement{
+			{Key: "friendly", Label: "Friendly"},
+			{Key: "on_time", Label: "On time"},
+		},
+	}
+
+	msg := svc.CreateRatingTypeLikert(req)
+	assert.Equal(t, message.ErrMaxStatements, msg)
+}
+
+func TestUpdateRatingTypeLikertRenameStatementWithSubmission(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	req := request.SaveRatingTypeLikertRequest{
+		Id:         "629ec07e6f3c2761ba2dc828",
+		Statements: []entity.LikertStatement{{Key: "2", Label: "Punctual"}, {Key: "1", Label: "Kind"}},
+	}
+	objectId, _ := primitive.ObjectIDFromHex(req.Id)
+	statement01, statement02 := "Friendly", "On time"
+	likert := entity.RatingTypesLikertCol{ID: objectId, Type: "likert", NumStatements: 2, Statement01: &statement01, Statement02: &statement02}
+	rating := entity.RatingsCol{ID: objectId}
+	repo.Mock.On("GetRatingTypeLikertById", objectId).Return(likert)
+	repo.Mock.On("GetRatingByType", req.Id).Return(rating)
+	repo.Mock.On("GetRatingSubmissionByRatingId", req.Id).Return(entity.RatingSubmisson{ID: objectId})
+
+	msg := newSoftDeleteSvc(repo).UpdateRatingTypeLikert(req)
+	assert.Equal(t, message.SuccessMsg, msg)
+
+	req.Statements = []entity.LikertStatement{{Key: "1", Label: "Kind"}, {Key: "punctual", Label: "Punctual"}}
+	msg = newSoftDeleteSvc(repo).UpdateRatingTypeLikert(req)
+	assert.Equal(t, message.ErrCannotModifiedStatement, msg)
+}
+
+func TestGetRatingTypeLikertByIdReturnsBothShapes(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629ec07e6f3c2761ba2dc868")
+	likert := entity.RatingTypesLikertCol{
+		ID:            objectId,
+		NumStatements: 2,
+		Statements:    []entity.LikertStatement{{Key: "friendly", Label: "Friendly"}, {Key: "on_time", Label: "On time"}},
+	}
+	repo.Mock.On("GetRatingTypeLikertById", objectId).Return(likert)
+
+	result, msg := newSoftDeleteSvc(repo).GetRatingTypeLikertById(request.GetRatingTypeLikertRequest{Id: objectId.Hex()})
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, "Friendly", *result.Statement01)
+	assert.Equal(t, "On time", *result.Statement02)
+	assert.Nil(t, result.Statement03)
+}
+
+func TestGetRatingTypeLikertByIdLegacyStatementsWithGap(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629ec07e6f3c2761ba2dc868")
+	friendly, onTime := "Friendly", "On time"
+	likert := entity.RatingTypesLikertCol{
+		ID:            objectId,
+		NumStatements: 2,
+		Statement01:   &friendly,
+		Statement03:   &onTime,
+	}
+	repo.Mock.On("GetRatingTypeLikertById", objectId).Return(likert)
+
+	result, msg := newSoftDeleteSvc(repo).GetRatingTypeLikertById(request.GetRatingTypeLikertRequest{Id: objectId.Hex()})
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, []entity.LikertStatement{{Key: "1", Label: "Friendly"}, {Key: "3", Label: "On time"}}, result.Statements)
+	assert.Equal(t, "Friendly", *result.Statement01)
+	assert.Nil(t, result.Statement02)
+	assert.Equal(t, "On time", *result.Statement03)
+}
+
+func TestCreateRatingTypeLikertUnsupportedLanguage(t *testing.T) {
+	req := request.SaveRatingTypeLikertRequest{
+		Type:            "likert_statements",
+		DescriptionI18n: entity.LocalizedText{message.LanguageID: "Layanan"},
+		Statements: []entity.LikertStatement{
+			{Key: "friendly", Label: "Friendly", LabelI18n: entity.LocalizedText{"fr": "Aimable"}},
+		},
+	}
+
+	msg := svc.CreateRatingTypeLikert(req)
+	assert.Equal(t, message.ErrUnsupportedLanguage, msg)
+
+	req.Statements[0].LabelI18n = entity.LocalizedText{message.LanguageID: "Ramah"}
+	req.DescriptionI18n = entity.LocalizedText{"id-ID": "Layanan"}
+	msg = svc.CreateRatingTypeLikert(req)
+	assert.Equal(t, message.ErrUnsupportedLanguage, msg)
+}
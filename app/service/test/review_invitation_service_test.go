@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"go-klikdokter/app/model/base"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/model/response"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/message"
+	util_invitation "go-klikdokter/pkg/util/invitation"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	viper.Set("review-invitation.secret", "test-secret")
+}
+
+func newInvitation(userId string) entity.ReviewInvitationCol {
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	return entity.ReviewInvitationCol{
+		ID:          objectId,
+		OrderNumber: "888888",
+		UserID:      userId,
+		SourceType:  "product",
+		SourceUID:   "Frtgffggffgft123",
+		StoreUID:    "store-1",
+		RatingTypes: []string{"rating_for_product"},
+		Status:      entity.ReviewInvitationStatusPending,
+		ExpiredAt:   time.Now().Add(24 * time.Hour),
+	}
+}
+
+func newInvitationSubmission(userId, token string) request.CreateRatingSubmissionRequest {
+	return request.CreateRatingSubmissionRequest{
+		UserID:          &userId,
+		UserIDLegacy:    &userId,
+		DisplayName:     &name,
+		SourceUID:       "Frtgffggffgft123",
+		RatingType:      "rating_for_product",
+		Value:           "5",
+		Comment:         "mantap",
+		InvitationToken: token,
+	}
+}
+
+func TestCreateReviewInvitations(t *testing.T) {
+	viper.Set("review-invitation.rating-types.product", []string{"rating_for_product"})
+	defer viper.Set("review-invitation.rating-types.product", nil)
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	invitation := newInvitation("34343432")
+
+	repo.Mock.On("CreateReviewInvitations", mock.MatchedBy(func(invitations []entity.ReviewInvitationCol) bool {
+		expiry := time.Until(invitations[0].ExpiredAt)
+		return len(invitations) == 1 &&
+			assert.ObjectsAreEqual([]string{"rating_for_product"}, invitations[0].RatingTypes) &&
+			expiry > 6*24*time.Hour && expiry <= 7*24*time.Hour
+	})).Return(1, nil).Once()
+	repo.Mock.On("GetReviewInvitationsByOrderNumber", "888888", "34343432").Return([]entity.ReviewInvitationCol{invitation}, nil).Once()
+
+	result, msg := service.NewReviewInvitationService(logger, repo).CreateReviewInvitations(request.CreateReviewInvitationsRequest{
+		OrderNumber: "888888",
+		UserID:      "34343432",
+		Items:       []request.ReviewInvitationItem{{SourceType: "product", SourceUID: "Frtgffggffgft123", StoreUID: "store-1"}},
+		ExpiryDays:  7,
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Len(t, result, 1)
+	id, err := util_invitation.ParseToken(result[0].Token)
+	assert.Nil(t, err)
+	assert.Equal(t, invitation.ID.Hex(), id)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateReviewInvitationsWithoutItems(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+
+	_, msg := service.NewReviewInvitationService(logger, repo).CreateReviewInvitations(request.CreateReviewInvitationsRequest{
+		OrderNumber: "888888",
+		UserID:      "34343432",
+	})
+
+	assert.Equal(t, message.ValidationFailCode, msg.Code)
+	repo.AssertNotCalled(t, "CreateReviewInvitations", mock.Anything)
+}
+
+func TestGetListPendingReviewInvitations(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	invitation := newInvitation("34343432")
+
+	repo.Mock.On("GetPendingReviewInvitations", "34343432", 1, int64(50)).Return([]entity.ReviewInvitationCol{invitation}, &base.Pagination{}, nil).Once()
+
+	result, _, msg := service.NewReviewInvitationService(logger, repo).GetListPendingReviewInvitations(request.ListPendingReviewInvitationsRequest{UserID: "34343432"})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, []response.ReviewInvitationResponse{{
+		ID:          invitation.ID.Hex(),
+		OrderNumber: "888888",
+		SourceType:  "product",
+		SourceUID:   "Frtgffggffgft123",
+		StoreUID:    "store-1",
+		RatingTypes: []string{"rating_for_product"},
+		Status:      entity.ReviewInvitationStatusPending,
+		ExpiredAt:   invitation.ExpiredAt,
+		Token:       util_invitation.SignToken(invitation.ID.Hex(), invitation.ExpiredAt),
+	}}, result)
+}
+
+func TestCreateRatingSubmissionMpWithInvitation(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	invitation := newInvitation("34343432")
+	ratingType := entity.RatingTypesNumCol{ID: invitation.ID}
+	token := util_invitation.SignToken(invitation.ID.Hex(), invitation.ExpiredAt)
+
+	repo.Mock.On("GetReviewInvitationById", invitation.ID).Return(&invitation, nil).Once()
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_product").Return(&ratingType, nil).Once()
+	repo.Mock.On("FindRatingSubmissionByOrderLine", "888888", "product", "Frtgffggffgft123", "34343432").Return(nil, mongo.ErrNoDocuments).Once()
+	repo.Mock.On("CreateRatingSubmission", mock.MatchedBy(func(input []entity.RatingSubmissionMp) bool {
+		return input[0].InvitationID != nil && *input[0].InvitationID == invitation.ID && input[0].IsVerifiedPurchase &&
+			input[0].SourceTransID == "888888" && input[0].OrderNumber == "888888" && input[0].StoreUID == "store-1"
+	}), mock.Anything).Return(&[]entity.RatingSubmissionMp{{ID: invitation.ID}}, nil).Once()
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(), newInvitationSubmission("34343432", token))
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "GetReviewInvitationByOrderLine", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateRatingSubmissionMpInvitationOtherLineOfOrder(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	invitation := newInvitation("34343432")
+	invitation.SourceUID = "Hyjkkjjkkj456"
+	ratingType := entity.RatingTypesNumCol{ID: invitation.ID}
+	token := util_invitation.SignToken(invitation.ID.Hex(), invitation.ExpiredAt)
+	input := newInvitationSubmission("34343432", token)
+	input.SourceUID = invitation.SourceUID
+
+	// the first line of the order is already reviewed, the second one is keyed by its own source uid
+	repo.Mock.On("GetReviewInvitationById", invitation.ID).Return(&invitation, nil).Once()
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_product").Return(&ratingType, nil).Once()
+	repo.Mock.On("FindRatingSubmissionByOrderLine", "888888", "product", "Hyjkkjjkkj456", "34343432").Return(nil, mongo.ErrNoDocuments).Once()
+	repo.Mock.On("CreateRatingSubmission", mock.MatchedBy(func(input []entity.RatingSubmissionMp) bool {
+		return input[0].SourceTransID == "888888" && input[0].OrderNumber == "888888" && input[0].SourceUID == "Hyjkkjjkkj456"
+	}), mock.Anything).Return(&[]entity.RatingSubmissionMp{{ID: invitation.ID}}, nil).Once()
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(), input)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateRatingSubmissionMpOrderLineRated(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	invitation := newInvitation("34343432")
+	ratingType := entity.RatingTypesNumCol{ID: invitation.ID}
+	token := util_invitation.SignToken(invitation.ID.Hex(), invitation.ExpiredAt)
+
+	repo.Mock.On("GetReviewInvitationById", invitation.ID).Return(&invitation, nil)
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_product").Return(&ratingType, nil)
+	repo.Mock.On("FindRatingSubmissionByOrderLine", "888888", "product", "Frtgffggffgft123", "34343432").Return(nil, mongo.ErrNoDocuments).Once()
+	// rated by a concurrent submission, the unique index of the order line rejects it
+	repo.Mock.On("CreateRatingSubmission", mock.Anything, mock.Anything).
+		Return(nil, mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}).Once()
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(), newInvitationSubmission("34343432", token))
+	assert.Equal(t, message.UserRated, msg)
+
+	repo.Mock.On("FindRatingSubmissionByOrderLine", "888888", "product", "Frtgffggffgft123", "34343432").
+		Return(&entity.RatingSubmissionMp{ID: invitation.ID}, nil).Once()
+
+	_, msg = service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(), newInvitationSubmission("34343432", token))
+	assert.Equal(t, message.UserRated, msg)
+}
+
+func TestCreateRatingSubmissionMpInvitationRedeemed(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	invitation := newInvitation("34343432")
+	ratingType := entity.RatingTypesNumCol{ID: invitation.ID}
+	token := util_invitation.SignToken(invitation.ID.Hex(), invitation.ExpiredAt)
+
+	repo.Mock.On("GetReviewInvitationById", invitation.ID).Return(&invitation, nil).Once()
+	repo.Mock.On("FindRatingTypeNumByRatingType", "rating_for_product").Return(&ratingType, nil).Once()
+	repo.Mock.On("FindRatingSubmissionByOrderLine", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments).Once()
+	// redeemed by a concurrent submission
+	repo.Mock.On("CreateRatingSubmission", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments).Once()
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(), newInvitationSubmission("34343432", token))
+
+	assert.Equal(t, message.ErrInvitationNotPending, msg)
+}
+
+func TestCreateRatingSubmissionMpInvalidInvitation(t *testing.T) {
+	invitation := newInvitation("34343432")
+	token := util_invitation.SignToken(invitation.ID.Hex(), invitation.ExpiredAt)
+	submitted := newInvitation("34343432")
+	submitted.Status = entity.ReviewInvitationStatusSubmitted
+	storeOnly := newInvitation("34343432")
+	storeOnly.RatingTypes = []string{"rating_for_store"}
+
+	tests := []struct {
+		name       string
+		token      string
+		userId     string
+		invitation *entity.ReviewInvitationCol
+		expected   message.Message
+	}{
+		{"tampered token", token + "x", "34343432", &invitation, message.ErrInvitationInvalid},
+		{"expired token", util_invitation.SignToken(invitation.ID.Hex(), time.Now().Add(-time.Minute)), "34343432", &invitation, message.ErrInvitationExpired},
+		{"another user", token, "11111111", &invitation, message.ErrInvitationInvalid},
+		{"already submitted", token, "34343432", &submitted, message.ErrInvitationNotPending},
+		{"rating type not allowed", token, "34343432", &storeOnly, message.ErrInvitationRatingType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+			repo.Mock.On("GetReviewInvitationById", invitation.ID).Return(tt.invitation, nil)
+
+			_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(), newInvitationSubmission(tt.userId, tt.token))
+
+			assert.Equal(t, tt.expected, msg)
+			repo.AssertNotCalled(t, "CreateRatingSubmission", mock.Anything, mock.Anything)
+		})
+	}
+}
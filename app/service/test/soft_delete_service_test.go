@@ -0,0 +1,143 @@
+package test
+
+import (
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/message"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newSoftDeleteSvc(repo *repository_mock.RatingRepositoryMock) service.RatingService {
+	return service.NewRatingService(logger, repo, publicRatingRepository, medicalFacility, ratingMpRepository)
+}
+
+func TestRestoreRatingTypeNumById(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	repo.Mock.On("RestoreRatingTypeNum", objectId).Return(nil).Once()
+
+	msg := newSoftDeleteSvc(repo).RestoreRatingTypeNumById(objectId.Hex())
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestRestoreRatingTypeLikertByIdNotDeleted(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	repo.Mock.On("RestoreRatingTypeLikert", objectId).Return(mongo.ErrNoDocuments).Once()
+
+	msg := newSoftDeleteSvc(repo).RestoreRatingTypeLikertById(objectId.Hex())
+	assert.Equal(t, message.ErrNoData, msg)
+}
+
+func TestRestoreRatingFormulaByIdWrongId(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+
+	msg := newSoftDeleteSvc(repo).RestoreRatingFormulaById("wrong")
+	assert.Equal(t, message.ErrNoData, msg)
+	repo.Mock.AssertNotCalled(t, "RestoreRatingFormula", mock.Anything)
+}
+
+func TestRestoreRating(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	rating := entity.RatingsCol{ID: objectId, RatingTypeId: "629ec0836f3c2761ba2dc869", SourceUid: "1", SourceType: "doctor"}
+	repo.Mock.On("GetDeletedRatingById", objectId).Return(&rating, nil).Once()
+	repo.Mock.On("GetRatingByRatingTypeSourceUidAndSourceType", "1", "doctor").Return(nil, mongo.ErrNoDocuments).Once()
+	repo.Mock.On("RestoreRating", objectId).Return(nil).Once()
+
+	msg := newSoftDeleteSvc(repo).RestoreRating(objectId.Hex())
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestRestoreRatingExisting(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	rating := entity.RatingsCol{ID: objectId, RatingTypeId: "629ec0836f3c2761ba2dc869", SourceUid: "1", SourceType: "doctor"}
+	repo.Mock.On("GetDeletedRatingById", objectId).Return(&rating, nil).Once()
+	repo.Mock.On("GetRatingByRatingTypeSourceUidAndSourceType", "1", "doctor").Return(&entity.RatingsCol{}, nil).Once()
+
+	msg := newSoftDeleteSvc(repo).RestoreRating(objectId.Hex())
+	assert.Equal(t, message.ErrExistingRatingTypeIdSourceUidAndSourceType, msg)
+	repo.Mock.AssertNotCalled(t, "RestoreRating", objectId)
+}
+
+func TestRestoreRatingNotDeleted(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	repo.Mock.On("GetDeletedRatingById", objectId).Return(nil, mongo.ErrNoDocuments).Once()
+
+	msg := newSoftDeleteSvc(repo).RestoreRating(objectId.Hex())
+	assert.Equal(t, message.ErrDataNotFound, msg)
+}
+
+func TestRestoreRatingSubmission(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	repo.Mock.On("RestoreSubmission", objectId).Return(nil).Once()
+
+	msg := newSoftDeleteSvc(repo).RestoreRatingSubmission(objectId.Hex())
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestDeleteRatingSubmissionMp(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	repo.Mock.On("SoftDeleteRatingSubmission", objectId, deletedBy).Return(&entity.RatingSubmissionMp{ID: objectId}, nil).Once()
+
+	msg := service.NewRatingMpService(logger, repo).DeleteRatingSubmission(objectId.Hex(), deletedBy)
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestDeleteRatingSubmissionMpAlreadyDeleted(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	repo.Mock.On("SoftDeleteRatingSubmission", objectId, deletedBy).Return(nil, mongo.ErrNoDocuments).Once()
+
+	msg := service.NewRatingMpService(logger, repo).DeleteRatingSubmission(objectId.Hex(), deletedBy)
+	assert.Equal(t, message.ErrNoData, msg)
+}
+
+func TestRestoreRatingSubmissionMp(t *testing.T) {
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	repo.Mock.On("RestoreRatingSubmission", objectId).Return(&entity.RatingSubmissionMp{ID: objectId}, nil).Once()
+
+	msg := service.NewRatingMpService(logger, repo).RestoreRatingSubmission(objectId.Hex())
+	assert.Equal(t, message.SuccessMsg, msg)
+}
+
+func TestPurgeSoftDeleted(t *testing.T) {
+	viper.Set("soft-delete.retention-days", 7)
+	defer viper.Set("soft-delete.retention-days", nil)
+
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	var deletedBefore time.Time
+	repo.Mock.On("PurgeSoftDeleted", entity.RatingSubmisson{}.CollectionName(), mock.Anything).
+		Run(func(args mock.Arguments) { deletedBefore = args.Get(1).(time.Time) }).
+		Return(int64(3), nil).Once()
+	repo.Mock.On("PurgeSoftDeleted", entity.RatingsCol{}.CollectionName(), mock.Anything).Return(int64(0), e).Once()
+	repo.Mock.On("PurgeSoftDeleted", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	total := service.NewPurgeService(logger, repo).PurgeSoftDeleted()
+
+	assert.Equal(t, int64(7), total)
+	assert.WithinDuration(t, time.Now().Add(-7*24*time.Hour), deletedBefore, time.Minute)
+}
+
+func TestRestoreRatingTypeNumRecreated(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84826")
+	// the type was created again after the delete, it holds the unique key
+	repo.Mock.On("RestoreRatingTypeNum", objectId).Return(mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}).Once()
+
+	msg := newSoftDeleteSvc(repo).RestoreRatingTypeNumById(objectId.Hex())
+	assert.Equal(t, message.ErrDataExists, msg)
+}
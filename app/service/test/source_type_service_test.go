@@ -0,0 +1,189 @@
+package test
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newSourceTypeRepo(t *testing.T) *repository_mock.SourceTypeRepository {
+	t.Cleanup(func() { global.SetSourceTypes(entity.DefaultSourceTypes) })
+	return &repository_mock.SourceTypeRepository{Mock: mock.Mock{}}
+}
+
+func TestCreateSourceType(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+	input := request.SaveSourceTypeRequest{
+		Name:          "pharmacy",
+		RatingTypes:   []string{"rating_for_pharmacy"},
+		IsMarketplace: true,
+		Scale:         4,
+		AllowedValues: []string{"4", "3", "2", "1"},
+	}
+	created := entity.SourceTypeCol{ID: primitive.NewObjectID(), Name: "pharmacy", RatingTypes: input.RatingTypes, IsMarketplace: true, Scale: 4, AllowedValues: input.AllowedValues}
+
+	repo.Mock.On("GetSourceTypes").Return(entity.DefaultSourceTypes, nil).Once()
+	repo.Mock.On("CreateSourceType", mock.MatchedBy(func(sourceType entity.SourceTypeCol) bool {
+		return sourceType.Name == "pharmacy" && sourceType.Scale == 4
+	})).Return(&created, nil).Once()
+	repo.Mock.On("GetSourceTypes").Return(append(entity.DefaultSourceTypes, created), nil).Once()
+
+	result, msg := service.NewSourceTypeService(logger, repo).CreateSourceType(input)
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, "pharmacy", result.Name)
+	assert.Equal(t, "pharmacy", global.GetSourceTypeByRatingType("rating_for_pharmacy"))
+	assert.Equal(t, "4.0", global.GetMaximumValueBySourceType("pharmacy"))
+	assert.Contains(t, global.GetMarketplaceSourceTypeNames(), "pharmacy")
+	// its submissions take the marketplace path without any config change
+	assert.True(t, global.IsMarketplaceRatingType("rating_for_pharmacy"))
+	assert.Contains(t, global.GetMarketplaceRatingTypes(), "rating_for_pharmacy")
+	value := "4"
+	assert.NoError(t, request.UpdateRatingSubmissionRequest{RatingType: "rating_for_pharmacy", Value: &value}.Validate(), "rating_id is not required")
+	repo.AssertExpectations(t)
+}
+
+func TestCreateSourceTypeDuplicateName(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+	repo.Mock.On("GetSourceTypes").Return(entity.DefaultSourceTypes, nil).Once()
+
+	_, msg := service.NewSourceTypeService(logger, repo).CreateSourceType(request.SaveSourceTypeRequest{Name: "doctor"})
+
+	assert.Equal(t, message.ErrExistingSourceType, msg)
+	repo.Mock.AssertNotCalled(t, "CreateSourceType", mock.Anything)
+}
+
+func TestCreateSourceTypeRatingTypeInUse(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+	repo.Mock.On("GetSourceTypes").Return(entity.DefaultSourceTypes, nil).Once()
+
+	_, msg := service.NewSourceTypeService(logger, repo).CreateSourceType(request.SaveSourceTypeRequest{
+		Name:        "pharmacy",
+		RatingTypes: []string{"rating_for_store"},
+	})
+
+	assert.Equal(t, message.ErrSourceTypeRatingTypeInUse, msg)
+}
+
+func TestCreateSourceTypeWrongAllowedValues(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+
+	_, msg := service.NewSourceTypeService(logger, repo).CreateSourceType(request.SaveSourceTypeRequest{
+		Name:          "pharmacy",
+		Scale:         3,
+		AllowedValues: []string{"4", "3"},
+	})
+
+	assert.Equal(t, message.ValidationFailCode, msg.Code)
+	repo.Mock.AssertNotCalled(t, "GetSourceTypes")
+}
+
+func TestCreateSourceTypeDuplicateDimension(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+
+	_, msg := service.NewSourceTypeService(logger, repo).CreateSourceType(request.SaveSourceTypeRequest{
+		Name:          "pharmacy",
+		IsMarketplace: true,
+		Dimensions: []entity.RatingDimension{
+			{Name: "packaging", RatingType: "rating_for_pharmacy_packaging"},
+			{Name: "packaging", RatingType: "rating_for_pharmacy_delivery_speed"},
+		},
+	})
+
+	assert.Equal(t, message.ValidationFailCode, msg.Code)
+	repo.Mock.AssertNotCalled(t, "GetSourceTypes")
+}
+
+func TestCreateSourceTypeSoldByItself(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+
+	_, msg := service.NewSourceTypeService(logger, repo).CreateSourceType(request.SaveSourceTypeRequest{
+		Name:            "pharmacy",
+		StoreSourceType: "pharmacy",
+	})
+
+	assert.Equal(t, message.ValidationFailCode, msg.Code)
+	repo.Mock.AssertNotCalled(t, "GetSourceTypes")
+}
+
+func TestUpdateSourceTypeRenameInUse(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+	objectId := primitive.NewObjectID()
+	current := entity.SourceTypeCol{ID: objectId, Name: "doctor", AllowComment: true}
+	repo.Mock.On("GetSourceTypeById", objectId).Return(&current, nil).Once()
+	repo.Mock.On("GetSourceTypes").Return([]entity.SourceTypeCol{current}, nil).Once()
+	repo.Mock.On("CountRatingsBySourceType", "doctor").Return(int64(1), nil).Once()
+
+	_, msg := service.NewSourceTypeService(logger, repo).UpdateSourceType(request.SaveSourceTypeRequest{Id: objectId.Hex(), Name: "dokter"})
+
+	assert.Equal(t, message.ErrSourceTypeInUse, msg)
+	repo.Mock.AssertNotCalled(t, "UpdateSourceType", mock.Anything, mock.Anything)
+}
+
+func TestDeleteSourceTypeInUse(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+	objectId := primitive.NewObjectID()
+	repo.Mock.On("GetSourceTypeById", objectId).Return(&entity.SourceTypeCol{ID: objectId, Name: "store"}, nil).Once()
+	repo.Mock.On("CountRatingsBySourceType", "store").Return(int64(1), nil).Once()
+
+	msg := service.NewSourceTypeService(logger, repo).DeleteSourceType(objectId.Hex())
+
+	assert.Equal(t, message.ErrSourceTypeInUse, msg)
+	repo.Mock.AssertNotCalled(t, "DeleteSourceType", mock.Anything)
+}
+
+func TestLoadSourceTypesDoesNotSeed(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+	// every source type was deleted by the admins
+	repo.Mock.On("GetSourceTypes").Return([]entity.SourceTypeCol{}, nil).Once()
+
+	err := service.NewSourceTypeService(logger, repo).LoadSourceTypes()
+
+	assert.Nil(t, err)
+	assert.Empty(t, global.GetSourceTypeNames())
+	repo.Mock.AssertNotCalled(t, "SeedSourceTypes", mock.Anything)
+}
+
+func TestSeedSourceTypes(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+	repo.Mock.On("SeedSourceTypes", entity.DefaultSourceTypes).Return(nil).Once()
+	repo.Mock.On("SeedDimensionRatingTypes", entity.DefaultDimensionRatingTypesNum()).Return(nil).Once()
+
+	err := service.NewSourceTypeService(logger, repo).SeedSourceTypes()
+
+	assert.Nil(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateSourceTypeConcurrentName(t *testing.T) {
+	repo := newSourceTypeRepo(t)
+	repo.Mock.On("GetSourceTypes").Return(entity.DefaultSourceTypes, nil).Once()
+	repo.Mock.On("CreateSourceType", mock.Anything).Return(nil, mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}).Once()
+
+	_, msg := service.NewSourceTypeService(logger, repo).CreateSourceType(request.SaveSourceTypeRequest{Name: "pharmacy"})
+
+	assert.Equal(t, message.ErrExistingSourceType, msg)
+}
+
+func TestCreateRatingSubmissionMpCommentNotAllowed(t *testing.T) {
+	t.Cleanup(func() { global.SetSourceTypes(entity.DefaultSourceTypes) })
+	global.SetSourceTypes([]entity.SourceTypeCol{
+		{Name: "store", RatingTypes: []string{"rating_for_store"}, IsMarketplace: true, Scale: 3, AllowedValues: []string{"3", "2", "1"}, AllowMedia: true},
+	})
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+
+	_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(), newDimensionSubmission())
+
+	assert.Equal(t, message.ErrCommentNotAllowed, msg)
+	repo.Mock.AssertNotCalled(t, "CreateRatingSubmission", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,217 @@
+package test
+
+import (
+	"context"
+	"go-klikdokter/app/model/entity"
+	"go-klikdokter/app/model/request"
+	"go-klikdokter/app/repository/repository_mock"
+	"go-klikdokter/app/service"
+	"go-klikdokter/helper/message"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newSubmissionTags() []entity.SubmissionTagCol {
+	return []entity.SubmissionTagCol{
+		{SourceType: "store", Key: "late_delivery", Label: "Pengiriman terlambat", LabelI18n: entity.LocalizedText{"en": "Late delivery"}, MinValue: 1, MaxValue: 2},
+		{SourceType: "store", Key: "fast_response", Label: "Respon cepat", LabelI18n: entity.LocalizedText{"en": "Fast response"}, MinValue: 3, MaxValue: 3},
+		{SourceType: "store", Key: "original_product", Label: "Produk original"},
+	}
+}
+
+func TestGetListSubmissionTagsByValue(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	repo.Mock.On("GetSubmissionTags", "store").Return(newSubmissionTags(), nil).Once()
+
+	result, msg := service.NewSubmissionTagService(logger, repo).GetListSubmissionTags(request.ListSubmissionTagsRequest{
+		SourceType: "store",
+		Value:      "3",
+		Lang:       "en",
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, "Fast response", result[0].Label)
+	assert.Equal(t, "Produk original", result[1].Label)
+}
+
+func TestCreateSubmissionTagExistingKey(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	repo.Mock.On("GetSubmissionTags", "store").Return(newSubmissionTags(), nil).Once()
+
+	_, msg := service.NewSubmissionTagService(logger, repo).CreateSubmissionTag(request.SaveSubmissionTagRequest{
+		SourceType: "store",
+		Key:        "late_delivery",
+		Label:      "Terlambat",
+	})
+
+	assert.Equal(t, message.ErrExistingTag, msg)
+	repo.Mock.AssertNotCalled(t, "CreateSubmissionTag", mock.Anything)
+}
+
+func TestCreateSubmissionTagConcurrentKey(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	repo.Mock.On("GetSubmissionTags", "store").Return(newSubmissionTags(), nil).Once()
+	// another admin created the key since it was checked
+	repo.Mock.On("CreateSubmissionTag", mock.Anything).Return(nil, mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}).Once()
+
+	_, msg := service.NewSubmissionTagService(logger, repo).CreateSubmissionTag(request.SaveSubmissionTagRequest{
+		SourceType: "store",
+		Key:        "damaged",
+		Label:      "Rusak",
+	})
+
+	assert.Equal(t, message.ErrExistingTag, msg)
+	repo.Mock.AssertExpectations(t)
+}
+
+func TestCreateSubmissionTagWrongRange(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+
+	_, msg := service.NewSubmissionTagService(logger, repo).CreateSubmissionTag(request.SaveSubmissionTagRequest{
+		SourceType: "store",
+		Key:        "damaged",
+		Label:      "Rusak",
+		MinValue:   2,
+		MaxValue:   1,
+	})
+
+	assert.Equal(t, message.ValidationFailCode, msg.Code)
+	repo.Mock.AssertNotCalled(t, "GetSubmissionTags", mock.Anything)
+}
+
+func TestUpdateSubmissionTagKeepsKey(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId := primitive.NewObjectID()
+	current := newSubmissionTags()[0]
+	current.ID = objectId
+	repo.Mock.On("GetSubmissionTagById", objectId).Return(&current, nil).Once()
+	repo.Mock.On("UpdateSubmissionTag", objectId, entity.SubmissionTagCol{Label: "Terlambat", MinValue: 1, MaxValue: 3}).Return(&current, nil).Once()
+
+	_, msg := service.NewSubmissionTagService(logger, repo).UpdateSubmissionTag(request.SaveSubmissionTagRequest{
+		Id:       objectId.Hex(),
+		Key:      "renamed",
+		Label:    "Terlambat",
+		MinValue: 1,
+		MaxValue: 3,
+	})
+
+	assert.Equal(t, message.SuccessMsg, msg)
+	repo.Mock.AssertExpectations(t)
+}
+
+func TestCreateRatingSubmissionMpTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		msg  message.Message
+	}{
+		{name: "not exist", tags: []string{"damaged"}, msg: message.ErrTagNotExist},
+		{name: "duplicate", tags: []string{"fast_response", "fast_response"}, msg: message.ErrDuplicateTag},
+		{name: "not allowed for value", tags: []string{"late_delivery"}, msg: message.ErrTagNotAllowedForValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newDimensionRatingMpRepo(t)
+			repo.Mock.On("GetSubmissionTags", "store").Return(newSubmissionTags(), nil).Once()
+			input := newDimensionSubmission()
+			input.Tags = tt.tags
+
+			_, msg := service.NewRatingMpService(logger, repo).CreateRatingSubmissionMp(context.Background(), input)
+
+			assert.Equal(t, tt.msg, msg)
+			repo.Mock.AssertNotCalled(t, "CreateRatingSubmission", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateRatingSubmissionTagNotAllowedForValue(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	value := "1"
+	userIdLegacy := "success"
+	minScore, maxScore, scale, intervals := 1, 5, 0, 5
+	repo.Mock.On("FindRatingByRatingID", objectId).Return(entity.RatingsCol{ID: objectId, RatingTypeId: id, SourceType: "doctor", Status: &Bool}, nil)
+	repo.Mock.On("FindRatingSubmissionByUserIDLegacyAndRatingID", &userIdLegacy, id, mock.Anything).Return(entity.RatingSubmisson{}, nil)
+	repo.Mock.On("FindRatingNumericTypeByRatingTypeID", objectId).Return(entity.RatingTypesNumCol{
+		Status: &Bool, MinScore: &minScore, MaxScore: &maxScore, Scale: &scale, Intervals: &intervals,
+	}, nil)
+	repo.Mock.On("GetSubmissionTags", "doctor").Return([]entity.SubmissionTagCol{{SourceType: "doctor", Key: "fast_response", MinValue: 4, MaxValue: 5}}, nil).Once()
+
+	_, msg := service.NewRatingService(logger, repo, publicRatingRepository, medicalFacility, ratingMpRepository).CreateRatingSubmission(request.CreateRatingSubmissionRequest{
+		Ratings:      []request.RatingByType{{ID: id, Value: &value}},
+		UserIDLegacy: &userIdLegacy,
+		DisplayName:  &name,
+		Tags:         []string{"fast_response"},
+	})
+
+	assert.Equal(t, message.ErrTagNotAllowedForValue, msg)
+	repo.Mock.AssertNotCalled(t, "CreateRatingSubmission", mock.Anything, mock.Anything)
+}
+
+func TestUpdateRatingSubmissionMpTagsCheckedAgainstNewValue(t *testing.T) {
+	defer setEditLimits(5, 30)()
+	repo := &repository_mock.RatingMpRepository{Mock: mock.Mock{}}
+	svc := service.NewRatingMpService(logger, repo)
+
+	userId := "34343432"
+	objectId, _ := primitive.ObjectIDFromHex("629dce7bf1f26275e0d84847")
+	comment := "telat"
+	repo.Mock.On("GetRatingSubmissionById", objectId).Return(&entity.RatingSubmissionMp{
+		ID: objectId, UserID: &userId, UserIDLegacy: &userId, SourceType: "store", StoreUID: "store-1",
+		Value: 1, Comment: &comment, Tags: []string{"late_delivery"}, CreatedAt: time.Now(),
+	}, nil)
+	repo.Mock.On("GetSubmissionTags", "store").Return(newSubmissionTags(), nil)
+
+	// the 1 star tags are kept by an edit to 5 stars without tags
+	value := "5"
+	msg := svc.UpdateRatingSubmission(context.Background(), request.UpdateRatingSubmissionRequest{
+		ID: objectId.Hex(), UserID: &userId, UserIDLegacy: &userId, Value: &value, Comment: comment,
+	})
+	assert.Equal(t, message.ErrTagNotAllowedForValue, msg)
+	repo.Mock.AssertNotCalled(t, "EditRatingSubmission", mock.Anything, mock.Anything, mock.Anything)
+
+	repo.Mock.On("EditRatingSubmission", mock.MatchedBy(func(sub entity.RatingSubmissionMp) bool {
+		return sub.Value == 3 && assert.ObjectsAreEqual([]string{"fast_response"}, sub.Tags)
+	}), objectId, mock.Anything).Return(nil).Once()
+	repo.Mock.On("ScheduleFinalRating", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	value = "3"
+	msg = svc.UpdateRatingSubmission(context.Background(), request.UpdateRatingSubmissionRequest{
+		ID: objectId.Hex(), UserID: &userId, UserIDLegacy: &userId, Value: &value, Comment: comment, Tags: []string{"fast_response"},
+	})
+	assert.Equal(t, message.SuccessMsg, msg)
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateRatingSubmissionTagsCheckedAgainstNewValue(t *testing.T) {
+	repo := &repository_mock.RatingRepositoryMock{Mock: mock.Mock{}}
+	objectId, _ := primitive.ObjectIDFromHex(id)
+	userIdLegacy := "success"
+	minScore, maxScore, scale, intervals := 1, 5, 0, 5
+	repo.Mock.On("GetRatingSubmissionById", objectId).Return(entity.RatingSubmisson{
+		ID: objectId, RatingID: id, Value: "5", Tags: []string{"fast_response"},
+	}, nil)
+	repo.Mock.On("FindRatingByRatingID", objectId).Return(entity.RatingsCol{ID: objectId, RatingTypeId: id, SourceType: "doctor", Status: &Bool}, nil)
+	repo.Mock.On("FindRatingNumericTypeByRatingTypeID", objectId).Return(entity.RatingTypesNumCol{
+		Status: &Bool, MinScore: &minScore, MaxScore: &maxScore, Scale: &scale, Intervals: &intervals,
+	}, nil)
+	repo.Mock.On("FindRatingSubmissionByUserIDLegacyAndRatingID", mock.Anything, mock.Anything, mock.Anything).Return(entity.RatingSubmisson{}, nil)
+	repo.Mock.On("GetSubmissionTags", "doctor").Return([]entity.SubmissionTagCol{{SourceType: "doctor", Key: "fast_response", MinValue: 4, MaxValue: 5}}, nil)
+
+	value := "1"
+	msg := service.NewRatingService(logger, repo, publicRatingRepository, medicalFacility, ratingMpRepository).UpdateRatingSubmission(request.UpdateRatingSubmissionRequest{
+		ID:           id,
+		RatingID:     id,
+		Value:        &value,
+		UserIDLegacy: &userIdLegacy,
+	})
+
+	assert.Equal(t, message.ErrTagNotAllowedForValue, msg)
+	repo.Mock.AssertNotCalled(t, "UpdateRatingSubmission", mock.Anything)
+}
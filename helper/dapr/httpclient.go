@@ -61,6 +61,7 @@ func (c httpClient) PublishEvent(topic string, data string) (map[string]interfac
 		"Response":       "",
 	}
 
+	dataResponse["StatusCode"] = response.StatusCode
 	dataResponse["Request"] = string(data)
 	dataRsh, _ := json.Marshal(response.Header)
 	dataRs, _ := ioutil.ReadAll(response.Body)
@@ -2,12 +2,15 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-klikdokter/helper/config"
+	util_ratelimit "go-klikdokter/pkg/util/ratelimit"
 	"time"
 
 	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -17,6 +20,12 @@ var (
 	DbMongo *mongo.Database
 )
 
+// codes of the errors returned when dropping an index already dropped
+const (
+	errCodeNamespaceNotFound = 26
+	errCodeIndexNotFound     = 27
+)
+
 func NewMongo() (*mongo.Database, error) {
 	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
 	// uri := fmt.Sprintf("mongodb://%s:%s@%s:%s/?authSource=%s", viper.GetString("database.username"), viper.GetString("database.password"), viper.GetString("database.hostname"), viper.GetString("database.port"), viper.GetString("database.dbname"))
@@ -31,19 +40,19 @@ func NewMongo() (*mongo.Database, error) {
 		return nil, err
 	}
 
-	err = CreateIndex(client, "ratingTypesNumCol", "type", true)
+	err = CreateIndexSoftDeleteUnique(client, "ratingTypesNumCol", "type")
 	if err != nil {
 		return nil, err
 	}
-	err = CreateIndex(client, "ratingTypesLikertCol", "type", true)
+	err = CreateIndexSoftDeleteUnique(client, "ratingTypesLikertCol", "type")
 	if err != nil {
 		return nil, err
 	}
-	err = CreateIndex(client, "ratingsCol", "name", true)
+	err = CreateIndexSoftDeleteUnique(client, "ratingsCol", "name")
 	if err != nil {
 		return nil, err
 	}
-	err = CreateIndex(client, "ratingSubCol", "source_trans_id", true)
+	err = CreateIndexRatingSubCol(client)
 	if err != nil {
 		return nil, err
 	}
@@ -55,13 +64,25 @@ func NewMongo() (*mongo.Database, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = CreateIndex(client, "ratingAggregateCol", "rating_id", true)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexRatingAggregateCol(client)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexRatingFormulaVersionCol(client)
+	if err != nil {
+		return nil, err
+	}
 
 	// for mp
 	err = CreateIndex(client, "ratingsMpCol", "name", true)
 	if err != nil {
 		return nil, err
 	}
-	err = CreateIndex(client, "ratingSubMpCol", "source_trans_id", true)
+	err = CreateIndexRatingSubMpCol(client)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +90,75 @@ func NewMongo() (*mongo.Database, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the spam heuristics count the recent submissions of an ip
+	err = CreateIndex(client, "ratingSubCol", "ip_address", false)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndex(client, "ratingSubMpCol", "ip_address", false)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexFinalRatingCol(client)
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateIndexOutboxCol(client)
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateIndexReviewInvitationCol(client)
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateIndexDaprEventCol(client)
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateIndexCommentText(client, "ratingSubCol")
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexCommentText(client, "ratingSubMpCol")
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexRatingSubHelpfulCol(client)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexRatingSubReportCol(client)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexRateLimitCol(client)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexRatingSubRevisionCol(client)
+	if err != nil {
+		return nil, err
+	}
+	err = CreateIndexSubmissionTagCol(client)
+	if err != nil {
+		return nil, err
+	}
+	// the replicas seeding the source types concurrently and the admins saving the same name conflict
+	err = CreateIndex(client, "sourceTypeCol", "name", true)
+	if err != nil {
+		return nil, err
+	}
+	// the purge job looks for the soft deleted rows
+	for _, collection := range []string{"ratingsCol", "ratingSubCol", "ratingSubMpCol", "ratingTypesNumCol", "ratingTypesLikertCol", "ratingFormulaCol"} {
+		err = CreateIndexDeletedAt(client, collection)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return client.Database(config.GetConfigString(viper.GetString("database.dbname"))), nil
 }
@@ -84,6 +174,45 @@ func CreateIndex(client *mongo.Client, collection, col string, isUnique bool) er
 	return err
 }
 
+// CreateIndexSoftDeleteUnique keeps col unique among the rows not soft deleted, deleted_at is part of the key
+// so a soft deleted row frees its col for a new row. The former unique index on col alone is dropped.
+func CreateIndexSoftDeleteUnique(client *mongo.Client, collection, col string) error {
+	indexes := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection(collection).Indexes()
+	_, err := indexes.CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: col, Value: 1}, {Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return dropIndex(indexes, col+"_1")
+}
+
+// dropIndex drops the index, an index already dropped is not an error
+func dropIndex(indexes mongo.IndexView, name string) error {
+	_, err := indexes.DropOne(context.Background(), name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.HasErrorCode(errCodeIndexNotFound) || cmdErr.HasErrorCode(errCodeNamespaceNotFound)) {
+		return nil
+	}
+	return err
+}
+
+// CreateIndexDeletedAt indexes only the soft deleted rows of the collection
+func CreateIndexDeletedAt(client *mongo.Client, collection string) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection(collection).Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	)
+	return err
+}
+
 func CreateIndexRatingsCol(client *mongo.Client) error {
 	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("ratingsCol").Indexes().CreateOne(
 		context.Background(),
@@ -93,3 +222,338 @@ func CreateIndexRatingsCol(client *mongo.Client) error {
 	)
 	return err
 }
+
+func CreateIndexRatingAggregateCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("ratingAggregateCol").Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "source_type", Value: 1}, {Key: "source_uid", Value: 1}},
+		},
+	)
+	return err
+}
+
+func CreateIndexRatingFormulaVersionCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("ratingFormulaVersionCol").Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "formula_id", Value: 1}, {Key: "version", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	return err
+}
+
+func CreateIndexFinalRatingCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("finalRatingCol").Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "source_type", Value: 1}, {Key: "source_uid", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	return err
+}
+
+// CreateIndexOutboxCol indexes the messages the dispatcher looks for and the admin list
+func CreateIndexOutboxCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("outboxCol").Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "target", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+	)
+	return err
+}
+
+// CreateIndexReviewInvitationCol keeps a single invitation per order line and indexes the pending invitations of a user,
+// a submission redeems an invitation once
+func CreateIndexReviewInvitationCol(client *mongo.Client) error {
+	db := client.Database(config.GetConfigString(viper.GetString("database.dbname")))
+	_, err := db.Collection("reviewInvitationCol").Indexes().CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "order_number", Value: 1}, {Key: "user_id", Value: 1}, {Key: "source_type", Value: 1}, {Key: "source_uid", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}, {Key: "expired_at", Value: 1}}},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection("ratingSubMpCol").Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "invitation_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.D{{Key: "invitation_id", Value: bson.D{{Key: "$exists", Value: true}}}}),
+		},
+	)
+	return err
+}
+
+// CreateIndexSubmissionTagCol keeps a single tag per key in the catalogue of a source type, the admins creating
+// the same key concurrently conflict
+func CreateIndexSubmissionTagCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("submissionTagCol").Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "source_type", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	return err
+}
+
+// CreateIndexDaprEventCol expires the processed events once dapr stopped redelivering them
+func CreateIndexDaprEventCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("daprEventCol").Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "processed_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32((30 * 24 * time.Hour).Seconds())),
+		},
+	)
+	return err
+}
+
+// CreateIndexCommentText is the full-text index of the comments searched by the q parameter.
+// The normalized comment_search weighs more, comment still matches the submissions stored before it existed.
+// Mongo has no indonesian stemmer, the words are indexed as is.
+func CreateIndexCommentText(client *mongo.Client, collection string) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection(collection).Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "comment_search", Value: "text"}, {Key: "comment", Value: "text"}},
+			Options: options.Index().SetName("comment_text").SetDefaultLanguage("none").
+				SetWeights(bson.D{{Key: "comment_search", Value: 2}, {Key: "comment", Value: 1}}),
+		},
+	)
+	return err
+}
+
+// CreateIndexRatingSubHelpfulCol keeps a single helpful vote per user and submission, of ratingSubCol and ratingSubMpCol alike.
+// The votes saved twice before the index existed are deduplicated first, the index would not be created otherwise.
+func CreateIndexRatingSubHelpfulCol(client *mongo.Client) error {
+	db := client.Database(config.GetConfigString(viper.GetString("database.dbname")))
+	indexes := db.Collection("ratingSubHelpfulCol").Indexes()
+	exists, err := hasIndex(indexes, "rating_submission_id_1_user_id_legacy_1")
+	if err != nil || exists {
+		return err
+	}
+	if _, err = DedupeRatingSubHelpfulCol(db); err != nil {
+		return err
+	}
+	_, err = indexes.CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "rating_submission_id", Value: 1}, {Key: "user_id_legacy", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	return err
+}
+
+// DedupeRatingSubHelpfulCol keeps the latest vote of a user on a submission and recounts like_counter and
+// not_helpful_counter of the submissions whose votes were removed, it returns the number of votes removed
+func DedupeRatingSubHelpfulCol(db *mongo.Database) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	helpfulColl := db.Collection("ratingSubHelpfulCol")
+	cursor, err := helpfulColl.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "rating_submission_id", Value: "$rating_submission_id"}, {Key: "user_id_legacy", Value: "$user_id_legacy"}}},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return 0, err
+	}
+	var duplicates []struct {
+		ID struct {
+			RatingSubmissionID string `bson:"rating_submission_id"`
+		} `bson:"_id"`
+		IDs []interface{} `bson:"ids"`
+	}
+	if err = cursor.All(ctx, &duplicates); err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	recount := map[string]bool{}
+	for _, duplicate := range duplicates {
+		// the latest vote comes first
+		result, err := helpfulColl.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: duplicate.IDs[1:]}}}})
+		if err != nil {
+			return removed, err
+		}
+		removed += result.DeletedCount
+		recount[duplicate.ID.RatingSubmissionID] = true
+	}
+
+	for ratingSubmissionId := range recount {
+		if err = recountRatingSubHelpful(ctx, db, ratingSubmissionId); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// recountRatingSubHelpful sets the counters of the submission, of ratingSubCol or ratingSubMpCol, from its votes
+func recountRatingSubHelpful(ctx context.Context, db *mongo.Database, ratingSubmissionId string) error {
+	objectId, err := primitive.ObjectIDFromHex(ratingSubmissionId)
+	if err != nil {
+		return nil
+	}
+	cursor, err := db.Collection("ratingSubHelpfulCol").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "rating_submission_id", Value: ratingSubmissionId}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "like_counter", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$status", 1, 0}}}}}},
+			{Key: "not_helpful_counter", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$not_helpful", 1, 0}}}}}},
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+	var counters []struct {
+		LikeCounter       int `bson:"like_counter"`
+		NotHelpfulCounter int `bson:"not_helpful_counter"`
+	}
+	if err = cursor.All(ctx, &counters); err != nil {
+		return err
+	}
+	data := bson.D{{Key: "$set", Value: bson.D{{Key: "like_counter", Value: 0}, {Key: "not_helpful_counter", Value: 0}}}}
+	if len(counters) > 0 {
+		data = bson.D{{Key: "$set", Value: bson.D{
+			{Key: "like_counter", Value: counters[0].LikeCounter},
+			{Key: "not_helpful_counter", Value: counters[0].NotHelpfulCounter},
+		}}}
+	}
+	for _, collection := range []string{"ratingSubCol", "ratingSubMpCol"} {
+		if _, err = db.Collection(collection).UpdateOne(ctx, bson.D{{Key: "_id", Value: objectId}}, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasIndex(indexes mongo.IndexView, name string) (bool, error) {
+	cursor, err := indexes.List(context.Background())
+	if err != nil {
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.HasErrorCode(errCodeNamespaceNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	var specs []bson.M
+	if err = cursor.All(context.Background(), &specs); err != nil {
+		return false, err
+	}
+	for _, spec := range specs {
+		if spec["name"] == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateIndexRatingSubCol keeps a single submission per order and rating
+func CreateIndexRatingSubCol(client *mongo.Client) error {
+	return createIndexRatingSubmissionKey(client, "ratingSubCol", "source_trans_id_1_rating_id_1_deleted_at_1",
+		bson.D{{Key: "source_trans_id", Value: 1}, {Key: "rating_id", Value: 1}, {Key: "deleted_at", Value: 1}})
+}
+
+// CreateIndexRatingSubMpCol keeps a single submission of a user per order line, the source type and uid reviewed
+func CreateIndexRatingSubMpCol(client *mongo.Client) error {
+	return createIndexRatingSubmissionKey(client, "ratingSubMpCol", "order_number_1_source_type_1_source_uid_1_user_id_1_deleted_at_1",
+		bson.D{{Key: "order_number", Value: 1}, {Key: "source_type", Value: 1}, {Key: "source_uid", Value: 1}, {Key: "user_id", Value: 1}, {Key: "deleted_at", Value: 1}})
+}
+
+// createIndexRatingSubmissionKey replaces the unique index on the concatenated source_trans_id by the index on its parts,
+// the old index is dropped first since the migrated rows of an order share their source_trans_id
+func createIndexRatingSubmissionKey(client *mongo.Client, collection, name string, keys bson.D) error {
+	db := client.Database(config.GetConfigString(viper.GetString("database.dbname")))
+	indexes := db.Collection(collection).Indexes()
+	exists, err := hasIndex(indexes, name)
+	if err != nil || exists {
+		return err
+	}
+	for _, old := range []string{"source_trans_id_1_deleted_at_1", "source_trans_id_1"} {
+		if err = dropIndex(indexes, old); err != nil {
+			return err
+		}
+	}
+	if _, err = MigrateSourceTransID(db, collection); err != nil {
+		return err
+	}
+	_, err = indexes.CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    keys,
+			Options: options.Index().SetName(name).SetUnique(true),
+		},
+	)
+	return err
+}
+
+// MigrateSourceTransID strips the rating id, or the source type, uid and user id, concatenated with "||" to the order id
+// in source_trans_id, it returns the number of submissions migrated
+func MigrateSourceTransID(db *mongo.Database, collection string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	orderNumber := bson.D{{Key: "$arrayElemAt", Value: bson.A{bson.D{{Key: "$split", Value: bson.A{"$source_trans_id", "||"}}}, 0}}}
+	result, err := db.Collection(collection).UpdateMany(ctx,
+		bson.D{{Key: "source_trans_id", Value: primitive.Regex{Pattern: `\|\|`}}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "source_trans_id", Value: orderNumber}}}}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// CreateIndexRatingSubReportCol keeps a single report per user and submission, of ratingSubCol and ratingSubMpCol alike
+func CreateIndexRatingSubReportCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("ratingSubReportCol").Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "rating_submission_id", Value: 1}, {Key: "user_id_legacy", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	return err
+}
+
+// CreateIndexRatingSubRevisionCol keeps one version per revision of a submission, of ratingSubCol and ratingSubMpCol alike
+func CreateIndexRatingSubRevisionCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection("ratingSubRevisionCol").Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "rating_submission_id", Value: 1}, {Key: "source", Value: 1}, {Key: "revision", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	return err
+}
+
+// CreateIndexRateLimitCol removes the windows of the rate limiter once they end
+func CreateIndexRateLimitCol(client *mongo.Client) error {
+	_, err := client.Database(config.GetConfigString(viper.GetString("database.dbname"))).Collection(util_ratelimit.CollectionName).Indexes().CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "expired_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	)
+	return err
+}
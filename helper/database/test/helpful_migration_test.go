@@ -0,0 +1,66 @@
+package databasetest
+
+import (
+	"go-klikdokter/helper/database"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCreateIndexRatingSubHelpfulColDedupesFirst(t *testing.T) {
+	viper.Set("database.dbname", "test")
+	defer viper.Set("database.dbname", nil)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("duplicates", func(mt *mtest.T) {
+		submissionId := primitive.NewObjectID()
+		latest, older := primitive.NewObjectID(), primitive.NewObjectID()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "test.ratingSubHelpfulCol", mtest.FirstBatch,
+				bson.D{{Key: "name", Value: "_id_"}}),
+			mtest.CreateCursorResponse(0, "test.ratingSubHelpfulCol", mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: bson.D{{Key: "rating_submission_id", Value: submissionId.Hex()}, {Key: "user_id_legacy", Value: "1"}}},
+				{Key: "ids", Value: bson.A{latest, older}},
+				{Key: "count", Value: 2},
+			}),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}},
+			mtest.CreateCursorResponse(0, "test.ratingSubHelpfulCol", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: nil}, {Key: "like_counter", Value: 3}, {Key: "not_helpful_counter", Value: 1}}),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}},
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := database.CreateIndexRatingSubHelpfulCol(mt.Client)
+
+		assert.Nil(t, err)
+		deletes := startedCommands(mt, "delete")
+		assert.Len(t, deletes, 1)
+		ids := deletes[0].Lookup("deletes").Array().Index(0).Value().Document().Lookup("q", "_id", "$in").Array()
+		assert.Equal(t, older, ids.Index(0).Value().ObjectID())
+		_, err = ids.IndexErr(1)
+		assert.Error(t, err, "the latest vote is kept")
+
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 2)
+		set := updates[1].Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$set").Document()
+		assert.Equal(t, int32(3), set.Lookup("like_counter").Int32())
+		assert.Equal(t, int32(1), set.Lookup("not_helpful_counter").Int32())
+		assert.Len(t, startedCommands(mt, "createIndexes"), 1, "the index is created once the votes are deduplicated")
+	})
+
+	mt.Run("index exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.ratingSubHelpfulCol", mtest.FirstBatch,
+			bson.D{{Key: "name", Value: "_id_"}},
+			bson.D{{Key: "name", Value: "rating_submission_id_1_user_id_legacy_1"}}))
+
+		assert.Nil(t, database.CreateIndexRatingSubHelpfulCol(mt.Client))
+		assert.Empty(t, startedCommands(mt, "aggregate"))
+		assert.Empty(t, startedCommands(mt, "createIndexes"))
+	})
+}
@@ -0,0 +1,99 @@
+package databasetest
+
+import (
+	"go-klikdokter/helper/database"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// startedCommands returns the commands named name sent to the mocked deployment, the oldest first
+func startedCommands(mt *mtest.T, name string) []bson.Raw {
+	var commands []bson.Raw
+	for _, evt := range mt.GetAllStartedEvents() {
+		if evt.CommandName == name {
+			commands = append(commands, evt.Command)
+		}
+	}
+	return commands
+}
+
+func startedCommand(mt *mtest.T, name string) bson.Raw {
+	if commands := startedCommands(mt, name); len(commands) > 0 {
+		return commands[0]
+	}
+	return nil
+}
+
+// indexKey returns the values of doc indexed by keys, a missing field is indexed as null
+func indexKey(keys bson.Raw, doc bson.M) []interface{} {
+	elements, _ := keys.Elements()
+	values := make([]interface{}, 0, len(elements))
+	for _, element := range elements {
+		values = append(values, doc[element.Key()])
+	}
+	return values
+}
+
+func TestCreateIndexSoftDeleteUniqueFreesDeletedKey(t *testing.T) {
+	viper.Set("database.dbname", "test")
+	defer viper.Set("database.dbname", nil)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("recreate", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		err := database.CreateIndexSoftDeleteUnique(mt.Client, "ratingsCol", "name")
+
+		assert.Nil(t, err)
+		index := startedCommand(mt, "createIndexes").Lookup("indexes").Array().Index(0).Value().Document()
+		assert.True(t, index.Lookup("unique").Boolean())
+		_, err = index.LookupErr("partialFilterExpression")
+		assert.Error(t, err)
+		keys := index.Lookup("key").Document()
+
+		deleted := bson.M{"name": "rating-1", "deleted_at": time.Now()}
+		recreated := bson.M{"name": "rating-1"}
+		duplicate := bson.M{"name": "rating-1", "deleted_at": nil}
+		assert.NotEqual(t, indexKey(keys, deleted), indexKey(keys, recreated), "a deleted row must not hold the key of a new one")
+		assert.Equal(t, indexKey(keys, recreated), indexKey(keys, duplicate), "two live rows must not share the key")
+		assert.Equal(t, "name_1", startedCommand(mt, "dropIndexes").Lookup("index").StringValue())
+	})
+
+	mt.Run("former index already dropped", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code: 27, Name: "IndexNotFound", Message: "index not found with name [name_1]",
+		}))
+
+		assert.Nil(t, database.CreateIndexSoftDeleteUnique(mt.Client, "ratingsCol", "name"))
+	})
+}
+
+func TestCreateIndexSubmissionTagColUniqueKeyBySourceType(t *testing.T) {
+	viper.Set("database.dbname", "test")
+	defer viper.Set("database.dbname", nil)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("create", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := database.CreateIndexSubmissionTagCol(mt.Client)
+
+		assert.Nil(t, err)
+		command := startedCommand(mt, "createIndexes")
+		assert.Equal(t, "submissionTagCol", command.Lookup("createIndexes").StringValue())
+		index := command.Lookup("indexes").Array().Index(0).Value().Document()
+		assert.True(t, index.Lookup("unique").Boolean())
+		keys := index.Lookup("key").Document()
+		assert.NotEqual(t, indexKey(keys, bson.M{"source_type": "store", "key": "damaged"}), indexKey(keys, bson.M{"source_type": "product", "key": "damaged"}),
+			"the same key is allowed in the catalogues of two source types")
+		assert.Equal(t, indexKey(keys, bson.M{"source_type": "store", "key": "damaged"}), indexKey(keys, bson.M{"source_type": "store", "key": "damaged", "label": "Rusak"}),
+			"two tags of a catalogue must not share the key")
+	})
+}
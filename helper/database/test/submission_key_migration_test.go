@@ -0,0 +1,90 @@
+package databasetest
+
+import (
+	"go-klikdokter/helper/database"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCreateIndexRatingSubMpColMigratesSourceTransID(t *testing.T) {
+	viper.Set("database.dbname", "test")
+	defer viper.Set("database.dbname", nil)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("migrate", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "test.ratingSubMpCol", mtest.FirstBatch,
+				bson.D{{Key: "name", Value: "_id_"}},
+				bson.D{{Key: "name", Value: "source_trans_id_1_deleted_at_1"}}),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 27, Name: "IndexNotFound", Message: "index not found"}),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}},
+			mtest.CreateSuccessResponse(),
+		)
+
+		err := database.CreateIndexRatingSubMpCol(mt.Client)
+
+		assert.Nil(t, err)
+		drops := startedCommands(mt, "dropIndexes")
+		assert.Len(t, drops, 2)
+		assert.Equal(t, "source_trans_id_1_deleted_at_1", drops[0].Lookup("index").StringValue(),
+			"the old index is dropped before the rows of an order share their source_trans_id")
+
+		updates := startedCommands(mt, "update")
+		assert.Len(t, updates, 1)
+		update := updates[0].Lookup("updates").Array().Index(0).Value().Document()
+		pattern, _ := update.Lookup("q", "source_trans_id").Regex()
+		assert.Equal(t, `\|\|`, pattern)
+		assert.True(t, update.Lookup("multi").Boolean())
+		split := update.Lookup("u").Array().Index(0).Value().Document().Lookup("$set", "source_trans_id", "$arrayElemAt").Array()
+		assert.Equal(t, "||", split.Index(0).Value().Document().Lookup("$split").Array().Index(1).Value().StringValue())
+		assert.Equal(t, int32(0), split.Index(1).Value().Int32())
+
+		index := startedCommand(mt, "createIndexes").Lookup("indexes").Array().Index(0).Value().Document()
+		assert.True(t, index.Lookup("unique").Boolean())
+		keys, _ := index.Lookup("key").Document().Elements()
+		var names []string
+		for _, key := range keys {
+			names = append(names, key.Key())
+		}
+		assert.Equal(t, []string{"order_number", "source_type", "source_uid", "user_id", "deleted_at"}, names)
+	})
+
+	mt.Run("index exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.ratingSubMpCol", mtest.FirstBatch,
+			bson.D{{Key: "name", Value: "_id_"}},
+			bson.D{{Key: "name", Value: "order_number_1_source_type_1_source_uid_1_user_id_1_deleted_at_1"}}))
+
+		assert.Nil(t, database.CreateIndexRatingSubMpCol(mt.Client))
+		assert.Empty(t, startedCommands(mt, "update"))
+		assert.Empty(t, startedCommands(mt, "createIndexes"))
+	})
+}
+
+func TestCreateIndexRatingSubColKeysOrderAndRating(t *testing.T) {
+	viper.Set("database.dbname", "test")
+	defer viper.Set("database.dbname", nil)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("new collection", func(mt *mtest.T) {
+		notFound := mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 26, Name: "NamespaceNotFound", Message: "ns not found"})
+		mt.AddMockResponses(
+			notFound,
+			notFound,
+			notFound,
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}},
+			mtest.CreateSuccessResponse(),
+		)
+
+		assert.Nil(t, database.CreateIndexRatingSubCol(mt.Client))
+		index := startedCommand(mt, "createIndexes").Lookup("indexes").Array().Index(0).Value().Document()
+		assert.Equal(t, "source_trans_id_1_rating_id_1_deleted_at_1", index.Lookup("name").StringValue())
+		assert.True(t, index.Lookup("unique").Boolean())
+	})
+}
@@ -0,0 +1,181 @@
+package global
+
+import (
+	"fmt"
+	"strings"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/viper"
+)
+
+// Roles known by the authorization policy
+const (
+	RoleAdmin           = "admin"
+	RoleMerchant        = "merchant"
+	RoleInternalService = "internal-service"
+	RoleUser            = "user"
+)
+
+// Policies guarding the authenticated routes, each policy lists the roles (or scopes) allowed
+const (
+	PolicyRatingTypeRead         = "rating-type-read"
+	PolicyRatingTypeWrite        = "rating-type-write"
+	PolicyRatingRead             = "rating-read"
+	PolicyRatingWrite            = "rating-write"
+	PolicyFormulaRead            = "formula-read"
+	PolicyFormulaWrite           = "formula-write"
+	PolicySubmissionCreate       = "submission-create"
+	PolicySubmissionRead         = "submission-read"
+	PolicySubmissionList         = "submission-list"
+	PolicySubmissionUpdate       = "submission-update"
+	PolicySubmissionDelete       = "submission-delete"
+	PolicySubmissionCancel       = "submission-cancel"
+	PolicySubmissionReply        = "submission-reply"
+	PolicySubmissionReplyHide    = "submission-reply-hide"
+	PolicySubmissionDisplayName  = "submission-display-name"
+	PolicySubmissionModerate     = "submission-moderate"
+	PolicySubmissionReport       = "submission-report"
+	PolicyHelpful                = "helpful"
+	PolicyInternalRating         = "internal-rating"
+	PolicyFinalRatingRepublish   = "final-rating-republish"
+	PolicyOutboxRead             = "outbox-read"
+	PolicyOutboxReplay           = "outbox-replay"
+	PolicyReviewInvitationCreate = "review-invitation-create"
+	PolicyReviewInvitationList   = "review-invitation-list"
+	PolicySourceTypeRead         = "source-type-read"
+	PolicySourceTypeWrite        = "source-type-write"
+	PolicySubmissionTagRead      = "submission-tag-read"
+	PolicySubmissionTagWrite     = "submission-tag-write"
+)
+
+var allRoles = []string{RoleAdmin, RoleMerchant, RoleInternalService, RoleUser}
+
+// DefaultAuthorizationRules is used for every policy missing from the authorization.rules config
+var DefaultAuthorizationRules = map[string][]string{
+	PolicyRatingTypeRead:         {RoleAdmin, RoleInternalService},
+	PolicyRatingTypeWrite:        {RoleAdmin},
+	PolicyRatingRead:             allRoles,
+	PolicyRatingWrite:            {RoleAdmin, RoleInternalService},
+	PolicyFormulaRead:            {RoleAdmin},
+	PolicyFormulaWrite:           {RoleAdmin},
+	PolicySubmissionCreate:       allRoles,
+	PolicySubmissionRead:         allRoles,
+	PolicySubmissionList:         {RoleAdmin, RoleInternalService},
+	PolicySubmissionUpdate:       {RoleAdmin, RoleUser},
+	PolicySubmissionDelete:       {RoleAdmin},
+	PolicySubmissionCancel:       {RoleAdmin, RoleInternalService},
+	PolicySubmissionReply:        {RoleAdmin, RoleMerchant},
+	PolicySubmissionReplyHide:    {RoleAdmin},
+	PolicySubmissionDisplayName:  {RoleAdmin, RoleInternalService},
+	PolicySubmissionModerate:     {RoleAdmin},
+	PolicySubmissionReport:       allRoles,
+	PolicyHelpful:                allRoles,
+	PolicyInternalRating:         {RoleAdmin, RoleInternalService},
+	PolicyFinalRatingRepublish:   {RoleAdmin},
+	PolicyOutboxRead:             {RoleAdmin},
+	PolicyOutboxReplay:           {RoleAdmin},
+	PolicyReviewInvitationCreate: {RoleAdmin, RoleInternalService},
+	PolicyReviewInvitationList:   {RoleAdmin, RoleUser},
+	PolicySourceTypeRead:         {RoleAdmin, RoleInternalService},
+	PolicySourceTypeWrite:        {RoleAdmin},
+	PolicySubmissionTagRead:      allRoles,
+	PolicySubmissionTagWrite:     {RoleAdmin},
+}
+
+// GetRolesFromClaims reads the roles of a verified token from the claims listed in authorization.role-claims.
+// A token without any role gets authorization.default-role, which keeps plain user tokens working.
+func GetRolesFromClaims(claims jwtv4.MapClaims) []string {
+	var roles []string
+	roleClaims := viper.GetStringSlice("authorization.role-claims")
+	if len(roleClaims) <= 0 {
+		roleClaims = []string{"roles", "role"}
+	}
+	for _, name := range roleClaims {
+		roles = append(roles, claimValues(claims, name)...)
+	}
+
+	if len(roles) <= 0 {
+		defaultRole := viper.GetString("authorization.default-role")
+		if defaultRole == "" {
+			defaultRole = RoleUser
+		}
+		roles = append(roles, defaultRole)
+	}
+	return roles
+}
+
+// GetScopesFromClaims reads the space separated scopes of a verified token.
+func GetScopesFromClaims(claims jwtv4.MapClaims) []string {
+	scopeClaim := viper.GetString("authorization.scope-claim")
+	if scopeClaim == "" {
+		scopeClaim = "scope"
+	}
+	var scopes []string
+	for _, v := range claimValues(claims, scopeClaim) {
+		scopes = append(scopes, strings.Fields(v)...)
+	}
+	return scopes
+}
+
+// GetAuthorizationRule returns the roles or scopes allowed for a policy, config rules override the defaults.
+func GetAuthorizationRule(policy string) []string {
+	key := "authorization.rules." + policy
+	if viper.IsSet(key) {
+		return viper.GetStringSlice(key)
+	}
+	return DefaultAuthorizationRules[policy]
+}
+
+// IsAuthorized reports whether one of the roles or scopes is allowed by the policy.
+// Scopes are matched with a "scope:" prefix in the rule, ex: "scope:rating.write".
+func IsAuthorized(policy string, roles, scopes []string) bool {
+	for _, allowed := range GetAuthorizationRule(policy) {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if strings.HasPrefix(allowed, "scope:") {
+			for _, scope := range scopes {
+				if strings.ToLower(scope) == strings.TrimPrefix(allowed, "scope:") {
+					return true
+				}
+			}
+			continue
+		}
+		for _, role := range roles {
+			if strings.ToLower(role) == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimValues resolves a dotted claim path (ex: realm_access.roles) into a list of strings.
+func claimValues(claims jwtv4.MapClaims, path string) []string {
+	var current interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case []string:
+		return v
+	}
+	return nil
+}
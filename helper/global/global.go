@@ -18,34 +18,3 @@ func HtmlEscape(req interface{}) {
 		field.SetString(html.EscapeString(str))
 	}
 }
-
-func GetSourceTypeByRatingType(ratingType string) string {
-	sourceType := "doctor"
-	if ratingType == "review_for_layanan" {
-		sourceType = "layanan"
-	} else if ratingType == "rating_for_product" {
-		sourceType = "product"
-	} else if ratingType == "rating_for_store" {
-		sourceType = "store"
-	}
-
-	return sourceType
-}
-
-func GetMaximumValueBySourceType(sourceType string) string {
-	// default value for product
-	var maximumValue string = "5.0"
-	if sourceType == "store" {
-		maximumValue = "3.0"
-	}
-	return maximumValue
-}
-
-func GetListRatingValueBySourceType(sourceType string) []string {
-	// default value for product
-	var arrRatingValue []string = []string{"5", "4", "3", "2", "1"}
-	if sourceType == "store" {
-		arrRatingValue = []string{"3", "2", "1"}
-	}
-	return arrRatingValue
-}
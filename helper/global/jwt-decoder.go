@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"go-klikdokter/helper/config"
 	"go-klikdokter/helper/message"
+	"strings"
+	"time"
 
 	"github.com/go-kit/kit/auth/jwt"
-	jwtgo "github.com/golang-jwt/jwt"
+	jwtgo "github.com/golang-jwt/jwt/v4"
 	"github.com/spf13/viper"
 )
 
@@ -17,18 +19,33 @@ type JWTObj struct {
 	Email        string      `json:"email"`
 	Avatar       interface{} `json:"avatar"`
 	Token        string      `json:"token"`
+	StoreUID     string      `json:"store_uid"`
+	Roles        []string    `json:"roles"`
+	// AccountCreatedAt is read from the claim spam.account-created-claim, nil when the token has none
+	AccountCreatedAt *time.Time `json:"-"`
+}
+
+// IsAdmin reports whether the token owner carries the admin role.
+func (j JWTObj) IsAdmin() bool {
+	return j.HasRole(RoleAdmin)
+}
+
+// HasRole reports whether the token owner carries the role.
+func (j JWTObj) HasRole(role string) bool {
+	for _, r := range j.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
 }
 
 func SetJWTInfoFromContext(ctx context.Context) (JWTObj, message.Message) {
 	jwtObj := JWTObj{}
 	var avatar string
 	defaultAvatar := config.GetConfigString(viper.GetString("image.default-avatar"))
-	token, _, err := new(jwtgo.Parser).ParseUnverified(fmt.Sprint(ctx.Value(jwt.JWTContextKey)), jwtgo.MapClaims{})
-	if err != nil {
-		return jwtObj, message.ErrNoAuth
-	}
-
-	if claims, ok := token.Claims.(jwtgo.MapClaims); ok {
+	// claims are only present once the token signature has been verified by the JWT middleware
+	if claims, ok := ctx.Value(jwt.JWTClaimsContextKey).(jwtgo.MapClaims); ok {
 		// Get claim value
 		var userIdLegacy = claims["id"]
 		// if token.Method == jwtgo.SigningMethodRS256 {
@@ -61,9 +78,40 @@ func SetJWTInfoFromContext(ctx context.Context) (JWTObj, message.Message) {
 		jwtObj.Fullname = fmt.Sprintf("%s", fullname)
 		jwtObj.Avatar = avatar
 		jwtObj.Email = fmt.Sprintf("%s", claims["email"])
+		jwtObj.Token = fmt.Sprint(ctx.Value(jwt.JWTContextKey))
+		jwtObj.Roles = GetRolesFromClaims(claims)
+		if storeUID, ok := claims["store_uid"]; ok && storeUID != nil {
+			jwtObj.StoreUID = fmt.Sprint(storeUID)
+		}
+		jwtObj.AccountCreatedAt = getTimeClaim(claims, getAccountCreatedClaim())
 
 		return jwtObj, message.SuccessMsg
 	} else {
 		return jwtObj, message.ErrNoAuth
 	}
 }
+
+func getAccountCreatedClaim() string {
+	if claim := viper.GetString("spam.account-created-claim"); claim != "" {
+		return claim
+	}
+	return "created_at"
+}
+
+// getTimeClaim reads a claim holding unix seconds or an RFC3339 date
+func getTimeClaim(claims jwtgo.MapClaims, name string) *time.Time {
+	var t time.Time
+	switch v := claims[name].(type) {
+	case float64:
+		t = time.Unix(int64(v), 0)
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil
+		}
+		t = parsed
+	default:
+		return nil
+	}
+	return &t
+}
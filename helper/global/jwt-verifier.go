@@ -0,0 +1,276 @@
+package global
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go-klikdokter/helper/config"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/viper"
+)
+
+const defaultJWKSReloadIntervalSeconds = 60
+
+var (
+	ErrJWTKeyNotFound = errors.New("no verification key matches the token")
+	ErrJWTIssuer      = errors.New("token issuer is not accepted")
+	ErrJWTAudience    = errors.New("token audience is not accepted")
+	ErrJWTExpRequired = errors.New("token has no expiration")
+)
+
+// JWTVerificationKey is a single key accepted to verify incoming tokens.
+// Kid is empty for keys configured without a key id, those keys are tried
+// in order when the token header carries no kid.
+type JWTVerificationKey struct {
+	Kid    string
+	Method jwtv4.SigningMethod
+	Key    interface{}
+}
+
+type jwksFile struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		K   string `json:"k"`
+	} `json:"keys"`
+}
+
+type jwtKeyStore struct {
+	mu          sync.RWMutex
+	keys        []JWTVerificationKey
+	jwksPath    string
+	jwksModTime time.Time
+	loaded      bool
+}
+
+var jwtKeys = &jwtKeyStore{}
+
+// GetJWTVerificationKeys returns the keys matching the signing method and kid of a token.
+// The keys are loaded once, RunJWTKeyReload picks up a rotated JWKS file without restart.
+func GetJWTVerificationKeys(method jwtv4.SigningMethod, kid string) ([]JWTVerificationKey, error) {
+	keys, err := jwtKeys.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []JWTVerificationKey
+	for _, k := range keys {
+		if k.Method.Alg() != method.Alg() {
+			continue
+		}
+		if kid != "" && k.Kid != "" && k.Kid != kid {
+			continue
+		}
+		results = append(results, k)
+	}
+	if len(results) <= 0 {
+		return nil, ErrJWTKeyNotFound
+	}
+	return results, nil
+}
+
+// VerifyJWTClaims validates the configured issuer and audience of a verified token.
+// exp and nbf are already checked by the parser, exp is only enforced to be present here.
+func VerifyJWTClaims(claims jwtv4.MapClaims) error {
+	if _, ok := claims["exp"]; !ok && viper.GetBool("security.jwt.require-exp") {
+		return ErrJWTExpRequired
+	}
+
+	issuers := viper.GetStringSlice("security.jwt.issuer")
+	if len(issuers) > 0 {
+		valid := false
+		for _, iss := range issuers {
+			if claims.VerifyIssuer(config.GetConfigString(iss), true) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return ErrJWTIssuer
+		}
+	}
+
+	audiences := viper.GetStringSlice("security.jwt.audience")
+	if len(audiences) > 0 {
+		valid := false
+		for _, aud := range audiences {
+			if claims.VerifyAudience(config.GetConfigString(aud), true) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return ErrJWTAudience
+		}
+	}
+	return nil
+}
+
+// ResetJWTVerificationKeys drops the cached keys, the next verification reloads them from config.
+func ResetJWTVerificationKeys() {
+	jwtKeys.mu.Lock()
+	defer jwtKeys.mu.Unlock()
+	jwtKeys.keys = nil
+	jwtKeys.loaded = false
+	jwtKeys.jwksModTime = time.Time{}
+}
+
+// RunJWTKeyReload checks the JWKS file every security.jwt.jwks-reload-interval-seconds until ctx is done
+// and reloads the keys when the file changed on disk. The current keys are kept when the reload fails.
+func RunJWTKeyReload(ctx context.Context, logger log.Logger) {
+	interval := viper.GetInt("security.jwt.jwks-reload-interval-seconds")
+	if interval <= 0 {
+		interval = defaultJWKSReloadIntervalSeconds
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := jwtKeys.reloadIfChanged(); err != nil {
+				_ = level.Error(logger).Log("Type", "RunJWTKeyReload", "err", err)
+			}
+		}
+	}
+}
+
+func (s *jwtKeyStore) load() ([]JWTVerificationKey, error) {
+	s.mu.RLock()
+	if s.loaded {
+		keys := s.keys
+		s.mu.RUnlock()
+		return keys, nil
+	}
+	s.mu.RUnlock()
+
+	jwksPath, modTime, err := jwksFileState()
+	if err != nil {
+		return nil, err
+	}
+	return s.reload(jwksPath, modTime)
+}
+
+// reloadIfChanged reloads the keys when the JWKS file path or its modification time changed since the last load.
+func (s *jwtKeyStore) reloadIfChanged() error {
+	jwksPath, modTime, err := jwksFileState()
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	unchanged := s.loaded && s.jwksPath == jwksPath && s.jwksModTime.Equal(modTime)
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	_, err = s.reload(jwksPath, modTime)
+	return err
+}
+
+func (s *jwtKeyStore) reload(jwksPath string, modTime time.Time) ([]JWTVerificationKey, error) {
+	keys := loadJWTSecrets()
+	if jwksPath != "" {
+		jwks, err := loadJWKSFile(jwksPath)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, jwks...)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.jwksPath = jwksPath
+	s.jwksModTime = modTime
+	s.loaded = true
+	s.mu.Unlock()
+	return keys, nil
+}
+
+// jwksFileState returns the configured JWKS file path and its modification time, both empty without a file.
+func jwksFileState() (string, time.Time, error) {
+	jwksPath := config.GetConfigString(viper.GetString("security.jwt.jwks-file"))
+	if jwksPath == "" {
+		return "", time.Time{}, nil
+	}
+	info, err := os.Stat(jwksPath)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return jwksPath, info.ModTime(), nil
+}
+
+// loadJWTSecrets reads the HS256 secret and the previous secrets still accepted while rotating.
+func loadJWTSecrets() []JWTVerificationKey {
+	var keys []JWTVerificationKey
+	secrets := append([]string{viper.GetString("security.jwt.secret")}, viper.GetStringSlice("security.jwt.secrets")...)
+	for _, secret := range secrets {
+		secret = config.GetConfigString(secret)
+		if secret == "" {
+			continue
+		}
+		keys = append(keys, JWTVerificationKey{Method: jwtv4.SigningMethodHS256, Key: []byte(secret)})
+	}
+	return keys
+}
+
+func loadJWKSFile(path string) ([]JWTVerificationKey, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var jwks jwksFile
+	if err = json.Unmarshal(raw, &jwks); err != nil {
+		return nil, err
+	}
+
+	var keys []JWTVerificationKey
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch strings.ToUpper(k.Kty) {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return nil, err
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, JWTVerificationKey{
+				Kid:    k.Kid,
+				Method: jwtv4.SigningMethodRS256,
+				Key: &rsa.PublicKey{
+					N: new(big.Int).SetBytes(n),
+					E: int(new(big.Int).SetBytes(e).Int64()),
+				},
+			})
+		case "OCT":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, JWTVerificationKey{Kid: k.Kid, Method: jwtv4.SigningMethodHS256, Key: secret})
+		}
+	}
+	return keys, nil
+}
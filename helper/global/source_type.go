@@ -0,0 +1,155 @@
+package global
+
+import (
+	"go-klikdokter/app/model/entity"
+	"sync"
+)
+
+// sourceTypes is the registry of the source types loaded from sourceTypeCol, the defaults until it is loaded
+var sourceTypes = struct {
+	sync.RWMutex
+	items []entity.SourceTypeCol
+}{items: entity.DefaultSourceTypes}
+
+// SetSourceTypes replaces the registry of the source types
+func SetSourceTypes(items []entity.SourceTypeCol) {
+	sourceTypes.Lock()
+	defer sourceTypes.Unlock()
+	sourceTypes.items = items
+}
+
+// GetSourceType returns the source type of the registry by name
+func GetSourceType(name string) (entity.SourceTypeCol, bool) {
+	sourceTypes.RLock()
+	defer sourceTypes.RUnlock()
+	for _, item := range sourceTypes.items {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return entity.SourceTypeCol{}, false
+}
+
+// GetSourceTypeNames returns the names of the source types of the registry
+func GetSourceTypeNames() []string {
+	sourceTypes.RLock()
+	defer sourceTypes.RUnlock()
+	names := make([]string, 0, len(sourceTypes.items))
+	for _, item := range sourceTypes.items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+// GetMarketplaceSourceTypeNames returns the names of the marketplace source types of the registry
+func GetMarketplaceSourceTypeNames() []string {
+	sourceTypes.RLock()
+	defer sourceTypes.RUnlock()
+	var names []string
+	for _, item := range sourceTypes.items {
+		if item.IsMarketplace {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// IsMarketplaceSourceType reports whether the submissions of the source type are kept in ratingSubMpCol
+func IsMarketplaceSourceType(sourceType string) bool {
+	item, _ := GetSourceType(sourceType)
+	return item.IsMarketplace
+}
+
+// GetSourceTypeByRatingType returns the source type of the submissions of the rating type, empty when no source type has it
+func GetSourceTypeByRatingType(ratingType string) string {
+	sourceTypes.RLock()
+	defer sourceTypes.RUnlock()
+	for _, item := range sourceTypes.items {
+		if item.HasRatingType(ratingType) {
+			return item.Name
+		}
+	}
+	return ""
+}
+
+// IsMarketplaceRatingType reports whether the submissions of the rating type are kept in ratingSubMpCol
+func IsMarketplaceRatingType(ratingType string) bool {
+	return IsMarketplaceSourceType(GetSourceTypeByRatingType(ratingType))
+}
+
+// GetMarketplaceRatingTypes returns the rating types of the marketplace source types of the registry
+func GetMarketplaceRatingTypes() []string {
+	sourceTypes.RLock()
+	defer sourceTypes.RUnlock()
+	var ratingTypes []string
+	for _, item := range sourceTypes.items {
+		if item.IsMarketplace {
+			ratingTypes = append(ratingTypes, item.RatingTypes...)
+		}
+	}
+	return ratingTypes
+}
+
+// IsStoreSourceType reports whether the source type is the store source type of another one,
+// its sources are then identified by the store_uid of the submissions
+func IsStoreSourceType(sourceType string) bool {
+	return len(GetSourceTypesOfStore(sourceType)) > 0
+}
+
+// GetSourceTypesOfStore returns the names of the source types sold by the stores of the store source type
+func GetSourceTypesOfStore(storeSourceType string) []string {
+	sourceTypes.RLock()
+	defer sourceTypes.RUnlock()
+	var names []string
+	for _, item := range sourceTypes.items {
+		if storeSourceType != "" && item.StoreSourceType == storeSourceType {
+			names = append(names, item.Name)
+		}
+	}
+	return names
+}
+
+// GetStoreProductSourceType returns the first source type sold by stores, the default of the store summaries
+func GetStoreProductSourceType() string {
+	sourceTypes.RLock()
+	defer sourceTypes.RUnlock()
+	for _, item := range sourceTypes.items {
+		if item.StoreSourceType != "" {
+			return item.Name
+		}
+	}
+	return ""
+}
+
+// GetFinalRatingTopicBySourceType returns the topic of the final rating events of the source type, empty when it has none
+func GetFinalRatingTopicBySourceType(sourceType string) string {
+	item, _ := GetSourceType(sourceType)
+	return item.FinalRatingTopic
+}
+
+// GetRatingDimensionsBySourceType returns the dimensions scored by the submissions of the source type
+func GetRatingDimensionsBySourceType(sourceType string) []entity.RatingDimension {
+	item, _ := GetSourceType(sourceType)
+	return item.Dimensions
+}
+
+// GetMaximumValueBySourceType returns the highest value of the scale of the source type, e.g. "5.0"
+func GetMaximumValueBySourceType(sourceType string) string {
+	item, _ := GetSourceType(sourceType)
+	return item.MaximumValue()
+}
+
+// GetListRatingValueBySourceType returns the values allowed for a submission of the source type, from the highest
+func GetListRatingValueBySourceType(sourceType string) []string {
+	item, _ := GetSourceType(sourceType)
+	return item.AllowedValues
+}
+
+// GetDefaultRatingFormula returns the default formula of the source type as a rating formula, nil when it has none
+func GetDefaultRatingFormula(sourceType string) *entity.RatingFormulaCol {
+	item, ok := GetSourceType(sourceType)
+	if !ok || item.DefaultFormula == "" {
+		return nil
+	}
+	return &entity.RatingFormulaCol{SourceType: sourceType, Formula: item.DefaultFormula}
+}
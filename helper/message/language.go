@@ -0,0 +1,197 @@
+package message
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Languages of the texts, the messages are written in English
+const (
+	LanguageID = "id"
+	LanguageEN = "en"
+)
+
+var SupportedLanguages = []string{LanguageID, LanguageEN}
+
+// DefaultLanguage is the language of a request without lang and Accept-Language of a supported language
+var DefaultLanguage = LanguageEN
+
+// IsSupportedLanguage reports whether lang is one of SupportedLanguages
+func IsSupportedLanguage(lang string) bool {
+	for _, supported := range SupportedLanguages {
+		if lang == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLanguage returns lang when supported, else the supported language of acceptLanguage with the highest quality,
+// else fallback. e.g. ParseLanguage("", "id-ID,id;q=0.9,en;q=0.8", "en") returns "id"
+func ParseLanguage(lang, acceptLanguage, fallback string) string {
+	if lang = primaryLanguage(lang); IsSupportedLanguage(lang) {
+		return lang
+	}
+
+	type weighted struct {
+		lang    string
+		quality float64
+	}
+	var accepted []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.SplitN(strings.TrimSpace(part), ";", 2)
+		quality := 1.0
+		if len(fields) == 2 && strings.HasPrefix(strings.TrimSpace(fields[1]), "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(fields[1]), "q="), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if tag := primaryLanguage(fields[0]); IsSupportedLanguage(tag) && quality > 0 {
+			accepted = append(accepted, weighted{tag, quality})
+		}
+	}
+	if len(accepted) == 0 {
+		return fallback
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].quality > accepted[j].quality })
+	return accepted[0].lang
+}
+
+// primaryLanguage returns the language of a tag, e.g. "id" of "id-ID"
+func primaryLanguage(tag string) string {
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// Localize returns the message with the text in lang
+func Localize(msg Message, lang string) Message {
+	msg.Message = LocalizeText(msg.Message, lang)
+	return msg
+}
+
+// LocalizeText returns the text of a message in lang, a text without translation is returned as it is
+func LocalizeText(text, lang string) string {
+	if lang != LanguageID {
+		return text
+	}
+	if translated, ok := translationsID[text]; ok {
+		return translated
+	}
+	return text
+}
+
+// translationsID are the texts of the messages in Indonesian
+var translationsID = map[string]string{
+	TelErrUserNotFound.Message:                            "Tidak ditemukan",
+	ErrDataExists.Message:                                 "Data sudah ada",
+	ErrBadRouting.Message:                                 "Pemetaan antara route dan handler tidak konsisten",
+	ErrInternalError.Message:                              "Terjadi kesalahan saat memproses permintaan",
+	ErrUnmarshalRequest.Message:                           "Permintaan tidak dapat dibaca",
+	ErrNoAuth.Message:                                     "Tidak ada otorisasi",
+	ErrTokenInvalid.Message:                               "Token tidak valid",
+	ErrTokenExpired.Message:                               "Token sudah kedaluwarsa",
+	ErrForbidden.Message:                                  "Tidak diizinkan mengakses resource ini",
+	ErrTooManyRequests.Message:                            "Terlalu banyak permintaan, silakan coba lagi nanti",
+	ErrInvalidHeader.Message:                              "Header tidak valid",
+	ErrDB.Message:                                         "Terjadi kesalahan saat memproses permintaan database",
+	ErrLTNumState.Message:                                 "Jumlah pernyataan kurang dari num_statements",
+	ErrGTNumState.Message:                                 "Jumlah pernyataan lebih dari num_statements",
+	ErrNoData.Message:                                     "Data tidak ditemukan",
+	ErrSaveData.Message:                                   "Data tidak dapat disimpan, silakan periksa permintaan Anda",
+	ErrMatchNumState.Message:                              "num_statements tidak sesuai dengan jumlah pernyataan yang valid",
+	ErrReq.Message:                                        "Wajib diisi",
+	ErrTypeReq.Message:                                    "Type wajib diisi",
+	ErrTypeFormatReq.Message:                              "Format type salah",
+	ErrIdFormatReq.Message:                                "Format id salah",
+	ErrScaleValueReq.Message:                              "Nilai scale salah",
+	ErrDuplicateType.Message:                              "Type duplikat, silakan periksa permintaan Anda",
+	ErrIntervalsValueReq.Message:                          "Nilai intervals salah",
+	UserAgentTooLong.Message:                              "Panjang maksimal user_agent adalah 200 karakter",
+	ErrIPFormatReq.Message:                                "Format IP salah",
+	UserUIDRequired.Message:                               "Salah satu dari display_name, user_id dan user_id_legacy wajib diisi",
+	UserRated.Message:                                     "Penilaian ganda oleh user id, rating id dan source_trans_id yang sama tidak diizinkan",
+	ErrRatingNotFound.Message:                             "Rating tidak ditemukan",
+	ErrRatingNumericTypeNotFound.Message:                  "Tipe rating numerik tidak ditemukan",
+	RatingSubmissionNotFound.Message:                      "Penilaian tidak ditemukan",
+	WrongScoreFilter.Message:                              "Format filter score salah",
+	WrongFilter.Message:                                   "Filter salah",
+	ErrValueFormatForNumericType.Message:                  "Format nilai untuk tipe numerik salah",
+	ErrLikertTypeNotFound.Message:                         "Tipe likert tidak ditemukan",
+	ErrValueFormat.Message:                                "Format nilai salah",
+	ErrRatingTypeNotExist.Message:                         "Tipe rating tidak ada",
+	ErrDuplicateRatingName.Message:                        "Nama rating sudah ada",
+	ErrSourceNotExist.Message:                             "Source tidak ada",
+	ErrFailedToCallGetMedicalFacility.Message:             "Gagal mengambil data fasilitas kesehatan",
+	ErrThisRatingTypeIsInUse.Message:                      "Tipe rating ini sedang digunakan dan memiliki penilaian",
+	ErrUnmarshalFilterListRatingRequest.Message:           "Parameter filter tidak dapat dibaca",
+	ErrDataNotFound.Message:                               "Data tidak ditemukan",
+	ErrRatingHasRatingSubmission.Message:                  "Rating memiliki penilaian",
+	ErrMinScoreReq.Message:                                "Min score wajib diisi",
+	ErrMaxScoreReq.Message:                                "Max score wajib diisi",
+	ErrScaleReq.Message:                                   "Scale wajib diisi",
+	ErrCannotModifiedStatus.Message:                       "Status tidak dapat diubah karena tipe rating ini sedang digunakan",
+	ErrCannotModifiedRatingType.Message:                   "Tipe rating tidak dapat diubah karena sudah memiliki penilaian",
+	ErrCannotModifiedRatingTypeId.Message:                 "Id tipe rating tidak dapat diubah karena sudah memiliki penilaian",
+	ErrCannotModifiedMinScore.Message:                     "Min score tidak dapat diubah karena tipe rating ini sedang digunakan dan memiliki penilaian",
+	ErrCannotModifiedMaxScore.Message:                     "Max score tidak dapat diubah karena tipe rating ini sedang digunakan dan memiliki penilaian",
+	ErrCannotModifiedScale.Message:                        "Scale tidak dapat diubah karena tipe rating ini sedang digunakan dan memiliki penilaian",
+	ErrCannotModifiedInterval.Message:                     "Interval tidak dapat diubah karena tipe rating ini sedang digunakan dan memiliki penilaian",
+	ErrCannotModifiedStatement.Message:                    "Pernyataan tidak dapat diubah karena tipe rating ini sedang digunakan dan memiliki penilaian",
+	ErrCannotModifiedNumStatement.Message:                 "Jumlah pernyataan tidak dapat diubah karena tipe rating ini sedang digunakan dan memiliki penilaian",
+	ErrCannotModifiedType.Message:                         "Type tidak dapat diubah karena tipe rating ini sedang digunakan",
+	ErrSourceUidRequire.Message:                           "source_uid wajib diisi",
+	ErrStoreUidRequire.Message:                            "store_uid wajib diisi",
+	ErrStoreUidMax.Message:                                "Panjang maksimal store_uid adalah 20",
+	ErrSourceUidMax.Message:                               "Panjang maksimal source_uid adalah 50",
+	ErrMaxMin.Message:                                     "max_score harus lebih besar dari min_score",
+	ErrTypeNotFound.Message:                               "Tipe rating tidak ditemukan",
+	ErrCannotSameRatingId.Message:                         "Tidak dapat membuat penilaian dengan rating yang sama",
+	ErrExistingRatingTypeIdSourceUidAndSourceType.Message: "Id tipe rating, source uid dan source type sudah ada",
+	ErrCanNotUpdateSourceTypeOrSoureUid.Message:           "Source uid atau source type tidak dapat diubah jika rating sudah memiliki penilaian",
+	ErrFailedToCalculate.Message:                          "Gagal menghitung nilai rating",
+	ErrFailedToGetFormula.Message:                         "Gagal mengambil formula rating",
+	ErrInvalidFormula.Message:                             "Formula tidak valid",
+	ErrOutboxMessageProcessing.Message:                    "Pesan outbox sedang dikirim, ulangi setelah pengiriman selesai",
+	ErrFailedSummaryRatingNumeric.Message:                 "Gagal membuat ringkasan rating numerik",
+	ErrDisplayNameRequired.Message:                        "Display name wajib diisi",
+	ErrUserNotFound.Message:                               "User tidak ditemukan",
+	ErrUserPermissionUpdate.Message:                       "Tidak diizinkan mengubah penilaian milik user lain",
+	ErrRangeDate.Message:                                  "end_date tidak boleh sebelum start_date",
+	ErrReplyStoreNotAllowed.Message:                       "Tidak diizinkan membalas penilaian milik toko lain",
+	ErrInvalidDate.Message:                                "Format tanggal salah, format harus 2006-01-02",
+	ErrInvitationInvalid.Message:                          "Undangan ulasan tidak valid",
+	ErrInvitationExpired.Message:                          "Undangan ulasan sudah kedaluwarsa",
+	ErrInvitationNotPending.Message:                       "Undangan ulasan sudah digunakan atau dibatalkan",
+	ErrInvitationRatingType.Message:                       "Tipe rating tidak diizinkan oleh undangan ulasan",
+	ErrRatingSubReportExists.Message:                      "Penilaian sudah dilaporkan oleh user",
+	ErrRatingSubEditWindowExpired.Message:                 "Penilaian tidak dapat diubah lagi",
+	ErrRatingSubEditLimitReached.Message:                  "Penilaian sudah mencapai batas maksimal perubahan",
+	ErrMaxStatements.Message:                              "Jumlah pernyataan melebihi batas pernyataan likert",
+	ErrStatementKey.Message:                               "Key pernyataan wajib diisi, unik dan terdiri dari huruf, angka, - atau _ dan label wajib diisi",
+	ErrUnsupportedLanguage.Message:                        "Teks terlokalisasi harus menggunakan bahasa yang didukung",
+	ErrDimensionNotExist.Message:                          "Dimensi tidak ada untuk tipe rating",
+	ErrDuplicateDimension.Message:                         "Dimensi duplikat, silakan periksa permintaan anda",
+	ErrCommentNotAllowed.Message:                          "Komentar tidak diizinkan untuk tipe sumber",
+	ErrMediaNotAllowed.Message:                            "Media tidak diizinkan untuk tipe sumber",
+	ErrExistingSourceType.Message:                         "Tipe sumber sudah ada",
+	ErrSourceTypeRatingTypeInUse.Message:                  "Tipe rating sudah dimiliki tipe sumber lain",
+	ErrSourceTypeInUse.Message:                            "Tipe sumber sedang digunakan dan memiliki rating",
+	ErrTagNotExist.Message:                                "Tag tidak ada untuk tipe sumber",
+	ErrTagNotAllowedForValue.Message:                      "Tag tidak diperbolehkan untuk nilai rating",
+	ErrDuplicateTag.Message:                               "Tag duplikat, silakan periksa permintaan anda",
+	ErrExistingTag.Message:                                "Key tag sudah ada untuk tipe sumber",
+	ErrSourceTypeNotSoldByStore.Message:                   "Tipe sumber tidak dijual oleh toko",
+	ErrRevocerRoute.Message:                               "Terjadi kesalahan routing",
+	ErrPageNotFound.Message:                               "Halaman tidak ditemukan",
+	SuccessMsg.Message:                                    "Berhasil",
+	FailedMsg.Message:                                     "Gagal",
+	RecordNotFound.Message:                                "Collection tidak ditemukan",
+	ErrReqParam.Message:                                   "Parameter permintaan tidak valid",
+	ErrFailedRequestToPayment.Message:                     "Gagal memanggil payment service",
+	ErrUploadMedia.Message:                                "Upload gagal",
+}
@@ -9,6 +9,8 @@ var (
 	SuccessCode         = 212000
 	DataNotFoundCode    = 212004
 	ErrDataNotFoundCode = 412003
+	ForbiddenCode       = 412004
+	TooManyRequestsCode = 412029
 )
 
 // Message wrapper.
@@ -23,6 +25,10 @@ var ErrBadRouting = Message{Code: FailConnectCode, Message: "Inconsistent mappin
 var ErrInternalError = Message{Code: ValidationFailCode, Message: "Error has been occured while processing request"}
 var ErrUnmarshalRequest = Message{Code: ValidationFailCode, Message: "Error can not unmarshal"}
 var ErrNoAuth = Message{Code: UnauthorizedCode, Message: "No Authorization"}
+var ErrTokenInvalid = Message{Code: UnauthorizedCode, Message: "Invalid token"}
+var ErrTokenExpired = Message{Code: UnauthorizedCode, Message: "Token is expired"}
+var ErrForbidden = Message{Code: ForbiddenCode, Message: "Not allowed to access this resource"}
+var ErrTooManyRequests = Message{Code: TooManyRequestsCode, Message: "Too many requests, please try again later"}
 var ErrInvalidHeader = Message{Code: 34005, Message: "Invalid header"}
 var ErrDB = Message{Code: FailConnectCode, Message: "Error has been occured while processing database request"}
 var ErrLTNumState = Message{Code: ValidationFailCode, Message: "Error Num of Statements less Than required Num Statements"}
@@ -82,25 +88,50 @@ var ErrRatingSubmissionNotFound = Message{Code: ValidationFailCode, Message: "Ra
 var ErrCanNotUpdateSourceTypeOrSoureUid = Message{Code: ValidationFailCode, Message: "Can not update source uid or source type if rating has rating submission"}
 var ErrFailedToCalculate = Message{Code: ValidationFailCode, Message: "Failed to calculate rating value"}
 var ErrFailedToGetFormula = Message{Code: ValidationFailCode, Message: "Failed to get formula rating"}
+var ErrInvalidFormula = Message{Code: ValidationFailCode, Message: "Formula is invalid"}
+var ErrOutboxMessageProcessing = Message{Code: ValidationFailCode, Message: "Outbox message is being delivered, replay it once the delivery finished"}
 var ErrFailedSummaryRatingNumeric = Message{Code: ValidationFailCode, Message: "Failed to summary rating numeric"}
 var ErrDisplayNameRequired = Message{Code: ValidationFailCode, Message: "Display Name is required"}
 var ErrUserNotFound = Message{Code: ValidationFailCode, Message: "User not found"}
 var ErrUserPermissionUpdate = Message{Code: ValidationFailCode, Message: "Not allowed update rating submission of another user"}
 var ErrRangeDate = Message{Code: ValidationFailCode, Message: "end_date can not before start_date"}
+var ErrReplyStoreNotAllowed = Message{Code: ForbiddenCode, Message: "Not allowed to reply rating submission of another store"}
 var ErrInvalidDate = Message{Code: ValidationFailCode, Message: "invalid format date, format should be 2006-01-02"}
+var ErrInvitationInvalid = Message{Code: ValidationFailCode, Message: "Review invitation is invalid"}
+var ErrInvitationExpired = Message{Code: ValidationFailCode, Message: "Review invitation is expired"}
+var ErrInvitationNotPending = Message{Code: ValidationFailCode, Message: "Review invitation was already used or cancelled"}
+var ErrInvitationRatingType = Message{Code: ValidationFailCode, Message: "Rating type is not allowed by the review invitation"}
+var ErrRatingSubReportExists = Message{Code: ValidationFailCode, Message: "Rating submission was already reported by the user"}
+var ErrRatingSubEditWindowExpired = Message{Code: ValidationFailCode, Message: "Rating submission can no longer be edited"}
+var ErrRatingSubEditLimitReached = Message{Code: ValidationFailCode, Message: "Rating submission reached the maximum number of edits"}
+var ErrMaxStatements = Message{Code: ValidationFailCode, Message: "Number of statements is over the limit of likert statements"}
+var ErrStatementKey = Message{Code: ValidationFailCode, Message: "Statement key is required, unique and made of letters, numbers, - or _ and label is required"}
+var ErrUnsupportedLanguage = Message{Code: ValidationFailCode, Message: "Localized text must be keyed by a supported language"}
+var ErrDimensionNotExist = Message{Code: ValidationFailCode, Message: "Dimension not exist for the rating type"}
+var ErrDuplicateDimension = Message{Code: ValidationFailCode, Message: "Duplicate dimension, please check your request"}
+var ErrCommentNotAllowed = Message{Code: ValidationFailCode, Message: "Comment is not allowed for the source type"}
+var ErrMediaNotAllowed = Message{Code: ValidationFailCode, Message: "Media is not allowed for the source type"}
+var ErrExistingSourceType = Message{Code: ValidationFailCode, Message: "Source type has already existed"}
+var ErrSourceTypeRatingTypeInUse = Message{Code: ValidationFailCode, Message: "Rating type already belongs to another source type"}
+var ErrSourceTypeInUse = Message{Code: ValidationFailCode, Message: "Source type is in use and has ratings"}
+var ErrTagNotExist = Message{Code: ValidationFailCode, Message: "Tag not exist for the source type"}
+var ErrTagNotAllowedForValue = Message{Code: ValidationFailCode, Message: "Tag is not allowed for the rating value"}
+var ErrDuplicateTag = Message{Code: ValidationFailCode, Message: "Duplicate tag, please check your request"}
+var ErrExistingTag = Message{Code: ValidationFailCode, Message: "Tag key has already existed for the source type"}
+var ErrSourceTypeNotSoldByStore = Message{Code: ValidationFailCode, Message: "Source type is not sold by stores"}
 
 // Code 39000 - 39999 Server error
-var ErrRevocerRoute = Message{Code: 39000, Message: "Terjadi kesalahan routing"}
-var ErrPageNotFound = Message{Code: 39404, Message: "Halaman Tidak ditemukan"}
+var ErrRevocerRoute = Message{Code: 39000, Message: "Routing error has occurred"}
+var ErrPageNotFound = Message{Code: 39404, Message: "Page not found"}
 var SuccessMsg = Message{Code: SuccessCode, Message: "Success"}
 var FailedMsg = Message{Code: ValidationFailCode, Message: "Failed"}
-var RecordNotFound = Message{Code: ValidationFailCode, Message: "Collection tidak ditemukan"}
+var RecordNotFound = Message{Code: ValidationFailCode, Message: "Collection not found"}
 var ErrReqParam = Message{Code: ValidationFailCode, Message: "Invalid Request Parameter(s)"}
 var ErrFailedRequestToPayment = Message{Code: ValidationFailCode, Message: "Failed to call request to payment service"}
 
 // msg in api get booking Medical facility
 var GetMedicalFacilitySuccess = Message{Code: 200, Message: "OK"}
-var GetMedicalFacilityNotFound = Message{Code: 400, Message: "Data tidak ditemukan"}
+var GetMedicalFacilityNotFound = Message{Code: 400, Message: "Data not found"}
 
 // error code upload media
 var ErrUploadMedia = Message{Code: 109400, Message: "Upload Failed"}
@@ -16,12 +16,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"go-klikdokter/app/api/initialization"
+	"go-klikdokter/app/registry"
 	"go-klikdokter/helper/_struct"
 	"go-klikdokter/helper/config"
 	"go-klikdokter/helper/consul"
 	"go-klikdokter/helper/database"
+	"go-klikdokter/helper/global"
+	"go-klikdokter/helper/message"
+	util_invitation "go-klikdokter/pkg/util/invitation"
 	"net/http"
 	"os"
 	"os/signal"
@@ -79,6 +84,11 @@ func main() {
 		logger = log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
 	}
 
+	// Review invitation tokens are forgeable when signed without a secret
+	if err := util_invitation.ValidateSecret(); err != nil {
+		panic(err.Error())
+	}
+
 	// Init MongoDB Connection
 	db, err := database.NewMongo()
 	if err != nil {
@@ -93,6 +103,52 @@ func main() {
 	}
 	_ = logger.Log("message", "Connection Db Success")
 
+	// Source types registry is loaded before the commands and the routes read it
+	sourceTypeSvc := registry.RegisterSourceTypeService(db, logger)
+	if err := sourceTypeSvc.SeedSourceTypes(); err != nil {
+		panic(err.Error())
+	}
+	if err := sourceTypeSvc.LoadSourceTypes(); err != nil {
+		panic(err.Error())
+	}
+
+	// one-off commands run against the database and exit without serving http
+	// rebuild-rating-aggregate [source_type] : backfill / repair ratingAggregateCol
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-rating-aggregate" {
+		var sourceType string
+		if len(os.Args) > 2 {
+			sourceType = os.Args[2]
+		}
+		total, msg := registry.RegisterRatingService(db, logger).RebuildRatingAggregates(sourceType)
+		_ = logger.Log("command", os.Args[1], "source_type", sourceType, "total", total, "message", msg.Message)
+		if msg.Code != message.SuccessMsg.Code {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// migrate-likert-statements : move statement_01..statement_10 of the likert types to statements
+	if len(os.Args) > 1 && os.Args[1] == "migrate-likert-statements" {
+		total, msg := registry.RegisterRatingService(db, logger).MigrateLikertStatements()
+		_ = logger.Log("command", os.Args[1], "total", total, "message", msg.Message)
+		if msg.Code != message.SuccessMsg.Code {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Outbox dispatcher delivers the calls to payment-svc, media-svc and dapr saved with the submissions
+	go registry.RegisterOutboxService(db, logger).RunOutboxDispatcher(context.Background())
+
+	// Purge job hard deletes the soft deleted rows after soft-delete.retention-days
+	go registry.RegisterPurgeService(db, logger).RunPurgeJob(context.Background())
+
+	// Source types changed on another replica are reloaded every source-type.refresh-interval-seconds
+	go sourceTypeSvc.RunSourceTypeRefresh(context.Background())
+
+	// The JWKS file is checked every security.jwt.jwks-reload-interval-seconds so keys rotate without restart
+	go global.RunJWTKeyReload(context.Background(), logger)
+
 	// Consul initialization
 	registar := consul.ConsulRegisterService(config.GetConfigString(viper.GetString("server.service-name")), config.GetConfigInt(viper.GetString("server.port")), logger)
 	registar.Register()
@@ -51,3 +51,12 @@ func ConvertToDateTime(dateTime string) (time.Time, error) {
 
 	return DateTime, err
 }
+
+// TimeIn returns the time in the location, a nil time stays nil
+func TimeIn(t *time.Time, loc *time.Location) *time.Time {
+	if t == nil {
+		return nil
+	}
+	in := t.In(loc)
+	return &in
+}
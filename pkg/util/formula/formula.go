@@ -0,0 +1,339 @@
+package util_formula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/vjeantet/govaluate"
+)
+
+// Variables available to a rating formula
+const (
+	VarSum              = "sum"
+	VarCount            = "count"
+	VarMean             = "mean"
+	VarTotalRatingPoint = "total_rating_point"
+	VarTotalUserCount   = "total_user_count"
+	VarGlobalMean       = "global_mean"
+	VarGlobalCount      = "global_count"
+	VarDecayedSum       = "decayed_sum"
+	VarDecayedCount     = "decayed_count"
+	VarDecayedMean      = "decayed_mean"
+	// hist_5 is the number of submissions with value 5, hist_4_5 with value 4.5
+	VarHistogramPrefix = "hist_"
+)
+
+// Built-in functions of a rating formula
+const (
+	// bayes(m, C) is the bayesian average of the reviews with C virtual reviews of value m
+	FuncBayes = "bayes"
+	// wilson_lower_bound(positive, total[, z]) is the lower bound of the wilson score interval, z defaults to 1.96 (95%)
+	FuncWilsonLowerBound = "wilson_lower_bound"
+)
+
+const defaultDecayHalfLifeDays = 90
+
+var (
+	identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+	functionPattern   = regexp.MustCompile(`\b(` + FuncBayes + `|` + FuncWilsonLowerBound + `)\s*\(`)
+)
+
+// Variables holds the values of a rating used to evaluate its formula
+type Variables struct {
+	Sum   float64
+	Count int64
+	// keyed by submission value, "." replaced by "_"
+	Histogram    map[string]int64
+	GlobalMean   float64
+	GlobalCount  int64
+	DecayedSum   float64
+	DecayedCount float64
+}
+
+// Loader loads the variables which need an extra query, each one is only called when the formula uses it
+type Loader struct {
+	Histogram func() (map[string]int64, error)
+	Global    func() (sum float64, count int64, err error)
+	Decayed   func(halfLife time.Duration) (sum float64, count float64, err error)
+}
+
+// Usage tells which group of variables is used by a formula
+type Usage struct {
+	Histogram bool
+	Global    bool
+	Decayed   bool
+}
+
+// GetUsage returns the variables used by the formula
+func GetUsage(formula string) Usage {
+	usage := Usage{}
+	for _, name := range identifierPattern.FindAllString(formula, -1) {
+		switch {
+		case strings.HasPrefix(name, VarHistogramPrefix):
+			usage.Histogram = true
+		case name == VarGlobalMean || name == VarGlobalCount:
+			usage.Global = true
+		case name == VarDecayedSum || name == VarDecayedCount || name == VarDecayedMean:
+			usage.Decayed = true
+		}
+	}
+	return usage
+}
+
+// Load fills the variables used by the formula which are not loaded yet
+func (v *Variables) Load(formula string, loader Loader) error {
+	usage := GetUsage(formula)
+	if usage.Histogram && v.Histogram == nil && loader.Histogram != nil {
+		histogram, err := loader.Histogram()
+		if err != nil {
+			return err
+		}
+		v.Histogram = histogram
+	}
+	if usage.Global && loader.Global != nil {
+		sum, count, err := loader.Global()
+		if err != nil {
+			return err
+		}
+		v.GlobalCount = count
+		if count > 0 {
+			v.GlobalMean = sum / float64(count)
+		}
+	}
+	if usage.Decayed && loader.Decayed != nil {
+		sum, count, err := loader.Decayed(GetDecayHalfLife())
+		if err != nil {
+			return err
+		}
+		v.DecayedSum, v.DecayedCount = sum, count
+	}
+	return nil
+}
+
+// GetDecayHalfLife returns the age at which a submission weighs half in the decayed variables
+func GetDecayHalfLife() time.Duration {
+	days := viper.GetFloat64("formula.decay-half-life-days")
+	if days <= 0 {
+		days = defaultDecayHalfLifeDays
+	}
+	return time.Duration(days * float64(24*time.Hour))
+}
+
+// Parameters returns the govaluate parameters of the variables
+func (v Variables) Parameters() map[string]interface{} {
+	parameters := map[string]interface{}{
+		VarSum:              v.Sum,
+		VarCount:            float64(v.Count),
+		VarMean:             ratio(v.Sum, float64(v.Count)),
+		VarTotalRatingPoint: v.Sum,
+		VarTotalUserCount:   float64(v.Count),
+		VarGlobalMean:       v.GlobalMean,
+		VarGlobalCount:      float64(v.GlobalCount),
+		VarDecayedSum:       v.DecayedSum,
+		VarDecayedCount:     v.DecayedCount,
+		VarDecayedMean:      ratio(v.DecayedSum, v.DecayedCount),
+	}
+	for key, total := range v.Histogram {
+		parameters[VarHistogramPrefix+strings.ReplaceAll(key, ".", "_")] = float64(total)
+	}
+	return parameters
+}
+
+// Evaluate returns the value of the formula for the variables
+func Evaluate(formula string, v Variables) (float64, error) {
+	parameters := v.Parameters()
+	return evaluate(formula, parameters)
+}
+
+func evaluate(formula string, parameters map[string]interface{}) (float64, error) {
+	expanded, err := expandFunctions(formula, parameters)
+	if err != nil {
+		return 0, err
+	}
+	expression, err := govaluate.NewEvaluableExpression(expanded)
+	if err != nil {
+		return 0, err
+	}
+	for _, token := range expression.Tokens() {
+		if token.Kind != govaluate.VARIABLE {
+			continue
+		}
+		name := token.Value.(string)
+		if _, ok := parameters[name]; ok {
+			continue
+		}
+		// a value without any submission is not in the histogram
+		if strings.HasPrefix(name, VarHistogramPrefix) {
+			parameters[name] = float64(0)
+			continue
+		}
+		return 0, fmt.Errorf("unknown variable %s", name)
+	}
+
+	value, err := expression.Evaluate(parameters)
+	if err != nil {
+		return 0, err
+	}
+	result, ok := value.(float64)
+	if !ok {
+		return 0, errors.New("formula must return a number")
+	}
+	return result, nil
+}
+
+// expandFunctions replaces every built-in function call by a parameter holding its result,
+// govaluate does not support functions
+func expandFunctions(formula string, parameters map[string]interface{}) (string, error) {
+	for {
+		loc := functionPattern.FindStringSubmatchIndex(formula)
+		if loc == nil {
+			return formula, nil
+		}
+		name := formula[loc[2]:loc[3]]
+		args, end, err := splitArguments(formula, loc[1])
+		if err != nil {
+			return "", err
+		}
+
+		values := make([]float64, 0, len(args))
+		for _, arg := range args {
+			value, err := evaluate(arg, parameters)
+			if err != nil {
+				return "", fmt.Errorf("%s: %v", name, err)
+			}
+			values = append(values, value)
+		}
+		result, err := callFunction(name, values, parameters)
+		if err != nil {
+			return "", err
+		}
+
+		key := fmt.Sprintf("fn_%d", len(parameters))
+		parameters[key] = result
+		formula = formula[:loc[0]] + key + formula[end:]
+	}
+}
+
+// splitArguments returns the arguments of the call opened just before start and the index after its closing parenthesis
+func splitArguments(formula string, start int) ([]string, int, error) {
+	var args []string
+	depth := 0
+	last := start
+	for i := start; i < len(formula); i++ {
+		switch formula[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				arg := strings.TrimSpace(formula[last:i])
+				if arg != "" || len(args) > 0 {
+					args = append(args, arg)
+				}
+				return args, i + 1, nil
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(formula[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	return nil, 0, errors.New("unbalanced parenthesis")
+}
+
+func callFunction(name string, args []float64, parameters map[string]interface{}) (float64, error) {
+	switch name {
+	case FuncBayes:
+		if len(args) != 2 {
+			return 0, errors.New("bayes needs 2 arguments: bayes(m, C)")
+		}
+		sum := parameters[VarSum].(float64)
+		count := parameters[VarCount].(float64)
+		return ratio(args[1]*args[0]+sum, args[1]+count), nil
+	case FuncWilsonLowerBound:
+		if len(args) != 2 && len(args) != 3 {
+			return 0, errors.New("wilson_lower_bound needs 2 or 3 arguments: wilson_lower_bound(positive, total[, z])")
+		}
+		z := 1.96
+		if len(args) == 3 {
+			z = args[2]
+		}
+		return wilsonLowerBound(args[0], args[1], z), nil
+	}
+	return 0, fmt.Errorf("unknown function %s", name)
+}
+
+func wilsonLowerBound(positive, total, z float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	phat := positive / total
+	z2 := z * z
+	return (phat + z2/(2*total) - z*math.Sqrt((phat*(1-phat)+z2/(4*total))/total)) / (1 + z2/total)
+}
+
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
+
+// samples used to validate a formula before it is saved
+var samples = []Variables{
+	{
+		Sum:          5,
+		Count:        1,
+		Histogram:    map[string]int64{"5": 1},
+		GlobalMean:   4.2,
+		GlobalCount:  1000,
+		DecayedSum:   4.6,
+		DecayedCount: 0.92,
+	},
+	{
+		Sum:          2400,
+		Count:        500,
+		Histogram:    map[string]int64{"1": 5, "2": 10, "3": 25, "4": 60, "5": 400},
+		GlobalMean:   4.2,
+		GlobalCount:  1000,
+		DecayedSum:   1501.5,
+		DecayedCount: 312.4,
+	},
+}
+
+// Validate evaluates the formula against sample data, the formula must return a finite number for every sample
+func Validate(formula string) error {
+	if strings.TrimSpace(formula) == "" {
+		return errors.New("formula is empty")
+	}
+	for _, sample := range samples {
+		value, err := Evaluate(formula, sample)
+		if err != nil {
+			return err
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("formula returns %v for %s", value, sample)
+		}
+	}
+	return nil
+}
+
+func (v Variables) String() string {
+	keys := make([]string, 0, len(v.Histogram))
+	for key := range v.Histogram {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	histogram := make([]string, 0, len(keys))
+	for _, key := range keys {
+		histogram = append(histogram, fmt.Sprintf("%s:%d", key, v.Histogram[key]))
+	}
+	return fmt.Sprintf("sum=%v count=%d histogram=[%s]", v.Sum, v.Count, strings.Join(histogram, " "))
+}
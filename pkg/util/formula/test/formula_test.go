@@ -0,0 +1,155 @@
+package formulatest
+
+import (
+	"errors"
+	util_formula "go-klikdokter/pkg/util/formula"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	variables := util_formula.Variables{
+		Sum:          18,
+		Count:        4,
+		Histogram:    map[string]int64{"5": 2, "4": 2, "4.5": 1},
+		GlobalMean:   4,
+		GlobalCount:  100,
+		DecayedSum:   9,
+		DecayedCount: 2,
+	}
+	tests := []struct {
+		name     string
+		formula  string
+		expected float64
+	}{
+		{"mean", "sum / count", 4.5},
+		{"mean variable", "mean", 4.5},
+		{"legacy variables", "total_rating_point / total_user_count", 4.5},
+		{"global", "global_mean * global_count", 400},
+		{"decayed mean", "decayed_mean", 4.5},
+		{"histogram", "hist_5 + hist_4", 4},
+		{"histogram of a decimal value", "hist_4_5", 1},
+		{"histogram of a value without submission", "hist_1", 0},
+		{"bayes", "bayes(global_mean, 6)", (6*4 + 18) / 10.0},
+		{"bayes of expressions", "bayes(global_mean + 1, 2 * 3)", (6*5 + 18) / 10.0},
+		{"wilson lower bound", "wilson_lower_bound(hist_5, count)", 0.15},
+		{"wilson lower bound with z", "wilson_lower_bound(hist_5, count, 0)", 0.5},
+		{"nested functions", "bayes(wilson_lower_bound(hist_5, count, 0), 2)", (2*0.5 + 18) / 6.0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := util_formula.Evaluate(test.formula, variables)
+			assert.Nil(t, err)
+			assert.InDelta(t, test.expected, value, 0.01)
+		})
+	}
+}
+
+func TestEvaluateWithoutSubmission(t *testing.T) {
+	value, err := util_formula.Evaluate("mean + decayed_mean + wilson_lower_bound(hist_5, count)", util_formula.Variables{})
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), value)
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+	}{
+		{"unknown variable", "sum / reviews"},
+		{"bayes arguments", "bayes(global_mean)"},
+		{"wilson lower bound arguments", "wilson_lower_bound(hist_5)"},
+		{"unbalanced parenthesis", "bayes(global_mean, 6"},
+		{"syntax", "sum /"},
+		{"not a number", "sum > count"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := util_formula.Evaluate(test.formula, util_formula.Variables{Sum: 5, Count: 1})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+	tests := []struct {
+		formula  string
+		expected util_formula.Usage
+	}{
+		{"sum / count", util_formula.Usage{}},
+		{"hist_5 / count", util_formula.Usage{Histogram: true}},
+		{"bayes(global_mean, 10)", util_formula.Usage{Global: true}},
+		{"global_count + decayed_mean", util_formula.Usage{Global: true, Decayed: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.formula, func(t *testing.T) {
+			assert.Equal(t, test.expected, util_formula.GetUsage(test.formula))
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	calls := map[string]int{}
+	loader := util_formula.Loader{
+		Histogram: func() (map[string]int64, error) {
+			calls["histogram"]++
+			return map[string]int64{"5": 3}, nil
+		},
+		Global: func() (float64, int64, error) {
+			calls["global"]++
+			return 420, 100, nil
+		},
+		Decayed: func(halfLife time.Duration) (float64, float64, error) {
+			calls["decayed"]++
+			return 9, 2, nil
+		},
+	}
+
+	variables := util_formula.Variables{Sum: 15, Count: 3}
+	assert.Nil(t, variables.Load("bayes(global_mean, 10) + hist_5", loader))
+	assert.Equal(t, map[string]int{"histogram": 1, "global": 1}, calls)
+	assert.Equal(t, 4.2, variables.GlobalMean)
+	assert.Equal(t, int64(100), variables.GlobalCount)
+	assert.Equal(t, map[string]int64{"5": 3}, variables.Histogram)
+
+	// a histogram already loaded is kept
+	assert.Nil(t, variables.Load("hist_5 + decayed_mean", loader))
+	assert.Equal(t, map[string]int{"histogram": 1, "global": 1, "decayed": 1}, calls)
+	assert.Equal(t, float64(9), variables.DecayedSum)
+}
+
+func TestLoadError(t *testing.T) {
+	failure := errors.New("failed")
+	variables := util_formula.Variables{}
+	err := variables.Load("global_mean", util_formula.Loader{Global: func() (float64, int64, error) { return 0, 0, failure }})
+	assert.Equal(t, failure, err)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		formula string
+		valid   bool
+	}{
+		{"sum / count", true},
+		{"bayes(global_mean, 10)", true},
+		{"wilson_lower_bound(hist_5 + hist_4, count)", true},
+		{"", false},
+		{"sum / hist_1", false},
+		{"unknown + 1", false},
+	}
+	for _, test := range tests {
+		t.Run(test.formula, func(t *testing.T) {
+			err := util_formula.Validate(test.formula)
+			assert.Equal(t, test.valid, err == nil, err)
+		})
+	}
+}
+
+func TestValidateInfiniteValue(t *testing.T) {
+	value, err := util_formula.Evaluate("sum / hist_1", util_formula.Variables{Sum: 5, Count: 1})
+	assert.Nil(t, err)
+	assert.True(t, math.IsInf(value, 1))
+}
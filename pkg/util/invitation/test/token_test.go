@@ -0,0 +1,56 @@
+package invitationtest
+
+import (
+	util_invitation "go-klikdokter/pkg/util/invitation"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSecret(t *testing.T) {
+	defer viper.Set("review-invitation.secret", nil)
+
+	viper.Set("review-invitation.secret", "")
+	assert.Equal(t, util_invitation.ErrSecretEmpty, util_invitation.ValidateSecret())
+
+	// the secret read from an unset env var is empty too
+	os.Unsetenv("REVIEW_INVITATION_SECRET_TEST")
+	viper.Set("review-invitation.secret", "${REVIEW_INVITATION_SECRET_TEST}")
+	assert.Equal(t, util_invitation.ErrSecretEmpty, util_invitation.ValidateSecret())
+
+	viper.Set("review-invitation.secret", "test-secret")
+	assert.NoError(t, util_invitation.ValidateSecret())
+}
+
+func TestParseToken(t *testing.T) {
+	viper.Set("review-invitation.secret", "test-secret")
+	defer viper.Set("review-invitation.secret", nil)
+
+	token := util_invitation.SignToken("629dce7bf1f26275e0d84826", time.Now().Add(time.Hour))
+	id, err := util_invitation.ParseToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "629dce7bf1f26275e0d84826", id)
+
+	_, err = util_invitation.ParseToken(util_invitation.SignToken("629dce7bf1f26275e0d84826", time.Now().Add(-time.Minute)))
+	assert.Equal(t, util_invitation.ErrTokenExpired, err)
+
+	_, err = util_invitation.ParseToken(token + "x")
+	assert.Equal(t, util_invitation.ErrTokenInvalid, err)
+
+	viper.Set("review-invitation.secret", "other-secret")
+	_, err = util_invitation.ParseToken(token)
+	assert.Equal(t, util_invitation.ErrTokenInvalid, err)
+}
+
+func TestParseTokenEmptySecret(t *testing.T) {
+	viper.Set("review-invitation.secret", "")
+	defer viper.Set("review-invitation.secret", nil)
+
+	// a token signed without a secret is never accepted
+	token := util_invitation.SignToken("629dce7bf1f26275e0d84826", time.Now().Add(time.Hour))
+	_, err := util_invitation.ParseToken(token)
+	assert.Equal(t, util_invitation.ErrTokenInvalid, err)
+}
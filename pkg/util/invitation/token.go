@@ -0,0 +1,78 @@
+package util_invitation
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"go-klikdokter/helper/config"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	ErrTokenInvalid = errors.New("invalid review invitation token")
+	ErrTokenExpired = errors.New("review invitation token is expired")
+	ErrSecretEmpty  = errors.New("review-invitation.secret is empty")
+)
+
+// ValidateSecret fails when review-invitation.secret is empty, every token signed with an empty secret is forgeable.
+// It is checked at startup.
+func ValidateSecret() error {
+	if getSecret() == "" {
+		return ErrSecretEmpty
+	}
+	return nil
+}
+
+// SignToken returns the token redeeming the invitation, the token is valid until expiredAt.
+// Format is base64url(<invitation id>.<expired at unix>).base64url(hmac-sha256)
+func SignToken(invitationId string, expiredAt time.Time) string {
+	payload := invitationId + "." + strconv.FormatInt(expiredAt.Unix(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(sign(payload))
+}
+
+// ParseToken verifies the signature and the expiry of the token, returns the id of the invitation
+func ParseToken(token string) (string, error) {
+	if getSecret() == "" {
+		return "", ErrTokenInvalid
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", ErrTokenInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrTokenInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, sign(string(payload))) {
+		return "", ErrTokenInvalid
+	}
+
+	fields := strings.Split(string(payload), ".")
+	if len(fields) != 2 {
+		return "", ErrTokenInvalid
+	}
+	expiredAt, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", ErrTokenInvalid
+	}
+	if time.Now().Unix() > expiredAt {
+		return "", ErrTokenExpired
+	}
+	return fields[0], nil
+}
+
+func sign(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(getSecret()))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func getSecret() string {
+	return config.GetConfigString(viper.GetString("review-invitation.secret"))
+}
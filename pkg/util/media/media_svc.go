@@ -49,6 +49,9 @@ func ImageHouseKeeping(ctx context.Context,logg log.Logger, mediaPath []entity.M
 				_ = level.Error(logger).Log("Log", fmt.Sprintf("Got error from endpoint: %s, with request: %v, response: %v",
 					url, string(jsonData), string(jsonDataResponse)))
 				error = err
+				if error == nil {
+					error = fmt.Errorf("media service response status code is %d", statusCode)
+				}
 				continue
 			}
 			_ = level.Info(logger).Log(fmt.Sprintf("Success Push, with Response: %v", err))
@@ -0,0 +1,183 @@
+package util_moderation
+
+import (
+	"go-klikdokter/app/model/entity"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Result of a moderator, Reasons lists every rule hit by the text
+type Result struct {
+	Flagged bool     `json:"flagged"`
+	Reasons []string `json:"reasons"`
+}
+
+// Moderator inspects a comment before it is published
+type Moderator interface {
+	Moderate(text string) Result
+}
+
+// Chain runs every moderator and merges their reasons
+type Chain []Moderator
+
+func (c Chain) Moderate(text string) Result {
+	result := Result{}
+	for _, m := range c {
+		res := m.Moderate(text)
+		if res.Flagged {
+			result.Flagged = true
+			result.Reasons = append(result.Reasons, res.Reasons...)
+		}
+	}
+	return result
+}
+
+var (
+	registeredMu sync.RWMutex
+	registered   []Moderator
+)
+
+// Register plugs an additional moderator into the chain built by GetModerator
+func Register(m Moderator) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registered = append(registered, m)
+}
+
+// GetModerator builds the moderator chain from the moderation config plus the registered moderators
+func GetModerator() Moderator {
+	var chain Chain
+	words := viper.GetStringSlice("moderation.banned-words")
+	patterns := viper.GetStringSlice("moderation.banned-patterns")
+	if len(words) > 0 || len(patterns) > 0 {
+		chain = append(chain, getBannedWordModerator(words, patterns))
+	}
+	if viper.GetBool("moderation.pii-detection") {
+		chain = append(chain, NewPIIModerator())
+	}
+	if viper.GetBool("moderation.block-links") {
+		chain = append(chain, NewLinkModerator())
+	}
+
+	registeredMu.RLock()
+	chain = append(chain, registered...)
+	registeredMu.RUnlock()
+	return chain
+}
+
+// GetModerationStatus returns the initial moderation_status of a submission comment.
+// When moderation is disabled every submission is approved right away.
+func GetModerationStatus(comment string) (string, []string) {
+	if !viper.GetBool("moderation.enabled") {
+		return entity.ModerationStatusApproved, nil
+	}
+
+	result := GetModerator().Moderate(comment)
+	if result.Flagged {
+		return entity.ModerationStatusPending, result.Reasons
+	}
+	if viper.GetBool("moderation.require-review") && strings.TrimSpace(comment) != "" {
+		return entity.ModerationStatusPending, nil
+	}
+	return entity.ModerationStatusApproved, nil
+}
+
+type bannedWordModerator struct {
+	patterns []*regexp.Regexp
+}
+
+var (
+	bannedWordMu     sync.Mutex
+	bannedWordKey    string
+	bannedWordCached Moderator
+)
+
+// getBannedWordModerator compiles the banned words and patterns once and reuses them until the config changes
+func getBannedWordModerator(words []string, patterns []string) Moderator {
+	key := strings.Join(words, "\x00") + "\x01" + strings.Join(patterns, "\x00")
+
+	bannedWordMu.Lock()
+	defer bannedWordMu.Unlock()
+	if bannedWordCached == nil || bannedWordKey != key {
+		bannedWordCached = NewBannedWordModerator(words, patterns)
+		bannedWordKey = key
+	}
+	return bannedWordCached
+}
+
+// NewBannedWordModerator flags whole banned words (case insensitive) and custom regex patterns.
+// Invalid patterns are skipped.
+func NewBannedWordModerator(words []string, patterns []string) Moderator {
+	m := &bannedWordModerator{}
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		m.patterns = append(m.patterns, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(w)+`\b`))
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			continue
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m
+}
+
+func (m *bannedWordModerator) Moderate(text string) Result {
+	for _, re := range m.patterns {
+		if re.MatchString(text) {
+			return Result{Flagged: true, Reasons: []string{"banned_word"}}
+		}
+	}
+	return Result{}
+}
+
+var (
+	regexPhone = regexp.MustCompile(`(?:\+62|62|\b0)[\s-]?8[1-9](?:[\s-]?\d){6,11}\b`)
+	regexEmail = regexp.MustCompile(`(?i)\b[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}\b`)
+	regexNik   = regexp.MustCompile(`\b\d{16}\b`)
+	regexLink  = regexp.MustCompile(`(?i)(?:https?://|www\.)\S+|\b[a-z0-9\-]+\.(?:com|id|co\.id|net|org|info|xyz|ly|me|io)\b`)
+)
+
+type piiModerator struct{}
+
+// NewPIIModerator flags Indonesian phone numbers, emails and NIK (16 digits identity number)
+func NewPIIModerator() Moderator {
+	return piiModerator{}
+}
+
+func (piiModerator) Moderate(text string) Result {
+	result := Result{}
+	if regexPhone.MatchString(text) {
+		result.Reasons = append(result.Reasons, "pii_phone")
+	}
+	if regexEmail.MatchString(text) {
+		result.Reasons = append(result.Reasons, "pii_email")
+	}
+	if regexNik.MatchString(text) {
+		result.Reasons = append(result.Reasons, "pii_nik")
+	}
+	result.Flagged = len(result.Reasons) > 0
+	return result
+}
+
+type linkModerator struct{}
+
+// NewLinkModerator flags urls and bare domains
+func NewLinkModerator() Moderator {
+	return linkModerator{}
+}
+
+func (linkModerator) Moderate(text string) Result {
+	// emails are handled by the PII moderator
+	if regexLink.MatchString(regexEmail.ReplaceAllString(text, "")) {
+		return Result{Flagged: true, Reasons: []string{"link"}}
+	}
+	return Result{}
+}
@@ -0,0 +1,182 @@
+package moderationtest
+
+import (
+	"go-klikdokter/app/model/entity"
+	util_moderation "go-klikdokter/pkg/util/moderation"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBannedWordModerator(t *testing.T) {
+	moderator := util_moderation.NewBannedWordModerator([]string{"bodoh", " ", "a.b"}, []string{`(?i)pe+nipu`, `[`})
+
+	tests := []struct {
+		name    string
+		text    string
+		flagged bool
+	}{
+		{name: "banned word", text: "Penjual bodoh", flagged: true},
+		{name: "case insensitive", text: "Penjual BODOH", flagged: true},
+		{name: "part of a word", text: "kebodohan", flagged: false},
+		{name: "quoted word", text: "axb", flagged: false},
+		{name: "literal word", text: "a.b", flagged: true},
+		{name: "custom pattern", text: "dasar PEEENIPU", flagged: true},
+		{name: "clean", text: "barang bagus", flagged: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := moderator.Moderate(tt.text)
+			assert.Equal(t, tt.flagged, result.Flagged)
+			if tt.flagged {
+				assert.Equal(t, []string{"banned_word"}, result.Reasons)
+			} else {
+				assert.Empty(t, result.Reasons)
+			}
+		})
+	}
+}
+
+func TestPIIModerator(t *testing.T) {
+	moderator := util_moderation.NewPIIModerator()
+
+	tests := []struct {
+		name    string
+		text    string
+		reasons []string
+	}{
+		{name: "phone", text: "hubungi 0812-3456-7890", reasons: []string{"pii_phone"}},
+		{name: "phone with country code", text: "wa +62 81234567890", reasons: []string{"pii_phone"}},
+		{name: "email", text: "kirim ke Budi.Santoso@mail.co.id", reasons: []string{"pii_email"}},
+		{name: "nik", text: "nik saya 3174012345678901", reasons: []string{"pii_nik"}},
+		{name: "every flag", text: "0812-3456-7890 budi@mail.com 3174012345678901", reasons: []string{"pii_phone", "pii_email", "pii_nik"}},
+		{name: "short number", text: "harga 150000", reasons: nil},
+		{name: "clean", text: "pengiriman cepat", reasons: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := moderator.Moderate(tt.text)
+			assert.Equal(t, len(tt.reasons) > 0, result.Flagged)
+			assert.Equal(t, tt.reasons, result.Reasons)
+		})
+	}
+}
+
+func TestLinkModerator(t *testing.T) {
+	moderator := util_moderation.NewLinkModerator()
+
+	tests := []struct {
+		name    string
+		text    string
+		flagged bool
+	}{
+		{name: "url", text: "cek https://tokosebelah.example/promo", flagged: true},
+		{name: "www", text: "cek www.tokosebelah.com", flagged: true},
+		{name: "bare domain", text: "beli di tokosebelah.co.id saja", flagged: true},
+		{name: "email only", text: "kirim ke budi@mail.com", flagged: false},
+		{name: "clean", text: "barang sesuai deskripsi.", flagged: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := moderator.Moderate(tt.text)
+			assert.Equal(t, tt.flagged, result.Flagged)
+			if tt.flagged {
+				assert.Equal(t, []string{"link"}, result.Reasons)
+			} else {
+				assert.Empty(t, result.Reasons)
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	chain := util_moderation.Chain{
+		util_moderation.NewBannedWordModerator([]string{"bodoh"}, nil),
+		util_moderation.NewPIIModerator(),
+		util_moderation.NewLinkModerator(),
+	}
+
+	result := chain.Moderate("bodoh, hubungi 0812-3456-7890 atau www.tokosebelah.com")
+	assert.True(t, result.Flagged)
+	assert.Equal(t, []string{"banned_word", "pii_phone", "link"}, result.Reasons)
+
+	result = chain.Moderate("barang bagus")
+	assert.False(t, result.Flagged)
+	assert.Empty(t, result.Reasons)
+}
+
+func resetModerationConfig() {
+	viper.Set("moderation.enabled", false)
+	viper.Set("moderation.require-review", false)
+	viper.Set("moderation.pii-detection", false)
+	viper.Set("moderation.block-links", false)
+	viper.Set("moderation.banned-words", []string{})
+	viper.Set("moderation.banned-patterns", []string{})
+}
+
+func TestGetModerationStatusDisabled(t *testing.T) {
+	resetModerationConfig()
+	defer resetModerationConfig()
+	viper.Set("moderation.banned-words", []string{"bodoh"})
+
+	status, flags := util_moderation.GetModerationStatus("Penjual bodoh")
+	assert.Equal(t, entity.ModerationStatusApproved, status)
+	assert.Empty(t, flags)
+}
+
+func TestGetModerationStatusRequireReview(t *testing.T) {
+	resetModerationConfig()
+	defer resetModerationConfig()
+	viper.Set("moderation.enabled", true)
+	viper.Set("moderation.require-review", true)
+
+	status, flags := util_moderation.GetModerationStatus("barang bagus")
+	assert.Equal(t, entity.ModerationStatusPending, status)
+	assert.Empty(t, flags)
+
+	status, flags = util_moderation.GetModerationStatus("  ")
+	assert.Equal(t, entity.ModerationStatusApproved, status)
+	assert.Empty(t, flags)
+}
+
+func TestGetModerationStatusBannedWordsConfigChange(t *testing.T) {
+	resetModerationConfig()
+	defer resetModerationConfig()
+	viper.Set("moderation.enabled", true)
+	viper.Set("moderation.banned-words", []string{"bodoh"})
+
+	status, flags := util_moderation.GetModerationStatus("Penjual bodoh")
+	assert.Equal(t, entity.ModerationStatusPending, status)
+	assert.Equal(t, []string{"banned_word"}, flags)
+
+	viper.Set("moderation.banned-words", []string{"penipu"})
+
+	status, flags = util_moderation.GetModerationStatus("Penjual bodoh")
+	assert.Equal(t, entity.ModerationStatusApproved, status)
+	assert.Empty(t, flags)
+
+	status, flags = util_moderation.GetModerationStatus("Penjual penipu")
+	assert.Equal(t, entity.ModerationStatusPending, status)
+	assert.Equal(t, []string{"banned_word"}, flags)
+}
+
+type spamModerator struct{}
+
+func (spamModerator) Moderate(text string) util_moderation.Result {
+	if text == "spam" {
+		return util_moderation.Result{Flagged: true, Reasons: []string{"spam"}}
+	}
+	return util_moderation.Result{}
+}
+
+func TestRegister(t *testing.T) {
+	resetModerationConfig()
+	defer resetModerationConfig()
+	viper.Set("moderation.enabled", true)
+	util_moderation.Register(spamModerator{})
+
+	status, flags := util_moderation.GetModerationStatus("spam")
+	assert.Equal(t, entity.ModerationStatusPending, status)
+	assert.Equal(t, []string{"spam"}, flags)
+}
@@ -54,4 +54,12 @@ func ReformatMDNWithPrefiX(mdn string, prefixType int) string {
 	}
 
 	return mdn
-}
\ No newline at end of file
+}
+// HelpfulRatio is the share of helpful votes among the helpful and not helpful votes of a submission, 0 without vote
+func HelpfulRatio(likeCounter, notHelpfulCounter int) float64 {
+	total := likeCounter + notHelpfulCounter
+	if total <= 0 {
+		return 0
+	}
+	return RoundFloatWithPrecision(float64(likeCounter)/float64(total), 2)
+}
@@ -0,0 +1,167 @@
+package util_ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionName holds the hits of the mongo store, expired windows are removed by the ttl index on expired_at
+const CollectionName = "rateLimitCol"
+
+// Store counts the hits of a key in fixed windows
+type Store interface {
+	// Hit counts a hit of the key in the current window, it returns the hits of the window and when the window ends
+	Hit(key string, window time.Duration) (int64, time.Time, error)
+}
+
+// Budget of a route, Limit hits per Window and per key, a route without limit is not limited
+type Budget struct {
+	Limit  int64
+	Window time.Duration
+}
+
+// GetBudget reads rate-limit.routes.<route> of the config
+func GetBudget(route string) Budget {
+	key := "rate-limit.routes." + route
+	return Budget{
+		Limit:  viper.GetInt64(key + ".limit"),
+		Window: time.Duration(viper.GetFloat64(key+".window-seconds") * float64(time.Second)),
+	}
+}
+
+// Allow counts a hit of every key on the route, it returns false and the time to wait when a key is over the budget of the route
+func Allow(store Store, route string, keys ...string) (bool, time.Duration, error) {
+	budget := GetBudget(route)
+	if !viper.GetBool("rate-limit.enabled") || budget.Limit <= 0 || budget.Window <= 0 {
+		return true, 0, nil
+	}
+
+	allowed := true
+	var retryAfter time.Duration
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		hits, resetAt, err := store.Hit(route+"||"+key, budget.Window)
+		if err != nil {
+			return true, 0, err
+		}
+		if hits > budget.Limit {
+			allowed = false
+			if wait := time.Until(resetAt); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+	return allowed, retryAfter, nil
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store = NewMemoryStore()
+)
+
+// UseStore replaces the store used by the rate limited routes, the memory store is used until then
+func UseStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+// GetStore returns the store used by the rate limited routes
+func GetStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// NewStore builds the store of rate-limit.store, mongo shares the hits across the replicas, memory counts the hits of this replica only
+func NewStore(db *mongo.Database) Store {
+	if viper.GetString("rate-limit.store") == "mongo" && db != nil {
+		return NewMongoStore(db)
+	}
+	return NewMemoryStore()
+}
+
+type memoryWindow struct {
+	hits    int64
+	resetAt time.Time
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+}
+
+// NewMemoryStore counts the hits in memory, the ended windows are dropped as the keys are hit again
+func NewMemoryStore() Store {
+	return &memoryStore{windows: map[string]*memoryWindow{}}
+}
+
+func (m *memoryStore) Hit(key string, window time.Duration) (int64, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	w, ok := m.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		if len(m.windows) > 10000 {
+			m.dropEnded(now)
+		}
+		w = &memoryWindow{resetAt: windowStart(now, window).Add(window)}
+		m.windows[key] = w
+	}
+	w.hits++
+	return w.hits, w.resetAt, nil
+}
+
+func (m *memoryStore) dropEnded(now time.Time) {
+	for key, w := range m.windows {
+		if !now.Before(w.resetAt) {
+			delete(m.windows, key)
+		}
+	}
+}
+
+type mongoStore struct {
+	db *mongo.Database
+}
+
+// NewMongoStore counts the hits in rateLimitCol with an atomic $inc
+func NewMongoStore(db *mongo.Database) Store {
+	return &mongoStore{db}
+}
+
+func (m *mongoStore) Hit(key string, window time.Duration) (int64, time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	start := windowStart(time.Now(), window)
+	resetAt := start.Add(window)
+	var result struct {
+		Hits int64 `bson:"hits"`
+	}
+	err := m.db.Collection(CollectionName).FindOneAndUpdate(ctx,
+		bson.D{{Key: "_id", Value: key + "||" + start.UTC().Format(time.RFC3339)}},
+		bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "hits", Value: 1}}},
+			{Key: "$setOnInsert", Value: bson.D{{Key: "expired_at", Value: resetAt}}},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&result)
+	if err != nil {
+		return 0, resetAt, err
+	}
+	return result.Hits, resetAt, nil
+}
+
+// windowStart aligns the windows of every replica on the same boundaries
+func windowStart(now time.Time, window time.Duration) time.Time {
+	return now.Truncate(window)
+}
@@ -0,0 +1,127 @@
+package util_search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// SnippetWords is the number of words kept around the first match of a highlighted snippet
+	SnippetWords  = 12
+	highlightOpen = "<em>"
+	highlightEnd  = "</em>"
+)
+
+// Normalize lowercases the text, drops the punctuation and shortens the letters repeated for emphasis,
+// "Bagusss!!" and "bagus" are both normalized to "bagus".
+// A letter repeated three times or more is kept once, a doubled last letter of a word too ("kerenn"),
+// a doubled letter inside a word ("maaf", "saat") and the digits are kept as is.
+func Normalize(text string) string {
+	return strings.Join(Terms(text), " ")
+}
+
+// Terms returns the normalized words of the text
+func Terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, normalizeWord(word))
+	}
+	return terms
+}
+
+func normalizeWord(word string) string {
+	runes := []rune(word)
+	result := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		repeat := j - i
+		if unicode.IsLetter(runes[i]) && (repeat >= 3 || (repeat == 2 && j == len(runes))) {
+			repeat = 1
+		}
+		for k := 0; k < repeat; k++ {
+			result = append(result, runes[i])
+		}
+		i = j
+	}
+	return string(result)
+}
+
+// Filter returns the $text filter of the query, empty when the query has no word
+func Filter(q string) bson.D {
+	search := Normalize(q)
+	if search == "" {
+		return bson.D{}
+	}
+	return bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: search}}}}
+}
+
+// SortByScore is the sort of the $text matches, the most relevant first
+var SortByScore = bson.D{{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}}
+
+// Sort returns the sort of a list searched by q, the most relevant first when no sort field is given
+func Sort(q, sort string, dir interface{}) bson.D {
+	if sort == "" {
+		if Normalize(q) != "" {
+			return SortByScore
+		}
+		sort = "created_at"
+	}
+	return bson.D{{Key: sort, Value: dir}}
+}
+
+// Highlight returns the part of the text around the first word matching the query, every matching word is wrapped in <em>.
+// The text is html escaped, an empty string is returned when no word matches.
+func Highlight(text, q string) string {
+	queryTerms := map[string]bool{}
+	for _, term := range Terms(q) {
+		queryTerms[term] = true
+	}
+	if len(queryTerms) == 0 {
+		return ""
+	}
+
+	words := strings.Fields(text)
+	first := -1
+	highlighted := make([]string, len(words))
+	for i, word := range words {
+		highlighted[i] = html.EscapeString(word)
+		for _, term := range Terms(word) {
+			if queryTerms[term] {
+				highlighted[i] = highlightOpen + html.EscapeString(word) + highlightEnd
+				if first < 0 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start := first - SnippetWords/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + SnippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+	snippet := strings.Join(highlighted[start:end], " ")
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(words) {
+		snippet += "..."
+	}
+	return snippet
+}
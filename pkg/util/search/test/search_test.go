@@ -0,0 +1,110 @@
+package searchtest
+
+import (
+	util_search "go-klikdokter/pkg/util/search"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "bagusss", want: "bagus"},
+		{text: "Bagusss!!", want: "bagus"},
+		{text: "BAGUS", want: "bagus"},
+		{text: "kerenn", want: "keren"},
+		{text: "mantapppp sekaliii", want: "mantap sekali"},
+		// a doubled letter inside a word is part of it
+		{text: "maaf", want: "maaf"},
+		{text: "saat", want: "saat"},
+		// the stop words are kept, mongo has no indonesian stop words to drop them
+		{text: "maaf yang dan", want: "maaf yang dan"},
+		{text: "harga 1000", want: "harga 1000"},
+		{text: "100000", want: "100000"},
+		{text: "pengiriman,cepat. barang-ok", want: "pengiriman cepat barang ok"},
+		{text: "  ", want: ""},
+		{text: "?!...", want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, util_search.Normalize(tt.text), "text %q", tt.text)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	assert.Equal(t, bson.D{}, util_search.Filter("?!"))
+	assert.Equal(t, bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: "bagus"}}}}, util_search.Filter("Bagusss!!"))
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		sort string
+		want bson.D
+	}{
+		{name: "searched without sort", q: "bagus", want: util_search.SortByScore},
+		{name: "searched with sort", q: "bagus", sort: "like_counter", want: bson.D{{Key: "like_counter", Value: -1}}},
+		{name: "not searched", want: bson.D{{Key: "created_at", Value: -1}}},
+		{name: "query without word", q: "!!", want: bson.D{{Key: "created_at", Value: -1}}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, util_search.Sort(tt.q, tt.sort, -1), tt.name)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	// words of the comments, numbered to tell the bounds of the snippet
+	words := func(from, to int) string {
+		var parts []string
+		for i := from; i <= to; i++ {
+			parts = append(parts, "w"+strings.Repeat("x", i))
+		}
+		return strings.Join(parts, " ")
+	}
+	longText := words(1, 10) + " bagus " + words(11, 20)
+
+	tests := []struct {
+		name string
+		text string
+		q    string
+		want string
+	}{
+		{name: "no query", text: "barang bagus", q: "", want: ""},
+		{name: "no match", text: "barang bagus", q: "jelek", want: ""},
+		{name: "every match", text: "Bagus, bagusss sekali", q: "bagus", want: "<em>Bagus,</em> <em>bagusss</em> sekali"},
+		{
+			name: "html escaped",
+			text: `<script>alert("x")</script> bagus & murah`,
+			q:    "bagus",
+			want: "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <em>bagus</em> &amp; murah",
+		},
+		{name: "html in the matching word escaped", text: "<b>bagus</b> banget", q: "bagus", want: "<em>&lt;b&gt;bagus&lt;/b&gt;</em> banget"},
+		{
+			name: "match at the start",
+			text: "bagus " + words(1, 20),
+			q:    "bagus",
+			want: "<em>bagus</em> " + words(1, util_search.SnippetWords-1) + "...",
+		},
+		{
+			name: "match at the end",
+			text: words(1, 20) + " bagus",
+			q:    "bagus",
+			want: "..." + words(20-util_search.SnippetWords/2+1, 20) + " <em>bagus</em>",
+		},
+		{
+			name: "match in the middle",
+			text: longText,
+			q:    "bagus",
+			want: "..." + words(10-util_search.SnippetWords/2+1, 10) + " <em>bagus</em> " + words(11, 11+util_search.SnippetWords/2-2) + "...",
+		},
+		{name: "short comment", text: "pengiriman cepat barang bagus", q: "bagus", want: "pengiriman cepat barang <em>bagus</em>"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, util_search.Highlight(tt.text, tt.q), tt.name)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"go-klikdokter/app/model/entity"
 	"go-klikdokter/app/model/request"
 	"go-klikdokter/helper/message"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -85,7 +87,7 @@ func ValidInputUpdateRatingTypeLikertInRating(input request.SaveRatingTypeLikert
 	return errMsg
 }
 
-func ValidInputUpdateRatingTypeLikertInSubmission(input request.SaveRatingTypeLikertRequest) message.Message {
+func ValidInputUpdateRatingTypeLikertInSubmission(input request.SaveRatingTypeLikertRequest, statements []entity.LikertStatement) message.Message {
 	var errMsg message.Message
 	if input.Status != nil {
 		errMsg = message.ErrCannotModifiedStatus
@@ -95,53 +97,57 @@ func ValidInputUpdateRatingTypeLikertInSubmission(input request.SaveRatingTypeLi
 		errMsg = message.ErrCannotModifiedType
 		return errMsg
 	}
-	if input.NumStatements != 0 {
+	if input.NumStatements != 0 && input.NumStatements != len(statements) {
 		errMsg = message.ErrCannotModifiedNumStatement
 		return errMsg
 	}
-	if input.Statement01 != nil {
+	// the submissions reference the statements by key, the labels and icons can change but not the keys
+	if inputStatements := input.GetStatements(); len(inputStatements) > 0 && !sameLikertStatementKeys(inputStatements, statements) {
 		errMsg = message.ErrCannotModifiedStatement
 		return errMsg
 	}
-	if input.Statement02 != nil {
-		errMsg = message.ErrCannotModifiedStatement
-		return errMsg
-	}
-	if input.Statement03 != nil {
-		errMsg = message.ErrCannotModifiedStatement
-		return errMsg
-	}
-	if input.Statement04 != nil {
-		errMsg = message.ErrCannotModifiedStatement
-		return errMsg
-	}
-	if input.Statement05 != nil {
-		errMsg = message.ErrCannotModifiedStatement
-		return errMsg
+	return errMsg
+}
+
+func sameLikertStatementKeys(statements, other []entity.LikertStatement) bool {
+	if len(statements) != len(other) {
+		return false
 	}
-	if input.Statement06 != nil {
-		errMsg = message.ErrCannotModifiedStatement
-		return errMsg
+	keys := make(map[string]bool, len(other))
+	for _, statement := range other {
+		keys[statement.Key] = true
 	}
-	if input.Statement07 != nil {
-		errMsg = message.ErrCannotModifiedStatement
-		return errMsg
-	}
-	if input.Statement08 != nil {
-		errMsg = message.ErrCannotModifiedStatement
-		return errMsg
+	for _, statement := range statements {
+		if !keys[statement.Key] {
+			return false
+		}
 	}
-	if input.Statement09 != nil {
-		errMsg = message.ErrCannotModifiedStatement
+	return true
+}
+
+var regexLikertStatementKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateLikertStatements checks the statements count against num_statements and the limit, and that the keys are unique
+func ValidateLikertStatements(statements []entity.LikertStatement, numStatements, limit int) message.Message {
+	var errMsg message.Message
+	if len(statements) != numStatements {
+		errMsg = message.ErrMatchNumState
 		return errMsg
 	}
-	if input.Statement10 != nil {
-		errMsg = message.ErrCannotModifiedStatement
+	if len(statements) > limit {
+		errMsg = message.ErrMaxStatements
 		return errMsg
 	}
-	if input.Status != nil {
-		errMsg = message.ErrCannotModifiedStatus
-		return errMsg
+	keys := make(map[string]bool, len(statements))
+	for _, statement := range statements {
+		if !regexLikertStatementKey.MatchString(statement.Key) || keys[statement.Key] || strings.TrimSpace(statement.Label) == "" {
+			errMsg = message.ErrStatementKey
+			return errMsg
+		}
+		if errMsg = ValidateLocalizedText(statement.LabelI18n); errMsg.Message != "" {
+			return errMsg
+		}
+		keys[statement.Key] = true
 	}
 	return errMsg
 }
@@ -159,49 +165,17 @@ func ValidateTypeNumeric(input *entity.RatingTypesNumCol, value float64) message
 	return message.SuccessMsg
 }
 
-func ValidateLikertType(input *entity.RatingTypesLikertCol, value []string) (error, []int) {
+// ValidateLikertType checks that every value is the key or the position (from 1) of a statement of the likert type,
+// the keys are returned to tell the valid values
+func ValidateLikertType(input *entity.RatingTypesLikertCol, value []string) (error, []string) {
 	wrongValue := "wrong value"
-	validValue := make([]int, 0)
-	emtpy := ""
-	if input.Statement01 != nil && *input.Statement01 != emtpy {
-		validValue = append(validValue, 1)
-	}
-	if input.Statement02 != nil && *input.Statement02 != emtpy {
-		validValue = append(validValue, 2)
-	}
-	if input.Statement03 != nil && *input.Statement03 != emtpy {
-		validValue = append(validValue, 3)
-	}
-	if input.Statement04 != nil && *input.Statement04 != emtpy {
-		validValue = append(validValue, 4)
-	}
-	if input.Statement05 != nil && *input.Statement05 != emtpy {
-		validValue = append(validValue, 5)
-	}
-	if input.Statement06 != nil && *input.Statement06 != emtpy {
-		validValue = append(validValue, 6)
-	}
-	if input.Statement07 != nil && *input.Statement07 != emtpy {
-		validValue = append(validValue, 7)
-	}
-	if input.Statement08 != nil && *input.Statement08 != emtpy {
-		validValue = append(validValue, 8)
-	}
-	if input.Statement09 != nil && *input.Statement09 != emtpy {
-		validValue = append(validValue, 9)
-	}
-	if input.Statement10 != nil && *input.Statement10 != emtpy {
-		validValue = append(validValue, 10)
+	statements := input.GetStatements()
+	validValue := make([]string, 0, len(statements))
+	for _, statement := range statements {
+		validValue = append(validValue, statement.Key)
 	}
 	for _, args := range value {
-		v, err := strconv.ParseFloat(args, 64)
-		if err != nil {
-			return errors.New(wrongValue), validValue
-		}
-		if v-float64(int(v)) > 0 || v == 0 {
-			return errors.New(wrongValue), validValue
-		}
-		if isInclude := IsInclude(validValue, v); isInclude == false {
+		if _, ok := likertStatementKey(statements, args); !ok {
 			return errors.New(wrongValue), validValue
 		}
 	}
@@ -209,6 +183,31 @@ func ValidateLikertType(input *entity.RatingTypesLikertCol, value []string) (err
 	return nil, validValue
 }
 
+// LikertStatementKeys returns the keys of the statements selected by the values, a position is replaced by the key of the statement
+func LikertStatementKeys(input *entity.RatingTypesLikertCol, value []string) []string {
+	statements := input.GetStatements()
+	keys := make([]string, 0, len(value))
+	for _, args := range value {
+		if key, ok := likertStatementKey(statements, args); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func likertStatementKey(statements []entity.LikertStatement, value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	for _, statement := range statements {
+		if statement.Key == value {
+			return statement.Key, true
+		}
+	}
+	if position, err := strconv.Atoi(value); err == nil && position > 0 && position <= len(statements) {
+		return statements[position-1].Key, true
+	}
+	return "", false
+}
+
 func IsInclude(arrValue []int, value float64) bool {
 	for _, args := range arrValue {
 		if int(value) == args {
@@ -316,4 +315,18 @@ func ValidateUserCannotUpdateMp(userID string, userIDLegacy string, ratingSub en
 	}
 
 	return false
+}
+
+// ValidateLocalizedText returns ErrUnsupportedLanguage when a text is keyed by a language that is not supported
+func ValidateLocalizedText(texts ...entity.LocalizedText) message.Message {
+	var errMsg message.Message
+	for _, text := range texts {
+		for lang := range text {
+			if !message.IsSupportedLanguage(lang) {
+				errMsg = message.ErrUnsupportedLanguage
+				return errMsg
+			}
+		}
+	}
+	return errMsg
 }
\ No newline at end of file